@@ -0,0 +1,333 @@
+package bots
+
+import (
+	"context"
+	"time"
+
+	"tic-tac-toe-3d-bots/engine"
+)
+
+// AlphaBetaMinimaxBot represents a minimax AI player with threshold-based pruning optimization
+type AlphaBetaMinimaxBot struct {
+	BaseBot
+	Depth     int
+	MoveTime  time.Duration         // Longest a move may take, deepening one ply at a time until it runs out; 0 searches at Depth at once
+	Evaluator engine.Evaluator      // How the bot scores positions, whatever the board's own evaluator
+	tt        *TranspositionTable   // Positions searched, kept from one move to the next
+	progress  func(SearchIteration) // Receives each iteration of the search while set, see ReportProgress
+}
+
+// NewAlphaBetaMinimaxBot creates a new threshold-based pruning minimax bot with the given symbol, name, and search depth
+// Its transposition table takes ttSize MiB (TTSize if not positive) and replaces entries by ttPolicy (TTPolicy if 0)
+func NewAlphaBetaMinimaxBot(symbol byte, name string, depth int, evaluator engine.Evaluator, ttSize int, ttPolicy ReplacementPolicy) *AlphaBetaMinimaxBot {
+	return &AlphaBetaMinimaxBot{
+		BaseBot:   NewBaseBot(symbol, name),
+		Depth:     depth,
+		Evaluator: evaluator,
+		tt:        NewTranspositionTable(ttSize, ttPolicy),
+	}
+}
+
+// init registers AlphaBetaMinimaxBot with the bot registry
+func init() {
+	RegisterBot(&BotRegistration{
+		Key:         "alphabeta",
+		DisplayName: "AlphaBetaMinimaxBot",
+		Description: "minimax with alpha-beta pruning",
+		Order:       4,
+		// Higher depth due to pruning efficiency; tt in MiB and ttpolicy (see ReplacementPolicies, from 1) take TTSize and TTPolicy at 0
+		// movetime in milliseconds bounds each move, searching no deeper than depth; 0 leaves moves unbounded
+		Defaults: withEvaluatorDefaults(map[string]int{"depth": 7, "movetime": 0, "tt": 0, "ttpolicy": 0}),
+		New: func(symbol byte, name string, params map[string]int) BotInterface {
+			bot := NewAlphaBetaMinimaxBot(symbol, name, params["depth"], evaluatorFromParams(params), params["tt"], ttPolicyFromParams(params))
+			bot.MoveTime = time.Duration(params["movetime"]) * time.Millisecond
+			return bot
+		},
+	})
+}
+
+// MakeMove makes a move using alpha-beta pruning minimax algorithm (implements BotInterface)
+// Uses threshold-based pruning to eliminate unnecessary branches from the search tree
+func (bot *AlphaBetaMinimaxBot) MakeMove(ctx context.Context, board *engine.Board) (Move, error) {
+	defer useEvaluator(board, bot.Evaluator)()
+	bot.tt.Prepare(board)
+
+	// Use extreme threshold for root call (no pruning constraint from parent)
+	isMaximizing := bot.Symbol() == 'x'
+	threshold := engine.MAX_INT // If we're maximizing, use MAX_INT (can never be reached, so never prunes)
+	if !isMaximizing {
+		threshold = engine.MIN_INT // If we're minimizing, use MIN_INT (can never be reached, so never prunes)
+	}
+	var score int
+	var bestMove string
+	search := func(ctx context.Context, depth int) (int, string) {
+		iterationCtx, endSpan := startIterationSpan(ctx, board, depth, bot.tt)
+		score, move := alphaBetaTT(board, depth, isMaximizing, threshold, bot.tt, iterationCtx)
+		endSpan(score, move)
+		return score, move
+	}
+	if bot.progress == nil && bot.MoveTime <= 0 {
+		score, bestMove = search(ctx, bot.Depth)
+	} else {
+		// Deepen one ply at a time, each iteration searching the table's moves of the last one first
+		searchCtx := ctx
+		if bot.MoveTime > 0 {
+			var cancel context.CancelFunc
+			searchCtx, cancel = context.WithTimeout(ctx, bot.MoveTime)
+			defer cancel()
+		}
+		for depth := 1; depth <= bot.Depth && !SearchCancelled(searchCtx); depth++ {
+			iterationScore, iterationMove := search(searchCtx, depth)
+			if SearchCancelled(searchCtx) && bestMove != "" {
+				break // Out of time: the last complete iteration stands
+			}
+			score, bestMove = iterationScore, iterationMove
+			if !SearchCancelled(searchCtx) && bot.progress != nil {
+				bot.progress(SearchIteration{Depth: depth, Score: score, Line: ttLine(board, bot.tt, bot.Symbol(), depth)})
+			}
+		}
+	}
+	bot.reportScore(score)
+
+	stats := bot.tt.Stats()
+	searchLog.Load().Debug("transposition table", "player", string(bot.Symbol()), "policy", stats.Policy, "fill", stats.FillRate(),
+		"used", stats.Used, "entries", stats.Entries, "hit_rate", stats.HitRate(), "cutoffs", stats.Cutoffs,
+		"overwritten", stats.Overwritten, "rejected", stats.Rejected)
+	return PlayChosenMove(ctx, board, bot.Symbol(), bestMove)
+}
+
+// ReportProgress has the bot search by iterative deepening, passing each completed iteration to report, or search at
+// its depth at once again if report is nil (implements ProgressReporter)
+func (bot *AlphaBetaMinimaxBot) ReportProgress(report func(SearchIteration)) {
+	bot.progress = report
+}
+
+// TTStats returns the size and fill of the bot's transposition table
+func (bot *AlphaBetaMinimaxBot) TTStats() TTStats {
+	return bot.tt.Stats()
+}
+
+// Close gives the memory of the bot's transposition table back to the search memory budget (implements BotInterface)
+func (bot *AlphaBetaMinimaxBot) Close() {
+	bot.tt.Release()
+}
+
+// AlphaBetaMinimax performs minimax with threshold-based pruning optimization
+// This approach simplifies traditional alpha-beta pruning by using:
+// - threshold: the current best score we're trying to beat (MAX_INT/MIN_INT if no constraint)
+// When a score exceeds the threshold, we can prune the remaining search branches
+// If ctx is cancelled the search unwinds early and its result must be discarded
+func AlphaBetaMinimax(board *engine.Board, depth int, isMaximizing bool, threshold int, ctx context.Context) (int, []string) {
+	// Check for winning conditions first
+	winner := board.CheckWin()
+	if winner != '|' {
+		if winner == 'x' {
+			return engine.MAX_INT / 2, []string{} // X wins
+		} else {
+			return engine.MIN_INT / 2, []string{} // O wins
+		}
+	}
+
+	if depth == 0 {
+		return board.Score, []string{} // Use the board's current score
+	}
+	if board.IsFull() {
+		return board.Score, []string{} // A full board is a draw, scored as a leaf
+	}
+
+	// Set result to very low/high initial value
+	var symbol byte = 'x'
+	currentScore := engine.MIN_INT
+	if !isMaximizing {
+		symbol = 'o'
+		currentScore = engine.MAX_INT
+	}
+	bestMoves := []string{}
+
+	// Moves are only named once they prove best, so the nodes searched allocate nothing else
+	board.EachValidMove(func(col, row int) bool {
+		if SearchCancelled(ctx) {
+			return false
+		}
+
+		board.MoveAt(col, row, symbol)
+
+		// Pass our current best score as threshold for pruning
+		score, moves := AlphaBetaMinimax(board, depth-1, !isMaximizing, currentScore, ctx)
+		board.UnMoveAt(col, row)
+
+		if isMaximizing {
+			if score > currentScore {
+				currentScore = score
+				bestMoves = append([]string{board.MoveName(col, row)}, moves...)
+			}
+			// Threshold-based pruning: if our score beats the threshold, parent won't choose this path
+			return currentScore < threshold // Parent is minimizing and won't select this branch otherwise
+		}
+		if score < currentScore {
+			currentScore = score
+			bestMoves = append([]string{board.MoveName(col, row)}, moves...)
+		}
+		// Threshold-based pruning: if our score is worse than threshold, parent won't choose this path
+		return currentScore > threshold // Parent is maximizing and won't select this branch otherwise
+	})
+
+	return currentScore, bestMoves
+}
+
+// deepeningAlphaBeta is AlphaBetaMinimax from the root at depth for the bots searching without a table: at once if
+// report is nil, else deepening one ply at a time and passing each completed iteration to report
+func deepeningAlphaBeta(board *engine.Board, depth int, symbol byte, report func(SearchIteration), ctx context.Context) (int, []string) {
+	isMaximizing := symbol == 'x'
+	threshold := engine.MAX_INT
+	if !isMaximizing {
+		threshold = engine.MIN_INT
+	}
+	var score int
+	var line []string
+	search := func(depth int) {
+		iterationCtx, endSpan := startIterationSpan(ctx, board, depth, nil)
+		score, line = AlphaBetaMinimax(board, depth, isMaximizing, threshold, iterationCtx)
+		endSpan(score, FirstMove(line))
+	}
+	if report == nil {
+		search(depth)
+		return score, line
+	}
+	for iteration := 1; iteration <= depth && !SearchCancelled(ctx); iteration++ {
+		search(iteration)
+		if !SearchCancelled(ctx) {
+			report(SearchIteration{Depth: iteration, Score: score, Line: line})
+		}
+	}
+	return score, line
+}
+
+// searchTable is where alphaBetaTT remembers positions: a bot's own TranspositionTable, or a SharedTT its parallel
+// searches share
+type searchTable interface {
+	probe(hash uint64) (ttEntry, bool)
+	peek(hash uint64) (ttEntry, bool)
+	store(hash uint64, depth, score int, bound uint8, move int16)
+	countCutoff()
+	Stats() TTStats
+}
+
+// alphaBetaTT is AlphaBetaMinimax remembering the positions it searches in tt: a position searched at least as deep
+// before is answered from the table when its score is exact, or already falls past the threshold, and otherwise its
+// best move from the table is searched first. It returns the best move rather than the whole line, which the table
+// does not keep
+func alphaBetaTT(board *engine.Board, depth int, isMaximizing bool, threshold int, tt searchTable, ctx context.Context) (int, string) {
+	score, move := alphaBetaTTSearch(board, depth, isMaximizing, threshold, tt, ctx)
+	return score, unpackMove(move, board)
+}
+
+// ttLine returns the principal variation tt holds for board with symbol to move: the best moves the table keeps
+// from one position to the next, at most depth of them
+func ttLine(board *engine.Board, tt searchTable, symbol byte, depth int) []string {
+	var line []string
+	var played [][2]int
+	for len(line) < depth && board.CheckWin() == '|' {
+		entry, found := tt.probe(board.ZobristHash())
+		if !found || entry.move < 0 {
+			break
+		}
+		col, row := int(entry.move)/board.Width, int(entry.move)%board.Width
+		if col >= board.Length || board.CurrentHeights[col][row] >= board.Height {
+			break // Another position's entry
+		}
+		line = append(line, board.MoveName(col, row))
+		board.MoveAt(col, row, symbol)
+		played = append(played, [2]int{col, row})
+		symbol = engine.OpponentSymbol(symbol)
+	}
+	for i := len(played) - 1; i >= 0; i-- {
+		board.UnMoveAt(played[i][0], played[i][1])
+	}
+	return line
+}
+
+// alphaBetaTTSearch is alphaBetaTT with its best move packed as the table keeps it (see packMove), so that only the
+// root's is ever named
+func alphaBetaTTSearch(board *engine.Board, depth int, isMaximizing bool, threshold int, tt searchTable, ctx context.Context) (int, int16) {
+	winner := board.CheckWin()
+	if winner != '|' {
+		if winner == 'x' {
+			return engine.MAX_INT / 2, -1
+		}
+		return engine.MIN_INT / 2, -1
+	}
+	if depth == 0 || board.IsFull() {
+		return board.Score, -1
+	}
+
+	ttMove := int16(-1)
+	hash := board.ZobristHash()
+	if entry, found := tt.probe(hash); found {
+		if int(entry.depth) >= depth {
+			switch {
+			case entry.bound == ttExact,
+				entry.bound == ttLower && isMaximizing && entry.score >= threshold,
+				entry.bound == ttUpper && !isMaximizing && entry.score <= threshold:
+				tt.countCutoff()
+				return entry.score, entry.move
+			}
+		}
+		ttMove = entry.move
+	}
+
+	var symbol byte = 'x'
+	currentScore := engine.MIN_INT
+	if !isMaximizing {
+		symbol = 'o'
+		currentScore = engine.MAX_INT
+	}
+	bestMove := int16(-1)
+	bound := ttExact
+	cancelled := false
+
+	// search searches one move, reporting whether the remaining ones still need searching
+	search := func(col, row int) bool {
+		if SearchCancelled(ctx) {
+			cancelled = true
+			return false
+		}
+
+		board.MoveAt(col, row, symbol)
+		score, _ := alphaBetaTTSearch(board, depth-1, !isMaximizing, currentScore, tt, ctx)
+		board.UnMoveAt(col, row)
+
+		if isMaximizing {
+			if score > currentScore {
+				currentScore, bestMove = score, packMove(col, row, board.Width)
+			}
+			if currentScore >= threshold {
+				bound = ttLower // The remaining moves might score higher still
+				return false
+			}
+		} else {
+			if score < currentScore {
+				currentScore, bestMove = score, packMove(col, row, board.Width)
+			}
+			if currentScore <= threshold {
+				bound = ttUpper
+				return false
+			}
+		}
+		return true
+	}
+
+	// The table's move first, then the others
+	ttCol, ttRow := int(ttMove)/board.Width, int(ttMove)%board.Width
+	if ttMove < 0 || board.CurrentHeights[ttCol][ttRow] >= board.Height || search(ttCol, ttRow) {
+		board.EachValidMove(func(col, row int) bool {
+			return (ttMove >= 0 && col == ttCol && row == ttRow) || search(col, row)
+		})
+	}
+
+	if cancelled || SearchCancelled(ctx) {
+		return currentScore, bestMove // Incomplete, so not stored
+	}
+	tt.store(hash, depth, currentScore, bound, bestMove)
+	return currentScore, bestMove
+}
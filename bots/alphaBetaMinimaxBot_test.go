@@ -0,0 +1,28 @@
+package bots
+
+import (
+	"context"
+	"testing"
+
+	"tic-tac-toe-3d-bots/engine"
+)
+
+func BenchmarkAlphaBetaDepth6(b *testing.B) {
+	board, err := engine.New(engine.WithDims(4, 4, 4))
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, move := range []string{"B2", "C3", "B3"} {
+		board.Move(move, board.NextPlayer())
+	}
+	isMaximizing := board.NextPlayer() == 'x'
+	threshold := engine.MAX_INT
+	if !isMaximizing {
+		threshold = engine.MIN_INT
+	}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		AlphaBetaMinimax(board, 6, isMaximizing, threshold, context.Background())
+	}
+}
@@ -0,0 +1,256 @@
+// Package bots implements the bots and their searches, and the registry creating them from bot specs
+package bots
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"runtime"
+	"sort"
+	"strings"
+
+	"tic-tac-toe-3d-bots/engine"
+)
+
+// BotInterface defines the interface that all bots must implement
+type BotInterface interface {
+	MakeMove(ctx context.Context, board *engine.Board) (Move, error) // Plays a move on the board; stops early if ctx is cancelled
+	Name() string                                                    // Display name of the bot
+	Symbol() byte                                                    // Symbol the bot plays ('x' or 'o')
+	OpponentMove(move string)                                        // Notifies the bot of the opponent's move
+	Close()                                                          // Releases any background resources held by the bot
+}
+
+// GameResultListener is implemented by bots that want to learn the result of each finished game
+// winner is 'x', 'o' or '|' for a draw
+type GameResultListener interface {
+	GameOver(winner byte)
+}
+
+// SearchIteration is a completed iteration of a bot's iteratively deepening search
+type SearchIteration struct {
+	Depth int
+	Score int      // score from 'x' perspective
+	Line  []string // principal variation of the best move
+}
+
+// ProgressReporter is implemented by bots whose search deepens iteratively and can report every iteration it
+// completes while they think. report is called from the search, until it is replaced; nil stops the reports
+type ProgressReporter interface {
+	ReportProgress(report func(SearchIteration))
+}
+
+// BotConfig describes one bot in the lineup
+type BotConfig struct {
+	Type   string         `json:"type"`   // bot type as accepted by --bot1/--bot2, e.g. "alphabeta"
+	Name   string         `json:"name"`   // display name (optional)
+	Params map[string]int `json:"params"` // bot parameters such as depth and base
+
+	Command []string `json:"command,omitempty"` // executable and arguments of an external engine, or server and bot of a remote one (profiles only)
+}
+
+// BaseBot holds the name and symbol shared by every bot
+// Embedding it provides Name and Symbol plus no-op OpponentMove and Close,
+// so a bot only has to override the hooks it actually needs
+type BaseBot struct {
+	name   string
+	symbol byte
+	config *BotConfig // registry configuration the bot was created from, if any
+	rng    *rand.Rand // random source of bots that make random choices, see random
+	score  int        // root score of the bot's last search, see LastScore
+	scored bool       // whether the bot has reported one
+}
+
+// NewBaseBot creates a BaseBot with the given symbol and name
+func NewBaseBot(symbol byte, name string) BaseBot {
+	return BaseBot{name: name, symbol: symbol}
+}
+
+// Name returns the bot's name (implements BotInterface)
+func (base *BaseBot) Name() string {
+	return base.name
+}
+
+// Symbol returns the bot's symbol (implements BotInterface)
+func (base *BaseBot) Symbol() byte {
+	return base.symbol
+}
+
+// Config returns the registry configuration the bot was created from, or nil if it was built directly
+func (base *BaseBot) Config() *BotConfig {
+	return base.config
+}
+
+// SetConfig records the configuration the registry created the bot from
+func (base *BaseBot) SetConfig(config *BotConfig) {
+	base.config = config
+}
+
+// LastScore returns the score ('x' perspective) the bot's last search gave the move it played, and whether the bot
+// reports one at all: searching bots do, bots choosing their moves otherwise do not
+func (base *BaseBot) LastScore() (int, bool) {
+	return base.score, base.scored
+}
+
+// reportScore records the root score of the bot's search for LastScore
+func (base *BaseBot) reportScore(score int) {
+	base.score, base.scored = score, true
+}
+
+// OpponentMove ignores the opponent's move by default (implements BotInterface)
+func (base *BaseBot) OpponentMove(move string) {}
+
+// Close does nothing by default (implements BotInterface)
+func (base *BaseBot) Close() {}
+
+// Move is a move played by a bot
+type Move struct {
+	Name   string // move in board notation, e.g. "A1"
+	Coords [3]int // coordinates where the piece landed
+}
+
+// ErrNoValidMoves is returned by MakeMove when the board has no legal moves left
+var ErrNoValidMoves = errors.New("no valid moves")
+
+// SEARCH_YIELD_INTERVAL is how many polls of SearchCancelled a search makes between yields on WebAssembly
+const SEARCH_YIELD_INTERVAL = 1024
+
+// searchPolls counts the polls of SearchCancelled, on WebAssembly only
+var searchPolls int
+
+// SearchCancelled reports whether ctx has been cancelled, without blocking
+// Search loops poll this between moves so that a cancelled context stops them promptly
+// WebAssembly has no preemption, so a search there yields now and then to let the timer of a deadline fire
+func SearchCancelled(ctx context.Context) bool {
+	if runtime.GOARCH == "wasm" {
+		if searchPolls++; searchPolls%SEARCH_YIELD_INTERVAL == 0 {
+			runtime.Gosched()
+		}
+	}
+	select {
+	case <-ctx.Done():
+		return true
+	default:
+		return false
+	}
+}
+
+// ConfigOf returns the configuration a bot was created from, or nil if it cannot be recreated
+func ConfigOf(bot BotInterface) *BotConfig {
+	if configured, ok := bot.(interface{ Config() *BotConfig }); ok {
+		return configured.Config()
+	}
+	return nil
+}
+
+// ScoreOf returns the score ('x' perspective) bot's last search gave the move it played, if the bot reports one
+func ScoreOf(bot BotInterface) (int, bool) {
+	if scorer, ok := bot.(interface{ LastScore() (int, bool) }); ok {
+		return scorer.LastScore()
+	}
+	return 0, false
+}
+
+// FirstMove returns the first move of a line, or "" if the line is empty
+func FirstMove(line []string) string {
+	if len(line) == 0 {
+		return ""
+	}
+	return line[0]
+}
+
+// PlayChosenMove plays a bot's chosen move on the board
+// Returns ctx's error if the search was interrupted (the move is then not played),
+// or ErrNoValidMoves if no move was chosen
+func PlayChosenMove(ctx context.Context, board *engine.Board, symbol byte, move string) (Move, error) {
+	if err := ctx.Err(); err != nil {
+		searchLog.Load().Debug("search stopped before choosing a move", "player", string(symbol), "reason", err)
+		return Move{}, err
+	}
+	if move == "" {
+		return Move{}, ErrNoValidMoves
+	}
+	coords := board.Move(move, symbol)
+	if coords[0] == -1 {
+		searchLog.Load().Warn("search chose an illegal move", "player", string(symbol), "move", move)
+		return Move{}, fmt.Errorf("illegal move %q", move)
+	}
+	searchLog.Load().Debug("move chosen", "player", string(symbol), "move", move, "score", board.Score)
+	return Move{Name: move, Coords: coords}, nil
+}
+
+// LegacyBot is the bot signature used before MakeMove took a context
+type LegacyBot interface {
+	MakeMove(board *engine.Board) (string, [3]int)
+	Name() string
+	Symbol() byte
+}
+
+// legacyBotAdapter wraps a LegacyBot so it satisfies BotInterface
+// The legacy search itself cannot be interrupted, so the context is only checked before it starts
+type legacyBotAdapter struct {
+	bot LegacyBot
+}
+
+// AdaptLegacyBot converts a bot with the old MakeMove signature to BotInterface
+// OpponentMove and Close are forwarded if the legacy bot implements them
+func AdaptLegacyBot(bot LegacyBot) BotInterface {
+	return &legacyBotAdapter{bot: bot}
+}
+
+// MakeMove implements BotInterface
+func (adapter *legacyBotAdapter) MakeMove(ctx context.Context, board *engine.Board) (Move, error) {
+	if err := ctx.Err(); err != nil {
+		return Move{}, err
+	}
+	move, coords := adapter.bot.MakeMove(board)
+	if coords[0] == -1 {
+		return Move{}, ErrNoValidMoves
+	}
+	return Move{Name: move, Coords: coords}, nil
+}
+
+// Name implements BotInterface
+func (adapter *legacyBotAdapter) Name() string {
+	return adapter.bot.Name()
+}
+
+// Symbol implements BotInterface
+func (adapter *legacyBotAdapter) Symbol() byte {
+	return adapter.bot.Symbol()
+}
+
+// OpponentMove implements BotInterface
+func (adapter *legacyBotAdapter) OpponentMove(move string) {
+	if listener, ok := adapter.bot.(interface{ OpponentMove(string) }); ok {
+		listener.OpponentMove(move)
+	}
+}
+
+// Close implements BotInterface
+func (adapter *legacyBotAdapter) Close() {
+	if closer, ok := adapter.bot.(interface{ Close() }); ok {
+		closer.Close()
+	}
+}
+
+// Spec converts the bot configuration to a bot spec string (see ParseBotSpec)
+func (bc *BotConfig) Spec() string {
+	if len(bc.Params) == 0 {
+		return bc.Type
+	}
+
+	// Sort keys so the generated spec is stable
+	keys := make([]string, 0, len(bc.Params))
+	for key := range bc.Params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = fmt.Sprintf("%s=%d", key, bc.Params[key])
+	}
+	return bc.Type + ":" + strings.Join(pairs, ",")
+}
@@ -0,0 +1,174 @@
+package bots
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// BotFactory creates a bot from fully resolved parameters (defaults merged with overrides)
+type BotFactory func(symbol byte, name string, params map[string]int) BotInterface
+
+// BotRegistration describes a bot type that can be selected by name from menus, flags and profiles
+type BotRegistration struct {
+	Key         string                            // name used in bot specs, e.g. "alphabeta"
+	DisplayName string                            // name shown in menus, e.g. "AlphaBetaMinimaxBot"
+	Description string                            // short description shown in menus
+	Order       int                               // position in menus (lower first)
+	Defaults    map[string]int                    // accepted parameters and their default values
+	Check       func(params map[string]int) error // optional check of the resolved parameters beyond their ranges
+	New         BotFactory
+}
+
+// botRegistry holds all registered bot types keyed by lowercase Key
+var botRegistry = make(map[string]*BotRegistration)
+
+// RegisterBot adds a bot type to the registry; bots call this from their init functions
+func RegisterBot(registration *BotRegistration) {
+	key := strings.ToLower(registration.Key)
+	if _, exists := botRegistry[key]; exists {
+		panic(fmt.Sprintf("bot %q registered twice", registration.Key))
+	}
+	botRegistry[key] = registration
+}
+
+// LookupBot finds a registered bot type by key (case-insensitive)
+func LookupBot(key string) (*BotRegistration, bool) {
+	registration, exists := botRegistry[strings.ToLower(strings.TrimSpace(key))]
+	return registration, exists
+}
+
+// RegisteredBots returns all registered bot types in menu order
+func RegisteredBots() []*BotRegistration {
+	registrations := make([]*BotRegistration, 0, len(botRegistry))
+	for _, registration := range botRegistry {
+		registrations = append(registrations, registration)
+	}
+	sort.Slice(registrations, func(i, j int) bool {
+		if registrations[i].Order != registrations[j].Order {
+			return registrations[i].Order < registrations[j].Order
+		}
+		return registrations[i].Key < registrations[j].Key
+	})
+	return registrations
+}
+
+// RegisteredBotKeys returns the spec names of all registered bots in menu order
+func RegisteredBotKeys() []string {
+	registrations := RegisteredBots()
+	keys := make([]string, len(registrations))
+	for i, registration := range registrations {
+		keys[i] = registration.Key
+	}
+	return keys
+}
+
+// Create builds a bot, applying params over the registered defaults
+// Parameters the bot does not declare in Defaults are rejected, and so are values out of range (see paramRanges)
+func (registration *BotRegistration) Create(symbol byte, name string, params map[string]int) (BotInterface, error) {
+	resolved := make(map[string]int, len(registration.Defaults))
+	for key, value := range registration.Defaults {
+		resolved[key] = value
+	}
+	for key, value := range params {
+		if _, known := registration.Defaults[key]; !known {
+			return nil, fmt.Errorf("unknown parameter %q for bot %q", key, registration.Key)
+		}
+		resolved[key] = value
+	}
+	if err := checkParams(registration.Key, resolved); err != nil {
+		return nil, err
+	}
+	if registration.Check != nil {
+		if err := registration.Check(resolved); err != nil {
+			return nil, fmt.Errorf("bot %q: %v", registration.Key, err)
+		}
+	}
+
+	bot := registration.New(symbol, name, resolved)
+	if configurable, ok := bot.(interface{ SetConfig(*BotConfig) }); ok {
+		configurable.SetConfig(&BotConfig{Type: registration.Key, Name: name, Params: resolved})
+	}
+	return bot, nil
+}
+
+// MAX_SEARCH_DEPTH is the deepest search a bot's "depth" parameter can ask for
+const MAX_SEARCH_DEPTH = 64
+
+// MAX_BOT_ELO is the highest rating the "elo" parameter of a strength-limited bot can aim at
+const MAX_BOT_ELO = 4000
+
+// paramRange is the values a bot parameter takes, from min to max
+type paramRange struct {
+	min, max int
+}
+
+// paramRanges bounds the parameters the registered bots share; Create rejects values outside them, so that a bad
+// spec fails instead of building a bot that cannot play. The evaluator's parameters are checked by Evaluator.Validate
+var paramRanges = map[string]paramRange{
+	"depth":     {1, MAX_SEARCH_DEPTH},
+	"movetime":  {0, math.MaxInt},
+	"forcing":   {0, MAX_SEARCH_DEPTH},
+	"elo":       {0, MAX_BOT_ELO},
+	"base":      {2, math.MaxInt},
+	"tt":        {0, MAX_TT_SIZE},
+	"ttpolicy":  {0, len(replacementPolicyNames)},
+	"ttlocking": {0, len(ttLockingNames)},
+	"ttshards":  {0, MAX_TT_SHARDS},
+}
+
+// checkParams reports an error for the first of a bot's resolved parameters that is out of range
+func checkParams(key string, params map[string]int) error {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		limits, bounded := paramRanges[name]
+		value := params[name]
+		switch {
+		case !bounded || value >= limits.min && value <= limits.max:
+		case limits.max == math.MaxInt:
+			return fmt.Errorf("parameter %s of bot %q must be at least %d, got %d", name, key, limits.min, value)
+		default:
+			return fmt.Errorf("parameter %s of bot %q must be between %d and %d, got %d", name, key, limits.min, limits.max, value)
+		}
+	}
+	if _, evaluates := params["playability"]; evaluates {
+		if err := evaluatorFromParams(params).Validate(); err != nil {
+			return fmt.Errorf("bot %q: %v", key, err)
+		}
+	}
+	return nil
+}
+
+// ParseBotSpec splits a bot spec such as "alphabeta:depth=6,base=10" into its name and parameters
+func ParseBotSpec(spec string) (string, map[string]int, error) {
+	name, paramStr, _ := strings.Cut(spec, ":")
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return "", nil, fmt.Errorf("empty bot name in spec %q", spec)
+	}
+
+	params := make(map[string]int)
+	if paramStr == "" {
+		return name, params, nil
+	}
+
+	for _, pair := range strings.Split(paramStr, ",") {
+		key, value, found := strings.Cut(pair, "=")
+		if !found {
+			return "", nil, fmt.Errorf("bot parameter %q is not in key=value form", pair)
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return "", nil, fmt.Errorf("bot parameter %q: %v", pair, err)
+		}
+		params[strings.ToLower(strings.TrimSpace(key))] = n
+	}
+
+	return name, params, nil
+}
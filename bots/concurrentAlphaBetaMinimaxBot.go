@@ -0,0 +1,740 @@
+package bots
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"tic-tac-toe-3d-bots/engine"
+)
+
+// ConcurrentAlphaBetaMinimaxBot represents a concurrent minimax AI player with alpha-beta pruning
+type ConcurrentAlphaBetaMinimaxBot struct {
+	BaseBot
+	Depth     int
+	Evaluator engine.Evaluator      // How the bot scores positions, whatever the board's own evaluator
+	tt        *SharedTT             // Positions searched by the sequential searches at the tree's leaves, kept from one move to the next
+	progress  func(SearchIteration) // Receives each iteration of the search while set, see ReportProgress
+}
+
+// NewConcurrentAlphaBetaMinimaxBot creates a new concurrent alpha-beta minimax bot
+// Its parallel searches share a transposition table of tt MiB (see NewSharedTT for the options)
+func NewConcurrentAlphaBetaMinimaxBot(symbol byte, name string, depth int, evaluator engine.Evaluator, tt *SharedTT) *ConcurrentAlphaBetaMinimaxBot {
+	return &ConcurrentAlphaBetaMinimaxBot{
+		BaseBot:   NewBaseBot(symbol, name),
+		Depth:     depth,
+		Evaluator: evaluator,
+		tt:        tt,
+	}
+}
+
+// init registers ConcurrentAlphaBetaMinimaxBot with the bot registry
+func init() {
+	RegisterBot(&BotRegistration{
+		Key:         "concurrentalphabeta",
+		DisplayName: "ConcurrentAlphaBetaMinimaxBot",
+		Description: "concurrent alpha-beta pruning",
+		Order:       7,
+		// tt, ttpolicy and ttlocking (1 lock-free, 2 sharded) take TTSize, TTPolicy and TTLockingDefault at 0, ttshards DEFAULT_TT_SHARDS
+		Defaults: withEvaluatorDefaults(map[string]int{"depth": 6, "tt": 0, "ttpolicy": 0, "ttlocking": 0, "ttshards": 0}),
+		Check: func(params map[string]int) error {
+			return checkTTShards(params["tt"], params["ttshards"])
+		},
+		New: func(symbol byte, name string, params map[string]int) BotInterface {
+			tt, _ := NewSharedTT(params["tt"], ttPolicyFromParams(params), ttLockingFromParams(params), params["ttshards"]) // Checked by Check
+			return NewConcurrentAlphaBetaMinimaxBot(symbol, name, params["depth"], evaluatorFromParams(params), tt)
+		},
+	})
+}
+
+// MakeMove makes a move using streaming concurrent alpha-beta pruning minimax algorithm (implements BotInterface)
+func (bot *ConcurrentAlphaBetaMinimaxBot) MakeMove(ctx context.Context, board *engine.Board) (Move, error) {
+	defer useEvaluator(board, bot.Evaluator)()
+	if bot.tt != nil {
+		bot.tt.Prepare(board)
+	}
+
+	// Use streaming concurrent minimax; only the final answer matters here, so a mailbox suffices
+	searchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	searchCtx = traceRootMoves(searchCtx)
+	if bot.tt != nil {
+		searchCtx = withSearchTable(searchCtx, bot.tt)
+	}
+	var bestMove string
+	if bot.progress == nil {
+		bestMove, _ = bot.search(board, bot.Depth, searchCtx)
+	} else {
+		// Deepen one ply at a time, each iteration finding the table filled by the last one
+		for depth := 1; depth <= bot.Depth && !SearchCancelled(ctx); depth++ {
+			move, score := bot.search(board, depth, searchCtx)
+			if move == "" || SearchCancelled(ctx) {
+				break
+			}
+			bestMove = move
+			bot.progress(SearchIteration{Depth: depth, Score: score, Line: bot.line(board, move, depth)})
+		}
+	}
+
+	if bot.tt != nil {
+		stats := bot.tt.Stats()
+		searchLog.Load().Debug("transposition table", "player", string(bot.Symbol()), "policy", stats.Policy, "locking", stats.Locking,
+			"fill", stats.FillRate(), "hit_rate", stats.HitRate(), "cutoffs", stats.Cutoffs, "contended", stats.Contended,
+			"lock_wait_ms", stats.LockWaitMS)
+	}
+	return PlayChosenMove(ctx, board, bot.Symbol(), bestMove)
+}
+
+// search runs the streaming concurrent search of board at depth, returning the best move and its score
+func (bot *ConcurrentAlphaBetaMinimaxBot) search(board *engine.Board, depth int, ctx context.Context) (string, int) {
+	var table searchTable
+	if bot.tt != nil {
+		table = bot.tt
+	}
+	ctx, endSpan := startIterationSpan(ctx, board, depth, table)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	resultCh := concurrentAlphaBetaMinimaxStream(board, depth, bot.Symbol() == 'x', StreamBuffering{Mode: BufferLatestWins}, ctx)
+
+	var bestMove string
+	var bestScore int
+
+	// Listen to the stream until we get the final result
+	for result := range resultCh {
+		if result.Final {
+			bestMove, bestScore = result.Move, result.Score
+			bot.reportScore(result.Score)
+			break
+		}
+		// Keep updating with better moves as they're found
+		bestMove, bestScore = result.Move, result.Score
+	}
+
+	// Pruned searches may still be running: wait for them to stop, so the next search's do not share the table with them
+	cancel()
+	for range resultCh {
+	}
+	endSpan(bestScore, bestMove)
+	return bestMove, bestScore
+}
+
+// line returns the principal variation of an iteration at depth that chose move: the move, then as much of the line
+// after it as the table holds, which only the sequential searches at the tree's leaves fill
+func (bot *ConcurrentAlphaBetaMinimaxBot) line(board *engine.Board, move string, depth int) []string {
+	line := []string{move}
+	if bot.tt == nil || board.Move(move, bot.Symbol())[0] == -1 {
+		return line
+	}
+	defer board.UnMove(move)
+	return append(line, ttLine(board, bot.tt, engine.OpponentSymbol(bot.Symbol()), depth-1)...)
+}
+
+// ReportProgress has the bot search by iterative deepening, passing each completed iteration to report, or search at
+// its depth at once again if report is nil (implements ProgressReporter)
+func (bot *ConcurrentAlphaBetaMinimaxBot) ReportProgress(report func(SearchIteration)) {
+	bot.progress = report
+}
+
+// TTStats returns the size, fill and contention of the bot's transposition table
+func (bot *ConcurrentAlphaBetaMinimaxBot) TTStats() TTStats {
+	if bot.tt == nil {
+		return TTStats{}
+	}
+	return bot.tt.Stats()
+}
+
+// Close gives the memory of the bot's transposition table back to the search memory budget (implements BotInterface)
+func (bot *ConcurrentAlphaBetaMinimaxBot) Close() {
+	if bot.tt != nil {
+		bot.tt.Release()
+	}
+}
+
+// searchTableKey marks a context with the transposition table the sequential searches of a parallel search share
+type searchTableKey struct{}
+
+// withSearchTable returns a context whose searches remember positions in table (see searchTableFrom)
+func withSearchTable(ctx context.Context, table searchTable) context.Context {
+	return context.WithValue(ctx, searchTableKey{}, table)
+}
+
+// searchTableFrom returns the transposition table of ctx's search, nil if it has none
+func searchTableFrom(ctx context.Context) searchTable {
+	table, _ := ctx.Value(searchTableKey{}).(searchTable)
+	return table
+}
+
+// StreamResult represents a streaming result from minimax evaluation
+type StreamResult struct {
+	Move  string
+	Score int
+	Final bool // true if this is the final result for this branch
+}
+
+// MultiDepthStreamResult represents a streaming result from multiple depth evaluation
+type MultiDepthStreamResult struct {
+	Moves []string // sequence of moves
+	Score int
+	Depth int
+	Final bool // true if this is the final result
+}
+
+// StreamBufferMode selects how a stream channel behaves when its consumer falls behind
+type StreamBufferMode int
+
+const (
+	BufferBounded    StreamBufferMode = iota // producer blocks once Size results are queued
+	BufferLatestWins                         // single-slot mailbox where unread results are replaced by newer ones
+)
+
+// StreamBuffering configures the result channel of a streaming search
+// Every producer gives up as soon as its context is cancelled, so a consumer that stops
+// reading early only needs to cancel the context it passed in for all goroutines to exit
+type StreamBuffering struct {
+	Mode StreamBufferMode
+	Size int // channel capacity in BufferBounded mode (0 means unbuffered)
+}
+
+// DefaultStreamBuffering is a small bounded buffer, matching the historical behaviour
+var DefaultStreamBuffering = StreamBuffering{Mode: BufferBounded, Size: 10}
+
+// NewStreamChannel creates a result channel sized for the given buffering strategy
+func NewStreamChannel[T any](buffering StreamBuffering) chan T {
+	if buffering.Mode == BufferLatestWins {
+		return make(chan T, 1)
+	}
+	if buffering.Size < 0 {
+		return make(chan T)
+	}
+	return make(chan T, buffering.Size)
+}
+
+// SendStream delivers value on ch according to the buffering strategy
+// Returns false if ctx was cancelled before the value could be delivered
+// Latest-wins sends never block: a stale unread value is discarded in favour of the new one.
+// This relies on each stream channel having exactly one producer goroutine
+func SendStream[T any](ctx context.Context, ch chan T, value T, buffering StreamBuffering) bool {
+	if buffering.Mode == BufferLatestWins {
+		for {
+			select {
+			case <-ctx.Done():
+				return false
+			case ch <- value:
+				return true
+			default:
+			}
+			// Mailbox is full, drop the stale value and retry
+			select {
+			case <-ch:
+			default:
+			}
+		}
+	}
+
+	select {
+	case <-ctx.Done():
+		return false
+	case ch <- value:
+		return true
+	}
+}
+
+// concurrentAlphaBetaMinimaxStream performs streaming concurrent minimax with alpha-beta pruning
+// Returns a channel that continuously emits better moves as they're discovered
+func concurrentAlphaBetaMinimaxStream(board *engine.Board, depth int, isMaximizing bool, buffering StreamBuffering, parentCtx context.Context) <-chan StreamResult {
+	resultCh := NewStreamChannel[StreamResult](buffering)
+	if parentCtx == nil {
+		parentCtx = context.Background()
+	}
+
+	go func() {
+		defer close(resultCh)
+
+		// Check for winning conditions first
+		winner := board.CheckWin()
+		if winner != '|' {
+			if winner == 'x' {
+				SendStream(parentCtx, resultCh, StreamResult{Move: "", Score: engine.MAX_INT / 2, Final: true}, buffering)
+			} else {
+				SendStream(parentCtx, resultCh, StreamResult{Move: "", Score: engine.MIN_INT / 2, Final: true}, buffering)
+			}
+			return
+		}
+
+		if depth == 0 {
+			SendStream(parentCtx, resultCh, StreamResult{Move: "", Score: board.Score, Final: true}, buffering)
+			return
+		}
+
+		validMoves := board.GetValidMoves()
+		if len(validMoves) == 0 {
+			SendStream(parentCtx, resultCh, StreamResult{Move: "", Score: board.Score, Final: true}, buffering)
+			return
+		}
+
+		// For small cases, use sequential to avoid overhead
+		if len(validMoves) <= 2 || depth <= 2 {
+			threshold := engine.MAX_INT
+			if !isMaximizing {
+				threshold = engine.MIN_INT
+			}
+			var score int
+			var move string
+			if table := searchTableFrom(parentCtx); table != nil {
+				score, move = alphaBetaTT(board, depth, isMaximizing, threshold, table, parentCtx)
+			} else {
+				var moves []string
+				score, moves = AlphaBetaMinimax(board, depth, isMaximizing, threshold, parentCtx)
+				move = FirstMove(moves)
+			}
+			SendStream(parentCtx, resultCh, StreamResult{Move: move, Score: score, Final: true}, buffering)
+			return
+		}
+
+		// Streaming concurrent evaluation
+		symbol := byte('x')
+		bestScore := engine.MIN_INT
+		if !isMaximizing {
+			symbol = 'o'
+			bestScore = engine.MAX_INT
+		}
+
+		var bestMove string
+
+		// Context for cancellation; the stream only closes once every child search has stopped
+		ctx, cancel := context.WithCancel(parentCtx)
+		var wg sync.WaitGroup
+		defer wg.Wait()
+		defer cancel()
+
+		// Channel to collect child results
+		childResults := make(chan StreamResult, len(validMoves)*2) // Buffer for multiple results per child
+
+		// Launch goroutines for each move
+		for _, move := range validMoves {
+			wg.Add(1)
+			go func(move string) {
+				defer wg.Done()
+
+				// Create a deep copy for this move
+				testBoard := engine.CopyBoard(board)
+				testBoard.Move(move, symbol)
+
+				// Start streaming evaluation for this child
+				childCtx, span := startRootMoveSpan(ctx, move)
+				defer span.End()
+				childCh := concurrentAlphaBetaMinimaxStream(testBoard, depth-1, !isMaximizing, buffering, childCtx)
+				defer func() {
+					for range childCh { // Until the child's search has stopped
+					}
+				}()
+
+				// Forward all results from child, tagging with the move
+				for childResult := range childCh {
+					span.SetAttributes("score", childResult.Score, "final", childResult.Final)
+					select {
+					case <-ctx.Done():
+						return // Pruned by parent
+					case childResults <- StreamResult{
+						Move:  move,
+						Score: childResult.Score,
+						Final: childResult.Final,
+					}:
+					}
+
+					// Stop if child sent final result
+					if childResult.Final {
+						break
+					}
+				}
+			}(move)
+		}
+
+		// Close results channel when all workers are done
+		go func() {
+			wg.Wait()
+			close(childResults)
+		}()
+
+		// Process streaming results from all children
+		activeMoves := make(map[string]bool)
+		for _, move := range validMoves {
+			activeMoves[move] = true
+		}
+
+		for result := range childResults {
+			// Check if this result improves our best score
+			improved := false
+			if isMaximizing && result.Score > bestScore {
+				bestScore = result.Score
+				bestMove = result.Move
+				improved = true
+			} else if !isMaximizing && result.Score < bestScore {
+				bestScore = result.Score
+				bestMove = result.Move
+				improved = true
+			}
+
+			// Stream the improvement to parent
+			if improved {
+				if !SendStream(parentCtx, resultCh, StreamResult{Move: bestMove, Score: bestScore, Final: false}, buffering) {
+					return // Parent cancelled us
+				}
+
+				// Check if we can prune remaining children (using reasonable thresholds)
+				if (isMaximizing && bestScore >= engine.MAX_INT/3) || (!isMaximizing && bestScore <= engine.MIN_INT/3) {
+					cancel() // Signal children to stop
+					break
+				}
+			}
+
+			// If this was a final result for this move, mark it as complete
+			if result.Final {
+				delete(activeMoves, result.Move)
+
+				// If all moves are complete, we're done
+				if len(activeMoves) == 0 {
+					break
+				}
+			}
+		}
+
+		// Send final result
+		if !SendStream(parentCtx, resultCh, StreamResult{Move: bestMove, Score: bestScore, Final: true}, buffering) {
+			return
+		}
+	}()
+
+	return resultCh
+}
+
+// SequenceStreamResult represents a streaming result with move sequences
+type SequenceStreamResult struct {
+	Moves []string
+	Score int
+	Final bool
+}
+
+// concurrentAlphaBetaMinimaxStreamWithSequence performs streaming concurrent minimax that tracks move sequences
+func concurrentAlphaBetaMinimaxStreamWithSequence(board *engine.Board, depth int, isMaximizing bool, buffering StreamBuffering, parentCtx context.Context) <-chan SequenceStreamResult {
+	resultCh := NewStreamChannel[SequenceStreamResult](buffering)
+	if parentCtx == nil {
+		parentCtx = context.Background()
+	}
+
+	go func() {
+		defer close(resultCh)
+
+		// Check for winning conditions first
+		winner := board.CheckWin()
+		if winner != '|' {
+			if winner == 'x' {
+				SendStream(parentCtx, resultCh, SequenceStreamResult{Moves: []string{}, Score: engine.MAX_INT / 2, Final: true}, buffering)
+			} else {
+				SendStream(parentCtx, resultCh, SequenceStreamResult{Moves: []string{}, Score: engine.MIN_INT / 2, Final: true}, buffering)
+			}
+			return
+		}
+
+		if depth == 0 {
+			SendStream(parentCtx, resultCh, SequenceStreamResult{Moves: []string{}, Score: board.Score, Final: true}, buffering)
+			return
+		}
+
+		validMoves := board.GetValidMoves()
+		if len(validMoves) == 0 {
+			SendStream(parentCtx, resultCh, SequenceStreamResult{Moves: []string{}, Score: board.Score, Final: true}, buffering)
+			return
+		}
+
+		// For small cases, use sequential
+		if len(validMoves) <= 2 || depth <= 2 {
+			threshold := engine.MAX_INT
+			if !isMaximizing {
+				threshold = engine.MIN_INT
+			}
+			score, moves := AlphaBetaMinimax(board, depth, isMaximizing, threshold, parentCtx)
+			SendStream(parentCtx, resultCh, SequenceStreamResult{Moves: moves, Score: score, Final: true}, buffering)
+			return
+		}
+
+		// Streaming concurrent evaluation with sequence tracking
+		symbol := byte('x')
+		bestScore := engine.MIN_INT
+		if !isMaximizing {
+			symbol = 'o'
+			bestScore = engine.MAX_INT
+		}
+
+		var bestMoves []string
+
+		// Context for cancellation
+		ctx, cancel := context.WithCancel(parentCtx)
+		defer cancel()
+
+		// Channel to collect child results
+		childResults := make(chan SequenceStreamResult, len(validMoves)*2)
+		var wg sync.WaitGroup
+
+		// Launch goroutines for each move
+		for _, move := range validMoves {
+			wg.Add(1)
+			go func(move string) {
+				defer wg.Done()
+
+				// Create a deep copy for this move
+				testBoard := engine.CopyBoard(board)
+				testBoard.Move(move, symbol)
+
+				// Start streaming evaluation for this child
+				childCtx, span := startRootMoveSpan(ctx, move)
+				defer span.End()
+				childCh := concurrentAlphaBetaMinimaxStreamWithSequence(testBoard, depth-1, !isMaximizing, buffering, childCtx)
+
+				// Forward all results from child, prepending current move
+				for childResult := range childCh {
+					span.SetAttributes("score", childResult.Score, "final", childResult.Final)
+					select {
+					case <-ctx.Done():
+						return // Pruned by parent
+					case childResults <- SequenceStreamResult{
+						Moves: append([]string{move}, childResult.Moves...),
+						Score: childResult.Score,
+						Final: childResult.Final,
+					}:
+					}
+
+					// Stop if child sent final result
+					if childResult.Final {
+						break
+					}
+				}
+			}(move)
+		}
+
+		// Close results channel when all workers are done
+		go func() {
+			wg.Wait()
+			close(childResults)
+		}()
+
+		// Process streaming results from all children
+		activeMoves := make(map[string]bool)
+		for _, move := range validMoves {
+			activeMoves[move] = true
+		}
+
+		for result := range childResults {
+			// Check if this result improves our best score
+			improved := false
+			if isMaximizing && result.Score > bestScore {
+				bestScore = result.Score
+				bestMoves = result.Moves
+				improved = true
+			} else if !isMaximizing && result.Score < bestScore {
+				bestScore = result.Score
+				bestMoves = result.Moves
+				improved = true
+			}
+
+			// Stream the improvement to parent
+			if improved {
+				if !SendStream(parentCtx, resultCh, SequenceStreamResult{Moves: bestMoves, Score: bestScore, Final: false}, buffering) {
+					return // Parent cancelled us
+				}
+
+				// Check if we can prune remaining children
+				if (isMaximizing && bestScore >= engine.MAX_INT/3) || (!isMaximizing && bestScore <= engine.MIN_INT/3) {
+					cancel() // Signal children to stop
+					break
+				}
+			}
+
+			// If this was a final result for this move, mark it as complete
+			if result.Final && len(result.Moves) > 0 {
+				delete(activeMoves, result.Moves[0])
+
+				// If all moves are complete, we're done
+				if len(activeMoves) == 0 {
+					break
+				}
+			}
+		}
+
+		// Send final result
+		if !SendStream(parentCtx, resultCh, SequenceStreamResult{Moves: bestMoves, Score: bestScore, Final: true}, buffering) {
+			return
+		}
+	}()
+
+	return resultCh
+}
+
+// StreamThrottle controls how intermediate stream results are coalesced before being forwarded
+// The zero value forwards every result that differs from the previously forwarded one
+type StreamThrottle struct {
+	MinInterval   time.Duration // minimum time between forwarded intermediate results
+	MinScoreDelta int           // minimum score change that counts as material when the PV is unchanged
+}
+
+// shouldForward reports whether an intermediate result differs materially from the last forwarded one
+func (throttle StreamThrottle) shouldForward(last, next MultiDepthStreamResult, lastSent time.Time) bool {
+	if !lastSent.IsZero() && time.Since(lastSent) < throttle.MinInterval {
+		return false
+	}
+	if !sameMoves(last.Moves, next.Moves) {
+		return true
+	}
+	delta := next.Score - last.Score
+	if delta < 0 {
+		delta = -delta
+	}
+	if throttle.MinScoreDelta <= 0 {
+		return delta != 0
+	}
+	return delta >= throttle.MinScoreDelta
+}
+
+// sameMoves reports whether two move sequences are identical
+func sameMoves(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// MultiDepthAlphaBetaStream performs concurrent alpha-beta with multiple depths
+// Returns a channel that streams the best moves found by different depth bots,
+// coalescing intermediate results according to throttle (the final result is always sent)
+// Cancelling parentCtx stops every search goroutine, so consumers may stop reading at any time
+func MultiDepthAlphaBetaStream(board *engine.Board, isMaximizing bool, depths []int, throttle StreamThrottle, buffering StreamBuffering, parentCtx context.Context) <-chan MultiDepthStreamResult {
+	resultCh := NewStreamChannel[MultiDepthStreamResult](buffering)
+	if parentCtx == nil {
+		parentCtx = context.Background()
+	}
+
+	go func() {
+		defer close(resultCh)
+
+		ctx, cancel := context.WithCancel(parentCtx)
+		defer cancel()
+
+		// Channel to collect results from all depth bots
+		depthResults := make(chan MultiDepthStreamResult, len(depths)*5)
+		var wg sync.WaitGroup
+
+		// Launch a bot for each depth
+		for _, depth := range depths {
+			wg.Add(1)
+			go func(depth int) {
+				defer wg.Done()
+				depthCtx, span := startSpan(ctx, "depth iteration", "depth", depth)
+				defer span.End()
+
+				// Get streaming results from this depth, on a board of its own as shallow searches play moves on it
+				streamCh := concurrentAlphaBetaMinimaxStreamWithSequence(engine.CopyBoard(board), depth, isMaximizing, buffering, traceRootMoves(depthCtx))
+
+				// Forward results with depth information
+				for result := range streamCh {
+					span.SetAttributes("score", result.Score, "line", result.Moves, "final", result.Final)
+					select {
+					case <-ctx.Done():
+						return
+					case depthResults <- MultiDepthStreamResult{
+						Moves: result.Moves,
+						Score: result.Score,
+						Depth: depth,
+						Final: result.Final,
+					}:
+					}
+				}
+			}(depth)
+		}
+
+		// Close results channel when all workers are done
+		go func() {
+			wg.Wait()
+			close(depthResults)
+		}()
+
+		// Track best results and active depths
+		bestScore := engine.MIN_INT
+		if !isMaximizing {
+			bestScore = engine.MAX_INT
+		}
+		var bestMoves []string
+		bestDepth := 0
+		var lastForwarded MultiDepthStreamResult
+		var lastSent time.Time
+		activeDepths := make(map[int]bool)
+		for _, depth := range depths {
+			activeDepths[depth] = true
+		}
+
+		// Process results as they stream in
+		for result := range depthResults {
+			// Check if this result improves our best result
+			// Priority: Deeper depth wins if scores are equal or better
+			improved := false
+
+			if isMaximizing {
+				// For maximizing: better score OR (same/better score + deeper depth)
+				if result.Depth > bestDepth ||
+					(result.Score > bestScore && result.Depth == bestDepth) {
+					bestScore = result.Score
+					bestMoves = result.Moves
+					bestDepth = result.Depth
+					improved = true
+				}
+			} else {
+				// For minimizing: better score OR (same/better score + deeper depth)
+				if result.Depth > bestDepth ||
+					(result.Score < bestScore && result.Depth == bestDepth) {
+					bestScore = result.Score
+					bestMoves = result.Moves
+					bestDepth = result.Depth
+					improved = true
+				}
+			}
+
+			// Stream the improvement if it differs materially from what the consumer last saw
+			current := MultiDepthStreamResult{
+				Moves: bestMoves,
+				Score: bestScore,
+				Depth: bestDepth,
+				Final: false,
+			}
+			if improved && throttle.shouldForward(lastForwarded, current, lastSent) {
+				if !SendStream(ctx, resultCh, current, buffering) {
+					return
+				}
+				lastForwarded = current
+				lastSent = time.Now()
+			}
+
+			// If this was a final result for this depth, mark it as complete
+			if result.Final {
+				delete(activeDepths, result.Depth)
+				searchLog.Load().Debug("depth finished", "depth", result.Depth, "score", result.Score, "line", result.Moves, "pending", len(activeDepths))
+
+				// If all depths are complete, send final result and exit
+				if len(activeDepths) == 0 {
+					SendStream(ctx, resultCh, MultiDepthStreamResult{
+						Moves: bestMoves,
+						Score: bestScore,
+						Depth: bestDepth,
+						Final: true,
+					}, buffering)
+					return
+				}
+			}
+		}
+	}()
+
+	return resultCh
+}
@@ -0,0 +1,208 @@
+package bots
+
+import (
+	"context"
+	"runtime"
+	"sync"
+
+	"tic-tac-toe-3d-bots/engine"
+)
+
+// ConcurrentMinimaxBot represents a concurrent minimax AI player using goroutines at top level only
+type ConcurrentMinimaxBot struct {
+	BaseBot
+	Depth   int
+	Base    int // Base for exponential scoring (e.g., 2, 3, 4); 0 keeps the board's own
+	Workers int // Number of root-splitting workers (defaults to runtime.NumCPU())
+
+	Stats RootSplitStats // Statistics from the most recent search
+}
+
+// RootSplitStats records how the root moves were distributed across workers
+type RootSplitStats struct {
+	WorkerNodes  []int `json:"worker_nodes"`  // Positions searched by each worker
+	WorkerMoves  []int `json:"worker_moves"`  // Root moves evaluated by each worker
+	WorkerSteals []int `json:"worker_steals"` // Root moves each worker stole from another worker's deque
+}
+
+// NewConcurrentMinimaxBot creates a new concurrent minimax bot with the given symbol, name, and search depth
+func NewConcurrentMinimaxBot(symbol byte, name string, depth int, base int) *ConcurrentMinimaxBot {
+	return &ConcurrentMinimaxBot{
+		BaseBot: NewBaseBot(symbol, name),
+		Depth:   depth,
+		Base:    base,
+		Workers: runtime.NumCPU(),
+	}
+}
+
+// init registers ConcurrentMinimaxBot with the bot registry
+func init() {
+	RegisterBot(&BotRegistration{
+		Key:         "concurrent",
+		DisplayName: "ConcurrentMinimaxBot",
+		Description: "concurrent at top level",
+		Order:       5,
+		Defaults:    map[string]int{"depth": 6, "base": 10},
+		New: func(symbol byte, name string, params map[string]int) BotInterface {
+			return NewConcurrentMinimaxBot(symbol, name, params["depth"], params["base"])
+		},
+	})
+}
+
+// MoveResult represents the result of evaluating a move
+type MoveResult struct {
+	Move  string
+	Score int
+}
+
+// MakeMove makes a move using concurrent minimax algorithm (implements BotInterface)
+// Uses concurrency only at the top level for evaluating root moves, scoring lines in the bot's base
+func (bot *ConcurrentMinimaxBot) MakeMove(ctx context.Context, board *engine.Board) (Move, error) {
+	if bot.Base != 0 {
+		evaluator := board.Evaluator
+		evaluator.Base = bot.Base
+		defer useEvaluator(board, evaluator)()
+	}
+	validMoves := board.GetValidMoves()
+
+	// Use shallow concurrent minimax (top-level only)
+	bestMove, score, stats := concurrentMinimax(board, bot.Depth, bot.Symbol() == 'x', validMoves, bot.Workers, ctx)
+	bot.Stats = stats
+	if bestMove != "" {
+		bot.reportScore(score)
+	}
+	searchLog.Load().Debug("root split finished", "workers", len(stats.WorkerNodes), "nodes", stats.WorkerNodes, "steals", stats.WorkerSteals)
+	return PlayChosenMove(ctx, board, bot.Symbol(), bestMove)
+}
+
+// workDeque is a double-ended queue of root move indices owned by one worker
+// The owner pops from the back while other workers steal from the front
+type workDeque struct {
+	mutex sync.Mutex
+	tasks []int
+}
+
+// push adds a task to the back of the deque
+func (d *workDeque) push(task int) {
+	d.mutex.Lock()
+	d.tasks = append(d.tasks, task)
+	d.mutex.Unlock()
+}
+
+// pop removes a task from the back of the deque (owner side)
+func (d *workDeque) pop() (int, bool) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if len(d.tasks) == 0 {
+		return -1, false
+	}
+	task := d.tasks[len(d.tasks)-1]
+	d.tasks = d.tasks[:len(d.tasks)-1]
+	return task, true
+}
+
+// steal removes a task from the front of the deque (thief side)
+func (d *workDeque) steal() (int, bool) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if len(d.tasks) == 0 {
+		return -1, false
+	}
+	task := d.tasks[0]
+	d.tasks = d.tasks[1:]
+	return task, true
+}
+
+// concurrentMinimax evaluates all possible moves on a fixed pool of work-stealing workers
+// and returns the best one and its score together with per-worker statistics
+// A single move is searched too, for its score
+func concurrentMinimax(board *engine.Board, depth int, isMaximizing bool, validMoves []string, workers int, ctx context.Context) (string, int, RootSplitStats) {
+	if len(validMoves) == 0 {
+		return "", 0, RootSplitStats{}
+	}
+
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > len(validMoves) {
+		workers = len(validMoves)
+	}
+
+	// Deal root moves round-robin onto the worker deques
+	deques := make([]*workDeque, workers)
+	for w := range deques {
+		deques[w] = &workDeque{}
+	}
+	for i := range validMoves {
+		deques[i%workers].push(i)
+	}
+
+	stats := RootSplitStats{
+		WorkerNodes:  make([]int, workers),
+		WorkerMoves:  make([]int, workers),
+		WorkerSteals: make([]int, workers),
+	}
+
+	// Each root move writes its own slot, so no channel or lock is needed for results
+	scores := make([]int, len(validMoves))
+	var wg sync.WaitGroup
+
+	symbol := byte('x')
+	if !isMaximizing {
+		symbol = 'o'
+	}
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+
+			for !SearchCancelled(ctx) {
+				task, ok := deques[w].pop()
+				stolen := !ok
+				if !ok {
+					// Own deque is empty, try to steal from the other workers
+					for offset := 1; offset < workers && !ok; offset++ {
+						task, ok = deques[(w+offset)%workers].steal()
+					}
+					if !ok {
+						return // No work left anywhere (tasks are never added after start)
+					}
+					stats.WorkerSteals[w]++
+				}
+
+				// Create a deep copy of the board to test the move
+				testBoard := engine.CopyBoard(board)
+				testBoard.Move(validMoves[task], symbol)
+
+				// Evaluate this move using sequential minimax from this point
+				_, span := startSpan(ctx, "root move", "move", validMoves[task], "worker", w, "stolen", stolen)
+				nodes := 0
+				scores[task], _ = countedMinimax(testBoard, depth-1, !isMaximizing, &nodes, ctx)
+				span.SetAttributes("score", scores[task], "nodes", nodes)
+				span.End()
+
+				stats.WorkerNodes[w] += nodes
+				searchedNodes.Add(int64(nodes))
+				stats.WorkerMoves[w]++
+			}
+		}(w)
+	}
+
+	wg.Wait()
+
+	// Find the best move, scanning in move order so ties resolve deterministically
+	bestMove, bestScore := validMoves[0], scores[0]
+
+	for i, score := range scores {
+		if isMaximizing && score > bestScore {
+			bestScore = score
+			bestMove = validMoves[i]
+		} else if !isMaximizing && score < bestScore {
+			bestScore = score
+			bestMove = validMoves[i]
+		}
+	}
+
+	return bestMove, bestScore, stats
+}
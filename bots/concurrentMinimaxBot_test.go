@@ -0,0 +1,43 @@
+package bots
+
+import (
+	"context"
+	"testing"
+
+	"tic-tac-toe-3d-bots/engine"
+)
+
+func TestConcurrentMinimaxBotScore(t *testing.T) {
+	for _, base := range []int{3, 10} {
+		board, err := engine.New(engine.WithDims(3, 3, 3))
+		if err != nil {
+			t.Fatal(err)
+		}
+		board.Move("B2", board.NextPlayer())
+		board.Move("A1", board.NextPlayer())
+		original := board.Evaluator
+
+		// The bot searches lines scored in its own base, as minimax does on a board evaluating with that base
+		evaluator := board.Evaluator
+		evaluator.Base = base
+		reference, err := engine.New(engine.WithDims(3, 3, 3), engine.WithEvaluator(evaluator))
+		if err != nil {
+			t.Fatal(err)
+		}
+		reference.Move("B2", reference.NextPlayer())
+		reference.Move("A1", reference.NextPlayer())
+		want, _ := minimax(reference, 3, true, context.Background())
+
+		bot := NewConcurrentMinimaxBot('x', "concurrent", 3, base)
+		bot.Workers = 2
+		if _, err := bot.MakeMove(context.Background(), board); err != nil {
+			t.Fatalf("base %d: MakeMove: %v", base, err)
+		}
+		if score, ok := bot.LastScore(); !ok || score != want {
+			t.Errorf("base %d: LastScore = %d, %v, want %d", base, score, ok, want)
+		}
+		if board.Evaluator != original {
+			t.Errorf("base %d: the board's evaluator was left at %+v", base, board.Evaluator)
+		}
+	}
+}
@@ -1,66 +1,61 @@
-package main
+package bots
 
 import (
+	"context"
 	"sync"
+
+	"tic-tac-toe-3d-bots/engine"
 )
 
 // ConcurrentMinimaxDeepBot represents a fully concurrent minimax AI player using goroutines at all levels
 type ConcurrentMinimaxDeepBot struct {
-	Symbol byte
-	Name   string
-	Depth  int
-	Base   int // Base for exponential scoring (e.g., 2, 3, 4)
+	BaseBot
+	Depth int
+	Base  int // Base for exponential scoring (e.g., 2, 3, 4)
 }
 
 // NewConcurrentMinimaxDeepBot creates a new deep concurrent minimax bot with the given symbol, name, and search depth
 func NewConcurrentMinimaxDeepBot(symbol byte, name string, depth int, base int) *ConcurrentMinimaxDeepBot {
 	return &ConcurrentMinimaxDeepBot{
-		Symbol: symbol,
-		Name:   name,
-		Depth:  depth,
-		Base:   base,
+		BaseBot: NewBaseBot(symbol, name),
+		Depth:   depth,
+		Base:    base,
 	}
 }
 
+// init registers ConcurrentMinimaxDeepBot with the bot registry
+func init() {
+	RegisterBot(&BotRegistration{
+		Key:         "concurrentdeep",
+		DisplayName: "ConcurrentMinimaxDeepBot",
+		Description: "concurrent at all levels",
+		Order:       6,
+		Defaults:    map[string]int{"depth": 5, "base": 10}, // Lower depth due to overhead
+		New: func(symbol byte, name string, params map[string]int) BotInterface {
+			return NewConcurrentMinimaxDeepBot(symbol, name, params["depth"], params["base"])
+		},
+	})
+}
+
 // MakeMove makes a move using deep concurrent minimax algorithm (implements BotInterface)
 // Uses concurrency at every level of the minimax tree
-func (bot *ConcurrentMinimaxDeepBot) MakeMove(board *Board) (string, [3]int) {
-	validMoves := board.GetValidMoves()
-	if len(validMoves) == 0 {
-		return "", [3]int{-1, -1, -1} // No valid moves
-	}
-
+func (bot *ConcurrentMinimaxDeepBot) MakeMove(ctx context.Context, board *engine.Board) (Move, error) {
 	// Use deep concurrent minimax to find the best move
-	_, bestMoves := concurrentMinimaxDeep(board, bot.Depth, bot.Symbol == 'x')
-	if len(bestMoves) == 0 {
-		return "", [3]int{-1, -1, -1} // No valid moves
-	}
-
-	bestMove := bestMoves[0] // Pick the first best move
-	coords := board.Move(bestMove, bot.Symbol)
-	return bestMove, coords
-}
-
-// getName returns the bot's name (implements BotInterface)
-func (bot *ConcurrentMinimaxDeepBot) getName() string {
-	return bot.Name
-}
-
-// getSymbol returns the bot's symbol (implements BotInterface)
-func (bot *ConcurrentMinimaxDeepBot) getSymbol() byte {
-	return bot.Symbol
+	score, bestMoves := concurrentMinimaxDeep(board, bot.Depth, bot.Symbol() == 'x', traceRootMoves(ctx))
+	bot.reportScore(score)
+	return PlayChosenMove(ctx, board, bot.Symbol(), FirstMove(bestMoves)) // Pick the first best move
 }
 
 // concurrentMinimaxDeep performs fully concurrent minimax at every level
 // This version uses goroutines at every level of the recursion for maximum parallelization
-func concurrentMinimaxDeep(board *Board, depth int, isMaximizing bool) (int, []string) {
+func concurrentMinimaxDeep(board *engine.Board, depth int, isMaximizing bool, ctx context.Context) (int, []string) {
 	// Check for winning conditions first
 	winner := board.CheckWin()
 	if winner != '|' {
 		if winner == 'x' {
-			return MAX_INT / 2, []string{} // X wins
+			return engine.MAX_INT / 2, []string{} // X wins
 		} else {
-			return MIN_INT / 2, []string{} // O wins
+			return engine.MIN_INT / 2, []string{} // O wins
 		}
 	}
 
@@ -75,7 +70,7 @@ func concurrentMinimaxDeep(board *Board, depth int, isMaximizing bool) (int, []s
 
 	// For small number of moves or shallow depth, use sequential to avoid overhead
 	if len(validMoves) <= 2 || depth <= 1 {
-		return minimax(board, depth, isMaximizing)
+		return minimax(board, depth, isMaximizing, ctx)
 	}
 
 	// Set result to very low/high initial value
@@ -100,11 +95,14 @@ func concurrentMinimaxDeep(board *Board, depth int, isMaximizing bool) (int, []s
 			defer wg.Done()
 
 			// Create a deep copy of the board to test the move
-			testBoard := copyBoard(board)
+			testBoard := engine.CopyBoard(board)
 			testBoard.Move(move, symbol)
 
 			// Recursively evaluate this branch with deep concurrency
-			score, moves := concurrentMinimaxDeep(testBoard, depth-1, !isMaximizing)
+			childCtx, span := startRootMoveSpan(ctx, move)
+			score, moves := concurrentMinimaxDeep(testBoard, depth-1, !isMaximizing, childCtx)
+			span.SetAttributes("score", score)
+			span.End()
 
 			results <- DepthResult{Move: move, Score: score, Moves: moves}
 		}(move)
@@ -117,9 +115,9 @@ func concurrentMinimaxDeep(board *Board, depth int, isMaximizing bool) (int, []s
 	}()
 
 	// Find the best result from all branches
-	bestScore := MIN_INT
+	bestScore := engine.MIN_INT
 	if !isMaximizing {
-		bestScore = MAX_INT
+		bestScore = engine.MAX_INT
 	}
 	bestMoves := []string{}
 
@@ -0,0 +1,133 @@
+package bots
+
+import (
+	"container/list"
+	"sync"
+
+	"tic-tac-toe-3d-bots/engine"
+)
+
+// EVAL_CACHE_SIZE is the number of positions an evaluation cache keeps by default
+const EVAL_CACHE_SIZE = 1 << 16
+
+// EVAL_CACHE_MIN_RESERVATION is the fewest positions an evaluation cache reserves the memory of at a time
+const EVAL_CACHE_MIN_RESERVATION = 1 << 10
+
+// EvalCache remembers the static evaluations of the most recently evaluated positions, keyed by their Zobrist hash,
+// for searches that evaluate whole boards at their leaves and reach the same positions by different move orders.
+// The least recently used position makes way for a new one once the cache is full, or once the search memory budget
+// (see SetSearchMemory) grants it no more room. It is safe for concurrent use
+type EvalCache struct {
+	mutex     sync.Mutex
+	capacity  int
+	reserved  int  // positions the cache has memory reserved for
+	capped    bool // the budget turned down the last reservation: the cache grows no further until it is emptied
+	entries   map[uint64]*list.Element
+	order     *list.List // of evalCacheEntry, most recently used first
+	evaluator engine.Evaluator
+	size      [4]int // Length, Width, Height and WinLength of the boards cached
+	hits      int
+	misses    int
+}
+
+// evalCacheEntry is a position's score in an EvalCache
+type evalCacheEntry struct {
+	hash  uint64
+	score int
+}
+
+// NewEvalCache creates an empty evaluation cache holding up to capacity positions (EVAL_CACHE_SIZE if not positive)
+func NewEvalCache(capacity int) *EvalCache {
+	if capacity <= 0 {
+		capacity = EVAL_CACHE_SIZE
+	}
+	return &EvalCache{capacity: capacity, entries: make(map[uint64]*list.Element), order: list.New()}
+}
+
+// Evaluate returns board.Evaluate() for the position on board, evaluating it only if it is not cached
+// The cache empties itself when the board's evaluator or dimensions differ from those of the positions it holds
+func (cache *EvalCache) Evaluate(board *engine.Board) int {
+	hash := board.ZobristHash()
+	size := [4]int{board.Length, board.Width, board.Height, board.WinLength}
+
+	cache.mutex.Lock()
+	if board.Evaluator != cache.evaluator || size != cache.size {
+		cache.clear()
+		cache.evaluator, cache.size = board.Evaluator, size
+	}
+	if element, found := cache.entries[hash]; found {
+		cache.hits++
+		cache.order.MoveToFront(element)
+		score := element.Value.(evalCacheEntry).score
+		cache.mutex.Unlock()
+		return score
+	}
+	cache.misses++
+	cache.mutex.Unlock()
+
+	// Evaluated outside the lock, so that concurrent searches only wait for each other's lookups
+	score := board.Evaluate()
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if board.Evaluator != cache.evaluator || size != cache.size {
+		return score // Another search cleared the cache for other positions meanwhile
+	}
+	if element, found := cache.entries[hash]; found {
+		cache.order.MoveToFront(element)
+		return score
+	}
+	cache.entries[hash] = cache.order.PushFront(evalCacheEntry{hash: hash, score: score})
+	if cache.order.Len() > cache.reserved {
+		cache.reserve()
+	}
+	if cache.order.Len() > cache.reserved {
+		oldest := cache.order.Back()
+		cache.order.Remove(oldest)
+		delete(cache.entries, oldest.Value.(evalCacheEntry).hash)
+	}
+	return score
+}
+
+// Stats returns the number of lookups answered from the cache and of those that had to evaluate the position
+func (cache *EvalCache) Stats() (hits, misses int) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	return cache.hits, cache.misses
+}
+
+// HitRate returns the share of lookups answered from the cache, from 0 to 1 (0 before the first lookup)
+func (cache *EvalCache) HitRate() float64 {
+	hits, misses := cache.Stats()
+	if hits+misses == 0 {
+		return 0
+	}
+	return float64(hits) / float64(hits+misses)
+}
+
+// reserve reserves the memory of more positions, doubling the cache's room up to its capacity; the caller holds the
+// mutex
+func (cache *EvalCache) reserve() {
+	if cache.capped || cache.reserved >= cache.capacity {
+		return
+	}
+	wanted := min(max(cache.reserved, EVAL_CACHE_MIN_RESERVATION), cache.capacity-cache.reserved)
+	granted := reserveMemory(MemoryEvalCache, wanted*EVAL_CACHE_ENTRY_SIZE, 0) / EVAL_CACHE_ENTRY_SIZE
+	cache.reserved += granted
+	cache.capped = granted < wanted
+}
+
+// Release empties the cache and gives its memory back to the search memory budget
+func (cache *EvalCache) Release() {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.clear()
+}
+
+// clear empties the cache, giving its memory back; the caller holds the mutex
+func (cache *EvalCache) clear() {
+	clear(cache.entries)
+	cache.order.Init()
+	releaseMemory(MemoryEvalCache, cache.reserved*EVAL_CACHE_ENTRY_SIZE)
+	cache.reserved, cache.capped = 0, false
+}
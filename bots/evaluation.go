@@ -0,0 +1,43 @@
+package bots
+
+import (
+	"tic-tac-toe-3d-bots/engine"
+)
+
+// withEvaluatorDefaults adds the parameters of a bot's own evaluator to its other defaults: the base, the weight
+// of each direction class in percent, the playability, the parity and the fork bonus, e.g.
+// "alphabeta:vertical=60,forks=50"
+func withEvaluatorDefaults(defaults map[string]int) map[string]int {
+	evaluator := engine.DefaultEvaluator()
+	defaults["base"] = evaluator.Base
+	defaults["playability"] = evaluator.Playability
+	defaults["parity"] = evaluator.Parity
+	defaults["forks"] = evaluator.Forks
+	for class, weight := range evaluator.Weights {
+		defaults[engine.DirectionClass(class).String()] = weight
+	}
+	return defaults
+}
+
+// evaluatorFromParams builds the evaluator described by a bot's parameters, which Create has checked
+func evaluatorFromParams(params map[string]int) engine.Evaluator {
+	evaluator := engine.DefaultEvaluator()
+	evaluator.Base = params["base"]
+	evaluator.Playability = params["playability"]
+	evaluator.Parity = params["parity"]
+	evaluator.Forks = params["forks"]
+	for class := range evaluator.Weights {
+		evaluator.Weights[class] = params[engine.DirectionClass(class).String()]
+	}
+	return evaluator
+}
+
+// useEvaluator makes board evaluate positions with evaluator until the returned function puts its own back
+func useEvaluator(board *engine.Board, evaluator engine.Evaluator) (restore func()) {
+	original := board.Evaluator
+	if evaluator == original {
+		return func() {}
+	}
+	board.SetEvaluator(evaluator)
+	return func() { board.SetEvaluator(original) }
+}
@@ -0,0 +1,128 @@
+package bots
+
+import (
+	"context"
+	"math"
+
+	"tic-tac-toe-3d-bots/engine"
+)
+
+// StrengthLevel is one calibrated setting of LimitedBot: how deep it searches, and how freely it plays moves its
+// search scores below the best one
+type StrengthLevel struct {
+	Elo         int // approximate rating the setting plays at, on the scale of the calibrate command's reference bots
+	Depth       int
+	Temperature int // score loss that makes a move e times less likely than the best one; 0 always plays the best
+}
+
+// StrengthLevels lists LimitedBot's settings from weakest to strongest, as measured by the calibrate command
+// Ratings between two levels search at the depth of the lower one, with a temperature in between
+var StrengthLevels = []StrengthLevel{
+	{Elo: 600, Depth: 1, Temperature: 2000},
+	{Elo: 900, Depth: 1, Temperature: 400},
+	{Elo: 1200, Depth: 2, Temperature: 150},
+	{Elo: 1500, Depth: 3, Temperature: 60},
+	{Elo: 1800, Depth: 4, Temperature: 20},
+	{Elo: 2100, Depth: 6, Temperature: 0},
+}
+
+// StrengthFor returns the setting aimed at elo, clamped to the range of StrengthLevels
+func StrengthFor(elo int) StrengthLevel {
+	if elo <= StrengthLevels[0].Elo {
+		return StrengthLevels[0]
+	}
+	for i, upper := range StrengthLevels[1:] {
+		if elo >= upper.Elo {
+			continue
+		}
+		lower := StrengthLevels[i]
+		share := float64(elo-lower.Elo) / float64(upper.Elo-lower.Elo)
+		temperature := float64(lower.Temperature) + share*float64(upper.Temperature-lower.Temperature)
+		return StrengthLevel{Elo: elo, Depth: lower.Depth, Temperature: int(math.Round(temperature))}
+	}
+	return StrengthLevels[len(StrengthLevels)-1]
+}
+
+// LimitedBot plays at a chosen approximate rating instead of as well as it can, so casual players are not simply
+// crushed. It scores every legal move with a search bounded to its level's depth, then picks one at random: the best
+// move is the likeliest, and each other move is less likely the more its score falls short (see StrengthLevel)
+type LimitedBot struct {
+	BaseBot
+	Elo int
+}
+
+// NewLimitedBot creates a new strength-limited bot with the given symbol and name, aiming at elo
+func NewLimitedBot(symbol byte, name string, elo int) *LimitedBot {
+	return &LimitedBot{
+		BaseBot: NewBaseBot(symbol, name),
+		Elo:     elo,
+	}
+}
+
+// init registers LimitedBot with the bot registry
+func init() {
+	RegisterBot(&BotRegistration{
+		Key:         "limited",
+		DisplayName: "LimitedBot",
+		Description: "plays at a chosen approximate Elo, e.g. limited:elo=1200",
+		Order:       15,
+		Defaults:    map[string]int{"elo": 1500},
+		New: func(symbol byte, name string, params map[string]int) BotInterface {
+			return NewLimitedBot(symbol, name, params["elo"])
+		},
+	})
+}
+
+// MakeMove scores every legal move and plays one of them at random, weighted by how little it loses (implements BotInterface)
+func (bot *LimitedBot) MakeMove(ctx context.Context, board *engine.Board) (Move, error) {
+	validMoves := board.GetValidMoves()
+	if len(validMoves) == 0 {
+		return Move{}, ErrNoValidMoves
+	}
+	level := StrengthFor(bot.Elo)
+
+	// Every root move is searched with a full window, so its score is exact rather than a pruning bound
+	isMaximizing := bot.Symbol() == 'x'
+	childThreshold := engine.MIN_INT
+	sign := 1
+	if !isMaximizing {
+		childThreshold, sign = engine.MAX_INT, -1
+	}
+	scores := make([]int, len(validMoves))
+	best := 0
+	for i, move := range validMoves {
+		if SearchCancelled(ctx) {
+			break
+		}
+		board.Move(move, bot.Symbol())
+		scores[i], _ = AlphaBetaMinimax(board, level.Depth-1, !isMaximizing, childThreshold, ctx)
+		board.UnMove(move)
+		if sign*scores[i] > sign*scores[best] {
+			best = i
+		}
+	}
+	if level.Temperature <= 0 {
+		return PlayChosenMove(ctx, board, bot.Symbol(), validMoves[best])
+	}
+
+	// The best move weighs 1; a move losing Temperature weighs 1/e, and one losing by force next to nothing
+	weights := make([]float64, len(validMoves))
+	total := 0.0
+	for i, score := range scores {
+		loss := float64(sign * (scores[best] - score))
+		weights[i] = math.Exp(-loss / float64(level.Temperature))
+		total += weights[i]
+	}
+	pick := bot.Random().Float64() * total
+	chosen := best
+	for i, weight := range weights {
+		if pick < weight {
+			chosen = i
+			break
+		}
+		pick -= weight
+	}
+	searchLog.Load().Debug("strength-limited move", "player", string(bot.Symbol()), "elo", bot.Elo, "depth", level.Depth,
+		"move", validMoves[chosen], "best", validMoves[best], "loss", sign*(scores[best]-scores[chosen]))
+	return PlayChosenMove(ctx, board, bot.Symbol(), validMoves[chosen])
+}
@@ -0,0 +1,23 @@
+package bots
+
+import (
+	"log/slog"
+	"sync/atomic"
+)
+
+// Searches report what they find to two scoped loggers: "search" for the moves searches choose,
+// and "tree" for the persistent bot's search tree. Both discard everything until SetLogger is called
+var (
+	searchLog atomic.Pointer[slog.Logger]
+	treeLog   atomic.Pointer[slog.Logger]
+)
+
+func init() {
+	SetLogger(slog.New(slog.DiscardHandler))
+}
+
+// SetLogger sends the bots' diagnostics to logger, each record tagged with its subsystem ("search" or "tree")
+func SetLogger(logger *slog.Logger) {
+	searchLog.Store(logger.With("subsystem", "search"))
+	treeLog.Store(logger.With("subsystem", "tree"))
+}
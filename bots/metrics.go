@@ -0,0 +1,12 @@
+package bots
+
+import "sync/atomic"
+
+// searchedNodes counts the positions searched by the bots that count them, for monitoring
+var searchedNodes atomic.Int64
+
+// SearchedNodes returns the number of positions searched so far by the bots that count them:
+// the root-splitting minimax bot and the persistent bot's search tree
+func SearchedNodes() int64 {
+	return searchedNodes.Load()
+}
@@ -0,0 +1,102 @@
+package bots
+
+import (
+	"context"
+
+	"tic-tac-toe-3d-bots/engine"
+)
+
+// MinimaxBot represents an optimized minimax AI player with move/unmove and delta evaluation
+type MinimaxBot struct {
+	BaseBot
+	Depth int
+	Base  int // Base for exponential scoring (e.g., 2, 3, 4)
+}
+
+// NewMinimaxBot creates a new minimax bot with the given symbol, name, and search depth
+func NewMinimaxBot(symbol byte, name string, depth int, base int) *MinimaxBot {
+	return &MinimaxBot{
+		BaseBot: NewBaseBot(symbol, name),
+		Depth:   depth,
+		Base:    base,
+	}
+}
+
+// init registers MinimaxBot with the bot registry
+func init() {
+	RegisterBot(&BotRegistration{
+		Key:         "minimax",
+		DisplayName: "MinimaxBot",
+		Description: "optimized minimax with delta evaluation",
+		Order:       3,
+		Defaults:    map[string]int{"depth": 6, "base": 10},
+		New: func(symbol byte, name string, params map[string]int) BotInterface {
+			return NewMinimaxBot(symbol, name, params["depth"], params["base"])
+		},
+	})
+}
+
+// MakeMove makes a move using optimized minimax algorithm (implements BotInterface)
+// Uses delta evaluation and move/unmove optimization for better performance
+func (bot *MinimaxBot) MakeMove(ctx context.Context, board *engine.Board) (Move, error) {
+	score, bestMoves := minimax(board, bot.Depth, bot.Symbol() == 'x', ctx)
+	bot.reportScore(score)
+	return PlayChosenMove(ctx, board, bot.Symbol(), FirstMove(bestMoves)) // Pick the first best move
+}
+
+// Default minimax function, returns pair of (score, array of best moves)
+// If ctx is cancelled the search unwinds early and its result must be discarded
+func minimax(board *engine.Board, depth int, isMaximizing bool, ctx context.Context) (int, []string) {
+	return countedMinimax(board, depth, isMaximizing, nil, ctx)
+}
+
+// countedMinimax is minimax that also increments *nodes for every position visited (nodes may be nil)
+func countedMinimax(board *engine.Board, depth int, isMaximizing bool, nodes *int, ctx context.Context) (int, []string) {
+	if nodes != nil {
+		*nodes++
+	}
+
+	// Check for winning conditions first
+	winner := board.CheckWin()
+	if winner != '|' {
+		if winner == 'x' {
+			return engine.MAX_INT / 2, []string{} // X wins
+		} else {
+			return engine.MIN_INT / 2, []string{} // O wins
+		}
+	}
+
+	if depth == 0 {
+		return board.Score, []string{} // Use the board's current score instead of recalculating
+	}
+
+	// Set result to very low/high initial value
+	var symbol byte = 'x'
+	bestScore := engine.MIN_INT
+	if !isMaximizing {
+		symbol = 'o'
+		bestScore = engine.MAX_INT
+	}
+	bestMoves := []string{}
+
+	board.EachValidMove(func(col, row int) bool {
+		if SearchCancelled(ctx) {
+			return false
+		}
+
+		board.MoveAt(col, row, symbol)
+		score, moves := countedMinimax(board, depth-1, !isMaximizing, nodes, ctx)
+		board.UnMoveAt(col, row)
+
+		if isMaximizing && score > bestScore {
+			bestScore = score
+			bestMoves = append([]string{board.MoveName(col, row)}, moves...)
+		} else if !isMaximizing && score < bestScore {
+			bestScore = score
+			bestMoves = append([]string{board.MoveName(col, row)}, moves...)
+		}
+		return true
+	})
+
+	return bestScore, bestMoves
+}
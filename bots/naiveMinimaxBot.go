@@ -0,0 +1,103 @@
+package bots
+
+import (
+	"context"
+
+	"tic-tac-toe-3d-bots/engine"
+)
+
+// NaiveMinimaxBot represents a simple minimax AI player without optimizations
+type NaiveMinimaxBot struct {
+	BaseBot
+	Depth int
+	Base  int        // Base for exponential scoring (e.g., 2, 3, 4)
+	cache *EvalCache // Leaf evaluations, shared by the bot's searches
+}
+
+// NewNaiveMinimaxBot creates a new naive minimax bot with the given symbol, name, and search depth
+func NewNaiveMinimaxBot(symbol byte, name string, depth int, base int) *NaiveMinimaxBot {
+	return &NaiveMinimaxBot{
+		BaseBot: NewBaseBot(symbol, name),
+		Depth:   depth,
+		Base:    base,
+		cache:   NewEvalCache(EVAL_CACHE_SIZE),
+	}
+}
+
+// init registers NaiveMinimaxBot with the bot registry
+func init() {
+	RegisterBot(&BotRegistration{
+		Key:         "naive",
+		DisplayName: "NaiveMinimaxBot",
+		Description: "basic minimax without optimizations",
+		Order:       2,
+		Defaults:    map[string]int{"depth": 4, "base": 10}, // Lower depth for naive approach
+		New: func(symbol byte, name string, params map[string]int) BotInterface {
+			return NewNaiveMinimaxBot(symbol, name, params["depth"], params["base"])
+		},
+	})
+}
+
+// MakeMove makes a move using naive minimax algorithm (implements BotInterface)
+// Uses full board evaluation at each step - no delta evaluation optimization
+func (bot *NaiveMinimaxBot) MakeMove(ctx context.Context, board *engine.Board) (Move, error) {
+	score, bestMoves := naiveMinimax(board, bot.Depth, bot.Symbol() == 'x', bot.cache, ctx)
+	bot.reportScore(score)
+	hits, misses := bot.cache.Stats()
+	searchLog.Load().Debug("evaluation cache", "player", string(bot.Symbol()), "hits", hits, "misses", misses, "hitRate", bot.cache.HitRate())
+	return PlayChosenMove(ctx, board, bot.Symbol(), FirstMove(bestMoves)) // Pick the first best move
+}
+
+// Close gives the memory of the bot's evaluation cache back to the search memory budget (implements BotInterface)
+func (bot *NaiveMinimaxBot) Close() {
+	bot.cache.Release()
+}
+
+// naiveMinimax function uses full board evaluation instead of delta evaluation, looked up in cache first
+func naiveMinimax(board *engine.Board, depth int, isMaximizing bool, cache *EvalCache, ctx context.Context) (int, []string) {
+	// Check for winning conditions first
+	winner := board.CheckWin()
+	if winner != '|' {
+		if winner == 'x' {
+			return engine.MAX_INT / 2, []string{} // X wins
+		} else {
+			return engine.MIN_INT / 2, []string{} // O wins
+		}
+	}
+
+	if depth == 0 {
+		// Use full evaluation instead of the board's incremental score
+		return cache.Evaluate(board), []string{}
+	}
+
+	// Set result to very low/high initial value
+	var symbol byte = 'x'
+	bestScore := engine.MIN_INT
+	if !isMaximizing {
+		symbol = 'o'
+		bestScore = engine.MAX_INT
+	}
+	bestMoves := []string{}
+
+	for _, move := range board.GetValidMoves() {
+		if SearchCancelled(ctx) {
+			break
+		}
+
+		// Create a deep copy for naive approach (no move/unmove optimization)
+		testBoard := engine.CopyBoard(board)
+		testBoard.Move(move, symbol)
+
+		score, moves := naiveMinimax(testBoard, depth-1, !isMaximizing, cache, ctx)
+
+		if isMaximizing && score > bestScore {
+			bestScore = score
+			bestMoves = append([]string{move}, moves...)
+		} else if !isMaximizing && score < bestScore {
+			bestScore = score
+			bestMoves = append([]string{move}, moves...)
+		}
+	}
+
+	return bestScore, bestMoves
+}
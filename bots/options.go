@@ -0,0 +1,124 @@
+package bots
+
+import (
+	"fmt"
+	"strings"
+
+	"tic-tac-toe-3d-bots/engine"
+)
+
+// botSettings collects the options of New
+type botSettings struct {
+	symbol byte
+	name   string
+	params map[string]int
+}
+
+// Option configures a bot created by New; an invalid option makes New fail
+type Option func(settings *botSettings) error
+
+// WithSymbol sets the side the bot plays, 'x' (the default) or 'o'
+func WithSymbol(symbol byte) Option {
+	return func(settings *botSettings) error {
+		if symbol != 'x' && symbol != 'o' {
+			return fmt.Errorf("invalid symbol %q (expected 'x' or 'o')", symbol)
+		}
+		settings.symbol = symbol
+		return nil
+	}
+}
+
+// WithName sets the bot's name; the default is the bot type's display name, e.g. "AlphaBetaMinimaxBot"
+func WithName(name string) Option {
+	return func(settings *botSettings) error {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("empty bot name")
+		}
+		settings.name = name
+		return nil
+	}
+}
+
+// WithParam sets one of the bot's parameters, as in the bot spec "alphabeta:depth=6"
+// New fails if the bot does not take the parameter
+func WithParam(key string, value int) Option {
+	return func(settings *botSettings) error {
+		key = strings.ToLower(strings.TrimSpace(key))
+		if key == "" {
+			return fmt.Errorf("empty bot parameter name")
+		}
+		settings.params[key] = value
+		return nil
+	}
+}
+
+// WithDepth sets the search depth of a searching bot (the "depth" parameter)
+func WithDepth(depth int) Option {
+	return func(settings *botSettings) error {
+		if depth < 1 {
+			return fmt.Errorf("search depth must be positive, got %d", depth)
+		}
+		return WithParam("depth", depth)(settings)
+	}
+}
+
+// WithBase sets the base of the bot's exponential line scores (the "base" parameter)
+func WithBase(base int) Option {
+	return func(settings *botSettings) error {
+		if base < 2 {
+			return fmt.Errorf("evaluation base must be at least 2, got %d", base)
+		}
+		return WithParam("base", base)(settings)
+	}
+}
+
+// WithEvaluator sets the evaluator the bot scores positions with (the "base", "horizontal", "vertical", "planar",
+// "space", "playability", "parity" and "forks" parameters); New fails for bots that evaluate with the board's own evaluator
+func WithEvaluator(evaluator engine.Evaluator) Option {
+	return func(settings *botSettings) error {
+		if err := evaluator.Validate(); err != nil {
+			return err
+		}
+		settings.params["base"] = evaluator.Base
+		settings.params["playability"] = evaluator.Playability
+		settings.params["parity"] = evaluator.Parity
+		settings.params["forks"] = evaluator.Forks
+		for class, weight := range evaluator.Weights {
+			settings.params[engine.DirectionClass(class).String()] = weight
+		}
+		return nil
+	}
+}
+
+// WithTT sizes the bot's transposition table in bytes, passed on as the "tt" parameter in whole MiB (rounded up)
+// New fails for bots without a transposition table
+func WithTT(bytes int) Option {
+	return func(settings *botSettings) error {
+		if bytes < 1 || bytes > MAX_TT_SIZE<<20 {
+			return fmt.Errorf("transposition table size must be between 1 byte and %d MiB, got %d bytes", MAX_TT_SIZE, bytes)
+		}
+		return WithParam("tt", (bytes+1<<20-1)>>20)(settings)
+	}
+}
+
+// New creates a bot of a registered type, e.g. New("alphabeta", WithDepth(8))
+// The type may also be a bot spec such as "alphabeta:depth=6", whose parameters the options override.
+// An unknown bot type, an invalid option, or a parameter the bot does not take or out of its range is an error
+func New(spec string, options ...Option) (BotInterface, error) {
+	key, params, err := ParseBotSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	registration, exists := LookupBot(key)
+	if !exists {
+		return nil, fmt.Errorf("unknown bot %q (available: %s)", key, strings.Join(RegisteredBotKeys(), ", "))
+	}
+
+	settings := botSettings{symbol: 'x', name: registration.DisplayName, params: params}
+	for _, option := range options {
+		if err := option(&settings); err != nil {
+			return nil, err
+		}
+	}
+	return registration.Create(settings.symbol, settings.name, settings.params)
+}
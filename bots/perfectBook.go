@@ -0,0 +1,31 @@
+package bots
+
+// perfectBook is x's winning strategy on the 3x3x3 board: the move to play, by Snapshot, in every position the
+// strategy reaches, whatever o replies. x starts in the centre column and wins with their fifth piece at the latest.
+// Positions where x wins on the spot are left out, as FindWinningMove finds those. The moves are gravitySolver's
+// own, from walking the strategy with it; a change to the solver's move order may change them
+var perfectBook = map[string]string{
+	"3x3x3/3 -,-,-/-,-,-/-,-,- x":   "B2",
+	"3x3x3/3 -,-,-/-,x,-/-,-,o x":   "B2",
+	"3x3x3/3 -,-,-/-,x,-/-,o,- x":   "A1",
+	"3x3x3/3 -,-,-/-,x,-/o,-,- x":   "A2",
+	"3x3x3/3 -,-,-/-,x,o/-,-,- x":   "A1",
+	"3x3x3/3 -,-,-/-,xo,-/-,-,- x":  "A1",
+	"3x3x3/3 -,-,-/o,x,-/-,-,- x":   "A1",
+	"3x3x3/3 -,-,o/-,x,-/-,-,- x":   "B1",
+	"3x3x3/3 -,o,-/-,x,-/-,-,- x":   "A1",
+	"3x3x3/3 o,-,-/-,x,-/-,-,- x":   "A2",
+	"3x3x3/3 -,-,-/-,xxo,-/-,-,o x": "B3",
+	"3x3x3/3 -,-,-/x,x,o/o,-,- x":   "A2",
+	"3x3x3/3 -,x,o/-,x,-/-,o,- x":   "B1",
+	"3x3x3/3 o,-,-/x,x,o/-,-,- x":   "A2",
+	"3x3x3/3 x,-,-/-,x,-/-,o,o x":   "A3",
+	"3x3x3/3 x,-,-/-,x,o/-,-,o x":   "C1",
+	"3x3x3/3 x,-,-/-,xo,-/-,-,o x":  "A2",
+	"3x3x3/3 x,-,-/o,x,-/-,-,o x":   "B1",
+	"3x3x3/3 x,o,-/-,x,-/-,-,o x":   "A2",
+	"3x3x3/3 -,-,-/xxo,x,o/o,-,- x": "B2",
+	"3x3x3/3 -,o,-/-,xxo,-/-,x,o x": "B1",
+	"3x3x3/3 -,xxo,o/-,x,-/-,o,- x": "B2",
+	"3x3x3/3 o,-,-/xxo,x,o/-,-,- x": "B2",
+}
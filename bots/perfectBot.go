@@ -0,0 +1,196 @@
+package bots
+
+import (
+	"context"
+
+	"tic-tac-toe-3d-bots/engine"
+)
+
+// PerfectBot plays the solved 3x3x3 board perfectly. The first player forces a win there by their fifth piece,
+// and the bot plays x's winning strategy from perfectBook; otherwise, as o or in positions off the book, it solves the
+// position exactly. It wins as soon as it can, and it resists as long as it can when it cannot avoid losing.
+// On other boards, too large to solve, it plays alpha-beta at its depth
+type PerfectBot struct {
+	BaseBot
+	Depth    int                   // Search depth off the 3x3x3 board
+	progress func(SearchIteration) // Receives each iteration of the alpha-beta search while set, see ReportProgress
+}
+
+// NewPerfectBot creates a new perfect bot with the given symbol and name, searching depth plies off the 3x3x3 board
+func NewPerfectBot(symbol byte, name string, depth int) *PerfectBot {
+	return &PerfectBot{
+		BaseBot: NewBaseBot(symbol, name),
+		Depth:   depth,
+	}
+}
+
+// init registers PerfectBot with the bot registry
+func init() {
+	RegisterBot(&BotRegistration{
+		Key:         "perfect",
+		DisplayName: "PerfectBot",
+		Description: "plays the solved 3x3x3 board perfectly (alpha-beta on larger boards)",
+		Order:       11,
+		Defaults:    map[string]int{"depth": 6},
+		New: func(symbol byte, name string, params map[string]int) BotInterface {
+			return NewPerfectBot(symbol, name, params["depth"])
+		},
+	})
+}
+
+// MakeMove makes the solved move on the 3x3x3 board, or the alpha-beta move on others (implements BotInterface)
+func (bot *PerfectBot) MakeMove(ctx context.Context, board *engine.Board) (Move, error) {
+	validMoves := board.GetValidMoves()
+	if len(validMoves) == 0 {
+		return Move{}, ErrNoValidMoves
+	}
+
+	if !isSolvedBoard(board) {
+		_, bestMoves := deepeningAlphaBeta(board, bot.Depth, bot.Symbol(), bot.progress, ctx)
+		return PlayChosenMove(ctx, board, bot.Symbol(), FirstMove(bestMoves))
+	}
+
+	if move := FindWinningMove(board, validMoves, bot.Symbol()); move != "" {
+		return PlayChosenMove(ctx, board, bot.Symbol(), move)
+	}
+	if move, found := perfectBook[board.Snapshot()]; found {
+		return PlayChosenMove(ctx, board, bot.Symbol(), move)
+	}
+	move, score := newGravitySolver().bestMove(ctx, board)
+	searchLog.Load().Debug("position solved", "player", string(bot.Symbol()), "move", move, "score", score)
+	return PlayChosenMove(ctx, board, bot.Symbol(), move)
+}
+
+// ReportProgress has the bot's alpha-beta search off the 3x3x3 board deepen iteratively, passing each completed
+// iteration to report, or search at its depth at once again if report is nil (implements ProgressReporter). On the
+// 3x3x3 board its moves come from the book or the solver, which report nothing
+func (bot *PerfectBot) ReportProgress(report func(SearchIteration)) {
+	bot.progress = report
+}
+
+// isSolvedBoard reports whether board is the 3x3x3 board with three in a row winning, which PerfectBot has solved
+func isSolvedBoard(board *engine.Board) bool {
+	return board.Length == 3 && board.Width == 3 && board.Height == 3 && board.WinLength == 3
+}
+
+// SOLVED_WIN scores a won position for gravitySolver: a win with n pieces on the board scores SOLVED_WIN-n for the
+// winner and n-SOLVED_WIN for the loser, so sooner wins and later losses score higher. As gravity fixes the number of
+// pieces of a position, the scores do not depend on the moves that reached it
+const SOLVED_WIN = 1000
+
+// Bounds of the solver's transposition table entries
+const (
+	solvedExact = iota
+	solvedLower // the score is at least the entry's
+	solvedUpper // the score is at most the entry's
+)
+
+// solvedEntry is a position's score in a gravitySolver's transposition table
+type solvedEntry struct {
+	score int
+	bound int
+}
+
+// gravitySolver searches positions to the end of the game with alpha-beta, scoring them exactly (see SOLVED_WIN)
+// It is practical on the 3x3x3 board only
+type gravitySolver struct {
+	table map[uint64]solvedEntry // by Zobrist hash
+}
+
+// newGravitySolver creates a solver with an empty transposition table
+func newGravitySolver() *gravitySolver {
+	return &gravitySolver{table: make(map[uint64]solvedEntry)}
+}
+
+// bestMove returns the move of the player to move that scores best, the first in move order among equals, and its
+// score for that player; if ctx is cancelled the result must be discarded
+func (solver *gravitySolver) bestMove(ctx context.Context, board *engine.Board) (string, int) {
+	symbol := board.NextPlayer()
+	bestMove, bestScore := "", -2*SOLVED_WIN
+	for _, move := range board.GetValidMoves() {
+		board.Move(move, symbol)
+		score := SOLVED_WIN - board.MoveCount()
+		if board.CheckWin() != symbol {
+			score = -solver.negamax(ctx, board, -2*SOLVED_WIN, -bestScore)
+		}
+		board.UnMove(move)
+
+		if score > bestScore {
+			bestMove, bestScore = move, score
+		}
+		if SearchCancelled(ctx) {
+			break
+		}
+	}
+	return bestMove, bestScore
+}
+
+// negamax returns the score of board for the player to move, exact if it lies between alpha and beta, and otherwise
+// a bound on the side of the window it falls
+func (solver *gravitySolver) negamax(ctx context.Context, board *engine.Board, alpha, beta int) int {
+	searchedNodes.Add(1)
+	validMoves := board.GetValidMoves()
+	if len(validMoves) == 0 || SearchCancelled(ctx) {
+		return 0
+	}
+	symbol := board.NextPlayer()
+	pieces := board.MoveCount()
+
+	// Immediate wins, and threats the player must block: two of them lose
+	if FindWinningMove(board, validMoves, symbol) != "" {
+		return SOLVED_WIN - (pieces + 1)
+	}
+	opponent := byte('o')
+	if symbol == 'o' {
+		opponent = 'x'
+	}
+	if block := FindWinningMove(board, validMoves, opponent); block != "" {
+		board.Move(block, symbol)
+		another := FindWinningMove(board, board.GetValidMoves(), opponent)
+		board.UnMove(block)
+		if another != "" {
+			return (pieces + 2) - SOLVED_WIN
+		}
+		validMoves = []string{block}
+	}
+
+	// Neither can win on the next move: the window narrows to the scores left
+	alpha, beta = max(alpha, (pieces+2)-SOLVED_WIN), min(beta, SOLVED_WIN-(pieces+3))
+	if alpha >= beta {
+		return alpha
+	}
+
+	hash := board.ZobristHash()
+	if entry, found := solver.table[hash]; found {
+		if entry.bound == solvedExact || (entry.bound == solvedLower && entry.score >= beta) ||
+			(entry.bound == solvedUpper && entry.score <= alpha) {
+			return entry.score
+		}
+	}
+
+	originalAlpha := alpha
+	best := -2 * SOLVED_WIN
+	for _, move := range validMoves {
+		board.Move(move, symbol)
+		score := -solver.negamax(ctx, board, -beta, -alpha)
+		board.UnMove(move)
+
+		best = max(best, score)
+		alpha = max(alpha, score)
+		if alpha >= beta {
+			break
+		}
+	}
+	if SearchCancelled(ctx) {
+		return 0 // Not stored, as the search was cut short
+	}
+
+	bound := solvedExact
+	if best <= originalAlpha {
+		bound = solvedUpper
+	} else if best >= beta {
+		bound = solvedLower
+	}
+	solver.table[hash] = solvedEntry{score: best, bound: bound}
+	return best
+}
@@ -1,18 +1,21 @@
-package main
+package bots
 
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"tic-tac-toe-3d-bots/engine"
 )
 
 // PersistentMinimaxBot represents a bot that maintains a persistent search tree
 // and continues calculating during opponent's thinking time
 type PersistentMinimaxBot struct {
-	Symbol       byte
-	Name         string
+	BaseBot
 	InitialDepth int
 	Base         int
+	cache        *EvalCache // Evaluations of the tree's nodes, which transpositions share
 
 	// Tree management
 	rootNode *SearchNode
@@ -22,12 +25,12 @@ type PersistentMinimaxBot struct {
 
 // SearchNode represents a node in the persistent search tree
 type SearchNode struct {
-	ID           string // unique identifier
-	Board        *Board // game state at this node
-	Move         string // move that led to this state (empty for root)
-	Depth        int    // depth in the search tree
-	Score        int    // minimax score
-	IsMaximizing bool   // whether this is a maximizing node
+	ID           string        // unique identifier
+	Board        *engine.Board // game state at this node
+	Move         string        // move that led to this state (empty for root)
+	Depth        int           // depth in the search tree
+	Score        int           // minimax score
+	IsMaximizing bool          // whether this is a maximizing node
 
 	// Tree structure
 	Parent   *SearchNode            // parent node
@@ -42,49 +45,75 @@ type SearchNode struct {
 	mutex       sync.RWMutex // protects node data
 	expanded    bool         // whether children have been generated
 	calculating bool         // whether currently calculating
+	dirty       bool         // whether a descendant score changed since the last propagation pass
 }
 
 // SearchTree manages the persistent search tree
+// Its nodes' memory comes from the search memory budget (see SetSearchMemory): nodes are only expanded while the
+// budget grants their children's, and the tree grows again once pruning gives some back
 type SearchTree struct {
 	root     *SearchNode
 	maxDepth int                    // current maximum search depth
 	nodes    map[string]*SearchNode // all active nodes
 	mutex    sync.RWMutex           // protects tree structure
+	nodeSize int                    // estimated memory of a node, reserved for every one in nodes
+	starved  atomic.Bool            // the budget turned down an expansion, and no node was removed since
 
 	// Background calculation
-	expandQueue chan *SearchNode   // nodes waiting to be expanded
-	ctx         context.Context    // global context
-	cancel      context.CancelFunc // global cancellation
-	wg          sync.WaitGroup     // tracks active goroutines
+	expandQueue    chan *SearchNode   // nodes waiting to be expanded
+	propagateQueue chan struct{}      // requests an immediate propagation pass
+	ctx            context.Context    // global context
+	cancel         context.CancelFunc // global cancellation
+	wg             sync.WaitGroup     // tracks active goroutines
 }
 
 // NewPersistentMinimaxBot creates a new persistent minimax bot
 func NewPersistentMinimaxBot(symbol byte, name string, initialDepth int, base int) *PersistentMinimaxBot {
 	bot := &PersistentMinimaxBot{
-		Symbol:       symbol,
-		Name:         name,
+		BaseBot:      NewBaseBot(symbol, name),
 		InitialDepth: initialDepth,
 		Base:         base,
+		cache:        NewEvalCache(EVAL_CACHE_SIZE),
 	}
 
 	// Initialize search tree with deeper initial depth for better exploration
 	ctx, cancel := context.WithCancel(context.Background())
 	bot.tree = &SearchTree{
-		maxDepth:    6, // Start deeper to see more strategic patterns
-		nodes:       make(map[string]*SearchNode),
-		expandQueue: make(chan *SearchNode, 100), // buffered queue
-		ctx:         ctx,
-		cancel:      cancel,
+		maxDepth:       6, // Start deeper to see more strategic patterns
+		nodes:          make(map[string]*SearchNode),
+		expandQueue:    make(chan *SearchNode, 100), // buffered queue
+		propagateQueue: make(chan struct{}, 1),      // one pending request is enough
+		ctx:            ctx,
+		cancel:         cancel,
 	}
 
-	// Start background worker for expanding nodes
+	// Start background workers for expanding nodes and propagating scores
 	go bot.tree.backgroundExpander()
+	go bot.tree.backgroundPropagator()
 
 	return bot
 }
 
+// init registers PersistentMinimaxBot with the bot registry
+func init() {
+	RegisterBot(&BotRegistration{
+		Key:         "persistent",
+		DisplayName: "PersistentMinimaxBot",
+		Description: "persistent search tree with background calculation",
+		Order:       8,
+		Defaults:    map[string]int{"depth": 4, "base": 10},
+		New: func(symbol byte, name string, params map[string]int) BotInterface {
+			return NewPersistentMinimaxBot(symbol, name, params["depth"], params["base"])
+		},
+	})
+}
+
 // MakeMove implements BotInterface
-func (bot *PersistentMinimaxBot) MakeMove(board *Board) (string, [3]int) {
+func (bot *PersistentMinimaxBot) MakeMove(ctx context.Context, board *engine.Board) (Move, error) {
+	if err := ctx.Err(); err != nil {
+		return Move{}, err
+	}
+
 	bot.mutex.Lock()
 	defer bot.mutex.Unlock()
 
@@ -99,15 +128,15 @@ func (bot *PersistentMinimaxBot) MakeMove(board *Board) (string, [3]int) {
 	// Use a smarter approach - evaluate immediate scores for each move
 	validMoves := board.GetValidMoves()
 	bestMove := ""
-	bestScore := MIN_INT
+	bestScore := engine.MIN_INT
 	if !bot.rootNode.IsMaximizing {
-		bestScore = MAX_INT
+		bestScore = engine.MAX_INT
 	}
 
 	// Quick evaluation of immediate moves
 	for _, move := range validMoves {
-		testBoard := copyBoard(board)
-		coords := testBoard.Move(move, bot.Symbol)
+		testBoard := engine.CopyBoard(board)
+		coords := testBoard.Move(move, bot.Symbol())
 		if coords[0] != -1 {
 			score := testBoard.Score
 
@@ -125,19 +154,20 @@ func (bot *PersistentMinimaxBot) MakeMove(board *Board) (string, [3]int) {
 		bestMove = validMoves[0]
 	}
 
-	// Execute the move
-	coords := [3]int{-1, -1, -1}
-	if bestMove != "" {
-		coords = board.Move(bestMove, bot.Symbol)
+	hits, misses := bot.cache.Stats()
+	searchLog.Load().Debug("evaluation cache", "player", string(bot.Symbol()), "hits", hits, "misses", misses, "hitRate", bot.cache.HitRate())
 
+	// Execute the move
+	move, err := PlayChosenMove(ctx, board, bot.Symbol(), bestMove)
+	if err == nil {
 		// Update root to reflect our move
 		bot.moveRoot(bestMove)
 	}
 
-	return bestMove, coords
+	return move, err
 }
 
-// OpponentMove notifies the bot of opponent's move for tree pruning
+// OpponentMove notifies the bot of opponent's move for tree pruning (implements BotInterface)
 func (bot *PersistentMinimaxBot) OpponentMove(move string) {
 	bot.mutex.Lock()
 	defer bot.mutex.Unlock()
@@ -148,16 +178,16 @@ func (bot *PersistentMinimaxBot) OpponentMove(move string) {
 }
 
 // initializeRoot creates the initial root node and starts search
-func (bot *PersistentMinimaxBot) initializeRoot(board *Board) {
+func (bot *PersistentMinimaxBot) initializeRoot(board *engine.Board) {
 	rootID := "root"
 	ctx, cancel := context.WithCancel(bot.tree.ctx)
 
 	bot.rootNode = &SearchNode{
 		ID:           rootID,
-		Board:        copyBoard(board),
+		Board:        engine.CopyBoard(board),
 		Move:         "",
 		Depth:        0,
-		IsMaximizing: bot.Symbol == 'x',
+		IsMaximizing: bot.Symbol() == 'x',
 		Children:     make(map[string]*SearchNode),
 		ctx:          ctx,
 		cancel:       cancel,
@@ -166,13 +196,16 @@ func (bot *PersistentMinimaxBot) initializeRoot(board *Board) {
 
 	bot.tree.root = bot.rootNode
 	bot.tree.nodes[rootID] = bot.rootNode
+	bot.tree.nodeSize = searchNodeSize(board)
+	reserveMemory(MemoryTree, bot.tree.nodeSize, bot.tree.nodeSize)
+	treeLog.Load().Debug("search tree started", "bot", bot.Name(), "max_depth", bot.tree.maxDepth)
 
 	// Start expanding from root
 	go bot.expandNode(bot.rootNode)
 }
 
 // updateRoot updates the root to match current board state
-func (bot *PersistentMinimaxBot) updateRoot(board *Board) {
+func (bot *PersistentMinimaxBot) updateRoot(board *engine.Board) {
 	// For now, reinitialize if board state doesn't match
 	// TODO: Implement smart root finding based on board comparison
 	bot.cleanup()
@@ -192,6 +225,7 @@ func (bot *PersistentMinimaxBot) moveRoot(move string) {
 	if !exists {
 		// Move not in our search tree, need to cleanup but avoid deadlock
 		bot.tree.mutex.Unlock()
+		treeLog.Load().Debug("move outside the search tree, discarding it", "bot", bot.Name(), "move", move)
 		bot.cleanup() // Release lock before cleanup to avoid deadlock
 		return
 	}
@@ -215,7 +249,8 @@ func (bot *PersistentMinimaxBot) moveRoot(move string) {
 
 	// Clean up old root
 	oldRoot.cancel()
-	delete(bot.tree.nodes, oldRoot.ID)
+	bot.tree.remove(oldRoot.ID)
+	treeLog.Load().Debug("search tree root moved", "bot", bot.Name(), "move", move, "nodes", len(bot.tree.nodes))
 
 	bot.tree.mutex.Unlock() // Don't forget to unlock at the end
 }
@@ -251,8 +286,8 @@ func (bot *PersistentMinimaxBot) expandNode(node *SearchNode) {
 				// We're a leaf, calculate score if not done
 				if !node.calculating {
 					node.calculating = true
-					node.Score = node.Board.Evaluate()
-					bot.propagateScore(node)
+					node.Score = bot.cache.Evaluate(node.Board)
+					bot.markDirty(node)
 				}
 				node.mutex.Unlock()
 
@@ -276,8 +311,15 @@ func (bot *PersistentMinimaxBot) expandNode(node *SearchNode) {
 					validMoves = validMoves[:maxChildren]
 				}
 
+				// Out of memory, the node stays a leaf until pruning frees some
+				if !bot.tree.reserveChildren(len(validMoves)) {
+					node.mutex.Unlock()
+					time.Sleep(100 * time.Millisecond)
+					continue
+				}
+
 				for _, move := range validMoves {
-					childBoard := copyBoard(node.Board)
+					childBoard := engine.CopyBoard(node.Board)
 					childBoard.Move(move, symbol)
 
 					childID := node.ID + "_" + move
@@ -294,7 +336,7 @@ func (bot *PersistentMinimaxBot) expandNode(node *SearchNode) {
 						ctx:          ctx,
 						cancel:       cancel,
 						goroutine:    make(chan struct{}),
-						Score:        childBoard.Evaluate(), // Initialize with board evaluation
+						Score:        bot.cache.Evaluate(childBoard), // Initialize with board evaluation
 					}
 
 					node.Children[move] = child
@@ -303,6 +345,7 @@ func (bot *PersistentMinimaxBot) expandNode(node *SearchNode) {
 					bot.tree.mutex.Lock()
 					bot.tree.nodes[childID] = child
 					bot.tree.mutex.Unlock()
+					searchedNodes.Add(1)
 
 					// Start goroutine for child
 					go bot.expandNode(child)
@@ -310,8 +353,10 @@ func (bot *PersistentMinimaxBot) expandNode(node *SearchNode) {
 
 				node.expanded = true
 
-				// Immediately propagate initial scores up
-				bot.propagateScore(node)
+				// Schedule the initial child scores for the next propagation pass
+				node.dirty = true
+				bot.markDirty(node)
+				bot.tree.requestPropagation()
 			}
 
 			node.mutex.Unlock()
@@ -322,48 +367,95 @@ func (bot *PersistentMinimaxBot) expandNode(node *SearchNode) {
 	}
 }
 
-// propagateScore propagates a score change up the tree
-func (bot *PersistentMinimaxBot) propagateScore(node *SearchNode) {
+// markDirty flags every ancestor of node as needing recomputation
+// The walk stops at the first ancestor that is already dirty, since everything above it is dirty too
+func (bot *PersistentMinimaxBot) markDirty(node *SearchNode) {
 	current := node.Parent
 
 	for current != nil {
-		// Collect child scores first to avoid holding multiple locks
-		var childScores []int
-		current.mutex.RLock()
-		childCount := len(current.Children)
-		if childCount > 0 {
-			childScores = make([]int, 0, childCount)
-			for _, child := range current.Children {
-				child.mutex.RLock()
-				childScores = append(childScores, child.Score)
-				child.mutex.RUnlock()
-			}
+		current.mutex.Lock()
+		alreadyDirty := current.dirty
+		current.dirty = true
+		current.mutex.Unlock()
+
+		if alreadyDirty {
+			return
 		}
-		isMaximizing := current.IsMaximizing
-		current.mutex.RUnlock()
-
-		// Calculate best score without holding locks
-		if len(childScores) > 0 {
-			bestScore := MIN_INT
-			if !isMaximizing {
-				bestScore = MAX_INT
-			}
+		current = current.Parent
+	}
+}
 
-			for _, score := range childScores {
-				if isMaximizing && score > bestScore {
-					bestScore = score
-				} else if !isMaximizing && score < bestScore {
-					bestScore = score
-				}
-			}
+// requestPropagation asks the background propagator to run a pass as soon as possible
+// Requests made while one is already pending are coalesced
+func (tree *SearchTree) requestPropagation() {
+	select {
+	case tree.propagateQueue <- struct{}{}:
+	default:
+	}
+}
+
+// propagateDirty recomputes minimax scores bottom-up for every dirty node under node
+// Only one node lock is held at a time, so this never contends in lock order with the expanders
+func (tree *SearchTree) propagateDirty(node *SearchNode) int {
+	node.mutex.Lock()
+	if !node.dirty || len(node.Children) == 0 {
+		score := node.Score
+		node.dirty = false
+		node.mutex.Unlock()
+		return score
+	}
+
+	// Clear the flag before descending so that updates arriving mid-pass re-mark this node
+	node.dirty = false
+	children := make([]*SearchNode, 0, len(node.Children))
+	for _, child := range node.Children {
+		children = append(children, child)
+	}
+	isMaximizing := node.IsMaximizing
+	node.mutex.Unlock()
+
+	bestScore := engine.MIN_INT
+	if !isMaximizing {
+		bestScore = engine.MAX_INT
+	}
 
-			// Update score with minimal lock time
-			current.mutex.Lock()
-			current.Score = bestScore
-			current.mutex.Unlock()
+	for _, child := range children {
+		score := tree.propagateDirty(child)
+		if isMaximizing && score > bestScore {
+			bestScore = score
+		} else if !isMaximizing && score < bestScore {
+			bestScore = score
 		}
+	}
 
-		current = current.Parent
+	node.mutex.Lock()
+	node.Score = bestScore
+	node.mutex.Unlock()
+
+	return bestScore
+}
+
+// backgroundPropagator periodically (or on request) runs a single bottom-up propagation pass
+func (tree *SearchTree) backgroundPropagator() {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-tree.ctx.Done():
+			return
+
+		case <-ticker.C:
+		case <-tree.propagateQueue:
+		}
+
+		tree.mutex.RLock()
+		root := tree.root
+		tree.mutex.RUnlock()
+
+		if root != nil {
+			tree.propagateDirty(root)
+		}
 	}
 }
 
@@ -412,10 +504,42 @@ func (bot *PersistentMinimaxBot) killBranch(node *SearchNode) {
 
 	// Remove from tree with proper synchronization
 	bot.tree.mutex.Lock()
-	delete(bot.tree.nodes, node.ID)
+	bot.tree.remove(node.ID)
 	bot.tree.mutex.Unlock()
 }
 
+// reserveChildren reserves the memory of count new nodes, reporting whether the budget granted it
+// Once it is turned down, later expansions wait without asking until a node is removed; the caller holds no tree lock
+func (tree *SearchTree) reserveChildren(count int) bool {
+	if tree.starved.Load() {
+		return false
+	}
+	wanted := count * tree.nodeSize
+	if granted := reserveMemory(MemoryTree, wanted, 0); granted < wanted {
+		releaseMemory(MemoryTree, granted)
+		tree.starved.Store(true)
+		return false
+	}
+	return true
+}
+
+// remove takes the node with the given ID out of the tree, giving its memory back; the caller holds the tree's mutex
+func (tree *SearchTree) remove(id string) {
+	if _, ok := tree.nodes[id]; ok {
+		delete(tree.nodes, id)
+		releaseMemory(MemoryTree, tree.nodeSize)
+		tree.starved.Store(false)
+	}
+}
+
+// release gives the memory of every node left in the tree back, once its goroutines have stopped
+func (tree *SearchTree) release() {
+	tree.mutex.Lock()
+	defer tree.mutex.Unlock()
+	releaseMemory(MemoryTree, len(tree.nodes)*tree.nodeSize)
+	clear(tree.nodes)
+}
+
 // updateDepths recursively updates depths after root change
 func (bot *PersistentMinimaxBot) updateDepths(node *SearchNode, newDepth int) {
 	if node == nil {
@@ -436,6 +560,7 @@ func (bot *PersistentMinimaxBot) cleanup() {
 	if bot.tree != nil {
 		bot.tree.cancel()
 		bot.tree.wg.Wait()
+		bot.tree.release()
 	}
 
 	bot.rootNode = nil
@@ -443,27 +568,40 @@ func (bot *PersistentMinimaxBot) cleanup() {
 	// Reinitialize tree with deeper search
 	ctx, cancel := context.WithCancel(context.Background())
 	bot.tree = &SearchTree{
-		maxDepth:    6, // Use deeper initial depth for better play
-		nodes:       make(map[string]*SearchNode),
-		expandQueue: make(chan *SearchNode, 100),
-		ctx:         ctx,
-		cancel:      cancel,
+		maxDepth:       6, // Use deeper initial depth for better play
+		nodes:          make(map[string]*SearchNode),
+		expandQueue:    make(chan *SearchNode, 100),
+		propagateQueue: make(chan struct{}, 1),
+		ctx:            ctx,
+		cancel:         cancel,
 	}
 
 	go bot.tree.backgroundExpander()
+	go bot.tree.backgroundPropagator()
 }
 
-// getName implements BotInterface
-func (bot *PersistentMinimaxBot) getName() string {
-	return bot.Name
-}
+// Close shuts down the bot and cleans up resources (implements BotInterface)
+// Unlike cleanup, no new tree is started, so no background goroutines outlive the bot
+func (bot *PersistentMinimaxBot) Close() {
+	bot.mutex.Lock()
+	defer bot.mutex.Unlock()
 
-// getSymbol implements BotInterface
-func (bot *PersistentMinimaxBot) getSymbol() byte {
-	return bot.Symbol
+	if bot.tree != nil {
+		bot.tree.cancel()
+		bot.tree.wg.Wait()
+		bot.tree.release()
+	}
+	bot.rootNode = nil
+	bot.cache.Release()
 }
 
-// Close shuts down the bot and cleans up resources
-func (bot *PersistentMinimaxBot) Close() {
-	bot.cleanup()
+// NodeCount returns the number of nodes in the bot's search tree
+func (bot *PersistentMinimaxBot) NodeCount() int {
+	if bot.tree == nil {
+		return 0
+	}
+
+	bot.tree.mutex.RLock()
+	defer bot.tree.mutex.RUnlock()
+	return len(bot.tree.nodes)
 }
@@ -0,0 +1,205 @@
+package bots
+
+import (
+	"context"
+	"sort"
+
+	"tic-tac-toe-3d-bots/engine"
+)
+
+// QubicBot knows the classic 4x4x4 board, four in a row, and consults what it knows before searching:
+// it wins or blocks on the spot, plays out forcing sequences, and takes its first moves from qubicOpenings.
+// A forcing sequence is a run of moves that each threaten to win on a cell the opponent must fill at once, ending in
+// two threats at the same time; with gravity a threat only forces a reply when its cell is the next of its column.
+// The bot looks for them among the key cells first, the sixteen corners and centre cells that lie on seven lines
+// each. Otherwise, and on other boards, it plays alpha-beta at its depth
+type QubicBot struct {
+	BaseBot
+	Depth     int
+	Forcing   int                   // Most moves of the bot's own in a forcing sequence it looks for
+	Evaluator engine.Evaluator      // How the bot scores positions, whatever the board's own evaluator
+	progress  func(SearchIteration) // Receives each iteration of the alpha-beta search while set, see ReportProgress
+}
+
+// NewQubicBot creates a new Qubic bot with the given symbol, name, search depth, forcing sequence length and evaluator
+func NewQubicBot(symbol byte, name string, depth int, forcing int, evaluator engine.Evaluator) *QubicBot {
+	return &QubicBot{
+		BaseBot:   NewBaseBot(symbol, name),
+		Depth:     depth,
+		Forcing:   forcing,
+		Evaluator: evaluator,
+	}
+}
+
+// init registers QubicBot with the bot registry
+func init() {
+	RegisterBot(&BotRegistration{
+		Key:         "qubic",
+		DisplayName: "QubicBot",
+		Description: "precomputed openings and forcing sequences for 4x4x4, then alpha-beta",
+		Order:       12,
+		Defaults:    withEvaluatorDefaults(map[string]int{"depth": 6, "forcing": 6}),
+		New: func(symbol byte, name string, params map[string]int) BotInterface {
+			return NewQubicBot(symbol, name, params["depth"], params["forcing"], evaluatorFromParams(params))
+		},
+	})
+}
+
+// MakeMove plays what the bot knows of the position, or else the alpha-beta move (implements BotInterface)
+func (bot *QubicBot) MakeMove(ctx context.Context, board *engine.Board) (Move, error) {
+	validMoves := board.GetValidMoves()
+	if len(validMoves) == 0 {
+		return Move{}, ErrNoValidMoves
+	}
+	defer useEvaluator(board, bot.Evaluator)()
+
+	if isQubicBoard(board) {
+		opponent := engine.OpponentSymbol(bot.Symbol())
+		for _, symbol := range []byte{bot.Symbol(), opponent} {
+			if move := FindWinningMove(board, validMoves, symbol); move != "" {
+				return PlayChosenMove(ctx, board, bot.Symbol(), move)
+			}
+		}
+		if move := FindForcingWin(ctx, board, bot.Symbol(), bot.Forcing); move != "" {
+			searchLog.Load().Debug("forcing sequence found", "player", string(bot.Symbol()), "move", move)
+			return PlayChosenMove(ctx, board, bot.Symbol(), move)
+		}
+		if move, found := qubicOpeningMove(board); found {
+			return PlayChosenMove(ctx, board, bot.Symbol(), move)
+		}
+	}
+
+	_, bestMoves := deepeningAlphaBeta(board, bot.Depth, bot.Symbol(), bot.progress, ctx)
+	return PlayChosenMove(ctx, board, bot.Symbol(), FirstMove(bestMoves))
+}
+
+// ReportProgress has the bot's alpha-beta search deepen iteratively, passing each completed iteration to report, or
+// search at its depth at once again if report is nil (implements ProgressReporter). The moves it knows, from its openings
+// or a forcing sequence, are played without a search and report nothing
+func (bot *QubicBot) ReportProgress(report func(SearchIteration)) {
+	bot.progress = report
+}
+
+// isQubicBoard reports whether board is the 4x4x4 board with four in a row winning
+func isQubicBoard(board *engine.Board) bool {
+	return board.Length == 4 && board.Width == 4 && board.Height == 4 && board.WinLength == 4
+}
+
+// qubicOpeningMove looks the position up in qubicOpenings under each of the board's symmetries, and returns its move
+// mapped back onto the board
+func qubicOpeningMove(board *engine.Board) (string, bool) {
+	if board.MoveCount() > QUBIC_OPENING_PIECES {
+		return "", false
+	}
+	for symmetry := range board.Symmetries() {
+		move, found := qubicOpenings[board.Transformed(symmetry).Snapshot()]
+		if !found {
+			continue
+		}
+		for _, candidate := range board.GetValidMoves() {
+			if board.TransformMove(candidate, symmetry) == move {
+				return candidate, true
+			}
+		}
+	}
+	return "", false
+}
+
+// FindForcingWin returns the first move of a forcing sequence of at most length moves that wins for symbol, the player
+// to move, or "" if there is none (see QubicBot); it works on any board
+func FindForcingWin(ctx context.Context, board *engine.Board, symbol byte, length int) string {
+	if length < 1 {
+		return ""
+	}
+	lines := cellLines(board)
+	for _, move := range keyCellsFirst(board, lines, board.GetValidMoves()) {
+		if SearchCancelled(ctx) {
+			return ""
+		}
+		if forcesWin(ctx, board, lines, move, symbol, length) {
+			return move
+		}
+	}
+	return ""
+}
+
+// forcesWin reports whether move, played by symbol, starts a forcing sequence of at most length moves that wins
+// lines is the board's cellLines
+func forcesWin(ctx context.Context, board *engine.Board, lines [][][]int, move string, symbol byte, length int) bool {
+	board.Move(move, symbol)
+	defer board.UnMove(move)
+	if board.CheckWin() == symbol {
+		return true
+	}
+
+	// The move forces a reply if it threatens once, and wins if it threatens twice; either way the opponent must not win first
+	opponent := engine.OpponentSymbol(symbol)
+	validMoves := board.GetValidMoves()
+	if FindWinningMove(board, validMoves, opponent) != "" {
+		return false
+	}
+	threats := winningMoves(board, validMoves, symbol)
+	if len(threats) != 1 {
+		return len(threats) > 1
+	}
+	if length == 1 || SearchCancelled(ctx) {
+		return false
+	}
+
+	board.Move(threats[0], opponent)
+	defer board.UnMove(threats[0])
+	validMoves = board.GetValidMoves()
+	if FindWinningMove(board, validMoves, symbol) != "" {
+		return true // The forced reply let a piece of symbol's in on top of it
+	}
+	if block := FindWinningMove(board, validMoves, opponent); block != "" {
+		validMoves = []string{block} // The forced reply threatens in turn, so the next move must block it
+	}
+	for _, next := range keyCellsFirst(board, lines, validMoves) {
+		if forcesWin(ctx, board, lines, next, symbol, length-1) {
+			return true
+		}
+	}
+	return false
+}
+
+// winningMoves returns the moves of validMoves that immediately win for symbol
+func winningMoves(board *engine.Board, validMoves []string, symbol byte) []string {
+	var wins []string
+	for _, move := range validMoves {
+		board.Move(move, symbol)
+		if board.CheckWin() == symbol {
+			wins = append(wins, move)
+		}
+		board.UnMove(move)
+	}
+	return wins
+}
+
+// cellLines returns the number of lines through each cell of board, by coordinates
+func cellLines(board *engine.Board) [][][]int {
+	lines := make([][][]int, board.Length)
+	for x := range lines {
+		lines[x] = make([][]int, board.Width)
+		for y := range lines[x] {
+			lines[x][y] = make([]int, board.Height)
+			for z := range lines[x][y] {
+				lines[x][y][z] = board.LinesThrough(x, y, z)
+			}
+		}
+	}
+	return lines
+}
+
+// keyCellsFirst orders moves by the number of lines through the cell each lands on, most first, keeping the order of
+// moves landing on equal cells; lines is the board's cellLines
+func keyCellsFirst(board *engine.Board, lines [][][]int, moves []string) []string {
+	landing := make(map[string]int, len(moves))
+	for _, move := range moves {
+		col, row := engine.ParseMove(move)
+		landing[move] = lines[col][row][board.CurrentHeights[col][row]]
+	}
+	ordered := append([]string(nil), moves...)
+	sort.SliceStable(ordered, func(i, j int) bool { return landing[ordered[i]] > landing[ordered[j]] })
+	return ordered
+}
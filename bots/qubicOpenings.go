@@ -0,0 +1,29 @@
+package bots
+
+// QUBIC_OPENING_PIECES is the most pieces of a position in qubicOpenings
+const QUBIC_OPENING_PIECES = 2
+
+// qubicOpenings are QubicBot's precomputed opening moves on the 4x4x4 board: the move to play, by Snapshot, for x's
+// first move, o's reply to every first move, and x's second move after every reply to the first. Each position
+// stands for all those its symmetries map it to (see qubicOpeningMove).
+// They are not solved-game knowledge: Qubic was solved without gravity (O. Patashnik, "Qubic: 4x4x4 Tic-Tac-Toe",
+// Mathematics Magazine 53(4), 1980; L. V. Allis, "Searching for Solutions in Games and Artificial Intelligence",
+// 1994), and that solution does not carry over to columns filled from the bottom. Each move is the one AlphaBetaMinimax
+// picks searching the position 8 plies deep with an evaluator of base 10, direction weights 100, 150, 100 and 100, and
+// no playability, parity or fork terms, kept here so the bot does not search it again
+var qubicOpenings = map[string]string{
+	"4x4x4/4 -,-,-,-/-,-,-,-/-,-,-,-/-,-,-,- x":  "A1",
+	"4x4x4/4 x,-,-,-/-,-,-,-/-,-,-,-/-,-,-,- o":  "B2",
+	"4x4x4/4 -,x,-,-/-,-,-,-/-,-,-,-/-,-,-,- o":  "B2",
+	"4x4x4/4 -,-,-,-/-,x,-,-/-,-,-,-/-,-,-,- o":  "A1",
+	"4x4x4/4 xo,-,-,-/-,-,-,-/-,-,-,-/-,-,-,- x": "D4",
+	"4x4x4/4 x,-,-,-/o,-,-,-/-,-,-,-/-,-,-,- x":  "B2",
+	"4x4x4/4 x,-,-,-/-,-,-,-/o,-,-,-/-,-,-,- x":  "D1",
+	"4x4x4/4 x,-,-,-/-,-,-,-/-,-,-,-/o,-,-,- x":  "D1",
+	"4x4x4/4 x,-,-,-/-,o,-,-/-,-,-,-/-,-,-,- x":  "A1",
+	"4x4x4/4 x,-,-,-/-,-,-,-/-,o,-,-/-,-,-,- x":  "D1",
+	"4x4x4/4 x,-,-,-/-,-,-,-/-,-,-,-/-,o,-,- x":  "D4",
+	"4x4x4/4 x,-,-,-/-,-,-,-/-,-,o,-/-,-,-,- x":  "A4",
+	"4x4x4/4 x,-,-,-/-,-,-,-/-,-,-,-/-,-,o,- x":  "A4",
+	"4x4x4/4 x,-,-,-/-,-,-,-/-,-,-,-/-,-,-,o x":  "A4",
+}
@@ -0,0 +1,61 @@
+package bots
+
+import (
+	"context"
+
+	"tic-tac-toe-3d-bots/engine"
+)
+
+// Bot represents a simple AI player
+type Bot struct {
+	BaseBot
+}
+
+// NewBot creates a new bot with the given symbol and name
+func NewBot(symbol byte, name string) *Bot {
+	return &Bot{
+		BaseBot: NewBaseBot(symbol, name),
+	}
+}
+
+// init registers RandomBot with the bot registry
+func init() {
+	RegisterBot(&BotRegistration{
+		Key:         "random",
+		DisplayName: "RandomBot",
+		Description: "makes random moves",
+		Order:       1,
+		Defaults:    map[string]int{},
+		New: func(symbol byte, name string, params map[string]int) BotInterface {
+			return NewBot(symbol, name)
+		},
+	})
+}
+
+// MakeMove makes a random valid move on the board (implements BotInterface)
+func (bot *Bot) MakeMove(ctx context.Context, board *engine.Board) (Move, error) {
+	if err := ctx.Err(); err != nil {
+		return Move{}, err
+	}
+	move, coords := bot.MakeRandomMove(board)
+	if move == "" {
+		return Move{}, ErrNoValidMoves
+	}
+	return Move{Name: move, Coords: coords}, nil
+}
+
+// MakeRandomMove makes a random valid move on the board
+func (bot *Bot) MakeRandomMove(board *engine.Board) (string, [3]int) {
+	validMoves := board.GetValidMoves()
+	if len(validMoves) == 0 {
+		return "", [3]int{-1, -1, -1}
+	}
+
+	// Pick a random valid move
+	randomIndex := bot.Random().Intn(len(validMoves))
+	chosenMove := validMoves[randomIndex]
+
+	// Make the move
+	coords := board.Move(chosenMove, bot.Symbol())
+	return chosenMove, coords
+}
@@ -0,0 +1,71 @@
+package bots
+
+import (
+	"context"
+
+	"tic-tac-toe-3d-bots/engine"
+)
+
+// RuleBot represents a simple rule-based AI player
+// It takes an immediate win if there is one, blocks the opponent's immediate win, and otherwise plays randomly
+type RuleBot struct {
+	BaseBot
+}
+
+// NewRuleBot creates a new rule-based bot with the given symbol and name
+func NewRuleBot(symbol byte, name string) *RuleBot {
+	return &RuleBot{
+		BaseBot: NewBaseBot(symbol, name),
+	}
+}
+
+// init registers RuleBot with the bot registry
+func init() {
+	RegisterBot(&BotRegistration{
+		Key:         "rules",
+		DisplayName: "RuleBot",
+		Description: "wins or blocks when it can, otherwise random",
+		Order:       9,
+		Defaults:    map[string]int{},
+		New: func(symbol byte, name string, params map[string]int) BotInterface {
+			return NewRuleBot(symbol, name)
+		},
+	})
+}
+
+// MakeMove makes a rule-based move (implements BotInterface)
+func (bot *RuleBot) MakeMove(ctx context.Context, board *engine.Board) (Move, error) {
+	validMoves := board.GetValidMoves()
+	if len(validMoves) == 0 {
+		return Move{}, ErrNoValidMoves
+	}
+
+	opponent := byte('o')
+	if bot.Symbol() == 'o' {
+		opponent = 'x'
+	}
+
+	// Rule 1: win immediately, Rule 2: block the opponent's immediate win
+	for _, symbol := range []byte{bot.Symbol(), opponent} {
+		if move := FindWinningMove(board, validMoves, symbol); move != "" {
+			return PlayChosenMove(ctx, board, bot.Symbol(), move)
+		}
+	}
+
+	// Rule 3: play randomly
+	return PlayChosenMove(ctx, board, bot.Symbol(), validMoves[bot.Random().Intn(len(validMoves))])
+}
+
+// FindWinningMove returns a move from validMoves that immediately wins for symbol, or "" if there is none
+func FindWinningMove(board *engine.Board, validMoves []string, symbol byte) string {
+	for _, move := range validMoves {
+		board.Move(move, symbol)
+		wins := board.CheckWin() == symbol
+		board.UnMove(move)
+
+		if wins {
+			return move
+		}
+	}
+	return ""
+}
@@ -0,0 +1,119 @@
+package bots
+
+import (
+	"sync/atomic"
+	"unsafe"
+
+	"tic-tac-toe-3d-bots/engine"
+)
+
+// SearchMemory is a kind of search structure whose memory the bots account for
+type SearchMemory int
+
+const (
+	MemoryTT        SearchMemory = iota // transposition tables, shared or not
+	MemoryEvalCache                     // evaluation caches
+	MemoryTree                          // the persistent bot's search trees
+	memoryKinds
+)
+
+// searchMemoryNames name the kinds of search memory in logs and metrics
+var searchMemoryNames = [memoryKinds]string{"tt", "evalcache", "tree"}
+
+// String returns the kind's name, e.g. "tt"
+func (kind SearchMemory) String() string {
+	return searchMemoryNames[kind]
+}
+
+// Every bot of the run draws the memory of its search structures from one budget, capped with --search-memory:
+// a structure reserves memory before growing, and what it is not granted it does without. Transposition tables are
+// made smaller, evaluation caches evict their oldest positions, and search trees stop growing. Memory is given back
+// when the structure is emptied or its bot closed. The amounts are estimates of the structures' own allocations,
+// close enough to keep a run within a machine's memory, not an exact account of the heap
+var (
+	memoryLimit   atomic.Int64              // in bytes, 0 for no cap
+	memoryUsed    [memoryKinds]atomic.Int64 // in bytes
+	memoryCapHits [memoryKinds]atomic.Int64 // reservations cut short by the cap
+	memoryTotal   atomic.Int64              // of memoryUsed, kept so reservations check the cap with one load
+)
+
+// SetSearchMemory caps the memory of every bot's search structures at mib MiB; 0 removes the cap
+// Structures already grown past a lower cap keep their memory, but grow no further until enough is given back
+func SetSearchMemory(mib int) {
+	memoryLimit.Store(int64(max(mib, 0)) << 20)
+}
+
+// SearchMemoryLimit returns the cap on the memory of search structures in MiB, 0 if there is none
+func SearchMemoryLimit() int {
+	return int(memoryLimit.Load() >> 20)
+}
+
+// SearchMemoryStats describes the memory search structures hold
+type SearchMemoryStats struct {
+	Limit   int64            `json:"limit"` // cap in bytes, 0 for none
+	Used    int64            `json:"used"`  // bytes held by every kind of structure together
+	ByKind  map[string]int64 `json:"by_kind"`
+	CapHits map[string]int64 `json:"cap_hits"` // reservations of each kind cut short by the cap
+}
+
+// SearchMemoryUsage returns the memory search structures hold now, by kind
+func SearchMemoryUsage() SearchMemoryStats {
+	stats := SearchMemoryStats{Limit: memoryLimit.Load(), Used: memoryTotal.Load(),
+		ByKind: make(map[string]int64, memoryKinds), CapHits: make(map[string]int64, memoryKinds)}
+	for kind := range memoryKinds {
+		stats.ByKind[kind.String()] = memoryUsed[kind].Load()
+		stats.CapHits[kind.String()] = memoryCapHits[kind].Load()
+	}
+	return stats
+}
+
+// reserveMemory reserves bytes of the budget for a structure of the given kind, and returns how many it was granted:
+// all of them, or as many as the cap leaves, though never fewer than minimum. A reservation cut short is logged
+func reserveMemory(kind SearchMemory, bytes, minimum int) int {
+	granted := int64(bytes)
+	for {
+		total, limit := memoryTotal.Load(), memoryLimit.Load()
+		if limit > 0 && total+granted > limit {
+			granted = max(limit-total, int64(minimum), 0)
+		}
+		if memoryTotal.CompareAndSwap(total, total+granted) {
+			break
+		}
+		granted = int64(bytes)
+	}
+	memoryUsed[kind].Add(granted)
+
+	if granted < int64(bytes) {
+		memoryCapHits[kind].Add(1)
+		searchLog.Load().Warn("search memory cap reached", "kind", kind.String(), "wanted", bytes, "granted", granted,
+			"used", memoryTotal.Load(), "limit", memoryLimit.Load())
+	}
+	return int(granted)
+}
+
+// releaseMemory gives bytes reserved for a structure of the given kind back to the budget
+func releaseMemory(kind SearchMemory, bytes int) {
+	memoryUsed[kind].Add(-int64(bytes))
+	memoryTotal.Add(-int64(bytes))
+}
+
+// Estimates of the memory of single entries of the structures that grow an entry at a time
+const (
+	// EVAL_CACHE_ENTRY_SIZE is the memory a position cached by an EvalCache takes: its list element, its boxed
+	// entry and its map slot
+	EVAL_CACHE_ENTRY_SIZE = 128
+
+	// searchNodeOverhead is the memory of a search tree node besides its struct and board: its children map, its
+	// context and channel, and its slot in the tree's node map
+	searchNodeOverhead = 512
+)
+
+// searchNodeSize estimates the memory a node of a persistent search tree over board takes
+func searchNodeSize(board *engine.Board) int {
+	cells := board.Length * board.Width * board.Height
+	columns := board.Length * board.Width
+	grid := cells + (columns+board.Length)*int(unsafe.Sizeof([]byte{})) // Cells, and the row and column slice headers
+	heights := columns*int(unsafe.Sizeof(0)) + board.Length*int(unsafe.Sizeof([]int{}))
+	counters := board.LineCount() * int(unsafe.Sizeof(uint32(0)))
+	return int(unsafe.Sizeof(SearchNode{})+unsafe.Sizeof(engine.Board{})) + grid + heights + counters + searchNodeOverhead
+}
@@ -0,0 +1,56 @@
+package bots
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// RunSeed is the seed every random choice of the run derives from: set with --seed, or picked from the clock
+// Running again with the same seed replays the same games, as long as bot moves do not depend on time limits
+var RunSeed int64
+
+var (
+	seedSource = rand.New(rand.NewSource(time.Now().UnixNano())) // hands out the seeds of bots and matches
+	seedMutex  sync.Mutex
+)
+
+// SetSeed makes every later random choice derive from seed; 0 picks a seed from the clock
+func SetSeed(seed int64) {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	seedMutex.Lock()
+	RunSeed = seed
+	seedSource = rand.New(rand.NewSource(seed))
+	seedMutex.Unlock()
+}
+
+// NextSeed returns the next seed from the run's seed source
+func NextSeed() int64 {
+	seedMutex.Lock()
+	defer seedMutex.Unlock()
+	return seedSource.Int63()
+}
+
+// Random returns the bot's own random source, seeded from the run's seed the first time it is used
+func (base *BaseBot) Random() *rand.Rand {
+	if base.rng == nil {
+		base.rng = rand.New(rand.NewSource(NextSeed()))
+	}
+	return base.rng
+}
+
+// reseed restarts the bot's random source from seed
+func (base *BaseBot) reseed(seed int64) {
+	base.rng = rand.New(rand.NewSource(seed))
+}
+
+// SeedBot restarts bot's random source from seed if it has one
+// Matches seed the bots of each game this way, so games played in parallel do not depend on which worker runs first
+func SeedBot(bot BotInterface, seed int64) BotInterface {
+	if seeded, ok := bot.(interface{ reseed(int64) }); ok {
+		seeded.reseed(seed)
+	}
+	return bot
+}
@@ -0,0 +1,432 @@
+package bots
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"tic-tac-toe-3d-bots/engine"
+)
+
+// TTLocking selects how the parallel searches sharing a SharedTT keep out of each other's way
+type TTLocking int
+
+const (
+	TTLockFree TTLocking = iota + 1 // entries are written as two atomic words, the key xor'd with the data: a torn entry reads as a miss
+	TTSharded                       // the table is split into shards, each behind a lock of its own
+)
+
+// DEFAULT_TT_LOCKING is the locking of the shared transposition tables of bots not given one, until SetTTLocking
+// changes it
+const DEFAULT_TT_LOCKING = TTLockFree
+
+// DEFAULT_TT_SHARDS is the number of shards of a sharded table not given one
+const DEFAULT_TT_SHARDS = 64
+
+// MAX_TT_SHARDS is the most shards a sharded table can be split into; NewSharedTT allocates every shard up front
+const MAX_TT_SHARDS = 1024
+
+// ttLockingNames are the names of the lockings, as --tt-locking takes them
+var ttLockingNames = map[TTLocking]string{
+	TTLockFree: "lockfree",
+	TTSharded:  "sharded",
+}
+
+// String returns the locking's name, e.g. "sharded"
+func (locking TTLocking) String() string {
+	if name, ok := ttLockingNames[locking]; ok {
+		return name
+	}
+	return fmt.Sprintf("TTLocking(%d)", int(locking))
+}
+
+// ParseTTLocking reads a locking name written by String
+func ParseTTLocking(name string) (TTLocking, error) {
+	for _, locking := range []TTLocking{TTLockFree, TTSharded} {
+		if strings.EqualFold(strings.TrimSpace(name), locking.String()) {
+			return locking, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown transposition table locking %q (expected %s or %s)", name, TTLockFree, TTSharded)
+}
+
+// ttLockingFromParams returns the locking of a bot's "ttlocking" parameter; 0 leaves the choice to TTLockingDefault
+func ttLockingFromParams(params map[string]int) TTLocking {
+	return TTLocking(params["ttlocking"])
+}
+
+// ttLocking is the locking of the shared tables of bots not given one, set with --tt-locking
+var ttLocking atomic.Int64
+
+func init() {
+	ttLocking.Store(int64(DEFAULT_TT_LOCKING))
+}
+
+// SetTTLocking sets the locking of the shared transposition tables of bots created from now on without a
+// "ttlocking" parameter
+func SetTTLocking(locking TTLocking) {
+	ttLocking.Store(int64(locking))
+}
+
+// TTLockingDefault returns the locking of the shared transposition tables of bots created without a "ttlocking"
+// parameter
+func TTLockingDefault() TTLocking {
+	return TTLocking(ttLocking.Load())
+}
+
+// Lock contention of every sharded table, for monitoring; locks taken without waiting are only counted by their
+// shards, which would otherwise all contend for the counter
+var (
+	ttLocksContended atomic.Int64
+	ttLockWait       atomic.Int64 // nanoseconds
+)
+
+// TTLockContention returns how many times the searches sharing sharded transposition tables found a shard's lock
+// held by another so far, and how long they waited for it in all
+func TTLockContention() (contended int64, waited time.Duration) {
+	return ttLocksContended.Load(), time.Duration(ttLockWait.Load())
+}
+
+// SharedTT is a transposition table the parallel searches of a bot share, with the replacement policy and aging of
+// a TranspositionTable. Lock-free, every slot is a pair of atomic words; sharded, it is a TranspositionTable per
+// shard, each behind a lock, whose contention it counts. It is safe for concurrent use during a search; Prepare and
+// Stats must not run alongside one
+type SharedTT struct {
+	locking  TTLocking
+	policy   ReplacementPolicy
+	bytes    int // wanted: the table takes less if the search memory budget grants less
+	reserved int // taken from the budget, 0 until the first Prepare
+
+	// Lock-free
+	words      []atomic.Uint64 // per slot, the key xor'd with the data, then the data (see packTTEntry)
+	generation uint8
+	evaluator  engine.Evaluator
+	size       [4]int
+	probes     atomic.Int64
+	hits       atomic.Int64
+	stores     atomic.Int64
+	used       atomic.Int64
+	replaced   atomic.Int64
+	stale      atomic.Int64
+	rejected   atomic.Int64
+
+	// Sharded
+	shards []ttShard
+
+	cutoffs atomic.Int64
+}
+
+// ttShard is one shard of a sharded SharedTT
+type ttShard struct {
+	mutex     sync.Mutex
+	table     *TranspositionTable
+	locks     int
+	contended int
+	waited    time.Duration
+	_         [24]byte // Rounds the shard up to a cache line, so searches locking neighbouring shards do not contend for one
+}
+
+// NewSharedTT creates an empty shared transposition table of mib MiB (TTSize if not positive) replacing entries by
+// policy (TTPolicy if 0), locked as locking says (TTLockingDefault if 0) in shards shards (DEFAULT_TT_SHARDS if not
+// positive) when sharded
+// It fails if the table is split into more than MAX_TT_SHARDS shards, or more than it has entries
+func NewSharedTT(mib int, policy ReplacementPolicy, locking TTLocking, shards int) (*SharedTT, error) {
+	if mib <= 0 {
+		mib = TTSize()
+	}
+	if err := checkTTShards(mib, shards); err != nil {
+		return nil, err
+	}
+	if policy == 0 {
+		policy = TTPolicy()
+	}
+	if locking == 0 {
+		locking = TTLockingDefault()
+	}
+	tt := &SharedTT{locking: locking, policy: policy}
+	if locking == TTSharded {
+		if shards <= 0 {
+			shards = DEFAULT_TT_SHARDS
+		}
+		tt.shards = make([]ttShard, shards)
+		for i := range tt.shards {
+			tt.shards[i].table = newTranspositionTable(mib<<20/shards, policy)
+			tt.shards[i].table.shard = true
+		}
+		tt.bytes = shards * tt.shards[0].table.bytes
+		return tt, nil
+	}
+
+	slots := mib << 20 / TT_PACKED_ENTRY_SIZE
+	if policy == ReplaceTwoSlot {
+		slots &^= 1 // Whole buckets
+	}
+	tt.bytes = slots * TT_PACKED_ENTRY_SIZE
+	return tt, nil
+}
+
+// checkTTShards reports an error unless a table of mib MiB (TTSize if not positive) can be split into shards shards
+// (DEFAULT_TT_SHARDS if not positive): at most MAX_TT_SHARDS, and no more than the table has entries
+func checkTTShards(mib, shards int) error {
+	if mib <= 0 {
+		mib = TTSize()
+	}
+	if shards <= 0 {
+		shards = DEFAULT_TT_SHARDS
+	}
+	if shards > MAX_TT_SHARDS {
+		return fmt.Errorf("a transposition table cannot be split into more than %d shards, got %d", MAX_TT_SHARDS, shards)
+	}
+	if entries := mib << 20 / TT_ENTRY_SIZE; shards > entries {
+		return fmt.Errorf("a transposition table of %d MiB has %d entries, too few for %d shards", mib, entries, shards)
+	}
+	return nil
+}
+
+// TT_PACKED_ENTRY_SIZE is the memory an entry of a lock-free SharedTT takes, in bytes
+const TT_PACKED_ENTRY_SIZE = 16
+
+// Prepare readies the table for a search of board, as TranspositionTable.Prepare does
+func (tt *SharedTT) Prepare(board *engine.Board) {
+	if tt.locking == TTSharded {
+		if tt.reserved == 0 {
+			// Every shard shrinks alike if the budget grants less than the whole table
+			bytes := reserveTTMemory(tt.bytes, tt.policy, TT_ENTRY_SIZE*len(tt.shards))
+			for i := range tt.shards {
+				tt.shards[i].table.bytes = bytes / len(tt.shards)
+			}
+			tt.reserved = bytes
+		}
+		for i := range tt.shards {
+			tt.shards[i].table.Prepare(board)
+		}
+		return
+	}
+
+	tt.generation = (tt.generation + 1) & ttPackedAgeMask
+	size := [4]int{board.Length, board.Width, board.Height, board.WinLength}
+	if tt.words == nil {
+		tt.reserved = reserveTTMemory(tt.bytes, tt.policy, TT_PACKED_ENTRY_SIZE)
+		tt.words = make([]atomic.Uint64, 2*tt.reserved/TT_PACKED_ENTRY_SIZE)
+	} else if board.Evaluator != tt.evaluator || size != tt.size {
+		for i := range tt.words {
+			tt.words[i].Store(0)
+		}
+		tt.used.Store(0)
+	}
+	tt.evaluator, tt.size = board.Evaluator, size
+}
+
+// Release empties the table and gives its memory back to the search memory budget; the next Prepare takes it again
+func (tt *SharedTT) Release() {
+	releaseMemory(MemoryTT, tt.reserved)
+	tt.reserved = 0
+	tt.words = nil
+	tt.used.Store(0)
+	for i := range tt.shards {
+		tt.shards[i].table.Release()
+	}
+}
+
+// shard returns the shard of the position with the given hash, locked; the caller unlocks it
+// Shards are picked by the high half of the hash, as the shard's own table picks slots by all of it
+func (tt *SharedTT) shard(hash uint64) *ttShard {
+	shard := &tt.shards[(hash>>32)%uint64(len(tt.shards))]
+	if shard.mutex.TryLock() {
+		shard.locks++
+		return shard
+	}
+	waiting := time.Now()
+	shard.mutex.Lock()
+	waited := time.Since(waiting)
+	shard.locks++
+	shard.contended++
+	shard.waited += waited
+	ttLocksContended.Add(1)
+	ttLockWait.Add(int64(waited))
+	return shard
+}
+
+// probe returns the entry of the position with the given hash, if the table holds it
+func (tt *SharedTT) probe(hash uint64) (ttEntry, bool) {
+	if tt.locking == TTSharded {
+		shard := tt.shard(hash)
+		defer shard.mutex.Unlock()
+		return shard.table.probe(hash)
+	}
+
+	tt.probes.Add(1)
+	first, count := tt.packedSlots(hash)
+	for i := first; i < first+count; i++ {
+		if entry := tt.loadPacked(i); entry.bound != 0 && entry.key == hash {
+			tt.hits.Add(1)
+			return entry, true
+		}
+	}
+	return ttEntry{}, false
+}
+
+// peek is probe without counting the lookup or the lock, for tracing (see startIterationSpan)
+func (tt *SharedTT) peek(hash uint64) (ttEntry, bool) {
+	if tt.locking == TTSharded {
+		shard := &tt.shards[(hash>>32)%uint64(len(tt.shards))]
+		shard.mutex.Lock()
+		defer shard.mutex.Unlock()
+		return shard.table.peek(hash)
+	}
+
+	first, count := tt.packedSlots(hash)
+	for i := first; i < first+count; i++ {
+		if entry := tt.loadPacked(i); entry.bound != 0 && entry.key == hash {
+			return entry, true
+		}
+	}
+	return ttEntry{}, false
+}
+
+// store records the result of searching the position with the given hash to depth, in the slot the policy picks
+// move is packed by packMove
+func (tt *SharedTT) store(hash uint64, depth, score int, bound uint8, move int16) {
+	if tt.locking == TTSharded {
+		shard := tt.shard(hash)
+		defer shard.mutex.Unlock()
+		shard.table.store(hash, depth, score, bound, move)
+		return
+	}
+
+	entry := ttEntry{key: hash, score: score, move: move, depth: uint8(min(depth, 255)), bound: bound, age: tt.generation}
+	tt.stores.Add(1)
+	first, count := tt.packedSlots(hash)
+	var bucket [2]ttEntry
+	slots := bucket[:count]
+	for i := range slots {
+		slots[i] = tt.loadPacked(first + i)
+	}
+	// Another search may write the slots meanwhile; one of the two entries is then lost, as if it had been replaced
+	index := replacedIndex(tt.policy, tt.generation, slots, entry)
+	if index < 0 {
+		tt.rejected.Add(1)
+		return
+	}
+	lost := slots[index]
+	demoted := demotes(tt.policy, slots, entry, index)
+	if demoted {
+		lost = slots[1]
+	}
+	switch {
+	case lost.bound == 0:
+		tt.used.Add(1)
+	case lost.key != hash:
+		tt.replaced.Add(1)
+		if lost.age != tt.generation {
+			tt.stale.Add(1)
+		}
+	}
+	if demoted {
+		data := packTTEntry(slots[0])
+		tt.words[2*(first+1)].Store(slots[0].key ^ data)
+		tt.words[2*(first+1)+1].Store(data)
+	}
+	data := packTTEntry(entry)
+	tt.words[2*(first+index)].Store(hash ^ data)
+	tt.words[2*(first+index)+1].Store(data)
+}
+
+// packedSlots returns the first slot the position with the given hash may be kept in, and how many: one, or a
+// bucket of two
+func (tt *SharedTT) packedSlots(hash uint64) (int, int) {
+	slots := uint64(len(tt.words) / 2)
+	if tt.policy == ReplaceTwoSlot {
+		return int(2 * (hash % (slots / 2))), 2
+	}
+	return int(hash % slots), 1
+}
+
+// loadPacked reads the entry in a lock-free slot; one written halfway by another search reads as empty
+func (tt *SharedTT) loadPacked(slot int) ttEntry {
+	check, data := tt.words[2*slot].Load(), tt.words[2*slot+1].Load()
+	entry := unpackTTEntry(data)
+	entry.key = check ^ data
+	return entry
+}
+
+// ttPackedAgeMask keeps the generations of lock-free entries to the 6 bits they are packed into
+const ttPackedAgeMask = 1<<6 - 1
+
+// ttPackedForcedScore stands for forced wins, whose scores do not fit the 32 bits a packed score has
+const ttPackedForcedScore = math.MaxInt32
+
+// packTTEntry packs an entry but its key into one word: the score in the high 32 bits, then the move, the depth,
+// and the bound and age sharing the low byte. Scores beyond 32 bits are clamped, forced results kept as such
+func packTTEntry(entry ttEntry) uint64 {
+	score := entry.score
+	switch {
+	case score >= engine.MAX_INT/2:
+		score = ttPackedForcedScore
+	case score <= engine.MIN_INT/2:
+		score = -ttPackedForcedScore
+	default:
+		score = min(max(score, -ttPackedForcedScore+1), ttPackedForcedScore-1)
+	}
+	return uint64(uint32(int32(score)))<<32 | uint64(uint16(entry.move))<<16 | uint64(entry.depth)<<8 |
+		uint64(entry.bound&3)<<6 | uint64(entry.age&ttPackedAgeMask)
+}
+
+// unpackTTEntry reverses packTTEntry, but for the key
+func unpackTTEntry(data uint64) ttEntry {
+	score := int(int32(uint32(data >> 32)))
+	switch score {
+	case ttPackedForcedScore:
+		score = engine.MAX_INT / 2
+	case -ttPackedForcedScore:
+		score = engine.MIN_INT / 2
+	}
+	return ttEntry{score: score, move: int16(uint16(data >> 16)), depth: uint8(data >> 8), bound: uint8(data>>6) & 3,
+		age: uint8(data) & ttPackedAgeMask}
+}
+
+// countCutoff records a search answered by an entry of the table
+func (tt *SharedTT) countCutoff() {
+	tt.cutoffs.Add(1)
+}
+
+// Stats returns the table's policy, locking, size, fill and use so far, with every shard's added together
+func (tt *SharedTT) Stats() TTStats {
+	if tt.locking != TTSharded {
+		bytes := tt.bytes
+		if tt.reserved > 0 {
+			bytes = tt.reserved
+		}
+		return TTStats{Policy: tt.policy.String(), Locking: tt.locking.String(), Bytes: bytes,
+			Entries: bytes / TT_PACKED_ENTRY_SIZE, Used: int(tt.used.Load()), Probes: int(tt.probes.Load()),
+			Hits: int(tt.hits.Load()), Cutoffs: int(tt.cutoffs.Load()), Stores: int(tt.stores.Load()),
+			Overwritten: int(tt.replaced.Load()), Stale: int(tt.stale.Load()), Rejected: int(tt.rejected.Load())}
+	}
+
+	total := TTStats{Policy: tt.policy.String(), Locking: tt.locking.String(), Cutoffs: int(tt.cutoffs.Load())}
+	var waited time.Duration
+	for i := range tt.shards {
+		shard := &tt.shards[i]
+		shard.mutex.Lock()
+		stats := shard.table.Stats()
+		total.Locks += shard.locks
+		total.Contended += shard.contended
+		waited += shard.waited
+		shard.mutex.Unlock()
+
+		total.Bytes += stats.Bytes
+		total.Entries += stats.Entries
+		total.Used += stats.Used
+		total.Probes += stats.Probes
+		total.Hits += stats.Hits
+		total.Stores += stats.Stores
+		total.Overwritten += stats.Overwritten
+		total.Stale += stats.Stale
+		total.Rejected += stats.Rejected
+	}
+	total.LockWaitMS = float64(waited) / float64(time.Millisecond)
+	return total
+}
@@ -0,0 +1,96 @@
+package bots
+
+import (
+	"sync"
+	"testing"
+
+	"tic-tac-toe-3d-bots/engine"
+)
+
+func TestPackTTEntry(t *testing.T) {
+	tests := []struct {
+		entry ttEntry
+		score int // score read back
+	}{
+		{ttEntry{score: 0, move: -1, depth: 0, bound: ttExact, age: 0}, 0},
+		{ttEntry{score: -123456, move: 4095, depth: 255, bound: ttLower, age: 63}, -123456},
+		{ttEntry{score: engine.MAX_INT / 2, move: 7, depth: 12, bound: ttUpper, age: 1}, engine.MAX_INT / 2},
+		{ttEntry{score: engine.MIN_INT/2 - 5, move: 7, depth: 12, bound: ttUpper, age: 1}, engine.MIN_INT / 2},
+		{ttEntry{score: 1 << 40, move: 7, depth: 12, bound: ttExact, age: 1}, ttPackedForcedScore - 1},
+		{ttEntry{score: -(1 << 40), move: 7, depth: 12, bound: ttExact, age: 1}, -ttPackedForcedScore + 1},
+	}
+	for _, test := range tests {
+		want := test.entry
+		want.score = test.score
+		if got := unpackTTEntry(packTTEntry(test.entry)); got != want {
+			t.Errorf("unpackTTEntry(packTTEntry(%+v)) = %+v, want %+v", test.entry, got, want)
+		}
+	}
+}
+
+func TestSharedTTConcurrent(t *testing.T) {
+	board, err := engine.New(engine.WithDims(4, 4, 4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	const searches, positions = 8, 2000
+	for _, locking := range []TTLocking{TTLockFree, TTSharded} {
+		table, err := NewSharedTT(1, ReplaceAlways, locking, 16)
+		if err != nil {
+			t.Fatal(err)
+		}
+		table.Prepare(board)
+
+		// Every search stores positions of its own, scored by their hash, and probes the others'
+		var wait sync.WaitGroup
+		for search := range searches {
+			wait.Add(1)
+			go func() {
+				defer wait.Done()
+				for i := range positions {
+					hash := uint64(search*positions+i+1) * 0x9E3779B97F4A7C15
+					table.store(hash, 3, int(hash%1000), ttExact, int16(i%64))
+					table.probe(uint64(((search+1)%searches)*positions+i+1) * 0x9E3779B97F4A7C15)
+				}
+			}()
+		}
+		wait.Wait()
+
+		found := 0
+		for i := range searches * positions {
+			hash := uint64(i+1) * 0x9E3779B97F4A7C15
+			if entry, ok := table.probe(hash); ok {
+				found++
+				if entry.score != int(hash%1000) || entry.move != int16(i%positions%64) || entry.depth != 3 {
+					t.Fatalf("%s: position %d read back as %+v", locking, i, entry)
+				}
+			}
+		}
+		// Lock-free, two searches filling the same empty slot at once both count it as used
+		stats := table.Stats()
+		if found > stats.Used || locking == TTSharded && found != stats.Used || stats.Stores != searches*positions || stats.Probes != 2*searches*positions {
+			t.Errorf("%s: %d positions found, stats %+v", locking, found, stats)
+		}
+		if locking == TTSharded && (stats.Locks != stats.Stores+stats.Probes || stats.Contended > stats.Locks) {
+			t.Errorf("%s: %d locks, %d contended, for %d stores and %d probes", locking, stats.Locks, stats.Contended, stats.Stores, stats.Probes)
+		}
+		table.Release()
+	}
+}
+
+func TestNewSharedTTShards(t *testing.T) {
+	tests := []struct {
+		mib, shards int
+		valid       bool
+	}{
+		{1, 0, true},
+		{1, MAX_TT_SHARDS, true},
+		{1, MAX_TT_SHARDS + 1, false},
+		{1, 1<<20/TT_ENTRY_SIZE + 1, false},
+	}
+	for _, test := range tests {
+		if _, err := NewSharedTT(test.mib, ReplaceAlways, TTSharded, test.shards); (err == nil) != test.valid {
+			t.Errorf("NewSharedTT(%d MiB, %d shards): error %v, want valid %v", test.mib, test.shards, err, test.valid)
+		}
+	}
+}
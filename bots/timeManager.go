@@ -0,0 +1,102 @@
+package bots
+
+import (
+	"context"
+	"time"
+
+	"tic-tac-toe-3d-bots/engine"
+)
+
+// TIME_MOVES_TO_GO is the most moves of its own the time manager expects a game to still last; it expects fewer when
+// fewer cells are left
+const TIME_MOVES_TO_GO = 20
+
+// TIME_OPENING_FACTOR and TIME_MIDGAME_FACTOR scale a move's even share of the clock on the empty board and at
+// TIME_MIDGAME_PEAK, the share of the board filled where positions are the most complex; the factor climbs between the
+// two and then falls back to 1 by twice the peak, as the endgame's forced lines take little time to see through
+const (
+	TIME_OPENING_FACTOR = 0.5
+	TIME_MIDGAME_FACTOR = 1.6
+	TIME_MIDGAME_PEAK   = 0.3
+)
+
+// TIME_HARD_FACTOR is how many times its soft limit a move may run before it must stop
+const TIME_HARD_FACTOR = 3
+
+// TIME_OVERHEAD is kept back from every move's limits for sending the move and the clock's own lag
+const TIME_OVERHEAD = 20 * time.Millisecond
+
+// TIME_PANIC_MOVE is the clock per expected move below which the time manager panics: it then lives off the increment
+// and what is left shared over twice the moves, so the clock never runs out before the game does
+const TIME_PANIC_MOVE = 100 * time.Millisecond
+
+// TimeManager converts a game clock, the time left to a player plus Increment after each of their moves, into the
+// time each move may take. It gives the complex midgame more than the opening and the endgame, and panics when the
+// clock runs low; Budget's limits feed the searches' contexts
+type TimeManager struct {
+	Increment time.Duration
+	Overhead  time.Duration // kept back from every move, TIME_OVERHEAD by default
+}
+
+// NewTimeManager creates a time manager for a clock with the given increment
+func NewTimeManager(increment time.Duration) *TimeManager {
+	return &TimeManager{Increment: increment, Overhead: TIME_OVERHEAD}
+}
+
+// MoveBudget is the time one move may take: a search should not start an iteration after Soft, and must stop by Hard
+type MoveBudget struct {
+	Soft  time.Duration
+	Hard  time.Duration
+	Panic bool // the clock is low, and the limits only keep it from running out
+}
+
+// FixedBudget is the budget of a move with a fixed time limit, such as a movetime; 0 leaves the move unlimited
+func FixedBudget(limit time.Duration) MoveBudget {
+	return MoveBudget{Soft: limit, Hard: limit}
+}
+
+// Budget returns the limits of the next move on board of a player with remaining on their clock
+func (manager *TimeManager) Budget(board *engine.Board, remaining time.Duration) MoveBudget {
+	cells := board.Length * board.Width * board.Height
+	movesToGo := min(max((cells-board.MoveCount()+1)/2, 1), TIME_MOVES_TO_GO)
+	usable := max(remaining-manager.Overhead, time.Millisecond)
+	increment := manager.Increment * 3 / 4 // Some of it goes to the overhead and lag as well
+
+	if usable < time.Duration(movesToGo)*TIME_PANIC_MOVE {
+		soft := min(usable/time.Duration(2*movesToGo)+increment, usable/3)
+		return MoveBudget{Soft: max(soft, time.Millisecond), Hard: max(min(2*soft, usable/3), time.Millisecond), Panic: true}
+	}
+
+	// The factor climbs from the opening to the midgame peak, then eases back toward an even share
+	filled := float64(board.MoveCount()) / float64(cells)
+	factor := 1.0
+	switch {
+	case filled < TIME_MIDGAME_PEAK:
+		factor = TIME_OPENING_FACTOR + (TIME_MIDGAME_FACTOR-TIME_OPENING_FACTOR)*filled/TIME_MIDGAME_PEAK
+	case filled < 2*TIME_MIDGAME_PEAK:
+		factor = TIME_MIDGAME_FACTOR + (1-TIME_MIDGAME_FACTOR)*(filled-TIME_MIDGAME_PEAK)/TIME_MIDGAME_PEAK
+	}
+	soft := time.Duration(factor*float64(usable/time.Duration(movesToGo))) + increment
+	hard := min(TIME_HARD_FACTOR*soft, usable/2+increment, usable)
+	return MoveBudget{Soft: min(soft, hard), Hard: hard}
+}
+
+// softDeadlineKey marks a context with the soft limit of its move's budget
+type softDeadlineKey struct{}
+
+// Context returns a context for the move that ends at the hard limit and carries the soft one (see SoftDeadlinePassed)
+// A budget without limits only derives a cancellable context
+func (budget MoveBudget) Context(parent context.Context) (context.Context, context.CancelFunc) {
+	if budget.Hard <= 0 {
+		return context.WithCancel(parent)
+	}
+	ctx := context.WithValue(parent, softDeadlineKey{}, time.Now().Add(budget.Soft))
+	return context.WithTimeout(ctx, budget.Hard)
+}
+
+// SoftDeadlinePassed reports whether the soft limit of the budget ctx was made for has passed, so an iteratively
+// deepening search should not start another depth
+func SoftDeadlinePassed(ctx context.Context) bool {
+	deadline, ok := ctx.Value(softDeadlineKey{}).(time.Time)
+	return ok && time.Now().After(deadline)
+}
@@ -0,0 +1,110 @@
+package bots
+
+import (
+	"context"
+	"sync/atomic"
+
+	"tic-tac-toe-3d-bots/engine"
+)
+
+// Searches can be traced: each iteration of an alpha-beta search, iteratively deepening or multi-depth, and each root
+// move searched on a goroutine of its own, becomes a span, a child of whatever span the search's context carries. An
+// iteration searching with a transposition table starts with a span of the table's probe of the root position, and
+// ends with the table's use during the iteration. Nothing is traced until SetTracer is called
+
+// Tracer starts the spans of bot searches
+type Tracer interface {
+	// Start starts a span named name as a child of the span carried by ctx, if any,
+	// and returns a context carrying the new span; attributes are key, value pairs as in log/slog
+	Start(ctx context.Context, name string, attributes ...any) (context.Context, Span)
+}
+
+// Span is one timed operation of a trace
+type Span interface {
+	SetAttributes(attributes ...any) // key, value pairs as in Tracer.Start
+	End()
+}
+
+// tracer traces searches once set by SetTracer
+var tracer atomic.Pointer[Tracer]
+
+// SetTracer traces every search with t from now on; nil stops tracing
+func SetTracer(t Tracer) {
+	if t == nil {
+		tracer.Store(nil)
+		return
+	}
+	tracer.Store(&t)
+}
+
+// noSpan is the span of searches that are not traced
+type noSpan struct{}
+
+func (noSpan) SetAttributes(attributes ...any) {}
+func (noSpan) End()                            {}
+
+// startSpan starts a span if searches are traced
+func startSpan(ctx context.Context, name string, attributes ...any) (context.Context, Span) {
+	t := tracer.Load()
+	if t == nil {
+		return ctx, noSpan{}
+	}
+	return (*t).Start(ctx, name, attributes...)
+}
+
+// traceRootKey marks a context whose search is at the root, so its moves are traced
+type traceRootKey struct{}
+
+// traceRootMoves marks ctx as the context of a search's root, whose moves startRootMoveSpan traces;
+// the recursive searches only trace their moves at the root, where they are few
+func traceRootMoves(ctx context.Context) context.Context {
+	if tracer.Load() == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, traceRootKey{}, true)
+}
+
+// startRootMoveSpan starts the span of searching move if ctx is a search's root (see traceRootMoves)
+// The returned context no longer is, so the moves below move are not traced
+func startRootMoveSpan(ctx context.Context, move string) (context.Context, Span) {
+	if root, _ := ctx.Value(traceRootKey{}).(bool); !root {
+		return ctx, noSpan{}
+	}
+	ctx, span := startSpan(ctx, "root move", "move", move)
+	return context.WithValue(ctx, traceRootKey{}, false), span
+}
+
+// ttBoundNames names the bounds of transposition table entries in traces
+var ttBoundNames = map[uint8]string{ttExact: "exact", ttLower: "lower", ttUpper: "upper"}
+
+// startIterationSpan starts the span of an iteration of an alpha-beta search of board at depth, searching with tt if
+// it is not nil, and returns the function ending it with the iteration's score and best move
+func startIterationSpan(ctx context.Context, board *engine.Board, depth int, tt searchTable) (context.Context, func(score int, move string)) {
+	if tracer.Load() == nil {
+		return ctx, func(int, string) {}
+	}
+	ctx, span := startSpan(ctx, "depth iteration", "depth", depth)
+	if tt == nil {
+		return ctx, func(score int, move string) {
+			span.SetAttributes("score", score, "move", move)
+			span.End()
+		}
+	}
+
+	_, probe := startSpan(ctx, "tt probe", "depth", depth)
+	entry, found := tt.peek(board.ZobristHash())
+	probe.SetAttributes("found", found)
+	if found {
+		probe.SetAttributes("entry_depth", int(entry.depth), "bound", ttBoundNames[entry.bound], "score", entry.score,
+			"move", unpackMove(entry.move, board))
+	}
+	probe.End()
+
+	before := tt.Stats()
+	return ctx, func(score int, move string) {
+		after := tt.Stats()
+		span.SetAttributes("score", score, "move", move, "tt_probes", after.Probes-before.Probes, "tt_hits", after.Hits-before.Hits,
+			"tt_cutoffs", after.Cutoffs-before.Cutoffs, "tt_stores", after.Stores-before.Stores)
+		span.End()
+	}
+}
@@ -0,0 +1,350 @@
+package bots
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"unsafe"
+
+	"tic-tac-toe-3d-bots/engine"
+)
+
+// DEFAULT_TT_SIZE is the size in MiB of the transposition tables of bots not given one, until SetTTSize changes it
+const DEFAULT_TT_SIZE = 16
+
+// MAX_TT_SIZE is the largest transposition table, in MiB, a bot's "tt" parameter or SetTTSize can ask for; specs
+// also come from untrusted input, such as API requests
+const MAX_TT_SIZE = 65536
+
+// ReplacementPolicy decides which entry a transposition table gives up when a new position needs its slot
+type ReplacementPolicy int
+
+const (
+	ReplaceAlways         ReplacementPolicy = iota + 1 // the new position always takes the slot
+	ReplaceDepthPreferred                              // the slot keeps whichever position was searched deeper, unless it is stale
+	ReplaceTwoSlot                                     // buckets of two slots: one depth-preferred, whose position moves to the other, always replaced, when it gives way
+)
+
+// DEFAULT_TT_POLICY is the replacement policy of the transposition tables of bots not given one, until SetTTPolicy
+// changes it
+const DEFAULT_TT_POLICY = ReplaceAlways
+
+// replacementPolicyNames are the names of the policies, as --tt-policy takes them
+var replacementPolicyNames = map[ReplacementPolicy]string{
+	ReplaceAlways:         "always",
+	ReplaceDepthPreferred: "depth",
+	ReplaceTwoSlot:        "twoslot",
+}
+
+// ReplacementPolicies lists every replacement policy, in order of their "ttpolicy" parameter values
+func ReplacementPolicies() []ReplacementPolicy {
+	return []ReplacementPolicy{ReplaceAlways, ReplaceDepthPreferred, ReplaceTwoSlot}
+}
+
+// ttPolicyFromParams returns the replacement policy of a bot's "ttpolicy" parameter; 0 leaves the choice to TTPolicy
+func ttPolicyFromParams(params map[string]int) ReplacementPolicy {
+	return ReplacementPolicy(params["ttpolicy"])
+}
+
+// String returns the policy's name, e.g. "twoslot"
+func (policy ReplacementPolicy) String() string {
+	if name, ok := replacementPolicyNames[policy]; ok {
+		return name
+	}
+	return fmt.Sprintf("ReplacementPolicy(%d)", int(policy))
+}
+
+// ParseReplacementPolicy reads a policy name written by String
+func ParseReplacementPolicy(name string) (ReplacementPolicy, error) {
+	var names []string
+	for _, policy := range ReplacementPolicies() {
+		if strings.EqualFold(strings.TrimSpace(name), policy.String()) {
+			return policy, nil
+		}
+		names = append(names, policy.String())
+	}
+	return 0, fmt.Errorf("unknown replacement policy %q (expected %s)", name, strings.Join(names, ", "))
+}
+
+var (
+	ttSize   atomic.Int64 // size in MiB of the transposition tables of bots not given one, set with --hash
+	ttPolicy atomic.Int64 // replacement policy of the transposition tables of bots not given one, set with --tt-policy
+)
+
+func init() {
+	ttSize.Store(DEFAULT_TT_SIZE)
+	ttPolicy.Store(int64(DEFAULT_TT_POLICY))
+}
+
+// SetTTSize sets the size in MiB of the transposition tables of bots created from now on without a "tt" parameter,
+// from 1 to MAX_TT_SIZE
+func SetTTSize(mib int) {
+	ttSize.Store(int64(min(max(mib, 1), MAX_TT_SIZE)))
+}
+
+// TTSize returns the size in MiB of the transposition tables of bots created without a "tt" parameter
+func TTSize() int {
+	return int(ttSize.Load())
+}
+
+// SetTTPolicy sets the replacement policy of the transposition tables of bots created from now on without a
+// "ttpolicy" parameter
+func SetTTPolicy(policy ReplacementPolicy) {
+	ttPolicy.Store(int64(policy))
+}
+
+// TTPolicy returns the replacement policy of the transposition tables of bots created without a "ttpolicy" parameter
+func TTPolicy() ReplacementPolicy {
+	return ReplacementPolicy(ttPolicy.Load())
+}
+
+// Bounds of transposition table entries; the zero value marks an empty slot
+const (
+	ttExact uint8 = iota + 1 // the search completed: the score is the position's value at the entry's depth
+	ttLower                  // a maximizing search was cut off: the value is at least the score
+	ttUpper                  // a minimizing search was cut off: the value is at most the score
+)
+
+// ttEntry is a position's search result in a TranspositionTable
+type ttEntry struct {
+	key   uint64 // Zobrist hash of the position
+	score int
+	move  int16 // column of the best move, col*Width+row, or -1 if the search found none
+	depth uint8
+	bound uint8
+	age   uint8 // generation of the table the entry was stored in
+}
+
+// TT_ENTRY_SIZE is the memory a transposition table entry takes, in bytes
+const TT_ENTRY_SIZE = int(unsafe.Sizeof(ttEntry{}))
+
+// TTStats describes the use of a transposition table, to compare replacement policies by
+type TTStats struct {
+	Policy      string `json:"policy"`
+	Bytes       int    `json:"bytes"`       // memory the table takes
+	Entries     int    `json:"entries"`     // positions it can hold
+	Used        int    `json:"used"`        // positions it holds
+	Probes      int    `json:"probes"`      // lookups
+	Hits        int    `json:"hits"`        // lookups that found their position
+	Cutoffs     int    `json:"cutoffs"`     // hits that answered the search without searching the position
+	Stores      int    `json:"stores"`      // search results offered to the table
+	Overwritten int    `json:"overwritten"` // stores that evicted another position
+	Stale       int    `json:"stale"`       // of those, the positions evicted for being left from an earlier move
+	Rejected    int    `json:"rejected"`    // stores the policy turned down, keeping a deeper position
+
+	// Of shared tables only (see SharedTT)
+	Locking    string  `json:"locking,omitempty"`
+	Locks      int     `json:"locks,omitempty"`        // shard locks taken
+	Contended  int     `json:"contended,omitempty"`    // of those, the ones another search held at the time
+	LockWaitMS float64 `json:"lock_wait_ms,omitempty"` // time spent waiting for them
+}
+
+// HitRate returns the share of lookups that found their position, from 0 to 1
+func (stats TTStats) HitRate() float64 {
+	if stats.Probes == 0 {
+		return 0
+	}
+	return float64(stats.Hits) / float64(stats.Probes)
+}
+
+// FillRate returns the share of the table's slots in use, from 0 to 1
+func (stats TTStats) FillRate() float64 {
+	if stats.Entries == 0 {
+		return 0
+	}
+	return float64(stats.Used) / float64(stats.Entries)
+}
+
+// TranspositionTable remembers the results of a bot's searches by the Zobrist hash of their positions, so a position
+// reached again, by another move order or on a later move, is not searched afresh. Its size is fixed when it is
+// created, and its ReplacementPolicy decides which position keeps a slot two positions hash to. Entries are aged by
+// the move they were stored on: the positions of earlier moves' searches, however deep, make way for those of the
+// current one, so the table never needs clearing between moves. Its memory comes from the search memory budget (see
+// SetSearchMemory), and it is made smaller if the budget cannot grant all of it. It is not safe for concurrent use
+type TranspositionTable struct {
+	bytes      int  // wanted: the table takes less if the budget grants less
+	shard      bool // of a SharedTT, which reserves the memory of all its shards at once
+	policy     ReplacementPolicy
+	generation uint8     // bumped by every search, that is every move of the bot's
+	entries    []ttEntry // allocated on first use, so bots that never search cost nothing
+	evaluator  engine.Evaluator
+	size       [4]int // Length, Width, Height and WinLength of the boards the entries are of
+	stats      TTStats
+}
+
+// NewTranspositionTable creates an empty transposition table of mib MiB (TTSize if not positive) replacing entries
+// by policy (TTPolicy if 0)
+func NewTranspositionTable(mib int, policy ReplacementPolicy) *TranspositionTable {
+	if mib <= 0 {
+		mib = TTSize()
+	}
+	if policy == 0 {
+		policy = TTPolicy()
+	}
+	return newTranspositionTable(mib<<20, policy)
+}
+
+// newTranspositionTable creates an empty transposition table of at most bytes bytes, replacing entries by policy
+func newTranspositionTable(bytes int, policy ReplacementPolicy) *TranspositionTable {
+	entries := max(bytes/TT_ENTRY_SIZE, 2)
+	if policy == ReplaceTwoSlot {
+		entries &^= 1 // Whole buckets
+	}
+	return &TranspositionTable{bytes: entries * TT_ENTRY_SIZE, policy: policy}
+}
+
+// Prepare readies the table for a search of board, starting a new generation, and emptying the table if the board's
+// evaluator or dimensions differ from those of the positions it holds
+func (tt *TranspositionTable) Prepare(board *engine.Board) {
+	tt.generation++
+	size := [4]int{board.Length, board.Width, board.Height, board.WinLength}
+	if tt.entries == nil {
+		bytes := tt.bytes
+		if !tt.shard {
+			bytes = reserveTTMemory(bytes, tt.policy, TT_ENTRY_SIZE)
+		}
+		tt.entries = make([]ttEntry, bytes/TT_ENTRY_SIZE)
+	} else if board.Evaluator != tt.evaluator || size != tt.size {
+		clear(tt.entries)
+		tt.stats.Used = 0
+	}
+	tt.evaluator, tt.size = board.Evaluator, size
+}
+
+// Release empties the table and gives its memory back to the search memory budget; the next Prepare takes it again
+func (tt *TranspositionTable) Release() {
+	if tt.entries != nil && !tt.shard {
+		releaseMemory(MemoryTT, len(tt.entries)*TT_ENTRY_SIZE)
+	}
+	tt.entries = nil
+	tt.stats.Used = 0
+}
+
+// reserveTTMemory reserves the memory of a table of bytes bytes with entries of entrySize bytes, and returns the size
+// of the table the budget leaves room for: whole entries, whole buckets under policy, and at least one bucket
+func reserveTTMemory(bytes int, policy ReplacementPolicy, entrySize int) int {
+	granted := reserveMemory(MemoryTT, bytes, 2*entrySize)
+	size := max(granted/entrySize, 2)
+	if policy == ReplaceTwoSlot {
+		size &^= 1
+	}
+	releaseMemory(MemoryTT, granted-size*entrySize)
+	return size * entrySize
+}
+
+// probe returns the entry of the position with the given hash, if the table holds it
+func (tt *TranspositionTable) probe(hash uint64) (ttEntry, bool) {
+	tt.stats.Probes++
+	for _, entry := range tt.slots(hash) {
+		if entry.bound != 0 && entry.key == hash {
+			tt.stats.Hits++
+			return entry, true
+		}
+	}
+	return ttEntry{}, false
+}
+
+// peek is probe without counting the lookup, for tracing (see startIterationSpan)
+func (tt *TranspositionTable) peek(hash uint64) (ttEntry, bool) {
+	for _, entry := range tt.slots(hash) {
+		if entry.bound != 0 && entry.key == hash {
+			return entry, true
+		}
+	}
+	return ttEntry{}, false
+}
+
+// store records the result of searching the position with the given hash to depth, in the slot the policy picks
+// move is packed by packMove
+func (tt *TranspositionTable) store(hash uint64, depth, score int, bound uint8, move int16) {
+	entry := ttEntry{key: hash, score: score, move: move, depth: uint8(min(depth, 255)), bound: bound, age: tt.generation}
+	tt.stats.Stores++
+	slots := tt.slots(hash)
+	index := replacedIndex(tt.policy, tt.generation, slots, entry)
+	if index < 0 {
+		tt.stats.Rejected++
+		return
+	}
+	lost := slots[index]
+	demoted := demotes(tt.policy, slots, entry, index)
+	if demoted {
+		lost = slots[1]
+	}
+	switch {
+	case lost.bound == 0:
+		tt.stats.Used++
+	case lost.key != hash:
+		tt.stats.Overwritten++
+		if lost.age != tt.generation {
+			tt.stats.Stale++
+		}
+	}
+	if demoted {
+		slots[1] = slots[0]
+	}
+	slots[index] = entry
+}
+
+// slots returns the slots the position with the given hash may be kept in: one, or a bucket of two
+func (tt *TranspositionTable) slots(hash uint64) []ttEntry {
+	if tt.policy == ReplaceTwoSlot {
+		bucket := 2 * (hash % uint64(len(tt.entries)/2))
+		return tt.entries[bucket : bucket+2]
+	}
+	index := hash % uint64(len(tt.entries))
+	return tt.entries[index : index+1]
+}
+
+// replacedIndex returns which of the slots entry goes into under policy in the given generation, or -1 if the policy
+// keeps what is there. A position already in the slots is always updated in its own
+func replacedIndex(policy ReplacementPolicy, generation uint8, slots []ttEntry, entry ttEntry) int {
+	for i := range slots {
+		if slots[i].bound != 0 && slots[i].key == entry.key {
+			return i
+		}
+	}
+
+	deep := slots[0]
+	switch {
+	case policy == ReplaceAlways, deep.bound == 0, deep.age != generation, entry.depth >= deep.depth:
+		return 0
+	case policy == ReplaceTwoSlot:
+		return 1 // Shallower than the deep slot's position: the always-replaced slot takes it
+	}
+	return -1
+}
+
+// demotes reports whether storing entry in the slot of the given index moves the position there to the
+// always-replaced slot of a two-slot bucket, rather than losing it: a different position in the deep slot
+func demotes(policy ReplacementPolicy, slots []ttEntry, entry ttEntry, index int) bool {
+	return policy == ReplaceTwoSlot && index == 0 && slots[0].bound != 0 && slots[0].key != entry.key
+}
+
+// countCutoff records a search answered by an entry of the table
+func (tt *TranspositionTable) countCutoff() {
+	tt.stats.Cutoffs++
+}
+
+// packMove packs the move in the given column and row of boards of the given width into an entry, as col*width+row;
+// entries without a move hold -1
+func packMove(col, row, width int) int16 {
+	return int16(col*width + row)
+}
+
+// unpackMove returns the move of board in the column packed by packMove, "" for -1
+func unpackMove(index int16, board *engine.Board) string {
+	if index < 0 {
+		return ""
+	}
+	return board.MoveName(int(index)/board.Width, int(index)%board.Width)
+}
+
+// Stats returns the table's policy, size, fill and use so far
+func (tt *TranspositionTable) Stats() TTStats {
+	stats := tt.stats
+	stats.Policy, stats.Bytes, stats.Entries = tt.policy.String(), tt.bytes, tt.bytes/TT_ENTRY_SIZE
+	if tt.entries != nil {
+		stats.Bytes, stats.Entries = len(tt.entries)*TT_ENTRY_SIZE, len(tt.entries)
+	}
+	return stats
+}
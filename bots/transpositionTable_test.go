@@ -0,0 +1,264 @@
+package bots
+
+import (
+	"fmt"
+	"testing"
+
+	"tic-tac-toe-3d-bots/engine"
+)
+
+// testTable is a transposition table of either kind
+type testTable interface {
+	searchTable
+	Prepare(board *engine.Board)
+	Release()
+}
+
+// testTables returns an empty table of every kind and locking under policy, with their names
+func testTables(t *testing.T, policy ReplacementPolicy) map[string]testTable {
+	tables := map[string]testTable{"single": newTranspositionTable(64*TT_ENTRY_SIZE, policy)}
+	for _, locking := range []TTLocking{TTLockFree, TTSharded} {
+		shared, err := NewSharedTT(1, policy, locking, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tables[locking.String()] = shared
+	}
+	for _, table := range tables {
+		t.Cleanup(table.Release)
+	}
+	return tables
+}
+
+func TestTTStoreProbe(t *testing.T) {
+	board, err := engine.New(engine.WithDims(4, 4, 4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := []ttEntry{
+		{key: 1, score: 0, move: -1, depth: 1, bound: ttExact},
+		{key: 2, score: -350, move: 15, depth: 7, bound: ttLower},
+		{key: 3, score: 123456, move: 0, depth: 255, bound: ttUpper},
+		{key: 4, score: engine.MAX_INT / 2, move: 5, depth: 3, bound: ttExact},
+		{key: 5, score: engine.MIN_INT / 2, move: 9, depth: 2, bound: ttExact},
+	}
+	for _, policy := range ReplacementPolicies() {
+		for name, table := range testTables(t, policy) {
+			context := fmt.Sprintf("%s %s", name, policy)
+			table.Prepare(board)
+			for _, entry := range entries {
+				table.store(entry.key, int(entry.depth), entry.score, entry.bound, entry.move)
+			}
+			for _, want := range entries {
+				got, found := table.probe(want.key)
+				got.age = 0
+				if !found || got != want {
+					t.Errorf("%s: probe(%d) = %+v, %v, want %+v", context, want.key, got, found, want)
+				}
+			}
+			if got, found := table.probe(1000); found {
+				t.Errorf("%s: probe of a position never stored found %+v", context, got)
+			}
+
+			// A position stored again keeps its slot, even searched less deep
+			table.store(2, 1, 10, ttUpper, 3)
+			if got, _ := table.probe(2); got.depth != 1 || got.score != 10 || got.bound != ttUpper || got.move != 3 {
+				t.Errorf("%s: probe after storing again = %+v", context, got)
+			}
+
+			stats := table.Stats()
+			if stats.Probes != len(entries)+2 || stats.Hits != len(entries)+1 || stats.Stores != len(entries)+1 || stats.Used != len(entries) {
+				t.Errorf("%s: stats %+v", context, stats)
+			}
+
+			// A board of other dimensions empties the table
+			other, err := engine.New(engine.WithDims(3, 3, 3))
+			if err != nil {
+				t.Fatal(err)
+			}
+			table.Prepare(other)
+			if got, found := table.probe(1); found {
+				t.Errorf("%s: probe after preparing for another board found %+v", context, got)
+			}
+		}
+	}
+}
+
+func TestTTSize(t *testing.T) {
+	defer SetTTSize(TTSize())
+	tests := []struct {
+		mib, want int // size asked of SetTTSize, and the size it sets
+	}{
+		{4, 4},
+		{0, 1},
+		{-3, 1},
+		{MAX_TT_SIZE + 1, MAX_TT_SIZE},
+	}
+	for _, test := range tests {
+		SetTTSize(test.mib)
+		if got := TTSize(); got != test.want {
+			t.Errorf("SetTTSize(%d): TTSize() = %d, want %d", test.mib, got, test.want)
+		}
+	}
+
+	SetTTSize(2)
+	board, err := engine.New(engine.WithDims(4, 4, 4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, mib := range []int{0, 1, 3} {
+		table := NewTranspositionTable(mib, ReplaceAlways)
+		want := mib << 20 / TT_ENTRY_SIZE
+		if mib == 0 {
+			want = TTSize() << 20 / TT_ENTRY_SIZE
+		}
+		if stats := table.Stats(); stats.Entries != want || stats.Bytes != want*TT_ENTRY_SIZE {
+			t.Errorf("NewTranspositionTable(%d): %d entries in %d bytes, want %d", mib, stats.Entries, stats.Bytes, want)
+		}
+
+		table.Prepare(board)
+		for hash := uint64(1); hash <= 1000; hash++ {
+			table.store(hash, 1, 0, ttExact, -1)
+		}
+		if got := table.Stats().FillRate(); got != 1000/float64(want) {
+			t.Errorf("NewTranspositionTable(%d): fill rate %g after 1000 positions, want %g", mib, got, 1000/float64(want))
+		}
+		table.Release()
+	}
+}
+
+// collidingHash returns a hash other than hash competing with it for the same slot, or bucket of two slots, of a
+// prepared table
+func collidingHash(table testTable, hash uint64) uint64 {
+	var slots int
+	var policy ReplacementPolicy
+	switch table := table.(type) {
+	case *TranspositionTable:
+		slots, policy = len(table.entries), table.policy
+	case *SharedTT:
+		if table.locking == TTSharded {
+			slots = len(table.shards[0].table.entries) // Low bits only: the shard is picked by the high half
+		} else {
+			slots = len(table.words) / 2
+		}
+		policy = table.policy
+	}
+	if policy == ReplaceTwoSlot {
+		slots /= 2
+	}
+	return hash + uint64(slots)
+}
+
+func TestTTReplacement(t *testing.T) {
+	board, err := engine.New(engine.WithDims(4, 4, 4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A deep position is stored, then a shallow one competing for its slot during the same search
+	tests := []struct {
+		policy   ReplacementPolicy
+		found    [2]bool // whether the deep and the shallow position are found afterwards
+		rejected int
+	}{
+		{ReplaceAlways, [2]bool{false, true}, 0},
+		{ReplaceDepthPreferred, [2]bool{true, false}, 1},
+		{ReplaceTwoSlot, [2]bool{true, true}, 0},
+	}
+	for _, test := range tests {
+		for name, table := range testTables(t, test.policy) {
+			context := fmt.Sprintf("%s %s", name, test.policy)
+			table.Prepare(board)
+			deep := uint64(12345)
+			shallow := collidingHash(table, deep)
+
+			table.store(deep, 6, 100, ttExact, 1)
+			table.store(shallow, 2, 200, ttExact, 2)
+			for i, hash := range []uint64{deep, shallow} {
+				if _, found := table.probe(hash); found != test.found[i] {
+					t.Errorf("%s: position %d found %v, want %v", context, i, found, test.found[i])
+				}
+			}
+			if stats := table.Stats(); stats.Rejected != test.rejected {
+				t.Errorf("%s: %d stores rejected, want %d", context, stats.Rejected, test.rejected)
+			}
+		}
+	}
+}
+
+func TestTTAging(t *testing.T) {
+	board, err := engine.New(engine.WithDims(4, 4, 4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A deep position stored by one search gives way to a shallow one competing for its slot in the next search;
+	// two slots keep it in the always-replaced one
+	tests := []struct {
+		policy      ReplacementPolicy
+		found       [2]bool // whether the old deep and the new shallow position are found afterwards
+		overwritten int     // also the stale positions overwritten
+	}{
+		{ReplaceAlways, [2]bool{false, true}, 1},
+		{ReplaceDepthPreferred, [2]bool{false, true}, 1},
+		{ReplaceTwoSlot, [2]bool{true, true}, 0},
+	}
+	for _, test := range tests {
+		for name, table := range testTables(t, test.policy) {
+			context := fmt.Sprintf("%s %s", name, test.policy)
+			table.Prepare(board)
+			old := uint64(12345)
+			table.store(old, 8, 100, ttExact, 1)
+
+			table.Prepare(board)
+			shallow := collidingHash(table, old)
+			table.store(shallow, 1, 200, ttExact, 2)
+			for i, hash := range []uint64{old, shallow} {
+				if _, found := table.probe(hash); found != test.found[i] {
+					t.Errorf("%s: position %d found %v, want %v", context, i, found, test.found[i])
+				}
+			}
+			if stats := table.Stats(); stats.Overwritten != test.overwritten || stats.Stale != test.overwritten || stats.Rejected != 0 {
+				t.Errorf("%s: %d positions overwritten, %d stale, %d stores rejected, want %d, %d and 0", context,
+					stats.Overwritten, stats.Stale, stats.Rejected, test.overwritten, test.overwritten)
+			}
+		}
+	}
+}
+
+func TestTTTwoSlotDemotion(t *testing.T) {
+	board, err := engine.New(engine.WithDims(4, 4, 4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, table := range testTables(t, ReplaceTwoSlot) {
+		table.Prepare(board)
+		deep := uint64(12345)
+		deeper := collidingHash(table, deep)
+		shallow := collidingHash(table, deeper)
+
+		// The deeper position takes the deep slot, moving the deep one to the always-replaced slot
+		table.store(deep, 6, 100, ttExact, 1)
+		table.store(deeper, 8, 200, ttExact, 2)
+		for _, want := range []struct {
+			hash  uint64
+			score int
+		}{{deep, 100}, {deeper, 200}} {
+			if entry, found := table.probe(want.hash); !found || entry.score != want.score || entry.depth == 0 {
+				t.Errorf("%s: position %d after the deeper store: %+v, found %v, want score %d", name, want.hash, entry, found, want.score)
+			}
+		}
+		if stats := table.Stats(); stats.Used != 2 || stats.Overwritten != 0 {
+			t.Errorf("%s: %d slots used, %d positions overwritten after the deeper store, want 2 and 0", name, stats.Used, stats.Overwritten)
+		}
+
+		// A shallow position then replaces only the demoted one
+		table.store(shallow, 2, 300, ttExact, 3)
+		for i, want := range []bool{false, true, true} {
+			if _, found := table.probe([]uint64{deep, deeper, shallow}[i]); found != want {
+				t.Errorf("%s: position %d found %v after the shallow store, want %v", name, i, found, want)
+			}
+		}
+		if stats := table.Stats(); stats.Used != 2 || stats.Overwritten != 1 {
+			t.Errorf("%s: %d slots used, %d positions overwritten after the shallow store, want 2 and 1", name, stats.Used, stats.Overwritten)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"tic-tac-toe-3d-bots/bots"
+	"tic-tac-toe-3d-bots/engine"
+)
+
+// adaptiveLevels maps each strength level of the adaptive bot to a search depth and error rate
+// The error rate is the chance of playing a random move instead of the searched one
+var adaptiveLevels = []struct {
+	Depth     int
+	ErrorRate float64
+}{
+	{1, 0.50}, {1, 0.30}, {2, 0.30}, {2, 0.15}, {3, 0.15}, {3, 0.05},
+	{4, 0.05}, {5, 0.00}, {6, 0.00}, {7, 0.00}, {8, 0.00},
+}
+
+// ADAPTIVE_START_LEVEL is the level used against a player with no history
+const ADAPTIVE_START_LEVEL = 4
+
+// AdaptiveBot represents an AI player that adjusts its strength to the human it plays against
+// After every game the level moves one step up if the human won and one step down if the human lost.
+// This staircase settles around the level at which the human wins about half of the decisive games
+type AdaptiveBot struct {
+	bots.BaseBot
+	Player string // name of the human opponent whose record drives the level
+
+	store *StatsStore
+}
+
+// NewAdaptiveBot creates a new adaptive bot playing against the named human, using the given stats store
+func NewAdaptiveBot(symbol byte, name string, player string, store *StatsStore) *AdaptiveBot {
+	bot := &AdaptiveBot{
+		BaseBot: bots.NewBaseBot(symbol, name),
+		store:   store,
+	}
+	bot.SetPlayer(player)
+	return bot
+}
+
+// init registers AdaptiveBot with the bot registry
+func init() {
+	bots.RegisterBot(&bots.BotRegistration{
+		Key:         "adaptive",
+		DisplayName: "AdaptiveBot",
+		Description: "adjusts its strength to keep your win rate near 50%",
+		Order:       10,
+		Defaults:    map[string]int{},
+		New: func(symbol byte, name string, params map[string]int) bots.BotInterface {
+			return NewAdaptiveBot(symbol, name, DEFAULT_PLAYER_NAME, sharedStatsStore())
+		},
+	})
+}
+
+// SetPlayer switches the bot to the record of the named human
+func (bot *AdaptiveBot) SetPlayer(player string) {
+	if player == "" {
+		player = DEFAULT_PLAYER_NAME
+	}
+	bot.Player = player
+}
+
+// Level returns the bot's current strength level against its player
+func (bot *AdaptiveBot) Level() int {
+	record, exists := bot.store.Lookup(bot.Player)
+	if !exists {
+		return ADAPTIVE_START_LEVEL
+	}
+	return min(max(record.AdaptiveLevel, 0), len(adaptiveLevels)-1)
+}
+
+// MakeMove makes a move at the current strength level (implements BotInterface)
+func (bot *AdaptiveBot) MakeMove(ctx context.Context, board *engine.Board) (bots.Move, error) {
+	validMoves := board.GetValidMoves()
+	if len(validMoves) == 0 {
+		return bots.Move{}, bots.ErrNoValidMoves
+	}
+
+	level := adaptiveLevels[bot.Level()]
+
+	// Strength throttling: deliberately play a random move some of the time
+	if bot.Random().Float64() < level.ErrorRate {
+		return bots.PlayChosenMove(ctx, board, bot.Symbol(), validMoves[bot.Random().Intn(len(validMoves))])
+	}
+
+	isMaximizing := bot.Symbol() == 'x'
+	threshold := engine.MAX_INT
+	if !isMaximizing {
+		threshold = engine.MIN_INT
+	}
+	_, bestMoves := bots.AlphaBetaMinimax(board, level.Depth, isMaximizing, threshold, ctx)
+	return bots.PlayChosenMove(ctx, board, bot.Symbol(), bots.FirstMove(bestMoves))
+}
+
+// GameOver adjusts the level for the next game; the result itself goes to the human's profile with every PvE game
+// winner is 'x', 'o' or '|' for a draw
+func (bot *AdaptiveBot) GameOver(winner byte) {
+	humanSymbol := byte('x')
+	if bot.Symbol() == 'x' {
+		humanSymbol = 'o'
+	}
+	level := bot.Level()
+	record := bot.store.Player(bot.Player)
+
+	switch winner {
+	case humanSymbol:
+		level = min(level+1, len(adaptiveLevels)-1)
+	case bot.Symbol():
+		level = max(level-1, 0)
+	}
+	record.AdaptiveLevel = level
+
+	if err := bot.store.Save(); err != nil {
+		fmt.Println(msg("player_stats.save_error"), err)
+	}
+}
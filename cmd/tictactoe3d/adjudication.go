@@ -0,0 +1,76 @@
+package main
+
+import (
+	"tic-tac-toe-3d-bots/formats"
+)
+
+// DEFAULT_ADJUDICATE_MOVES is how many moves in a row both bots' evaluations must agree on the outcome before a game
+// is adjudicated, unless --adjudicate-moves says otherwise
+const DEFAULT_ADJUDICATE_MOVES = 4
+
+// adjudicator ends the EvE games whose outcome both bots agree on: won once both score the position at least
+// settings.AdjudicateWin in the same side's favour, or drawn once both score it within settings.AdjudicateDraw of
+// even from move settings.DrawAfter on, in either case for settings.AdjudicateMoves moves in a row
+// Each bot's evaluation is the one it gave the position it left with its last move
+type adjudicator struct {
+	win, draw, drawAfter, moves int
+	scores                      [2]int  // last evaluation ('x' perspective) of 'x' and of 'o'
+	scored                      [2]bool // whether each side has given one yet
+	leader                      byte    // side both evaluations favour decisively, or 0
+	decisive                    int     // moves in a row both have favoured leader
+	even                        int     // moves in a row both have found the position even
+}
+
+// newAdjudicator returns the adjudicator of a game run with settings; without thresholds it adjudicates nothing
+func newAdjudicator(settings formats.EvESettings) *adjudicator {
+	return &adjudicator{
+		win:       settings.AdjudicateWin,
+		draw:      settings.AdjudicateDraw,
+		drawAfter: settings.DrawAfter,
+		moves:     max(settings.AdjudicateMoves, 1),
+	}
+}
+
+// enabled reports whether the adjudicator can end a game at all
+func (judge *adjudicator) enabled() bool {
+	return judge.win > 0 || judge.drawAfter > 0
+}
+
+// adjudicate records score, the evaluation ('x' perspective) symbol gave the position it left with the ply-th move
+// of the game, and returns the result both bots now agree on: 'x' or 'o' for a win, '|' for a draw, or 0 if the game
+// goes on
+func (judge *adjudicator) adjudicate(symbol byte, score, ply int) byte {
+	side := formats.SymbolIndex(symbol)
+	judge.scores[side], judge.scored[side] = score, true
+	if !judge.scored[1-side] {
+		return 0
+	}
+	x, o := judge.scores[0], judge.scores[1]
+
+	leader := byte(0)
+	switch {
+	case judge.win > 0 && min(x, o) >= judge.win:
+		leader = 'x'
+	case judge.win > 0 && max(x, o) <= -judge.win:
+		leader = 'o'
+	}
+	if leader != judge.leader {
+		judge.leader, judge.decisive = leader, 0
+	}
+	if leader != 0 {
+		judge.decisive++
+		if judge.decisive >= judge.moves {
+			return leader
+		}
+	}
+
+	if judge.drawAfter == 0 || ply < judge.drawAfter || max(x, -x, o, -o) > judge.draw {
+		judge.even = 0
+		return 0
+	}
+	judge.even++
+	if judge.even >= judge.moves {
+		return '|'
+	}
+	return 0
+}
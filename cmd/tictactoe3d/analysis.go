@@ -0,0 +1,437 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"tic-tac-toe-3d-bots/bots"
+	"tic-tac-toe-3d-bots/engine"
+)
+
+// HINT_TIME_LIMIT bounds how long the hint search may run
+const HINT_TIME_LIMIT = 500 * time.Millisecond
+
+// HINT_MAX_DEPTH is the deepest iteration the hint search attempts
+const HINT_MAX_DEPTH = 8
+
+// BLUNDER_PROBABILITY_DROP is how much a move may lower the win chance before training mode warns about it
+const BLUNDER_PROBABILITY_DROP = 0.2
+
+// WIN_PROBABILITY_SCALE is the score difference that shifts the win probability by about 23%
+const WIN_PROBABILITY_SCALE = 400.0
+
+// EVAL_BAR_DEPTH is the fixed search depth behind the evaluation bar
+const EVAL_BAR_DEPTH = 4
+
+// EVAL_BAR_WIDTH is the number of cells in the evaluation bar
+const EVAL_BAR_WIDTH = 20
+
+// Hint is a suggested move together with a one-line justification
+type Hint struct {
+	Move   string
+	Reason string
+	Score  int // score from 'x' perspective of the deepest completed search
+	Depth  int // deepest completed search depth (0 for a tactical hint)
+}
+
+// analyzeBest runs an iteratively deepening alpha-beta search for symbol on a copy of the board
+// Only fully completed iterations are used, so the result is valid even if ctx expires mid-search
+// Returns the best line found, its score, and the depth it was searched to
+func analyzeBest(board *engine.Board, symbol byte, maxDepth int, ctx context.Context) ([]string, int, int) {
+	return analyzeBestWithProgress(board, symbol, maxDepth, nil, ctx)
+}
+
+// analyzeBestWithProgress is analyzeBest, additionally calling onIteration (if not nil) after every completed depth
+// With the search cache set, a cached analysis of the position stands for the iterations it covers, and the search
+// goes on from the next depth; an analysis deeper than any before is cached in turn
+func analyzeBestWithProgress(board *engine.Board, symbol byte, maxDepth int, onIteration func(line []string, score, depth int), ctx context.Context) ([]string, int, int) {
+	analysisBoard := engine.CopyBoard(board) // Never touch the live board or any bot's state
+	isMaximizing := symbol == 'x'
+	threshold := engine.MAX_INT
+	if !isMaximizing {
+		threshold = engine.MIN_INT
+	}
+
+	var bestLine []string
+	bestScore, bestDepth := 0, 0
+	cacheable := symbol == board.NextPlayer() // Cached positions are searched for the player to move
+	if cached, ok := searchCache.Lookup(analysisBoard); ok && cacheable {
+		bestLine, bestScore, bestDepth = cached.Line, cached.Score, cached.Depth
+		if onIteration != nil {
+			onIteration(bestLine, bestScore, bestDepth)
+		}
+		if cached.Covers(maxDepth) {
+			return bestLine, bestScore, bestDepth
+		}
+	}
+	defer func() {
+		if cacheable {
+			searchCache.Store(analysisBoard, bestLine, bestScore, bestDepth)
+		}
+	}()
+
+	for depth := bestDepth + 1; depth <= maxDepth; depth++ {
+		score, line := bots.AlphaBetaMinimax(analysisBoard, depth, isMaximizing, threshold, ctx)
+		if bots.SearchCancelled(ctx) || len(line) == 0 {
+			break // Interrupted or no moves left: keep the last complete iteration
+		}
+		bestLine, bestScore, bestDepth = line, score, depth
+		if onIteration != nil {
+			onIteration(line, score, depth)
+		}
+
+		if score >= engine.MAX_INT/2 || score <= engine.MIN_INT/2 {
+			break // Forced result found, searching deeper cannot change it
+		}
+		if bots.SoftDeadlinePassed(ctx) {
+			break // The move's time is mostly spent, the next depth would not finish
+		}
+	}
+	return bestLine, bestScore, bestDepth
+}
+
+// suggestHint suggests a move for symbol using a threat check followed by a short time-boxed search
+// Returns ok=false if there are no valid moves
+func suggestHint(board *engine.Board, symbol byte, timeLimit time.Duration) (Hint, bool) {
+	validMoves := board.GetValidMoves()
+	if len(validMoves) == 0 {
+		return Hint{}, false
+	}
+
+	opponent := byte('o')
+	if symbol == 'o' {
+		opponent = 'x'
+	}
+
+	// Threat check: immediate wins and blocks do not need a search
+	analysisBoard := engine.CopyBoard(board)
+	if move := bots.FindWinningMove(analysisBoard, validMoves, symbol); move != "" {
+		return Hint{Move: move, Reason: msg("hint.win_now")}, true
+	}
+	if move := bots.FindWinningMove(analysisBoard, validMoves, opponent); move != "" {
+		return Hint{Move: move, Reason: msg("hint.block", opponent)}, true
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeLimit)
+	defer cancel()
+	line, score, depth := analyzeBest(board, symbol, HINT_MAX_DEPTH, ctx)
+	if len(line) == 0 {
+		// Not even depth 1 finished in time, fall back to the first legal move
+		return Hint{Move: validMoves[0], Reason: msg("hint.no_time")}, true
+	}
+
+	return Hint{Move: line[0], Reason: describeScore(score, symbol, depth), Score: score, Depth: depth}, true
+}
+
+// describeScore explains a search score from symbol's point of view
+func describeScore(score int, symbol byte, depth int) string {
+	if symbol == 'o' {
+		score = -score
+	}
+	switch {
+	case score >= engine.MAX_INT/2:
+		return msg("score.forced_win", depth)
+	case score <= engine.MIN_INT/2:
+		return msg("score.forced_loss", depth)
+	case score > 0:
+		return msg("score.better", depth, score)
+	case score < 0:
+		return msg("score.worse", depth, score)
+	default:
+		return msg("score.balanced", depth)
+	}
+}
+
+// Candidate is one root move with its searched score and principal variation
+type Candidate struct {
+	Move  string
+	Score int      // score from 'x' perspective
+	Line  []string // principal variation starting with Move
+}
+
+// analyzeCandidates scores every root move for symbol with an iteratively deepening search (MultiPV)
+// Each root move is searched with a full window so its score is exact rather than a pruning bound
+// Returns the candidates best first from the deepest iteration that completed before ctx expired
+func analyzeCandidates(board *engine.Board, symbol byte, maxDepth int, ctx context.Context) ([]Candidate, int) {
+	return analyzeCandidatesWithProgress(board, symbol, maxDepth, nil, ctx)
+}
+
+// analyzeCandidatesWithProgress is analyzeCandidates, additionally calling onIteration (if not nil) with the candidates
+// of every completed depth, best first
+func analyzeCandidatesWithProgress(board *engine.Board, symbol byte, maxDepth int, onIteration func(candidates []Candidate, depth int), ctx context.Context) ([]Candidate, int) {
+	analysisBoard := engine.CopyBoard(board)
+	isMaximizing := symbol == 'x'
+	childThreshold := engine.MIN_INT // The reply is minimizing, so it starts with no pruning constraint
+	if !isMaximizing {
+		childThreshold = engine.MAX_INT
+	}
+
+	var best []Candidate
+	bestDepth := 0
+	for depth := 1; depth <= maxDepth; depth++ {
+		candidates := make([]Candidate, 0, len(analysisBoard.GetValidMoves()))
+		for _, move := range analysisBoard.GetValidMoves() {
+			if bots.SearchCancelled(ctx) {
+				break
+			}
+			analysisBoard.Move(move, symbol)
+			score, line := bots.AlphaBetaMinimax(analysisBoard, depth-1, !isMaximizing, childThreshold, ctx)
+			analysisBoard.UnMove(move)
+			candidates = append(candidates, Candidate{Move: move, Score: score, Line: append([]string{move}, line...)})
+		}
+		if bots.SearchCancelled(ctx) || len(candidates) == 0 {
+			break // Keep the last complete iteration
+		}
+
+		// Stable sort keeps move order for equal scores
+		sort.SliceStable(candidates, func(i, j int) bool {
+			if isMaximizing {
+				return candidates[i].Score > candidates[j].Score
+			}
+			return candidates[i].Score < candidates[j].Score
+		})
+		best, bestDepth = candidates, depth
+		if onIteration != nil {
+			onIteration(candidates, depth)
+		}
+
+		forced := true
+		for _, candidate := range candidates {
+			forced = forced && (candidate.Score >= engine.MAX_INT/2 || candidate.Score <= engine.MIN_INT/2)
+		}
+		if forced {
+			break // Every move's result is forced, searching deeper cannot change them
+		}
+		if bots.SoftDeadlinePassed(ctx) {
+			break // The move's time is mostly spent, the next depth would not finish
+		}
+	}
+	return best, bestDepth
+}
+
+// winProbability converts a score ('x' perspective) to symbol's estimated chance of winning, in [0, 1]
+func winProbability(score int, symbol byte) float64 {
+	if symbol == 'o' {
+		score = -score
+	}
+	switch {
+	case score >= engine.MAX_INT/2:
+		return 1
+	case score <= engine.MIN_INT/2:
+		return 0
+	}
+	return 1 / (1 + math.Exp(-float64(score)/WIN_PROBABILITY_SCALE))
+}
+
+// printCandidates prints up to count candidate moves for symbol with scores, win chances and short PVs, and with
+// Playouts set the share of random playouts after each that symbol won
+func printCandidates(board *engine.Board, symbol byte, count int, timeLimit time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeLimit)
+	defer cancel()
+
+	candidates, depth := analyzeCandidates(board, symbol, HINT_MAX_DEPTH, ctx)
+	if len(candidates) == 0 {
+		fmt.Println(msg("candidates.none"))
+		return
+	}
+	if count > len(candidates) {
+		count = len(candidates)
+	}
+
+	fmt.Print(msg("candidates.title", count, depth))
+	for i, candidate := range candidates[:count] {
+		line := candidate.Line
+		if len(line) > 5 {
+			line = line[:5] // Keep the PV short
+		}
+		chance := 100 * winProbability(candidate.Score, symbol)
+		if Playouts == 0 {
+			fmt.Print(msg("candidates.row", i+1, candidate.Move, formatScore(candidate.Score), chance, strings.Join(line, " ")))
+			continue
+		}
+		after := engine.CopyBoard(board)
+		after.Move(candidate.Move, symbol)
+		playouts := playOut(after, Playouts)
+		fmt.Print(msg("candidates.playouts", i+1, candidate.Move, formatScore(candidate.Score), chance,
+			100*playouts.WinRate(symbol), strings.Join(line, " ")))
+	}
+}
+
+// blunderWarning checks the move symbol has just played on board against the best alternative
+// Returns a warning if the move loses by force or drops the win chance noticeably, otherwise ""
+func blunderWarning(board *engine.Board, symbol byte, move string, timeLimit time.Duration) string {
+	// Analyse the position before the move on a copy, leaving the live board as it is
+	before := engine.CopyBoard(board)
+	before.UnMove(move)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeLimit)
+	defer cancel()
+	candidates, _ := analyzeCandidates(before, symbol, HINT_MAX_DEPTH, ctx)
+	return judgeMove(candidates, symbol, move) // No candidates means no time to check: the benefit of the doubt
+}
+
+// judgeMove warns if move, among the analysed candidates of symbol, loses by force or drops the win chance
+// noticeably compared to the best one; otherwise it returns ""
+func judgeMove(candidates []Candidate, symbol byte, move string) string {
+	if len(candidates) == 0 || candidates[0].Move == move {
+		return ""
+	}
+	best := candidates[0]
+	for _, candidate := range candidates {
+		if candidate.Move != move {
+			continue
+		}
+		bestChance := winProbability(best.Score, symbol)
+		playedChance := winProbability(candidate.Score, symbol)
+		switch {
+		case playedChance == 0 && bestChance > 0:
+			return msg("blunder.loses", move, best.Move)
+		case bestChance == 1 && playedChance < 1:
+			return msg("blunder.misses_win", move, best.Move)
+		case bestChance-playedChance >= BLUNDER_PROBABILITY_DROP:
+			return msg("blunder.drops", move, 100*playedChance, 100*bestChance, best.Move)
+		}
+	}
+	return ""
+}
+
+// evaluatePosition scores the position ('x' perspective) with a fixed-depth search for the side toMove
+func evaluatePosition(board *engine.Board, toMove byte, depth int) int {
+	if winner := board.CheckWin(); winner == 'x' {
+		return engine.MAX_INT / 2
+	} else if winner == 'o' {
+		return engine.MIN_INT / 2
+	}
+
+	isMaximizing := toMove == 'x'
+	threshold := engine.MAX_INT
+	if !isMaximizing {
+		threshold = engine.MIN_INT
+	}
+	score, _ := bots.AlphaBetaMinimax(engine.CopyBoard(board), depth, isMaximizing, threshold, context.Background())
+	return score
+}
+
+// printEvalBar prints a text bar showing how the position favours 'x' or 'o', e.g.
+//
+//	X ████████████-------- O  (+62%)
+//
+// where the percentage is x's estimated win chance, followed with Playouts set by the share of random playouts from
+// the position each side won. The evaluation is published to session's OnEvalUpdate observers
+func printEvalBar(session *GameSession, board *engine.Board, toMove byte) {
+	score := evaluatePosition(board, toMove, EVAL_BAR_DEPTH)
+	session.PublishEval(EVAL_BAR_DEPTH, score, nil)
+	chance := winProbability(score, 'x')
+	filled := int(math.Round(chance * EVAL_BAR_WIDTH))
+	fmt.Printf("X %s%s O  (%+.0f%%)", strings.Repeat("█", filled), strings.Repeat("-", EVAL_BAR_WIDTH-filled), 100*chance)
+	if Playouts > 0 {
+		playouts := playOut(board, Playouts)
+		fmt.Print(msg("playouts.bar", 100*playouts.WinRate('x'), 100*playouts.WinRate('o'), playouts.Games))
+	}
+	fmt.Println()
+}
+
+// LIVE_LINE_INTERVAL is how often the live principal variation is printed while a bot thinks
+const LIVE_LINE_INTERVAL = time.Second
+
+// makeMoveWithLiveLine asks bot for its move while printing its current best line every interval
+// The line is the deepest iteration the bot's own search has reported, so only bots that are ProgressReporters print
+// one; the others just move. Every iteration reported is published to session's OnEvalUpdate observers
+func makeMoveWithLiveLine(session *GameSession, bot bots.BotInterface, board *engine.Board, interval time.Duration, ctx context.Context) (bots.Move, error) {
+	reporter, ok := bot.(bots.ProgressReporter)
+	if !ok {
+		return searchMove(ctx, bot, board)
+	}
+	iterations := make(chan bots.SearchIteration, 1)
+	reporter.ReportProgress(func(iteration bots.SearchIteration) {
+		session.PublishEval(iteration.Depth, iteration.Score, iteration.Line)
+		select {
+		case <-iterations: // Only the deepest iteration is printed
+		default:
+		}
+		iterations <- iteration
+	})
+	defer reporter.ReportProgress(nil)
+
+	type result struct {
+		move bots.Move
+		err  error
+	}
+	moved := make(chan result, 1)
+	go func() {
+		move, err := searchMove(ctx, bot, board)
+		moved <- result{move, err}
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	start := time.Now()
+	var latest bots.SearchIteration
+	for {
+		select {
+		case result := <-moved:
+			return result.move, result.err
+		case latest = <-iterations:
+		case <-ticker.C:
+			if latest.Depth > 0 {
+				fmt.Print(msg("live.line", time.Since(start).Seconds(), latest.Depth, formatScore(latest.Score), strings.Join(latest.Line, " ")))
+			}
+		}
+	}
+}
+
+// Heat-map markers for playable cells, from the engine's quick assessment of playing there
+const (
+	HEAT_GOOD    = '+' // as good as the best move
+	HEAT_NEUTRAL = '=' // playable but somewhat worse
+	HEAT_BAD     = '-' // a blunder (see BLUNDER_PROBABILITY_DROP)
+)
+
+// HEAT_GOOD_MARGIN is how far below the best move's win chance a move can be and still count as good
+const HEAT_GOOD_MARGIN = 0.05
+
+// heatMap rates every playable cell for symbol using a time-boxed MultiPV search
+// Returns a board overlay (see printBoardWithOverlay), or nil if the search produced nothing
+func heatMap(board *engine.Board, symbol byte, timeLimit time.Duration) map[[3]int]byte {
+	ctx, cancel := context.WithTimeout(context.Background(), timeLimit)
+	defer cancel()
+
+	candidates, _ := analyzeCandidates(board, symbol, HINT_MAX_DEPTH, ctx)
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	bestChance := winProbability(candidates[0].Score, symbol)
+	overlay := make(map[[3]int]byte, len(candidates))
+	for _, candidate := range candidates {
+		x, y := engine.ParseMove(candidate.Move)
+		cell := [3]int{x, y, board.CurrentHeights[x][y]}
+
+		chance := winProbability(candidate.Score, symbol)
+		switch {
+		case bestChance-chance <= HEAT_GOOD_MARGIN:
+			overlay[cell] = HEAT_GOOD
+		case bestChance-chance >= BLUNDER_PROBABILITY_DROP:
+			overlay[cell] = HEAT_BAD
+		default:
+			overlay[cell] = HEAT_NEUTRAL
+		}
+	}
+	return overlay
+}
+
+// formatScore shows a search score ('x' perspective), naming forced results instead of printing huge numbers
+func formatScore(score int) string {
+	switch {
+	case score >= engine.MAX_INT/2:
+		return msg("score.x_wins")
+	case score <= engine.MIN_INT/2:
+		return msg("score.o_wins")
+	}
+	return fmt.Sprintf("%+d", score)
+}
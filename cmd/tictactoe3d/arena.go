@@ -0,0 +1,806 @@
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"tic-tac-toe-3d-bots/bots"
+	"tic-tac-toe-3d-bots/engine"
+	"tic-tac-toe-3d-bots/formats"
+)
+
+// DEFAULT_ARENA_ADDRESS is the TCP address the arena accepts engines on unless --listen says otherwise
+// Like the broadcast spectators watch, it is local unless given an address other machines can reach
+const DEFAULT_ARENA_ADDRESS = "localhost:7878"
+
+// ARENA_ACCOUNTS_FILE keeps the secret every engine name was claimed with
+const ARENA_ACCOUNTS_FILE = "arena_accounts.json"
+
+// ARENA_SECRET_ENV holds the secret arena-join logs in with unless --secret-file names a file holding it
+const ARENA_SECRET_ENV = "TTT_ARENA_SECRET"
+
+// ARENA_SECRET_ITERATIONS is how many PBKDF2 iterations the secrets of the accounts file are hashed with
+const ARENA_SECRET_ITERATIONS = 100_000
+
+// ARENA_BOT_TYPE marks the bot configuration of a remote engine in game records; it is rated by its name
+const ARENA_BOT_TYPE = "arena"
+
+// ARENA_PAIRING_INTERVAL is how often idle engines are paired into matches
+const ARENA_PAIRING_INTERVAL = time.Second
+
+// ARENA_READY_TIMEOUT bounds an engine's answer to isready before each game
+const ARENA_READY_TIMEOUT = 10 * time.Second
+
+// ARENA_MOVE_GRACE is allowed beyond the move time for network latency before an engine loses on time
+const ARENA_MOVE_GRACE = time.Second
+
+// ARENA_LINE_BUFFER is how many lines of an engine are kept while the arena is not waiting for an answer
+const ARENA_LINE_BUFFER = 256
+
+// ARENA_PAIRINGS lists the values of --pairing: auto pairs idle engines into matches, seek leaves them to seek and challenge
+var ARENA_PAIRINGS = []string{"auto", "seek"}
+
+// arenaNamePattern is what engine names may look like
+var arenaNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,32}$`)
+
+// errArenaDisconnected is returned when an engine's connection drops while the arena waits for its answer
+var errArenaDisconnected = errors.New("the engine disconnected")
+
+// Remote engines connect over TCP (one command per line) or WebSocket (one command per text message),
+// log in, and then speak the engine protocol (see Engine) with the arena, which owns the board and validates every move:
+//
+//	hello <name> <secret>                         engine → arena: log in; a name belongs to the first secret it was used with
+//	welcome <name> <rating>                       arena → engine: logged in
+//	error <text>                                  arena → engine: the login was refused
+//	arena match <opponent> <games> <LxWxH/win>    arena → engine: a match against opponent starts
+//	arena game <n> <x|o>                          arena → engine: game n of the match starts, the engine playing x or o
+//	ucinewgame, setoption, isready, position, go  arena → engine: as in the engine protocol, answered with readyok and bestmove
+//	arena error <text>                            arena → engine: the engine's last answer was refused, losing the game
+//	arena result <win|loss|draw> <reason>         arena → engine: the game is over
+//	arena record <base64>                         arena → engine: the game, as the GameRecord message of game.proto
+//	arena rating <rating>                         arena → engine: the engine's rating after the match
+//
+// Besides being paired by the arena, engines can arrange their own games by seeking or challenging (see arenaSeek.go).
+// Every game is rated; an engine that is too slow, answers with an illegal move or disconnects loses it
+
+// arenaConn is a line-based connection to a remote engine
+type arenaConn interface {
+	ReadLine() (string, error)
+	WriteLine(line string) error
+	SetReadDeadline(deadline time.Time) error
+	Close() error
+}
+
+// arenaTCPConn is an engine connected over TCP, its lines read up to NET_MAX_LINE bytes each
+type arenaTCPConn struct {
+	net.Conn
+	scanner *bufio.Scanner
+}
+
+func (conn arenaTCPConn) ReadLine() (string, error) {
+	if !conn.scanner.Scan() {
+		if err := conn.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	return strings.TrimSpace(conn.scanner.Text()), nil
+}
+
+func (conn arenaTCPConn) WriteLine(line string) error {
+	_, err := io.WriteString(conn.Conn, line+"\n")
+	return err
+}
+
+// arenaWebSocketConn is an engine connected over WebSocket
+type arenaWebSocketConn struct {
+	*WebSocket
+}
+
+func (conn arenaWebSocketConn) ReadLine() (string, error) {
+	message, err := conn.ReadMessage()
+	return strings.TrimSpace(string(message)), err
+}
+
+func (conn arenaWebSocketConn) WriteLine(line string) error {
+	return conn.writeFrame(wsText, []byte(line))
+}
+
+func (conn arenaWebSocketConn) SetReadDeadline(deadline time.Time) error {
+	return conn.conn.SetReadDeadline(deadline)
+}
+
+// arenaEngine is a logged-in engine, read line by line in the background
+type arenaEngine struct {
+	name  string
+	conn  arenaConn
+	lines chan string   // lines received; dropped when the buffer is full, as only answers the arena waits for matter
+	gone  chan struct{} // closed when the connection drops
+	busy  bool          // playing a match; guarded by the arena's mutex
+	last  string        // name of the last opponent; guarded by the arena's mutex
+}
+
+// newArenaEngine starts reading the lines of a logged-in engine; seek and challenge commands go to offers as they arrive
+func newArenaEngine(name string, conn arenaConn, offers func(engine *arenaEngine, fields []string)) *arenaEngine {
+	engine := &arenaEngine{name: name, conn: conn, lines: make(chan string, ARENA_LINE_BUFFER), gone: make(chan struct{})}
+	go func() {
+		defer close(engine.gone)
+		for {
+			line, err := conn.ReadLine()
+			if err != nil {
+				conn.Close() // A line too long drops the connection
+				return
+			}
+			if fields := strings.Fields(line); len(fields) > 0 && arenaOfferCommands[fields[0]] {
+				offers(engine, fields)
+				continue
+			}
+			select {
+			case engine.lines <- line:
+			default:
+			}
+		}
+	}()
+	return engine
+}
+
+// send writes one protocol line; a failed write drops the connection
+func (engine *arenaEngine) send(format string, args ...any) {
+	if err := engine.conn.WriteLine(fmt.Sprintf(format, args...)); err != nil {
+		engine.conn.Close()
+	}
+}
+
+// connected reports whether the engine's connection is still up
+func (engine *arenaEngine) connected() bool {
+	select {
+	case <-engine.gone:
+		return false
+	default:
+		return true
+	}
+}
+
+// expect reads the engine's lines until one starting with command, which it returns
+// It fails with context.DeadlineExceeded if none comes within timeout, and errArenaDisconnected if the connection drops
+func (engine *arenaEngine) expect(command string, timeout time.Duration) ([]string, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		select {
+		case line := <-engine.lines:
+			if fields := strings.Fields(line); len(fields) > 0 && fields[0] == command {
+				return fields, nil
+			}
+		case <-engine.gone:
+			return nil, errArenaDisconnected
+		case <-timer.C:
+			return nil, context.DeadlineExceeded
+		}
+	}
+}
+
+// arenaLossReason is the result reason of a game lost because an engine failed to answer with err
+func arenaLossReason(err error) string {
+	if errors.Is(err, errArenaDisconnected) {
+		return "abandoned"
+	}
+	return "time"
+}
+
+// arenaBotConfig is the bot configuration recorded for a remote engine
+func arenaBotConfig(name string) *bots.BotConfig {
+	return &bots.BotConfig{Type: ARENA_BOT_TYPE, Name: name}
+}
+
+// arenaMatch is how the games of a match are played
+type arenaMatch struct {
+	board    engine.BoardConfig
+	games    int               // the engines take turns to play 'x'
+	moveTime time.Duration     // thinking time of every move, when the games have no clock
+	clock    *arenaTimeControl // clock of each engine in every game; nil gives every move moveTime
+}
+
+// Arena pairs the engines logged in to it into rated matches
+type Arena struct {
+	match   arenaMatch // how the matches the arena pairs are played
+	pairing string     // one of ARENA_PAIRINGS
+	output  io.Writer  // logins and results are reported here
+
+	mutex        sync.Mutex
+	engines      map[string]*arenaEngine // logged-in engines by name
+	accounts     map[string]string       // engine name → hash of its secret (see hashArenaSecret)
+	accountsFile string
+	offers       map[int]*arenaOffer // open seeks and challenges by id
+	lastOffer    int                 // id of the last offer made
+}
+
+// newArena creates an arena, loading the accounts of accountsFile if it exists
+func newArena(match arenaMatch, pairing, accountsFile string, output io.Writer) (*Arena, error) {
+	arena := &Arena{match: match, pairing: pairing, output: output, engines: make(map[string]*arenaEngine),
+		accounts: make(map[string]string), accountsFile: accountsFile, offers: make(map[int]*arenaOffer)}
+	data, err := os.ReadFile(accountsFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return arena, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &arena.accounts); err != nil {
+		return nil, fmt.Errorf("%s: %v", accountsFile, err)
+	}
+	return arena, nil
+}
+
+// login reads an engine's hello and, if its name and secret are accepted, adds it to the engines waiting for a match
+func (arena *Arena) login(conn arenaConn) {
+	conn.SetReadDeadline(time.Now().Add(NET_HANDSHAKE_TIMEOUT))
+	line, err := conn.ReadLine()
+	conn.SetReadDeadline(time.Time{})
+	if err != nil {
+		arenaLog.Debug("engine dropped before its hello", "error", err)
+		conn.Close()
+		return
+	}
+	fields := strings.Fields(line)
+	if len(fields) != 3 || fields[0] != "hello" {
+		arenaLog.Warn("engine sent an invalid hello", "line", line)
+		conn.WriteLine("error expected hello <name> <secret>")
+		conn.Close()
+		return
+	}
+
+	engine, err := arena.register(fields[1], fields[2], conn)
+	if err != nil {
+		arenaLog.Warn("engine login rejected", "name", fields[1], "error", err)
+		conn.WriteLine("error " + err.Error())
+		conn.Close()
+		return
+	}
+	rating := arena.rating(engine.name)
+	engine.send("welcome %s %.0f", engine.name, rating.Rating)
+	arenaLog.Info("engine logged in", "name", engine.name, "rating", rating.Rating)
+	fmt.Fprint(arena.output, msg("arena.connected", engine.name, rating.String()))
+}
+
+// register checks an engine's name and secret, claiming the name for the secret the first time it is used
+func (arena *Arena) register(name, secret string, conn arenaConn) (*arenaEngine, error) {
+	if !arenaNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid name %q (1 to 32 letters, digits, '_', '.' or '-')", name)
+	}
+	// Hash outside the mutex, as PBKDF2 takes a while on purpose
+	arena.mutex.Lock()
+	claimed, exists := arena.accounts[name]
+	arena.mutex.Unlock()
+	var hashed string
+	if exists {
+		matches, legacy := checkArenaSecret(claimed, secret)
+		if !matches {
+			return nil, fmt.Errorf("wrong secret for %q", name)
+		}
+		if legacy {
+			hashed = hashArenaSecret(secret) // Salt the secret of an account kept from before
+		}
+	} else {
+		hashed = hashArenaSecret(secret)
+	}
+
+	arena.mutex.Lock()
+	defer arena.mutex.Unlock()
+	if arena.accounts[name] != claimed {
+		return nil, fmt.Errorf("%q was claimed meanwhile", name)
+	}
+	if hashed != "" {
+		arena.accounts[name] = hashed
+		if err := arena.saveAccounts(); err != nil {
+			if exists {
+				arena.accounts[name] = claimed
+			} else {
+				delete(arena.accounts, name)
+			}
+			return nil, fmt.Errorf("cannot save the account: %v", err)
+		}
+	}
+	if engine, exists := arena.engines[name]; exists && engine.connected() {
+		return nil, fmt.Errorf("%q is already connected", name)
+	}
+
+	engine := newArenaEngine(name, conn, arena.offer)
+	arena.engines[name] = engine
+	return engine, nil
+}
+
+// hashArenaSecret hashes secret for the accounts file as "pbkdf2-sha256$<iterations>$<salt>$<key>", salt and key in hex
+func hashArenaSecret(secret string) string {
+	salt := make([]byte, 16)
+	rand.Read(salt)
+	key, _ := pbkdf2.Key(sha256.New, secret, salt, ARENA_SECRET_ITERATIONS, sha256.Size) // Only fails in FIPS mode on short salts
+	return fmt.Sprintf("pbkdf2-sha256$%d$%x$%x", ARENA_SECRET_ITERATIONS, salt, key)
+}
+
+// checkArenaSecret reports whether secret is the one hashed in claimed, and whether claimed is the unsalted hex SHA-256
+// accounts were kept as before, to be hashed again
+func checkArenaSecret(claimed, secret string) (matches, legacy bool) {
+	parts := strings.Split(claimed, "$")
+	if len(parts) != 4 || parts[0] != "pbkdf2-sha256" {
+		hash := sha256.Sum256([]byte(secret))
+		return subtle.ConstantTimeCompare([]byte(claimed), []byte(hex.EncodeToString(hash[:]))) == 1, true
+	}
+	var iterations int
+	if _, err := fmt.Sscan(parts[1], &iterations); err != nil || iterations < 1 {
+		return false, false
+	}
+	salt, saltErr := hex.DecodeString(parts[2])
+	want, keyErr := hex.DecodeString(parts[3])
+	if saltErr != nil || keyErr != nil || len(want) == 0 {
+		return false, false
+	}
+	key, err := pbkdf2.Key(sha256.New, secret, salt, iterations, len(want))
+	return err == nil && subtle.ConstantTimeCompare(key, want) == 1, false
+}
+
+// saveAccounts writes the accounts file; the caller holds the mutex
+func (arena *Arena) saveAccounts() error {
+	data, err := json.MarshalIndent(arena.accounts, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(arena.accountsFile, data, 0600)
+}
+
+// rating returns the current rating of an engine, or the starting rating if it has not played yet
+func (arena *Arena) rating(name string) *PlayerRating {
+	key, kind, display := ratingKey(arenaBotConfig(name), name)
+	if store := sharedRatingsStore(); store != nil {
+		if rating, exists := store.Rating(key); exists {
+			return rating
+		}
+	}
+	return newPlayerRating(display, kind)
+}
+
+// schedule pairs idle engines into matches every ARENA_PAIRING_INTERVAL, forever
+func (arena *Arena) schedule() {
+	for range time.Tick(ARENA_PAIRING_INTERVAL) {
+		for _, engines := range arena.pair() {
+			go arena.playMatch(engines, arena.match)
+		}
+	}
+}
+
+// pair marks the idle engines busy two by two, pairing engines of close ratings, and forgets disconnected engines
+// An engine is only paired with its last opponent again when nobody else is waiting; engines with open offers wait
+// for them to be taken, and with --pairing seek nobody is paired
+func (arena *Arena) pair() [][2]*arenaEngine {
+	arena.mutex.Lock()
+	defer arena.mutex.Unlock()
+
+	offering := make(map[*arenaEngine]bool)
+	for id, offer := range arena.offers {
+		if !offer.from.connected() || (offer.to != nil && !offer.to.connected()) {
+			arena.withdraw(id)
+			continue
+		}
+		offering[offer.from] = true
+		if offer.to != nil {
+			offering[offer.to] = true
+		}
+	}
+
+	var idle []*arenaEngine
+	ratings := make(map[*arenaEngine]float64)
+	for name, engine := range arena.engines {
+		if !engine.connected() {
+			delete(arena.engines, name)
+			fmt.Fprint(arena.output, msg("arena.disconnected", name))
+		} else if !engine.busy && !offering[engine] && arena.pairing == "auto" {
+			idle = append(idle, engine)
+			ratings[engine] = arena.rating(name).Rating
+		}
+	}
+	sort.Slice(idle, func(i, j int) bool {
+		if ratings[idle[i]] != ratings[idle[j]] {
+			return ratings[idle[i]] < ratings[idle[j]]
+		}
+		return idle[i].name < idle[j].name
+	})
+
+	var pairs [][2]*arenaEngine
+	for len(idle) >= 2 {
+		opponent := 1
+		for i := 1; i < len(idle); i++ {
+			if idle[i].name != idle[0].last {
+				opponent = i
+				break
+			}
+		}
+		engines := [2]*arenaEngine{idle[0], idle[opponent]}
+		idle = append(idle[1:opponent], idle[opponent+1:]...)
+		for i, engine := range engines {
+			engine.busy, engine.last = true, engines[1-i].name
+		}
+		pairs = append(pairs, engines)
+	}
+	return pairs
+}
+
+// playMatch plays the games of a match between two engines, which become idle again afterwards
+// The first engine plays 'x' in the first game; the match ends early if either engine disconnects
+func (arena *Arena) playMatch(engines [2]*arenaEngine, match arenaMatch) {
+	fmt.Fprint(arena.output, msg("arena.match", engines[0].name, engines[1].name, match.games))
+	for i, engine := range engines {
+		engine.send("arena match %s %d %s", engines[1-i].name, match.games, arenaBoardString(match.board))
+	}
+	for game := 1; game <= match.games && engines[0].connected() && engines[1].connected(); game++ {
+		players := engines
+		if game%2 == 0 {
+			players = [2]*arenaEngine{engines[1], engines[0]}
+		}
+		arena.playGame(players, game, match)
+	}
+
+	arena.mutex.Lock()
+	defer arena.mutex.Unlock()
+	for _, engine := range engines {
+		engine.busy = false
+		if engine.connected() {
+			engine.send("arena rating %.0f", arena.rating(engine.name).Rating)
+		}
+	}
+}
+
+// playGame plays game number of a match between the engines playing 'x' and 'o', and reports the result to both
+// The game is rated and stored like any other
+func (arena *Arena) playGame(players [2]*arenaEngine, number int, match arenaMatch) {
+	board, _ := engine.New(engine.WithConfig(match.board)) // validated by runArena, or by the offer
+	session := startGame(board, formats.GameRecord{
+		Mode:    "arena",
+		Players: [2]string{players[0].name, players[1].name},
+		Bots:    [2]*bots.BotConfig{arenaBotConfig(players[0].name), arenaBotConfig(players[1].name)},
+	})
+	arena.runGame(session, board, players, number, match)
+	session.End()
+
+	result := session.Result()
+	record := session.Record()
+	encoded := base64.StdEncoding.EncodeToString(record.MarshalProto())
+	for i, engine := range players {
+		outcome := "draw"
+		switch result.Winner {
+		case string("xo"[i]):
+			outcome = "win"
+		case string("xo"[1-i]):
+			outcome = "loss"
+		}
+		engine.send("arena result %s %s", outcome, result.Reason)
+		engine.send("arena record %s", encoded)
+	}
+	fmt.Fprint(arena.output, msg("arena.game_over", players[0].name, players[1].name, result.Winner, result.Reason))
+}
+
+// runGame asks the engines for their moves until the game is over, setting the session's result when an engine fails
+// With a clock, each move is given a share of the engine's remaining time, and an engine whose clock runs out loses on time
+func (arena *Arena) runGame(session *GameSession, board *engine.Board, players [2]*arenaEngine, number int, match arenaMatch) {
+	var clocks [2]time.Duration
+	if match.clock != nil {
+		clocks = [2]time.Duration{match.clock.Base, match.clock.Base}
+	}
+	for i, engine := range players {
+		engine.send("arena game %d %c", number, "xo"[i])
+		engine.send("ucinewgame")
+		engine.send("setoption name Board value %s", arenaBoardString(match.board))
+		engine.send("isready")
+	}
+	// Waiting for readyok also discards anything left over from the engine's last game
+	for i, engine := range players {
+		if _, err := engine.expect("readyok", ARENA_READY_TIMEOUT); err != nil {
+			arenaLog.Info("engine not ready for its game", "name", engine.name, "error", err)
+			session.SetResult("xo"[1-i], arenaLossReason(err))
+			return
+		}
+	}
+
+	var moves []string
+	maxMoves := board.Length * board.Width * board.Height
+	for current := 0; board.CheckWin() == '|' && board.MoveCount() < maxMoves; current = 1 - current {
+		engine := players[current]
+		position := "position startpos"
+		if len(moves) > 0 {
+			position += " moves " + strings.Join(moves, " ")
+		}
+		engine.send("%s", position)
+		moveTime, timeout := match.moveTime, match.moveTime
+		if match.clock != nil {
+			moveTime, timeout = match.clock.budget(board, clocks[current]), clocks[current]
+		}
+		engine.send("go movetime %d", max(moveTime.Milliseconds(), 1))
+
+		start := time.Now()
+		fields, err := engine.expect("bestmove", timeout+ARENA_MOVE_GRACE)
+		thinking := time.Since(start)
+		if err != nil {
+			engine.send("stop")
+			arenaLog.Info("engine lost on its move", "name", engine.name, "reason", arenaLossReason(err), "error", err)
+			session.SetResult("xo"[1-current], arenaLossReason(err))
+			return
+		}
+		move := ""
+		if len(fields) > 1 {
+			move = strings.ToUpper(fields[1])
+		}
+		if move == "" || board.Move(move, "xo"[current])[0] == -1 {
+			engine.send("arena error illegal move %q", move)
+			arenaLog.Info("engine forfeited with an illegal move", "name", engine.name, "move", move)
+			session.SetResult("xo"[1-current], "forfeit")
+			return
+		}
+		moves = append(moves, move)
+		session.RecordMove(move, thinking)
+		if match.clock != nil {
+			clocks[current] = max(clocks[current]-thinking, 0) + match.clock.Increment
+		}
+	}
+}
+
+// acceptArenaEngines logs in every engine connecting to listener, until the listener is closed
+func acceptArenaEngines(listener net.Listener, arena *Arena) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			arenaLog.Debug("stopped accepting engines", "error", err)
+			return
+		}
+		arenaLog.Debug("engine connected", "remote", conn.RemoteAddr())
+		go arena.login(arenaTCPConn{Conn: conn, scanner: netLines(conn)})
+	}
+}
+
+// runArena implements the arena command: it accepts remote engines and plays them against each other
+func runArena(args []string, output io.Writer) error {
+	var listen, webSocket, boardValue, pairing, accounts, db, lang string
+	var games, moveTime int
+	fs := flag.NewFlagSet("arena", flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.StringVar(&listen, "listen", DEFAULT_ARENA_ADDRESS, "TCP address to accept engines on")
+	fs.StringVar(&webSocket, "ws", "", "address to also accept engines on over WebSocket, at /arena (default off)")
+	addOriginFlag(fs)
+	fs.StringVar(&boardValue, "board", "3x3x3/3", "board of the games, as LxWxH/win")
+	fs.IntVar(&games, "games", 2, "games per match")
+	fs.IntVar(&moveTime, "movetime", 1000, "thinking time per move in milliseconds")
+	fs.StringVar(&pairing, "pairing", "auto", "how engines meet: "+strings.Join(ARENA_PAIRINGS, ", ")+" (engines can always seek and challenge)")
+	fs.StringVar(&accounts, "accounts", ARENA_ACCOUNTS_FILE, "file keeping the secret of every engine name")
+	fs.StringVar(&db, "db", "", "game database to store every game in")
+	fs.StringVar(&RatingsFile, "ratings", DEFAULT_STATS_FILE, "stats store the engines are rated in")
+	fs.StringVar(&lang, "lang", "", "language for messages: "+strings.Join(availableLocales(), ", ")+" (default from TTT_LANG or LANG)")
+	logging := addLogFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+	if games < 1 {
+		return fmt.Errorf("invalid --games %d (must be at least 1)", games)
+	}
+	if moveTime < 1 {
+		return fmt.Errorf("invalid --movetime %d (must be positive)", moveTime)
+	}
+	if !slices.Contains(ARENA_PAIRINGS, pairing) {
+		return fmt.Errorf("invalid --pairing %q (expected %s)", pairing, strings.Join(ARENA_PAIRINGS, " or "))
+	}
+	board, err := parseArenaBoard(boardValue)
+	if err != nil {
+		return err
+	}
+
+	if lang == "" {
+		lang = localeFromEnvironment()
+	}
+	if err := setLocale(lang); err != nil {
+		return err
+	}
+	closeLog, err := logging.apply()
+	if err != nil {
+		return err
+	}
+	defer closeLog()
+	if db != "" {
+		if gameDB, err = openGameDB(db); err != nil {
+			return err
+		}
+		defer gameDB.Close()
+	}
+
+	arena, err := newArena(arenaMatch{board: board, games: games, moveTime: time.Duration(moveTime) * time.Millisecond},
+		pairing, accounts, output)
+	if err != nil {
+		return err
+	}
+	listener, err := net.Listen("tcp", listen)
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+	fmt.Fprint(output, msg("arena.listening", listener.Addr()))
+	go acceptArenaEngines(listener, arena)
+
+	if webSocket != "" {
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /arena", func(writer http.ResponseWriter, request *http.Request) {
+			socket, err := upgradeWebSocket(writer, request)
+			if err != nil {
+				arenaLog.Warn("WebSocket handshake failed", "remote", request.RemoteAddr, "error", err)
+				return
+			}
+			arenaLog.Debug("engine connected over WebSocket", "remote", request.RemoteAddr)
+			arena.login(arenaWebSocketConn{socket})
+		})
+		socketListener, err := net.Listen("tcp", webSocket)
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(output, msg("arena.websocket", socketListener.Addr()))
+		go http.Serve(socketListener, mux)
+	}
+
+	arena.schedule()
+	return nil
+}
+
+// readArenaSecret returns the secret arena-join logs in with: the first line of file, or ARENA_SECRET_ENV if file is
+// empty. Unlike a flag, neither shows in the process list
+func readArenaSecret(file string) (string, error) {
+	secret := os.Getenv(ARENA_SECRET_ENV)
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return "", err
+		}
+		secret, _, _ = strings.Cut(string(data), "\n")
+	}
+	secret = strings.TrimSpace(secret)
+	switch {
+	case secret == "":
+		return "", fmt.Errorf("a secret is required, from --secret-file or %s", ARENA_SECRET_ENV)
+	case strings.ContainsAny(secret, " \t"):
+		return "", errors.New("the secret must not contain spaces")
+	}
+	return secret, nil
+}
+
+// runArenaJoin implements the arena-join command: it logs in to an arena and plays its matches with a bot
+// With --offer it seeks games on those terms, or challenges one engine with --challenge, again after every match
+func runArenaJoin(args []string, output io.Writer) error {
+	var server, name, secretFile, spec, offer, challenge, lang string
+	var acceptChallenges bool
+	fs := flag.NewFlagSet("arena-join", flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.StringVar(&server, "server", DEFAULT_ARENA_ADDRESS, "address of the arena")
+	fs.StringVar(&name, "name", "", "name to play under (required)")
+	fs.StringVar(&secretFile, "secret-file", "", "file holding the secret claiming the name (default the "+ARENA_SECRET_ENV+" environment variable)")
+	fs.StringVar(&spec, "bot", "alphabeta", "bot to play with, e.g. alphabeta:depth=6")
+	fs.StringVar(&offer, "offer", "", "seek games on these terms, as \"LxWxH/win base+increment [x|o]\" with the clock in seconds, e.g. \"4x4x4/4 60+2\"")
+	fs.StringVar(&challenge, "challenge", "", "challenge this engine on the terms of --offer instead of seeking")
+	fs.BoolVar(&acceptChallenges, "accept-challenges", false, "accept every challenge")
+	fs.StringVar(&lang, "lang", "", "language for messages: "+strings.Join(availableLocales(), ", ")+" (default from TTT_LANG or LANG)")
+	logging := addLogFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+	if name == "" {
+		return errors.New("--name is required")
+	}
+	secret, err := readArenaSecret(secretFile)
+	if err != nil {
+		return err
+	}
+	terms := strings.Fields(offer)
+	if len(terms) > 0 {
+		if len(terms) < 2 || len(terms) > 3 || len(terms) == 3 && terms[2] != "x" && terms[2] != "o" {
+			return fmt.Errorf("invalid --offer %q (expected LxWxH/win base+increment [x|o])", offer)
+		}
+		if _, err := parseArenaBoard(terms[0]); err != nil {
+			return err
+		}
+		if _, err := parseArenaTimeControl(terms[1]); err != nil {
+			return err
+		}
+	} else if challenge != "" {
+		return errors.New("--challenge needs the terms of --offer")
+	}
+	bot, err := newBotFromSpec(spec, 'x', "")
+	if err != nil {
+		return err
+	}
+	bot.Close()
+
+	if lang == "" {
+		lang = localeFromEnvironment()
+	}
+	if err := setLocale(lang); err != nil {
+		return err
+	}
+	closeLog, err := logging.apply()
+	if err != nil {
+		return err
+	}
+	defer closeLog()
+
+	conn, err := net.Dial("tcp", server)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	fmt.Fprintf(conn, "hello %s %s\n", name, secret)
+	lines := netLines(conn)
+	if !lines.Scan() {
+		return fmt.Errorf("the arena closed the connection: %v", cmp.Or(lines.Err(), io.EOF))
+	}
+	line := lines.Text()
+	fields := strings.Fields(line)
+	switch {
+	case len(fields) > 0 && fields[0] == "error":
+		return fmt.Errorf("the arena refused the login: %s", strings.Join(fields[1:], " "))
+	case len(fields) != 3 || fields[0] != "welcome":
+		return fmt.Errorf("unexpected answer %q", strings.TrimSpace(line))
+	}
+	fmt.Fprint(output, msg("arena.welcome", fields[1], fields[2]))
+	// makeOffer seeks or challenges on the terms of --offer, if given
+	makeOffer := func() {
+		if challenge != "" {
+			fmt.Fprintf(conn, "challenge %s %s\n", challenge, strings.Join(terms, " "))
+		} else if len(terms) > 0 {
+			fmt.Fprintf(conn, "seek %s\n", strings.Join(terms, " "))
+		}
+	}
+	makeOffer()
+
+	// Arena messages are shown, and answered when they call for it; everything else goes to the engine
+	commands, forward := io.Pipe()
+	go func() {
+		defer forward.Close()
+		for lines.Scan() {
+			line := lines.Text() + "\n"
+			if arenaMessage, isArena := strings.CutPrefix(line, "arena "); isArena {
+				fields := strings.Fields(arenaMessage)
+				if len(fields) > 0 && fields[0] == "record" {
+					continue // Game records are for engines keeping their games
+				}
+				fmt.Fprint(output, msg("arena.message", strings.TrimSpace(arenaMessage)))
+				switch {
+				case len(fields) > 1 && fields[0] == "challenge" && acceptChallenges:
+					fmt.Fprintf(conn, "accept %s\n", fields[1])
+				case len(fields) > 0 && fields[0] == "rating":
+					makeOffer() // The match is over
+				}
+			} else if _, err := io.WriteString(forward, line); err != nil {
+				return
+			}
+		}
+	}()
+	newEngine(spec, engine.BoardConfig{Length: 3, Width: 3, Height: 3, Win: 3}, conn).Run(commands)
+	return nil
+}
@@ -0,0 +1,348 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"tic-tac-toe-3d-bots/bots"
+	"tic-tac-toe-3d-bots/engine"
+)
+
+// Engines logged in to the arena can arrange their own games, much as players do on online chess servers:
+//
+//	seek <LxWxH/win> <base>+<inc> [x|o]              engine → arena: look for a game on this board, each engine having
+//	                                                  base seconds on its clock plus inc seconds after every move,
+//	                                                  playing x or o if given and either otherwise
+//	challenge <name> <LxWxH/win> <base>+<inc> [x|o]  engine → arena: offer the same game to one engine only
+//	accept <id>                                       engine → arena: take a seek or a challenge; the game starts at once
+//	decline <id>                                      engine → arena: turn a challenge down
+//	unseek [id]                                       engine → arena: withdraw one of its offers, or all of them
+//	seeks                                             engine → arena: list the open seeks
+//	arena offered <id>                                arena → engine: its seek or challenge is open under id
+//	arena seeking <id> <name> <rating> <board> <clock> <x|o|any>     arena → engines: an open seek, with the seeker's side
+//	arena challenge <id> <name> <rating> <board> <clock> <x|o|any>   arena → engine: it is challenged, with the challenger's side
+//	arena declined <id> <name>                        arena → engine: its challenge was turned down
+//	arena withdrawn <id>                              arena → engine: a challenge it received is no longer open
+//	arena refused <text>                              arena → engine: its command was refused
+//
+// A seek matching one already open, on the same board and clock with compatible sides, is accepted at once.
+// Sides not asked for are drawn at random. A game arranged this way is a match of one game, announced with
+// arena match and played like any other; the engine's offers are withdrawn when it starts
+
+// ARENA_OFFER_LIMIT is how many offers an engine may have open at once
+const ARENA_OFFER_LIMIT = 8
+
+// arenaOfferCommands are the commands engines send to arrange games; the arena answers them whenever they arrive
+var arenaOfferCommands = map[string]bool{"seek": true, "challenge": true, "accept": true, "decline": true, "unseek": true, "seeks": true}
+
+// arenaTimeControl is a game clock: Base for the whole game, plus Increment after every move
+type arenaTimeControl struct {
+	Base      time.Duration
+	Increment time.Duration
+}
+
+// parseArenaTimeControl parses a clock such as "60+2", in seconds
+func parseArenaTimeControl(value string) (arenaTimeControl, error) {
+	base, increment, found := strings.Cut(value, "+")
+	baseSeconds, err := strconv.ParseFloat(base, 64)
+	incrementSeconds, incrementErr := strconv.ParseFloat(increment, 64)
+	if !found || err != nil || incrementErr != nil || baseSeconds <= 0 || incrementSeconds < 0 || baseSeconds > 24*3600 || incrementSeconds > 3600 {
+		return arenaTimeControl{}, fmt.Errorf("invalid clock %q (expected <base>+<increment> in seconds, e.g. 60+2)", value)
+	}
+	return arenaTimeControl{Base: time.Duration(baseSeconds * float64(time.Second)),
+		Increment: time.Duration(incrementSeconds * float64(time.Second))}, nil
+}
+
+func (clock arenaTimeControl) String() string {
+	return strconv.FormatFloat(clock.Base.Seconds(), 'f', -1, 64) + "+" + strconv.FormatFloat(clock.Increment.Seconds(), 'f', -1, 64)
+}
+
+// budget is the thinking time given to the next move on board of an engine with remaining on its clock: the soft limit
+// of the time manager's budget, as engines take their movetime as a limit to keep to
+func (clock arenaTimeControl) budget(board *engine.Board, remaining time.Duration) time.Duration {
+	return bots.NewTimeManager(clock.Increment).Budget(board, remaining).Soft
+}
+
+// parseArenaBoard parses a board such as "4x4x4/4", the win length defaulting to the smallest dimension
+func parseArenaBoard(value string) (engine.BoardConfig, error) {
+	board, err := engine.ParseBoardConfig(value)
+	if err != nil {
+		return engine.BoardConfig{}, err
+	}
+	if board.Win == 0 {
+		board.Win = min(board.Length, board.Width, board.Height)
+	}
+	if err := engine.ValidateBoardDimensions(board.Length, board.Width, board.Height, board.Win); err != nil {
+		return engine.BoardConfig{}, err
+	}
+	return *board, nil
+}
+
+// arenaBoardString writes a board as the arena protocol does, e.g. "4x4x4/4"
+func arenaBoardString(board engine.BoardConfig) string {
+	return fmt.Sprintf("%dx%dx%d/%d", board.Length, board.Width, board.Height, board.Win)
+}
+
+// arenaOffer is an open seek, or a challenge to one engine
+type arenaOffer struct {
+	id    int
+	from  *arenaEngine
+	to    *arenaEngine // the challenged engine; nil for a seek
+	board engine.BoardConfig
+	clock arenaTimeControl
+	side  byte // 'x' or 'o' for the side from wants to play, 0 for either
+}
+
+// sideName writes the side of an offer as the protocol does
+func (offer *arenaOffer) sideName() string {
+	if offer.side == 0 {
+		return "any"
+	}
+	return string(offer.side)
+}
+
+// announce writes the protocol line telling another engine about the offer
+func (offer *arenaOffer) announce(arena *Arena) string {
+	kind := "seeking"
+	if offer.to != nil {
+		kind = "challenge"
+	}
+	return fmt.Sprintf("arena %s %d %s %.0f %s %s %s", kind, offer.id, offer.from.name, arena.rating(offer.from.name).Rating,
+		arenaBoardString(offer.board), offer.clock, offer.sideName())
+}
+
+// offer answers a seek or challenge command of an engine
+func (arena *Arena) offer(from *arenaEngine, fields []string) {
+	arena.mutex.Lock()
+	defer arena.mutex.Unlock()
+
+	var err error
+	switch fields[0] {
+	case "seek":
+		err = arena.seek(from, fields[1:])
+	case "challenge":
+		err = arena.challenge(from, fields[1:])
+	case "accept":
+		err = arena.accept(from, fields[1:])
+	case "decline":
+		err = arena.decline(from, fields[1:])
+	case "unseek":
+		err = arena.unseek(from, fields[1:])
+	case "seeks":
+		for _, offer := range arena.openSeeks() {
+			from.send("%s", offer.announce(arena))
+		}
+	}
+	if err != nil {
+		arenaLog.Debug("offer refused", "name", from.name, "command", strings.Join(fields, " "), "error", err)
+		from.send("arena refused %v", err)
+	}
+}
+
+// parseOffer reads the board, clock and optional side of a seek or challenge
+func (arena *Arena) parseOffer(from *arenaEngine, args []string) (*arenaOffer, error) {
+	if len(args) < 2 || len(args) > 3 {
+		return nil, fmt.Errorf("expected <LxWxH/win> <base>+<inc> [x|o]")
+	}
+	offer := &arenaOffer{from: from}
+	var err error
+	if offer.board, err = parseArenaBoard(args[0]); err != nil {
+		return nil, err
+	}
+	if offer.clock, err = parseArenaTimeControl(args[1]); err != nil {
+		return nil, err
+	}
+	if len(args) == 3 {
+		if args[2] != "x" && args[2] != "o" {
+			return nil, fmt.Errorf("invalid side %q (expected x or o)", args[2])
+		}
+		offer.side = args[2][0]
+	}
+	open := 0
+	for _, other := range arena.offers {
+		if other.from == from {
+			open++
+		}
+	}
+	if open >= ARENA_OFFER_LIMIT {
+		return nil, fmt.Errorf("too many open offers (at most %d)", ARENA_OFFER_LIMIT)
+	}
+	return offer, nil
+}
+
+// seek posts a seek, or starts a game at once if it matches one already open; the caller holds the mutex
+func (arena *Arena) seek(from *arenaEngine, args []string) error {
+	offer, err := arena.parseOffer(from, args)
+	if err != nil {
+		return err
+	}
+	if !from.busy {
+		for _, open := range arena.openSeeks() {
+			if open.from != from && !open.from.busy && open.from.connected() && open.board == offer.board && open.clock == offer.clock &&
+				(open.side == 0 || offer.side == 0 || open.side != offer.side) {
+				arena.start(open, from, offer.side)
+				return nil
+			}
+		}
+	}
+
+	arena.lastOffer++
+	offer.id = arena.lastOffer
+	arena.offers[offer.id] = offer
+	from.send("arena offered %d", offer.id)
+	arenaLog.Info("engine seeks a game", "name", from.name, "offer", offer.id, "board", arenaBoardString(offer.board), "clock", offer.clock.String())
+	fmt.Fprint(arena.output, msg("arena.seek", from.name, arenaBoardString(offer.board), offer.clock))
+	for _, engine := range arena.engines {
+		if engine != from && !engine.busy {
+			engine.send("%s", offer.announce(arena))
+		}
+	}
+	return nil
+}
+
+// challenge offers a game to one engine; the caller holds the mutex
+func (arena *Arena) challenge(from *arenaEngine, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("expected challenge <name> <LxWxH/win> <base>+<inc> [x|o]")
+	}
+	to := arena.engines[args[0]]
+	switch {
+	case to == nil || !to.connected():
+		return fmt.Errorf("%q is not connected", args[0])
+	case to == from:
+		return fmt.Errorf("cannot challenge yourself")
+	case to.busy:
+		return fmt.Errorf("%s is playing a match", to.name)
+	}
+	offer, err := arena.parseOffer(from, args[1:])
+	if err != nil {
+		return err
+	}
+
+	arena.lastOffer++
+	offer.id, offer.to = arena.lastOffer, to
+	arena.offers[offer.id] = offer
+	from.send("arena offered %d", offer.id)
+	to.send("%s", offer.announce(arena))
+	arenaLog.Info("engine challenges another", "name", from.name, "opponent", to.name, "offer", offer.id)
+	fmt.Fprint(arena.output, msg("arena.challenge", from.name, to.name, arenaBoardString(offer.board), offer.clock))
+	return nil
+}
+
+// accept takes an offer, starting its game; the caller holds the mutex
+func (arena *Arena) accept(taker *arenaEngine, args []string) error {
+	offer, err := arena.lookupOffer(args)
+	switch {
+	case err != nil:
+		return err
+	case offer.from == taker:
+		return fmt.Errorf("offer %d is your own", offer.id)
+	case offer.to != nil && offer.to != taker:
+		return fmt.Errorf("offer %d is a challenge to %s", offer.id, offer.to.name)
+	case taker.busy:
+		return fmt.Errorf("finish your match first")
+	case offer.from.busy || !offer.from.connected():
+		return fmt.Errorf("%s is not available", offer.from.name)
+	}
+	arena.start(offer, taker, 0)
+	return nil
+}
+
+// decline turns a challenge down; the caller holds the mutex
+func (arena *Arena) decline(to *arenaEngine, args []string) error {
+	offer, err := arena.lookupOffer(args)
+	if err != nil {
+		return err
+	}
+	if offer.to != to {
+		return fmt.Errorf("offer %d is not a challenge to you", offer.id)
+	}
+	delete(arena.offers, offer.id)
+	offer.from.send("arena declined %d %s", offer.id, to.name)
+	return nil
+}
+
+// unseek withdraws one of the engine's offers, or all of them without an id; the caller holds the mutex
+func (arena *Arena) unseek(from *arenaEngine, args []string) error {
+	if len(args) == 0 {
+		for id, offer := range arena.offers {
+			if offer.from == from {
+				arena.withdraw(id)
+			}
+		}
+		return nil
+	}
+	offer, err := arena.lookupOffer(args)
+	if err != nil {
+		return err
+	}
+	if offer.from != from {
+		return fmt.Errorf("offer %d is not yours", offer.id)
+	}
+	arena.withdraw(offer.id)
+	return nil
+}
+
+// lookupOffer finds the open offer whose id is the only argument
+func (arena *Arena) lookupOffer(args []string) (*arenaOffer, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("expected an offer id")
+	}
+	id, err := strconv.Atoi(args[0])
+	if err != nil || arena.offers[id] == nil {
+		return nil, fmt.Errorf("no open offer %q", args[0])
+	}
+	return arena.offers[id], nil
+}
+
+// withdraw removes an offer, telling the challenged engine of a challenge; the caller holds the mutex
+func (arena *Arena) withdraw(id int) {
+	offer := arena.offers[id]
+	delete(arena.offers, id)
+	if offer.to != nil && offer.to.connected() {
+		offer.to.send("arena withdrawn %d", id)
+	}
+}
+
+// openSeeks returns the open seeks, oldest first; the caller holds the mutex
+func (arena *Arena) openSeeks() []*arenaOffer {
+	var seeks []*arenaOffer
+	for _, offer := range arena.offers {
+		if offer.to == nil {
+			seeks = append(seeks, offer)
+		}
+	}
+	sort.Slice(seeks, func(i, j int) bool { return seeks[i].id < seeks[j].id })
+	return seeks
+}
+
+// start plays the game of an offer taken by taker, who asked for takerSide (0 for either)
+// Both engines' offers are withdrawn, as they are busy until the game is over; the caller holds the mutex
+func (arena *Arena) start(offer *arenaOffer, taker *arenaEngine, takerSide byte) {
+	fromSide := offer.side
+	if fromSide == 0 && takerSide != 0 {
+		fromSide = engine.OpponentSymbol(takerSide)
+	} else if fromSide == 0 {
+		fromSide = "xo"[bots.NextSeed()%2]
+	}
+	players := [2]*arenaEngine{offer.from, taker}
+	if fromSide == 'o' {
+		players = [2]*arenaEngine{taker, offer.from}
+	}
+
+	delete(arena.offers, offer.id)
+	for id, other := range arena.offers {
+		if other.from == offer.from || other.from == taker || other.to == offer.from || other.to == taker {
+			arena.withdraw(id)
+		}
+	}
+	for i, engine := range players {
+		engine.busy, engine.last = true, players[1-i].name
+	}
+	clock := offer.clock
+	arenaLog.Info("offer taken", "offer", offer.id, "x", players[0].name, "o", players[1].name)
+	go arena.playMatch(players, arenaMatch{board: offer.board, games: 1, clock: &clock})
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"tic-tac-toe-3d-bots/engine"
+)
+
+// ThreatMarks selects whose check threats printBoard highlights
+type ThreatMarks int
+
+const (
+	ThreatMarksBoth ThreatMarks = iota // highlight threats of both players
+	ThreatMarksX                       // highlight only 'x' threats
+	ThreatMarksO                       // highlight only 'o' threats
+	ThreatMarksNone                    // highlight no threats
+)
+
+// RenderOptions controls the decorations printBoard draws on top of the pieces
+// The zero value shows every decoration
+type RenderOptions struct {
+	HideWinHighlight bool        // don't capitalize the pieces of a winning line
+	Threats          ThreatMarks // whose threats get capitals and '#' markers
+	Accessible       bool        // list each column as plain text instead of drawing the projection
+}
+
+// DefaultRenderOptions is how boards are drawn; set from the command line at startup
+var DefaultRenderOptions RenderOptions
+
+// parseThreatMarks parses "both", "x", "o" or "none"
+func parseThreatMarks(s string) (ThreatMarks, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "both":
+		return ThreatMarksBoth, nil
+	case "x":
+		return ThreatMarksX, nil
+	case "o":
+		return ThreatMarksO, nil
+	case "none":
+		return ThreatMarksNone, nil
+	}
+	return ThreatMarksBoth, fmt.Errorf("unknown threat marks %q (expected both, x, o or none)", s)
+}
+
+// showsThreatsFor reports whether threats made by player should be highlighted
+func (ro RenderOptions) showsThreatsFor(player byte) bool {
+	switch ro.Threats {
+	case ThreatMarksX:
+		return player == 'x'
+	case ThreatMarksO:
+		return player == 'o'
+	case ThreatMarksNone:
+		return false
+	}
+	return true
+}
+
+// printBoard displays the board in a 2D projection
+// Shows winning lines and check threats with capital letters and '#' for critical cells,
+// as far as enabled by DefaultRenderOptions
+func printBoard(b *engine.Board) {
+	printBoardWithOverlay(b, nil)
+}
+
+// printBoardWithOverlay displays the board like printBoard, then draws overlay markers over the given empty cells
+func printBoardWithOverlay(b *engine.Board, overlay map[[3]int]byte) {
+	if DefaultRenderOptions.Accessible {
+		printAccessible(b, overlay)
+		return
+	}
+
+	toPrint := make([][]byte, b.Length+b.Width+b.Height-2)
+	for i := range toPrint {
+		toPrint[i] = make([]byte, b.Length*b.Width)
+		for j := range toPrint[i] {
+			toPrint[i][j] = ' '
+		}
+	}
+
+	// First, fill in the normal board state
+	for i := 0; i < b.Length; i++ {
+		for j := 0; j < b.Width; j++ {
+			for k := 0; k < b.Height; k++ {
+				toPrint[i+b.Width-j+b.Height-k-2][i*b.Width+j] = b.Grid[i][j][k]
+			}
+		}
+	}
+
+	directions := [][3]int{
+		{1, 0, 0}, {0, 1, 0}, {0, 0, 1}, // 1D
+		{1, 1, 0}, {1, -1, 0}, {1, 0, 1}, {1, 0, -1}, {0, 1, 1}, {0, 1, -1}, // 2D diagonals
+		{1, 1, 1}, {1, -1, -1}, {1, 1, -1}, {1, -1, 1}, // 3D diagonals
+	}
+
+	// Check all lines for winning conditions and check threats
+	for i := 0; i < b.Length; i++ {
+		for j := 0; j < b.Width; j++ {
+			for k := 0; k < b.Height; k++ {
+				for _, dir := range directions {
+					// Check if this line segment is valid
+					endX := i + (b.WinLength-1)*dir[0]
+					endY := j + (b.WinLength-1)*dir[1]
+					endZ := k + (b.WinLength-1)*dir[2]
+
+					if !b.IsValidCoordinate(endX, endY, endZ) {
+						continue
+					}
+
+					line := b.GetLine([3]int{i, j, k}, dir)
+					xCount := engine.CountBytes(line, 'x')
+					oCount := engine.CountBytes(line, 'o')
+					emptyCount := engine.CountBytes(line, '|')
+
+					// Case 1: Winning line (all pieces of one player)
+					if !DefaultRenderOptions.HideWinHighlight && ((xCount == b.WinLength) || (oCount == b.WinLength)) {
+						// Highlight all pieces in winning line as capitals
+						for pos := 0; pos < b.WinLength; pos++ {
+							x := i + pos*dir[0]
+							y := j + pos*dir[1]
+							z := k + pos*dir[2]
+
+							printY := x + b.Width - y + b.Height - z - 2
+							printX := x*b.Width + y
+
+							if printY >= 0 && printY < len(toPrint) && printX >= 0 && printX < len(toPrint[printY]) {
+								currentPiece := toPrint[printY][printX]
+								if currentPiece == 'x' {
+									toPrint[printY][printX] = 'X'
+								} else if currentPiece == 'o' {
+									toPrint[printY][printX] = 'O'
+								}
+							}
+						}
+					}
+
+					// Case 2: Check threat (winLength-1 pieces + 1 empty that can be played)
+					threatOwner := byte('x')
+					if xCount == 0 {
+						threatOwner = 'o'
+					}
+					if emptyCount == 1 && (oCount == 0 || xCount == 0) && DefaultRenderOptions.showsThreatsFor(threatOwner) {
+						var criticalCell [3]int
+
+						// Find the empty cell
+						for pos := 0; pos < b.WinLength; pos++ {
+							x := i + pos*dir[0]
+							y := j + pos*dir[1]
+							z := k + pos*dir[2]
+
+							if b.Grid[x][y][z] == '|' {
+								criticalCell = [3]int{x, y, z}
+								break
+							}
+						}
+
+						// Check if the critical cell can actually be played (correct height)
+						canBePlayed := (criticalCell[2] == b.CurrentHeights[criticalCell[0]][criticalCell[1]])
+
+						if canBePlayed {
+							// Highlight threat line pieces in capital letters
+							for pos := 0; pos < b.WinLength; pos++ {
+								x := i + pos*dir[0]
+								y := j + pos*dir[1]
+								z := k + pos*dir[2]
+
+								printY := x + b.Width - y + b.Height - z - 2
+								printX := x*b.Width + y
+
+								if printY >= 0 && printY < len(toPrint) && printX >= 0 && printX < len(toPrint[printY]) {
+									currentPiece := toPrint[printY][printX]
+									if currentPiece == 'x' {
+										toPrint[printY][printX] = 'X'
+									} else if currentPiece == 'o' {
+										toPrint[printY][printX] = 'O'
+									} else if currentPiece == '|' {
+										toPrint[printY][printX] = '#'
+									}
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+
+	// Overlay markers go on top of everything else
+	for cell, marker := range overlay {
+		if b.IsValidCoordinate(cell[0], cell[1], cell[2]) && b.Grid[cell[0]][cell[1]][cell[2]] == '|' {
+			toPrint[cell[0]+b.Width-cell[1]+b.Height-cell[2]-2][cell[0]*b.Width+cell[1]] = marker
+		}
+	}
+
+	for i := range toPrint {
+		fmt.Println(string(toPrint[i]))
+	}
+}
+
+// printAccessible lists the board one column per line for screen readers, bottom cell first, e.g.
+//
+//	A1: x, o, empty
+//
+// Overlay markers are named after the cell they mark
+func printAccessible(b *engine.Board, overlay map[[3]int]byte) {
+	for i := 0; i < b.Length; i++ {
+		for j := 0; j < b.Width; j++ {
+			cells := make([]string, b.Height)
+			for k := 0; k < b.Height; k++ {
+				switch b.Grid[i][j][k] {
+				case 'x':
+					cells[k] = "x"
+				case 'o':
+					cells[k] = "o"
+				default:
+					cells[k] = msg("board.empty")
+				}
+				if marker, marked := overlay[[3]int{i, j, k}]; marked {
+					cells[k] += msg("board.marked", marker)
+				}
+			}
+			fmt.Printf("%c%d: %s\n", 'A'+byte(i), j+1, strings.Join(cells, ", "))
+		}
+	}
+	if b.PlayerWin != '|' {
+		fmt.Print(msg("board.has_won", b.PlayerWin))
+	}
+}
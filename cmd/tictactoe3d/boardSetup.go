@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+
+	"tic-tac-toe-3d-bots/engine"
+)
+
+// BoardPreset is a named board configuration offered in the menus
+type BoardPreset struct {
+	Length    int
+	Width     int
+	Height    int
+	WinLength int
+}
+
+// boardPresets lists the board configurations offered before every game
+var boardPresets = []BoardPreset{
+	{3, 3, 3, 3},
+	{4, 4, 4, 4},
+	{5, 5, 5, 4},
+}
+
+// String describes the preset, e.g. "4x4x4 (4 in a row)"
+func (preset BoardPreset) String() string {
+	return msg("board.preset", preset.Length, preset.Width, preset.Height, preset.WinLength)
+}
+
+// chooseBoard asks the user for a board configuration and creates the board
+// Pressing Enter (or an invalid answer) selects the mode's default cube of defaultSize
+func chooseBoard(defaultSize int) *engine.Board {
+	fmt.Println(msg("board.choose"))
+	for i, preset := range boardPresets {
+		marker := ""
+		if preset.Length == defaultSize && preset.Width == defaultSize && preset.Height == defaultSize {
+			marker = msg("board.default")
+		}
+		fmt.Printf("%d. %s%s\n", i+1, preset, marker)
+	}
+	fmt.Print(msg("board.custom", len(boardPresets)+1))
+	fmt.Print(msg("board.prompt", len(boardPresets)+1))
+
+	var choice int
+	fmt.Scanln(&choice)
+
+	if choice >= 1 && choice <= len(boardPresets) {
+		preset := boardPresets[choice-1]
+		board, _ := engine.New(engine.WithDims(preset.Length, preset.Width, preset.Height), engine.WithWinLength(preset.WinLength))
+		return board
+	}
+
+	if choice == len(boardPresets)+1 {
+		var length, width, height, winLength int
+		fmt.Print(msg("board.custom_prompt"))
+		fmt.Scanln(&length, &width, &height, &winLength)
+
+		board, err := engine.New(engine.WithDims(length, width, height), engine.WithWinLength(winLength))
+		if err == nil {
+			return board
+		}
+		fmt.Print(msg("board.invalid_custom", err))
+	}
+
+	board, _ := engine.New(engine.WithDims(defaultSize, defaultSize, defaultSize))
+	return board
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+
+	"tic-tac-toe-3d-bots/bots"
+)
+
+// printBotChoices lists every registered bot followed by the loaded profiles as numbered menu entries
+// Returns the number of entries printed
+func printBotChoices() int {
+	registrations := bots.RegisteredBots()
+	for i, registration := range registrations {
+		fmt.Printf("%d. %s (%s)\n", i+1, registration.DisplayName, registration.Description)
+	}
+	printProfileChoices(len(registrations) + 1)
+	return len(registrations) + len(botProfiles)
+}
+
+// createBot creates a bot based on a menu choice numbered as by printBotChoices
+// An empty defaultName names the bot after its type; returns nil if the choice is invalid
+func createBot(choice int, symbol byte, defaultName string) bots.BotInterface {
+	registrations := bots.RegisteredBots()
+	if choice >= 1 && choice <= len(registrations) {
+		registration := registrations[choice-1]
+		if defaultName == "" {
+			defaultName = registration.DisplayName
+		}
+		bot, _ := registration.Create(symbol, defaultName, nil)
+		return bot
+	}
+
+	// Choices after the registered bots select a loaded profile
+	profile, ok := profileForChoice(choice, len(registrations)+1)
+	if !ok {
+		return nil
+	}
+	bot, err := newBotFromProfile(profile, symbol)
+	if err != nil {
+		fmt.Println(msg("profile.create_error"), err)
+		return nil
+	}
+	return bot
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"tic-tac-toe-3d-bots/bots"
+	"tic-tac-toe-3d-bots/engine"
+	"tic-tac-toe-3d-bots/formats"
+)
+
+// calibrationReferences are the bots the calibrate command measures LimitedBot's levels against, with the ratings
+// that anchor the scale of bots.StrengthLevels
+var calibrationReferences = []struct {
+	Spec string
+	Elo  float64
+}{
+	{Spec: "random", Elo: 600},
+	{Spec: "rules", Elo: 1000},
+	{Spec: "alphabeta:depth=3", Elo: 1500},
+	{Spec: "alphabeta:depth=7", Elo: 2000},
+}
+
+// runCalibrate implements the calibrate command: it plays each of LimitedBot's levels, or the given ratings, against
+// every reference bot and prints the rating each one performs at, to check and tune bots.StrengthLevels
+func runCalibrate(args []string, output io.Writer) error {
+	var boardSpec, eloList, lang string
+	var games, workers int
+
+	fs := flag.NewFlagSet("calibrate", flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.StringVar(&boardSpec, "board", "3x3x3", "board to play on, as LxWxH or LxWxH/win")
+	fs.StringVar(&eloList, "elo", "", "comma-separated ratings to measure (default: every calibrated level)")
+	fs.IntVar(&games, "games", 20, "games against each reference bot, sides alternating")
+	fs.IntVar(&workers, "workers", runtime.NumCPU(), "games played at once")
+	fs.StringVar(&lang, "lang", "", "language for messages: "+strings.Join(availableLocales(), ", ")+" (default from TTT_LANG or LANG)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+	if games < 1 {
+		return fmt.Errorf("--games must be at least 1")
+	}
+
+	if lang == "" {
+		lang = localeFromEnvironment()
+	}
+	if err := setLocale(lang); err != nil {
+		return err
+	}
+
+	config, err := engine.ParseBoardConfig(boardSpec)
+	if err != nil {
+		return err
+	}
+	if config.Win == 0 {
+		config.Win = min(config.Length, config.Width, config.Height)
+	}
+	board, err := engine.New(engine.WithConfig(*config))
+	if err != nil {
+		return err
+	}
+
+	var levels []int
+	for _, level := range bots.StrengthLevels {
+		levels = append(levels, level.Elo)
+	}
+	if eloList != "" {
+		levels = nil
+		for _, field := range strings.Split(eloList, ",") {
+			elo, err := strconv.Atoi(strings.TrimSpace(field))
+			if err != nil {
+				return fmt.Errorf("--elo: %v", err)
+			}
+			levels = append(levels, elo)
+		}
+	}
+
+	for _, elo := range levels {
+		level := bots.StrengthFor(elo)
+		fmt.Print(msg("calibrate.level", elo, level.Depth, level.Temperature))
+		limited := func(symbol byte) bots.BotInterface {
+			return bots.NewLimitedBot(symbol, fmt.Sprintf("Limited-%d", elo), elo)
+		}
+
+		points, opponents := 0.0, 0.0
+		for _, reference := range calibrationReferences {
+			newReference := func(symbol byte) bots.BotInterface {
+				bot, _ := newBotFromSpec(reference.Spec, symbol, "")
+				return bot
+			}
+			stats := runMatch(board, limited, newReference, games, workers, formats.EvESettings{}, nil, nil)
+			fmt.Print(msg("calibrate.reference", reference.Spec, reference.Elo, stats.Wins, stats.Draws, stats.Losses,
+				performanceRating(stats.Score, reference.Elo, stats.Games)))
+			points += stats.Score * float64(stats.Games)
+			opponents += reference.Elo * float64(stats.Games)
+		}
+		played := games * len(calibrationReferences)
+		fmt.Print(msg("calibrate.performance", elo, performanceRating(points/float64(played), opponents/float64(played), played)))
+	}
+	return nil
+}
+
+// performanceRating returns the rating that would expect to score score against opponents rated opponentElo on
+// average; a perfect or zero score counts as half a game short of it, so the result stays finite
+func performanceRating(score, opponentElo float64, games int) float64 {
+	margin := 0.5 / float64(games)
+	score = min(max(score, margin), 1-margin)
+	return opponentElo + 400*math.Log10(score/(1-score))
+}
@@ -0,0 +1,363 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"tic-tac-toe-3d-bots/bots"
+	"tic-tac-toe-3d-bots/engine"
+	"tic-tac-toe-3d-bots/formats"
+)
+
+// The chat command plays games against a bot in chat channels: anyone in a channel can start a game,
+// which they then play by posting commands, the bot answering in the channel:
+//
+//	!play [x|o] [bot]   start a game, playing x (the default) or o against bot (default --bot)
+//	!move <cell>        play a move, e.g. "!move A1" ("!m A1" for short)
+//	!board              show the board
+//	!resign             give up the game
+//	!help               list the commands
+//
+// Each channel has at most one game at a time. The chat platform is reached through a ChatTransport,
+// picked with --transport from chatTransports
+
+// CHAT_PREFIX starts every chat command
+const CHAT_PREFIX = "!"
+
+// DEFAULT_CHAT_MOVE_TIME is how long the bot may think about each move unless --movetime says otherwise
+const DEFAULT_CHAT_MOVE_TIME = 10 * time.Second
+
+// ChatMessage is a message posted in a chat channel
+type ChatMessage struct {
+	Channel string // channel the message was posted in; for a direct message, the sender, so that replies go back to them
+	User    string // name of the sender
+	Text    string
+}
+
+// ChatTransport connects the chat command to one chat platform
+type ChatTransport interface {
+	Connect(ctx context.Context) error         // connects and joins the channels to play in
+	Messages() <-chan ChatMessage              // messages posted in those channels; closed once disconnected
+	Send(channel string, lines []string) error // posts lines to channel, in order
+	Close() error
+}
+
+// ChatSettings configures a transport; each platform uses the settings that apply to it
+type ChatSettings struct {
+	Server   string   // address of the chat server, as host:port
+	Nick     string   // name to post under
+	Password string   // password of the server or account, if it needs one
+	Channels []string // channels to play in
+	TLS      bool     // connect with TLS
+}
+
+// chatTransports creates the transport of each supported chat platform, by the name given to --transport
+var chatTransports = map[string]func(settings ChatSettings) (ChatTransport, error){
+	"irc": newIRCTransport,
+}
+
+// chatGame is a game in progress in a channel
+type chatGame struct {
+	board   *engine.Board
+	bot     bots.BotInterface
+	player  string // user playing against the bot
+	human   byte   // symbol the user plays
+	session *GameSession
+
+	turnStart time.Time // when the user's turn started, to charge their thinking time
+}
+
+// ChatRelay plays the games of every channel, answering commands through its transport
+type ChatRelay struct {
+	transport ChatTransport
+	board     engine.BoardConfig
+	bot       string // bot spec used unless !play names another
+	moveTime  time.Duration
+	games     map[string]*chatGame // by channel
+}
+
+// Run answers commands until the transport disconnects, ending the games in progress
+func (relay *ChatRelay) Run() error {
+	defer func() {
+		for channel, game := range relay.games {
+			relay.endGame(channel, game)
+		}
+	}()
+	for message := range relay.transport.Messages() {
+		command, argument, _ := strings.Cut(strings.TrimSpace(message.Text), " ")
+		command, isCommand := strings.CutPrefix(strings.ToLower(command), CHAT_PREFIX)
+		if !isCommand {
+			continue
+		}
+		if lines := relay.handle(message, command, strings.Fields(argument)); len(lines) > 0 {
+			if err := relay.transport.Send(message.Channel, lines); err != nil {
+				return err
+			}
+		}
+	}
+	return fmt.Errorf("disconnected from the chat server")
+}
+
+// handle runs one command and returns the lines to answer with
+func (relay *ChatRelay) handle(message ChatMessage, command string, args []string) []string {
+	game := relay.games[message.Channel]
+	switch command {
+	case "help":
+		return strings.Split(strings.TrimRight(msg("chat.help"), "\n"), "\n")
+	case "play":
+		if game != nil {
+			return []string{msg("chat.in_progress", game.player)}
+		}
+		return relay.startGame(message, args)
+	case "move", "m":
+		switch {
+		case game == nil:
+			return []string{msg("chat.no_game")}
+		case message.User != game.player:
+			return []string{msg("chat.not_your_game", message.User, game.player)}
+		case len(args) != 1:
+			return []string{msg("chat.move_usage")}
+		}
+		return relay.playMove(message.Channel, game, strings.ToUpper(args[0]))
+	case "board":
+		if game == nil {
+			return []string{msg("chat.no_game")}
+		}
+		return chatBoardLines(game.board)
+	case "resign":
+		switch {
+		case game == nil:
+			return []string{msg("chat.no_game")}
+		case message.User != game.player:
+			return []string{msg("chat.not_your_game", message.User, game.player)}
+		}
+		game.session.SetResult(engine.OpponentSymbol(game.human), "resigned")
+		relay.endGame(message.Channel, game)
+		return []string{msg("chat.resigned", game.player, game.bot.Name())}
+	}
+	return nil // Commands of other bots in the channel are none of our business
+}
+
+// startGame starts a game for the sender of message; args are the optional side and bot spec of !play
+func (relay *ChatRelay) startGame(message ChatMessage, args []string) []string {
+	human, spec := byte('x'), relay.bot
+	if len(args) > 0 && (args[0] == "x" || args[0] == "o") {
+		human, args = args[0][0], args[1:]
+	}
+	if len(args) > 1 {
+		return []string{msg("chat.play_usage")}
+	}
+	var bot bots.BotInterface
+	var err error
+	if len(args) == 1 {
+		bot, err = newAPIBot(args[0], engine.OpponentSymbol(human), args[0]) // Anyone in the channel picks it
+	} else {
+		bot, err = newBotFromSpec(spec, engine.OpponentSymbol(human), spec)
+	}
+	if err != nil {
+		return []string{msg("chat.error", message.User, err)}
+	}
+	board, err := engine.New(engine.WithConfig(relay.board))
+	if err != nil {
+		bot.Close()
+		return []string{msg("chat.error", message.User, err)}
+	}
+	players := [2]string{message.User, bot.Name()}
+	botConfigs := [2]*bots.BotConfig{nil, bots.ConfigOf(bot)}
+	if human == 'o' {
+		players, botConfigs = [2]string{bot.Name(), message.User}, [2]*bots.BotConfig{bots.ConfigOf(bot), nil}
+	}
+	game := &chatGame{board: board, bot: bot, player: message.User, human: human, turnStart: time.Now(),
+		session: startGame(board, formats.GameRecord{Mode: "chat", Players: players, Bots: botConfigs})}
+	relay.games[message.Channel] = game
+
+	lines := []string{msg("chat.started", message.User, human, bot.Name(), board.Length, board.Width, board.Height, board.WinLength)}
+	if human == 'o' {
+		return append(lines, relay.botMove(message.Channel, game)...)
+	}
+	return append(lines, msg("chat.your_move", message.User))
+}
+
+// playMove plays the user's move and the bot's answer
+func (relay *ChatRelay) playMove(channel string, game *chatGame, move string) []string {
+	if game.board.Move(move, game.human)[0] == -1 {
+		return []string{msg("chat.illegal", game.player, move)}
+	}
+	game.bot.OpponentMove(move)
+	game.session.RecordMove(move, time.Since(game.turnStart))
+	if lines := relay.gameOver(channel, game); lines != nil {
+		return lines
+	}
+	return relay.botMove(channel, game)
+}
+
+// botMove lets the bot play, and reports its move and what comes next
+func (relay *ChatRelay) botMove(channel string, game *chatGame) []string {
+	ctx, done := game.session.searchContext()
+	ctx, cancel := context.WithTimeout(ctx, relay.moveTime)
+	start := time.Now()
+	move, err := searchMove(ctx, game.bot, game.board)
+	cancel()
+	done()
+	if err != nil {
+		// A bot that outthinks --movetime loses on time, as in eve games; one that cannot move forfeits
+		reason, line := "forfeit", msg("chat.forfeit", game.bot.Name(), err)
+		if errors.Is(err, context.DeadlineExceeded) {
+			reason, line = "time", msg("chat.time_loss", game.bot.Name(), relay.moveTime)
+		}
+		game.session.SetResult(game.human, reason)
+		relay.endGame(channel, game)
+		return []string{line, msg("chat.won", game.player)}
+	}
+	game.session.RecordMove(move.Name, time.Since(start))
+	game.session.LogBotSearch(game.bot)
+	game.turnStart = time.Now()
+
+	lines := []string{msg("chat.bot_moved", game.bot.Name(), move.Name)}
+	if over := relay.gameOver(channel, game); over != nil {
+		return append(lines, over...)
+	}
+	return append(append(lines, chatBoardLines(game.board)...), msg("chat.your_move", game.player))
+}
+
+// gameOver ends the game if it is over, returning the board and the result; nil if the game goes on
+func (relay *ChatRelay) gameOver(channel string, game *chatGame) []string {
+	winner := game.board.CheckWin()
+	if winner == '|' && !game.board.IsFull() {
+		return nil
+	}
+	relay.endGame(channel, game)
+
+	lines := chatBoardLines(game.board)
+	switch winner {
+	case game.human:
+		return append(lines, msg("chat.won", game.player))
+	case '|':
+		return append(lines, msg("chat.draw"))
+	}
+	return append(lines, msg("chat.won", game.bot.Name()))
+}
+
+// endGame ends the game of channel
+func (relay *ChatRelay) endGame(channel string, game *chatGame) {
+	game.session.End()
+	game.bot.Close()
+	delete(relay.games, channel)
+}
+
+// chatBoardLines draws the board in a few lines of text, one per row: each column from bottom to top, '.' for empty cells,
+// e.g. "A  1:xo.. 2:.... 3:x... 4:...."
+func chatBoardLines(board *engine.Board) []string {
+	lines := make([]string, board.Length)
+	for i := range board.Length {
+		columns := make([]string, board.Width)
+		for j := range board.Width {
+			cells := make([]byte, board.Height)
+			for k := range board.Height {
+				cells[k] = board.Grid[i][j][k]
+				if cells[k] == '|' {
+					cells[k] = '.'
+				}
+			}
+			columns[j] = fmt.Sprintf("%d:%s", j+1, cells)
+		}
+		lines[i] = fmt.Sprintf("%c  %s", 'A'+byte(i), strings.Join(columns, " "))
+	}
+	return lines
+}
+
+// runChat implements the chat command: it plays games against a bot in chat channels until the connection is lost
+func runChat(args []string, output io.Writer) error {
+	var transportName, channels, spec, boardValue, lang string
+	var moveTime int
+	settings := ChatSettings{}
+	transports := make([]string, 0, len(chatTransports))
+	for name := range chatTransports {
+		transports = append(transports, name)
+	}
+	sort.Strings(transports)
+
+	fs := flag.NewFlagSet("chat", flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.StringVar(&transportName, "transport", "irc", "chat platform: "+strings.Join(transports, ", "))
+	fs.StringVar(&settings.Server, "server", "", "chat server to connect to, as host:port (required)")
+	fs.StringVar(&settings.Nick, "nick", "ttt3d", "name to play under")
+	fs.StringVar(&settings.Password, "password", "", "password of the server or account, if it needs one")
+	fs.BoolVar(&settings.TLS, "tls", false, "connect with TLS")
+	fs.StringVar(&channels, "channels", "", "comma-separated channels to play in, e.g. \"#tictactoe\" (required)")
+	fs.StringVar(&spec, "bot", "alphabeta", "bot to play against unless !play names another, e.g. alphabeta:depth=6")
+	fs.StringVar(&boardValue, "board", "4x4x4/4", "board of the games, as LxWxH/win")
+	fs.IntVar(&moveTime, "movetime", int(DEFAULT_CHAT_MOVE_TIME.Milliseconds()), "longest the bot may think about a move in milliseconds; a bot taking longer loses on time")
+	fs.StringVar(&lang, "lang", "", "language for messages: "+strings.Join(availableLocales(), ", ")+" (default from TTT_LANG or LANG)")
+	logging := addLogFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+
+	newTransport := chatTransports[transportName]
+	if newTransport == nil {
+		return fmt.Errorf("unknown chat transport %q (available: %s)", transportName, strings.Join(transports, ", "))
+	}
+	for _, channel := range strings.Split(channels, ",") {
+		if channel = strings.TrimSpace(channel); channel != "" && !slices.Contains(settings.Channels, channel) {
+			settings.Channels = append(settings.Channels, channel)
+		}
+	}
+	if settings.Server == "" || len(settings.Channels) == 0 {
+		return fmt.Errorf("--server and --channels are required")
+	}
+	if moveTime < 1 {
+		return fmt.Errorf("invalid --movetime %d (must be positive)", moveTime)
+	}
+	bot, err := newBotFromSpec(spec, 'o', "")
+	if err != nil {
+		return err
+	}
+	bot.Close()
+	board, err := engine.ParseBoardConfig(boardValue)
+	if err != nil {
+		return err
+	}
+	if board.Win == 0 {
+		board.Win = min(board.Length, board.Width, board.Height)
+	}
+	if err := engine.ValidateBoardDimensions(board.Length, board.Width, board.Height, board.Win); err != nil {
+		return err
+	}
+
+	if lang == "" {
+		lang = localeFromEnvironment()
+	}
+	if err := setLocale(lang); err != nil {
+		return err
+	}
+	closeLog, err := logging.apply()
+	if err != nil {
+		return err
+	}
+	defer closeLog()
+
+	transport, err := newTransport(settings)
+	if err != nil {
+		return err
+	}
+	defer transport.Close()
+	if err := transport.Connect(context.Background()); err != nil {
+		return err
+	}
+	fmt.Fprint(output, msg("chat.connected", settings.Server, settings.Nick, strings.Join(settings.Channels, ", ")))
+
+	relay := &ChatRelay{transport: transport, board: *board, bot: spec, moveTime: time.Duration(moveTime) * time.Millisecond,
+		games: make(map[string]*chatGame)}
+	return relay.Run()
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// IRC_REGISTER_TIMEOUT is how long the IRC server gets to welcome the relay after it connects
+const IRC_REGISTER_TIMEOUT = 30 * time.Second
+
+// IRC_LINE_INTERVAL spaces the lines the relay posts, so that the server does not disconnect it for flooding
+const IRC_LINE_INTERVAL = 300 * time.Millisecond
+
+// IRCTransport plays in IRC channels (implements ChatTransport)
+type IRCTransport struct {
+	settings ChatSettings
+	conn     net.Conn
+	reader   *bufio.Reader
+	messages chan ChatMessage
+
+	writeMutex sync.Mutex
+	lastPost   time.Time
+}
+
+// newIRCTransport creates an IRC transport; it connects on Connect
+func newIRCTransport(settings ChatSettings) (ChatTransport, error) {
+	for _, channel := range settings.Channels {
+		if !strings.HasPrefix(channel, "#") && !strings.HasPrefix(channel, "&") {
+			return nil, fmt.Errorf("invalid IRC channel %q (expected a name such as #tictactoe)", channel)
+		}
+	}
+	if strings.ContainsAny(settings.Nick, " \r\n") || settings.Nick == "" {
+		return nil, fmt.Errorf("invalid IRC nick %q", settings.Nick)
+	}
+	return &IRCTransport{settings: settings, messages: make(chan ChatMessage, 16)}, nil
+}
+
+// ircLine is a line received from the server, e.g. ":nick!user@host PRIVMSG #channel :hello"
+type ircLine struct {
+	prefix  string // sender, e.g. "nick!user@host"; empty for the server itself
+	command string
+	params  []string // the last one may contain spaces
+}
+
+// parseIRCLine splits a line received from the server
+func parseIRCLine(text string) ircLine {
+	line := ircLine{}
+	text = strings.TrimRight(text, "\r\n")
+	if rest, hasPrefix := strings.CutPrefix(text, ":"); hasPrefix {
+		line.prefix, text, _ = strings.Cut(rest, " ")
+	}
+	text, trailing, hasTrailing := strings.Cut(text, " :")
+	fields := strings.Fields(text)
+	if len(fields) > 0 {
+		line.command, line.params = strings.ToUpper(fields[0]), fields[1:]
+	}
+	if hasTrailing {
+		line.params = append(line.params, trailing)
+	}
+	return line
+}
+
+// nick returns the nick of the line's sender
+func (line ircLine) nick() string {
+	nick, _, _ := strings.Cut(line.prefix, "!")
+	return nick
+}
+
+// write sends one raw line to the server
+func (transport *IRCTransport) write(format string, args ...any) error {
+	transport.writeMutex.Lock()
+	defer transport.writeMutex.Unlock()
+	_, err := fmt.Fprintf(transport.conn, format+"\r\n", args...)
+	return err
+}
+
+// Connect implements ChatTransport: it registers with the server, waits for its welcome and joins the channels
+func (transport *IRCTransport) Connect(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, IRC_REGISTER_TIMEOUT)
+	defer cancel()
+
+	var err error
+	if transport.settings.TLS {
+		dialer := &tls.Dialer{}
+		transport.conn, err = dialer.DialContext(ctx, "tcp", transport.settings.Server)
+	} else {
+		dialer := &net.Dialer{}
+		transport.conn, err = dialer.DialContext(ctx, "tcp", transport.settings.Server)
+	}
+	if err != nil {
+		return err
+	}
+	transport.reader = bufio.NewReader(transport.conn)
+
+	nick := transport.settings.Nick
+	if transport.settings.Password != "" {
+		transport.write("PASS %s", transport.settings.Password)
+	}
+	transport.write("NICK %s", nick)
+	transport.write("USER %s 0 * :tic-tac-toe-3d-bots", nick)
+
+	deadline, _ := ctx.Deadline()
+	transport.conn.SetReadDeadline(deadline)
+	for {
+		text, err := transport.reader.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("the IRC server did not welcome us: %v", err)
+		}
+		line := parseIRCLine(text)
+		switch line.command {
+		case "PING":
+			transport.write("PONG :%s", strings.Join(line.params, " "))
+		case "001": // RPL_WELCOME
+			transport.conn.SetReadDeadline(time.Time{})
+			for _, channel := range transport.settings.Channels {
+				if err := transport.write("JOIN %s", channel); err != nil {
+					return err
+				}
+			}
+			go transport.read()
+			return nil
+		case "432", "433", "436": // The nick is invalid or taken
+			return fmt.Errorf("the IRC server refused the nick %q", nick)
+		case "464", "ERROR":
+			return fmt.Errorf("the IRC server refused the connection: %s", strings.Join(line.params, " "))
+		}
+	}
+}
+
+// read forwards the messages posted in the channels and to the relay until the connection is lost
+func (transport *IRCTransport) read() {
+	defer close(transport.messages)
+	for {
+		text, err := transport.reader.ReadString('\n')
+		if err != nil {
+			serverLog.Warn("lost the IRC connection", "server", transport.settings.Server, "error", err)
+			return
+		}
+		line := parseIRCLine(text)
+		switch {
+		case line.command == "PING":
+			transport.write("PONG :%s", strings.Join(line.params, " "))
+		case line.command == "PRIVMSG" && len(line.params) == 2:
+			channel := line.params[0]
+			if strings.EqualFold(channel, transport.settings.Nick) {
+				channel = line.nick() // A direct message: answer the sender
+			}
+			transport.messages <- ChatMessage{Channel: channel, User: line.nick(), Text: line.params[1]}
+		case line.command == "KICK" && len(line.params) >= 2 && strings.EqualFold(line.params[1], transport.settings.Nick):
+			serverLog.Warn("kicked from an IRC channel", "channel", line.params[0])
+		}
+	}
+}
+
+// Messages implements ChatTransport
+func (transport *IRCTransport) Messages() <-chan ChatMessage {
+	return transport.messages
+}
+
+// Send implements ChatTransport, posting one line at most every IRC_LINE_INTERVAL
+func (transport *IRCTransport) Send(channel string, lines []string) error {
+	for _, line := range lines {
+		if wait := IRC_LINE_INTERVAL - time.Since(transport.lastPost); wait > 0 {
+			time.Sleep(wait)
+		}
+		line = strings.NewReplacer("\r", " ", "\n", " ").Replace(line)
+		if err := transport.write("PRIVMSG %s :%s", channel, line); err != nil {
+			return err
+		}
+		transport.lastPost = time.Now()
+	}
+	return nil
+}
+
+// Close implements ChatTransport
+func (transport *IRCTransport) Close() error {
+	if transport.conn == nil {
+		return nil
+	}
+	transport.write("QUIT :bye")
+	return transport.conn.Close()
+}
@@ -0,0 +1,503 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"time"
+
+	"tic-tac-toe-3d-bots/bots"
+	"tic-tac-toe-3d-bots/engine"
+	"tic-tac-toe-3d-bots/formats"
+)
+
+// CLIOptions holds the game settings given on the command line or in a config file
+// An empty Mode means nothing was configured and the interactive menu should be shown
+type CLIOptions struct {
+	Mode        string    // pvp, pve, eve, pvestream, evestream or mirror
+	Length      int       // board length (0 uses the mode's default)
+	Width       int       // board width (0 uses the mode's default)
+	Height      int       // board height (0 uses the mode's default)
+	Win         int       // pieces in a row needed to win (0 uses the smallest dimension)
+	Bot1        string    // bot spec for the 'x' player, e.g. "alphabeta:depth=6"
+	Bot2        string    // bot spec for the 'o' player
+	Bot1Name    string    // display name for bot 1 (optional)
+	Bot2Name    string    // display name for bot 2 (optional)
+	Auto        bool      // play bot moves without waiting for Enter
+	Quiet       bool      // EvE: play automatically and print only the result and final statistics
+	Games       int       // EvE: number of games to play, sides swapping after each
+	Workers     int       // EvE: games of a match played at once (0 uses every CPU core)
+	Hash        int       // size in MiB of the transposition tables of bots not given one
+	Memory      int       // cap in MiB on the memory of every bot's search structures together (0 for none)
+	Seed        int64     // seed of every random choice (0 picks one from the clock)
+	Bots        []string  // tournament: bot specs of the entrants; gauntlet: the reference bots
+	BotNames    []string  // display names of Bots (empty uses the spec)
+	SPRT        *SPRT     // EvE: stop a match early once this test decides (nil plays every game)
+	Profiles    string    // bot profiles file (empty loads DEFAULT_PROFILES_FILE if present)
+	Plugins     string    // bot plugin file or directory (empty loads DEFAULT_PLUGINS_DIR if present)
+	Player      string    // human player's name, used for per-player statistics
+	Training    bool      // warn about blunders in PvE and offer to take them back
+	Lang        string    // message language, e.g. "id" (empty uses TTT_LANG or LANG)
+	Cursor      bool      // pick moves with the arrow keys instead of typing them
+	Playouts    int       // random playouts shown next to displayed evaluations (0 for none)
+	Resume      string    // saved game to continue instead of starting a new one
+	ResumeRun   string    // event log of an interrupted EvE run to continue (empty starts the run afresh)
+	Replay      string    // saved game to step through in the replay viewer
+	Events      string    // JSON Lines event log file, "-" for stdout (empty disables the log)
+	CSV         string    // directory to export games and summaries to as CSV (empty disables the export)
+	DB          string    // game database file recording every game (empty disables the database)
+	Ratings     string    // stats store every finished game is rated in (empty rates none)
+	Book        string    // game database whose openings the games of matches start from (empty starts them from the empty board)
+	BookPlies   int       // longest opening sampled from Book
+	RandomPlies int       // random moves openings are filled up to (0 for none)
+	SearchCache string    // file deep analyses are remembered in across runs (empty disables the cache)
+	Archive     string    // binary archive every finished game is appended to (empty disables the archive)
+	Journal     string    // directory of the journals games in progress are recovered from after a crash (empty disables them)
+	Output      string    // console output format: text or json
+	Log         *logFlags // diagnostics logging: level, format and file
+	Metrics     string    // address to serve Prometheus metrics on while the program runs (empty disables them)
+	Trace       *string   // OpenTelemetry collector to trace bot moves to (empty disables tracing)
+	Broadcast   string    // address to accept spectators of the run's games on over TCP (empty disables the broadcast)
+	BroadcastWS string    // address to also accept spectators on over WebSocket (empty disables it)
+
+	MoveTimeLimit   time.Duration          // bots exceeding this per-move time lose on time (0 means unlimited)
+	ResignScore     int                    // EvE: bots scoring their position this far below even resign (0 never resigns)
+	ResignMoves     int                    // EvE: moves in a row a bot must score its position lost before resigning
+	Adjudication    AdjudicationConfig     // EvE: when games are settled on the bots' agreeing evaluations
+	ShowSearchStats bool                   // print per-worker search statistics after bot moves
+	Render          RenderOptions          // board decorations
+	TTPolicy        bots.ReplacementPolicy // replacement policy of the transposition tables of bots not given one
+	TTLocking       bots.TTLocking         // how parallel searches share their transposition table, for bots not given a way
+}
+
+// parseCLIOptions parses command-line arguments into CLIOptions
+// If --config is given, the file supplies every value not set explicitly by a flag
+func parseCLIOptions(args []string, output io.Writer) (*CLIOptions, error) {
+	opts := &CLIOptions{}
+	var size int
+	var configPath string
+	var threats string
+	var entrants string
+	var sprt string
+	var ttPolicy string
+	var ttLocking string
+	var sprtAlpha, sprtBeta float64
+
+	fs := flag.NewFlagSet("tic-tac-toe-3d-bots", flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.StringVar(&configPath, "config", "", "path to a JSON game configuration file")
+	fs.StringVar(&opts.Mode, "mode", "", "game mode: pvp, pve, eve, tournament, gauntlet, pvestream, evestream, mirror to analyse a game played elsewhere, or engine to speak a UCI-like protocol on stdin/stdout with --bot1")
+	fs.IntVar(&size, "size", 0, "board size (length, width and height)")
+	fs.IntVar(&opts.Win, "win", 0, "pieces in a row needed to win (defaults to size)")
+	fs.StringVar(&opts.Bot1, "bot1", "", "bot playing 'x', as name[:key=value,...] (e.g. alphabeta:depth=6); bots: "+strings.Join(bots.RegisteredBotKeys(), ", "))
+	fs.StringVar(&opts.Bot2, "bot2", "", "bot playing 'o', as name[:key=value,...]; also the PvE opponent")
+	fs.BoolVar(&opts.Auto, "auto", false, "play bot moves without pausing between them")
+	fs.BoolVar(&opts.Quiet, "quiet", false, "EvE: run headless, printing only the result and final statistics (implies --auto)")
+	fs.IntVar(&opts.Games, "games", 1, "EvE: play a match of this many games, swapping sides after each, and report aggregate statistics; tournament and gauntlet: games per pairing")
+	fs.IntVar(&opts.ResignScore, "resign-score", 0, "EvE, tournament and gauntlet: a bot resigns once its own evaluation of its position is this far below even for --resign-moves moves in a row (0 never resigns)")
+	fs.IntVar(&opts.ResignMoves, "resign-moves", DEFAULT_RESIGN_MOVES, "moves in a row a bot must find its position lost before resigning with --resign-score")
+	fs.IntVar(&opts.Adjudication.Win, "adjudicate-win", 0, "EvE, tournament and gauntlet: adjudicate a game won once both bots score the position at least this far in the same side's favour for --adjudicate-moves moves in a row (0 never)")
+	fs.IntVar(&opts.Adjudication.Draw, "adjudicate-draw", 0, "with --draw-after, adjudicate a game drawn once both bots score the position within this of even for --adjudicate-moves moves in a row")
+	fs.IntVar(&opts.Adjudication.DrawAfter, "draw-after", 0, "move from which games may be adjudicated drawn with --adjudicate-draw (0 never)")
+	fs.IntVar(&opts.Adjudication.Moves, "adjudicate-moves", DEFAULT_ADJUDICATE_MOVES, "moves in a row both bots' evaluations must agree before a game is adjudicated")
+	fs.StringVar(&sprt, "sprt", "", "EvE match: stop early once an SPRT between these Elo bounds of bot1 over bot2 decides, e.g. \"0,10\"; --games is the maximum")
+	fs.Float64Var(&sprtAlpha, "sprt-alpha", 0.05, "SPRT false positive rate")
+	fs.Float64Var(&sprtBeta, "sprt-beta", 0.05, "SPRT false negative rate")
+	fs.StringVar(&entrants, "bots", "", "tournament: space-separated bot specs to play all-play-all, e.g. \"alphabeta:depth=4 rules random\"; gauntlet: the reference bots --bot1 plays against")
+	fs.IntVar(&opts.Workers, "workers", 1, "EvE: number of match games to play at once (0 uses every CPU core)")
+	fs.IntVar(&opts.Hash, "hash", bots.DEFAULT_TT_SIZE, "size in MiB of each bot's transposition table, unless its spec sets one with tt=<MiB>")
+	fs.IntVar(&opts.Memory, "search-memory", 0, "cap in MiB on the memory of every bot's search structures together: transposition tables are made smaller, evaluation caches evict positions and search trees stop growing to stay under it, with a warning logged (0 for no cap)")
+	fs.StringVar(&ttPolicy, "tt-policy", bots.DEFAULT_TT_POLICY.String(), "replacement policy of each bot's transposition table, unless its spec sets one with ttpolicy=<1-3>: always, depth (keep the deeper search) or twoslot (a depth-preferred and an always-replaced slot per position); compare them with the ttbench command")
+	fs.StringVar(&ttLocking, "tt-locking", bots.DEFAULT_TT_LOCKING.String(), "how the parallel searches of the concurrent alpha-beta bot share its transposition table, unless its spec sets it with ttlocking=<1-2>: lockfree (atomic entries) or sharded (a lock per shard, ttshards=<n> of them); ttbench compares them")
+	fs.Int64Var(&opts.Seed, "seed", 0, "seed every random choice of bots, so the run can be replayed exactly (0 picks one from the clock; printed with match results)")
+	fs.StringVar(&opts.Player, "player", DEFAULT_PLAYER_NAME, "human player's profile name, under which PvE games and statistics are recorded")
+	fs.BoolVar(&opts.Training, "training", false, "PvE training mode: warn about blunders and offer to take them back")
+	fs.BoolVar(&opts.Render.HideWinHighlight, "no-highlight", false, "don't capitalize the pieces of a winning line")
+	fs.BoolVar(&opts.Render.Accessible, "accessible", false, "screen-reader friendly output: list each column as text instead of drawing the board")
+	fs.StringVar(&threats, "threats", "both", "whose threats to mark with capitals and '#': both, x, o or none")
+	fs.BoolVar(&opts.Cursor, "cursor", false, "pick moves with the arrow keys and Enter instead of typing coordinates")
+	fs.IntVar(&opts.Playouts, "playouts", 0, "play this many random games out from each position whose evaluation is shown, and show the share each side won next to the minimax score (0 plays none)")
+	fs.StringVar(&opts.Lang, "lang", "", "language for menus and messages: "+strings.Join(availableLocales(), ", ")+" (default from TTT_LANG or LANG)")
+	fs.StringVar(&opts.Resume, "resume", "", "continue a game saved with the 'save' command or on Ctrl+C")
+	fs.StringVar(&opts.ResumeRun, "resume-events", "", "continue the eve, tournament or gauntlet run interrupted while writing this event log (see --events), given the same options again: the games it finished are counted, those in progress are replayed to their last move, and the rest are played")
+	fs.StringVar(&opts.Replay, "replay", "", "step through a saved game move by move, with autoplay")
+	fs.StringVar(&opts.Events, "events", "", "append a JSON Lines log of every game's events to this file (\"-\" for stdout)")
+	fs.StringVar(&opts.CSV, "csv", "", "write a CSV row per game to games.csv in this directory, plus match, tournament and gauntlet summaries")
+	fs.StringVar(&opts.Ratings, "ratings", "", "rate the players of every finished game in this stats store, e.g. "+DEFAULT_STATS_FILE+" (see the leaderboard command; default rates none)")
+	fs.StringVar(&opts.DB, "db", "", "record every game with its moves and per-move statistics in this game database file")
+	fs.StringVar(&opts.Book, "book", "", "EvE match, tournament and gauntlet: start each pair of games from an opening sampled from this game database, as often as its games played it")
+	fs.IntVar(&opts.BookPlies, "book-plies", 4, "longest opening sampled with --book")
+	fs.IntVar(&opts.RandomPlies, "random-plies", 0, "EvE match, tournament and gauntlet: start each pair of games from an opening of this many random moves, one game with each bot moving first; with --book, the random moves continue the book's opening (0 for none)")
+	fs.StringVar(&opts.SearchCache, "search-cache", "", "remember hint, evaluation and engine analyses in this file across runs, and start from them when the same position is analysed again")
+	fs.StringVar(&opts.Archive, "archive", "", "append every finished game to this compact binary archive ("+formats.BINARY_ARCHIVE_EXTENSION+"), which 'export --game' reads")
+	fs.StringVar(&opts.Journal, "journal", defaultJournalDir(), "keep a journal of every game in progress in this directory, to recover games cut short by a crash on the next run (empty disables it)")
+	fs.StringVar(&opts.Output, "output", "text", "output format: text, or json for one JSON result per game with decorations suppressed")
+	fs.StringVar(&opts.Profiles, "profiles", "", "path to a JSON bot profiles file (default "+DEFAULT_PROFILES_FILE+" if present)")
+	opts.Log = addLogFlags(fs)
+	opts.Trace = addTraceFlag(fs)
+	fs.StringVar(&opts.Broadcast, "broadcast", "", "let spectators watch every game of the run over TCP at this address, e.g. \"localhost:7878\" (see the watch command)")
+	fs.StringVar(&opts.BroadcastWS, "broadcast-ws", "", "with --broadcast, also accept spectators over WebSocket at /spectate on this address")
+	addOriginFlag(fs)
+	fs.StringVar(&opts.Metrics, "metrics", "", "serve Prometheus metrics on /metrics at this address while games run, e.g. \"localhost:9090\" (default off)")
+	fs.StringVar(&opts.Plugins, "plugins", "", "bot plugin (.so) file, or directory of them, to load (default "+DEFAULT_PLUGINS_DIR+" if present)")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+	opts.Length, opts.Width, opts.Height = size, size, size
+	var err error
+	opts.Bots = strings.Fields(entrants)
+	if sprt != "" {
+		if opts.SPRT, err = parseSPRT(sprt, sprtAlpha, sprtBeta); err != nil {
+			return nil, err
+		}
+	}
+	if opts.TTPolicy, err = bots.ParseReplacementPolicy(ttPolicy); err != nil {
+		return nil, err
+	}
+	if opts.TTLocking, err = bots.ParseTTLocking(ttLocking); err != nil {
+		return nil, err
+	}
+	if opts.Render.Threats, err = parseThreatMarks(threats); err != nil {
+		return nil, err
+	}
+
+	if configPath != "" {
+		config, err := loadGameConfig(configPath)
+		if err != nil {
+			return nil, err
+		}
+		setFlags := make(map[string]bool)
+		fs.Visit(func(f *flag.Flag) { setFlags[f.Name] = true })
+		config.applyTo(opts, setFlags)
+	}
+
+	if opts.BroadcastWS != "" && opts.Broadcast == "" {
+		return nil, fmt.Errorf("--broadcast-ws needs --broadcast")
+	}
+	if _, err := parseOutputFormat(opts.Output); err != nil {
+		return nil, err
+	}
+	if opts.Games < 1 {
+		return nil, fmt.Errorf("--games must be at least 1")
+	}
+	if opts.SPRT != nil && opts.Games == 1 {
+		return nil, fmt.Errorf("--sprt needs --games, the most games the match may take")
+	}
+	if opts.ResignScore < 0 {
+		return nil, fmt.Errorf("--resign-score must not be negative")
+	}
+	if opts.ResignMoves < 1 {
+		return nil, fmt.Errorf("--resign-moves must be at least 1")
+	}
+	if err := opts.Adjudication.validate(); err != nil {
+		return nil, err
+	}
+	if opts.Adjudication.Moves < 1 {
+		return nil, fmt.Errorf("--adjudicate-moves must be at least 1")
+	}
+	if opts.BookPlies < 1 {
+		return nil, fmt.Errorf("--book-plies must be at least 1")
+	}
+	if opts.RandomPlies < 0 {
+		return nil, fmt.Errorf("--random-plies must not be negative")
+	}
+	if opts.Hash < 1 || opts.Hash > bots.MAX_TT_SIZE {
+		return nil, fmt.Errorf("--hash must be between 1 and %d", bots.MAX_TT_SIZE)
+	}
+	if opts.Memory < 0 {
+		return nil, fmt.Errorf("--search-memory must not be negative")
+	}
+	if opts.Playouts < 0 || opts.Playouts > MAX_PLAYOUTS {
+		return nil, fmt.Errorf("--playouts must be between 0 and %d", MAX_PLAYOUTS)
+	}
+	if mode := strings.ToLower(opts.Mode); opts.ResumeRun != "" && mode != "eve" && mode != "tournament" && mode != "gauntlet" {
+		return nil, fmt.Errorf("--resume-events continues eve, tournament and gauntlet runs only")
+	}
+	if opts.Workers < 0 {
+		return nil, fmt.Errorf("--workers must not be negative")
+	}
+	if opts.Workers == 0 {
+		opts.Workers = runtime.NumCPU()
+	}
+	if opts.Length < 0 || opts.Width < 0 || opts.Height < 0 || opts.Win < 0 {
+		return nil, fmt.Errorf("board dimensions and win length must be positive")
+	}
+
+	return opts, nil
+}
+
+// interactive reports whether the run needs a human at the keyboard
+func (opts *CLIOptions) interactive() bool {
+	if opts.Resume != "" || opts.Replay != "" {
+		return true
+	}
+	switch strings.ToLower(opts.Mode) {
+	case "evestream":
+		return false
+	case "eve":
+		return !opts.Auto && !opts.Quiet && opts.Games == 1
+	case "tournament", "gauntlet", "engine":
+		return false
+	}
+	return true
+}
+
+// newBotFromSpec creates a bot from a spec string (see bots.ParseBotSpec)
+// The name is either a registered bot key or a bot profile; for a profile, any parameters
+// given override the profile's own. Parameters the bot does not accept are rejected
+func newBotFromSpec(spec string, symbol byte, defaultName string) (bots.BotInterface, error) {
+	name, params, err := bots.ParseBotSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	if profile, isProfile := lookupBotProfile(name); isProfile {
+		switch profile.Config.Type {
+		case EXTERNAL_BOT_TYPE:
+			return newExternalBotFromProfile(profile, symbol, defaultName, params)
+		case REMOTE_BOT_TYPE:
+			return newRemoteBotFromProfile(profile, symbol, defaultName, params)
+		case CLUSTER_BOT_TYPE:
+			return newClusterBotFromProfile(profile, symbol, defaultName, params)
+		}
+		overrides := params
+		name, params, err = bots.ParseBotSpec(profile.Config.Spec())
+		if err != nil {
+			return nil, fmt.Errorf("profile %q: %v", profile.Name, err)
+		}
+		for key, value := range overrides {
+			params[key] = value
+		}
+	}
+
+	registration, exists := bots.LookupBot(name)
+	if !exists {
+		return nil, fmt.Errorf("unknown bot %q (available: %s)", name, strings.Join(bots.RegisteredBotKeys(), ", "))
+	}
+	return registration.Create(symbol, defaultName, params)
+}
+
+// specBotFactory returns a constructor for the bot described by spec, for either side
+// The spec is checked by building the bot once, so the constructor itself cannot fail
+func specBotFactory(spec, name string) (func(symbol byte) bots.BotInterface, error) {
+	bot, err := newBotFromSpec(spec, 'x', name)
+	if err != nil {
+		return nil, err
+	}
+	bot.Close()
+
+	return func(symbol byte) bots.BotInterface {
+		bot, _ := newBotFromSpec(spec, symbol, name)
+		return bot
+	}, nil
+}
+
+// botEntrants builds the entrants of a tournament or gauntlet panel from their specs
+// Entrants without a name are named after their spec, and repeated names are numbered
+func botEntrants(specs, names []string) ([]TournamentEntrant, error) {
+	entrantNames := make([]string, len(specs))
+	for i, spec := range specs {
+		entrantNames[i] = spec
+		if i < len(names) && names[i] != "" {
+			entrantNames[i] = names[i]
+		}
+	}
+	entrantNames = uniqueNames(entrantNames)
+
+	entrants := make([]TournamentEntrant, len(specs))
+	for i, spec := range specs {
+		newBot, err := specBotFactory(spec, entrantNames[i])
+		if err != nil {
+			return nil, err
+		}
+		entrants[i] = TournamentEntrant{Name: entrantNames[i], Spec: spec, newBot: newBot}
+	}
+	return entrants, nil
+}
+
+// newBoardFromOptions creates the board described by the options, falling back to a defaultSize cube
+func newBoardFromOptions(opts *CLIOptions, defaultSize int) (*engine.Board, error) {
+	dims := []int{opts.Length, opts.Width, opts.Height}
+	for i := range dims {
+		if dims[i] == 0 {
+			dims[i] = defaultSize
+		}
+	}
+
+	options := []engine.Option{engine.WithDims(dims[0], dims[1], dims[2])}
+	if opts.Win != 0 {
+		options = append(options, engine.WithWinLength(opts.Win))
+	}
+	return engine.New(options...)
+}
+
+// runWithOptions runs the game mode selected on the command line without showing any menus
+func runWithOptions(opts *CLIOptions) error {
+	mode := strings.ToLower(opts.Mode)
+	defaultSize := 3
+	if mode == "pvestream" {
+		defaultSize = 4
+	}
+	board, err := newBoardFromOptions(opts, defaultSize)
+	if err != nil {
+		return err
+	}
+	switch mode {
+	case "pvp":
+		playPvP(board, defaultPlayerNames())
+
+	case "pve":
+		spec := opts.Bot2
+		if spec == "" {
+			spec = "random"
+		}
+		bot, err := newBotFromSpec(spec, 'o', botDisplayName(opts.Bot2Name, "Bot"))
+		if err != nil {
+			return err
+		}
+		if player, ok := bot.(playerBot); ok {
+			player.SetPlayer(opts.Player)
+		}
+		playPvE(board, bot, formats.PvESettings{Training: opts.Training})
+
+	case "eve":
+		if opts.Bot1 == "" || opts.Bot2 == "" {
+			return fmt.Errorf("eve mode requires both --bot1 and --bot2")
+		}
+		settings := formats.EvESettings{
+			AutoPlay:        opts.Auto,
+			MoveTimeLimit:   opts.MoveTimeLimit,
+			ShowSearchStats: opts.ShowSearchStats,
+			Quiet:           opts.Quiet,
+			ResignScore:     opts.ResignScore,
+			ResignMoves:     opts.ResignMoves,
+		}
+		opts.Adjudication.applyTo(&settings)
+		name1, name2 := botDisplayName(opts.Bot1Name, "Bot1"), botDisplayName(opts.Bot2Name, "Bot2")
+		if err := beginRun(mode, board, []string{name1, name2}, []string{opts.Bot1, opts.Bot2}); err != nil {
+			return err
+		}
+		if opts.Games > 1 {
+			newBot1, err := specBotFactory(opts.Bot1, name1)
+			if err != nil {
+				return err
+			}
+			newBot2, err := specBotFactory(opts.Bot2, name2)
+			if err != nil {
+				return err
+			}
+			playMatch(board, newBot1, newBot2, opts.Games, opts.Workers, settings, opts.SPRT)
+			return nil
+		}
+
+		if _, over := resumedRun.finished(1); over {
+			return fmt.Errorf("the resumed game is already over")
+		}
+		board = resumedRun.board(1, board)
+		bot1, err := newBotFromSpec(opts.Bot1, 'x', name1)
+		if err != nil {
+			return err
+		}
+		bot2, err := newBotFromSpec(opts.Bot2, 'o', name2)
+		if err != nil {
+			return err
+		}
+		playEvE(board, bot1, bot2, settings)
+
+	case "tournament":
+		if len(opts.Bots) < 2 {
+			return fmt.Errorf("tournament mode requires at least two bots in --bots")
+		}
+		entrants, err := botEntrants(opts.Bots, opts.BotNames)
+		if err != nil {
+			return err
+		}
+		settings := formats.EvESettings{
+			MoveTimeLimit: opts.MoveTimeLimit,
+			ResignScore:   opts.ResignScore,
+			ResignMoves:   opts.ResignMoves,
+		}
+		opts.Adjudication.applyTo(&settings)
+		if err := beginRun(mode, board, entrantNames(entrants), entrantSpecs(entrants)); err != nil {
+			return err
+		}
+		playTournament(board, entrants, opts.Games, opts.Workers, settings)
+
+	case "gauntlet":
+		if opts.Bot1 == "" || len(opts.Bots) == 0 {
+			return fmt.Errorf("gauntlet mode requires a candidate in --bot1 and reference bots in --bots")
+		}
+		candidateName := botDisplayName(opts.Bot1Name, opts.Bot1)
+		newCandidate, err := specBotFactory(opts.Bot1, candidateName)
+		if err != nil {
+			return err
+		}
+		panel, err := botEntrants(opts.Bots, opts.BotNames)
+		if err != nil {
+			return err
+		}
+		candidate := TournamentEntrant{Name: candidateName, Spec: opts.Bot1, newBot: newCandidate}
+		settings := formats.EvESettings{
+			MoveTimeLimit: opts.MoveTimeLimit,
+			ResignScore:   opts.ResignScore,
+			ResignMoves:   opts.ResignMoves,
+		}
+		opts.Adjudication.applyTo(&settings)
+		field := append([]TournamentEntrant{candidate}, panel...)
+		if err := beginRun(mode, board, entrantNames(field), entrantSpecs(field)); err != nil {
+			return err
+		}
+		playGauntlet(board, candidate, panel, opts.Games, opts.Workers, settings)
+
+	case "pvestream":
+		playPvEStream(board, []int{3, 4, 5, 6, 7})
+
+	case "mirror":
+		playMirror(board, defaultPlayerNames())
+
+	case "evestream":
+		botX := bots.NewPersistentMinimaxBot('x', "PersistentBot-X", 4, 10)
+		botO := bots.NewPersistentMinimaxBot('o', "PersistentBot-O", 4, 10)
+		playEvEStream(board, botX, botO)
+
+	case "engine":
+		return runEngine(opts.Bot1, board)
+
+	default:
+		return fmt.Errorf("unknown mode %q (expected pvp, pve, eve, tournament, gauntlet, pvestream, evestream, mirror or engine)", opts.Mode)
+	}
+
+	return nil
+}
+
+// entrantNames returns the names of entrants in order
+func entrantNames(entrants []TournamentEntrant) []string {
+	names := make([]string, len(entrants))
+	for i, entrant := range entrants {
+		names[i] = entrant.Name
+	}
+	return names
+}
+
+// entrantSpecs returns the bot specs of entrants in order
+func entrantSpecs(entrants []TournamentEntrant) []string {
+	specs := make([]string, len(entrants))
+	for i, entrant := range entrants {
+		specs[i] = entrant.Spec
+	}
+	return specs
+}
+
+// botDisplayName returns name, or fallback if name is empty
+func botDisplayName(name, fallback string) string {
+	if name == "" {
+		return fallback
+	}
+	return name
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"tic-tac-toe-3d-bots/bots"
+	"tic-tac-toe-3d-bots/engine"
+	"tic-tac-toe-3d-bots/formats"
+)
+
+// CLUSTER_BOT_TYPE is the profile type of bots whose search is split across the machines of a cluster
+const CLUSTER_BOT_TYPE = "cluster"
+
+// clusterBotDefaults are the parameters a cluster bot accepts and their defaults:
+// depth is the depth of the search, and local the number of root moves searched at once on this machine besides the workers'
+var clusterBotDefaults = map[string]int{"depth": 6, "local": 0}
+
+// ClusterBot splits its alpha-beta search at the root across worker machines running the grpc command, so that deep
+// searches of large boards can use a small cluster. Cluster bots are declared as bot profiles, whose command lists
+// the workers:
+//
+//	{"Cluster": {"type": "cluster", "command": ["grpc://box1:50051", "grpc://box2:50051"], "params": {"depth": 9, "local": 2}}}
+//
+// Each worker searches one root move at a time (the SearchSubtree call), bounded by the best score found so far, so
+// later moves are cut off as they would be in a sequential search. The first move is searched alone, to give the others
+// a bound; a move whose worker fails is searched by another, and a worker that fails takes no more moves until the next
+// search. Whatever is left when every worker has failed is searched on this machine
+type ClusterBot struct {
+	bots.BaseBot
+	workers []string // addresses of the workers, as host:port
+	depth   int
+	local   int
+	client  *http.Client
+}
+
+// newClusterBotFromProfile creates the bot of a cluster profile; params override the profile's own
+func newClusterBotFromProfile(profile *BotProfile, symbol byte, name string, params map[string]int) (bots.BotInterface, error) {
+	resolved := make(map[string]int, len(clusterBotDefaults))
+	for key, value := range clusterBotDefaults {
+		resolved[key] = value
+	}
+	for _, overrides := range []map[string]int{profile.Config.Params, params} {
+		for key, value := range overrides {
+			if _, known := clusterBotDefaults[key]; !known {
+				return nil, fmt.Errorf("unknown parameter %q for bot %q", key, profile.Name)
+			}
+			resolved[key] = value
+		}
+	}
+	if resolved["depth"] <= 0 || resolved["local"] < 0 {
+		return nil, fmt.Errorf("bot %q: depth must be positive, and local not negative", profile.Name)
+	}
+	workers, err := parseClusterCommand(profile.Config.Command)
+	if err != nil {
+		return nil, fmt.Errorf("bot %q: %v", profile.Name, err)
+	}
+	if name == "" {
+		name = profile.displayName()
+	}
+
+	bot := &ClusterBot{
+		BaseBot: bots.NewBaseBot(symbol, name),
+		workers: workers,
+		depth:   resolved["depth"],
+		local:   resolved["local"],
+		client:  newGRPCClient(),
+	}
+	// Recreated through the profile, e.g. when a saved game is resumed
+	bot.SetConfig(&bots.BotConfig{Type: profile.Name, Name: name, Params: resolved})
+	return bot, nil
+}
+
+// parseClusterCommand reads the workers' addresses, grpc://host:port, from a cluster profile's command
+func parseClusterCommand(command []string) ([]string, error) {
+	if len(command) == 0 {
+		return nil, fmt.Errorf("the command of a cluster bot lists its workers, e.g. grpc://box1:50051")
+	}
+	workers := make([]string, len(command))
+	for i, address := range command {
+		worker, err := url.Parse(address)
+		if err != nil {
+			return nil, err
+		}
+		if worker.Scheme != "grpc" || worker.Host == "" {
+			return nil, fmt.Errorf("unsupported worker address %q (expected grpc://host:port)", address)
+		}
+		workers[i] = worker.Host
+	}
+	return workers, nil
+}
+
+// searchSubtree plays move for the side to move on board, a copy the caller owns, and searches the replies depth plies
+// deep; with bounded, replies that cannot beat bound (the root's best score so far) are cut off
+// Returns the score from x's perspective and the line starting with move
+func searchSubtree(ctx context.Context, board *engine.Board, move string, depth int, bound int64, bounded bool) (int, []string, error) {
+	symbol := board.NextPlayer()
+	if board.Move(move, symbol)[0] == -1 {
+		return 0, nil, fmt.Errorf("illegal move %q", move)
+	}
+	defer board.UnMove(move)
+
+	replyMaximizing := symbol == 'o'
+	threshold := engine.MIN_INT // No cut-off: a minimizing reply never reaches MIN_INT
+	if replyMaximizing {
+		threshold = engine.MAX_INT
+	}
+	if bounded {
+		threshold = int(bound)
+	}
+	score, line := bots.AlphaBetaMinimax(board, depth, replyMaximizing, threshold, ctx)
+	if bots.SearchCancelled(ctx) {
+		return 0, nil, ctx.Err()
+	}
+	return score, append([]string{move}, line...), nil
+}
+
+// clusterSearch is one distributed search, shared by the goroutines handing its root moves to the workers
+type clusterSearch struct {
+	maximizing bool
+	mutex      sync.Mutex
+	changed    *sync.Cond // signalled when a move is done, returned, or the search is cancelled
+	pending    []string   // root moves not searched yet
+	searching  int        // root moves being searched
+	bounded    bool       // whether a root move has been scored
+	best       int
+	line       []string
+	jobs       map[string]int // root moves searched by each worker, for the log
+}
+
+// next hands out the next root move with the bound to search it with; ok is false once there are none left
+// Until the first move is scored the others wait, as they could not be cut off
+func (search *clusterSearch) next(ctx context.Context) (move string, bound int, bounded, ok bool) {
+	search.mutex.Lock()
+	defer search.mutex.Unlock()
+	for !search.bounded && search.searching > 0 && len(search.pending) > 0 && ctx.Err() == nil {
+		search.changed.Wait()
+	}
+	if len(search.pending) == 0 || ctx.Err() != nil {
+		return "", 0, false, false
+	}
+	move, search.pending = search.pending[0], search.pending[1:]
+	search.searching++
+	return move, search.best, search.bounded, true
+}
+
+// finish records the result of a root move searched by worker, or returns the move to be searched again if it failed
+func (search *clusterSearch) finish(worker, move string, score int, line []string, err error) {
+	search.mutex.Lock()
+	defer search.mutex.Unlock()
+	search.searching--
+	if err != nil {
+		search.pending = append(search.pending, move)
+	} else {
+		search.jobs[worker]++
+		if !search.bounded || (search.maximizing && score > search.best) || (!search.maximizing && score < search.best) {
+			search.bounded, search.best, search.line = true, score, line
+		}
+	}
+	search.changed.Broadcast()
+}
+
+// MakeMove searches the position across the workers and plays the best move (implements BotInterface)
+func (bot *ClusterBot) MakeMove(ctx context.Context, board *engine.Board) (bots.Move, error) {
+	moves := board.GetValidMoves()
+	if len(moves) == 0 {
+		return bots.Move{}, bots.ErrNoValidMoves
+	}
+	search := &clusterSearch{maximizing: bot.Symbol() == 'x', pending: moves, jobs: make(map[string]int)}
+	search.changed = sync.NewCond(&search.mutex)
+	stopWaiting := context.AfterFunc(ctx, func() {
+		search.mutex.Lock()
+		search.changed.Broadcast()
+		search.mutex.Unlock()
+	})
+	defer stopWaiting()
+
+	root := formats.ProtoBoard{Length: board.Length, Width: board.Width, Height: board.Height, Win: board.WinLength,
+		Snapshot: board.Snapshot()}
+	start := time.Now()
+
+	// Each worker, and each local searcher, takes root moves until there are none left
+	var searchers sync.WaitGroup
+	searchWith := func(worker string, searchMove func(move string, bound int, bounded bool) (int, []string, error)) {
+		defer searchers.Done()
+		for {
+			move, bound, bounded, ok := search.next(ctx)
+			if !ok {
+				return
+			}
+			score, line, err := searchMove(move, bound, bounded)
+			search.finish(worker, move, score, line, err)
+			if err != nil {
+				if ctx.Err() == nil {
+					serverLog.Warn("a cluster worker failed; its moves go to the others", "worker", worker, "move", move, "error", err)
+				}
+				return
+			}
+		}
+	}
+	for _, worker := range bot.workers {
+		searchers.Add(1)
+		go searchWith(worker, func(move string, bound int, bounded bool) (int, []string, error) {
+			request := rpcSubtreeRequest{Board: root, Move: move, Depth: bot.depth - 1, Bound: int64(bound), Bounded: bounded}
+			reply, err := grpcCall(ctx, bot.client, worker, "/tictactoe3d.Engine/SearchSubtree", request.marshal())
+			if err != nil {
+				return 0, nil, err
+			}
+			result := rpcSubtreeResult{}
+			if err := result.unmarshal(reply); err != nil {
+				return 0, nil, err
+			}
+			if len(result.Line) == 0 || result.Line[0] != move {
+				return 0, nil, fmt.Errorf("the worker answered for %q instead of %q", result.Move, move)
+			}
+			return int(result.Score), result.Line, nil
+		})
+	}
+	searchLocally := func(move string, bound int, bounded bool) (int, []string, error) {
+		return searchSubtree(ctx, engine.CopyBoard(board), move, bot.depth-1, int64(bound), bounded)
+	}
+	for i := range bot.local {
+		searchers.Add(1)
+		go searchWith(fmt.Sprintf("local %d", i+1), searchLocally)
+	}
+	searchers.Wait()
+
+	// The moves of failed workers, if no one else could take them
+	if !search.bounded || len(search.pending) > 0 {
+		searchers.Add(1)
+		searchWith("local", searchLocally)
+	}
+	if err := ctx.Err(); err != nil {
+		return bots.Move{}, err
+	}
+
+	serverLog.Info("distributed search finished", "depth", bot.depth, "score", search.best, "line", strings.Join(search.line, " "),
+		"jobs", search.jobs, "elapsed", time.Since(start))
+	return bots.PlayChosenMove(ctx, board, bot.Symbol(), search.line[0])
+}
+
+// OpponentMove does nothing, as every search starts from scratch (implements BotInterface)
+func (bot *ClusterBot) OpponentMove(move string) {}
+
+// Close releases the connections to the workers (implements BotInterface)
+func (bot *ClusterBot) Close() {
+	bot.client.CloseIdleConnections()
+}
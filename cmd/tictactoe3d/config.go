@@ -0,0 +1,228 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"tic-tac-toe-3d-bots/bots"
+	"tic-tac-toe-3d-bots/engine"
+	"tic-tac-toe-3d-bots/formats"
+)
+
+// GameConfig is the on-disk JSON configuration loaded with --config
+// Every field is optional; command-line flags override values from the file
+type GameConfig struct {
+	Mode         string              `json:"mode"`
+	Board        engine.BoardConfig  `json:"board"`
+	TimeControl  TimeControlConfig   `json:"time_control"`
+	Adjudication *AdjudicationConfig `json:"adjudication"` // EvE: settle games on the bots' agreeing evaluations
+	Bot1         *bots.BotConfig     `json:"bot1"`
+	Bot2         *bots.BotConfig     `json:"bot2"`
+	Output       OutputConfig        `json:"output"`
+	Profiles     string              `json:"profiles"` // bot profiles file to load
+	Plugins      string              `json:"plugins"`  // bot plugin file or directory to load
+	Lang         string              `json:"lang"`     // message language, e.g. "id"
+	Games        int                 `json:"games"`    // EvE: games in the match, sides swapping after each
+	Workers      int                 `json:"workers"`  // EvE: match games played at once (0 uses every CPU core)
+	Bots         []*bots.BotConfig   `json:"bots"`     // tournament entrants, or the reference bots of a gauntlet
+	SPRT         *SPRT               `json:"sprt"`     // EvE: stop a match early once this test decides
+	Seed         int64               `json:"seed"`     // seed of every random choice, as --seed
+}
+
+// TimeControlConfig describes per-move time limits for bots, and when they give up lost games
+type TimeControlConfig struct {
+	MoveTimeLimit string `json:"move_time_limit"` // Go duration string, e.g. "1.5s"
+	ResignScore   int    `json:"resign_score"`    // EvE: bots this far below even resign, as --resign-score
+	ResignMoves   int    `json:"resign_moves"`    // EvE: moves in a row before resigning, as --resign-moves
+}
+
+// AdjudicationConfig describes when the games of EvE matches, tournaments and gauntlets are settled on the bots'
+// evaluations instead of played out; zero thresholds adjudicate nothing
+type AdjudicationConfig struct {
+	Win       int `json:"win"`        // adjudicate a win once both bots score the position this far in one side's favour
+	Draw      int `json:"draw"`       // adjudicate a draw once both score it within this of even...
+	DrawAfter int `json:"draw_after"` // ...from this move on
+	Moves     int `json:"moves"`      // moves in a row both bots must agree for (0 uses DEFAULT_ADJUDICATE_MOVES)
+}
+
+// validate checks that no threshold is negative
+func (config *AdjudicationConfig) validate() error {
+	if config.Win < 0 || config.Draw < 0 || config.DrawAfter < 0 || config.Moves < 0 {
+		return fmt.Errorf("adjudication thresholds must not be negative")
+	}
+	return nil
+}
+
+// applyTo sets the thresholds in the settings of an EvE game
+func (config *AdjudicationConfig) applyTo(settings *formats.EvESettings) {
+	settings.AdjudicateWin = config.Win
+	settings.AdjudicateDraw = config.Draw
+	settings.DrawAfter = config.DrawAfter
+	settings.AdjudicateMoves = max(config.Moves, 1)
+}
+
+// OutputConfig describes how games are displayed
+type OutputConfig struct {
+	Auto             bool   `json:"auto"`               // play bot moves without pausing
+	Quiet            bool   `json:"quiet"`              // EvE: print only the result and final statistics
+	ShowSearchStats  bool   `json:"show_search_stats"`  // print per-worker search statistics after bot moves
+	HideWinHighlight bool   `json:"hide_win_highlight"` // don't capitalize winning lines
+	Threats          string `json:"threats"`            // whose threats to mark: both, x, o or none
+	Accessible       bool   `json:"accessible"`         // screen-reader friendly board output
+	Events           string `json:"events"`             // JSON Lines event log file, "-" for stdout
+	CSV              string `json:"csv"`                // directory to export games and summaries to as CSV
+	DB               string `json:"db"`                 // game database file recording every game
+	Format           string `json:"format"`             // text or json
+}
+
+// loadGameConfig reads and validates a JSON configuration file
+func loadGameConfig(path string) (*GameConfig, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	config := &GameConfig{}
+	decoder := json.NewDecoder(file)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(config); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	for _, bot := range append([]*bots.BotConfig{config.Bot1, config.Bot2}, config.Bots...) {
+		if bot != nil && len(bot.Command) > 0 {
+			return nil, fmt.Errorf("%s: external engines must be declared as bot profiles", path)
+		}
+	}
+
+	if _, err := parseThreatMarks(config.Output.Threats); err != nil {
+		return nil, fmt.Errorf("%s: output.threats: %v", path, err)
+	}
+
+	if config.SPRT != nil {
+		if err := config.SPRT.validate(); err != nil {
+			return nil, fmt.Errorf("%s: sprt: %v", path, err)
+		}
+	}
+
+	if config.TimeControl.MoveTimeLimit != "" {
+		if _, err := time.ParseDuration(config.TimeControl.MoveTimeLimit); err != nil {
+			return nil, fmt.Errorf("%s: time_control.move_time_limit: %v", path, err)
+		}
+	}
+	if config.Adjudication != nil {
+		if err := config.Adjudication.validate(); err != nil {
+			return nil, fmt.Errorf("%s: adjudication: %v", path, err)
+		}
+	}
+	if config.TimeControl.ResignScore < 0 || config.TimeControl.ResignMoves < 0 {
+		return nil, fmt.Errorf("%s: time_control: resign_score and resign_moves must not be negative", path)
+	}
+
+	return config, nil
+}
+
+// applyTo fills in every option that was not set explicitly on the command line
+func (config *GameConfig) applyTo(opts *CLIOptions, setFlags map[string]bool) {
+	if !setFlags["mode"] {
+		opts.Mode = config.Mode
+	}
+	if !setFlags["size"] {
+		opts.Length = config.Board.Length
+		opts.Width = config.Board.Width
+		opts.Height = config.Board.Height
+	}
+	if !setFlags["win"] {
+		opts.Win = config.Board.Win
+	}
+	if !setFlags["bot1"] && config.Bot1 != nil {
+		opts.Bot1 = config.Bot1.Spec()
+		opts.Bot1Name = config.Bot1.Name
+	}
+	if !setFlags["bot2"] && config.Bot2 != nil {
+		opts.Bot2 = config.Bot2.Spec()
+		opts.Bot2Name = config.Bot2.Name
+	}
+	if !setFlags["bots"] && len(config.Bots) > 0 {
+		opts.Bots, opts.BotNames = nil, nil
+		for _, bot := range config.Bots {
+			opts.Bots = append(opts.Bots, bot.Spec())
+			opts.BotNames = append(opts.BotNames, bot.Name)
+		}
+	}
+	if !setFlags["profiles"] {
+		opts.Profiles = config.Profiles
+	}
+	if !setFlags["plugins"] {
+		opts.Plugins = config.Plugins
+	}
+	if !setFlags["lang"] {
+		opts.Lang = config.Lang
+	}
+	if !setFlags["auto"] {
+		opts.Auto = config.Output.Auto
+	}
+	if !setFlags["games"] && config.Games != 0 {
+		opts.Games = config.Games
+	}
+	if !setFlags["sprt"] && config.SPRT != nil {
+		opts.SPRT = config.SPRT
+	}
+	if !setFlags["seed"] && config.Seed != 0 {
+		opts.Seed = config.Seed
+	}
+	if !setFlags["workers"] && config.Workers != 0 {
+		opts.Workers = config.Workers
+	}
+	if !setFlags["quiet"] {
+		opts.Quiet = config.Output.Quiet
+	}
+	opts.ShowSearchStats = config.Output.ShowSearchStats
+	if !setFlags["no-highlight"] {
+		opts.Render.HideWinHighlight = config.Output.HideWinHighlight
+	}
+	if !setFlags["accessible"] {
+		opts.Render.Accessible = config.Output.Accessible
+	}
+	if !setFlags["output"] && config.Output.Format != "" {
+		opts.Output = config.Output.Format
+	}
+	if !setFlags["events"] {
+		opts.Events = config.Output.Events
+	}
+	if !setFlags["csv"] {
+		opts.CSV = config.Output.CSV
+	}
+	if !setFlags["db"] {
+		opts.DB = config.Output.DB
+	}
+	if !setFlags["threats"] {
+		opts.Render.Threats, _ = parseThreatMarks(config.Output.Threats) // validated on load
+	}
+	if config.TimeControl.MoveTimeLimit != "" {
+		opts.MoveTimeLimit, _ = time.ParseDuration(config.TimeControl.MoveTimeLimit) // validated on load
+	}
+	if adjudicate := config.Adjudication; adjudicate != nil {
+		if !setFlags["adjudicate-win"] {
+			opts.Adjudication.Win = adjudicate.Win
+		}
+		if !setFlags["adjudicate-draw"] {
+			opts.Adjudication.Draw = adjudicate.Draw
+		}
+		if !setFlags["draw-after"] {
+			opts.Adjudication.DrawAfter = adjudicate.DrawAfter
+		}
+		if !setFlags["adjudicate-moves"] && adjudicate.Moves != 0 {
+			opts.Adjudication.Moves = adjudicate.Moves
+		}
+	}
+	if !setFlags["resign-score"] && config.TimeControl.ResignScore != 0 {
+		opts.ResignScore = config.TimeControl.ResignScore
+	}
+	if !setFlags["resign-moves"] && config.TimeControl.ResignMoves != 0 {
+		opts.ResignMoves = config.TimeControl.ResignMoves
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"tic-tac-toe-3d-bots/engine"
+	"tic-tac-toe-3d-bots/formats"
+)
+
+// runExport implements the export command: it converts a saved game (see --save) to another format
+func runExport(args []string, output io.Writer) error {
+	var format, outPath string
+	var game int
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.StringVar(&format, "format", formats.FormatJSON, "format to write: json, csv or snapshot")
+	fs.StringVar(&outPath, "out", "-", "file to write (\"-\" for standard output)")
+	fs.IntVar(&game, "game", 1, "game to export from a binary archive ("+formats.BINARY_ARCHIVE_EXTENSION+"), counting from 1")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("the export command needs exactly one saved game")
+	}
+
+	if game < 1 {
+		return fmt.Errorf("--game must be at least 1")
+	}
+
+	var record *formats.GameRecord
+	var err error
+	if strings.HasSuffix(fs.Arg(0), formats.BINARY_ARCHIVE_EXTENSION) {
+		var archived *formats.ArchivedGame
+		if archived, err = loadArchivedGame(fs.Arg(0), game); err == nil {
+			record = &archived.Record
+		}
+	} else {
+		record, err = formats.LoadGameRecord(fs.Arg(0))
+	}
+	if err != nil {
+		return err
+	}
+	var converted bytes.Buffer
+	if err := formats.ExportRecord(record, strings.ToLower(format), &converted); err != nil {
+		return fmt.Errorf("%s: %v", fs.Arg(0), err)
+	}
+	return writeConverted(outPath, converted.Bytes())
+}
+
+// runImport implements the import command: it converts a game in another format to a saved game,
+// which --replay and --resume read
+func runImport(args []string, output io.Writer) error {
+	var format, outPath, boardFilter string
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.StringVar(&format, "format", formats.FormatJSON, "format to read: json, csv or snapshot")
+	fs.StringVar(&outPath, "out", "-", "saved game to write (\"-\" for standard output)")
+	fs.StringVar(&boardFilter, "board", "3x3x3", "board of a CSV move list, as LxWxH or LxWxH/win")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("the import command needs exactly one file to read (\"-\" for standard input)")
+	}
+
+	board, err := engine.ParseBoardConfig(boardFilter)
+	if err != nil {
+		return err
+	}
+	if board.Win == 0 {
+		board.Win = min(board.Length, board.Width, board.Height)
+	}
+
+	var data []byte
+	if fs.Arg(0) == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(fs.Arg(0))
+	}
+	if err != nil {
+		return err
+	}
+	record, err := formats.ImportRecord(data, strings.ToLower(format), board)
+	if err != nil {
+		return fmt.Errorf("%s: %v", fs.Arg(0), err)
+	}
+	if record.Players == ([2]string{}) {
+		record.Players = defaultPlayerNames()
+	}
+
+	if outPath != "-" {
+		return record.Save(outPath)
+	}
+	encoded, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeConverted(outPath, append(encoded, '\n'))
+}
+
+// writeConverted writes data to path, or to standard output for "-"
+func writeConverted(path string, data []byte) error {
+	if path == "-" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
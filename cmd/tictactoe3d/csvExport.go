@@ -0,0 +1,185 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"tic-tac-toe-3d-bots/formats"
+)
+
+// CSVExport writes a row per game to games.csv in its directory as games finish, and summary tables next to it
+type CSVExport struct {
+	dir   string
+	mutex sync.Mutex
+	file  *os.File
+	games *csv.Writer
+}
+
+// csvExport receives every game and summary when set with --csv; nil disables the export
+var csvExport *CSVExport
+
+// csvGameHeader names the columns of games.csv
+var csvGameHeader = []string{
+	"finished", "mode", "length", "width", "height", "win",
+	"player_x", "player_o", "bot_x", "bot_o", "winner", "reason", "plies",
+	"x_total_ms", "o_total_ms", "x_average_ms", "o_average_ms", "moves", "seed",
+}
+
+// openCSVExport creates dir if needed and starts a new games.csv in it
+func openCSVExport(dir string) (*CSVExport, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	file, err := os.Create(filepath.Join(dir, "games.csv"))
+	if err != nil {
+		return nil, err
+	}
+
+	export := &CSVExport{dir: dir, file: file, games: csv.NewWriter(file)}
+	export.games.Write(csvGameHeader)
+	export.games.Flush()
+	return export, export.games.Error()
+}
+
+// WriteGame adds a finished game to games.csv; a nil export discards it
+func (export *CSVExport) WriteGame(result formats.GameResult) {
+	if export == nil {
+		return
+	}
+
+	botSpecs := [2]string{}
+	for i, bot := range result.Bots {
+		if bot != nil {
+			botSpecs[i] = bot.Spec()
+		}
+	}
+	moves := make([]string, len(result.Moves))
+	for i, move := range result.Moves {
+		moves[i] = move.Move
+	}
+
+	board := result.Board
+	row := []string{
+		time.Now().Format(time.RFC3339), result.Mode,
+		strconv.Itoa(board.Length), strconv.Itoa(board.Width), strconv.Itoa(board.Height), strconv.Itoa(board.Win),
+		result.Players[0], result.Players[1], botSpecs[0], botSpecs[1], result.Winner, result.Reason, strconv.Itoa(len(result.Moves)),
+		csvFloat(result.Stats[0].TotalMS), csvFloat(result.Stats[1].TotalMS),
+		csvFloat(result.Stats[0].AverageMS), csvFloat(result.Stats[1].AverageMS),
+		strings.Join(moves, " "), strconv.FormatInt(result.Seed, 10),
+	}
+
+	export.mutex.Lock()
+	defer export.mutex.Unlock()
+	export.games.Write(row)
+	export.games.Flush() // Keep the file complete even if the run is interrupted
+	if err := export.games.Error(); err != nil {
+		fmt.Fprintln(os.Stderr, msg("csv.error"), err)
+	}
+}
+
+// WriteTable writes a summary table to name.csv in the export directory, replacing any earlier one
+func (export *CSVExport) WriteTable(name string, header []string, rows [][]string) {
+	if export == nil {
+		return
+	}
+
+	file, err := os.Create(filepath.Join(export.dir, name+".csv"))
+	if err == nil {
+		writer := csv.NewWriter(file)
+		writer.Write(header)
+		writer.WriteAll(rows) // Flushes
+		err = writer.Error()
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, msg("csv.error"), err)
+	}
+}
+
+// WriteMatch writes the summary of a match to match.csv
+func (export *CSVExport) WriteMatch(stats MatchStats) {
+	export.WriteTable("match",
+		[]string{"bot_a", "bot_b", "games", "wins", "draws", "losses", "score", "margin", "average_plies", "a_average_ms", "b_average_ms", "llr", "sprt"},
+		[][]string{matchRow(stats.Names[0], stats.Names[1], stats)})
+}
+
+// WriteTournament writes the standings of a tournament to tournament.csv and its crosstable to crosstable.csv
+func (export *CSVExport) WriteTournament(result TournamentResult) {
+	rows := make([][]string, len(result.Standings))
+	for rank, standing := range result.Standings {
+		rating, rd := "", ""
+		if standing.Rating != nil {
+			rating, rd = csvFloat(standing.Rating.Rating), csvFloat(standing.Rating.RD)
+		}
+		rows[rank] = []string{strconv.Itoa(rank + 1), standing.Name, csvFloat(standing.Points),
+			strconv.Itoa(standing.Games), strconv.Itoa(standing.Wins), strconv.Itoa(standing.Draws), strconv.Itoa(standing.Losses), rating, rd}
+	}
+	export.WriteTable("tournament", []string{"rank", "name", "points", "games", "wins", "draws", "losses", "rating", "rd"}, rows)
+
+	header := []string{"name"}
+	crosstable := make([][]string, len(result.Entrants))
+	for i, entrant := range result.Entrants {
+		header = append(header, entrant.Name)
+		crosstable[i] = []string{entrant.Name}
+		for j, points := range result.Crosstable[i] {
+			cell := ""
+			if i != j {
+				cell = csvFloat(points)
+			}
+			crosstable[i] = append(crosstable[i], cell)
+		}
+	}
+	export.WriteTable("crosstable", header, crosstable)
+}
+
+// WriteGauntlet writes the candidate's result against each reference bot, and overall, to gauntlet.csv
+func (export *CSVExport) WriteGauntlet(result GauntletResult) {
+	rows := make([][]string, 0, len(result.Opponents)+1)
+	for _, stats := range result.Opponents {
+		rows = append(rows, matchRow(result.Candidate, stats.Names[1], stats))
+	}
+	rows = append(rows, matchRow(result.Candidate, "overall", result.Overall))
+	export.WriteTable("gauntlet",
+		[]string{"candidate", "opponent", "games", "wins", "draws", "losses", "score", "margin", "average_plies", "a_average_ms", "b_average_ms", "llr", "sprt"},
+		rows)
+}
+
+// matchRow formats the columns shared by match.csv and gauntlet.csv
+func matchRow(botA, botB string, stats MatchStats) []string {
+	averagePlies, sprt := 0.0, ""
+	if stats.Games > 0 {
+		averagePlies = float64(stats.Plies) / float64(stats.Games)
+	}
+	if stats.SPRT != nil {
+		sprt = stats.SPRT.Hypothesis
+	}
+	return []string{botA, botB, strconv.Itoa(stats.Games), strconv.Itoa(stats.Wins), strconv.Itoa(stats.Draws), strconv.Itoa(stats.Losses),
+		csvFloat(stats.Score), csvFloat(stats.Margin), csvFloat(averagePlies), csvFloat(stats.AverageMS[0]), csvFloat(stats.AverageMS[1]),
+		csvFloat(stats.LLR), sprt}
+}
+
+// csvFloat formats a number for CSV without needless digits
+func csvFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// Close closes games.csv
+func (export *CSVExport) Close() error {
+	if export == nil {
+		return nil
+	}
+	return export.file.Close()
+}
+
+// exportGame adds a game to the CSV export, if any (an OnGameEnd observer)
+func exportGame(end GameEnd) {
+	csvExport.WriteGame(end.Result)
+}
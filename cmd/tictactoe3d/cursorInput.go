@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"tic-tac-toe-3d-bots/engine"
+)
+
+// CursorInput selects arrow-key move entry instead of typed coordinates; set from --cursor at startup
+var CursorInput bool
+
+// CURSOR_MARKER previews where a piece would land in the selected column
+const CURSOR_MARKER = '*'
+
+// cursorPosition is the column selected with the arrow keys, as board indices
+type cursorPosition struct {
+	col int // column letter index (A = 0)
+	row int // row index (1 = 0)
+}
+
+// move returns the cursor position in board notation, e.g. "B2"
+func (cursor cursorPosition) move() string {
+	return fmt.Sprintf("%c%d", 'A'+byte(cursor.col), cursor.row+1)
+}
+
+// stty runs stty on the terminal connected to standard input
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	output, err := cmd.Output()
+	return strings.TrimSpace(string(output)), err
+}
+
+var (
+	savedTerminal      string // terminal settings to restore while in cbreak mode, or ""
+	savedTerminalMutex sync.Mutex
+)
+
+// enableCbreak switches the terminal to unbuffered input without echo
+// Output processing and Ctrl+C keep working; returns a function that restores the previous settings
+func enableCbreak() (func(), error) {
+	saved, err := stty("-g")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := stty("-icanon", "-echo", "min", "1"); err != nil {
+		return nil, err
+	}
+
+	savedTerminalMutex.Lock()
+	savedTerminal = saved
+	savedTerminalMutex.Unlock()
+	return restoreTerminal, nil
+}
+
+// restoreTerminal undoes enableCbreak, if the terminal is in cbreak mode
+// Also called by the interrupt handler, since the program exits without running deferred restores
+func restoreTerminal() {
+	savedTerminalMutex.Lock()
+	defer savedTerminalMutex.Unlock()
+
+	if savedTerminal != "" {
+		stty(savedTerminal)
+		savedTerminal = ""
+	}
+}
+
+// readKey reads one key press, translating arrow key escape sequences to 'U', 'D', 'L' and 'R'
+func readKey() (byte, error) {
+	buf := make([]byte, 1)
+	if _, err := os.Stdin.Read(buf); err != nil {
+		return 0, err
+	}
+	if buf[0] != 27 {
+		return buf[0], nil
+	}
+
+	// Arrow keys arrive as ESC [ A-D
+	sequence := make([]byte, 2)
+	if _, err := os.Stdin.Read(sequence[:1]); err != nil || sequence[0] != '[' {
+		return 27, err
+	}
+	if _, err := os.Stdin.Read(sequence[1:]); err != nil {
+		return 27, err
+	}
+	switch sequence[1] {
+	case 'A':
+		return 'U', nil
+	case 'B':
+		return 'D', nil
+	case 'C':
+		return 'R', nil
+	case 'D':
+		return 'L', nil
+	}
+	return 27, nil
+}
+
+// readCursorMove lets the player pick a column with the arrow keys and drop a piece with Enter
+// The board is redrawn in place with CURSOR_MARKER on the cell where symbol would land, on top of overlay
+// Returns "" if the player pressed 't' to type a move or command instead, or if the terminal
+// does not support cursor input (CursorInput is then switched off)
+func readCursorMove(board *engine.Board, symbol byte, cursor *cursorPosition, overlay map[[3]int]byte) string {
+	restore, err := enableCbreak()
+	if err != nil {
+		fmt.Println(msg("cursor.unavailable"))
+		CursorInput = false
+		return ""
+	}
+	defer restore()
+
+	fmt.Println(msg("cursor.help"))
+	drawnLines := 0
+	for {
+		// Move back up over the previous drawing and redraw it in place
+		if drawnLines > 0 {
+			fmt.Printf("\033[%dA", drawnLines)
+		}
+		drawnLines = drawCursorBoard(board, symbol, *cursor, overlay)
+
+		key, err := readKey()
+		if err != nil {
+			return ""
+		}
+		switch key {
+		case 'U':
+			cursor.row = min(cursor.row+1, board.Width-1)
+		case 'D':
+			cursor.row = max(cursor.row-1, 0)
+		case 'R':
+			cursor.col = min(cursor.col+1, board.Length-1)
+		case 'L':
+			cursor.col = max(cursor.col-1, 0)
+		case '\n', '\r':
+			if board.CurrentHeights[cursor.col][cursor.row] < board.Height {
+				return cursor.move()
+			}
+		case 't', 'T':
+			fmt.Print(msg("cursor.type_prompt"))
+			return ""
+		}
+	}
+}
+
+// drawCursorBoard draws the board with the cursor preview and a status line, returning the lines printed
+func drawCursorBoard(board *engine.Board, symbol byte, cursor cursorPosition, overlay map[[3]int]byte) int {
+	status := msg("cursor.full", cursor.move())
+	height := board.CurrentHeights[cursor.col][cursor.row]
+	if height < board.Height {
+		status = msg("cursor.status", cursor.move(), symbol, height+1)
+	}
+
+	// The accessible renderer is a plain list, so only the status line is kept up to date
+	if DefaultRenderOptions.Accessible {
+		fmt.Print("\r\033[K" + status + "\n")
+		return 1
+	}
+
+	preview := make(map[[3]int]byte, len(overlay)+1)
+	for cell, marker := range overlay {
+		preview[cell] = marker
+	}
+	if height < board.Height {
+		preview[[3]int{cursor.col, cursor.row, height}] = CURSOR_MARKER
+	}
+	printBoardWithOverlay(board, preview)
+	fmt.Print("\r\033[K" + status + "\n")
+	return board.Length + board.Width + board.Height - 2 + 1 // projection rows plus the status line
+}
@@ -0,0 +1,434 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"tic-tac-toe-3d-bots/bots"
+	"tic-tac-toe-3d-bots/engine"
+	"tic-tac-toe-3d-bots/formats"
+)
+
+// ENGINE_MAX_DEPTH is the deepest iteration of an analysis no depth is set for, such as the one go falls back on when
+// the bot cannot be built; go infinite deepens until every cell is filled
+const ENGINE_MAX_DEPTH = 20
+
+// ENGINE_MAX_MULTIPV is the most lines the MultiPV option can ask for
+const ENGINE_MAX_MULTIPV = 64
+
+// ENGINE_MAX_HASH is the largest transposition table the Hash option can ask for, in MiB
+const ENGINE_MAX_HASH = bots.MAX_TT_SIZE
+
+// Engine speaks a UCI-like line protocol, so GUIs and tournament managers can drive the bots:
+//
+//	uci                                      identify the engine and list its options, then "uciok"
+//	isready                                  answered with "readyok"
+//	setoption name Bot value <spec>          bot to search with, e.g. alphabeta:depth=6
+//	setoption name Board value <LxWxH[/win]> board of later positions
+//	setoption name MultiPV value <N>         go infinite analyses the N best moves instead of only the best one
+//	setoption name Hash value <MiB>          size of the bot's transposition table, unless its spec sets one
+//	ucinewgame                               start from the empty board
+//	position startpos [moves A1 B2 ...]      the empty board, then these moves
+//	position snapshot <snapshot> [moves ...] a position written by the export command, then these moves
+//	go [depth N] [movetime MS] [infinite]    search the position, printing info lines and then bestmove;
+//	   [wtime MS btime MS winc MS binc MS]   with the clocks of 'x' and 'o' instead of a movetime, the time manager
+//	                                         (see bots.TimeManager) decides how long the move may take
+//	stop                                     end the search and print bestmove
+//	quit                                     exit
+//
+// While a bot that reports its progress (see bots.ProgressReporter) searches, it prints
+// "info depth D score cp S time MS pv ..." after every depth it completes, scored for the side to move; other bots
+// print no info lines. The move played is the bot's; if the bot is stopped before it decides, the first move of the
+// deepest line it reported is played instead, or the first legal move if it reported none.
+// A bot searching with a transposition table reports how full it is as "info hashfull N", in permille, before bestmove.
+// go infinite is analysis only: no bot searches, and an iteratively deepening analysis prints the info lines until stop,
+// which plays its best line; with MultiPV above 1 it prints "info depth D multipv K score ... pv ..." for each of the K
+// best moves instead
+type Engine struct {
+	spec    string
+	board   engine.BoardConfig
+	moves   []string // moves from the empty board to the current position
+	multiPV int      // lines the analysis of go infinite reports
+	output  io.Writer
+
+	writing sync.Mutex
+	search  *engineSearch // the search in progress, if any
+}
+
+// engineSearch is one go command in progress
+type engineSearch struct {
+	cancel   context.CancelFunc
+	infinite bool          // analyses until stopped
+	stopped  chan struct{} // closed by stop, which an infinite search waits for
+	done     chan struct{} // closed once bestmove is printed
+	stopOnce sync.Once
+	closing  atomic.Bool // the session is over, so the search ends without a bestmove
+}
+
+// newEngine creates an engine searching with the bot described by spec, on the empty board
+func newEngine(spec string, board engine.BoardConfig, output io.Writer) *Engine {
+	return &Engine{spec: spec, board: board, multiPV: 1, output: output}
+}
+
+// println writes one protocol line
+func (e *Engine) println(format string, args ...any) {
+	e.writing.Lock()
+	defer e.writing.Unlock()
+	fmt.Fprintf(e.output, format+"\n", args...)
+}
+
+// Run reads commands from input until quit or the end of input
+func (e *Engine) Run(input io.Reader) {
+	scanner := bufio.NewScanner(input)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if fields[0] == "quit" {
+			e.abandon()
+			return
+		}
+		if err := e.handle(fields[0], fields[1:]); err != nil {
+			e.println("info string error: %v", err)
+		}
+	}
+
+	// At the end of input a search with an end of its own still answers, as a script piping its commands in
+	// waits for that; an infinite one has nobody left to stop it
+	if search := e.search; search != nil && !search.infinite {
+		<-search.done
+		e.search = nil
+	}
+	e.abandon()
+}
+
+// handle executes one command
+func (e *Engine) handle(command string, args []string) error {
+	switch command {
+	case "uci":
+		e.println("id name 3D Tic-Tac-Toe (%s)", e.spec)
+		e.println("option name Bot type string default %s", e.spec)
+		e.println("option name Board type string default %dx%dx%d/%d", e.board.Length, e.board.Width, e.board.Height, e.board.Win)
+		e.println("option name MultiPV type spin default 1 min 1 max %d", ENGINE_MAX_MULTIPV)
+		e.println("option name Hash type spin default %d min 1 max %d", bots.TTSize(), ENGINE_MAX_HASH)
+		e.println("uciok")
+
+	case "isready":
+		e.println("readyok")
+
+	case "setoption":
+		e.stop()
+		return e.setOption(args)
+
+	case "ucinewgame":
+		e.stop()
+		e.moves = nil
+
+	case "position":
+		e.stop()
+		return e.setPosition(args)
+
+	case "go":
+		e.stop()
+		return e.goSearch(args)
+
+	case "stop":
+		e.stop()
+
+	default:
+		return fmt.Errorf("unknown command %q", command)
+	}
+	return nil
+}
+
+// setOption handles "setoption name <name> value <value>"
+func (e *Engine) setOption(args []string) error {
+	joined := strings.Join(args, " ")
+	name, value, found := strings.Cut(strings.TrimPrefix(joined, "name "), " value ")
+	if !found {
+		return fmt.Errorf("expected \"setoption name <name> value <value>\"")
+	}
+
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "bot":
+		bot, err := newBotFromSpec(value, 'x', "")
+		if err != nil {
+			return err
+		}
+		bot.Close()
+		e.spec = value
+
+	case "board":
+		board, err := engine.ParseBoardConfig(value)
+		if err != nil {
+			return err
+		}
+		if board.Win == 0 {
+			board.Win = min(board.Length, board.Width, board.Height)
+		}
+		if err := engine.ValidateBoardDimensions(board.Length, board.Width, board.Height, board.Win); err != nil {
+			return err
+		}
+		e.board, e.moves = *board, nil
+
+	case "multipv":
+		lines, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || lines < 1 || lines > ENGINE_MAX_MULTIPV {
+			return fmt.Errorf("MultiPV must be a number from 1 to %d", ENGINE_MAX_MULTIPV)
+		}
+		e.multiPV = lines
+
+	case "hash":
+		mib, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || mib < 1 || mib > ENGINE_MAX_HASH {
+			return fmt.Errorf("Hash must be a number of MiB from 1 to %d", ENGINE_MAX_HASH)
+		}
+		bots.SetTTSize(mib)
+
+	default:
+		return fmt.Errorf("unknown option %q", name)
+	}
+	return nil
+}
+
+// setPosition handles "position startpos [moves ...]" and "position snapshot <snapshot> [moves ...]"
+func (e *Engine) setPosition(args []string) error {
+	record := formats.GameRecord{Board: e.board}
+	switch {
+	case len(args) > 0 && args[0] == "startpos":
+		args = args[1:]
+	case len(args) >= 4 && args[0] == "snapshot":
+		board, moves, err := engine.ParseSnapshot(strings.Join(args[1:4], " "))
+		if err != nil {
+			return err
+		}
+		record.Board, record.Moves = board, moves
+		args = args[4:]
+	default:
+		return fmt.Errorf("expected \"position startpos\" or \"position snapshot <snapshot>\"")
+	}
+
+	if len(args) > 0 {
+		if args[0] != "moves" {
+			return fmt.Errorf("unexpected %q in position", args[0])
+		}
+		for _, move := range args[1:] {
+			record.Moves = append(record.Moves, strings.ToUpper(move))
+		}
+	}
+	if _, err := record.Replay(len(record.Moves)); err != nil {
+		return err
+	}
+	e.board, e.moves = record.Board, record.Moves
+	return nil
+}
+
+// goSearch handles "go [depth N] [movetime MS] [wtime MS btime MS winc MS binc MS] [infinite]", starting the search
+// in the background
+func (e *Engine) goSearch(args []string) error {
+	depth, moveTime, infinite := 0, time.Duration(0), false
+	clocks := make(map[string]time.Duration) // wtime, btime, winc and binc
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "infinite":
+			infinite = true
+		case "depth", "movetime", "wtime", "btime", "winc", "binc":
+			if i+1 >= len(args) {
+				return fmt.Errorf("%s needs a value", args[i])
+			}
+			value, err := strconv.Atoi(args[i+1])
+			if err != nil || value < 0 || (value == 0 && (args[i] == "depth" || args[i] == "movetime")) {
+				return fmt.Errorf("invalid %s %q", args[i], args[i+1])
+			}
+			switch args[i] {
+			case "depth":
+				depth = value
+			case "movetime":
+				moveTime = time.Duration(value) * time.Millisecond
+			default:
+				clocks[args[i]] = time.Duration(value) * time.Millisecond
+			}
+			i++
+		default:
+			return fmt.Errorf("unknown go parameter %q", args[i])
+		}
+	}
+
+	record := formats.GameRecord{Board: e.board, Moves: e.moves}
+	board, _ := record.Replay(len(record.Moves)) // validated by position
+
+	// A movetime outweighs the clocks: wtime and winc are 'x''s, btime and binc 'o''s
+	budget := bots.FixedBudget(moveTime)
+	remaining, hasClock := clocks["wtime"]
+	increment := clocks["winc"]
+	if board.NextPlayer() == 'o' {
+		remaining, hasClock = clocks["btime"]
+		increment = clocks["binc"]
+	}
+	if moveTime == 0 && hasClock && !infinite {
+		budget = bots.NewTimeManager(increment).Budget(board, remaining)
+	}
+	ctx, cancel := budget.Context(context.Background())
+	search := &engineSearch{cancel: cancel, infinite: infinite, stopped: make(chan struct{}), done: make(chan struct{})}
+	e.search = search
+	go e.runSearch(search, board, depth, infinite, e.multiPV, ctx)
+	return nil
+}
+
+// runSearch asks the bot for its move, printing info lines for the iterations it reports, then prints bestmove
+// An infinite search only analyses, until stopped
+func (e *Engine) runSearch(search *engineSearch, board *engine.Board, depth int, infinite bool, multiPV int, ctx context.Context) {
+	defer close(search.done)
+	defer search.cancel()
+
+	symbol := board.NextPlayer()
+	if board.CheckWin() != '|' || board.IsFull() {
+		e.println("bestmove (none)")
+		return
+	}
+
+	maxDepth := ENGINE_MAX_DEPTH
+	if infinite {
+		maxDepth = board.Length*board.Width*board.Height - board.MoveCount()
+	}
+	if depth > 0 {
+		maxDepth = depth
+	}
+
+	// The bot searches in the background while its progress streams info lines; an infinite search leaves the bot out
+	var bot bots.BotInterface
+	if !infinite {
+		var err error
+		if bot, err = engineBot(e.spec, symbol, depth); err != nil {
+			e.println("info string error: %v", err)
+		} else {
+			defer bot.Close()
+		}
+	}
+	control := startSearch(ctx, bot, board, searchOptions{MaxDepth: maxDepth, MultiPV: multiPV})
+	printed := make(chan struct{})
+	go func() {
+		defer close(printed)
+		for progress := range control.Subscribe() {
+			if progress.Candidates == nil {
+				e.println("info depth %d score %s time %d pv %s", progress.Depth, engineScore(progress.Score, symbol, len(progress.Line)),
+					progress.Elapsed.Milliseconds(), strings.Join(progress.Line, " "))
+			} else {
+				for i, candidate := range progress.Candidates[:min(multiPV, len(progress.Candidates))] {
+					e.println("info depth %d multipv %d score %s time %d pv %s", progress.Depth, i+1,
+						engineScore(candidate.Score, symbol, len(candidate.Line)), progress.Elapsed.Milliseconds(), strings.Join(candidate.Line, " "))
+				}
+			}
+		}
+	}()
+
+	// Without a bot move, play the deepest line reported; an analysis-only go depth waits for that depth to complete,
+	// and every analysis reports at least one info line unless it is stopped first
+	<-control.Moved()
+	if _, err := control.Result(); err != nil || depth > 0 {
+		<-control.Analysed()
+	}
+	select {
+	case <-control.Iterated():
+	case <-control.Analysed():
+	}
+	if infinite {
+		<-search.stopped
+	}
+	bestMove := control.Stop()
+	<-printed
+	if search.closing.Load() {
+		return // The session is over, and the search may not have finished
+	}
+
+	if searcher, ok := bot.(ttBot); ok {
+		e.println("info hashfull %d", int(1000*searcher.TTStats().FillRate()))
+	}
+
+	line := control.Progress().Line
+	if len(line) > 1 && line[0] == bestMove {
+		e.println("bestmove %s ponder %s", bestMove, line[1])
+	} else {
+		e.println("bestmove %s", bestMove)
+	}
+}
+
+// stop ends the search in progress, if any, and waits for its bestmove
+func (e *Engine) stop() {
+	search := e.search
+	if search == nil {
+		return
+	}
+	search.stopOnce.Do(func() { close(search.stopped) })
+	search.cancel()
+	<-search.done
+	e.search = nil
+}
+
+// abandon ends the search in progress, if any, without its bestmove, as the session is over
+func (e *Engine) abandon() {
+	if e.search != nil {
+		e.search.closing.Store(true)
+	}
+	e.stop()
+}
+
+// engineBot builds the engine's bot for symbol; a depth, if given, replaces the bot's own depth when it has one
+func engineBot(spec string, symbol byte, depth int) (bots.BotInterface, error) {
+	if depth > 0 {
+		if bot, err := newBotFromSpec(engineDepthSpec(spec, depth), symbol, ""); err == nil {
+			return bot, nil
+		}
+	}
+	return newBotFromSpec(spec, symbol, "")
+}
+
+// engineDepthSpec adds a depth parameter to spec
+func engineDepthSpec(spec string, depth int) string {
+	separator := ":"
+	if strings.Contains(spec, ":") {
+		separator = ","
+	}
+	return fmt.Sprintf("%s%sdepth=%d", spec, separator, depth)
+}
+
+// engineScore formats a search score ('x' perspective) for the side to move, as "cp N" or, for a forced result, "mate N"
+// The mate distance in moves of the side to move is taken from the length of the line
+func engineScore(score int, symbol byte, lineLength int) string {
+	sign := 1
+	if symbol == 'o' {
+		sign = -1
+	}
+	switch {
+	case score >= engine.MAX_INT/2:
+		return fmt.Sprintf("mate %d", sign*(lineLength+1)/2)
+	case score <= engine.MIN_INT/2:
+		return fmt.Sprintf("mate %d", -sign*(lineLength+1)/2)
+	}
+	return fmt.Sprintf("cp %d", sign*score)
+}
+
+// runEngine plays the engine protocol on standard input and output
+func runEngine(spec string, board *engine.Board) error {
+	if spec == "" {
+		spec = "alphabeta"
+	}
+	bot, err := newBotFromSpec(spec, 'x', "")
+	if err != nil {
+		return err
+	}
+	bot.Close()
+
+	config := engine.BoardConfig{Length: board.Length, Width: board.Width, Height: board.Height, Win: board.WinLength}
+	newEngine(spec, config, os.Stdout).Run(os.Stdin)
+	return nil
+}
@@ -0,0 +1,339 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"tic-tac-toe-3d-bots/bots"
+	"tic-tac-toe-3d-bots/engine"
+	"tic-tac-toe-3d-bots/formats"
+)
+
+// BotStats tracks performance statistics for a bot
+type BotStats struct {
+	Name        string
+	TotalTime   time.Duration
+	MoveCount   int
+	AverageTime time.Duration
+	TT          *bots.TTStats // the bot's transposition table after its last move, nil for bots without one
+}
+
+// UpdateStats adds a move time to the bot's statistics
+func (stats *BotStats) UpdateStats(moveTime time.Duration) {
+	stats.TotalTime += moveTime
+	stats.MoveCount++
+	stats.AverageTime = stats.TotalTime / time.Duration(stats.MoveCount)
+}
+
+// RunEvE starts an Environment vs Environment (Bot vs Bot) game and returns its rematch
+func RunEvE() Rematch {
+	board := chooseBoard(3)
+
+	fmt.Println(msg("eve.title"))
+	fmt.Println(msg("eve.choose"))
+
+	// Select first bot (X player)
+	fmt.Println(msg("eve.select_bot1"))
+	choices := printBotChoices()
+	fmt.Print(msg("choice.prompt", choices))
+
+	var bot1Choice int
+	fmt.Scanln(&bot1Choice)
+
+	newBot1 := menuBotFactory(bot1Choice, "Bot1")
+
+	// Select second bot (O player)
+	fmt.Println(msg("eve.select_bot2"))
+	printBotChoices()
+	fmt.Print(msg("choice.prompt", choices))
+
+	var bot2Choice int
+	fmt.Scanln(&bot2Choice)
+
+	newBot2 := menuBotFactory(bot2Choice, "Bot2")
+
+	fmt.Print(msg("match.prompt"))
+	var gamesInput string
+	fmt.Scanln(&gamesInput)
+	if games, err := strconv.Atoi(gamesInput); err == nil && games > 1 {
+		playMatch(board, newBot1, newBot2, games, 1, formats.EvESettings{}, nil)
+		return nil
+	}
+
+	fmt.Println(msg("eve.autoplay_prompt"))
+
+	var playMode string
+	fmt.Scanln(&playMode)
+
+	return eveMatch(board, newBot1, newBot2, formats.EvESettings{AutoPlay: playMode == "auto", Quiet: playMode == "quiet", ShowSearchStats: true})
+}
+
+// menuBotFactory returns a constructor for the bot picked from the bot menu, for either side
+// An invalid choice falls back to RandomBot
+func menuBotFactory(choice int, defaultName string) func(symbol byte) bots.BotInterface {
+	if bot := createBot(choice, 'x', defaultName); bot != nil {
+		bot.Close() // Only built to validate the choice
+		return func(symbol byte) bots.BotInterface {
+			return createBot(choice, symbol, defaultName)
+		}
+	}
+
+	fmt.Println(msg("choice.fallback"))
+	return func(symbol byte) bots.BotInterface {
+		return bots.NewBot(symbol, "RandomBot")
+	}
+}
+
+// playEvE runs a bot vs bot game on the given board until a win or a draw; bot1 plays 'x'
+// When settings.AutoPlay is false the board is shown and the user presses Enter between moves
+// Both bots are closed when the game ends; the returned result tells how it ended
+func playEvE(board *engine.Board, bot1, bot2 bots.BotInterface, settings formats.EvESettings) (result formats.GameResult) {
+	session := startGame(board, formats.GameRecord{
+		Mode:    "eve",
+		Players: [2]string{bot1.Name(), bot2.Name()},
+		Bots:    [2]*bots.BotConfig{bots.ConfigOf(bot1), bots.ConfigOf(bot2)},
+		EvE:     &settings,
+	})
+	defer func() { result = session.Result() }() // Runs after End has settled the result
+	defer session.End()
+	defer bot1.Close()
+	defer bot2.Close()
+	silent := settings.Silent
+	autoPlay := settings.AutoPlay || settings.Quiet || silent
+	quiet := settings.Quiet || silent
+
+	// Initialize statistics, carrying over the clocks of a resumed game
+	players := [2]bots.BotInterface{bot1, bot2}
+	stats := [2]*BotStats{{Name: bot1.Name()}, {Name: bot2.Name()}}
+	for i, bot := range players {
+		stats[i].TotalTime, stats[i].MoveCount = session.Clock(bot.Symbol())
+		if stats[i].MoveCount > 0 {
+			stats[i].AverageTime = stats[i].TotalTime / time.Duration(stats[i].MoveCount)
+		}
+	}
+
+	// Play the opening, if any, before the bots take over; like a resumed game, they find its moves on the board
+	if board.MoveCount() == 0 {
+		for _, move := range settings.Opening {
+			if board.Move(move, board.NextPlayer())[0] == -1 {
+				break
+			}
+			session.RecordMove(move, 0)
+		}
+	}
+
+	totalMoves := board.MoveCount()
+	maxMoves := board.Length * board.Width * board.Height
+	resignations := newResignWatch(settings)
+	adjudication := newAdjudicator(settings)
+
+	if !quiet {
+		fmt.Println(msg("eve.begins"))
+		fmt.Print(msg("eve.versus", stats[0].Name, stats[1].Name))
+	}
+
+	current := formats.SymbolIndex(board.NextPlayer())
+	for totalMoves < maxMoves {
+		bot, opponent := players[current], players[1-current]
+		botStats, opponentStats := stats[current], stats[1-current]
+		if !autoPlay {
+			printBoard(board)
+		}
+
+		if !quiet {
+			fmt.Print(msg("eve.thinking", botStats.Name, bot.Symbol()))
+		}
+
+		start := time.Now()
+		parentCtx, searchDone := session.searchContext()
+		moveCtx, cancel := moveContext(parentCtx, settings.MoveTimeLimit)
+		move, err := searchMove(moveCtx, bot, board)
+		cancel()
+		searchDone()
+		if session.Interrupted() {
+			return
+		}
+		moveTime := time.Since(start)
+		botStats.UpdateStats(moveTime)
+
+		// Enforce the time control
+		if errors.Is(err, context.DeadlineExceeded) || (settings.MoveTimeLimit > 0 && moveTime > settings.MoveTimeLimit) {
+			session.SetResult(opponent.Symbol(), "time")
+			if !silent {
+				fmt.Print(msg("eve.time_loss", botStats.Name, bot.Symbol(), settings.MoveTimeLimit, opponentStats.Name, opponent.Symbol()))
+				printFinalStats(stats[0], stats[1])
+			}
+			return
+		}
+		if err != nil && !board.IsFull() {
+			// A bot that cannot move, such as an external engine that crashed or played an illegal move, loses; only a
+			// full board leaves no move to play
+			session.SetResult(opponent.Symbol(), "forfeit")
+			if !silent {
+				fmt.Print(msg("eve.forfeit", botStats.Name, bot.Symbol(), err, opponentStats.Name, opponent.Symbol()))
+				printFinalStats(stats[0], stats[1])
+			}
+			return
+		}
+		if err != nil {
+			break // The board is full
+		}
+		opponent.OpponentMove(move.Name)
+		session.RecordMove(move.Name, moveTime)
+		session.LogBotSearch(bot)
+		if searcher, ok := bot.(ttBot); ok {
+			tt := searcher.TTStats()
+			botStats.TT = &tt
+		}
+
+		if !quiet {
+			fmt.Print(msg("eve.plays", botStats.Name, move.Name, move.Coords[0], move.Coords[1], move.Coords[2],
+				moveTime, botStats.AverageTime))
+			if botStats.TT != nil {
+				printTTFill(*botStats.TT)
+			}
+		}
+		if settings.ShowSearchStats && !quiet {
+			printWorkerStats(bot)
+		}
+		if !autoPlay {
+			printEvalBar(session, board, opponent.Symbol())
+		}
+		totalMoves++
+
+		// Check for a win
+		if board.CheckWin() == bot.Symbol() {
+			if !autoPlay {
+				printBoard(board)
+			}
+			if !silent {
+				fmt.Print(msg("eve.wins", botStats.Name, bot.Symbol()))
+				printFinalStats(stats[0], stats[1])
+			}
+			return
+		}
+
+		// Check if board is full
+		if board.IsFull() {
+			break
+		}
+
+		// The bot's evaluation of the position it left may make it resign, or settle the game with its opponent's
+		if resignations.enabled() || adjudication.enabled() {
+			score := botEvaluation(bot, board)
+			if resignations.resigns(bot.Symbol(), score) {
+				session.SetResult(opponent.Symbol(), "resigned")
+				if !silent {
+					fmt.Print(msg("eve.resigns", botStats.Name, bot.Symbol(), opponentStats.Name, opponent.Symbol()))
+					printFinalStats(stats[0], stats[1])
+				}
+				return
+			}
+			if winner := adjudication.adjudicate(bot.Symbol(), score, totalMoves); winner != 0 {
+				session.SetResult(winner, "adjudicated")
+				if !silent {
+					if winner == '|' {
+						fmt.Print(msg("eve.adjudicated_draw"))
+					} else {
+						fmt.Print(msg("eve.adjudicated_win", stats[formats.SymbolIndex(winner)].Name, winner))
+					}
+					printFinalStats(stats[0], stats[1])
+				}
+				return
+			}
+		}
+
+		if !autoPlay {
+			waitForEnter(session)
+		}
+		current = 1 - current
+	}
+
+	// If we reach here, it's a draw
+	if !autoPlay {
+		printBoard(board)
+	}
+	if !silent {
+		fmt.Println(msg("game.draw"))
+		printFinalStats(stats[0], stats[1])
+	}
+	return
+}
+
+// waitForEnter waits for the user to press Enter before the next bot move
+// A "save [file]" command typed instead saves the game and asks again
+func waitForEnter(session *GameSession) {
+	for {
+		fmt.Print(msg("eve.press_enter"))
+		var command, argument string
+		fmt.Scanln(&command, &argument)
+		if !saveCommand(session, command, argument) {
+			return
+		}
+	}
+}
+
+// moveContext derives the context for a single bot move from parent, with a deadline if limit is positive
+func moveContext(parent context.Context, limit time.Duration) (context.Context, context.CancelFunc) {
+	return bots.FixedBudget(limit).Context(parent)
+}
+
+// ttBot is a bot searching with a transposition table
+type ttBot interface {
+	TTStats() bots.TTStats
+}
+
+// printTTFill displays the fill of a bot's transposition table
+func printTTFill(stats bots.TTStats) {
+	fmt.Print(msg("eve.tt", 100*stats.FillRate(), stats.Used, stats.Entries, float64(stats.Bytes)/(1<<20)))
+}
+
+// printWorkerStats displays per-worker search statistics for bots that split the root across workers
+func printWorkerStats(bot bots.BotInterface) {
+	splitBot, ok := bot.(*bots.ConcurrentMinimaxBot)
+	if !ok || len(splitBot.Stats.WorkerNodes) == 0 {
+		return
+	}
+
+	for w := range splitBot.Stats.WorkerNodes {
+		fmt.Print(msg("eve.worker", w,
+			splitBot.Stats.WorkerNodes[w], splitBot.Stats.WorkerMoves[w], splitBot.Stats.WorkerSteals[w]))
+	}
+}
+
+// printFinalStats displays the final performance statistics
+func printFinalStats(bot1Stats, bot2Stats *BotStats) {
+	fmt.Println(msg("stats.title"))
+	fmt.Println("═══════════════════════════════════════")
+
+	fmt.Printf("🤖 %s:\n", bot1Stats.Name)
+	fmt.Print(msg("stats.total_moves", bot1Stats.MoveCount))
+	fmt.Print(msg("stats.total_time", bot1Stats.TotalTime))
+	fmt.Print(msg("stats.average_time", bot1Stats.AverageTime))
+	if bot1Stats.TT != nil {
+		fmt.Print(msg("stats.tt_fill", 100*bot1Stats.TT.FillRate(), bot1Stats.TT.Used, bot1Stats.TT.Entries))
+	}
+
+	fmt.Printf("\n🤖 %s:\n", bot2Stats.Name)
+	fmt.Print(msg("stats.total_moves", bot2Stats.MoveCount))
+	fmt.Print(msg("stats.total_time", bot2Stats.TotalTime))
+	fmt.Print(msg("stats.average_time", bot2Stats.AverageTime))
+	if bot2Stats.TT != nil {
+		fmt.Print(msg("stats.tt_fill", 100*bot2Stats.TT.FillRate(), bot2Stats.TT.Used, bot2Stats.TT.Entries))
+	}
+
+	// Performance comparison
+	fmt.Println(msg("stats.comparison"))
+	if bot1Stats.AverageTime < bot2Stats.AverageTime {
+		ratio := float64(bot2Stats.AverageTime) / float64(bot1Stats.AverageTime)
+		fmt.Print(msg("stats.faster", bot1Stats.Name, ratio, bot2Stats.Name))
+	} else if bot2Stats.AverageTime < bot1Stats.AverageTime {
+		ratio := float64(bot1Stats.AverageTime) / float64(bot2Stats.AverageTime)
+		fmt.Print(msg("stats.faster", bot2Stats.Name, ratio, bot1Stats.Name))
+	} else {
+		fmt.Println(msg("stats.similar"))
+	}
+}
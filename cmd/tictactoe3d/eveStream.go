@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"tic-tac-toe-3d-bots/bots"
+	"tic-tac-toe-3d-bots/engine"
+	"tic-tac-toe-3d-bots/formats"
+)
+
+// RunEvEStream runs the EvE Stream mode where two persistent minimax bots face each other
+// with bidirectional streaming and background calculation during opponent thinking time
+func RunEvEStream() {
+	fmt.Println(msg("evestream.title"))
+	fmt.Println("═════════════════════════════════════════════════════════════════")
+	fmt.Println()
+	fmt.Println(msg("evestream.intro1"))
+	fmt.Println(msg("evestream.intro2"))
+	fmt.Println()
+
+	// Create two persistent minimax bots
+	botX := bots.NewPersistentMinimaxBot('x', "PersistentBot-X", 4, 10)
+	botO := bots.NewPersistentMinimaxBot('o', "PersistentBot-O", 4, 10)
+
+	playEvEStream(chooseBoard(3), botX, botO)
+}
+
+// playEvEStream runs a game between two persistent bots on the given board and closes them afterwards
+func playEvEStream(board *engine.Board, botX, botO *bots.PersistentMinimaxBot) {
+	session := startGame(board, formats.GameRecord{
+		Mode:    "evestream",
+		Players: [2]string{botX.Name(), botO.Name()},
+		Bots:    [2]*bots.BotConfig{persistentBotConfig(botX), persistentBotConfig(botO)},
+	})
+	defer session.End()
+
+	// Ensure cleanup at the end
+	defer botX.Close()
+	defer botO.Close()
+
+	currentPlayer := board.NextPlayer()
+	moveCount := board.MoveCount()
+
+	fmt.Print(msg("evestream.versus", botX.Name(), botO.Name()))
+	fmt.Println()
+
+	for {
+		printBoard(board)
+		fmt.Println()
+
+		// Check for win condition
+		winner := board.CheckWin()
+		if winner != '|' {
+			if winner == 'x' {
+				fmt.Print(msg("evestream.wins", botX.Name(), 'X'))
+			} else {
+				fmt.Print(msg("evestream.wins", botO.Name(), 'O'))
+			}
+			break
+		}
+
+		// Check for draw
+		if len(board.GetValidMoves()) == 0 {
+			fmt.Println(msg("game.draw_short"))
+			break
+		}
+
+		moveCount++
+		fmt.Print(msg("evestream.move", moveCount))
+
+		var activeBot *bots.PersistentMinimaxBot
+		var waitingBot *bots.PersistentMinimaxBot
+
+		if currentPlayer == 'x' {
+			activeBot = botX
+			waitingBot = botO
+			fmt.Print(msg("evestream.thinking", botX.Name(), 'X'))
+		} else {
+			activeBot = botO
+			waitingBot = botX
+			fmt.Print(msg("evestream.thinking", botO.Name(), 'O'))
+		}
+
+		// Measure thinking time
+		start := time.Now()
+
+		// Active bot makes a move (this triggers background calculation in waiting bot)
+		ctx, searchDone := session.searchContext()
+		move, err := searchMove(ctx, activeBot, board)
+		searchDone()
+		if session.Interrupted() {
+			return
+		}
+
+		duration := time.Since(start)
+
+		if err != nil {
+			fmt.Print(msg("evestream.no_move", activeBot.Name()))
+			break
+		}
+
+		fmt.Print(msg("evestream.plays", move.Name, move.Coords[0], move.Coords[1], move.Coords[2], duration))
+
+		// Notify the waiting bot about opponent's move for tree pruning
+		waitingBot.OpponentMove(move.Name)
+		session.RecordMove(move.Name, duration)
+		session.LogBotSearch(activeBot)
+
+		// Show some statistics about the bots' search trees
+		showSearchStats(activeBot, waitingBot, duration)
+
+		fmt.Println()
+
+		// Switch players
+		if currentPlayer == 'x' {
+			currentPlayer = 'o'
+		} else {
+			currentPlayer = 'x'
+		}
+
+		// Small delay to make it more watchable
+		time.Sleep(500 * time.Millisecond)
+	}
+
+	fmt.Println(msg("evestream.game_over"))
+
+	// Show final statistics
+	fmt.Println(msg("evestream.final_title"))
+	showFinalStats(botX, botO)
+}
+
+// persistentBotConfig describes a persistent bot built outside the registry, so a saved game can recreate it
+func persistentBotConfig(bot *bots.PersistentMinimaxBot) *bots.BotConfig {
+	if config := bot.Config(); config != nil {
+		return config
+	}
+	return &bots.BotConfig{Type: "persistent", Name: bot.Name(), Params: map[string]int{"depth": bot.InitialDepth, "base": bot.Base}}
+}
+
+// showSearchStats displays current search statistics for both bots
+func showSearchStats(activeBot, waitingBot *bots.PersistentMinimaxBot, thinkingTime time.Duration) {
+	fmt.Print(msg("evestream.search_stats", activeBot.Name(), waitingBot.Name()))
+
+	// Get node counts (simplified for now)
+	activeNodes := activeBot.NodeCount()
+	waitingNodes := waitingBot.NodeCount()
+
+	fmt.Print(msg("evestream.nodes", activeNodes, waitingNodes))
+
+	fmt.Print(msg("evestream.thinking_time", thinkingTime))
+}
+
+// showFinalStats displays final statistics for both bots
+func showFinalStats(botX, botO *bots.PersistentMinimaxBot) {
+	fmt.Print(msg("evestream.final_nodes", botX.Name(), botX.NodeCount()))
+	fmt.Print(msg("evestream.final_nodes", botO.Name(), botO.NodeCount()))
+	fmt.Println(msg("evestream.persistent"))
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"tic-tac-toe-3d-bots/bots"
+	"tic-tac-toe-3d-bots/engine"
+	"tic-tac-toe-3d-bots/formats"
+)
+
+// EVENT_LINE_MAX is the longest event log line read back when resuming a run
+const EVENT_LINE_MAX = 1 << 20
+
+// eventRun is an EvE run as its event log tells it, read back to resume the run after an interruption
+// Games are numbered within the run as the schedule plays them (see runMatch), so the resumed run meets every game
+// under the number it was logged with: it counts the finished ones as they ended, replays those in progress to their
+// last move and plays the rest
+type eventRun struct {
+	header *GameEvent // run_started event naming the run's board and bots; nil if the log has none
+	games  map[int]*loggedGame
+}
+
+// loggedGame is an EvE game of the event log
+type loggedGame struct {
+	record formats.GameRecord  // players, board and moves so far, with the clocks they add up to
+	times  []time.Duration     // thinking time of each move
+	result *formats.GameResult // how the game ended, or nil if it did not finish
+}
+
+// resumedRun is the run being resumed with --resume-events, if any
+var resumedRun *eventRun
+
+// loadEventRun reads the EvE games of the event log at path
+// A game started again under the same number, as a resumed run does, continues from the ply its moves start at.
+// Games the log does not see to their end are replayed to find out whether their last move ended them
+func loadEventRun(path string) (*eventRun, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	run := &eventRun{games: make(map[int]*loggedGame)}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), EVENT_LINE_MAX)
+	for scanner.Scan() {
+		var event GameEvent
+		if json.Unmarshal(scanner.Bytes(), &event) != nil {
+			continue // A line cut short by the interruption
+		}
+		game := run.games[event.Game]
+		switch event.Type {
+		case EventRunStarted:
+			if run.header == nil {
+				header := event
+				run.header = &header
+			}
+		case EventGameStarted:
+			if event.Mode != "eve" || event.Board == nil || event.Players == nil {
+				delete(run.games, event.Game)
+				continue
+			}
+			if game == nil {
+				game = &loggedGame{}
+				run.games[event.Game] = game
+			}
+			game.record.Mode, game.record.Board, game.record.Players = event.Mode, *event.Board, *event.Players
+			game.result = nil
+		case EventMove:
+			if game == nil || event.Ply < 1 || event.Ply > len(game.record.Moves)+1 {
+				continue
+			}
+			game.record.Moves = append(game.record.Moves[:event.Ply-1], event.Move)
+			game.times = append(game.times[:event.Ply-1], time.Duration(event.ThinkingMS*float64(time.Millisecond)))
+		case EventGameOver:
+			if game == nil || event.Winner == "" {
+				continue // Interrupted, so still to be played out
+			}
+			game.result = &formats.GameResult{Winner: event.Winner, Reason: event.Reason}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	for number, game := range run.games {
+		board, err := game.record.Replay(len(game.record.Moves))
+		if err != nil {
+			return nil, fmt.Errorf("%s: game %d: %v", path, number, err)
+		}
+		var clocks [2]time.Duration
+		for ply, thinking := range game.times {
+			clocks[ply%2] += thinking
+		}
+		for i, clock := range clocks {
+			game.record.Clocks[i] = clock.String()
+		}
+
+		switch {
+		case game.result != nil:
+		case board.CheckWin() != '|':
+			game.result = &formats.GameResult{Winner: string(board.CheckWin()), Reason: "line"}
+		case board.IsFull():
+			game.result = &formats.GameResult{Winner: "draw", Reason: "full_board"}
+		default:
+			continue
+		}
+		game.completeResult(run.seed())
+	}
+	return run, nil
+}
+
+// seed returns the seed the run was started with, or 0 if its log does not say
+func (run *eventRun) seed() int64 {
+	if run == nil || run.header == nil {
+		return 0
+	}
+	return run.header.Seed
+}
+
+// restoreSeed has the resumed run's random choices carry on from the seed it was started with, which a --seed of
+// the resuming run other than 0 must match
+func (run *eventRun) restoreSeed(seed int64) error {
+	logged := run.seed()
+	if logged == 0 {
+		return nil
+	}
+	if seed != 0 && seed != logged {
+		return fmt.Errorf("the resumed run was played with --seed %d, not %d", logged, seed)
+	}
+	bots.SetSeed(logged)
+	return nil
+}
+
+// completeResult fills in the rest of the game's result from its record; seed is the run's
+func (game *loggedGame) completeResult(seed int64) {
+	result := game.result
+	result.Mode, result.Board, result.Players, result.Seed = game.record.Mode, game.record.Board, game.record.Players, seed
+	for ply, move := range game.record.Moves {
+		result.Moves = append(result.Moves, formats.PlayedMove{Ply: ply + 1, Player: string("xo"[ply%2]), Move: move, ThinkingMS: formats.Milliseconds(game.times[ply])})
+	}
+	for i, clock := range game.record.ThinkingTimes() {
+		stats := &result.Stats[i]
+		stats.Moves = (len(game.record.Moves) + 1 - i) / 2
+		stats.TotalMS = formats.Milliseconds(clock)
+		if stats.Moves > 0 {
+			stats.AverageMS = stats.TotalMS / float64(stats.Moves)
+		}
+	}
+}
+
+// counts returns how many of the run's games finished and how many were still in progress
+func (run *eventRun) counts() (finished, inProgress int) {
+	for _, game := range run.games {
+		if game.result != nil {
+			finished++
+		} else {
+			inProgress++
+		}
+	}
+	return finished, inProgress
+}
+
+// beginRun checks that the run being resumed, if any, is the one described by mode, board, participants and their
+// bot specs, then logs a run_started event for it
+// Resuming with other bots or in another order would count games they never played, so any difference is an error
+func beginRun(mode string, board *engine.Board, participants, specs []string) error {
+	size := engine.BoardConfig{Length: board.Length, Width: board.Width, Height: board.Height, Win: board.WinLength}
+	if err := resumedRun.check(mode, size, participants, specs); err != nil {
+		return err
+	}
+	eventLog.Log(GameEvent{Type: EventRunStarted, Mode: mode, Board: &size, Participants: participants, Bots: specs, Seed: bots.RunSeed})
+	return nil
+}
+
+// check reports an error unless the run was started in mode on a board of size with the same participants and bot
+// specs, in the same order, and every game of it was played on such a board
+func (run *eventRun) check(mode string, size engine.BoardConfig, participants, specs []string) error {
+	if run == nil {
+		return nil
+	}
+	header := run.header
+	if header == nil || header.Board == nil {
+		return fmt.Errorf("the resumed event log does not say which bots played the run")
+	}
+	if header.Mode != mode {
+		return fmt.Errorf("the resumed run was a %s run, not %s", header.Mode, mode)
+	}
+	if !slices.Equal(header.Participants, participants) || !slices.Equal(header.Bots, specs) {
+		return fmt.Errorf("the resumed run was played by %s, not %s", describeParticipants(header.Participants, header.Bots), describeParticipants(participants, specs))
+	}
+	for number, game := range run.games {
+		if game.record.Board != size {
+			logged := game.record.Board
+			return fmt.Errorf("game %d of the resumed run was played on a %dx%dx%d board with %d in a row, not this %dx%dx%d one with %d",
+				number, logged.Length, logged.Width, logged.Height, logged.Win, size.Length, size.Width, size.Height, size.Win)
+		}
+	}
+	if logged := *header.Board; logged != size {
+		return fmt.Errorf("the resumed run was played on a %dx%dx%d board with %d in a row, not this %dx%dx%d one with %d",
+			logged.Length, logged.Width, logged.Height, logged.Win, size.Length, size.Width, size.Height, size.Win)
+	}
+	return nil
+}
+
+// describeParticipants lists participants with their bot specs, e.g. "Bot1 (alphabeta:depth=4), Bot2 (random)"
+func describeParticipants(participants, specs []string) string {
+	described := make([]string, len(participants))
+	for i, name := range participants {
+		described[i] = name
+		if i < len(specs) {
+			described[i] += " (" + specs[i] + ")"
+		}
+	}
+	return strings.Join(described, ", ")
+}
+
+// finished returns the result of game number if the run finished it; a nil run finished none
+func (run *eventRun) finished(number int) (formats.GameResult, bool) {
+	if run == nil || run.games[number] == nil || run.games[number].result == nil {
+		return formats.GameResult{}, false
+	}
+	return *run.games[number].result, true
+}
+
+// inProgress returns the record of game number if the run left it in progress, or nil
+func (run *eventRun) inProgress(number int) *formats.GameRecord {
+	if run == nil || run.games[number] == nil || run.games[number].result != nil {
+		return nil
+	}
+	return &run.games[number].record
+}
+
+// board returns a board for game number to be played on: a fresh copy of board, with the moves the run played in it
+// if it left it in progress
+func (run *eventRun) board(number int, board *engine.Board) *engine.Board {
+	fresh := freshBoard(board)
+	if record := run.inProgress(number); record != nil {
+		for _, move := range record.Moves {
+			fresh.Move(move, fresh.NextPlayer())
+		}
+	}
+	return fresh
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"tic-tac-toe-3d-bots/bots"
+)
+
+func TestEventRunSeed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	log := `{"type":"run_started","game":0,"mode":"eve","board":{"length":3,"width":3,"height":3,"win":3},"participants":["a","b"],"bots":["random","random"],"seed":42}
+{"type":"game_started","game":1,"mode":"eve","board":{"length":3,"width":3,"height":3,"win":3},"players":["a","b"]}
+{"type":"move","game":1,"ply":1,"player":"x","move":"A1"}
+{"type":"game_over","game":1,"winner":"x","reason":"forfeit"}
+`
+	if err := os.WriteFile(path, []byte(log), 0644); err != nil {
+		t.Fatal(err)
+	}
+	run, err := loadEventRun(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result, ok := run.finished(1); !ok || result.Seed != 42 {
+		t.Errorf("finished game: seed %d, %v, want 42", result.Seed, ok)
+	}
+
+	seed := bots.RunSeed
+	t.Cleanup(func() { bots.SetSeed(seed) })
+	if err := run.restoreSeed(7); err == nil {
+		t.Errorf("restoreSeed with another --seed: got no error")
+	}
+	if err := run.restoreSeed(0); err != nil || bots.RunSeed != 42 {
+		t.Errorf("restoreSeed = %v, run seed %d, want the logged 42", err, bots.RunSeed)
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"tic-tac-toe-3d-bots/bots"
+	"tic-tac-toe-3d-bots/engine"
+	"tic-tac-toe-3d-bots/formats"
+)
+
+// Event types written to the event log
+const (
+	EventRunStarted  = "run_started"
+	EventGameStarted = "game_started"
+	EventMove        = "move"
+	EventSearchStats = "search_stats"
+	EventGameOver    = "game_over"
+)
+
+// GameEvent is one line of the JSON Lines event log
+// Fields that do not apply to an event type are left out
+type GameEvent struct {
+	Type string    `json:"type"` // one of the Event* constants
+	Time time.Time `json:"time"`
+	Game int       `json:"game"` // number of the game within this run, from 1; 0 for run_started
+
+	// run_started and game_started
+	Mode  string              `json:"mode,omitempty"`
+	Board *engine.BoardConfig `json:"board,omitempty"`
+
+	// run_started
+	Participants []string `json:"participants,omitempty"` // names of the run's bots in schedule order, the 'x' bot first in eve
+	Bots         []string `json:"bots,omitempty"`         // bot specs of the participants, in the same order
+	Seed         int64    `json:"seed,omitempty"`         // the run's --seed, which a resumed run carries on with
+
+	// game_started
+	Players *[2]string `json:"players,omitempty"` // names of the 'x' and 'o' players
+
+	// move and search_stats
+	Ply        int     `json:"ply,omitempty"`         // 1 for the first move of the game
+	Player     string  `json:"player,omitempty"`      // "x" or "o"
+	Move       string  `json:"move,omitempty"`        // move in board notation, e.g. "A1"
+	ThinkingMS float64 `json:"thinking_ms,omitempty"` // time spent choosing the move
+	Score      *int    `json:"score,omitempty"`       // board evaluation after the move (+ favors 'x')
+
+	// search_stats
+	Nodes       int                  `json:"nodes,omitempty"`        // size of a persistent bot's search tree
+	RootSplit   *bots.RootSplitStats `json:"root_split,omitempty"`   // per-worker statistics of a root-splitting bot
+	SearchDepth int                  `json:"search_depth,omitempty"` // depth of the analysis that chose the move
+	TT          *bots.TTStats        `json:"tt,omitempty"`           // size and fill of the bot's transposition table
+
+	// game_over
+	Winner string `json:"winner,omitempty"` // "x", "o" or "draw"; empty if the game did not finish
+	Reason string `json:"reason,omitempty"` // how the game ended: "line", "full_board", "time" or "interrupted"
+}
+
+// EventLog writes game events as JSON Lines
+type EventLog struct {
+	mutex   sync.Mutex
+	encoder *json.Encoder
+	closer  io.Closer // nil when writing to stdout
+}
+
+// eventLog receives the events of every game when set with --events; nil disables logging
+var eventLog *EventLog
+
+// openEventLog opens the event log at path, appending to an existing file; "-" writes to stdout
+func openEventLog(path string) (*EventLog, error) {
+	if path == "-" {
+		return &EventLog{encoder: json.NewEncoder(os.Stdout)}, nil
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &EventLog{encoder: json.NewEncoder(file), closer: file}, nil
+}
+
+// Log writes one event, stamping it with the current time; a nil log discards it
+// Write errors are ignored so that a full disk never interrupts a game
+func (log *EventLog) Log(event GameEvent) {
+	if log == nil {
+		return
+	}
+
+	event.Time = time.Now()
+	log.mutex.Lock()
+	log.encoder.Encode(event)
+	log.mutex.Unlock()
+}
+
+// Close closes the log file
+func (log *EventLog) Close() error {
+	if log == nil || log.closer == nil {
+		return nil
+	}
+	return log.closer.Close()
+}
+
+// searchStatsEvent describes the search statistics a bot exposes, if any
+func searchStatsEvent(bot bots.BotInterface) (GameEvent, bool) {
+	switch searcher := bot.(type) {
+	case *bots.ConcurrentMinimaxBot:
+		if len(searcher.Stats.WorkerNodes) == 0 {
+			return GameEvent{}, false
+		}
+		stats := searcher.Stats
+		return GameEvent{RootSplit: &stats}, true
+	case *bots.PersistentMinimaxBot:
+		nodes := searcher.NodeCount()
+		return GameEvent{Nodes: nodes}, nodes > 0
+	case ttBot:
+		stats := searcher.TTStats()
+		return GameEvent{TT: &stats}, stats.Probes > 0
+	}
+	return GameEvent{}, false
+}
+
+// logGameStart writes a game_started event (an OnGameStart observer)
+func logGameStart(start GameStart) {
+	eventLog.Log(GameEvent{Type: EventGameStarted, Game: start.Game, Mode: start.Record.Mode, Board: &start.Record.Board, Players: &start.Record.Players})
+}
+
+// logMove writes a move event (an OnMove observer)
+func logMove(move GameMove) {
+	score := move.Score
+	eventLog.Log(GameEvent{
+		Type:       EventMove,
+		Game:       move.Game,
+		Ply:        move.Ply,
+		Player:     string(move.Player),
+		Move:       move.Move,
+		ThinkingMS: formats.Milliseconds(move.Thinking),
+		Score:      &score,
+	})
+}
+
+// logGameOver writes a game_over event (an OnGameEnd observer)
+func logGameOver(end GameEnd) {
+	eventLog.Log(GameEvent{Type: EventGameOver, Game: end.Game, Winner: end.Result.Winner, Reason: end.Result.Reason})
+}
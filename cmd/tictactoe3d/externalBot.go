@@ -0,0 +1,248 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+
+	"tic-tac-toe-3d-bots/bots"
+	"tic-tac-toe-3d-bots/engine"
+)
+
+// EXTERNAL_BOT_TYPE is the profile type of bots played by an external engine
+const EXTERNAL_BOT_TYPE = "external"
+
+// EXTERNAL_ENGINE_TIMEOUT bounds an engine's answers to uci and isready, and its bestmove once told to stop
+const EXTERNAL_ENGINE_TIMEOUT = 10 * time.Second
+
+// EXTERNAL_MOVE_MARGIN is kept from a move's time limit for the engine's answer to reach the bot
+const EXTERNAL_MOVE_MARGIN = 50 * time.Millisecond
+
+// externalBotDefaults are the parameters an external bot accepts and their defaults:
+// movetime is the time per move in milliseconds (a move time limit lowers it), depth a maximum depth (0 leaves it to the engine)
+var externalBotDefaults = map[string]int{"movetime": 1000, "depth": 0}
+
+// ExternalBot plays the moves of an external executable speaking the engine protocol (see Engine),
+// started once per bot. External engines are declared as bot profiles, so they can play wherever a bot spec is accepted:
+//
+//	{"MyEngine": {"type": "external", "command": ["./my-engine", "--threads", "2"], "params": {"movetime": 500}}}
+type ExternalBot struct {
+	bots.BaseBot
+	command  []string
+	moveTime time.Duration
+	depth    int
+
+	process *exec.Cmd
+	input   io.WriteCloser
+	lines   chan string        // the engine's output, closed when it exits
+	board   engine.BoardConfig // board last set with setoption
+	moves   []string           // moves of the current game, while the bot has seen all of them
+	err     error              // set once the engine has failed; every later move fails with it
+}
+
+// newExternalBotFromProfile starts the engine of an external profile; params override the profile's own
+func newExternalBotFromProfile(profile *BotProfile, symbol byte, name string, params map[string]int) (bots.BotInterface, error) {
+	resolved := make(map[string]int, len(externalBotDefaults))
+	for key, value := range externalBotDefaults {
+		resolved[key] = value
+	}
+	for _, overrides := range []map[string]int{profile.Config.Params, params} {
+		for key, value := range overrides {
+			if _, known := externalBotDefaults[key]; !known {
+				return nil, fmt.Errorf("unknown parameter %q for bot %q", key, profile.Name)
+			}
+			resolved[key] = value
+		}
+	}
+	if resolved["movetime"] <= 0 {
+		return nil, fmt.Errorf("bot %q: movetime must be positive", profile.Name)
+	}
+	if name == "" {
+		name = profile.displayName()
+	}
+
+	bot := &ExternalBot{
+		BaseBot:  bots.NewBaseBot(symbol, name),
+		command:  profile.Config.Command,
+		moveTime: time.Duration(resolved["movetime"]) * time.Millisecond,
+		depth:    resolved["depth"],
+	}
+	if err := bot.start(); err != nil {
+		return nil, fmt.Errorf("bot %q: %v", profile.Name, err)
+	}
+	// Recreated through the profile, e.g. when a saved game is resumed
+	bot.SetConfig(&bots.BotConfig{Type: profile.Name, Name: name, Params: resolved})
+	return bot, nil
+}
+
+// start runs the engine and waits for it to identify itself
+func (bot *ExternalBot) start() error {
+	bot.process = exec.Command(bot.command[0], bot.command[1:]...)
+	input, err := bot.process.StdinPipe()
+	if err != nil {
+		return err
+	}
+	output, err := bot.process.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := bot.process.Start(); err != nil {
+		return err
+	}
+	bot.input = input
+
+	bot.lines = make(chan string, 64)
+	go func() {
+		defer close(bot.lines)
+		scanner := bufio.NewScanner(output)
+		for scanner.Scan() {
+			bot.lines <- scanner.Text()
+		}
+	}()
+
+	bot.send("uci")
+	if _, err := bot.expect("uciok", EXTERNAL_ENGINE_TIMEOUT); err != nil {
+		bot.Close()
+		return err
+	}
+	return nil
+}
+
+// send writes one command to the engine
+func (bot *ExternalBot) send(format string, args ...any) {
+	if bot.err != nil {
+		return
+	}
+	if _, err := fmt.Fprintf(bot.input, format+"\n", args...); err != nil {
+		bot.fail(err)
+	}
+}
+
+// expect reads the engine's output until a line starting with command, which it returns
+func (bot *ExternalBot) expect(command string, timeout time.Duration) ([]string, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for bot.err == nil {
+		select {
+		case line, open := <-bot.lines:
+			if !open {
+				bot.fail(errors.New("the engine exited"))
+			} else if fields := strings.Fields(line); len(fields) > 0 && fields[0] == command {
+				return fields, nil
+			}
+		case <-timer.C:
+			bot.fail(fmt.Errorf("no %q from the engine within %v", command, timeout))
+		}
+	}
+	return nil, bot.err
+}
+
+// fail records that the engine can no longer be used, and stops it
+func (bot *ExternalBot) fail(err error) {
+	if bot.err == nil {
+		bot.err = fmt.Errorf("engine %s: %v", bot.command[0], err)
+		bot.process.Process.Kill()
+	}
+}
+
+// MakeMove asks the engine for its move and plays it on the board (implements BotInterface)
+// The position is sent as the moves of the game when the bot has seen all of them, and as a snapshot otherwise
+func (bot *ExternalBot) MakeMove(ctx context.Context, board *engine.Board) (bots.Move, error) {
+	if bot.err != nil {
+		return bots.Move{}, bot.err
+	}
+	if err := ctx.Err(); err != nil {
+		return bots.Move{}, err
+	}
+
+	config := engine.BoardConfig{Length: board.Length, Width: board.Width, Height: board.Height, Win: board.WinLength}
+	if config != bot.board {
+		bot.send("setoption name Board value %dx%dx%d/%d", config.Length, config.Width, config.Height, config.Win)
+		bot.send("isready")
+		if _, err := bot.expect("readyok", EXTERNAL_ENGINE_TIMEOUT); err != nil {
+			return bots.Move{}, err
+		}
+		bot.board = config
+	}
+	if board.MoveCount() == 0 {
+		bot.moves = nil
+	}
+	if board.MoveCount() == len(bot.moves) {
+		position := "position startpos"
+		if len(bot.moves) > 0 {
+			position += " moves " + strings.Join(bot.moves, " ")
+		}
+		bot.send("%s", position)
+	} else {
+		bot.send("position snapshot %s", board.Snapshot())
+	}
+
+	moveTime := bot.moveTime
+	if deadline, ok := ctx.Deadline(); ok {
+		moveTime = max(time.Millisecond, min(moveTime, time.Until(deadline)-EXTERNAL_MOVE_MARGIN))
+	}
+	search := fmt.Sprintf("go movetime %d", moveTime.Milliseconds())
+	if bot.depth > 0 {
+		search += fmt.Sprintf(" depth %d", bot.depth)
+	}
+	bot.send("%s", search)
+
+	// The engine answers in its own time; if the move is cancelled it is told to stop, and its late answer is discarded
+	answer := make(chan []string, 1)
+	go func() {
+		fields, _ := bot.expect("bestmove", moveTime+EXTERNAL_ENGINE_TIMEOUT)
+		answer <- fields
+	}()
+	var fields []string
+	select {
+	case fields = <-answer:
+	case <-ctx.Done():
+		bot.input.Write([]byte("stop\n"))
+		<-answer
+		return bots.Move{}, ctx.Err()
+	}
+	if bot.err != nil {
+		return bots.Move{}, bot.err
+	}
+	if len(fields) < 2 || fields[1] == "(none)" {
+		return bots.Move{}, bots.ErrNoValidMoves
+	}
+
+	move, err := bots.PlayChosenMove(ctx, board, bot.Symbol(), strings.ToUpper(fields[1]))
+	if err != nil {
+		return bots.Move{}, fmt.Errorf("engine %s: %v", bot.command[0], err)
+	}
+	bot.moves = append(bot.moves, move.Name)
+	return move, nil
+}
+
+// OpponentMove keeps the game's moves, so the next position can be sent as moves (implements BotInterface)
+func (bot *ExternalBot) OpponentMove(move string) {
+	bot.moves = append(bot.moves, move)
+}
+
+// Close tells the engine to quit, stopping it if it does not (implements BotInterface)
+func (bot *ExternalBot) Close() {
+	if bot.process == nil {
+		return
+	}
+	bot.send("quit")
+	bot.input.Close()
+	exited := make(chan struct{})
+	go func() {
+		bot.process.Wait()
+		close(exited)
+	}()
+	select {
+	case <-exited:
+	case <-time.After(time.Second):
+		bot.process.Process.Kill()
+		<-exited
+	}
+	bot.process = nil
+}
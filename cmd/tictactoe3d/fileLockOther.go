@@ -0,0 +1,20 @@
+//go:build !unix && !windows
+
+package main
+
+import "os"
+
+// lockFile does nothing where advisory locks are not available: the file is only safe to share within the process
+func lockFile(file *os.File, exclusive bool) error {
+	return nil
+}
+
+// unlockFile does nothing, as lockFile took no lock
+func unlockFile(file *os.File) error {
+	return nil
+}
+
+// tryLockFile reports that it took no lock, as it cannot tell whether another process is using file
+func tryLockFile(file *os.File) (bool, error) {
+	return false, nil
+}
@@ -0,0 +1,43 @@
+//go:build windows
+
+package main
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// lockFile does nothing on Windows, whose locks are mandatory: the files locked are also read through other handles
+func lockFile(file *os.File, exclusive bool) error {
+	return nil
+}
+
+// unlockFile does nothing, as lockFile took no lock
+func unlockFile(file *os.File) error {
+	return nil
+}
+
+var procLockFileEx = syscall.NewLazyDLL("kernel32.dll").NewProc("LockFileEx")
+
+// Flags of LockFileEx, and the error of a lock held by another handle
+const (
+	LOCKFILE_FAIL_IMMEDIATELY = 0x1
+	LOCKFILE_EXCLUSIVE_LOCK   = 0x2
+	ERROR_LOCK_VIOLATION      = syscall.Errno(33)
+)
+
+// tryLockFile takes an exclusive lock on file if no other handle holds a lock on it, and reports whether it did
+// The lock is mandatory: until file is closed, only it can read or write the file
+func tryLockFile(file *os.File) (bool, error) {
+	var overlapped syscall.Overlapped
+	ok, _, err := procLockFileEx.Call(file.Fd(), LOCKFILE_EXCLUSIVE_LOCK|LOCKFILE_FAIL_IMMEDIATELY, 0, 0xFFFFFFFF, 0xFFFFFFFF,
+		uintptr(unsafe.Pointer(&overlapped)))
+	switch {
+	case ok != 0:
+		return true, nil
+	case err == ERROR_LOCK_VIOLATION:
+		return false, nil
+	}
+	return false, err
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"tic-tac-toe-3d-bots/formats"
+)
+
+// GameArchive appends every finished game to a binary archive, which holds millions of self-play games in a fraction
+// of the space of saved games
+type GameArchive struct {
+	mutex  sync.Mutex
+	writer *formats.BinaryWriter
+}
+
+// gameArchive receives every finished game when set with --archive; nil disables the archive
+var gameArchive *GameArchive
+
+// openGameArchive opens the archive at path, creating it if needed; games are added after the ones it holds
+func openGameArchive(path string) (*GameArchive, error) {
+	writer, err := formats.AppendBinaryArchive(path, formats.BINARY_GAMES)
+	if err != nil {
+		return nil, err
+	}
+	return &GameArchive{writer: writer}, nil
+}
+
+// Add appends a game to the archive; a nil archive discards it
+func (archive *GameArchive) Add(record formats.GameRecord, result formats.GameResult) error {
+	if archive == nil {
+		return nil
+	}
+	archive.mutex.Lock()
+	defer archive.mutex.Unlock()
+	return archive.writer.WriteGame(formats.ArchivedGame{Record: record, Winner: result.Winner, Reason: result.Reason})
+}
+
+// Close closes the archive's file
+func (archive *GameArchive) Close() error {
+	return archive.writer.Close()
+}
+
+// archiveGame adds a finished game to the archive
+func archiveGame(end GameEnd) {
+	if err := gameArchive.Add(end.Record, end.Result); err != nil {
+		fmt.Fprintln(os.Stderr, msg("archive.error"), err)
+	}
+}
+
+// loadArchivedGame reads the number-th game, counting from 1, of the archive at path
+func loadArchivedGame(path string, number int) (*formats.ArchivedGame, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	reader, err := formats.NewBinaryReader(file, formats.BINARY_GAMES)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	for i := 1; ; i++ {
+		game, err := reader.ReadGame()
+		if err == io.EOF {
+			return nil, fmt.Errorf("%s holds only %d games", path, i-1)
+		} else if err != nil {
+			return nil, fmt.Errorf("%s: game %d: %v", path, i, err)
+		}
+		if i == number {
+			return game, nil
+		}
+	}
+}
@@ -0,0 +1,442 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"tic-tac-toe-3d-bots/engine"
+	"tic-tac-toe-3d-bots/formats"
+)
+
+// StoredGame is one game of the game database: the full result with its moves and per-move statistics
+type StoredGame struct {
+	ID       int                `json:"id"` // from 1, in the order the games finished, whichever process recorded them
+	Finished time.Time          `json:"finished"`
+	Result   formats.GameResult `json:"result"`
+}
+
+// GameDB is an embedded database of finished games, set with --db
+// Games are appended to the file as one JSON record per line, so recording a game never rewrites earlier ones. Two
+// indexes are kept next to it and appended to alike, so that queries read only the games they return:
+//
+//	<file>.index      per game, by ID: the offset and length of its record and its board (gameIndexEntry)
+//	<file>.positions  per position a game reached, including the empty board: its hash and the game's ID
+//
+// Several processes can share the files: each holds a lock on the database file while it uses them (see lockFile),
+// and syncs a game's record, then its positions, then its index entry to the disk before releasing the lock. What a
+// crash leaves unfinished is dropped, and games recorded without their indexes (by a crash, or before the indexes
+// existed) are indexed, the next time the database is used
+type GameDB struct {
+	mutex     sync.Mutex
+	path      string
+	file      *os.File
+	index     *os.File
+	positions *os.File
+	games     int                         // games indexed so far
+	end       int64                       // length of their records
+	boards    map[engine.BoardConfig]bool // boards they were played on
+}
+
+// Extensions of the index files kept next to a game database
+const (
+	GAME_INDEX_EXTENSION     = ".index"
+	POSITION_INDEX_EXTENSION = ".positions"
+)
+
+// gameIndexEntry is an entry of a game database's index: where the game's record is, and the board it was played on
+// It is GAME_INDEX_ENTRY bytes: the offset and the length as little-endian uint64 and uint32, then the board's length,
+// width, height and win length as a byte each, as in Board.PositionHash
+type gameIndexEntry struct {
+	offset int64
+	length int
+	board  engine.BoardConfig
+}
+
+// Sizes of the entries of the game and position indexes
+const (
+	GAME_INDEX_ENTRY     = 16
+	POSITION_INDEX_ENTRY = 12 // the position's hash as a little-endian uint64, then the game's ID as a uint32
+)
+
+// gameDB receives every game when set with --db; nil disables the database
+var gameDB *GameDB
+
+// openGameDB opens the database at path with its indexes, creating them if needed
+func openGameDB(path string) (*GameDB, error) {
+	db := &GameDB{path: path, boards: make(map[engine.BoardConfig]bool)}
+	var err error
+	for file, name := range map[**os.File]string{&db.file: path, &db.index: path + GAME_INDEX_EXTENSION, &db.positions: path + POSITION_INDEX_EXTENSION} {
+		if *file, err = os.OpenFile(name, os.O_CREATE|os.O_RDWR, 0644); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+	if err := db.lock(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	db.unlock()
+	return db, nil
+}
+
+// lock takes the database's lock, then brings this process up to date with the games others have recorded, and
+// finishes or drops what a crash left unfinished; unlock releases it
+func (db *GameDB) lock() error {
+	if err := lockFile(db.file, true); err != nil {
+		return err
+	}
+	if err := db.refresh(); err != nil {
+		unlockFile(db.file)
+		return err
+	}
+	return nil
+}
+
+func (db *GameDB) unlock() {
+	unlockFile(db.file)
+}
+
+// refresh reads the index entries written since this process last did, drops the positions indexed for a game whose
+// index entry was never written, and indexes the complete records the index does not cover. A record left unfinished
+// is dropped. The caller holds the lock
+func (db *GameDB) refresh() error {
+	info, err := db.index.Stat()
+	if err != nil {
+		return err
+	}
+	indexed := int(info.Size() / GAME_INDEX_ENTRY)
+	if err := db.index.Truncate(int64(indexed) * GAME_INDEX_ENTRY); err != nil {
+		return err
+	}
+	for ; db.games < indexed; db.games++ {
+		entry, err := db.entry(db.games + 1)
+		if err != nil {
+			return err
+		}
+		db.end = entry.offset + int64(entry.length)
+		db.boards[entry.board] = true
+	}
+	if err := db.trimPositions(); err != nil {
+		return err
+	}
+
+	// Index the complete records after the last indexed one
+	if _, err := db.file.Seek(db.end, io.SeekStart); err != nil {
+		return err
+	}
+	reader := bufio.NewReader(db.file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			break // Anything after the last newline is an unfinished record
+		} else if err != nil {
+			return err
+		}
+		game := StoredGame{}
+		if err := json.Unmarshal(bytes.TrimSpace(line), &game); err != nil {
+			return fmt.Errorf("%s: record %d: %v", db.path, db.games+1, err)
+		}
+		if game.ID != db.games+1 {
+			return fmt.Errorf("%s: record %d has ID %d", db.path, db.games+1, game.ID)
+		}
+		if err := db.addToIndexes(&game, db.end, len(line)); err != nil {
+			return err
+		}
+	}
+	// Writers hold the lock until their record is complete, so an unfinished one was cut short by a crash
+	return db.file.Truncate(db.end)
+}
+
+// entry reads the index entry of the game with the given ID
+func (db *GameDB) entry(id int) (gameIndexEntry, error) {
+	data := make([]byte, GAME_INDEX_ENTRY)
+	if _, err := db.index.ReadAt(data, int64(id-1)*GAME_INDEX_ENTRY); err != nil {
+		return gameIndexEntry{}, fmt.Errorf("%s: index entry %d: %v", db.path, id, err)
+	}
+	return gameIndexEntry{
+		offset: int64(binary.LittleEndian.Uint64(data)),
+		length: int(binary.LittleEndian.Uint32(data[8:])),
+		board:  engine.BoardConfig{Length: int(data[12]), Width: int(data[13]), Height: int(data[14]), Win: int(data[15])},
+	}, nil
+}
+
+// trimPositions drops the positions indexed after the last game of the index, which a crash kept from being indexed
+func (db *GameDB) trimPositions() error {
+	info, err := db.positions.Stat()
+	if err != nil {
+		return err
+	}
+	size := info.Size() / POSITION_INDEX_ENTRY * POSITION_INDEX_ENTRY
+	data := make([]byte, POSITION_INDEX_ENTRY)
+	for size > 0 {
+		if _, err := db.positions.ReadAt(data, size-POSITION_INDEX_ENTRY); err != nil {
+			return err
+		}
+		if int(binary.LittleEndian.Uint32(data[8:])) <= db.games {
+			break
+		}
+		size -= POSITION_INDEX_ENTRY
+	}
+	return db.positions.Truncate(size)
+}
+
+// addToIndexes indexes game, whose record of length bytes is at offset: its positions, then its index entry, each
+// synced to the disk. The caller holds the lock
+func (db *GameDB) addToIndexes(game *StoredGame, offset int64, length int) error {
+	board := game.Result.Board
+	hashes := game.positionHashes()
+	positions := make([]byte, 0, len(hashes)*POSITION_INDEX_ENTRY)
+	for _, hash := range hashes {
+		positions = binary.LittleEndian.AppendUint64(positions, hash)
+		positions = binary.LittleEndian.AppendUint32(positions, uint32(game.ID))
+	}
+	info, err := db.positions.Stat()
+	if err != nil {
+		return err
+	}
+	if _, err := db.positions.WriteAt(positions, info.Size()); err != nil {
+		return err
+	}
+	if err := db.positions.Sync(); err != nil {
+		return err
+	}
+
+	entry := binary.LittleEndian.AppendUint64(make([]byte, 0, GAME_INDEX_ENTRY), uint64(offset))
+	entry = binary.LittleEndian.AppendUint32(entry, uint32(length))
+	entry = append(entry, byte(board.Length), byte(board.Width), byte(board.Height), byte(board.Win))
+	if _, err := db.index.WriteAt(entry, int64(game.ID-1)*GAME_INDEX_ENTRY); err != nil {
+		return err
+	}
+	if err := db.index.Sync(); err != nil {
+		return err
+	}
+	db.games, db.end = game.ID, offset+int64(length)
+	db.boards[board] = true
+	return nil
+}
+
+// positionHashes returns the hashes of the positions the game reached, including the empty board, each once
+// A game whose moves cannot be replayed reaches only the positions before the first move that cannot
+func (game *StoredGame) positionHashes() []uint64 {
+	board, err := engine.New(engine.WithConfig(game.Result.Board))
+	if err != nil {
+		return nil
+	}
+	hashes := []uint64{board.PositionHash()}
+	seen := map[uint64]bool{hashes[0]: true}
+	symbol := byte('x')
+	for _, move := range game.Result.Moves {
+		if coords := board.Move(move.Move, symbol); coords[0] == -1 {
+			break
+		}
+		if hash := board.PositionHash(); !seen[hash] {
+			hashes = append(hashes, hash)
+			seen[hash] = true
+		}
+		symbol = engine.OpponentSymbol(symbol)
+	}
+	return hashes
+}
+
+// Add records a finished game and returns its ID, one more than the last recorded by any process; a nil database
+// discards it
+func (db *GameDB) Add(result formats.GameResult) (int, error) {
+	if db == nil {
+		return 0, nil
+	}
+
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	if err := db.lock(); err != nil {
+		return 0, err
+	}
+	defer db.unlock()
+
+	game := StoredGame{ID: db.games + 1, Finished: time.Now(), Result: result}
+	data, err := json.Marshal(game)
+	if err != nil {
+		return 0, err
+	}
+	data = append(data, '\n')
+	if _, err := db.file.WriteAt(data, db.end); err != nil {
+		return 0, err
+	}
+	if err := db.file.Sync(); err != nil {
+		return 0, err
+	}
+	if err := db.addToIndexes(&game, db.end, len(data)); err != nil {
+		return 0, err
+	}
+	return game.ID, nil
+}
+
+// read reads the record of the game of an index entry
+func (db *GameDB) read(id int, entry gameIndexEntry) (StoredGame, error) {
+	data := make([]byte, entry.length)
+	if _, err := db.file.ReadAt(data, entry.offset); err != nil {
+		return StoredGame{}, fmt.Errorf("%s: game %d: %v", db.path, id, err)
+	}
+	game := StoredGame{}
+	if err := json.Unmarshal(bytes.TrimSpace(data), &game); err != nil {
+		return StoredGame{}, fmt.Errorf("%s: game %d: %v", db.path, id, err)
+	}
+	return game, nil
+}
+
+// Game returns the game with the given ID, found through the index; ok is false if there is none
+func (db *GameDB) Game(id int) (game *StoredGame, ok bool, err error) {
+	games, err := db.GamesByID([]int{id})
+	if err != nil || len(games) == 0 {
+		return nil, false, err
+	}
+	return &games[0], true, nil
+}
+
+// GamesByID returns the games with the given IDs that exist, in the order of ids, read through the index
+func (db *GameDB) GamesByID(ids []int) ([]StoredGame, error) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	if err := db.lock(); err != nil {
+		return nil, err
+	}
+	defer db.unlock()
+
+	games := make([]StoredGame, 0, len(ids))
+	for _, id := range ids {
+		if id < 1 || id > db.games {
+			continue
+		}
+		entry, err := db.entry(id)
+		if err != nil {
+			return nil, err
+		}
+		game, err := db.read(id, entry)
+		if err != nil {
+			return nil, err
+		}
+		games = append(games, game)
+	}
+	return games, nil
+}
+
+// Reaching returns the IDs of the games that reached a position with one of the hashes, in order, found through the
+// position index
+func (db *GameDB) Reaching(hashes map[uint64]bool) ([]int, error) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	if err := db.lock(); err != nil {
+		return nil, err
+	}
+	defer db.unlock()
+
+	if _, err := db.positions.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	reader := bufio.NewReader(db.positions)
+	var ids []int
+	data := make([]byte, POSITION_INDEX_ENTRY)
+	for {
+		if _, err := io.ReadFull(reader, data); errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return ids, nil
+		} else if err != nil {
+			return nil, err
+		}
+		// A game's positions are indexed together, once each, so its ID comes up at most once per hash
+		id := int(binary.LittleEndian.Uint32(data[8:]))
+		if hashes[binary.LittleEndian.Uint64(data)] && (len(ids) == 0 || ids[len(ids)-1] != id) {
+			ids = append(ids, id)
+		}
+	}
+}
+
+// Boards returns the boards the recorded games were played on
+func (db *GameDB) Boards() ([]engine.BoardConfig, error) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	if err := db.lock(); err != nil {
+		return nil, err
+	}
+	defer db.unlock()
+
+	boards := make([]engine.BoardConfig, 0, len(db.boards))
+	for board := range db.boards {
+		boards = append(boards, board)
+	}
+	return boards, nil
+}
+
+// Games returns the recorded games for which keep returns true, oldest first; a nil keep returns them all
+// The records are read one at a time, so only the games kept are held in memory
+func (db *GameDB) Games(keep func(*StoredGame) bool) ([]StoredGame, error) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	if err := db.lock(); err != nil {
+		return nil, err
+	}
+	defer db.unlock()
+
+	var games []StoredGame
+	reader := bufio.NewReader(io.NewSectionReader(db.file, 0, db.end))
+	for id := 1; id <= db.games; id++ {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			return nil, fmt.Errorf("%s: game %d: %v", db.path, id, err)
+		}
+		game := StoredGame{}
+		if err := json.Unmarshal(bytes.TrimSpace(line), &game); err != nil {
+			return nil, fmt.Errorf("%s: game %d: %v", db.path, id, err)
+		}
+		if keep == nil || keep(&game) {
+			games = append(games, game)
+		}
+	}
+	return games, nil
+}
+
+// Close closes the database and index files
+func (db *GameDB) Close() error {
+	if db == nil {
+		return nil
+	}
+	var err error
+	for _, file := range []*os.File{db.positions, db.index, db.file} {
+		if file != nil {
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+		}
+	}
+	return err
+}
+
+// rated converts a stored game to the rating history's form; ok is false for a game that did not finish
+// Names and kinds of the players are returned along with it for players the stats store does not know
+func (game *StoredGame) rated() (rated RatedGame, players [2]PlayerRating, ok bool) {
+	result := game.Result
+	if result.Winner == "" {
+		return RatedGame{}, players, false
+	}
+
+	rated = RatedGame{Time: game.Finished, Mode: result.Mode, Board: result.Board, Winner: result.Winner}
+	for i := range rated.Players {
+		key, kind, name := ratingKey(result.Bots[i], result.Players[i])
+		rated.Players[i] = key
+		players[i] = PlayerRating{Name: name, Kind: kind}
+	}
+	return rated, players, true
+}
+
+// storeGame adds a game to the game database, if any (an OnGameEnd observer)
+func storeGame(end GameEnd) {
+	if _, err := gameDB.Add(end.Result); err != nil {
+		fmt.Fprintln(os.Stderr, msg("db.error"), err)
+	}
+}
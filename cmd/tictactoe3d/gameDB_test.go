@@ -0,0 +1,153 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"tic-tac-toe-3d-bots/engine"
+	"tic-tac-toe-3d-bots/formats"
+)
+
+// testGame returns the result of a game on board with the given moves, won by 'x'
+func testGame(board engine.BoardConfig, moves ...string) formats.GameResult {
+	result := formats.GameResult{Mode: "eve", Board: board, Players: [2]string{"a", "b"}, Winner: "x"}
+	for i, move := range moves {
+		result.Moves = append(result.Moves, formats.PlayedMove{Ply: i + 1, Player: string("xo"[i%2]), Move: move})
+	}
+	return result
+}
+
+// testPositionHash returns the hash of the position moves lead to on board
+func testPositionHash(t *testing.T, board engine.BoardConfig, moves ...string) uint64 {
+	t.Helper()
+	record := formats.GameRecord{Board: board, Moves: moves}
+	position, err := record.Replay(len(moves))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return position.PositionHash()
+}
+
+// gameIDs returns the IDs of games
+func gameIDs(games []StoredGame) []int {
+	ids := []int{}
+	for _, game := range games {
+		ids = append(ids, game.ID)
+	}
+	return ids
+}
+
+func TestGameDBIndexes(t *testing.T) {
+	small := engine.BoardConfig{Length: 3, Width: 3, Height: 3, Win: 3}
+	large := engine.BoardConfig{Length: 4, Width: 4, Height: 4, Win: 4}
+	results := []formats.GameResult{
+		testGame(large, "A1", "B2", "C3"),
+		testGame(small, "A1", "B2"),
+		testGame(large, "C3", "B2", "A1"),
+		testGame(large, "D4"),
+	}
+
+	path := filepath.Join(t.TempDir(), "games.jsonl")
+	db, err := openGameDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, result := range results {
+		if id, err := db.Add(result); err != nil || id != i+1 {
+			t.Fatalf("Add of game %d = %d, %v", i+1, id, err)
+		}
+	}
+	db.Close()
+
+	tests := []struct {
+		name    string
+		prepare func(t *testing.T)
+	}{
+		{"reopened", func(t *testing.T) {}},
+		{"without indexes", func(t *testing.T) {
+			for _, extension := range []string{GAME_INDEX_EXTENSION, POSITION_INDEX_EXTENSION} {
+				if err := os.Remove(path + extension); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}},
+		{"unfinished index entry", func(t *testing.T) { truncateBy(t, path+GAME_INDEX_EXTENSION, GAME_INDEX_ENTRY+3) }},
+		{"positions without an index entry", func(t *testing.T) {
+			truncateBy(t, path+GAME_INDEX_EXTENSION, GAME_INDEX_ENTRY)
+			truncateBy(t, path+POSITION_INDEX_EXTENSION, 5)
+		}},
+		{"unfinished record", func(t *testing.T) {
+			file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+			file.WriteString(`{"id":5,"finished":`)
+			file.Close()
+		}},
+	}
+	for _, test := range tests {
+		test.prepare(t)
+		db, err := openGameDB(path)
+		if err != nil {
+			t.Fatalf("%s: openGameDB: %v", test.name, err)
+		}
+
+		game, ok, err := db.Game(3)
+		if err != nil || !ok || game.ID != 3 || game.Result.Moves[0].Move != "C3" {
+			t.Errorf("%s: Game(3) = %+v, %v, %v, want game 3", test.name, game, ok, err)
+		}
+		if _, ok, err := db.Game(5); ok || err != nil {
+			t.Errorf("%s: Game(5) = %v, %v, want no game", test.name, ok, err)
+		}
+
+		reaching := []struct {
+			hashes map[uint64]bool
+			want   []int
+		}{
+			{map[uint64]bool{testPositionHash(t, large, "A1", "B2", "C3"): true}, []int{1, 3}},
+			{map[uint64]bool{testPositionHash(t, large): true, testPositionHash(t, small): true}, []int{1, 2, 3, 4}},
+			{map[uint64]bool{testPositionHash(t, small, "A1", "B2"): true, testPositionHash(t, large, "D4"): true}, []int{2, 4}},
+			{map[uint64]bool{testPositionHash(t, small, "A1", "C3"): true}, nil},
+		}
+		for _, reach := range reaching {
+			if ids, err := db.Reaching(reach.hashes); err != nil || !reflect.DeepEqual(ids, reach.want) {
+				t.Errorf("%s: Reaching = %v, %v, want %v", test.name, ids, err, reach.want)
+			}
+		}
+
+		if games, err := db.Games(nil); err != nil || !reflect.DeepEqual(gameIDs(games), []int{1, 2, 3, 4}) {
+			t.Errorf("%s: Games = %v, %v, want games 1 to 4", test.name, gameIDs(games), err)
+		}
+		if boards, err := db.Boards(); err != nil || len(boards) != 2 {
+			t.Errorf("%s: Boards = %v, %v, want 2 boards", test.name, boards, err)
+		}
+		db.Close()
+	}
+
+	// Games recorded after the recovery carry on the IDs
+	db, err = openGameDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if id, err := db.Add(testGame(small, "B2")); err != nil || id != 5 {
+		t.Fatalf("Add after the recovery = %d, %v, want 5", id, err)
+	}
+	if ids, err := db.Reaching(map[uint64]bool{testPositionHash(t, small, "B2"): true}); err != nil || !reflect.DeepEqual(ids, []int{5}) {
+		t.Errorf("Reaching the new game's position = %v, %v, want [5]", ids, err)
+	}
+}
+
+// truncateBy cuts the last n bytes off the file at path, as a crash while writing them would
+func truncateBy(t *testing.T, path string, n int64) {
+	t.Helper()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Truncate(path, info.Size()-n); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"tic-tac-toe-3d-bots/engine"
+	"tic-tac-toe-3d-bots/formats"
+)
+
+// GameQuery selects games of the game database; zero fields match every game
+type GameQuery struct {
+	Opening  []string            // the game starts with these moves
+	Position []string            // the game reaches the position these moves lead to, in any move order
+	Hash     uint64              // the game reaches the position with this hash (see Board.PositionHash)
+	HasHash  bool                // whether Hash is set
+	Result   string              // "x", "o", "draw" or "unfinished"
+	Bot      string              // a player's name, bot type or bot spec
+	Board    *engine.BoardConfig // the game is played on this board; a zero Win matches any win length
+}
+
+// matches reports whether game is selected by the query, except for the position it reaches: those games are found
+// through the database's position index (see reaching)
+func (query *GameQuery) matches(game *StoredGame) bool {
+	result := game.Result
+	if query.Board != nil && !boardMatches(*query.Board, result.Board) {
+		return false
+	}
+
+	switch query.Result {
+	case "":
+	case "unfinished":
+		if result.Winner != "" {
+			return false
+		}
+	default:
+		if result.Winner != query.Result {
+			return false
+		}
+	}
+
+	if query.Bot != "" && !query.involves(result) {
+		return false
+	}
+
+	if len(query.Opening) > len(result.Moves) {
+		return false
+	}
+	for i, move := range query.Opening {
+		if !strings.EqualFold(result.Moves[i].Move, move) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// reaching returns the IDs of the games of db that reach the query's position, or nil if the query has none
+// The position's hash depends on the board's size, so Position is hashed on every board db has games on
+func (query *GameQuery) reaching(db *GameDB) ([]int, error) {
+	targets := make(map[uint64]bool)
+	if query.HasHash {
+		targets[query.Hash] = true
+	}
+	if len(query.Position) > 0 {
+		boards, err := db.Boards()
+		if err != nil {
+			return nil, err
+		}
+		for _, board := range boards {
+			if query.Board != nil && !boardMatches(*query.Board, board) {
+				continue
+			}
+			record := formats.GameRecord{Board: board, Moves: query.Position}
+			if position, err := record.Replay(len(query.Position)); err == nil {
+				targets[position.PositionHash()] = true
+			}
+		}
+	}
+	if len(targets) == 0 {
+		return []int{}, nil // The position cannot arise on any board
+	}
+	return db.Reaching(targets)
+}
+
+// involves reports whether one of the players is the query's bot, by name, bot type or bot spec
+func (query *GameQuery) involves(result formats.GameResult) bool {
+	for i, name := range result.Players {
+		if strings.EqualFold(name, query.Bot) {
+			return true
+		}
+		if bot := result.Bots[i]; bot != nil && (strings.EqualFold(bot.Type, query.Bot) || strings.EqualFold(bot.Spec(), query.Bot)) {
+			return true
+		}
+	}
+	return false
+}
+
+// finalHash returns the hash of the game's last position, or 0 if its moves cannot be replayed
+func (game *StoredGame) finalHash() uint64 {
+	record := game.record()
+	board, err := record.Replay(len(record.Moves))
+	if err != nil {
+		return 0
+	}
+	return board.PositionHash()
+}
+
+// record converts the stored game to a game record, as read by the replay viewer and --resume
+func (game *StoredGame) record() *formats.GameRecord {
+	return game.Result.Record()
+}
+
+// runGamesQuery implements the games command: it lists the games of a game database matching a query,
+// and can open one in the replay viewer or export it to a file the viewer reads
+func runGamesQuery(args []string, output io.Writer) error {
+	var dbPath, opening, position, hash, boardFilter, lang, exportPath string
+	var limit, replayID, exportID int
+	query := GameQuery{}
+
+	fs := flag.NewFlagSet("games", flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.StringVar(&dbPath, "db", "", "game database to search (required)")
+	fs.StringVar(&opening, "opening", "", "only games starting with these moves, e.g. \"A1 B2\"")
+	fs.StringVar(&position, "position", "", "only games reaching the position these moves lead to, in any move order")
+	fs.StringVar(&hash, "hash", "", "only games reaching the position with this hash, as printed for each game")
+	fs.StringVar(&query.Result, "result", "", "only games with this result: x, o, draw or unfinished")
+	fs.StringVar(&query.Bot, "bot", "", "only games played by this player name, bot type or bot spec")
+	fs.StringVar(&boardFilter, "board", "", "only games on this board, as LxWxH or LxWxH/win (e.g. 4x4x4)")
+	fs.IntVar(&limit, "limit", 0, "list at most this many games, the most recent ones (0 lists all)")
+	fs.IntVar(&replayID, "replay", 0, "open the game with this ID in the replay viewer")
+	fs.IntVar(&exportID, "export", 0, "write the game with this ID to a saved game file for --replay")
+	fs.StringVar(&exportPath, "to", "", "file written by --export (default game-<id>.json)")
+	fs.StringVar(&lang, "lang", "", "language for messages: "+strings.Join(availableLocales(), ", ")+" (default from TTT_LANG or LANG)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+	if dbPath == "" {
+		return fmt.Errorf("the games command needs a game database in --db")
+	}
+
+	if lang == "" {
+		lang = localeFromEnvironment()
+	}
+	if err := setLocale(lang); err != nil {
+		return err
+	}
+
+	query.Opening = strings.Fields(strings.ToUpper(opening))
+	query.Position = strings.Fields(strings.ToUpper(position))
+	if hash != "" {
+		parsed, err := strconv.ParseUint(strings.TrimPrefix(hash, "#"), 16, 64)
+		if err != nil {
+			return fmt.Errorf("invalid position hash %q", hash)
+		}
+		query.Hash, query.HasHash = parsed, true
+	}
+	switch query.Result {
+	case "", "x", "o", "draw", "unfinished":
+	default:
+		return fmt.Errorf("invalid result %q (expected x, o, draw or unfinished)", query.Result)
+	}
+	if boardFilter != "" {
+		var err error
+		if query.Board, err = engine.ParseBoardConfig(boardFilter); err != nil {
+			return err
+		}
+	}
+
+	db, err := openGameDB(dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// Open or export a single game
+	if replayID != 0 || exportID != 0 {
+		id := max(replayID, exportID)
+		game, ok, err := db.Game(id)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("%s has no game %d", dbPath, id)
+		}
+		record := game.record()
+		if replayID != 0 {
+			return replayRecord(record, fmt.Sprintf("%s #%d", dbPath, id))
+		}
+		if exportPath == "" {
+			exportPath = fmt.Sprintf("game-%d.json", id)
+		}
+		if err := record.Save(exportPath); err != nil {
+			return err
+		}
+		fmt.Print(msg("games.exported", id, exportPath))
+		return nil
+	}
+
+	var games []StoredGame
+	if len(query.Position) == 0 && !query.HasHash {
+		games, err = db.Games(query.matches)
+	} else {
+		var ids []int
+		if ids, err = query.reaching(db); err == nil {
+			games, err = db.GamesByID(ids)
+		}
+		kept := games[:0]
+		for i := range games {
+			if query.matches(&games[i]) {
+				kept = append(kept, games[i])
+			}
+		}
+		games = kept
+	}
+	if err != nil {
+		return err
+	}
+	if limit > 0 && len(games) > limit {
+		games = games[len(games)-limit:]
+	}
+	if len(games) == 0 {
+		fmt.Println(msg("games.none"))
+		return nil
+	}
+
+	for _, game := range games {
+		result := game.Result
+		outcome := msg("games.unfinished")
+		switch result.Winner {
+		case "x", "o":
+			outcome = msg("match.winner", result.Players[formats.SymbolIndex(result.Winner[0])])
+		case "draw":
+			outcome = msg("match.draw")
+		}
+		moves := make([]string, min(len(result.Moves), 6))
+		for i := range moves {
+			moves[i] = result.Moves[i].Move
+		}
+		opening := strings.Join(moves, " ")
+		if len(result.Moves) > len(moves) {
+			opening += " …"
+		}
+		board := result.Board
+		fmt.Print(msg("games.entry", game.ID, game.Finished.Local().Format("2006-01-02 15:04"), board.Length, board.Width, board.Height, board.Win,
+			result.Players[0], result.Players[1], outcome, len(result.Moves), opening, game.finalHash()))
+	}
+	fmt.Print(msg("games.count", len(games)))
+	return nil
+}
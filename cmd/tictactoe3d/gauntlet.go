@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"tic-tac-toe-3d-bots/bots"
+	"tic-tac-toe-3d-bots/engine"
+	"tic-tac-toe-3d-bots/formats"
+)
+
+// GauntletResult is the outcome of a gauntlet, written as one line of JSON with --output json
+type GauntletResult struct {
+	Candidate string       `json:"candidate"`
+	Opponents []MatchStats `json:"opponents"` // the candidate's match against each reference bot, from its point of view
+	Overall   MatchStats   `json:"overall"`   // all games together
+}
+
+// playGauntlet plays a match of games games between the candidate and each reference bot of the panel, alternating sides
+// A line is printed as each match finishes, followed by the candidate's overall score
+func playGauntlet(board *engine.Board, candidate TournamentEntrant, panel []TournamentEntrant, games, workers int, settings formats.EvESettings) GauntletResult {
+	result := GauntletResult{Candidate: candidate.Name}
+	result.Overall.Names = [2]string{candidate.Name, ""}
+
+	width := 0
+	for _, opponent := range panel {
+		width = max(width, len(opponent.Name))
+	}
+
+	fmt.Print(msg("gauntlet.begins", candidate.Name, len(panel), games))
+	for _, opponent := range panel {
+		stats := runMatch(board, candidate.newBot, opponent.newBot, games, workers, settings, nil, nil)
+		result.Opponents = append(result.Opponents, stats)
+		result.Overall.merge(stats)
+		fmt.Print(msg("gauntlet.opponent", width, opponent.Name, stats.Wins, stats.Draws, stats.Losses, stats.Score*100, stats.Margin*100))
+	}
+
+	overall := result.Overall
+	fmt.Print(msg("gauntlet.overall", candidate.Name, overall.Score*100, overall.Margin*100, overall.Games, overall.Wins, overall.Draws, overall.Losses))
+	fmt.Print(msg("seed.replay", bots.RunSeed))
+
+	csvExport.WriteGauntlet(result)
+	if JSONOutput {
+		if err := json.NewEncoder(resultOutput).Encode(result); err != nil {
+			fmt.Fprintln(os.Stderr, msg("error"), err)
+		}
+	}
+	return result
+}
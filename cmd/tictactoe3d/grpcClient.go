@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// The client side of the grpc command's protocol, for the bots that search on other machines:
+// unary calls only, over HTTP/2 without TLS like the server
+
+// newGRPCClient returns an HTTP client speaking HTTP/2 without TLS (h2c), as the grpc command does
+func newGRPCClient() *http.Client {
+	transport := &http.Transport{Protocols: &http.Protocols{}}
+	transport.Protocols.SetUnencryptedHTTP2(true)
+	return &http.Client{Transport: transport}
+}
+
+// grpcCall calls the unary method ("/package.Service/Method") of the server at host with request, returning its reply
+func grpcCall(ctx context.Context, client *http.Client, host, method string, request []byte) ([]byte, error) {
+	frame := binary.BigEndian.AppendUint32([]byte{0}, uint32(len(request)))
+	address := url.URL{Scheme: "http", Host: host, Path: method}
+	call, err := http.NewRequestWithContext(ctx, http.MethodPost, address.String(), bytes.NewReader(append(frame, request...)))
+	if err != nil {
+		return nil, err
+	}
+	call.Header.Set("Content-Type", "application/grpc")
+	call.Header.Set("TE", "trailers")
+	response, err := client.Do(call)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	reply, err := io.ReadAll(io.LimitReader(response.Body, GRPC_MAX_MESSAGE+5))
+	if err != nil {
+		return nil, err
+	}
+	status, message := response.Trailer.Get("Grpc-Status"), response.Trailer.Get("Grpc-Message")
+	if status == "" { // A call failing at once may send its status with the headers
+		status, message = response.Header.Get("Grpc-Status"), response.Header.Get("Grpc-Message")
+	}
+	if code, err := strconv.Atoi(status); err != nil || code != grpcOK {
+		if unescaped, err := url.PathUnescape(message); err == nil {
+			message = unescaped
+		}
+		return nil, fmt.Errorf("%s: grpc status %s: %s", method, status, message)
+	}
+	if len(reply) < 5 || reply[0] != 0 || int(binary.BigEndian.Uint32(reply[1:5])) != len(reply)-5 {
+		return nil, fmt.Errorf("%s: malformed reply", method)
+	}
+	return reply[5:], nil
+}
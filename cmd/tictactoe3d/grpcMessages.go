@@ -0,0 +1,210 @@
+package main
+
+import (
+	"tic-tac-toe-3d-bots/formats"
+)
+
+// The messages of engine.proto, around those of game.proto (see formats/protoMessages.go);
+// the server only decodes what clients send and encodes what it sends back
+
+// rpcPosition converts a Board message to the position the HTTP API would receive
+func rpcPosition(board formats.ProtoBoard) APIPosition {
+	return APIPosition{Board: board.Config(), Moves: board.Moves, Snapshot: board.Snapshot}
+}
+
+// rpcSearchRequest is the SearchRequest message
+type rpcSearchRequest struct {
+	Board       formats.ProtoBoard
+	Bot         string
+	Depth       int
+	TimeLimitMS int
+}
+
+func (request *rpcSearchRequest) unmarshal(data []byte) error {
+	return formats.DecodeProto(data, func(field formats.ProtoField) error {
+		switch field.Number {
+		case 1:
+			return request.Board.UnmarshalProto(field.Bytes)
+		case 2:
+			request.Bot = field.Text()
+		case 3:
+			request.Depth = int(field.Int())
+		case 4:
+			request.TimeLimitMS = int(field.Int())
+		}
+		return nil
+	})
+}
+
+// rpcSearchUpdateOf describes a line found for symbol
+func rpcSearchUpdateOf(line []string, score, depth int, symbol byte) formats.ProtoSearchUpdate {
+	return formats.ProtoSearchUpdate{Depth: depth, Score: int64(score), PV: line, WinProbability: winProbability(score, symbol), ForcedWinner: forcedWinner(score)}
+}
+
+// rpcGameStart is the GameStart message
+type rpcGameStart struct {
+	Board       formats.ProtoBoard
+	Bot         string
+	EnginePlays string
+	TimeLimitMS int
+}
+
+func (start *rpcGameStart) unmarshal(data []byte) error {
+	return formats.DecodeProto(data, func(field formats.ProtoField) error {
+		switch field.Number {
+		case 1:
+			return start.Board.UnmarshalProto(field.Bytes)
+		case 2:
+			start.Bot = field.Text()
+		case 3:
+			start.EnginePlays = field.Text()
+		case 4:
+			start.TimeLimitMS = int(field.Int())
+		}
+		return nil
+	})
+}
+
+// rpcPlayMessage is the PlayMessage message; exactly one of its fields is set
+type rpcPlayMessage struct {
+	Start *rpcGameStart
+	Move  *formats.ProtoMove
+}
+
+func (message *rpcPlayMessage) unmarshal(data []byte) error {
+	return formats.DecodeProto(data, func(field formats.ProtoField) error {
+		switch field.Number {
+		case 1:
+			message.Start, message.Move = &rpcGameStart{}, nil
+			return message.Start.unmarshal(field.Bytes)
+		case 2:
+			message.Start, message.Move = nil, &formats.ProtoMove{}
+			return message.Move.UnmarshalProto(field.Bytes)
+		}
+		return nil
+	})
+}
+
+// rpcGameState is the GameState message
+type rpcGameState struct {
+	Board        formats.ProtoBoard
+	LastMove     *formats.ProtoMove
+	Next, Winner string
+	LegalMoves   []string
+}
+
+func (state rpcGameState) marshal() []byte {
+	encoder := formats.ProtoEncoder{}
+	encoder.Bytes(1, state.Board.MarshalProto())
+	if state.LastMove != nil {
+		encoder.Bytes(2, state.LastMove.MarshalProto())
+	}
+	encoder.String(3, state.Next)
+	encoder.String(4, state.Winner)
+	encoder.Strings(5, state.LegalMoves)
+	return encoder.Data
+}
+
+// rpcGameStateOf describes the game of record, whose last move took thinking milliseconds
+func rpcGameStateOf(record *formats.GameRecord, thinking int64) rpcGameState {
+	api := apiState(0, record)
+	state := rpcGameState{Board: formats.ProtoBoardOf(record), Next: api.Next, Winner: api.Winner, LegalMoves: api.LegalMoves}
+	if len(record.Moves) > 0 {
+		player := "x"
+		if api.Next == "x" {
+			player = "o"
+		}
+		state.LastMove = &formats.ProtoMove{Name: record.Moves[len(record.Moves)-1], Player: player, ThinkingMS: thinking}
+	}
+	return state
+}
+
+// rpcGameEvent is the GameEvent message; exactly one of its fields is set
+type rpcGameEvent struct {
+	State    *rpcGameState
+	Thinking *formats.ProtoSearchUpdate
+	Error    string
+}
+
+func (event rpcGameEvent) marshal() []byte {
+	encoder := formats.ProtoEncoder{}
+	switch {
+	case event.State != nil:
+		encoder.Bytes(1, event.State.marshal())
+	case event.Thinking != nil:
+		encoder.Bytes(2, event.Thinking.MarshalProto())
+	default:
+		encoder.Bytes(3, []byte(event.Error))
+	}
+	return encoder.Data
+}
+
+// rpcSubtreeRequest is the SubtreeRequest message
+type rpcSubtreeRequest struct {
+	Board   formats.ProtoBoard
+	Move    string
+	Depth   int
+	Bound   int64
+	Bounded bool
+}
+
+func (request rpcSubtreeRequest) marshal() []byte {
+	encoder := formats.ProtoEncoder{}
+	encoder.Bytes(1, request.Board.MarshalProto())
+	encoder.String(2, request.Move)
+	encoder.Int(3, int64(request.Depth))
+	encoder.Int(4, request.Bound)
+	encoder.Bool(5, request.Bounded)
+	return encoder.Data
+}
+
+func (request *rpcSubtreeRequest) unmarshal(data []byte) error {
+	return formats.DecodeProto(data, func(field formats.ProtoField) error {
+		switch field.Number {
+		case 1:
+			return request.Board.UnmarshalProto(field.Bytes)
+		case 2:
+			request.Move = field.Text()
+		case 3:
+			request.Depth = int(field.Int())
+		case 4:
+			request.Bound = field.Int()
+		case 5:
+			request.Bounded = field.Bool()
+		}
+		return nil
+	})
+}
+
+// rpcSubtreeResult is the SubtreeResult message
+type rpcSubtreeResult struct {
+	Move      string
+	Score     int64
+	Line      []string
+	ElapsedMS int64
+}
+
+func (result rpcSubtreeResult) marshal() []byte {
+	encoder := formats.ProtoEncoder{}
+	encoder.String(1, result.Move)
+	encoder.Int(2, result.Score)
+	encoder.Strings(3, result.Line)
+	encoder.Int(4, result.ElapsedMS)
+	return encoder.Data
+}
+
+func (result *rpcSubtreeResult) unmarshal(data []byte) error {
+	return formats.DecodeProto(data, func(field formats.ProtoField) error {
+		switch field.Number {
+		case 1:
+			result.Move = field.Text()
+		case 2:
+			result.Score = field.Int()
+		case 3:
+			result.Line = append(result.Line, field.Text())
+		case 4:
+			result.ElapsedMS = field.Int()
+		}
+		return nil
+	})
+}
@@ -0,0 +1,495 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"tic-tac-toe-3d-bots/bots"
+	"tic-tac-toe-3d-bots/engine"
+	"tic-tac-toe-3d-bots/formats"
+)
+
+// DEFAULT_GRPC_ADDRESS is where the grpc command listens unless --listen says otherwise
+// The service has no authentication, so by default only local clients can reach it
+const DEFAULT_GRPC_ADDRESS = "localhost:50051"
+
+// GRPC_MAX_MESSAGE bounds the messages clients may send, like gRPC's default limit
+const GRPC_MAX_MESSAGE = 4 << 20
+
+// gRPC status codes (https://grpc.github.io/grpc/core/md_doc_statuscodes.html)
+const (
+	grpcOK                 = 0
+	grpcCanceled           = 1
+	grpcInvalidArgument    = 3
+	grpcDeadlineExceeded   = 4
+	grpcNotFound           = 5
+	grpcFailedPrecondition = 9
+	grpcUnimplemented      = 12
+	grpcInternal           = 13
+)
+
+// The grpc command serves the Engine service of engine.proto over HTTP/2 without TLS (h2c):
+// gRPC's framing is a one-byte compression flag and a four-byte length before every message,
+// and a call's status is sent in the grpc-status and grpc-message trailers
+
+// grpcError ends a call with a gRPC status
+type grpcError struct {
+	code    int
+	message string
+}
+
+func (e *grpcError) Error() string { return e.message }
+
+// grpcErrorf returns an error ending a call with code
+func grpcErrorf(code int, format string, args ...any) error {
+	return &grpcError{code: code, message: fmt.Sprintf(format, args...)}
+}
+
+// grpcStatus returns the status a call ending with err reports
+// Errors of the HTTP API keep their meaning; any other error is the client's fault
+func grpcStatus(err error) (int, string) {
+	var rpcErr *grpcError
+	var statusErr *apiError
+	switch {
+	case err == nil:
+		return grpcOK, ""
+	case errors.As(err, &rpcErr):
+		return rpcErr.code, rpcErr.message
+	case errors.Is(err, context.DeadlineExceeded):
+		return grpcDeadlineExceeded, err.Error()
+	case errors.Is(err, context.Canceled):
+		return grpcCanceled, err.Error()
+	case errors.As(err, &statusErr):
+		switch statusErr.status {
+		case http.StatusNotFound:
+			return grpcNotFound, err.Error()
+		case http.StatusConflict:
+			return grpcFailedPrecondition, err.Error()
+		case http.StatusServiceUnavailable:
+			return grpcDeadlineExceeded, err.Error()
+		case http.StatusInternalServerError:
+			return grpcInternal, err.Error()
+		}
+	}
+	return grpcInvalidArgument, err.Error()
+}
+
+// grpcStream is one call in progress, reading the client's messages and sending the server's
+type grpcStream struct {
+	ctx     context.Context
+	body    io.Reader
+	writer  http.ResponseWriter
+	writing sync.Mutex
+}
+
+// Receive returns the client's next message, or io.EOF once the client has sent all of them
+func (stream *grpcStream) Receive() ([]byte, error) {
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(stream.body, header); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+		return nil, grpcErrorf(grpcCanceled, "reading the request: %v", err)
+	}
+	if header[0] != 0 {
+		return nil, grpcErrorf(grpcUnimplemented, "compressed messages are not supported")
+	}
+	length := binary.BigEndian.Uint32(header[1:])
+	if length > GRPC_MAX_MESSAGE {
+		return nil, grpcErrorf(grpcInvalidArgument, "message of %d bytes is too large", length)
+	}
+	message := make([]byte, length)
+	if _, err := io.ReadFull(stream.body, message); err != nil {
+		return nil, grpcErrorf(grpcInvalidArgument, "truncated message: %v", err)
+	}
+	return message, nil
+}
+
+// receive decodes the client's next message with unmarshal, reporting a missing message as an error
+func (stream *grpcStream) receive(unmarshal func([]byte) error) error {
+	message, err := stream.Receive()
+	if err == io.EOF {
+		return grpcErrorf(grpcInvalidArgument, "missing request message")
+	} else if err != nil {
+		return err
+	}
+	if err := unmarshal(message); err != nil {
+		return grpcErrorf(grpcInvalidArgument, "invalid request message: %v", err)
+	}
+	return nil
+}
+
+// Send sends one message to the client
+func (stream *grpcStream) Send(message []byte) error {
+	stream.writing.Lock()
+	defer stream.writing.Unlock()
+	frame := binary.BigEndian.AppendUint32([]byte{0}, uint32(len(message)))
+	if _, err := stream.writer.Write(append(frame, message...)); err != nil {
+		return err
+	}
+	stream.writer.(http.Flusher).Flush()
+	return nil
+}
+
+// grpcHandler serves the gRPC methods, keyed by their path ("/package.Service/Method")
+func grpcHandler(methods map[string]func(*grpcStream) error) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodPost || !strings.HasPrefix(request.Header.Get("Content-Type"), "application/grpc") {
+			serverLog.Debug("rejected non-gRPC request", "method", request.Method, "path", request.URL.Path, "remote", request.RemoteAddr)
+			http.Error(writer, "expected a gRPC request", http.StatusUnsupportedMediaType)
+			return
+		}
+		writer.Header().Set("Content-Type", "application/grpc")
+		writer.Header().Set("Trailer", "Grpc-Status, Grpc-Message")
+		writer.WriteHeader(http.StatusOK)
+		writer.(http.Flusher).Flush()
+
+		started := time.Now()
+		ctx, cancel := context.WithCancel(request.Context())
+		defer cancel()
+		if timeout, ok := parseGRPCTimeout(request.Header.Get("Grpc-Timeout")); ok {
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		var err error
+		if method := methods[request.URL.Path]; method != nil {
+			err = method(&grpcStream{ctx: ctx, body: request.Body, writer: writer})
+		} else {
+			err = grpcErrorf(grpcUnimplemented, "unknown method %s", request.URL.Path)
+		}
+		if err != nil && ctx.Err() != nil {
+			err = ctx.Err()
+		}
+
+		code, message := grpcStatus(err)
+		level := slog.LevelInfo
+		if code == grpcInternal {
+			level = slog.LevelError
+		}
+		serverLog.Log(ctx, level, "grpc call", "method", request.URL.Path, "remote", request.RemoteAddr, "status", code, "error", message, "duration", time.Since(started))
+		writer.Header().Set("Grpc-Status", strconv.Itoa(code))
+		if message != "" {
+			writer.Header().Set("Grpc-Message", grpcPercentEncode(message))
+		}
+	}
+}
+
+// parseGRPCTimeout parses a grpc-timeout header: up to 8 digits and a unit (H, M, S, m, u or n)
+func parseGRPCTimeout(value string) (time.Duration, bool) {
+	if len(value) < 2 || len(value) > 9 {
+		return 0, false
+	}
+	amount, err := strconv.ParseInt(value[:len(value)-1], 10, 64)
+	if err != nil || amount < 0 {
+		return 0, false
+	}
+	units := map[byte]time.Duration{'H': time.Hour, 'M': time.Minute, 'S': time.Second, 'm': time.Millisecond, 'u': time.Microsecond, 'n': time.Nanosecond}
+	unit, ok := units[value[len(value)-1]]
+	return time.Duration(amount) * unit, ok
+}
+
+// grpcPercentEncode encodes a grpc-message: bytes outside printable ASCII, and '%', become %XX
+func grpcPercentEncode(message string) string {
+	var encoded strings.Builder
+	for i := 0; i < len(message); i++ {
+		if c := message[i]; c < ' ' || c > '~' || c == '%' {
+			fmt.Fprintf(&encoded, "%%%02X", c)
+		} else {
+			encoded.WriteByte(c)
+		}
+	}
+	return encoded.String()
+}
+
+// grpcPosition resolves a Board message to the position it describes, refusing finished games
+func grpcPosition(board formats.ProtoBoard) (*engine.Board, error) {
+	record, err := rpcPosition(board).record()
+	if err != nil {
+		return nil, err
+	}
+	position, _ := record.Replay(len(record.Moves))
+	if position.CheckWin() != '|' || position.IsFull() {
+		return nil, grpcErrorf(grpcFailedPrecondition, "the game is over")
+	}
+	return position, nil
+}
+
+// engineMethods are the methods of the Engine service
+var engineMethods = map[string]func(*grpcStream) error{
+	"/tictactoe3d.Engine/GetBestMove":    grpcGetBestMove,
+	"/tictactoe3d.Engine/StreamAnalysis": grpcStreamAnalysis,
+	"/tictactoe3d.Engine/PlayGame":       grpcPlayGame,
+	"/tictactoe3d.Engine/SearchSubtree":  grpcSearchSubtree,
+}
+
+// grpcGetBestMove implements GetBestMove: the bot's move for a position
+func grpcGetBestMove(stream *grpcStream) error {
+	request := rpcSearchRequest{}
+	if err := stream.receive(request.unmarshal); err != nil {
+		return err
+	}
+	board, err := grpcPosition(request.Board)
+	if err != nil {
+		return err
+	}
+	limit, err := timeLimit(request.TimeLimitMS)
+	if err != nil {
+		return err
+	}
+	if request.Bot == "" {
+		request.Bot = "alphabeta"
+	}
+	symbol := board.NextPlayer()
+	bot, err := engineBot(request.Bot, symbol, request.Depth)
+	if err != nil {
+		return err
+	}
+	defer bot.Close()
+
+	ctx, cancel := context.WithTimeout(stream.ctx, limit)
+	defer cancel()
+	start := time.Now()
+	move, err := searchMove(ctx, bot, board)
+	if err != nil {
+		return grpcErrorf(grpcDeadlineExceeded, "the bot did not move within %s", limit)
+	}
+	movesServed.add(1, "api", "grpc")
+	return stream.Send(formats.ProtoMove{Name: move.Name, Player: string(symbol), ThinkingMS: time.Since(start).Milliseconds()}.MarshalProto())
+}
+
+// grpcSearchSubtree implements SearchSubtree: a worker's share of a distributed search
+func grpcSearchSubtree(stream *grpcStream) error {
+	request := rpcSubtreeRequest{}
+	if err := stream.receive(request.unmarshal); err != nil {
+		return err
+	}
+	board, err := grpcPosition(request.Board)
+	if err != nil {
+		return err
+	}
+	if request.Depth < 0 {
+		return grpcErrorf(grpcInvalidArgument, "depth must not be negative")
+	}
+
+	start := time.Now()
+	score, line, err := searchSubtree(stream.ctx, board, strings.ToUpper(request.Move), request.Depth, request.Bound, request.Bounded)
+	if err != nil {
+		return err
+	}
+	return stream.Send(rpcSubtreeResult{Move: line[0], Score: int64(score), Line: line, ElapsedMS: time.Since(start).Milliseconds()}.marshal())
+}
+
+// grpcStreamAnalysis implements StreamAnalysis: the best line after every depth, then the final one
+func grpcStreamAnalysis(stream *grpcStream) error {
+	request := rpcSearchRequest{}
+	if err := stream.receive(request.unmarshal); err != nil {
+		return err
+	}
+	board, err := grpcPosition(request.Board)
+	if err != nil {
+		return err
+	}
+	if request.Depth < 0 || request.Depth > ENGINE_MAX_DEPTH {
+		return fmt.Errorf("depth must be between 1 and %d", ENGINE_MAX_DEPTH)
+	}
+	if request.Depth == 0 {
+		request.Depth = ENGINE_MAX_DEPTH
+	}
+	limit := time.Duration(request.TimeLimitMS) * time.Millisecond
+	if request.TimeLimitMS < 0 || limit > API_MAX_TIME_LIMIT {
+		return fmt.Errorf("time_limit_ms must be between 0 and %d", API_MAX_TIME_LIMIT.Milliseconds())
+	}
+
+	ctx := stream.ctx
+	if limit > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, limit)
+		defer cancel()
+	}
+	start := time.Now()
+	symbol := board.NextPlayer()
+	line, score, depth := analyzeBestWithProgress(board, symbol, request.Depth, func(line []string, score, depth int) {
+		update := rpcSearchUpdateOf(line, score, depth, symbol)
+		update.ElapsedMS = time.Since(start).Milliseconds()
+		stream.Send(update.MarshalProto())
+	}, ctx)
+	if err := stream.ctx.Err(); err != nil {
+		return err
+	}
+
+	final := rpcSearchUpdateOf(line, score, depth, symbol)
+	final.Final, final.ElapsedMS = true, time.Since(start).Milliseconds()
+	return stream.Send(final.MarshalProto())
+}
+
+// grpcPlayGame implements PlayGame: after a GameStart, the client's moves are answered by the engine's,
+// each followed by the game's state, and the engine's thinking is streamed while it chooses if its bot reports its
+// progress (see bots.ProgressReporter)
+// Rejected messages are answered with an error event; the call ends with the game, or when the client stops sending
+func grpcPlayGame(stream *grpcStream) error {
+	message := rpcPlayMessage{}
+	if err := stream.receive(message.unmarshal); err != nil {
+		return err
+	}
+	if message.Start == nil {
+		return grpcErrorf(grpcInvalidArgument, "the first message must start the game")
+	}
+	start := message.Start
+	record, err := rpcPosition(start.Board).record()
+	if err != nil {
+		return err
+	}
+	if start.EnginePlays == "" {
+		start.EnginePlays = "o"
+	}
+	if start.EnginePlays != "x" && start.EnginePlays != "o" {
+		return fmt.Errorf("engine_plays must be \"x\" or \"o\"")
+	}
+	if start.Bot == "" {
+		start.Bot = "alphabeta"
+	}
+	limit, err := timeLimit(start.TimeLimitMS)
+	if err != nil {
+		return err
+	}
+	bot, err := newAPIBot(start.Bot, start.EnginePlays[0], "")
+	if err != nil {
+		return err
+	}
+	defer bot.Close()
+
+	sendState := func(thinking int64) error {
+		state := rpcGameStateOf(record, thinking)
+		return stream.Send(rpcGameEvent{State: &state}.marshal())
+	}
+	if err := sendState(0); err != nil {
+		return err
+	}
+
+	for {
+		state := apiState(0, record)
+		if state.Winner != "" {
+			return nil
+		}
+
+		if state.Next == start.EnginePlays {
+			move, thinking, err := grpcEngineMove(stream, bot, record, limit)
+			if err != nil {
+				return err
+			}
+			record = &formats.GameRecord{Board: record.Board, Moves: append(append([]string{}, record.Moves...), move)}
+			if err := sendState(thinking); err != nil {
+				return err
+			}
+			continue
+		}
+
+		data, err := stream.Receive()
+		if err == io.EOF {
+			return nil // The client left the game
+		} else if err != nil {
+			return err
+		}
+		message := rpcPlayMessage{}
+		if err := message.unmarshal(data); err != nil || message.Move == nil {
+			stream.Send(rpcGameEvent{Error: "expected a move"}.marshal())
+			continue
+		}
+		played := &formats.GameRecord{Board: record.Board, Moves: append(append([]string{}, record.Moves...), strings.ToUpper(strings.TrimSpace(message.Move.Name)))}
+		if _, err := played.Replay(len(played.Moves)); err != nil {
+			stream.Send(rpcGameEvent{Error: fmt.Sprintf("illegal move %q", message.Move.Name)}.marshal())
+			continue
+		}
+		record = played
+		if err := sendState(0); err != nil {
+			return err
+		}
+	}
+}
+
+// grpcEngineMove asks bot for its move in the position of record within limit, streaming every iteration its search
+// reports meanwhile; if the bot runs out of time, the first move of the deepest line it reported is played instead
+func grpcEngineMove(stream *grpcStream, bot bots.BotInterface, record *formats.GameRecord, limit time.Duration) (string, int64, error) {
+	board, _ := record.Replay(len(record.Moves))
+	symbol := board.NextPlayer()
+	ctx, cancel := context.WithTimeout(stream.ctx, limit)
+	defer cancel()
+
+	start := time.Now()
+	control := startSearch(ctx, bot, board, searchOptions{MaxDepth: ENGINE_MAX_DEPTH})
+	sent := make(chan struct{})
+	go func() {
+		defer close(sent)
+		for progress := range control.Subscribe() {
+			update := rpcSearchUpdateOf(progress.Line, progress.Score, progress.Depth, symbol)
+			update.ElapsedMS = progress.Elapsed.Milliseconds()
+			stream.Send(rpcGameEvent{Thinking: &update}.marshal())
+		}
+	}()
+
+	<-control.Moved()
+	thinking := time.Since(start).Milliseconds()
+	move := control.Stop()
+	<-sent
+	if err := stream.ctx.Err(); err != nil {
+		return "", 0, err
+	}
+	return move, thinking, nil
+}
+
+// runGRPC implements the grpc command: it serves the Engine service until the program is stopped
+func runGRPC(args []string, output io.Writer) error {
+	var listen, lang string
+	fs := flag.NewFlagSet("grpc", flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.StringVar(&listen, "listen", DEFAULT_GRPC_ADDRESS, "address to serve the gRPC service on")
+	fs.StringVar(&lang, "lang", "", "language for messages: "+strings.Join(availableLocales(), ", ")+" (default from TTT_LANG or LANG)")
+	logging := addLogFlags(fs)
+	traceEndpoint := addTraceFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+
+	if lang == "" {
+		lang = localeFromEnvironment()
+	}
+	if err := setLocale(lang); err != nil {
+		return err
+	}
+	closeLog, err := logging.apply()
+	if err != nil {
+		return err
+	}
+	defer closeLog()
+	stopTracing, err := startTracing(*traceEndpoint)
+	if err != nil {
+		return err
+	}
+	defer stopTracing()
+
+	// gRPC clients connect with HTTP/2 directly, without TLS; health checks may use HTTP/1.1 as well
+	protocols := new(http.Protocols)
+	protocols.SetUnencryptedHTTP2(true)
+	protocols.SetHTTP1(true)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", healthHandler)
+	mux.HandleFunc("GET /readyz", readyHandler)
+	mux.Handle("/", grpcHandler(engineMethods))
+	server := &http.Server{Addr: listen, Handler: mux, Protocols: protocols}
+	fmt.Fprint(output, msg("grpc.listening", listen))
+	return serveGracefully(server, output)
+}
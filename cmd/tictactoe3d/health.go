@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// The serve and grpc commands answer health checks, and stop gracefully on SIGTERM or Ctrl+C:
+//
+//	GET /healthz   200 while the process is up
+//	GET /readyz    200 while new requests are welcome, 503 once the server is shutting down
+//
+// Shutting down, the server stops accepting connections and gives the requests in flight SERVER_DRAIN_TIMEOUT to finish;
+// the searches of those still running are then cancelled, and they get INTERRUPT_GRACE to answer before being cut off
+
+// SERVER_DRAIN_TIMEOUT is how long a stopping server waits for the requests in flight before cancelling their searches
+const SERVER_DRAIN_TIMEOUT = 15 * time.Second
+
+// serverDraining is set once the server has started shutting down
+var serverDraining atomic.Bool
+
+// healthHandler answers /healthz
+func healthHandler(writer http.ResponseWriter, request *http.Request) {
+	writeJSON(writer, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// readyHandler answers /readyz
+func readyHandler(writer http.ResponseWriter, request *http.Request) {
+	if serverDraining.Load() {
+		writeJSON(writer, http.StatusServiceUnavailable, map[string]string{"status": "shutting down"})
+		return
+	}
+	writeJSON(writer, http.StatusOK, map[string]string{"status": "ready"})
+}
+
+// serveGracefully runs server until it fails or the program receives SIGTERM or Ctrl+C, then shuts it down gracefully
+// Every request's context derives from one the shutdown cancels, which stops the searches of requests that outlast the drain
+func serveGracefully(server *http.Server, output io.Writer) error {
+	requests, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
+	server.BaseContext = func(net.Listener) context.Context { return requests }
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, os.Interrupt)
+	defer signal.Stop(signals)
+
+	failed := make(chan error, 1)
+	go func() { failed <- server.ListenAndServe() }()
+	var received os.Signal
+	select {
+	case err := <-failed:
+		return err
+	case received = <-signals:
+	}
+
+	serverDraining.Store(true)
+	serverLog.Info("shutting down", "signal", received.String(), "drain_timeout", SERVER_DRAIN_TIMEOUT)
+	fmt.Fprint(output, msg("serve.stopping", SERVER_DRAIN_TIMEOUT))
+
+	drain, cancelDrain := context.WithTimeout(context.Background(), SERVER_DRAIN_TIMEOUT)
+	defer cancelDrain()
+	err := server.Shutdown(drain)
+	if errors.Is(err, context.DeadlineExceeded) {
+		serverLog.Warn("requests still in flight after draining, cancelling their searches")
+		cancelRequests()
+		grace, cancelGrace := context.WithTimeout(context.Background(), INTERRUPT_GRACE)
+		defer cancelGrace()
+		if err = server.Shutdown(grace); err != nil {
+			serverLog.Warn("closing the connections of requests that did not stop", "error", err)
+			err = server.Close()
+		}
+	}
+	serverLog.Info("stopped")
+	return err
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"tic-tac-toe-3d-bots/bots"
+	"tic-tac-toe-3d-bots/engine"
+	"tic-tac-toe-3d-bots/formats"
+)
+
+// AUTOSAVE_FILE receives the record of a game interrupted with Ctrl+C
+const AUTOSAVE_FILE = "autosave.json"
+
+// INTERRUPT_GRACE is how long in-flight bot searches get to stop after Ctrl+C before the program exits anyway
+const INTERRUPT_GRACE = 2 * time.Second
+
+// GameSession is the game currently being played
+// Game loops search with its context, which is cancelled on Ctrl+C, and record every move they play
+type GameSession struct {
+	ctx         context.Context
+	cancel      context.CancelFunc
+	interrupted atomic.Bool
+	searches    sync.WaitGroup // bot searches in flight
+
+	mutex  sync.Mutex
+	record formats.GameRecord // Clocks are kept in clocks while the game runs
+	clocks [2]time.Duration   // thinking time used by 'x' and 'o'
+
+	board    *engine.Board
+	moves    []formats.PlayedMove // every move with its timing, for JSON output
+	game     int                  // number of the game within this run, from 1
+	winner   byte                 // result set by SetResult, or 0 to take it from the board
+	reason   string               // how the game ended, set along with winner
+	finished sync.Once            // the result is reported once, by End or the interrupt handler
+	outcome  formats.GameResult   // the result, once finished
+	ttFill   [2]float64           // fill rate of the transposition table of each bot keeping one, after its last move
+}
+
+var (
+	activeSessions      = make(map[*GameSession]bool) // games in progress; several while a match runs in parallel
+	activeSessionsMutex sync.Mutex
+	gamesStarted        atomic.Int64 // games numbered so far: those started on their own and the blocks matches reserve
+)
+
+// startGame registers a new game on board as the one in progress; the caller must End it when the game loop returns
+// record describes the players and settings; its board and history are filled in by the session
+// If a saved game of the same mode is being resumed, or the event log of a resumed run has the game in progress, its
+// moves and clocks carry over
+func startGame(board *engine.Board, record formats.GameRecord) *GameSession {
+	ctx, cancel := context.WithCancel(context.Background())
+	record.Board = engine.BoardConfig{Length: board.Length, Width: board.Width, Height: board.Height, Win: board.WinLength}
+	record.Moves = nil
+	session := &GameSession{
+		ctx:    ctx,
+		cancel: cancel,
+		board:  board,
+	}
+	if record.EvE != nil && record.EvE.Game > 0 {
+		session.game = record.EvE.Game // Numbered by the schedule of its run
+	} else {
+		session.game = int(gamesStarted.Add(1))
+	}
+
+	resumed := resumedRun.inProgress(session.game)
+	if resumedGame != nil && resumedGame.Mode == record.Mode {
+		resumed = resumedGame
+		resumedGame = nil // Rematches start from scratch
+	}
+	if resumed != nil && resumed.Mode == record.Mode {
+		record.Moves = append(record.Moves, resumed.Moves...)
+		for i, move := range resumed.Moves {
+			session.moves = append(session.moves, formats.PlayedMove{Ply: i + 1, Player: string("xo"[i%2]), Move: move})
+		}
+		session.clocks = resumed.ThinkingTimes()
+	}
+	session.record = record
+
+	activeSessionsMutex.Lock()
+	activeSessions[session] = true
+	activeSessionsMutex.Unlock()
+
+	startObservers.publish(GameStart{Game: session.game, Record: session.Record()})
+	return session
+}
+
+// End marks the game as finished
+// An interrupted game never returns from End, so nothing more is printed while the interrupt handler saves it
+func (session *GameSession) End() {
+	activeSessionsMutex.Lock()
+	delete(activeSessions, session)
+	activeSessionsMutex.Unlock()
+
+	session.cancel()
+	session.finish()
+	if session.Interrupted() {
+		select {} // The interrupt handler exits the program
+	}
+}
+
+// SetResult records a result that cannot be read from the board, such as a loss on time
+// winner is 'x', 'o' or '|' for a draw
+func (session *GameSession) SetResult(winner byte, reason string) {
+	session.mutex.Lock()
+	session.winner, session.reason = winner, reason
+	session.mutex.Unlock()
+}
+
+// finish publishes the result to the OnGameEnd observers
+// The result comes from the board unless SetResult was called
+func (session *GameSession) finish() {
+	session.finished.Do(func() {
+		session.mutex.Lock()
+		winner, reason := session.winner, session.reason
+		session.mutex.Unlock()
+
+		switch {
+		case session.Interrupted():
+			winner, reason = 0, "interrupted"
+		case winner != 0:
+		case session.board.CheckWin() != '|':
+			winner, reason = session.board.CheckWin(), "line"
+		default:
+			winner, reason = '|', "full_board"
+		}
+
+		winnerName := ""
+		switch winner {
+		case 'x', 'o':
+			winnerName = string(winner)
+		case '|':
+			winnerName = "draw"
+		}
+		session.outcome = session.result(winnerName, reason)
+		endObservers.publish(GameEnd{Game: session.game, Record: session.Record(), Winner: winner, Result: session.outcome})
+	})
+}
+
+// Result returns the summary of the game; only complete once End has returned
+func (session *GameSession) Result() formats.GameResult {
+	session.finish()
+	return session.outcome
+}
+
+// Interrupted reports whether the game was stopped with Ctrl+C
+func (session *GameSession) Interrupted() bool {
+	return session.interrupted.Load()
+}
+
+// searchContext returns the context for one bot search and a function to call once the search has returned
+// The interrupt handler waits for searches in flight to stop before saving the game
+func (session *GameSession) searchContext() (context.Context, func()) {
+	session.searches.Add(1)
+	return session.ctx, session.searches.Done
+}
+
+// RecordMove adds a played move to the game record, charging thinking to the clock of the player who made it
+// The move is published to the OnMove observers with the board's evaluation after it
+func (session *GameSession) RecordMove(move string, thinking time.Duration) {
+	session.mutex.Lock()
+	player := len(session.record.Moves) % 2
+	session.clocks[player] += thinking
+	session.record.Moves = append(session.record.Moves, move)
+	ply := len(session.record.Moves)
+	score := session.board.Score
+	moveResult := formats.PlayedMove{
+		Ply:        ply,
+		Player:     string("xo"[player]),
+		Move:       move,
+		ThinkingMS: formats.Milliseconds(thinking),
+		Score:      &score,
+	}
+	session.moves = append(session.moves, moveResult)
+	mode := session.record.Mode
+	session.mutex.Unlock()
+
+	moveObservers.publish(GameMove{Game: session.game, Mode: mode, Ply: ply, Player: "xo"[player], Move: move, Thinking: thinking, Score: score})
+}
+
+// PublishEval publishes an evaluation of the game's current position to the OnEvalUpdate observers
+func (session *GameSession) PublishEval(depth, score int, line []string) {
+	session.mutex.Lock()
+	ply := len(session.record.Moves)
+	session.mutex.Unlock()
+
+	evalObservers.publish(EvalUpdate{Game: session.game, Ply: ply, Depth: depth, Score: score, Line: line})
+}
+
+// LogSearch writes the statistics of the search behind the last move to the event log
+func (session *GameSession) LogSearch(event GameEvent) {
+	session.mutex.Lock()
+	ply := len(session.record.Moves)
+	session.mutex.Unlock()
+
+	event.Type = EventSearchStats
+	event.Game = session.game
+	event.Ply = ply
+	event.Player = string("xo"[(ply+1)%2])
+	eventLog.Log(event)
+}
+
+// LogBotSearch writes the search statistics of the bot that made the last move, if it keeps any, and keeps the fill
+// of its transposition table for the game's result
+func (session *GameSession) LogBotSearch(bot bots.BotInterface) {
+	if searcher, ok := bot.(ttBot); ok {
+		session.mutex.Lock()
+		session.ttFill[formats.SymbolIndex(bot.Symbol())] = searcher.TTStats().FillRate()
+		session.mutex.Unlock()
+	}
+	if event, ok := searchStatsEvent(bot); ok {
+		session.LogSearch(event)
+	}
+}
+
+// Clock returns the thinking time used by symbol and the number of moves it has played
+func (session *GameSession) Clock(symbol byte) (time.Duration, int) {
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+
+	index := formats.SymbolIndex(symbol)
+	return session.clocks[index], (len(session.record.Moves) + 1 - index) / 2
+}
+
+// Record returns a copy of the game record so far
+func (session *GameSession) Record() formats.GameRecord {
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+
+	record := session.record
+	record.Moves = make([]string, len(session.record.Moves))
+	copy(record.Moves, session.record.Moves)
+	for i, clock := range session.clocks {
+		record.Clocks[i] = clock.String()
+	}
+	return record
+}
+
+// interrupt cancels the game's searches and waits up to INTERRUPT_GRACE for those in flight to return
+func (session *GameSession) interrupt() {
+	session.interrupted.Store(true)
+	session.cancel()
+
+	stopped := make(chan struct{})
+	go func() {
+		session.searches.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(INTERRUPT_GRACE):
+	}
+}
+
+// handleInterrupts traps Ctrl+C for the rest of the program
+// An interrupted game has its searches stopped and is saved to AUTOSAVE_FILE before the program exits
+// When several match games are in progress they are all stopped, but none is saved
+func handleInterrupts() {
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+
+	go func() {
+		<-interrupts
+		signal.Stop(interrupts) // A second Ctrl+C kills the program outright
+		restoreTerminal()
+		saveSearchCache()
+
+		activeSessionsMutex.Lock()
+		sessions := make([]*GameSession, 0, len(activeSessions))
+		for session := range activeSessions {
+			sessions = append(sessions, session)
+		}
+		activeSessionsMutex.Unlock()
+
+		if len(sessions) == 0 {
+			eventLog.Close()
+			fmt.Println("\n" + msg("menu.goodbye"))
+			os.Exit(130)
+		}
+
+		var stopped sync.WaitGroup
+		for _, session := range sessions {
+			stopped.Add(1)
+			go func() {
+				defer stopped.Done()
+				session.interrupt()
+				session.finish()
+			}()
+		}
+		stopped.Wait()
+		eventLog.Close()
+
+		if len(sessions) > 1 {
+			fmt.Print(msg("interrupt.abandoned", len(sessions)))
+			os.Exit(130)
+		}
+		record := sessions[0].Record()
+		if err := record.Save(AUTOSAVE_FILE); err != nil {
+			fmt.Println("\n"+msg("interrupt.save_error"), err)
+			os.Exit(130)
+		}
+
+		fmt.Print(msg("interrupt.saved", len(record.Moves), AUTOSAVE_FILE))
+		fmt.Print(msg("save.resume_hint", AUTOSAVE_FILE))
+		os.Exit(130)
+	}()
+}
@@ -0,0 +1,316 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"tic-tac-toe-3d-bots/formats"
+)
+
+// JOURNAL_DIR is the directory of the user's cache game journals are kept in unless --journal says otherwise
+const JOURNAL_DIR = "tic-tac-toe-3d-bots/journal"
+
+// JOURNAL_EXTENSION marks journal files, named <process id>-<game number> so that concurrent runs keep apart
+const JOURNAL_EXTENSION = ".jsonl"
+
+// A journal is the crash-safe record of a game in progress, in JSON Lines: the game record as it started,
+// then one line per move as it is played, each synced to the disk. The process playing the game holds an exclusive
+// lock on it, and empties and deletes it once the game ends, so a journal left behind that nobody holds a lock on is
+// a game lost to a crash, which recoverJournals turns back into a saved game
+
+// journalMove is a line of a journal after the first
+type journalMove struct {
+	Move       string  `json:"move"`
+	ThinkingMS float64 `json:"thinking_ms"`
+}
+
+// Journal writes the journals of the games in progress
+type Journal struct {
+	dir   string
+	mutex sync.Mutex
+	files map[int]*os.File // journals of the games in progress by game number
+}
+
+// journal records every game while it is played when set with --journal; nil disables it
+var journal *Journal
+
+// defaultJournalDir returns where journals are kept unless --journal says otherwise: JOURNAL_DIR in the user's cache
+// directory, or "" to keep none if there is no such directory
+func defaultJournalDir() string {
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(cache, filepath.FromSlash(JOURNAL_DIR))
+}
+
+// openJournal keeps journals in dir, creating it if needed
+func openJournal(dir string) (*Journal, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &Journal{dir: dir, files: make(map[int]*os.File)}, nil
+}
+
+// path returns the journal file of a game of this process
+func (journal *Journal) path(game int) string {
+	return filepath.Join(journal.dir, fmt.Sprintf("%d-%d%s", os.Getpid(), game, JOURNAL_EXTENSION))
+}
+
+// start creates the journal of a game with its record so far, locked for as long as the game goes on
+func (journal *Journal) start(game int, record formats.GameRecord) error {
+	header, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	file, err := os.Create(journal.path(game))
+	if err != nil {
+		return err
+	}
+	if locked, err := tryLockFile(file); !locked && err == nil {
+		err = fmt.Errorf("%s: cannot be locked", file.Name())
+	}
+	if err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return err
+	}
+	if err := journal.write(file, header); err != nil {
+		file.Close()
+		return err
+	}
+
+	journal.mutex.Lock()
+	journal.files[game] = file
+	journal.mutex.Unlock()
+	return nil
+}
+
+// write appends a line to a journal and syncs it, so that not even the last move is lost to a crash
+func (journal *Journal) write(file *os.File, line []byte) error {
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return file.Sync()
+}
+
+// move appends a move to the journal of a game
+func (journal *Journal) move(game int, move string, thinking time.Duration) error {
+	journal.mutex.Lock()
+	file := journal.files[game]
+	journal.mutex.Unlock()
+	if file == nil {
+		return nil // The journal could not be created
+	}
+
+	line, _ := json.Marshal(journalMove{Move: move, ThinkingMS: formats.Milliseconds(thinking)})
+	return journal.write(file, line)
+}
+
+// end deletes the journal of a game that is over
+// It is emptied first: another process may open it between closing and deleting it, and takes an empty journal for
+// one that is being deleted
+func (journal *Journal) end(game int) error {
+	journal.mutex.Lock()
+	file := journal.files[game]
+	delete(journal.files, game)
+	journal.mutex.Unlock()
+	if file == nil {
+		return nil
+	}
+
+	err := file.Truncate(0)
+	file.Close()
+	if err := os.Remove(file.Name()); err != nil {
+		return err
+	}
+	return err
+}
+
+// journalGameStart, journalMove and journalGameEnd keep the journals of the games in progress; a nil journal skips them
+func journalGameStart(start GameStart) {
+	if journal == nil {
+		return
+	}
+	if err := journal.start(start.Game, start.Record); err != nil {
+		fmt.Fprintln(os.Stderr, msg("journal.error"), err)
+	}
+}
+
+func journalGameMove(move GameMove) {
+	if journal == nil {
+		return
+	}
+	if err := journal.move(move.Game, move.Move, move.Thinking); err != nil {
+		fmt.Fprintln(os.Stderr, msg("journal.error"), err)
+	}
+}
+
+func journalGameEnd(end GameEnd) {
+	if journal == nil {
+		return
+	}
+	if err := journal.end(end.Game); err != nil {
+		fmt.Fprintln(os.Stderr, msg("journal.error"), err)
+	}
+}
+
+// readJournal rebuilds the record of the game journaled in file at path; a move cut short by the crash is ignored
+func readJournal(file io.Reader, path string) (*formats.GameRecord, error) {
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 1<<20)
+	if !scanner.Scan() {
+		return nil, fmt.Errorf("%s: empty journal", path)
+	}
+	record := &formats.GameRecord{}
+	if err := json.Unmarshal(scanner.Bytes(), record); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	clocks := record.ThinkingTimes()
+	for scanner.Scan() {
+		move := journalMove{}
+		if err := json.Unmarshal(scanner.Bytes(), &move); err != nil {
+			break
+		}
+		clocks[len(record.Moves)%2] += time.Duration(move.ThinkingMS * float64(time.Millisecond))
+		record.Moves = append(record.Moves, move.Move)
+	}
+	for i, clock := range clocks {
+		record.Clocks[i] = clock.String()
+	}
+	return record, nil
+}
+
+// RecoveredGame is a game lost to a crash, saved again from its journal
+type RecoveredGame struct {
+	Path     string // the saved game
+	Moves    int
+	Finished bool // the game was over, but the crash came before its result was recorded
+	Modified time.Time
+}
+
+// recoverJournals saves the games whose journals were left in dir by processes no longer playing them, most recent
+// first. Each becomes a saved game in dir, which --resume continues, and its journal is deleted
+func recoverJournals(dir string) ([]RecoveredGame, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+JOURNAL_EXTENSION))
+	if err != nil {
+		return nil, err
+	}
+
+	var recovered []RecoveredGame
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), JOURNAL_EXTENSION)
+		pidText, _, _ := strings.Cut(name, "-")
+		if pid, err := strconv.Atoi(pidText); err != nil || pid == os.Getpid() {
+			continue
+		}
+		game, err := recoverJournal(path, filepath.Join(dir, "recovered-"+name+".json"))
+		if err != nil {
+			return recovered, err
+		}
+		if game != nil {
+			recovered = append(recovered, *game)
+		}
+	}
+
+	sort.Slice(recovered, func(i, j int) bool { return recovered[i].Modified.After(recovered[j].Modified) })
+	return recovered, nil
+}
+
+// recoverJournal saves the game journaled at path to saved and deletes the journal, unless the game is still being
+// played: then the process playing it holds the journal's lock, and recoverJournal returns nil
+func recoverJournal(path, saved string) (*RecoveredGame, error) {
+	file, err := os.OpenFile(path, os.O_RDWR, 0)
+	if os.IsNotExist(err) {
+		return nil, nil // The game just ended
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	if locked, err := tryLockFile(file); !locked {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() == 0 {
+		file.Close()
+		return nil, removeIfExists(path) // Emptied by the end of its game, or by a crash while deleting it
+	}
+
+	record, err := readJournal(file, path)
+	if err != nil {
+		return nil, err
+	}
+	board, err := record.Replay(len(record.Moves))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	game := &RecoveredGame{
+		Path:     saved,
+		Moves:    len(record.Moves),
+		Finished: board.CheckWin() != '|' || board.IsFull(),
+		Modified: info.ModTime(),
+	}
+	if err := record.Save(game.Path); err != nil {
+		return nil, err
+	}
+	file.Close() // Windows deletes no file that is open
+	return game, removeIfExists(path)
+}
+
+// removeIfExists deletes the file at path, if there is one
+func removeIfExists(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// offerRecovery recovers the games lost to a crash, lists them, and when ask is set offers to continue the most
+// recent unfinished one; it returns the saved game to resume, if any
+func offerRecovery(dir string, ask bool) string {
+	recovered, err := recoverJournals(dir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, msg("journal.error"), err)
+	}
+	if len(recovered) == 0 {
+		return ""
+	}
+
+	fmt.Fprint(os.Stderr, msg("journal.recovered", len(recovered)))
+	resumable := ""
+	for _, game := range recovered {
+		if game.Finished {
+			fmt.Fprint(os.Stderr, msg("journal.recovered_over", game.Path, game.Moves))
+			continue
+		}
+		fmt.Fprint(os.Stderr, msg("journal.recovered_game", game.Path, game.Moves))
+		if resumable == "" {
+			resumable = game.Path
+		}
+	}
+	if !ask || resumable == "" {
+		return ""
+	}
+
+	fmt.Print(msg("journal.resume_prompt", resumable))
+	var answer string
+	fmt.Scanln(&answer)
+	if !strings.EqualFold(answer, "y") {
+		fmt.Print(msg("save.resume_hint", resumable))
+		return ""
+	}
+	return resumable
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"tic-tac-toe-3d-bots/engine"
+	"tic-tac-toe-3d-bots/formats"
+)
+
+func TestRecoverJournals(t *testing.T) {
+	dir := t.TempDir()
+	journal, err := openJournal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	record := formats.GameRecord{Mode: "eve", Board: engine.BoardConfig{Length: 4, Width: 4, Height: 4, Win: 4}, Players: [2]string{"a", "b"}}
+	if err := journal.start(1, record); err != nil {
+		t.Fatal(err)
+	}
+	for _, move := range []string{"A1", "B2", "C3"} {
+		if err := journal.move(1, move, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The journal of a game still played, as by another process: its name is not this process's, its file still locked
+	live := filepath.Join(dir, "1-1"+JOURNAL_EXTENSION)
+	if err := os.Rename(journal.path(1), live); err != nil {
+		t.Skipf("cannot rename an open journal here: %v", err)
+	}
+	if recovered, err := recoverJournals(dir); err != nil || len(recovered) != 0 {
+		t.Fatalf("recoverJournals of a live journal = %v, %v, want nothing", recovered, err)
+	}
+	if _, err := os.Stat(live); err != nil {
+		t.Fatalf("live journal: %v", err)
+	}
+
+	// The process is gone, leaving its journal and one emptied as its game ended
+	journal.mutex.Lock()
+	journal.files[1].Close()
+	journal.mutex.Unlock()
+	emptied := filepath.Join(dir, "1-2"+JOURNAL_EXTENSION)
+	if err := os.WriteFile(emptied, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	recovered, err := recoverJournals(dir)
+	if err != nil || len(recovered) != 1 || recovered[0].Moves != 3 || recovered[0].Finished {
+		t.Fatalf("recoverJournals after the crash = %+v, %v, want one unfinished game of 3 moves", recovered, err)
+	}
+	for _, path := range []string{live, emptied} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s left behind: %v", path, err)
+		}
+	}
+	if _, err := os.Stat(recovered[0].Path); err != nil {
+		t.Errorf("recovered game: %v", err)
+	}
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"tic-tac-toe-3d-bots/engine"
+)
+
+// LeaderboardFilter selects the games a leaderboard is computed from
+type LeaderboardFilter struct {
+	Board    *engine.BoardConfig // only games on this board; a zero Win matches any win length
+	Since    time.Time           // only games played at or after this time (zero for no limit)
+	Until    time.Time           // only games played before this time (zero for no limit)
+	Kind     string              // "bot" or "human" to list only one kind of player ("" lists both)
+	MinGames int                 // leave out players with fewer rated games
+}
+
+// matches reports whether game is one of the filter's games
+func (filter LeaderboardFilter) matches(game RatedGame) bool {
+	if filter.Board != nil && !boardMatches(*filter.Board, game.Board) {
+		return false
+	}
+	if !filter.Since.IsZero() && game.Time.Before(filter.Since) {
+		return false
+	}
+	if !filter.Until.IsZero() && !game.Time.Before(filter.Until) {
+		return false
+	}
+	return true
+}
+
+// boardMatches reports whether board has the dimensions of filter; a zero Win in filter matches any win length
+func boardMatches(filter, board engine.BoardConfig) bool {
+	if board.Length != filter.Length || board.Width != filter.Width || board.Height != filter.Height {
+		return false
+	}
+	return filter.Win == 0 || board.Win == filter.Win
+}
+
+// Leaderboard returns the ratings of the filter's players, best first
+// Without a board or time filter these are the stored ratings; otherwise they are recomputed from the matching games
+func (store *StatsStore) Leaderboard(filter LeaderboardFilter) []PlayerRating {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if filter.Board == nil && filter.Since.IsZero() && filter.Until.IsZero() {
+		return rankRatings(store.Ratings, filter)
+	}
+	players := make(map[string]PlayerRating, len(store.Ratings))
+	for key, rating := range store.Ratings {
+		players[key] = *rating
+	}
+	return rankRatings(replayRatings(store.Games, players, filter), filter)
+}
+
+// dbLeaderboard returns the ratings of the filter's players computed from every matching game of the database, best first
+func dbLeaderboard(db *GameDB, filter LeaderboardFilter) ([]PlayerRating, error) {
+	recorded, err := db.Games(nil)
+	if err != nil {
+		return nil, err
+	}
+	var games []RatedGame
+	players := make(map[string]PlayerRating)
+	for _, stored := range recorded {
+		game, named, ok := stored.rated()
+		if !ok {
+			continue
+		}
+		games = append(games, game)
+		for i, key := range game.Players {
+			players[key] = named[i]
+		}
+	}
+	return rankRatings(replayRatings(games, players, filter), filter), nil
+}
+
+// replayRatings rates the players of the filter's games from scratch, in the order the games were played
+// players supplies the name and kind of each rating key
+func replayRatings(games []RatedGame, players map[string]PlayerRating, filter LeaderboardFilter) map[string]*PlayerRating {
+	ratings := make(map[string]*PlayerRating)
+	for _, game := range games {
+		if !filter.matches(game) {
+			continue
+		}
+		var rated [2]*PlayerRating
+		for i, key := range game.Players {
+			if ratings[key] == nil {
+				name, kind := key, "bot"
+				if player, exists := players[key]; exists {
+					name, kind = player.Name, player.Kind
+				}
+				ratings[key] = newPlayerRating(name, kind)
+			}
+			rated[i] = ratings[key]
+		}
+		rateResult(rated, game)
+	}
+	return ratings
+}
+
+// rankRatings sorts the ratings the filter lets through, best first
+func rankRatings(ratings map[string]*PlayerRating, filter LeaderboardFilter) []PlayerRating {
+	leaderboard := make([]PlayerRating, 0, len(ratings))
+	for _, rating := range ratings {
+		if filter.Kind != "" && rating.Kind != filter.Kind {
+			continue
+		}
+		if rating.Games() < filter.MinGames {
+			continue
+		}
+		leaderboard = append(leaderboard, *rating)
+	}
+	sort.Slice(leaderboard, func(a, b int) bool {
+		if leaderboard[a].Rating != leaderboard[b].Rating {
+			return leaderboard[a].Rating > leaderboard[b].Rating
+		}
+		return leaderboard[a].Name < leaderboard[b].Name
+	})
+	return leaderboard
+}
+
+// parseTimeFilter parses a date ("2026-01-31"), an RFC 3339 time, or a Go duration meaning that long ago ("168h")
+func parseTimeFilter(value string, now time.Time) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if ago, err := time.ParseDuration(value); err == nil {
+		return now.Add(-ago), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (expected a date like 2026-01-31, an RFC 3339 time or a duration like 168h)", value)
+}
+
+// runLeaderboard implements the leaderboard command: it prints the ranked ratings in the stats store or a game database
+func runLeaderboard(args []string, output io.Writer) error {
+	var boardFilter, since, until, lang, dbPath string
+	filter := LeaderboardFilter{}
+	statsFile := DEFAULT_STATS_FILE
+
+	fs := flag.NewFlagSet("leaderboard", flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.StringVar(&statsFile, "stats", DEFAULT_STATS_FILE, "stats store to read")
+	fs.StringVar(&dbPath, "db", "", "compute the ratings from every game in this game database (see --db) instead of the stats store")
+	fs.StringVar(&boardFilter, "board", "", "only count games on this board, as LxWxH or LxWxH/win (e.g. 4x4x4)")
+	fs.StringVar(&since, "since", "", "only count games played since this date, time or duration ago (e.g. 2026-01-31 or 168h)")
+	fs.StringVar(&until, "until", "", "only count games played before this date or time")
+	fs.StringVar(&filter.Kind, "kind", "", "list only bots or humans: bot or human")
+	fs.IntVar(&filter.MinGames, "min-games", 0, "leave out players with fewer rated games")
+	fs.StringVar(&lang, "lang", "", "language for messages: "+strings.Join(availableLocales(), ", ")+" (default from TTT_LANG or LANG)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+
+	if lang == "" {
+		lang = localeFromEnvironment()
+	}
+	if err := setLocale(lang); err != nil {
+		return err
+	}
+
+	var err error
+	if boardFilter != "" {
+		if filter.Board, err = engine.ParseBoardConfig(boardFilter); err != nil {
+			return err
+		}
+	}
+	now := time.Now()
+	if since != "" {
+		if filter.Since, err = parseTimeFilter(since, now); err != nil {
+			return err
+		}
+	}
+	if until != "" {
+		if filter.Until, err = parseTimeFilter(until, now); err != nil {
+			return err
+		}
+	}
+	if filter.Kind != "" && filter.Kind != "bot" && filter.Kind != "human" {
+		return fmt.Errorf("invalid kind %q (expected bot or human)", filter.Kind)
+	}
+
+	var leaderboard []PlayerRating
+	if dbPath != "" {
+		db, err := openGameDB(dbPath)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		if leaderboard, err = dbLeaderboard(db, filter); err != nil {
+			return err
+		}
+	} else {
+		store, err := loadStatsStore(statsFile)
+		if err != nil {
+			return err
+		}
+		leaderboard = store.Leaderboard(filter)
+	}
+	if len(leaderboard) == 0 {
+		fmt.Println(msg("leaderboard.empty"))
+		return nil
+	}
+
+	width := 0
+	for _, rating := range leaderboard {
+		width = max(width, len(rating.Name))
+	}
+	fmt.Println(msg("leaderboard.title"))
+	fmt.Println(strings.Repeat("═", width+60))
+	for rank, rating := range leaderboard {
+		fmt.Print(msg("leaderboard.entry", rank+1, width, rating.Name, msg("leaderboard.kind_"+rating.Kind), rating.String(),
+			rating.Games(), rating.Wins, rating.Draws, rating.Losses, rating.Updated.Local().Format("2006-01-02")))
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"tic-tac-toe-3d-bots/bots"
+)
+
+// Diagnostics go to a structured logger rather than the console output of games and commands.
+// Every record is tagged with the subsystem it comes from, and each subsystem can be given a level of its own:
+//
+//	search  the moves bot searches choose (from the bots package)
+//	tree    the persistent bot's search tree (from the bots package)
+//	server  the HTTP and gRPC servers
+//	arena   the arena and the engines connected to it
+//	trace   the export of traces to an OpenTelemetry collector
+//
+// --log-level takes a default level followed by per-subsystem levels, e.g. "info,search=debug";
+// nothing below warnings is logged unless asked for
+
+// LOG_SUBSYSTEMS lists the subsystems that may be given a level of their own
+var LOG_SUBSYSTEMS = []string{"search", "tree", "server", "arena", "trace"}
+
+// DEFAULT_LOG_LEVEL is the level of every subsystem not set with --log-level
+const DEFAULT_LOG_LEVEL = "warn"
+
+// Loggers of the subsystems in this package; they discard everything until configureLogging is called
+var (
+	serverLog = slog.New(slog.DiscardHandler)
+	arenaLog  = slog.New(slog.DiscardHandler)
+	traceLog  = slog.New(slog.DiscardHandler)
+)
+
+// logFlags holds the logging flags shared by the main command and the server commands
+type logFlags struct {
+	level  string
+	format string
+	file   string
+}
+
+// addLogFlags declares the logging flags on fs
+func addLogFlags(fs *flag.FlagSet) *logFlags {
+	flags := &logFlags{}
+	fs.StringVar(&flags.level, "log-level", DEFAULT_LOG_LEVEL, "diagnostics to log: debug, info, warn or error, optionally followed by per-subsystem levels, e.g. \"info,search=debug\"; subsystems: "+strings.Join(LOG_SUBSYSTEMS, ", "))
+	fs.StringVar(&flags.format, "log-format", "text", "diagnostics format: text, or json for one JSON object per line")
+	fs.StringVar(&flags.file, "log-file", "", "append diagnostics to this file instead of standard error")
+	return flags
+}
+
+// apply configures logging from the flags, returning a function to call once the program is done logging
+func (flags *logFlags) apply() (func() error, error) {
+	levels, err := parseLogLevels(flags.level)
+	if err != nil {
+		return nil, err
+	}
+	if flags.format != "text" && flags.format != "json" {
+		return nil, fmt.Errorf("invalid log format %q (expected text or json)", flags.format)
+	}
+
+	var writer io.Writer = os.Stderr
+	closeLog := func() error { return nil }
+	if flags.file != "" {
+		file, err := os.OpenFile(flags.file, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, err
+		}
+		writer, closeLog = file, file.Close
+	}
+	configureLogging(writer, flags.format == "json", levels)
+	return closeLog, nil
+}
+
+// subsystemLevels is the level of each subsystem; the "" entry applies to the others
+type subsystemLevels map[string]slog.Level
+
+// parseLogLevels parses a --log-level value such as "warn" or "info,search=debug,server=error"
+func parseLogLevels(spec string) (subsystemLevels, error) {
+	levels := subsystemLevels{"": slog.LevelWarn}
+	for i, part := range strings.Split(spec, ",") {
+		subsystem, name, scoped := strings.Cut(strings.TrimSpace(part), "=")
+		if !scoped {
+			subsystem, name = "", subsystem
+			if i > 0 {
+				return nil, fmt.Errorf("invalid log level %q: only the first level may apply to every subsystem", part)
+			}
+		}
+
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(name)); err != nil {
+			return nil, fmt.Errorf("invalid log level %q (expected debug, info, warn or error)", name)
+		}
+		subsystem = strings.ToLower(strings.TrimSpace(subsystem))
+		if subsystem != "" && !validSubsystem(subsystem) {
+			return nil, fmt.Errorf("unknown log subsystem %q (available: %s)", subsystem, strings.Join(LOG_SUBSYSTEMS, ", "))
+		}
+		levels[subsystem] = level
+	}
+	return levels, nil
+}
+
+// validSubsystem reports whether subsystem is one of LOG_SUBSYSTEMS
+func validSubsystem(subsystem string) bool {
+	for _, known := range LOG_SUBSYSTEMS {
+		if subsystem == known {
+			return true
+		}
+	}
+	return false
+}
+
+// configureLogging sends the diagnostics of every subsystem to writer, as JSON or as key=value text
+// It must be called before any game or server starts
+func configureLogging(writer io.Writer, json bool, levels subsystemLevels) {
+	options := &slog.HandlerOptions{Level: slog.LevelDebug} // levels are decided by subsystemHandler
+	var handler slog.Handler = slog.NewTextHandler(writer, options)
+	if json {
+		handler = slog.NewJSONHandler(writer, options)
+	}
+	logger := slog.New(&subsystemHandler{handler: handler, levels: levels, level: levels[""]})
+
+	serverLog = logger.With("subsystem", "server")
+	arenaLog = logger.With("subsystem", "arena")
+	traceLog = logger.With("subsystem", "trace")
+	bots.SetLogger(logger)
+}
+
+// subsystemHandler filters records by the level of the subsystem they are tagged with
+type subsystemHandler struct {
+	handler slog.Handler
+	levels  subsystemLevels
+	level   slog.Level // level of this handler's subsystem
+}
+
+// Enabled implements slog.Handler
+func (h *subsystemHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+// Handle implements slog.Handler
+func (h *subsystemHandler) Handle(ctx context.Context, record slog.Record) error {
+	return h.handler.Handle(ctx, record)
+}
+
+// WithAttrs implements slog.Handler; a "subsystem" attribute switches to that subsystem's level
+func (h *subsystemHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	scoped := &subsystemHandler{handler: h.handler.WithAttrs(attrs), levels: h.levels, level: h.level}
+	for _, attr := range attrs {
+		if attr.Key != "subsystem" {
+			continue
+		}
+		if level, ok := h.levels[attr.Value.String()]; ok {
+			scoped.level = level
+		}
+	}
+	return scoped
+}
+
+// WithGroup implements slog.Handler
+func (h *subsystemHandler) WithGroup(name string) slog.Handler {
+	return &subsystemHandler{handler: h.handler.WithGroup(name), levels: h.levels, level: h.level}
+}
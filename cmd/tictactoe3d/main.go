@@ -0,0 +1,284 @@
+//go:build !(js && wasm)
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"tic-tac-toe-3d-bots/bots"
+)
+
+func main() {
+	// The leaderboard, games and openings commands only read the stats store or the game database; train teaches the
+	// qlearning bot, calibrate measures the limited bot's strength levels, tune the evaluator's parameters, and ttbench
+	// compares transposition table replacement policies; export and import convert saved games;
+	// host and join play a game over the network; serve answers the HTTP API, and grpc the gRPC service of engine.proto;
+	// arena plays remote engines against each other, and arena-join plays in an arena with a bot; watch follows a broadcast;
+	// chat plays against a bot in chat channels
+	commands := map[string]func([]string, io.Writer) error{
+		"leaderboard": runLeaderboard, "games": runGamesQuery, "openings": runOpenings, "train": runTrain, "calibrate": runCalibrate, "tune": runTune, "ttbench": runTTBench, "export": runExport, "import": runImport,
+		"host": runHost, "join": runJoin, "serve": runServe, "grpc": runGRPC,
+		"arena": runArena, "arena-join": runArenaJoin, "watch": runWatch,
+		"chat": runChat,
+	}
+	if len(os.Args) > 1 && commands[os.Args[1]] != nil {
+		err := commands[os.Args[1]](os.Args[2:], os.Stderr)
+		if err == flag.ErrHelp {
+			return
+		} else if err != nil {
+			fmt.Fprintln(os.Stderr, msg("error"), err)
+			os.Exit(2)
+		}
+		return
+	}
+
+	// Parse command-line flags (and the config file, if one is given)
+	opts, err := parseCLIOptions(os.Args[1:], os.Stderr)
+	if err == flag.ErrHelp {
+		return
+	} else if err != nil {
+		fmt.Fprintln(os.Stderr, msg("error"), err)
+		os.Exit(2)
+	}
+
+	// Ctrl+C stops the game in progress and saves it instead of killing searches mid-flight
+	handleInterrupts()
+
+	// Seed random choices before any bot is created
+	bots.SetSeed(opts.Seed)
+	bots.SetTTSize(opts.Hash)
+	bots.SetTTPolicy(opts.TTPolicy)
+	bots.SetTTLocking(opts.TTLocking)
+	bots.SetSearchMemory(opts.Memory)
+
+	DefaultRenderOptions = opts.Render
+	currentProfile = opts.Player
+	CursorInput = opts.Cursor
+	Playouts = opts.Playouts
+	RatingsFile = opts.Ratings
+
+	// Select the message language before anything is shown
+	locale := opts.Lang
+	if locale == "" {
+		locale = localeFromEnvironment()
+	}
+	if err := setLocale(locale); err != nil {
+		fmt.Fprintln(os.Stderr, msg("error"), err)
+		os.Exit(2)
+	}
+
+	// Diagnostics are logged from here on
+	closeLog, err := opts.Log.apply()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, msg("error"), err)
+		os.Exit(2)
+	}
+	defer closeLog()
+
+	stopTracing, err := startTracing(*opts.Trace)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, msg("error"), err)
+		os.Exit(2)
+	}
+	defer stopTracing()
+
+	if opts.Metrics != "" {
+		address, err := serveMetrics(opts.Metrics)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, msg("error"), err)
+			os.Exit(2)
+		}
+		fmt.Fprint(os.Stderr, msg("metrics.listening", address))
+	}
+
+	if opts.Broadcast != "" {
+		addresses, err := startBroadcast(opts.Broadcast, opts.BroadcastWS)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, msg("error"), err)
+			os.Exit(2)
+		}
+		fmt.Fprint(os.Stderr, msg("broadcast.listening", addresses[0]))
+		if len(addresses) > 1 {
+			fmt.Fprint(os.Stderr, msg("broadcast.websocket", addresses[1]))
+		}
+	}
+
+	// Open the event log before any game starts
+	if opts.Events != "" {
+		if eventLog, err = openEventLog(opts.Events); err != nil {
+			fmt.Fprintln(os.Stderr, msg("error"), err)
+			os.Exit(2)
+		}
+		defer eventLog.Close()
+	}
+	if opts.CSV != "" {
+		if csvExport, err = openCSVExport(opts.CSV); err != nil {
+			fmt.Fprintln(os.Stderr, msg("error"), err)
+			os.Exit(2)
+		}
+		defer csvExport.Close()
+	}
+	if opts.DB != "" {
+		if gameDB, err = openGameDB(opts.DB); err != nil {
+			fmt.Fprintln(os.Stderr, msg("error"), err)
+			os.Exit(2)
+		}
+		defer gameDB.Close()
+	}
+	if opts.Book != "" || opts.RandomPlies > 0 {
+		if openingBook, err = openOpeningBook(opts.Book, opts.BookPlies, opts.RandomPlies); err != nil {
+			fmt.Fprintln(os.Stderr, msg("error"), err)
+			os.Exit(2)
+		}
+	}
+	if opts.SearchCache != "" {
+		if searchCache, err = openSearchCache(opts.SearchCache); err != nil {
+			fmt.Fprintln(os.Stderr, msg("error"), err)
+			os.Exit(2)
+		}
+		defer saveSearchCache()
+	}
+	if opts.Archive != "" {
+		if gameArchive, err = openGameArchive(opts.Archive); err != nil {
+			fmt.Fprintln(os.Stderr, msg("error"), err)
+			os.Exit(2)
+		}
+		defer gameArchive.Close()
+	}
+	if opts.Journal != "" {
+		if journal, err = openJournal(opts.Journal); err != nil {
+			fmt.Fprintln(os.Stderr, msg("error"), err)
+			os.Exit(2)
+		}
+	}
+
+	// JSON output keeps standard output free of everything but the game results
+	if jsonOutput, _ := parseOutputFormat(opts.Output); jsonOutput { // validated by parseCLIOptions
+		if err := enableJSONOutput(opts.interactive()); err != nil {
+			fmt.Fprintln(os.Stderr, msg("error"), err)
+			os.Exit(2)
+		}
+	}
+
+	// Load bot plugins, then bot profiles (which may use the plugins' bots), so they can be picked from the menus and flags alike
+	if opts.Plugins != "" {
+		err = loadBotPlugins(opts.Plugins)
+	} else {
+		err = loadDefaultBotPlugins()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, msg("error.plugins"), err)
+		os.Exit(2)
+	}
+	if opts.Profiles != "" {
+		err = loadBotProfiles(opts.Profiles)
+	} else {
+		err = loadDefaultBotProfiles()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, msg("error.profiles"), err)
+		os.Exit(2)
+	}
+
+	// Recover the games a crash cut short, offering to continue one when nothing else was asked for
+	if journal != nil {
+		if resume := offerRecovery(opts.Journal, opts.Mode == "" && opts.Resume == "" && opts.Replay == ""); resume != "" {
+			opts.Resume = resume
+		}
+	}
+
+	// Continue a saved game instead of starting a new one
+	if opts.Resume != "" {
+		if err := resumeGame(opts.Resume); err != nil {
+			fmt.Fprintln(os.Stderr, msg("error"), err)
+			os.Exit(2)
+		}
+		return
+	}
+
+	// Pick an interrupted run up where its event log stops
+	if opts.ResumeRun != "" {
+		if resumedRun, err = loadEventRun(opts.ResumeRun); err != nil {
+			fmt.Fprintln(os.Stderr, msg("error"), err)
+			os.Exit(2)
+		}
+		if err := resumedRun.restoreSeed(opts.Seed); err != nil {
+			fmt.Fprintln(os.Stderr, msg("error"), err)
+			os.Exit(2)
+		}
+		finished, inProgress := resumedRun.counts()
+		fmt.Fprint(os.Stderr, msg("events.resuming", opts.ResumeRun, finished, inProgress))
+	}
+
+	// Review a saved game without playing it
+	if opts.Replay != "" {
+		if err := replayGame(opts.Replay); err != nil {
+			fmt.Fprintln(os.Stderr, msg("error"), err)
+			os.Exit(2)
+		}
+		return
+	}
+
+	// Non-interactive mode: run straight from the configured options
+	if opts.Mode != "" {
+		if err := runWithOptions(opts); err != nil {
+			fmt.Fprintln(os.Stderr, msg("error"), err)
+			os.Exit(2)
+		}
+		return
+	}
+
+	// Interactive mode: keep returning to the menu until the user exits
+	for {
+		fmt.Println(msg("menu.title"))
+		fmt.Println("═══════════════════════════════")
+		fmt.Println()
+		fmt.Println(msg("menu.choose"))
+		fmt.Println(msg("menu.pvp"))
+		fmt.Println(msg("menu.pve"))
+		fmt.Println(msg("menu.eve"))
+		fmt.Println(msg("menu.pvestream"))
+		fmt.Println(msg("menu.evestream"))
+		fmt.Println(msg("menu.profiles"))
+		fmt.Println(msg("menu.mirror"))
+		fmt.Println(msg("menu.exit"))
+		fmt.Println()
+
+		var choice int
+		fmt.Print(msg("menu.prompt"))
+		if _, err := fmt.Scanln(&choice); err == io.EOF {
+			return // Input closed, nothing more to play
+		}
+
+		var rematch Rematch
+		switch choice {
+		case 1:
+			rematch = RunPvP()
+		case 2:
+			rematch = RunPvE()
+		case 3:
+			rematch = RunEvE()
+		case 4:
+			RunPvEStream()
+		case 5:
+			RunEvEStream()
+		case 6:
+			RunProfiles()
+		case 7:
+			RunMirror()
+		case 8:
+			fmt.Println(msg("menu.goodbye"))
+			return
+		default:
+			fmt.Println(msg("menu.invalid"))
+		}
+
+		for rematch != nil && askRematch() {
+			rematch = rematch()
+		}
+		fmt.Println()
+	}
+}
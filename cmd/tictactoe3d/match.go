@@ -0,0 +1,281 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"sync"
+	"time"
+
+	"tic-tac-toe-3d-bots/bots"
+	"tic-tac-toe-3d-bots/engine"
+	"tic-tac-toe-3d-bots/formats"
+)
+
+// MatchStats sums up a multi-game match between bot A and bot B, from bot A's point of view
+type MatchStats struct {
+	Names     [2]string        `json:"names"` // bot A and bot B
+	Games     int              `json:"games"`
+	Wins      int              `json:"wins"`
+	Draws     int              `json:"draws"`
+	Losses    int              `json:"losses"`
+	Plies     int              `json:"plies"`          // moves played over all games
+	Resigned  [2]int           `json:"resign_wins"`    // games bot A and bot B won by the other resigning
+	Judged    int              `json:"adjudicated"`    // games adjudicated on the bots' evaluations
+	Thinking  [2]time.Duration `json:"-"`              // thinking time of bot A and bot B
+	Moves     [2]int           `json:"moves"`          // moves played by bot A and bot B
+	Score     float64          `json:"score"`          // (wins + draws/2) / games
+	Margin    float64          `json:"margin"`         // 95% confidence interval of the score, ±
+	AverageMS [2]float64       `json:"average_ms"`     // average thinking time per move of bot A and bot B
+	LLR       float64          `json:"llr,omitempty"`  // log-likelihood ratio of the SPRT, if one is run
+	SPRT      *SPRTDecision    `json:"sprt,omitempty"` // the SPRT's decision; nil if it reached none
+}
+
+// add counts one game in which bot A played side (0 for 'x', 1 for 'o')
+func (stats *MatchStats) add(result formats.GameResult, side int) {
+	stats.Games++
+	stats.Plies += len(result.Moves)
+	if result.Reason == "adjudicated" {
+		stats.Judged++
+	}
+	switch result.Winner {
+	case "draw":
+		stats.Draws++
+	case string("xo"[side]):
+		stats.Wins++
+		if result.Reason == "resigned" {
+			stats.Resigned[0]++
+		}
+	default:
+		stats.Losses++
+		if result.Reason == "resigned" {
+			stats.Resigned[1]++
+		}
+	}
+
+	for bot, index := range [2]int{side, 1 - side} {
+		played := result.Stats[index]
+		stats.Moves[bot] += played.Moves
+		stats.Thinking[bot] += time.Duration(played.TotalMS * float64(time.Millisecond))
+	}
+	stats.update()
+}
+
+// merge adds the games of another match played by the same bot A
+func (stats *MatchStats) merge(other MatchStats) {
+	stats.Games += other.Games
+	stats.Wins += other.Wins
+	stats.Draws += other.Draws
+	stats.Losses += other.Losses
+	stats.Plies += other.Plies
+	stats.Judged += other.Judged
+	for bot := range stats.Resigned {
+		stats.Resigned[bot] += other.Resigned[bot]
+	}
+	for bot := range stats.Moves {
+		stats.Moves[bot] += other.Moves[bot]
+		stats.Thinking[bot] += other.Thinking[bot]
+	}
+	stats.update()
+}
+
+// update recomputes the score, its margin and the average move times from the totals
+func (stats *MatchStats) update() {
+	for bot := range stats.Moves {
+		if stats.Moves[bot] > 0 {
+			stats.AverageMS[bot] = formats.Milliseconds(stats.Thinking[bot]) / float64(stats.Moves[bot])
+		}
+	}
+	if stats.Games == 0 {
+		return
+	}
+
+	// Each game scores 1, 1/2 or 0; the margin is 1.96 standard errors of their mean
+	games := float64(stats.Games)
+	stats.Score = (float64(stats.Wins) + float64(stats.Draws)/2) / games
+	variance := (float64(stats.Wins)*math.Pow(1-stats.Score, 2) +
+		float64(stats.Draws)*math.Pow(0.5-stats.Score, 2) +
+		float64(stats.Losses)*math.Pow(stats.Score, 2)) / games
+	stats.Margin = 1.96 * math.Sqrt(variance/games)
+}
+
+// averageTime returns bot's average thinking time per move
+func (stats *MatchStats) averageTime(bot int) time.Duration {
+	if stats.Moves[bot] == 0 {
+		return 0
+	}
+	return stats.Thinking[bot] / time.Duration(stats.Moves[bot])
+}
+
+// matchGame is the result of one game of a match
+type matchGame struct {
+	number int // game number, from 1
+	side   int // side bot A played: 0 for 'x', 1 for 'o'
+	result formats.GameResult
+}
+
+// playMatch plays games EvE games between the bots of newBotA and newBotB, swapping sides after every game
+// With a test the match stops early once it decides which bot is stronger, making games the maximum
+// Only a progress line per finished game and the final summary are printed
+func playMatch(board *engine.Board, newBotA, newBotB func(symbol byte) bots.BotInterface, games, workers int, settings formats.EvESettings, test *SPRT) MatchStats {
+	fmt.Print(msg("match.begins", games, max(1, min(workers, games))))
+	if test != nil {
+		lower, upper := test.bounds()
+		fmt.Print(msg("sprt.begins", test.Elo0, test.Elo1, test.Alpha, test.Beta, lower, upper))
+	}
+	stats := runMatch(board, newBotA, newBotB, games, workers, settings, test, func(game matchGame, stats *MatchStats) {
+		result := game.result
+		outcome := msg("match.draw")
+		if result.Winner != "draw" {
+			outcome = msg("match.winner", result.Players[formats.SymbolIndex(result.Winner[0])])
+		}
+		if result.Reason == "resigned" {
+			outcome = msg("match.resigned", result.Players[formats.SymbolIndex(result.Winner[0])])
+		}
+		if result.Reason == "adjudicated" {
+			outcome = msg("match.adjudicated", outcome)
+		}
+		fmt.Print(msg("match.game", stats.Games, games, game.number, result.Players[0], result.Players[1], outcome, len(result.Moves), stats.Score*100))
+		if test != nil {
+			fmt.Print(msg("sprt.llr", stats.LLR))
+		}
+	})
+
+	printMatchStats(stats)
+	return stats
+}
+
+// runMatch plays a match like playMatch without printing anything, calling progress (if not nil) after every game
+// Bot A plays 'x' in odd-numbered games. Up to workers games run at once, each on its own copy of board with its own bots
+// With a test, no new game is started once it reaches a decision; games already running are still counted
+// Resuming a run, the games its event log has finished are counted without being played again
+func runMatch(board *engine.Board, newBotA, newBotB func(symbol byte) bots.BotInterface, games, workers int, settings formats.EvESettings, test *SPRT, progress func(matchGame, *MatchStats)) MatchStats {
+	settings.Silent = true
+	workers = max(1, min(workers, games))
+
+	// Seeds are drawn before any game starts, so each game gets the same ones however many workers there are
+	seeds := make([][2]int64, games)
+	for i := range seeds {
+		seeds[i] = [2]int64{bots.NextSeed(), bots.NextSeed()}
+	}
+
+	// Each pair of games, one with each bot as 'x', starts from the same opening of the book, if there is one, so
+	// neither bot gains from the openings it happens to be given
+	openings := make([][]string, games)
+	if openingBook != nil {
+		random := rand.New(rand.NewSource(bots.NextSeed()))
+		for i := 0; i < games; i += 2 {
+			openings[i] = openingBook.Opening(board, random)
+			if i+1 < games {
+				openings[i+1] = openings[i]
+			}
+		}
+	}
+
+	// The match's games take a block of numbers within the run, so that the games of a run resumed from its event log
+	// (see eventRun) keep theirs
+	first := int(gamesStarted.Add(int64(games))) - games
+
+	numbers := make(chan int)
+	decided := make(chan struct{})
+	go func() {
+		defer close(numbers)
+		for number := 1; number <= games; number++ {
+			select {
+			case numbers <- number:
+			case <-decided:
+				return
+			}
+		}
+	}()
+
+	finished := make(chan matchGame)
+	var running sync.WaitGroup
+	for range workers {
+		running.Add(1)
+		go func() {
+			defer running.Done()
+			for number := range numbers {
+				game := matchGame{number: number, side: (number - 1) % 2}
+				if result, over := resumedRun.finished(first + number); over {
+					game.result = result
+					finished <- game
+					continue
+				}
+				seed := seeds[number-1]
+				gameSettings := settings
+				gameSettings.Opening = openings[number-1]
+				gameSettings.Game = first + number
+				gameBoard := resumedRun.board(first+number, board)
+				if game.side == 0 {
+					game.result = playEvE(gameBoard, bots.SeedBot(newBotA('x'), seed[0]), bots.SeedBot(newBotB('o'), seed[1]), gameSettings)
+				} else {
+					game.result = playEvE(gameBoard, bots.SeedBot(newBotB('x'), seed[1]), bots.SeedBot(newBotA('o'), seed[0]), gameSettings)
+				}
+				finished <- game
+			}
+		}()
+	}
+	go func() {
+		running.Wait()
+		close(finished)
+	}()
+
+	stats := MatchStats{}
+	for game := range finished {
+		stats.Names = [2]string{game.result.Players[game.side], game.result.Players[1-game.side]}
+		stats.add(game.result, game.side)
+		if test != nil {
+			stats.LLR = test.llr(&stats)
+		}
+		if test != nil && stats.SPRT == nil {
+			if decision := test.decide(stats.LLR); decision != "" {
+				stats.SPRT = &SPRTDecision{Hypothesis: decision, Games: stats.Games, LLR: stats.LLR}
+				close(decided)
+			}
+		}
+		if progress != nil {
+			progress(game, &stats)
+		}
+	}
+	return stats
+}
+
+// printMatchStats prints the summary of a match; with JSON output it is also written as one line of JSON
+func printMatchStats(stats MatchStats) {
+	fmt.Println(msg("match.title"))
+	fmt.Println("═══════════════════════════════════════")
+	fmt.Print(msg("match.record", stats.Names[0], stats.Wins, stats.Draws, stats.Losses, stats.Names[1]))
+	fmt.Print(msg("match.score", stats.Names[0], stats.Score*100, stats.Margin*100))
+	if stats.Games > 0 {
+		fmt.Print(msg("match.length", float64(stats.Plies)/float64(stats.Games)))
+	}
+	if stats.Judged > 0 {
+		fmt.Print(msg("match.adjudications", stats.Judged))
+	}
+	if stats.Resigned != [2]int{} {
+		fmt.Print(msg("match.resign_wins", stats.Resigned[0], stats.Names[0], stats.Resigned[1], stats.Names[1]))
+	}
+	for bot, name := range stats.Names {
+		fmt.Print(msg("match.average_time", name, stats.averageTime(bot)))
+	}
+	switch {
+	case stats.SPRT == nil && stats.LLR != 0:
+		fmt.Print(msg("sprt.undecided", stats.LLR))
+	case stats.SPRT == nil:
+	case stats.SPRT.Hypothesis == "H1":
+		fmt.Print(msg("sprt.h1", stats.SPRT.Games, stats.SPRT.LLR))
+	default:
+		fmt.Print(msg("sprt.h0", stats.SPRT.Games, stats.SPRT.LLR))
+	}
+	fmt.Print(msg("seed.replay", bots.RunSeed))
+
+	csvExport.WriteMatch(stats)
+	if JSONOutput {
+		if err := json.NewEncoder(resultOutput).Encode(stats); err != nil {
+			fmt.Fprintln(os.Stderr, msg("error"), err)
+		}
+	}
+}
@@ -0,0 +1,433 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// DEFAULT_LOCALE is used when no locale is requested or the requested one is not shipped
+const DEFAULT_LOCALE = "en"
+
+// MessageCatalog maps message keys to fmt format strings in one language
+type MessageCatalog map[string]string
+
+// messageCatalogs holds every shipped locale keyed by language code
+var messageCatalogs = map[string]MessageCatalog{
+	"en": englishMessages,
+	"id": indonesianMessages,
+}
+
+// currentMessages is the catalog used by msg, set by setLocale
+var currentMessages = englishMessages
+
+// localeLanguage extracts the language code from a locale such as "id", "id_ID" or "id_ID.UTF-8"
+func localeLanguage(locale string) string {
+	language := strings.ToLower(strings.TrimSpace(locale))
+	if cut := strings.IndexAny(language, "_.-@"); cut >= 0 {
+		language = language[:cut]
+	}
+	return language
+}
+
+// setLocale selects the message catalog for a locale such as "id", "id_ID" or "id_ID.UTF-8"
+func setLocale(locale string) error {
+	catalog, exists := messageCatalogs[localeLanguage(locale)]
+	if !exists {
+		return fmt.Errorf("unknown language %q (available: %s)", locale, strings.Join(availableLocales(), ", "))
+	}
+	currentMessages = catalog
+	return nil
+}
+
+// availableLocales returns the shipped language codes in sorted order
+func availableLocales() []string {
+	locales := make([]string, 0, len(messageCatalogs))
+	for locale := range messageCatalogs {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// localeFromEnvironment returns the locale requested by TTT_LANG, falling back to LANG
+// An unsupported LANG (e.g. "C" or "fr_FR") silently selects DEFAULT_LOCALE
+func localeFromEnvironment() string {
+	if locale := os.Getenv("TTT_LANG"); locale != "" {
+		return locale
+	}
+	if locale := os.Getenv("LANG"); locale != "" {
+		if _, supported := messageCatalogs[localeLanguage(locale)]; supported {
+			return locale
+		}
+	}
+	return DEFAULT_LOCALE
+}
+
+// msg formats the message for key in the current locale, falling back to English
+// A key missing from every catalog is returned as is so that it shows up in the output
+func msg(key string, args ...any) string {
+	format, exists := currentMessages[key]
+	if !exists {
+		format, exists = englishMessages[key]
+	}
+	if !exists {
+		return key
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// englishMessages is the reference catalog; every other catalog translates these keys
+var englishMessages = MessageCatalog{
+	// Main menu
+	"error":           "Error:",
+	"error.plugins":   "Error loading bot plugins:",
+	"error.profiles":  "Error loading bot profiles:",
+	"menu.title":      "🎯 Welcome to 3D Tic-Tac-Toe! 🎯",
+	"menu.choose":     "Choose game mode:",
+	"menu.pvp":        "1. Player vs Player (PvP)",
+	"menu.pve":        "2. Player vs Bot (PvE)",
+	"menu.eve":        "3. Bot vs Bot (Eve)",
+	"menu.pvestream":  "4. PvE Stream (Multi-Depth Analysis)",
+	"menu.evestream":  "5. EvE Stream (Bidirectional Persistent Search)",
+	"menu.profiles":   "6. Player Profiles",
+	"menu.mirror":     "7. Analysis Board (Mirror a Game)",
+	"menu.exit":       "8. Exit",
+	"menu.prompt":     "Enter your choice (1-8): ",
+	"menu.goodbye":    "Thanks for playing! Goodbye! 👋",
+	"menu.invalid":    "Invalid choice. Please select 1, 2, 3, 4, 5, 6, 7, or 8.",
+	"choice.prompt":   "Enter your choice (1-%d): ",
+	"choice.fallback": "Invalid choice, defaulting to RandomBot.",
+
+	// Shared game messages
+	"game.welcome":      "Welcome to 3D Tic-Tac-Toe!",
+	"game.move_format":  "Enter moves in format like A1, B2, etc. (A-%c, 1-%d)\n",
+	"game.invalid_move": "Invalid move! Try again.",
+	"game.draw":         "\n🤝 It's a draw! The board is full. 🤝",
+	"game.draw_short":   "🤝 It's a draw! 🤝",
+	"game.you_win":      "🎉 You win! 🎉",
+
+	// Board selection
+	"board.preset":         "%dx%dx%d (%d in a row)",
+	"board.choose":         "\nChoose the board:",
+	"board.default":        " [default]",
+	"board.custom":         "%d. Custom\n",
+	"board.prompt":         "Enter your choice (1-%d, Enter for default): ",
+	"board.custom_prompt":  "Enter length, width, height and win length (e.g. 4 4 4 4): ",
+	"board.invalid_custom": "Invalid board: %v. Using the default board.\n",
+
+	// Player vs Player
+	"pvp.title":   "🎮 Player vs Player Mode",
+	"pvp.player1": "Player 1",
+	"pvp.player2": "Player 2",
+	"pvp.turn":    "\n%s's turn (playing '%c'): ",
+	"pvp.placed":  "Move %s placed at coordinates: (%d, %d, %d)\n",
+	"pvp.wins":    "\n🎉 %s wins! 🎉\n",
+
+	// Player vs Bot
+	"pve.title":             "🤖 Player vs Bot Mode",
+	"pve.choose_difficulty": "Choose a difficulty:",
+	"pve.custom":            "%d. Custom (pick a specific bot)\n",
+	"pve.choose_opponent":   "Choose your opponent:",
+	"pve.you_face":          "You will face %s!\n",
+	"pve.enter_name":        "Enter your profile name (Enter for %s): ",
+	"pve.adaptive_welcome":  "Welcome %s! Starting at level %d.\n",
+	"pve.training_prompt":   "Enable training mode (warns about blunders)? (y/n): ",
+	"pve.sides":             "You are '%c', %s is '%c'\n",
+	"pve.hint_help":         "Type 'hint' for a suggested move, or 'hint 3' for the top three candidates",
+	"pve.heat_help":         "Type 'heat' to toggle the heat-map of move strength",
+	"pve.heat_legend":       "Heat-map: '%c' good, '%c' neutral, '%c' bad\n",
+	"pve.your_turn":         "\nYour turn (playing '%c'): ",
+	"pve.hint":              "💡 Hint: %s - %s\n",
+	"pve.keep_prompt":       "Keep it? (y/n): ",
+	"pve.taken_back":        "Move taken back.",
+	"pve.your_move":         "Your move %s placed at coordinates: (%d, %d, %d)\n",
+	"pve.you_win":           "\n🎉 You win! 🎉\n",
+	"pve.thinking":          "\n%s is thinking...\n",
+	"pve.time_taken":        "Time taken by %s: %v\n",
+	"pve.bot_plays":         "%s plays %s at coordinates: (%d, %d, %d)\n",
+	"pve.bot_wins":          "\n🤖 %s wins! Better luck next time! 🤖\n",
+	"pve.bot_forfeits":      "\n⚠️ %s cannot move (%v) and forfeits! You win! ⚠️\n",
+
+	// Difficulty levels (keyed by the lowercase level name)
+	"difficulty.easy":          "Easy",
+	"difficulty.easy.desc":     "takes wins and blocks threats, otherwise plays randomly",
+	"difficulty.medium":        "Medium",
+	"difficulty.medium.desc":   "looks 3 moves ahead",
+	"difficulty.hard":          "Hard",
+	"difficulty.hard.desc":     "plays at about 1700 Elo, with the odd slip",
+	"difficulty.expert":        "Expert",
+	"difficulty.expert.desc":   "plays the solved 3x3x3 board perfectly; other boards have no solver, so there it looks as far ahead as 3 seconds a move allow",
+	"difficulty.perfect":       "Perfect",
+	"difficulty.perfect.desc":  "never misses a win on the 3x3x3 board, where the first player wins",
+	"difficulty.adaptive":      "Adaptive",
+	"difficulty.adaptive.desc": "adjusts to keep your win rate near 50%",
+
+	// Bot vs Bot
+	"eve.title":              "🤖 Bot vs Bot Mode (Eve) 🤖",
+	"eve.choose":             "Choose the bots to fight:",
+	"eve.select_bot1":        "\nSelect Bot 1 (plays 'x'):",
+	"eve.select_bot2":        "\nSelect Bot 2 (plays 'o'):",
+	"eve.autoplay_prompt":    "\nPress Enter to continue between moves, type 'auto' for automatic play, or 'quiet' to show only the result...",
+	"eve.begins":             "\n🎯 Bot Battle Begins! 🎯",
+	"eve.versus":             "%s ('x') vs %s ('o')\n",
+	"eve.thinking":           "\n%s ('%c') is thinking...\n",
+	"eve.forfeit":            "\n⚠️ %s ('%c') cannot move (%v) and forfeits! %s ('%c') wins! ⚠️\n",
+	"eve.time_loss":          "\n⏰ %s ('%c') exceeded the %v time limit and loses on time! %s ('%c') wins! ⏰\n",
+	"eve.plays":              "%s plays %s at (%d, %d, %d) - Time: %v (Avg: %v)\n",
+	"eve.wins":               "\n🎉 %s ('%c') wins! 🎉\n",
+	"eve.adjudicated_win":    "\n⚖️  Both bots agree %s ('%c') has won: the game is adjudicated ⚖️\n",
+	"eve.adjudicated_draw":   "\n⚖️  Both bots agree the position is even: the game is adjudicated a draw ⚖️\n",
+	"eve.resigns":            "\n🏳️  %s ('%c') resigns! %s ('%c') wins! 🏳️\n",
+	"eve.press_enter":        "Press Enter to continue (or type 'save' to save the game)...",
+	"eve.worker":             "   Worker %d: %d nodes, %d root moves (%d stolen)\n",
+	"eve.tt":                 "   Transposition table: %.1f%% full (%d of %d entries, %.0f MiB)\n",
+	"match.prompt":           "\nNumber of games (Enter for 1; bots swap sides after every game): ",
+	"match.begins":           "\n🏁 Match of %d games on %d workers, sides swap after every game 🏁\n",
+	"match.game":             "[%d/%d] Game %d: %s ('x') vs %s ('o') - %s in %d moves; score so far %.1f%%\n",
+	"match.winner":           "%s wins",
+	"match.adjudicated":      "%s (adjudicated)",
+	"match.resigned":         "%s wins by resignation",
+	"match.draw":             "draw",
+	"match.title":            "\n📊 Match Results 📊",
+	"match.record":           "   %s: %d wins, %d draws, %d losses against %s\n",
+	"match.score":            "   Score of %s: %.1f%% ± %.1f%% (95%% confidence)\n",
+	"match.length":           "   Average game length: %.1f moves\n",
+	"match.adjudications":    "   Adjudicated games: %d\n",
+	"match.resign_wins":      "   Wins by resignation: %d for %s, %d for %s\n",
+	"match.average_time":     "   Average move time of %s: %v\n",
+	"tournament.begins":      "\n🏆 Round-robin tournament: %d bots, %d pairings of %d games each 🏆\n",
+	"tournament.pairing":     "[%d/%d] %s %d-%d-%d %s\n",
+	"tournament.crosstable":  "\n📊 Crosstable (points of the row bot against the column bot) 📊",
+	"tournament.standings":   "\n🏆 Standings 🏆",
+	"tournament.standing":    "%2d. %-*s %5.1f points from %d games (+%d =%d -%d)  rating %s\n",
+	"gauntlet.begins":        "\n🛡️  Gauntlet: %s against %d reference bots, %d games each 🛡️\n",
+	"gauntlet.opponent":      "   vs %-*s +%d =%d -%d  score %.1f%% ± %.1f%%\n",
+	"gauntlet.overall":       "\n📊 Overall score of %s: %.1f%% ± %.1f%% from %d games (+%d =%d -%d) 📊\n",
+	"sprt.begins":            "SPRT: H0 elo %g, H1 elo %g, alpha %g, beta %g; accepts H0 at LLR %.2f and H1 at LLR %.2f\n",
+	"sprt.llr":               "   LLR %.2f\n",
+	"sprt.h1":                "   SPRT accepted H1 after %d games (LLR %.2f): bot 1 is the stronger bot\n",
+	"sprt.h0":                "   SPRT accepted H0 after %d games (LLR %.2f): bot 1 is not the stronger bot\n",
+	"sprt.undecided":         "   SPRT undecided after every game (LLR %.2f)\n",
+	"csv.error":              "⚠️  Could not write CSV export:",
+	"archive.error":          "⚠️  Could not add the game to the archive:",
+	"db.error":               "⚠️  Could not record the game in the database:",
+	"ratings.save_error":     "⚠️  Could not save ratings:",
+	"leaderboard.title":      "\n🏆 Leaderboard 🏆",
+	"leaderboard.empty":      "No rated games match.",
+	"leaderboard.entry":      "%3d. %-*s %-5s %11s  %4d games (+%d =%d -%d)  last played %s\n",
+	"leaderboard.kind_bot":   "bot",
+	"leaderboard.kind_human": "human",
+	"games.entry":            "#%-4d %s  %dx%dx%d/%d  %s ('x') vs %s ('o'): %s in %d moves  [%s]  hash %016x\n",
+	"games.count":            "%d games\n",
+	"games.none":             "No games match.",
+	"games.unfinished":       "unfinished",
+	"games.exported":         "Game %d written to %s; view it with --replay\n",
+	"openings.board":         "\n%dx%dx%d/%d (%d games), after %s, '%c' to move:\n",
+	"openings.start":         "the start",
+	"openings.entry":         "  %-4s %5d games  x %d = %d o %d  scores %.1f%%\n",
+	"openings.none":          "No openings match.",
+	"train.progress":         "Episode %d/%d: exploring %.2f, %d positions learned\n",
+	"train.saved":            "Saved %s: %d positions from %d episodes\n",
+	"train.score":            "Against random moves over %d games per side: scores %.1f%% as 'x', %.1f%% as 'o'\n",
+	"calibrate.level":        "Level %d Elo (depth %d, temperature %d):\n",
+	"calibrate.reference":    "  against %s (%.0f): +%d =%d -%d, performs at %.0f\n",
+	"calibrate.performance":  "  Level %d performs at %.0f Elo overall\n",
+	"tune.begins":            "Tuning %s, parameter %s, on %dx%dx%d/%d, at most %d games per value, SPRT [%g, %g]:\n",
+	"tune.result":            "  %s=%d: +%d =%d -%d, scores %.1f%% ± %.1f%% (%+.0f Elo), LLR %.2f, %s\n",
+	"tune.undecided":         "undecided",
+	"ttbench.game":           "Searching the %d positions of a %s game at depth %d, with %d MiB tables:\n",
+	"ttbench.policy":         "  %-8s %10v  hits %5.1f%%, cutoffs %d, overwritten %d (%d stale), rejected %d, %.1f%% full, %d allocations\n",
+	"ttbench.parallel":       "With the concurrent bot's table shared by its searches, on %d threads:\n",
+	"ttbench.locking":        "  %-11s %10v  hits %5.1f%%, cutoffs %d, contended locks %d of %d, waited %.1fms, %d allocations\n",
+	"profile.title":          "\n👤 Player Profiles",
+	"profile.choice":         "%d. %s (%d games)\n",
+	"profile.prompt":         "Select a profile by number or enter a new name (Enter keeps %s): ",
+	"profile.summary":        "\n👤 Profile: %s\n",
+	"profile.no_games":       "No games played yet.",
+	"profile.record":         "Games: %d (%d wins, %d draws, %d losses), win rate %.1f%%\n",
+	"profile.move_time":      "Average move time: %.2fs over %d moves\n",
+	"profile.rating":         "Rating: %s\n",
+	"profile.last_played":    "Last played: %s\n",
+	"profile.tendencies":     "Favorite columns: %s; blocked %d of %d threats along a column and %d of %d others\n",
+	"profile.opponents":      "Results per bot:",
+	"profile.opponent":       "  %s (%s): %d games, %d-%d-%d, win rate %.1f%%\n",
+	"seed.replay":            "🎲 Seed %d (run again with --seed to replay)\n",
+	"net.listening":          "🌐 Waiting for an opponent on %s...\n",
+	"net.joined":             "🌐 %s joined the game. You play 'x'.\n",
+	"net.connected":          "🌐 Connected! You play '%c' against %s.\n",
+	"net.help":               "Type a move to play it, \"chat <message>\" to talk to your opponent, or \"quit\" to resign.",
+	"net.your_turn":          "\nYour turn (playing '%c'): ",
+	"net.waiting_move":       "\nWaiting for %s's move...\n",
+	"net.not_your_turn":      "It's not your turn.",
+	"net.moved":              "%s played %s.\n",
+	"net.chat":               "💬 %s: %s\n",
+	"net.disconnected":       "🔌 %s disconnected; waiting up to %s for them to reconnect...\n",
+	"net.reconnected":        "🔌 %s reconnected.\n",
+	"net.reconnecting":       "🔌 Connection lost, reconnecting...\n",
+	"net.abandoned":          "🔌 %s did not come back; you win by forfeit.\n",
+	"net.resigned":           "🏳️  %s resigned.\n",
+	"serve.listening":        "🌐 Serving the HTTP API and the web UI on %s\n",
+	"metrics.listening":      "📈 Serving metrics on http://%s/metrics\n",
+	"serve.stopping":         "\n🛑 Shutting down: finishing the requests in flight (at most %s)...\n",
+	"grpc.listening":         "🌐 Serving the gRPC engine service on %s\n",
+	"arena.listening":        "Arena accepting engines on %s\n",
+	"arena.websocket":        "Arena accepting WebSocket engines on ws://%s/arena\n",
+	"arena.connected":        "%s connected (rating %s)\n",
+	"arena.disconnected":     "%s disconnected\n",
+	"arena.match":            "Match: %s vs %s, %d games\n",
+	"arena.seek":             "%s seeks a game on %s, clock %s\n",
+	"arena.challenge":        "%s challenges %s to a game on %s, clock %s\n",
+	"arena.game_over":        "%s (x) vs %s (o): winner %s (%s)\n",
+	"arena.welcome":          "Logged in to the arena as %s (rating %s)\n",
+	"arena.message":          "Arena: %s\n",
+	"broadcast.listening":    "📺 Spectators can watch on %s (the watch command)\n",
+	"broadcast.websocket":    "📺 Spectators can watch over WebSocket on ws://%s/spectate\n",
+	"watch.connected":        "📺 Watching the games broadcast on %s\n",
+	"watch.closed":           "📺 The broadcast has ended\n",
+	"watch.game_started":     "\n🎮 Game %d (%s): %s (X) vs %s (O) on %dx%dx%d/%d\n",
+	"watch.move":             "\nGame %d: %s (%s) plays %s\n",
+	"watch.game_over":        "🏁 Game %d over: %s (%s)\n",
+
+	"chat.connected":     "💬 Connected to %s as %s, playing in %s\n",
+	"chat.help":          "Commands: !play [x|o] [bot] starts a game against the bot (you play x unless you say o)\n!move A1 (or !m A1) plays a move, row letter then column number; the piece falls to the bottom\n!board shows the board, each column from bottom to top, and !resign gives up\n",
+	"chat.in_progress":   "A game against %s is already in progress here; wait for it to end",
+	"chat.no_game":       "No game in progress here; start one with !play",
+	"chat.not_your_game": "%s: this game belongs to %s",
+	"chat.play_usage":    "Usage: !play [x|o] [bot], e.g. !play o alphabeta:depth=4",
+	"chat.move_usage":    "Usage: !move A1",
+	"chat.error":         "%s: %v",
+	"chat.started":       "%s plays %c against %s on %dx%dx%d, %d in a row wins",
+	"chat.your_move":     "%s, your move (e.g. !move A1)",
+	"chat.illegal":       "%s: %s is not a legal move",
+	"chat.bot_moved":     "%s plays %s",
+	"chat.won":           "🏆 %s wins!",
+	"chat.draw":          "🤝 Draw: the board is full",
+	"chat.resigned":      "%s resigns; %s wins",
+	"chat.time_loss":     "⏰ %s did not move within %s and loses on time",
+	"chat.forfeit":       "%s cannot move (%v) and forfeits",
+	"stats.title":        "\n📊 Final Performance Statistics 📊",
+	"stats.total_moves":  "   Total Moves: %d\n",
+	"stats.total_time":   "   Total Time:  %v\n",
+	"stats.average_time": "   Average Time: %v\n",
+	"stats.tt_fill":      "   Transposition Table: %.1f%% full (%d of %d entries)\n",
+	"stats.comparison":   "\n⚡ Performance Comparison:",
+	"stats.faster":       "   %s is %.2fx faster than %s\n",
+	"stats.similar":      "   Both bots have similar performance!",
+
+	// PvE Stream
+	"pvestream.title":          "🌊 PvE Stream Mode - Multi-Depth Analysis 🌊",
+	"pvestream.intro1":         "This mode runs multiple concurrent alpha-beta bots with different depths",
+	"pvestream.intro2":         "and shows real-time analysis as they find better moves!",
+	"pvestream.depths":         "Analyzing with depths: %v\n",
+	"pvestream.bot_wins":       "🤖 Bot wins! 🤖",
+	"pvestream.prompt":         "Your turn! Enter move (e.g., A1, B2, C3): ",
+	"pvestream.invalid_format": "Invalid format! Use format like A1, B2, C3",
+	"pvestream.you_played":     "You played %s at (%d, %d, %d)\n",
+	"pvestream.analyzing":      "🤖 Multi-Depth Bot is analyzing...",
+	"pvestream.new_best":       "📈 New best move from depth %d: [%s] (Score: %d)\n",
+	"pvestream.final":          "🎯 Final decision from depth %d: [%s]\n",
+	"pvestream.bot_plays":      "🤖 Bot plays %s at (%d, %d, %d) - Time: %v\n",
+	"pvestream.no_move":        "🤖 Bot cannot find a valid move!",
+	"pvestream.game_over":      "\nGame Over! Thanks for playing! 👋",
+
+	// EvE Stream
+	"evestream.title":         "🤖⚔️🤖 EvE Stream Mode - Bidirectional Persistent Search 🤖⚔️🤖",
+	"evestream.intro1":        "Two persistent minimax bots with background calculation!",
+	"evestream.intro2":        "Each bot continues calculating during opponent's thinking time.",
+	"evestream.versus":        "🤖 %s (X) vs %s (O) 🤖\n",
+	"evestream.wins":          "🎉 %s (%c) wins! 🎉\n",
+	"evestream.move":          "Move %d: ",
+	"evestream.thinking":      "%s (%c) is thinking...",
+	"evestream.no_move":       "\n🚨 %s cannot find a valid move!\n",
+	"evestream.plays":         " -> %s at (%d, %d, %d) [Time: %v]\n",
+	"evestream.game_over":     "\nGame Over! Both bots performed persistent background search! 🎯",
+	"evestream.final_title":   "\n📊 Final Search Statistics:",
+	"evestream.search_stats":  "   📈 Search Stats - Active: %s, Background: %s\n",
+	"evestream.nodes":         "   🔍 Active bot nodes: %d, Background bot nodes: %d\n",
+	"evestream.thinking_time": "   ⏱️  Thinking time: %v (Background bot was calculating simultaneously)\n",
+	"evestream.final_nodes":   "🤖 %s final nodes: %d\n",
+	"evestream.persistent":    "Both bots maintained persistent search trees throughout the game!",
+
+	// Analysis (hints, candidates, training mode, live line)
+	"hint.win_now":         "completes a line and wins immediately",
+	"hint.block":           "blocks '%c' from winning next move",
+	"hint.no_time":         "no time to search, any legal move will do",
+	"score.forced_win":     "forces a win within %d moves",
+	"score.forced_loss":    "the opponent can force a win within %d moves, this resists longest",
+	"score.better":         "best position found looking %d moves ahead (score %+d)",
+	"score.worse":          "limits the damage looking %d moves ahead (score %+d)",
+	"score.balanced":       "keeps the position balanced looking %d moves ahead",
+	"score.x_wins":         "x wins",
+	"score.o_wins":         "o wins",
+	"candidates.none":      "No candidate moves found in time.",
+	"candidates.title":     "💡 Top %d moves (searched %d moves ahead):\n",
+	"candidates.row":       "  %d. %-4s score %s, win chance %3.0f%%, line: %s\n",
+	"candidates.playouts":  "  %d. %-4s score %s, win chance %3.0f%% (won %3.0f%% of random playouts), line: %s\n",
+	"playouts.bar":         "  random playouts: X %.0f%%, O %.0f%% of %d",
+	"mirror.title":         "🔍 Analysis Board: enter the moves of a game played elsewhere",
+	"mirror.help":          "The engine comments on every move and never plays itself; type 'hint' for the side to play, or 'hint 3' for the top three candidates",
+	"mirror.best":          "✅ %s is the best move (depth %d)",
+	"mirror.playable":      "👍 %s is playable (%s), though %s was better (%s)",
+	"mirror.no_time":       "(no time to judge the move)",
+	"blunder.loses":        "⚠️  %s loses by force (%s was safer)",
+	"blunder.misses_win":   "⚠️  %s misses a forced win with %s",
+	"blunder.drops":        "⚠️  %s drops the advantage: win chance %.0f%% instead of %.0f%% with %s",
+	"live.line":            "   [%2.0fs] depth %d: %s  %s\n",
+	"profile.create_error": "Cannot create bot from profile:",
+	"profile.entry":        "%d. %s (profile: %s)\n",
+
+	// Accessible board output and player statistics
+	"board.empty":             "empty",
+	"board.marked":            " (marked %c)",
+	"board.has_won":           "%c has won.\n",
+	"player_stats.load_error": "Cannot load player stats, starting fresh:",
+	"qtable.load_error":       "Cannot load the Q-learning table, starting empty:",
+	"player_stats.save_error": "Cannot save player stats:",
+	"searchcache.save_error":  "Cannot save the search cache:",
+
+	// Rematch
+	"rematch.prompt": "Rematch with sides swapped? (y/n): ",
+
+	// Interrupt handling
+	"interrupt.abandoned":  "\n⏸️  Match interrupted; %d games in progress were abandoned\n",
+	"interrupt.saved":      "\n⏸️  Game interrupted after %d moves, saved to %s\n",
+	"interrupt.save_error": "⏸️  Game interrupted, but it could not be saved:",
+
+	// Save and resume
+	"save.help":        "Type 'save' or 'save <file>' to save the game and resume it later",
+	"save.saved":       "💾 Game saved to %s\n",
+	"save.error":       "Cannot save the game:",
+	"save.resume_hint": "Resume it with: --resume %s\n",
+	"events.resuming":  "Resuming the run logged in %s: %d finished games are counted and %d in progress replayed\n",
+	"save.resuming":    "Resuming %s after %d moves\n",
+
+	// Crash recovery
+	"journal.error":          "⚠️  Could not keep the game journal:",
+	"journal.recovered":      "🩹 %d games were cut short by a crash and have been recovered:\n",
+	"journal.recovered_game": "   %s (%d moves)\n",
+	"journal.recovered_over": "   %s (%d moves, already over)\n",
+	"journal.resume_prompt":  "Continue %s now? (y/n): ",
+
+	// Replay viewer
+	"replay.title":     "🎬 Replaying %s: %s ('x') vs %s ('o'), %d moves\n",
+	"replay.help":      "Enter: next move, 'back': previous move, 'auto [delay]': play by itself (e.g. 'auto 500ms', Enter pauses), 'quit': leave",
+	"replay.start":     "Start position (%d moves to go)\n",
+	"replay.move":      "Move %d/%d: %s ('%c') plays %s\n",
+	"replay.prompt":    "replay> ",
+	"replay.autoplay":  "▶️  Playing one move every %v, press Enter to pause\n",
+	"replay.paused":    "⏸️  Paused",
+	"replay.end":       "End of the recorded moves.",
+	"replay.bad_delay": "Invalid delay %q, use a duration like 500ms or 2s\n",
+
+	// Cursor input
+	"cursor.help":        "Arrow keys move the cursor, Enter drops a piece, 't' types a move or command",
+	"cursor.status":      "Cursor: %s - '%c' lands at height %d",
+	"cursor.full":        "Cursor: %s - column is full",
+	"cursor.type_prompt": "> ",
+	"cursor.unavailable": "Cursor input needs an interactive terminal, falling back to typed moves.",
+}
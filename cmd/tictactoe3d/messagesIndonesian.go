@@ -0,0 +1,352 @@
+package main
+
+// indonesianMessages is the Indonesian (id) translation of englishMessages
+var indonesianMessages = MessageCatalog{
+	// Main menu
+	"error":           "Galat:",
+	"error.plugins":   "Galat saat memuat plugin bot:",
+	"error.profiles":  "Galat saat memuat profil bot:",
+	"menu.title":      "🎯 Selamat datang di Tic-Tac-Toe 3D! 🎯",
+	"menu.choose":     "Pilih mode permainan:",
+	"menu.pvp":        "1. Pemain vs Pemain (PvP)",
+	"menu.pve":        "2. Pemain vs Bot (PvE)",
+	"menu.eve":        "3. Bot vs Bot (Eve)",
+	"menu.pvestream":  "4. PvE Stream (Analisis Multi-Kedalaman)",
+	"menu.evestream":  "5. EvE Stream (Pencarian Persisten Dua Arah)",
+	"menu.profiles":   "6. Profil Pemain",
+	"menu.mirror":     "7. Papan Analisis (Mencerminkan Permainan)",
+	"menu.exit":       "8. Keluar",
+	"menu.prompt":     "Masukkan pilihan Anda (1-8): ",
+	"menu.goodbye":    "Terima kasih sudah bermain! Sampai jumpa! 👋",
+	"menu.invalid":    "Pilihan tidak valid. Pilih 1, 2, 3, 4, 5, 6, 7, atau 8.",
+	"choice.prompt":   "Masukkan pilihan Anda (1-%d): ",
+	"choice.fallback": "Pilihan tidak valid, menggunakan RandomBot.",
+
+	// Shared game messages
+	"game.welcome":      "Selamat datang di Tic-Tac-Toe 3D!",
+	"game.move_format":  "Masukkan langkah dengan format seperti A1, B2, dst. (A-%c, 1-%d)\n",
+	"game.invalid_move": "Langkah tidak valid! Coba lagi.",
+	"game.draw":         "\n🤝 Seri! Papan sudah penuh. 🤝",
+	"game.draw_short":   "🤝 Seri! 🤝",
+	"game.you_win":      "🎉 Anda menang! 🎉",
+
+	// Board selection
+	"board.preset":         "%dx%dx%d (%d berderet)",
+	"board.choose":         "\nPilih papan:",
+	"board.default":        " [bawaan]",
+	"board.custom":         "%d. Kustom\n",
+	"board.prompt":         "Masukkan pilihan Anda (1-%d, Enter untuk bawaan): ",
+	"board.custom_prompt":  "Masukkan panjang, lebar, tinggi, dan panjang deret kemenangan (mis. 4 4 4 4): ",
+	"board.invalid_custom": "Papan tidak valid: %v. Menggunakan papan bawaan.\n",
+
+	// Player vs Player
+	"pvp.title":   "🎮 Mode Pemain vs Pemain",
+	"pvp.player1": "Pemain 1",
+	"pvp.player2": "Pemain 2",
+	"pvp.turn":    "\nGiliran %s (bermain '%c'): ",
+	"pvp.placed":  "Langkah %s ditempatkan di koordinat: (%d, %d, %d)\n",
+	"pvp.wins":    "\n🎉 %s menang! 🎉\n",
+
+	// Player vs Bot
+	"pve.title":             "🤖 Mode Pemain vs Bot",
+	"pve.choose_difficulty": "Pilih tingkat kesulitan:",
+	"pve.custom":            "%d. Kustom (pilih bot tertentu)\n",
+	"pve.choose_opponent":   "Pilih lawan Anda:",
+	"pve.you_face":          "Anda akan melawan %s!\n",
+	"pve.enter_name":        "Masukkan nama profil Anda (Enter untuk %s): ",
+	"pve.adaptive_welcome":  "Selamat datang %s! Mulai di level %d.\n",
+	"pve.training_prompt":   "Aktifkan mode latihan (peringatan langkah blunder)? (y/n): ",
+	"pve.sides":             "Anda bermain '%c', %s bermain '%c'\n",
+	"pve.hint_help":         "Ketik 'hint' untuk saran langkah, atau 'hint 3' untuk tiga kandidat terbaik",
+	"pve.heat_help":         "Ketik 'heat' untuk menampilkan/menyembunyikan peta kekuatan langkah",
+	"pve.heat_legend":       "Peta langkah: '%c' bagus, '%c' netral, '%c' buruk\n",
+	"pve.your_turn":         "\nGiliran Anda (bermain '%c'): ",
+	"pve.hint":              "💡 Saran: %s - %s\n",
+	"pve.keep_prompt":       "Tetap dimainkan? (y/n): ",
+	"pve.taken_back":        "Langkah dibatalkan.",
+	"pve.your_move":         "Langkah Anda %s ditempatkan di koordinat: (%d, %d, %d)\n",
+	"pve.you_win":           "\n🎉 Anda menang! 🎉\n",
+	"pve.thinking":          "\n%s sedang berpikir...\n",
+	"pve.time_taken":        "Waktu yang dipakai %s: %v\n",
+	"pve.bot_plays":         "%s memainkan %s di koordinat: (%d, %d, %d)\n",
+	"pve.bot_wins":          "\n🤖 %s menang! Semoga lebih beruntung lain kali! 🤖\n",
+	"pve.bot_forfeits":      "\n⚠️ %s tidak dapat melangkah (%v) dan kalah! Anda menang! ⚠️\n",
+
+	// Difficulty levels (keyed by the lowercase level name)
+	"difficulty.easy":          "Mudah",
+	"difficulty.easy.desc":     "mengambil kemenangan dan memblok ancaman, selebihnya acak",
+	"difficulty.medium":        "Sedang",
+	"difficulty.medium.desc":   "melihat 3 langkah ke depan",
+	"difficulty.hard":          "Sulit",
+	"difficulty.hard.desc":     "bermain di sekitar 1700 Elo, sesekali keliru",
+	"difficulty.expert":        "Ahli",
+	"difficulty.expert.desc":   "memainkan papan 3x3x3 yang sudah terpecahkan dengan sempurna; papan lain tidak punya pemecah, jadi di sana melihat sejauh yang dimungkinkan 3 detik per langkah",
+	"difficulty.perfect":       "Sempurna",
+	"difficulty.perfect.desc":  "tidak pernah melewatkan kemenangan di papan 3x3x3, tempat pemain pertama menang",
+	"difficulty.adaptive":      "Adaptif",
+	"difficulty.adaptive.desc": "menyesuaikan diri agar peluang menang Anda sekitar 50%",
+
+	// Bot vs Bot
+	"eve.title":              "🤖 Mode Bot vs Bot (Eve) 🤖",
+	"eve.choose":             "Pilih bot yang akan bertanding:",
+	"eve.select_bot1":        "\nPilih Bot 1 (bermain 'x'):",
+	"eve.select_bot2":        "\nPilih Bot 2 (bermain 'o'):",
+	"eve.autoplay_prompt":    "\nTekan Enter untuk lanjut di antara langkah, ketik 'auto' untuk bermain otomatis, atau 'quiet' untuk hanya menampilkan hasil...",
+	"eve.begins":             "\n🎯 Pertarungan Bot Dimulai! 🎯",
+	"eve.versus":             "%s ('x') vs %s ('o')\n",
+	"eve.thinking":           "\n%s ('%c') sedang berpikir...\n",
+	"eve.forfeit":            "\n⚠️ %s ('%c') tidak dapat melangkah (%v) dan kalah! %s ('%c') menang! ⚠️\n",
+	"eve.time_loss":          "\n⏰ %s ('%c') melewati batas waktu %v dan kalah waktu! %s ('%c') menang! ⏰\n",
+	"eve.plays":              "%s memainkan %s di (%d, %d, %d) - Waktu: %v (Rata-rata: %v)\n",
+	"eve.wins":               "\n🎉 %s ('%c') menang! 🎉\n",
+	"eve.adjudicated_win":    "\n⚖️  Kedua bot sepakat %s ('%c') sudah menang: permainan diputuskan ⚖️\n",
+	"eve.adjudicated_draw":   "\n⚖️  Kedua bot sepakat posisinya seimbang: permainan diputuskan seri ⚖️\n",
+	"eve.resigns":            "\n🏳️  %s ('%c') menyerah! %s ('%c') menang! 🏳️\n",
+	"eve.press_enter":        "Tekan Enter untuk lanjut (atau ketik 'save' untuk menyimpan permainan)...",
+	"eve.worker":             "   Pekerja %d: %d simpul, %d langkah akar (%d dicuri)\n",
+	"eve.tt":                 "   Tabel transposisi: terisi %.1f%% (%d dari %d entri, %.0f MiB)\n",
+	"match.prompt":           "\nJumlah permainan (Enter untuk 1; bot bertukar sisi setiap permainan): ",
+	"match.begins":           "\n🏁 Pertandingan %d permainan dengan %d pekerja, sisi bertukar setiap permainan 🏁\n",
+	"match.game":             "[%d/%d] Permainan %d: %s ('x') vs %s ('o') - %s dalam %d langkah; skor sementara %.1f%%\n",
+	"match.winner":           "%s menang",
+	"match.adjudicated":      "%s (diputuskan)",
+	"match.resigned":         "%s menang karena lawan menyerah",
+	"match.draw":             "seri",
+	"match.title":            "\n📊 Hasil Pertandingan 📊",
+	"match.record":           "   %s: %d menang, %d seri, %d kalah melawan %s\n",
+	"match.score":            "   Skor %s: %.1f%% ± %.1f%% (kepercayaan 95%%)\n",
+	"match.length":           "   Rata-rata panjang permainan: %.1f langkah\n",
+	"match.adjudications":    "   Permainan yang diputuskan: %d\n",
+	"match.resign_wins":      "   Menang karena lawan menyerah: %d untuk %s, %d untuk %s\n",
+	"match.average_time":     "   Rata-rata waktu langkah %s: %v\n",
+	"tournament.begins":      "\n🏆 Turnamen round-robin: %d bot, %d pasangan masing-masing %d permainan 🏆\n",
+	"tournament.pairing":     "[%d/%d] %s %d-%d-%d %s\n",
+	"tournament.crosstable":  "\n📊 Tabel silang (poin bot baris melawan bot kolom) 📊",
+	"tournament.standings":   "\n🏆 Klasemen 🏆",
+	"tournament.standing":    "%2d. %-*s %5.1f poin dari %d permainan (+%d =%d -%d)  rating %s\n",
+	"gauntlet.begins":        "\n🛡️  Gauntlet: %s melawan %d bot referensi, masing-masing %d permainan 🛡️\n",
+	"gauntlet.opponent":      "   vs %-*s +%d =%d -%d  skor %.1f%% ± %.1f%%\n",
+	"gauntlet.overall":       "\n📊 Skor keseluruhan %s: %.1f%% ± %.1f%% dari %d permainan (+%d =%d -%d) 📊\n",
+	"sprt.begins":            "SPRT: H0 elo %g, H1 elo %g, alpha %g, beta %g; menerima H0 pada LLR %.2f dan H1 pada LLR %.2f\n",
+	"sprt.llr":               "   LLR %.2f\n",
+	"sprt.h1":                "   SPRT menerima H1 setelah %d permainan (LLR %.2f): bot 1 lebih kuat\n",
+	"sprt.h0":                "   SPRT menerima H0 setelah %d permainan (LLR %.2f): bot 1 tidak lebih kuat\n",
+	"sprt.undecided":         "   SPRT belum memutuskan setelah semua permainan (LLR %.2f)\n",
+	"csv.error":              "⚠️  Tidak dapat menulis ekspor CSV:",
+	"archive.error":          "⚠️  Tidak dapat menambahkan permainan ke arsip:",
+	"db.error":               "⚠️  Tidak dapat mencatat permainan di basis data:",
+	"ratings.save_error":     "⚠️  Tidak dapat menyimpan rating:",
+	"leaderboard.title":      "\n🏆 Papan Peringkat 🏆",
+	"leaderboard.empty":      "Tidak ada permainan berperingkat yang cocok.",
+	"leaderboard.entry":      "%3d. %-*s %-7s %11s  %4d permainan (+%d =%d -%d)  terakhir bermain %s\n",
+	"leaderboard.kind_bot":   "bot",
+	"leaderboard.kind_human": "manusia",
+	"games.entry":            "#%-4d %s  %dx%dx%d/%d  %s ('x') vs %s ('o'): %s dalam %d langkah  [%s]  hash %016x\n",
+	"games.count":            "%d permainan\n",
+	"games.none":             "Tidak ada permainan yang cocok.",
+	"games.unfinished":       "belum selesai",
+	"games.exported":         "Permainan %d ditulis ke %s; lihat dengan --replay\n",
+	"openings.board":         "\n%dx%dx%d/%d (%d permainan), setelah %s, giliran '%c':\n",
+	"openings.start":         "awal permainan",
+	"openings.entry":         "  %-4s %5d permainan  x %d = %d o %d  skor %.1f%%\n",
+	"openings.none":          "Tidak ada pembukaan yang cocok.",
+	"train.progress":         "Episode %d/%d: eksplorasi %.2f, %d posisi dipelajari\n",
+	"train.saved":            "Disimpan %s: %d posisi dari %d episode\n",
+	"train.score":            "Melawan langkah acak dalam %d permainan per sisi: skor %.1f%% sebagai 'x', %.1f%% sebagai 'o'\n",
+	"calibrate.level":        "Level %d Elo (kedalaman %d, suhu %d):\n",
+	"calibrate.reference":    "  melawan %s (%.0f): +%d =%d -%d, bermain setara %.0f\n",
+	"calibrate.performance":  "  Level %d bermain setara %.0f Elo secara keseluruhan\n",
+	"tune.begins":            "Menyetel %s, parameter %s, di %dx%dx%d/%d, paling banyak %d permainan per nilai, SPRT [%g, %g]:\n",
+	"tune.result":            "  %s=%d: +%d =%d -%d, skor %.1f%% ± %.1f%% (%+.0f Elo), LLR %.2f, %s\n",
+	"tune.undecided":         "belum diputuskan",
+	"ttbench.game":           "Mencari %d posisi permainan %s pada kedalaman %d, dengan tabel %d MiB:\n",
+	"ttbench.policy":         "  %-8s %10v  ditemukan %5.1f%%, dipangkas %d, ditimpa %d (%d usang), ditolak %d, terisi %.1f%%, %d alokasi\n",
+	"ttbench.parallel":       "Dengan tabel bot konkuren dipakai bersama oleh pencariannya, pada %d thread:\n",
+	"ttbench.locking":        "  %-11s %10v  ditemukan %5.1f%%, dipangkas %d, kunci terebut %d dari %d, menunggu %.1fms, %d alokasi\n",
+	"profile.title":          "\n👤 Profil Pemain",
+	"profile.choice":         "%d. %s (%d permainan)\n",
+	"profile.prompt":         "Pilih profil dengan nomor atau masukkan nama baru (Enter tetap %s): ",
+	"profile.summary":        "\n👤 Profil: %s\n",
+	"profile.no_games":       "Belum ada permainan.",
+	"profile.record":         "Permainan: %d (%d menang, %d seri, %d kalah), rasio menang %.1f%%\n",
+	"profile.move_time":      "Rata-rata waktu langkah: %.2fs dari %d langkah\n",
+	"profile.rating":         "Rating: %s\n",
+	"profile.last_played":    "Terakhir bermain: %s\n",
+	"profile.tendencies":     "Kolom favorit: %s; memblok %d dari %d ancaman sepanjang kolom dan %d dari %d lainnya\n",
+	"profile.opponents":      "Hasil per bot:",
+	"profile.opponent":       "  %s (%s): %d permainan, %d-%d-%d, rasio menang %.1f%%\n",
+	"seed.replay":            "🎲 Seed %d (jalankan lagi dengan --seed untuk mengulang)\n",
+	"net.listening":          "🌐 Menunggu lawan di %s...\n",
+	"net.joined":             "🌐 %s bergabung. Anda bermain 'x'.\n",
+	"net.connected":          "🌐 Terhubung! Anda bermain '%c' melawan %s.\n",
+	"net.help":               "Ketik langkah untuk memainkannya, \"chat <pesan>\" untuk berbicara dengan lawan, atau \"quit\" untuk menyerah.",
+	"net.your_turn":          "\nGiliran Anda (bermain '%c'): ",
+	"net.waiting_move":       "\nMenunggu langkah %s...\n",
+	"net.not_your_turn":      "Belum giliran Anda.",
+	"net.moved":              "%s memainkan %s.\n",
+	"net.chat":               "💬 %s: %s\n",
+	"net.disconnected":       "🔌 %s terputus; menunggu hingga %s untuk tersambung kembali...\n",
+	"net.reconnected":        "🔌 %s tersambung kembali.\n",
+	"net.reconnecting":       "🔌 Koneksi terputus, menyambung kembali...\n",
+	"net.abandoned":          "🔌 %s tidak kembali; Anda menang karena lawan mundur.\n",
+	"net.resigned":           "🏳️  %s menyerah.\n",
+	"serve.listening":        "🌐 Melayani HTTP API dan UI web di %s\n",
+	"metrics.listening":      "📈 Melayani metrik di http://%s/metrics\n",
+	"serve.stopping":         "\n🛑 Mematikan server: menyelesaikan permintaan yang sedang berjalan (paling lama %s)...\n",
+	"grpc.listening":         "🌐 Melayani layanan mesin gRPC di %s\n",
+	"arena.listening":        "Arena menerima engine di %s\n",
+	"arena.websocket":        "Arena menerima engine WebSocket di ws://%s/arena\n",
+	"arena.connected":        "%s terhubung (rating %s)\n",
+	"arena.disconnected":     "%s terputus\n",
+	"arena.match":            "Pertandingan: %s vs %s, %d permainan\n",
+	"arena.seek":             "%s mencari permainan di %s, jam %s\n",
+	"arena.challenge":        "%s menantang %s bermain di %s, jam %s\n",
+	"arena.game_over":        "%s (x) vs %s (o): pemenang %s (%s)\n",
+	"arena.welcome":          "Masuk ke arena sebagai %s (rating %s)\n",
+	"arena.message":          "Arena: %s\n",
+	"broadcast.listening":    "📺 Penonton dapat menonton di %s (perintah watch)\n",
+	"broadcast.websocket":    "📺 Penonton dapat menonton lewat WebSocket di ws://%s/spectate\n",
+	"watch.connected":        "📺 Menonton permainan yang disiarkan di %s\n",
+	"watch.closed":           "📺 Siaran telah berakhir\n",
+	"watch.game_started":     "\n🎮 Permainan %d (%s): %s (X) vs %s (O) di %dx%dx%d/%d\n",
+	"watch.move":             "\nPermainan %d: %s (%s) memainkan %s\n",
+	"watch.game_over":        "🏁 Permainan %d selesai: %s (%s)\n",
+
+	"chat.connected":     "💬 Terhubung ke %s sebagai %s, bermain di %s\n",
+	"chat.help":          "Perintah: !play [x|o] [bot] memulai permainan melawan bot (kamu bermain x kecuali memilih o)\n!move A1 (atau !m A1) memainkan langkah, huruf baris lalu nomor kolom; bidak jatuh ke bawah\n!board menampilkan papan, tiap kolom dari bawah ke atas, dan !resign menyerah\n",
+	"chat.in_progress":   "Permainan melawan %s sedang berlangsung di sini; tunggu sampai selesai",
+	"chat.no_game":       "Tidak ada permainan di sini; mulai dengan !play",
+	"chat.not_your_game": "%s: permainan ini milik %s",
+	"chat.play_usage":    "Penggunaan: !play [x|o] [bot], mis. !play o alphabeta:depth=4",
+	"chat.move_usage":    "Penggunaan: !move A1",
+	"chat.error":         "%s: %v",
+	"chat.started":       "%s bermain %c melawan %s di %dx%dx%d, %d berderet menang",
+	"chat.your_move":     "%s, giliranmu (mis. !move A1)",
+	"chat.illegal":       "%s: %s bukan langkah yang sah",
+	"chat.bot_moved":     "%s memainkan %s",
+	"chat.won":           "🏆 %s menang!",
+	"chat.draw":          "🤝 Seri: papan penuh",
+	"chat.resigned":      "%s menyerah; %s menang",
+	"chat.time_loss":     "⏰ %s tidak melangkah dalam %s dan kalah waktu",
+	"chat.forfeit":       "%s tidak dapat melangkah (%v) dan kalah",
+	"stats.title":        "\n📊 Statistik Performa Akhir 📊",
+	"stats.total_moves":  "   Jumlah Langkah: %d\n",
+	"stats.total_time":   "   Total Waktu:  %v\n",
+	"stats.average_time": "   Waktu Rata-rata: %v\n",
+	"stats.tt_fill":      "   Tabel Transposisi: terisi %.1f%% (%d dari %d entri)\n",
+	"stats.comparison":   "\n⚡ Perbandingan Performa:",
+	"stats.faster":       "   %s %.2fx lebih cepat dari %s\n",
+	"stats.similar":      "   Kedua bot memiliki performa yang mirip!",
+
+	// PvE Stream
+	"pvestream.title":          "🌊 Mode PvE Stream - Analisis Multi-Kedalaman 🌊",
+	"pvestream.intro1":         "Mode ini menjalankan beberapa bot alpha-beta konkuren dengan kedalaman berbeda",
+	"pvestream.intro2":         "dan menampilkan analisis langsung saat mereka menemukan langkah yang lebih baik!",
+	"pvestream.depths":         "Menganalisis dengan kedalaman: %v\n",
+	"pvestream.bot_wins":       "🤖 Bot menang! 🤖",
+	"pvestream.prompt":         "Giliran Anda! Masukkan langkah (mis. A1, B2, C3): ",
+	"pvestream.invalid_format": "Format tidak valid! Gunakan format seperti A1, B2, C3",
+	"pvestream.you_played":     "Anda memainkan %s di (%d, %d, %d)\n",
+	"pvestream.analyzing":      "🤖 Bot Multi-Kedalaman sedang menganalisis...",
+	"pvestream.new_best":       "📈 Langkah terbaik baru dari kedalaman %d: [%s] (Skor: %d)\n",
+	"pvestream.final":          "🎯 Keputusan akhir dari kedalaman %d: [%s]\n",
+	"pvestream.bot_plays":      "🤖 Bot memainkan %s di (%d, %d, %d) - Waktu: %v\n",
+	"pvestream.no_move":        "🤖 Bot tidak menemukan langkah yang valid!",
+	"pvestream.game_over":      "\nPermainan Selesai! Terima kasih sudah bermain! 👋",
+
+	// EvE Stream
+	"evestream.title":         "🤖⚔️🤖 Mode EvE Stream - Pencarian Persisten Dua Arah 🤖⚔️🤖",
+	"evestream.intro1":        "Dua bot minimax persisten dengan kalkulasi di latar belakang!",
+	"evestream.intro2":        "Setiap bot terus menghitung selama lawan berpikir.",
+	"evestream.versus":        "🤖 %s (X) vs %s (O) 🤖\n",
+	"evestream.wins":          "🎉 %s (%c) menang! 🎉\n",
+	"evestream.move":          "Langkah %d: ",
+	"evestream.thinking":      "%s (%c) sedang berpikir...",
+	"evestream.no_move":       "\n🚨 %s tidak menemukan langkah yang valid!\n",
+	"evestream.plays":         " -> %s di (%d, %d, %d) [Waktu: %v]\n",
+	"evestream.game_over":     "\nPermainan Selesai! Kedua bot melakukan pencarian persisten di latar belakang! 🎯",
+	"evestream.final_title":   "\n📊 Statistik Pencarian Akhir:",
+	"evestream.search_stats":  "   📈 Statistik Pencarian - Aktif: %s, Latar belakang: %s\n",
+	"evestream.nodes":         "   🔍 Simpul bot aktif: %d, Simpul bot latar belakang: %d\n",
+	"evestream.thinking_time": "   ⏱️  Waktu berpikir: %v (Bot latar belakang menghitung bersamaan)\n",
+	"evestream.final_nodes":   "🤖 %s simpul akhir: %d\n",
+	"evestream.persistent":    "Kedua bot mempertahankan pohon pencarian persisten sepanjang permainan!",
+
+	// Analysis (hints, candidates, training mode, live line)
+	"hint.win_now":         "melengkapi deret dan langsung menang",
+	"hint.block":           "memblok '%c' agar tidak menang di langkah berikutnya",
+	"hint.no_time":         "tidak sempat mencari, langkah legal apa pun boleh",
+	"score.forced_win":     "memaksa kemenangan dalam %d langkah",
+	"score.forced_loss":    "lawan bisa memaksa kemenangan dalam %d langkah, ini bertahan paling lama",
+	"score.better":         "posisi terbaik yang ditemukan dengan melihat %d langkah ke depan (skor %+d)",
+	"score.worse":          "membatasi kerugian dengan melihat %d langkah ke depan (skor %+d)",
+	"score.balanced":       "menjaga posisi tetap seimbang dengan melihat %d langkah ke depan",
+	"score.x_wins":         "x menang",
+	"score.o_wins":         "o menang",
+	"candidates.none":      "Tidak ada kandidat langkah yang ditemukan tepat waktu.",
+	"candidates.title":     "💡 %d langkah teratas (dicari %d langkah ke depan):\n",
+	"candidates.row":       "  %d. %-4s skor %s, peluang menang %3.0f%%, urutan: %s\n",
+	"candidates.playouts":  "  %d. %-4s skor %s, peluang menang %3.0f%% (menang %3.0f%% permainan acak), urutan: %s\n",
+	"playouts.bar":         "  permainan acak: X %.0f%%, O %.0f%% dari %d",
+	"mirror.title":         "🔍 Papan Analisis: masukkan langkah permainan yang dimainkan di tempat lain",
+	"mirror.help":          "Mesin mengomentari setiap langkah dan tidak pernah bermain sendiri; ketik 'hint' untuk pihak yang melangkah, atau 'hint 3' untuk tiga kandidat terbaik",
+	"mirror.best":          "✅ %s adalah langkah terbaik (kedalaman %d)",
+	"mirror.playable":      "👍 %s masih layak (%s), meski %s lebih baik (%s)",
+	"mirror.no_time":       "(tidak sempat menilai langkah)",
+	"blunder.loses":        "⚠️  %s kalah secara paksa (%s lebih aman)",
+	"blunder.misses_win":   "⚠️  %s melewatkan kemenangan paksa dengan %s",
+	"blunder.drops":        "⚠️  %s membuang keunggulan: peluang menang %.0f%% bukannya %.0f%% dengan %s",
+	"live.line":            "   [%2.0fs] kedalaman %d: %s  %s\n",
+	"profile.create_error": "Tidak dapat membuat bot dari profil:",
+	"profile.entry":        "%d. %s (profil: %s)\n",
+
+	// Accessible board output and player statistics
+	"board.empty":             "kosong",
+	"board.marked":            " (bertanda %c)",
+	"board.has_won":           "%c telah menang.\n",
+	"player_stats.load_error": "Tidak dapat memuat statistik pemain, mulai dari awal:",
+	"qtable.load_error":       "Tidak dapat memuat tabel Q-learning, mulai kosong:",
+	"player_stats.save_error": "Tidak dapat menyimpan statistik pemain:",
+	"searchcache.save_error":  "Tidak dapat menyimpan cache pencarian:",
+
+	// Rematch
+	"rematch.prompt": "Main lagi dengan sisi ditukar? (y/n): ",
+
+	// Interrupt handling
+	"interrupt.abandoned":  "\n⏸️  Pertandingan dihentikan; %d permainan yang sedang berjalan dibatalkan\n",
+	"interrupt.saved":      "\n⏸️  Permainan dihentikan setelah %d langkah, disimpan ke %s\n",
+	"interrupt.save_error": "⏸️  Permainan dihentikan, tetapi tidak dapat disimpan:",
+
+	// Save and resume
+	"save.help":        "Ketik 'save' atau 'save <file>' untuk menyimpan permainan dan melanjutkannya nanti",
+	"save.saved":       "💾 Permainan disimpan ke %s\n",
+	"save.error":       "Tidak dapat menyimpan permainan:",
+	"save.resume_hint": "Lanjutkan dengan: --resume %s\n",
+	"events.resuming":  "Melanjutkan run yang dicatat di %s: %d permainan selesai dihitung dan %d yang sedang berjalan diputar ulang\n",
+	"save.resuming":    "Melanjutkan %s setelah %d langkah\n",
+
+	// Pemulihan setelah crash
+	"journal.error":          "⚠️  Tidak dapat menyimpan jurnal permainan:",
+	"journal.recovered":      "🩹 %d permainan terhenti karena crash dan telah dipulihkan:\n",
+	"journal.recovered_game": "   %s (%d langkah)\n",
+	"journal.recovered_over": "   %s (%d langkah, sudah selesai)\n",
+	"journal.resume_prompt":  "Lanjutkan %s sekarang? (y/n): ",
+
+	// Replay viewer
+	"replay.title":     "🎬 Memutar ulang %s: %s ('x') vs %s ('o'), %d langkah\n",
+	"replay.help":      "Enter: langkah berikutnya, 'back': langkah sebelumnya, 'auto [jeda]': putar otomatis (mis. 'auto 500ms', Enter menjeda), 'quit': keluar",
+	"replay.start":     "Posisi awal (%d langkah tersisa)\n",
+	"replay.move":      "Langkah %d/%d: %s ('%c') memainkan %s\n",
+	"replay.prompt":    "replay> ",
+	"replay.autoplay":  "▶️  Memainkan satu langkah setiap %v, tekan Enter untuk menjeda\n",
+	"replay.paused":    "⏸️  Dijeda",
+	"replay.end":       "Akhir langkah yang tercatat.",
+	"replay.bad_delay": "Jeda %q tidak valid, gunakan durasi seperti 500ms atau 2s\n",
+
+	// Cursor input
+	"cursor.help":        "Tombol panah menggerakkan kursor, Enter menjatuhkan bidak, 't' untuk mengetik langkah atau perintah",
+	"cursor.status":      "Kursor: %s - '%c' mendarat di ketinggian %d",
+	"cursor.full":        "Kursor: %s - kolom sudah penuh",
+	"cursor.type_prompt": "> ",
+	"cursor.unavailable": "Input kursor memerlukan terminal interaktif, kembali ke langkah yang diketik.",
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"tic-tac-toe-3d-bots/bots"
+	"tic-tac-toe-3d-bots/engine"
+)
+
+// Metrics are exposed in the Prometheus text format: on /metrics by the serve command,
+// and on a local endpoint of their own with --metrics while games, matches and tournaments run
+//
+//	ttt_games_in_progress           games being played right now
+//	ttt_games_total                 finished games, by mode and winner ("x", "o", "draw" or "none" if interrupted)
+//	ttt_moves_total                 moves played in games, by mode
+//	ttt_moves_served_total          bot moves answered by the HTTP and gRPC servers, by API
+//	ttt_search_duration_seconds     time bots took to choose their moves
+//	ttt_search_nodes_total          positions searched by the bots that count them; its rate is the nodes per second
+//	ttt_tt_lock_contended_total     transposition table shard locks parallel searches found held by another search
+//	ttt_tt_lock_wait_seconds_total  time parallel searches spent waiting for those locks
+//	ttt_search_memory_bytes         estimated memory of the bots' search structures, by kind ("tt", "evalcache" or "tree")
+//	ttt_search_memory_limit_bytes   cap on that memory set with --search-memory (0 for none)
+//	ttt_search_memory_capped_total  reservations of search memory cut short by the cap, by kind
+//	go_goroutines                   goroutines of the program
+
+// SEARCH_DURATION_BUCKETS are the upper bounds, in seconds, of the search duration histogram
+var SEARCH_DURATION_BUCKETS = []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// counterVec is a counter with labels, keyed by its rendered label set, e.g. `{mode="eve"}`
+type counterVec struct {
+	mutex  sync.Mutex
+	values map[string]float64
+}
+
+// add adds value to the counter of the labels, given as name, value pairs
+func (counter *counterVec) add(value float64, labels ...string) {
+	counter.mutex.Lock()
+	defer counter.mutex.Unlock()
+	if counter.values == nil {
+		counter.values = make(map[string]float64)
+	}
+	counter.values[metricLabels(labels...)] += value
+}
+
+// histogram counts observations into cumulative buckets
+type histogram struct {
+	mutex   sync.Mutex
+	bounds  []float64
+	buckets []uint64 // observations at most each bound
+	sum     float64
+	count   uint64
+}
+
+// newHistogram creates a histogram with the bucket upper bounds
+func newHistogram(bounds []float64) *histogram {
+	return &histogram{bounds: bounds, buckets: make([]uint64, len(bounds))}
+}
+
+// observe adds one observation
+func (h *histogram) observe(value float64) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	for i, bound := range h.bounds {
+		if value <= bound {
+			h.buckets[i]++
+		}
+	}
+	h.sum += value
+	h.count++
+}
+
+var (
+	gamesFinished  counterVec
+	movesPlayed    counterVec
+	movesServed    counterVec
+	searchDuration = newHistogram(SEARCH_DURATION_BUCKETS)
+)
+
+// metricLabelEscaper escapes label values as the text format requires
+var metricLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// metricLabels renders name, value pairs as a label set, e.g. `{mode="eve",winner="x"}`; no pairs render as ""
+func metricLabels(labels ...string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	pairs := make([]string, 0, len(labels)/2)
+	for i := 0; i+1 < len(labels); i += 2 {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, labels[i], metricLabelEscaper.Replace(labels[i+1])))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// searchMove asks bot for its move on board, timing the search for ttt_search_duration_seconds
+// and tracing it if --trace-endpoint is set; searches that fail or are cancelled are not timed
+func searchMove(ctx context.Context, bot bots.BotInterface, board *engine.Board) (bots.Move, error) {
+	ctx, span := traceMove(ctx, bot, board)
+	start := time.Now()
+	move, err := bot.MakeMove(ctx, board)
+	if err == nil {
+		searchDuration.observe(time.Since(start).Seconds())
+	}
+	if span != nil {
+		span.SetAttributes("move", move.Name)
+		if err != nil {
+			span.SetAttributes("error", err.Error())
+		}
+		span.End()
+	}
+	return move, err
+}
+
+// countMove counts a played move (an OnMove observer)
+func countMove(move GameMove) {
+	movesPlayed.add(1, "mode", move.Mode)
+}
+
+// countGame counts a finished game (an OnGameEnd observer)
+func countGame(end GameEnd) {
+	winner := end.Result.Winner
+	if winner == "" {
+		winner = "none"
+	}
+	gamesFinished.add(1, "mode", end.Record.Mode, "winner", winner)
+}
+
+func init() {
+	OnMove(countMove)
+	OnGameEnd(countGame)
+}
+
+// writeMetrics writes every metric in the Prometheus text format
+func writeMetrics(writer io.Writer) {
+	activeSessionsMutex.Lock()
+	inProgress := len(activeSessions)
+	activeSessionsMutex.Unlock()
+
+	writeMetric(writer, "ttt_games_in_progress", "gauge", "Games being played.", map[string]float64{"": float64(inProgress)})
+	writeCounterVec(writer, "ttt_games_total", "Finished games by mode and winner.", &gamesFinished)
+	writeCounterVec(writer, "ttt_moves_total", "Moves played in games by mode.", &movesPlayed)
+	writeCounterVec(writer, "ttt_moves_served_total", "Bot moves answered by the servers by API.", &movesServed)
+	writeHistogram(writer, "ttt_search_duration_seconds", "Time bots took to choose their moves.", searchDuration)
+	writeMetric(writer, "ttt_search_nodes_total", "counter", "Positions searched by the bots that count them.", map[string]float64{"": float64(bots.SearchedNodes())})
+	contended, waited := bots.TTLockContention()
+	writeMetric(writer, "ttt_tt_lock_contended_total", "counter", "Transposition table shard locks found held by another search.", map[string]float64{"": float64(contended)})
+	writeMetric(writer, "ttt_tt_lock_wait_seconds_total", "counter", "Time spent waiting for transposition table shard locks.", map[string]float64{"": waited.Seconds()})
+	memory := bots.SearchMemoryUsage()
+	used, capHits := make(map[string]float64), make(map[string]float64)
+	for kind, bytes := range memory.ByKind {
+		used[metricLabels("kind", kind)] = float64(bytes)
+		capHits[metricLabels("kind", kind)] = float64(memory.CapHits[kind])
+	}
+	writeMetric(writer, "ttt_search_memory_bytes", "gauge", "Estimated memory of the bots' search structures by kind.", used)
+	writeMetric(writer, "ttt_search_memory_limit_bytes", "gauge", "Cap on the memory of search structures, 0 for none.", map[string]float64{"": float64(memory.Limit)})
+	writeMetric(writer, "ttt_search_memory_capped_total", "counter", "Reservations of search memory cut short by the cap by kind.", capHits)
+	writeMetric(writer, "go_goroutines", "gauge", "Number of goroutines that currently exist.", map[string]float64{"": float64(runtime.NumGoroutine())})
+}
+
+// writeMetric writes one metric with its values keyed by label set, sorted by label set
+func writeMetric(writer io.Writer, name, kind, help string, values map[string]float64) {
+	fmt.Fprintf(writer, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	labelSets := make([]string, 0, len(values))
+	for labels := range values {
+		labelSets = append(labelSets, labels)
+	}
+	sort.Strings(labelSets)
+	for _, labels := range labelSets {
+		fmt.Fprintf(writer, "%s%s %s\n", name, labels, strconv.FormatFloat(values[labels], 'f', -1, 64))
+	}
+}
+
+// writeCounterVec writes a counter with labels
+func writeCounterVec(writer io.Writer, name, help string, counter *counterVec) {
+	counter.mutex.Lock()
+	values := make(map[string]float64, len(counter.values))
+	for labels, value := range counter.values {
+		values[labels] = value
+	}
+	counter.mutex.Unlock()
+	writeMetric(writer, name, "counter", help, values)
+}
+
+// writeHistogram writes a histogram's buckets, sum and count
+func writeHistogram(writer io.Writer, name, help string, h *histogram) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	fmt.Fprintf(writer, "# HELP %s %s\n# TYPE %s histogram\n", name, help, name)
+	for i, bound := range h.bounds {
+		fmt.Fprintf(writer, "%s_bucket{le=\"%g\"} %d\n", name, bound, h.buckets[i])
+	}
+	fmt.Fprintf(writer, "%s_bucket{le=\"+Inf\"} %d\n%s_sum %s\n%s_count %d\n", name, h.count, name, strconv.FormatFloat(h.sum, 'f', -1, 64), name, h.count)
+}
+
+// metricsHandler answers with every metric
+func metricsHandler(writer http.ResponseWriter, request *http.Request) {
+	writer.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	writeMetrics(writer)
+}
+
+// serveMetrics serves /metrics on address in the background, for the length of the run
+func serveMetrics(address string) (net.Addr, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /metrics", metricsHandler)
+	go http.Serve(listener, mux)
+	return listener.Addr(), nil
+}
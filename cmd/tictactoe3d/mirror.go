@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"tic-tac-toe-3d-bots/engine"
+	"tic-tac-toe-3d-bots/formats"
+)
+
+// RunMirror starts an analysis board following a game played elsewhere
+func RunMirror() {
+	playMirror(chooseBoard(3), defaultPlayerNames())
+}
+
+// playMirror follows a game played elsewhere on the given board, where playerNames[0] plays 'x': the user enters the
+// moves of both sides, and the engine comments on each one, shows the evaluation and suggests moves for the side to
+// play, without ever moving itself
+func playMirror(board *engine.Board, playerNames [2]string) {
+	session := startGame(board, formats.GameRecord{Mode: "mirror", Players: playerNames})
+	defer session.End()
+
+	fmt.Println(msg("mirror.title"))
+	fmt.Print(msg("game.move_format", 'A'+byte(board.Length-1), board.Width))
+	fmt.Println(msg("mirror.help"))
+	fmt.Println(msg("save.help"))
+	fmt.Println()
+
+	turnStart := time.Now()
+	for board.CheckWin() == '|' && !board.IsFull() {
+		symbol := board.NextPlayer()
+		printBoard(board)
+		printEvalBar(session, board, symbol)
+		fmt.Print(msg("pvp.turn", playerNames[formats.SymbolIndex(symbol)], symbol))
+
+		var moveInput, argument string
+		fmt.Scanln(&moveInput, &argument)
+
+		if saveCommand(session, moveInput, argument) {
+			continue
+		}
+
+		if strings.EqualFold(moveInput, "hint") {
+			if hintCount, _ := strconv.Atoi(argument); hintCount > 1 {
+				printCandidates(board, symbol, hintCount, HINT_TIME_LIMIT)
+			} else if hint, ok := suggestHint(board, symbol, HINT_TIME_LIMIT); ok {
+				fmt.Print(msg("pve.hint", hint.Move, hint.Reason))
+			}
+			continue
+		}
+
+		coords := board.Move(moveInput, symbol)
+		if coords[0] == -1 {
+			fmt.Println(msg("game.invalid_move"))
+			continue
+		}
+		fmt.Print(msg("pvp.placed", moveInput, coords[0], coords[1], coords[2]))
+		session.RecordMove(moveInput, time.Since(turnStart))
+		fmt.Println(mirrorComment(board, symbol, moveInput, HINT_TIME_LIMIT))
+		turnStart = time.Now()
+	}
+
+	printBoard(board)
+	if winner := board.CheckWin(); winner != '|' {
+		fmt.Print(msg("pvp.wins", playerNames[formats.SymbolIndex(winner)]))
+	} else {
+		fmt.Println(msg("game.draw"))
+	}
+}
+
+// mirrorComment judges the move symbol has just played on board against the alternatives it had: the best move,
+// a playable one with the better move it missed, or a blunder (see judgeMove)
+func mirrorComment(board *engine.Board, symbol byte, move string, timeLimit time.Duration) string {
+	// Analyse the position before the move on a copy, leaving the live board as it is
+	before := engine.CopyBoard(board)
+	before.UnMove(move)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeLimit)
+	defer cancel()
+	candidates, depth := analyzeCandidates(before, symbol, HINT_MAX_DEPTH, ctx)
+	if len(candidates) == 0 {
+		return msg("mirror.no_time")
+	}
+	if warning := judgeMove(candidates, symbol, move); warning != "" {
+		return warning
+	}
+
+	best := candidates[0]
+	for _, candidate := range candidates {
+		if candidate.Move == move && candidate.Score != best.Score {
+			return msg("mirror.playable", move, formatScore(candidate.Score), best.Move, formatScore(best.Score))
+		}
+	}
+	return msg("mirror.best", move, depth)
+}
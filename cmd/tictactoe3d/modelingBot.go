@@ -0,0 +1,268 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"tic-tac-toe-3d-bots/bots"
+	"tic-tac-toe-3d-bots/engine"
+)
+
+// MODELING_MARGIN is how much lower a move's estimated chance of winning may be than the best move's for the modeling
+// bot to consider it, when it exploits its opponent's tendencies
+const MODELING_MARGIN = 0.05
+
+// PlayerTendencies is what the modeling bot has learned of a human's play over their games against it
+type PlayerTendencies struct {
+	Columns         map[string]int `json:"columns"`          // moves played per column, by move name, e.g. "B2"
+	VerticalBlocked int            `json:"vertical_blocked"` // single threats along a column the human blocked
+	VerticalMissed  int            `json:"vertical_missed"`  // ... and left open
+	OtherBlocked    int            `json:"other_blocked"`    // single threats along any other line the human blocked
+	OtherMissed     int            `json:"other_missed"`     // ... and left open
+}
+
+// add counts the tendencies of another game into these
+func (tendencies *PlayerTendencies) add(game *PlayerTendencies) {
+	if tendencies.Columns == nil {
+		tendencies.Columns = make(map[string]int)
+	}
+	for column, moves := range game.Columns {
+		tendencies.Columns[column] += moves
+	}
+	tendencies.VerticalBlocked += game.VerticalBlocked
+	tendencies.VerticalMissed += game.VerticalMissed
+	tendencies.OtherBlocked += game.OtherBlocked
+	tendencies.OtherMissed += game.OtherMissed
+}
+
+// missRate estimates the chance the human leaves a threat open, vertical or not; with no record it is one half
+func (tendencies *PlayerTendencies) missRate(vertical bool) float64 {
+	blocked, missed := tendencies.OtherBlocked, tendencies.OtherMissed
+	if vertical {
+		blocked, missed = tendencies.VerticalBlocked, tendencies.VerticalMissed
+	}
+	return float64(missed+1) / float64(blocked+missed+2)
+}
+
+// columnShare returns the share of the human's moves played in column
+func (tendencies *PlayerTendencies) columnShare(column string) float64 {
+	total := 0
+	for _, moves := range tendencies.Columns {
+		total += moves
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(tendencies.Columns[column]) / float64(total)
+}
+
+// favoriteColumns returns up to count of the columns the human played most, the most played first
+func (tendencies *PlayerTendencies) favoriteColumns(count int) []string {
+	columns := make([]string, 0, len(tendencies.Columns))
+	for column := range tendencies.Columns {
+		columns = append(columns, column)
+	}
+	sort.Slice(columns, func(i, j int) bool {
+		if tendencies.Columns[columns[i]] != tendencies.Columns[columns[j]] {
+			return tendencies.Columns[columns[i]] > tendencies.Columns[columns[j]]
+		}
+		return columns[i] < columns[j]
+	})
+	return columns[:min(count, len(columns))]
+}
+
+// ModelingBot represents an AI player that learns the tendencies of the human it plays against and exploits them
+// Over its games it records in the human's profile which columns they favor and how often they leave a threat open,
+// telling threats along a column, easy to miss when looking at the board from above, from the others.
+// It scores every move with a search at its depth; among the moves about as good as the best (see MODELING_MARGIN) it
+// plays the one whose threats the human is the most likely to miss: of the kinds they miss, away from their favorite columns
+type ModelingBot struct {
+	bots.BaseBot
+	Depth  int
+	Player string // name of the human opponent whose tendencies the bot learns
+
+	store   *StatsStore
+	game    PlayerTendencies // what this game showed, added to the profile when it ends
+	pending *engine.Board    // the board after the bot's last move, if it left a single threat for the human to answer
+	threat  string           // that threat's move
+}
+
+// NewModelingBot creates a new modeling bot with the given search depth, playing against the named human and
+// keeping their tendencies in the given stats store
+func NewModelingBot(symbol byte, name string, depth int, player string, store *StatsStore) *ModelingBot {
+	bot := &ModelingBot{
+		BaseBot: bots.NewBaseBot(symbol, name),
+		Depth:   depth,
+		store:   store,
+		game:    PlayerTendencies{Columns: make(map[string]int)},
+	}
+	bot.SetPlayer(player)
+	return bot
+}
+
+// init registers ModelingBot with the bot registry
+func init() {
+	bots.RegisterBot(&bots.BotRegistration{
+		Key:         "modeling",
+		DisplayName: "ModelingBot",
+		Description: "learns your favorite columns and blind spots, and plays on them",
+		Order:       13,
+		Defaults:    map[string]int{"depth": 4},
+		New: func(symbol byte, name string, params map[string]int) bots.BotInterface {
+			return NewModelingBot(symbol, name, params["depth"], DEFAULT_PLAYER_NAME, sharedStatsStore())
+		},
+	})
+}
+
+// SetPlayer switches the bot to the profile of the named human
+func (bot *ModelingBot) SetPlayer(player string) {
+	if player == "" {
+		player = DEFAULT_PLAYER_NAME
+	}
+	bot.Player = player
+}
+
+// Tendencies returns what the bot knows of its player, this game included
+func (bot *ModelingBot) Tendencies() *PlayerTendencies {
+	tendencies := &PlayerTendencies{}
+	if record, exists := bot.store.Lookup(bot.Player); exists && record.Tendencies != nil {
+		bot.store.mutex.Lock()
+		tendencies.add(record.Tendencies)
+		bot.store.mutex.Unlock()
+	}
+	tendencies.add(&bot.game)
+	return tendencies
+}
+
+// MakeMove plays the move that best exploits the human's tendencies among those scoring close to the best (implements BotInterface)
+func (bot *ModelingBot) MakeMove(ctx context.Context, board *engine.Board) (bots.Move, error) {
+	if len(board.GetValidMoves()) == 0 {
+		return bots.Move{}, bots.ErrNoValidMoves
+	}
+
+	// No candidates means the search was cancelled before its first depth completed. A forced result is played as
+	// searched: its score cannot tell a quick loss from a slow one, nor keep a win in reach
+	candidates, _ := analyzeCandidates(board, bot.Symbol(), bot.Depth, ctx)
+	choice, bestExploit := "", -1.0
+	if len(candidates) > 0 && (candidates[0].Score >= engine.MAX_INT/2 || candidates[0].Score <= engine.MIN_INT/2) {
+		choice = candidates[0].Move
+	} else if len(candidates) > 0 {
+		tendencies := bot.Tendencies()
+		bestChance := winProbability(candidates[0].Score, bot.Symbol())
+		for _, candidate := range candidates {
+			if bestChance-winProbability(candidate.Score, bot.Symbol()) > MODELING_MARGIN {
+				break // Best first, so the rest are worse still
+			}
+			if exploit := bot.exploitation(board, candidate.Move, tendencies); exploit > bestExploit {
+				choice, bestExploit = candidate.Move, exploit
+			}
+		}
+	}
+
+	move, err := bots.PlayChosenMove(ctx, board, bot.Symbol(), choice)
+	if err != nil {
+		return move, err
+	}
+
+	// A single threat tests whether the human sees it; two cannot both be blocked
+	bot.pending, bot.threat = nil, ""
+	if board.CheckWin() != '|' {
+		return move, nil // No moves may follow a win, not even tried ones
+	}
+	if threats := threatsOf(board, bot.Symbol()); len(threats) == 1 {
+		bot.pending, bot.threat = engine.CopyBoard(board), threats[0]
+	}
+	return move, nil
+}
+
+// exploitation rates how likely the human is to leave open the threats move makes: for each of them, the chance they
+// miss a threat of its kind, the more so away from their favorite columns
+func (bot *ModelingBot) exploitation(board *engine.Board, move string, tendencies *PlayerTendencies) float64 {
+	board.Move(move, bot.Symbol())
+	defer board.UnMove(move)
+	if board.CheckWin() != '|' {
+		return 0 // A won game has no threats left
+	}
+
+	exploit := 0.0
+	for _, threat := range threatsOf(board, bot.Symbol()) {
+		exploit += tendencies.missRate(isVerticalThreat(board, threat, bot.Symbol())) * (1 - tendencies.columnShare(threat))
+	}
+	return exploit
+}
+
+// OpponentMove counts the human's column, and whether they blocked the bot's threat (implements BotInterface)
+func (bot *ModelingBot) OpponentMove(move string) {
+	move = strings.ToUpper(move)
+	bot.game.Columns[move]++
+
+	board, threat := bot.pending, bot.threat
+	bot.pending, bot.threat = nil, ""
+	if board == nil {
+		return
+	}
+	human := engine.OpponentSymbol(bot.Symbol())
+	if board.Move(move, human)[0] == -1 || board.CheckWin() == human {
+		return // Winning on the spot is no sign of missing the threat
+	}
+	board.UnMove(move)
+
+	vertical := isVerticalThreat(board, threat, bot.Symbol())
+	switch {
+	case move == threat && vertical:
+		bot.game.VerticalBlocked++
+	case move == threat:
+		bot.game.OtherBlocked++
+	case vertical:
+		bot.game.VerticalMissed++
+	default:
+		bot.game.OtherMissed++
+	}
+}
+
+// GameOver adds the game's tendencies to the human's profile
+// winner is 'x', 'o' or '|' for a draw
+func (bot *ModelingBot) GameOver(winner byte) {
+	record := bot.store.Player(bot.Player)
+	bot.store.mutex.Lock()
+	if record.Tendencies == nil {
+		record.Tendencies = &PlayerTendencies{}
+	}
+	record.Tendencies.add(&bot.game)
+	bot.store.mutex.Unlock()
+	bot.game = PlayerTendencies{Columns: make(map[string]int)}
+
+	if err := bot.store.Save(); err != nil {
+		fmt.Println(msg("player_stats.save_error"), err)
+	}
+}
+
+// threatsOf returns the moves that would win on the spot for symbol
+func threatsOf(board *engine.Board, symbol byte) []string {
+	var threats []string
+	for _, move := range board.GetValidMoves() {
+		board.Move(move, symbol)
+		if board.CheckWin() == symbol {
+			threats = append(threats, move)
+		}
+		board.UnMove(move)
+	}
+	return threats
+}
+
+// isVerticalThreat reports whether move wins for symbol by topping a column of its pieces
+func isVerticalThreat(board *engine.Board, move string, symbol byte) bool {
+	x, y := engine.ParseMove(move)
+	z := board.CurrentHeights[x][y]
+	if z < board.WinLength-1 {
+		return false
+	}
+	for below := z - board.WinLength + 1; below < z; below++ {
+		if board.Grid[x][y][below] != symbol {
+			return false
+		}
+	}
+	return true
+}
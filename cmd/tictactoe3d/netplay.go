@@ -0,0 +1,516 @@
+package main
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"tic-tac-toe-3d-bots/engine"
+	"tic-tac-toe-3d-bots/formats"
+)
+
+// DEFAULT_NET_PORT is the TCP port network games are hosted on unless --listen or --server says otherwise
+const DEFAULT_NET_PORT = "7777"
+
+// NET_RECONNECT_TIMEOUT is how long a dropped connection may take to come back before the game is given up
+const NET_RECONNECT_TIMEOUT = time.Minute
+
+// NET_HANDSHAKE_TIMEOUT is how long a new connection has to introduce itself
+const NET_HANDSHAKE_TIMEOUT = 10 * time.Second
+
+// NET_MAX_LINE is the longest protocol line read; a peer sending a longer one is disconnected
+const NET_MAX_LINE = 4096
+
+// Network games use a line protocol; the host owns the board and arbitrates every move
+//
+//	HELLO <token> <name>                        guest → host: join, or rejoin with the token from WELCOME ("-" when joining)
+//	WELCOME <symbol> <token> <LxWxH/win> <name> host → guest: the guest's symbol, its token, the board and the host's name
+//	STATE [moves...]                            host → guest: every move so far, 'x' first, to rebuild the board
+//	MOVE <symbol> <move>                        guest → host: "MOVE <move>" plays a move; host → guest: a move was played
+//	CHAT <text>                                 either way: a chat message
+//	ERROR <text>                                host → guest: the last command was refused
+//	OVER <x|o|draw>                             host → guest: the game has ended
+//	QUIT                                        guest → host: the guest resigns
+
+// netPeer is the other end of a network game, read line by line in the background
+type netPeer struct {
+	conn    net.Conn
+	lines   chan string   // closed when the connection drops
+	closed  chan struct{} // closed by close, so lines are no longer delivered
+	closing sync.Once
+	writing sync.Mutex
+}
+
+// netLines returns a scanner reading the protocol lines of conn, up to NET_MAX_LINE bytes each
+func netLines(conn net.Conn) *bufio.Scanner {
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 512), NET_MAX_LINE)
+	return scanner
+}
+
+// newNetPeer starts reading lines from conn through scanner; a line too long for it drops the connection
+func newNetPeer(conn net.Conn, scanner *bufio.Scanner) *netPeer {
+	peer := &netPeer{conn: conn, lines: make(chan string), closed: make(chan struct{})}
+	go func() {
+		defer close(peer.lines)
+		for scanner.Scan() {
+			select {
+			case peer.lines <- strings.TrimSpace(scanner.Text()):
+			case <-peer.closed:
+				return
+			}
+		}
+	}()
+	return peer
+}
+
+// close drops the connection
+func (peer *netPeer) close() {
+	peer.closing.Do(func() {
+		close(peer.closed)
+		peer.conn.Close()
+	})
+}
+
+// send writes one protocol line
+func (peer *netPeer) send(format string, args ...any) error {
+	peer.writing.Lock()
+	defer peer.writing.Unlock()
+	_, err := fmt.Fprintf(peer.conn, format+"\n", args...)
+	return err
+}
+
+// netGuest is a connection that has introduced itself with HELLO
+type netGuest struct {
+	peer  *netPeer
+	token string
+	name  string
+}
+
+// acceptGuests hands every connection that introduces itself to guests, until the listener is closed
+// Once done is closed, guests still arriving are turned away rather than waiting for a game that has ended
+func acceptGuests(listener net.Listener, guests chan<- netGuest, done <-chan struct{}) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			scanner := netLines(conn)
+			conn.SetReadDeadline(time.Now().Add(NET_HANDSHAKE_TIMEOUT))
+			hello := scanner.Scan()
+			conn.SetReadDeadline(time.Time{})
+			fields := strings.SplitN(strings.TrimSpace(scanner.Text()), " ", 3)
+			if !hello || len(fields) < 3 || fields[0] != "HELLO" {
+				fmt.Fprintln(conn, "ERROR expected HELLO <token> <name>")
+				conn.Close()
+				return
+			}
+			peer := newNetPeer(conn, scanner)
+			select {
+			case guests <- netGuest{peer: peer, token: fields[1], name: fields[2]}:
+			case <-done:
+				peer.close()
+			}
+		}()
+	}
+}
+
+// stdinLines returns the lines typed on standard input; the channel is closed at the end of input
+func stdinLines() <-chan string {
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			lines <- strings.TrimSpace(scanner.Text())
+		}
+	}()
+	return lines
+}
+
+// newNetToken returns a random token a guest rejoins with
+func newNetToken() string {
+	token := make([]byte, 8)
+	rand.Read(token)
+	return hex.EncodeToString(token)
+}
+
+// netAddress adds the default port to an address without one
+func netAddress(address string) string {
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		return net.JoinHostPort(address, DEFAULT_NET_PORT)
+	}
+	return address
+}
+
+// runHost implements the host command: it waits for a guest to join and plays 'x' against them
+func runHost(args []string, output io.Writer) error {
+	var listen, name, lang string
+	var size, win int
+	fs := flag.NewFlagSet("host", flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.StringVar(&listen, "listen", ":"+DEFAULT_NET_PORT, "address to accept the guest on")
+	fs.IntVar(&size, "size", 3, "board size (length, width and height)")
+	fs.IntVar(&win, "win", 0, "pieces in a row needed to win (defaults to size)")
+	fs.StringVar(&name, "name", "", "your name, shown to the guest")
+	fs.StringVar(&lang, "lang", "", "language for messages: "+strings.Join(availableLocales(), ", ")+" (default from TTT_LANG or LANG)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+
+	if lang == "" {
+		lang = localeFromEnvironment()
+	}
+	if err := setLocale(lang); err != nil {
+		return err
+	}
+	if name == "" {
+		name = msg("pvp.player1")
+	}
+	if win == 0 {
+		win = size
+	}
+	board, err := engine.New(engine.WithDims(size, size, size), engine.WithWinLength(win))
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", netAddress(listen))
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+	guests := make(chan netGuest)
+	done := make(chan struct{})
+	defer close(done)
+	go acceptGuests(listener, guests, done)
+	fmt.Print(msg("net.listening", listener.Addr()))
+
+	hostGame(board, name, guests)
+	return nil
+}
+
+// hostGame plays the host's side of a network game; the first guest to arrive plays 'o'
+func hostGame(board *engine.Board, name string, guests <-chan netGuest) {
+	guest := <-guests
+	token := newNetToken()
+	peer := guest.peer
+	fmt.Print(msg("net.joined", guest.name))
+
+	names := [2]string{name, guest.name}
+	session := startGame(board, formats.GameRecord{Mode: "netpvp", Players: names})
+	defer session.End()
+
+	var moves []string
+	welcome := func() {
+		peer.send("WELCOME o %s %dx%dx%d/%d %s", token, board.Length, board.Width, board.Height, board.WinLength, name)
+		peer.send("STATE %s", strings.Join(moves, " "))
+	}
+	welcome()
+	fmt.Println(msg("net.help"))
+	printBoard(board)
+	fmt.Print(msg("net.your_turn", 'x'))
+
+	// play applies a move by symbol if it is legal and symbol's turn, returning an error for the player otherwise
+	turnStart := time.Now()
+	play := func(symbol byte, move string) error {
+		if board.NextPlayer() != symbol {
+			return fmt.Errorf("%s", msg("net.not_your_turn"))
+		}
+		if coords := board.Move(move, symbol); coords[0] == -1 {
+			return fmt.Errorf("%s", msg("game.invalid_move"))
+		}
+		moves = append(moves, move)
+		session.RecordMove(move, time.Since(turnStart))
+		turnStart = time.Now()
+		if peer != nil {
+			peer.send("MOVE %c %s", symbol, move)
+		}
+		printBoard(board)
+		fmt.Print(msg("net.moved", names[formats.SymbolIndex(symbol)], move))
+		return nil
+	}
+
+	input := stdinLines()
+	var reconnect <-chan time.Time
+	for {
+		var peerLines <-chan string
+		if peer != nil {
+			peerLines = peer.lines
+		}
+
+		select {
+		case line, ok := <-input:
+			command, text, _ := strings.Cut(line, " ")
+			switch {
+			case !ok || strings.EqualFold(command, "quit"):
+				fmt.Print(msg("net.resigned", name))
+				session.SetResult('o', "resigned")
+				if peer != nil {
+					peer.send("OVER o")
+					peer.close()
+				}
+				return
+			case strings.EqualFold(command, "chat"):
+				if peer != nil {
+					peer.send("CHAT %s", text)
+				}
+				continue
+			case line == "":
+				continue
+			}
+			if err := play('x', strings.ToUpper(command)); err != nil {
+				fmt.Println(err)
+				continue
+			}
+
+		case line, ok := <-peerLines:
+			if !ok {
+				peer = nil
+				reconnect = time.After(NET_RECONNECT_TIMEOUT)
+				fmt.Print(msg("net.disconnected", guest.name, NET_RECONNECT_TIMEOUT))
+				continue
+			}
+			command, text, _ := strings.Cut(line, " ")
+			switch command {
+			case "MOVE":
+				if err := play('o', strings.ToUpper(text)); err != nil {
+					peer.send("ERROR %v", err)
+					continue
+				}
+			case "CHAT":
+				fmt.Print(msg("net.chat", guest.name, text))
+				continue
+			case "QUIT":
+				fmt.Print(msg("net.resigned", guest.name))
+				session.SetResult('x', "resigned")
+				peer.close()
+				return
+			default:
+				peer.send("ERROR unknown command %q", command)
+				continue
+			}
+
+		case arrival := <-guests:
+			if arrival.token != token {
+				arrival.peer.send("ERROR this game already has a guest")
+				arrival.peer.close()
+				continue
+			}
+			if peer != nil {
+				peer.close() // The guest is back before its old connection was seen to drop
+			}
+			peer, reconnect = arrival.peer, nil
+			fmt.Print(msg("net.reconnected", guest.name))
+			welcome()
+			continue
+
+		case <-reconnect:
+			fmt.Print(msg("net.abandoned", guest.name))
+			session.SetResult('x', "abandoned")
+			return
+		}
+
+		// A move was played
+		if winner := board.CheckWin(); winner != '|' {
+			fmt.Print(msg("pvp.wins", names[formats.SymbolIndex(winner)]))
+			if peer != nil {
+				peer.send("OVER %c", winner)
+			}
+			return
+		}
+		if board.IsFull() {
+			fmt.Println(msg("game.draw"))
+			if peer != nil {
+				peer.send("OVER draw")
+			}
+			return
+		}
+		if board.NextPlayer() == 'x' {
+			fmt.Print(msg("net.your_turn", 'x'))
+		} else {
+			fmt.Print(msg("net.waiting_move", guest.name))
+		}
+	}
+}
+
+// runJoin implements the join command: it connects to a host and plays 'o', reconnecting if the connection drops
+func runJoin(args []string, output io.Writer) error {
+	var server, name, lang string
+	fs := flag.NewFlagSet("join", flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.StringVar(&server, "server", "localhost:"+DEFAULT_NET_PORT, "address of the host")
+	fs.StringVar(&name, "name", "", "your name, shown to the host")
+	fs.StringVar(&lang, "lang", "", "language for messages: "+strings.Join(availableLocales(), ", ")+" (default from TTT_LANG or LANG)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+
+	if lang == "" {
+		lang = localeFromEnvironment()
+	}
+	if err := setLocale(lang); err != nil {
+		return err
+	}
+	if name == "" {
+		name = msg("pvp.player2")
+	}
+	server = netAddress(server)
+
+	dial := func(token string) (*netPeer, error) {
+		conn, err := net.Dial("tcp", server)
+		if err != nil {
+			return nil, err
+		}
+		peer := newNetPeer(conn, netLines(conn))
+		return peer, peer.send("HELLO %s %s", token, name)
+	}
+	peer, err := dial("-")
+	if err != nil {
+		return err
+	}
+
+	var board *engine.Board
+	var symbol byte
+	token, opponent := "-", ""
+	input := stdinLines()
+	for {
+		select {
+		case line, ok := <-input:
+			command, text, _ := strings.Cut(line, " ")
+			switch {
+			case !ok || strings.EqualFold(command, "quit"):
+				peer.send("QUIT")
+				peer.close()
+				return nil
+			case strings.EqualFold(command, "chat"):
+				peer.send("CHAT %s", text)
+			case line == "":
+			case board == nil || board.NextPlayer() != symbol:
+				fmt.Println(msg("net.not_your_turn"))
+			default:
+				peer.send("MOVE %s", strings.ToUpper(command))
+			}
+
+		case line, ok := <-peer.lines:
+			if !ok {
+				if token == "-" {
+					return fmt.Errorf("the host closed the connection")
+				}
+				fmt.Print(msg("net.reconnecting"))
+				if peer, err = reconnectPeer(dial, token); err != nil {
+					return err
+				}
+				continue
+			}
+
+			command, text, _ := strings.Cut(line, " ")
+			switch command {
+			case "WELCOME":
+				fields := strings.SplitN(text, " ", 4)
+				if len(fields) < 4 || fields[0] != "x" && fields[0] != "o" {
+					return fmt.Errorf("unexpected welcome %q", line)
+				}
+				config, err := engine.ParseBoardConfig(fields[2])
+				if err != nil {
+					return err
+				}
+				symbol, token, opponent = fields[0][0], fields[1], fields[3]
+				if board, err = engine.New(engine.WithConfig(*config)); err != nil {
+					return err
+				}
+				fmt.Print(msg("net.connected", symbol, opponent))
+				fmt.Println(msg("net.help"))
+
+			case "STATE":
+				if board == nil {
+					return fmt.Errorf("the host sent %q before its welcome", line)
+				}
+				record := formats.GameRecord{Board: engine.BoardConfig{Length: board.Length, Width: board.Width, Height: board.Height, Win: board.WinLength},
+					Moves: strings.Fields(text)}
+				if board, err = record.Replay(len(record.Moves)); err != nil {
+					return err
+				}
+				printBoard(board)
+				promptNetTurn(board, symbol, opponent)
+
+			case "MOVE":
+				played, move, _ := strings.Cut(text, " ")
+				switch {
+				case board == nil:
+					return fmt.Errorf("the host sent %q before its welcome", line)
+				case played != "x" && played != "o" || move == "":
+					return fmt.Errorf("unexpected move %q", line)
+				case board.Move(move, played[0])[0] == -1:
+					return fmt.Errorf("the host sent the illegal move %q", line)
+				}
+				printBoard(board)
+				if played[0] != symbol {
+					fmt.Print(msg("net.moved", opponent, move))
+				}
+				if board.CheckWin() == '|' && !board.IsFull() {
+					promptNetTurn(board, symbol, opponent)
+				}
+
+			case "CHAT":
+				fmt.Print(msg("net.chat", opponent, text))
+
+			case "ERROR":
+				fmt.Println(text)
+
+			case "OVER":
+				switch {
+				case text == "draw":
+					fmt.Println(msg("game.draw"))
+				case text != "x" && text != "o":
+					return fmt.Errorf("unexpected result %q", line)
+				case text[0] == symbol:
+					fmt.Print(msg("pvp.wins", name))
+				default:
+					fmt.Print(msg("pvp.wins", opponent))
+				}
+				peer.close()
+				return nil
+			}
+		}
+	}
+}
+
+// reconnectPeer dials the host again with the guest's token until it answers or NET_RECONNECT_TIMEOUT passes
+func reconnectPeer(dial func(token string) (*netPeer, error), token string) (*netPeer, error) {
+	deadline := time.Now().Add(NET_RECONNECT_TIMEOUT)
+	for {
+		peer, err := dial(token)
+		if err == nil {
+			return peer, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("could not reconnect: %v", err)
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+// promptNetTurn tells the guest whose turn it is
+func promptNetTurn(board *engine.Board, symbol byte, opponent string) {
+	if board.NextPlayer() == symbol {
+		fmt.Print(msg("net.your_turn", symbol))
+	} else {
+		fmt.Print(msg("net.waiting_move", opponent))
+	}
+}
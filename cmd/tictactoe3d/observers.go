@@ -0,0 +1,138 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	"tic-tac-toe-3d-bots/formats"
+)
+
+// Game loops publish what happens in their games through a GameSession, and everything that follows games
+// (the event log, ratings, statistics, storage and JSON output) subscribes here instead of being called by each mode.
+// Observers run synchronously on the game's goroutine, in the order they subscribed, so they must be quick;
+// games of a match run in parallel, so an observer may be called from several goroutines at once
+
+// GameStart is published when a game begins
+type GameStart struct {
+	Game   int                // number of the game within this run, from 1
+	Record formats.GameRecord // mode, board and players; the moves of a resumed game
+}
+
+// GameMove is published for every move recorded in a game
+type GameMove struct {
+	Game     int
+	Mode     string
+	Ply      int    // 1 for the first move of the game
+	Player   byte   // 'x' or 'o'
+	Move     string // e.g. "A1"
+	Thinking time.Duration
+	Score    int // board evaluation after the move (+ favors 'x')
+}
+
+// GameEnd is published once a game is over, finished or not
+type GameEnd struct {
+	Game   int
+	Record formats.GameRecord // the complete record, with clocks
+	Winner byte               // 'x', 'o', '|' for a draw, or 0 if the game did not finish
+	Result formats.GameResult // the summary written with --output json
+}
+
+// EvalUpdate is published when a game's position is evaluated: for the eval bar, or by the search behind a live line
+type EvalUpdate struct {
+	Game  int
+	Ply   int // moves played in the evaluated position
+	Depth int
+	Score int      // search score (+ favors 'x')
+	Line  []string // best line from the position, if the search found one
+}
+
+// observers holds the subscribed functions of one kind of event
+type observers[Event any] struct {
+	mutex     sync.RWMutex
+	functions map[int]func(Event)
+	order     []int // subscription ids, oldest first
+	nextID    int
+}
+
+var (
+	startObservers observers[GameStart]
+	moveObservers  observers[GameMove]
+	endObservers   observers[GameEnd]
+	evalObservers  observers[EvalUpdate]
+)
+
+// subscribe adds observer and returns the function removing it again
+func (list *observers[Event]) subscribe(observer func(Event)) func() {
+	list.mutex.Lock()
+	defer list.mutex.Unlock()
+	if list.functions == nil {
+		list.functions = make(map[int]func(Event))
+	}
+	list.nextID++
+	id := list.nextID
+	list.functions[id] = observer
+	list.order = append(list.order, id)
+
+	return func() {
+		list.mutex.Lock()
+		defer list.mutex.Unlock()
+		delete(list.functions, id)
+		for i, subscribed := range list.order {
+			if subscribed == id {
+				list.order = append(list.order[:i], list.order[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// publish calls every observer with event
+func (list *observers[Event]) publish(event Event) {
+	list.mutex.RLock()
+	functions := make([]func(Event), len(list.order))
+	for i, id := range list.order {
+		functions[i] = list.functions[id]
+	}
+	list.mutex.RUnlock()
+
+	for _, observer := range functions {
+		observer(event)
+	}
+}
+
+// OnGameStart subscribes observer to the start of every game; the returned function unsubscribes it
+func OnGameStart(observer func(GameStart)) func() {
+	return startObservers.subscribe(observer)
+}
+
+// OnMove subscribes observer to every move played in every game; the returned function unsubscribes it
+func OnMove(observer func(GameMove)) func() {
+	return moveObservers.subscribe(observer)
+}
+
+// OnGameEnd subscribes observer to the end of every game; the returned function unsubscribes it
+func OnGameEnd(observer func(GameEnd)) func() {
+	return endObservers.subscribe(observer)
+}
+
+// OnEvalUpdate subscribes observer to the evaluations made while games run; the returned function unsubscribes it
+func OnEvalUpdate(observer func(EvalUpdate)) func() {
+	return evalObservers.subscribe(observer)
+}
+
+// init subscribes what records every game, in the order the records are written:
+// the event log, ratings, the CSV export, the game database, player profiles, and JSON output
+func init() {
+	OnGameStart(logGameStart)
+	OnMove(logMove)
+	OnGameEnd(logGameOver)
+	OnGameEnd(rateFinishedGame)
+	OnGameEnd(exportGame)
+	OnGameEnd(storeGame)
+	OnGameEnd(archiveGame)
+	OnGameStart(journalGameStart)
+	OnMove(journalGameMove)
+	OnGameEnd(journalGameEnd)
+	OnGameEnd(recordFinishedProfileGame)
+	OnGameEnd(printJSONResult)
+}
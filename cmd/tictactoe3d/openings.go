@@ -0,0 +1,330 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"strings"
+
+	"tic-tac-toe-3d-bots/engine"
+)
+
+// OPENING_PLIES is how many moves of each game the opening tree of the openings command and --book aggregates by default
+const OPENING_PLIES = 8
+
+// OpeningStats counts the games that played a move from a position, by result
+type OpeningStats struct {
+	Games int
+	XWins int
+	OWins int
+	Draws int
+}
+
+// score returns the share of the points symbol took in the games, a draw counting half
+func (stats OpeningStats) score(symbol byte) float64 {
+	if stats.Games == 0 {
+		return 0
+	}
+	wins := stats.XWins
+	if symbol == 'o' {
+		wins = stats.OWins
+	}
+	return (float64(wins) + float64(stats.Draws)/2) / float64(stats.Games)
+}
+
+// OpeningMove is a move of the opening tree, with the games that played it
+type OpeningMove struct {
+	Move string
+	OpeningStats
+}
+
+// OpeningTree aggregates the first moves of finished games: for each position, by Board.PositionHash, the moves
+// played from it and how those games ended. As the hash covers the board's size, one tree holds every board's openings
+type OpeningTree struct {
+	positions map[uint64]map[string]*OpeningStats
+	boards    map[engine.BoardConfig]int // games per board
+}
+
+// buildOpeningTree aggregates the first plies moves of the finished games
+func buildOpeningTree(games []StoredGame, plies int) *OpeningTree {
+	tree := &OpeningTree{positions: make(map[uint64]map[string]*OpeningStats), boards: make(map[engine.BoardConfig]int)}
+	for _, game := range games {
+		result := game.Result
+		if result.Winner != "x" && result.Winner != "o" && result.Winner != "draw" {
+			continue
+		}
+		board, err := engine.New(engine.WithConfig(result.Board))
+		if err != nil {
+			continue
+		}
+		tree.boards[result.Board]++
+
+		for _, played := range result.Moves[:min(plies, len(result.Moves))] {
+			move := strings.ToUpper(played.Move)
+			hash := board.PositionHash()
+			if board.Move(move, board.NextPlayer())[0] == -1 {
+				break
+			}
+			if tree.positions[hash] == nil {
+				tree.positions[hash] = make(map[string]*OpeningStats)
+			}
+			stats := tree.positions[hash][move]
+			if stats == nil {
+				stats = &OpeningStats{}
+				tree.positions[hash][move] = stats
+			}
+			stats.Games++
+			switch result.Winner {
+			case "x":
+				stats.XWins++
+			case "o":
+				stats.OWins++
+			default:
+				stats.Draws++
+			}
+		}
+	}
+	return tree
+}
+
+// Boards returns the boards the tree has games of, the most played first
+func (tree *OpeningTree) Boards() []engine.BoardConfig {
+	boards := make([]engine.BoardConfig, 0, len(tree.boards))
+	for board := range tree.boards {
+		boards = append(boards, board)
+	}
+	sort.Slice(boards, func(i, j int) bool {
+		if tree.boards[boards[i]] != tree.boards[boards[j]] {
+			return tree.boards[boards[i]] > tree.boards[boards[j]]
+		}
+		return fmt.Sprint(boards[i]) < fmt.Sprint(boards[j])
+	})
+	return boards
+}
+
+// Moves returns the moves played from the position on board: the most played first, then the best scoring for the
+// player to move
+func (tree *OpeningTree) Moves(board *engine.Board) []OpeningMove {
+	played := tree.positions[board.PositionHash()]
+	moves := make([]OpeningMove, 0, len(played))
+	for move, stats := range played {
+		moves = append(moves, OpeningMove{Move: move, OpeningStats: *stats})
+	}
+	symbol := board.NextPlayer()
+	sort.Slice(moves, func(i, j int) bool {
+		if moves[i].Games != moves[j].Games {
+			return moves[i].Games > moves[j].Games
+		}
+		if moves[i].score(symbol) != moves[j].score(symbol) {
+			return moves[i].score(symbol) > moves[j].score(symbol)
+		}
+		return moves[i].Move < moves[j].Move
+	})
+	return moves
+}
+
+// Opening samples up to plies moves from an empty board like board, each move as often as the games played it
+// The opening stops early at a position no game went on from
+func (tree *OpeningTree) Opening(board *engine.Board, plies int, random *rand.Rand) []string {
+	position := freshBoard(board)
+	var opening []string
+	for len(opening) < plies && position.CheckWin() == '|' {
+		moves := tree.Moves(position)
+		total := 0
+		for _, move := range moves {
+			total += move.Games
+		}
+		if total == 0 {
+			break
+		}
+		pick := random.Intn(total)
+		for _, move := range moves {
+			if pick -= move.Games; pick < 0 {
+				position.Move(move.Move, position.NextPlayer())
+				opening = append(opening, move.Move)
+				break
+			}
+		}
+	}
+	return opening
+}
+
+// OpeningBook starts the games of matches from openings sampled from an opening tree, set with --book, and
+// continued with random moves, set with --random-plies
+type OpeningBook struct {
+	tree   *OpeningTree // nil for random openings only
+	plies  int          // longest opening sampled from tree
+	random int          // plies random moves fill the opening up to
+}
+
+// openingBook varies the openings of matches, tournaments and gauntlets when set with --book or --random-plies; nil
+// starts every game from the empty board, where deterministic bots play the same game every time
+var openingBook *OpeningBook
+
+// openOpeningBook builds the opening book of the game database at path, sampling openings of up to plies moves, then
+// filling them up to randomPlies moves with random ones. Without a path, openings are random moves only
+func openOpeningBook(path string, plies, randomPlies int) (*OpeningBook, error) {
+	book := &OpeningBook{plies: plies, random: randomPlies}
+	if path == "" {
+		return book, nil
+	}
+	db, err := openGameDB(path)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	games, err := db.Games(nil)
+	if err != nil {
+		return nil, err
+	}
+	book.tree = buildOpeningTree(games, plies)
+	return book, nil
+}
+
+// Opening samples an opening for a game on board; a nil book always returns none
+func (book *OpeningBook) Opening(board *engine.Board, random *rand.Rand) []string {
+	if book == nil {
+		return nil
+	}
+	var opening []string
+	if book.tree != nil {
+		opening = book.tree.Opening(board, book.plies, random)
+	}
+	if len(opening) < book.random {
+		opening = randomOpening(board, opening, book.random, random)
+	}
+	return opening
+}
+
+// randomOpening continues opening, played from an empty board like board, to plies moves, each drawn uniformly from
+// the legal moves that do not win on the spot, so the bots take over a game still to be played
+// The opening stops early on a board with no such move
+func randomOpening(board *engine.Board, opening []string, plies int, random *rand.Rand) []string {
+	position := freshBoard(board)
+	for _, move := range opening {
+		position.Move(move, position.NextPlayer())
+	}
+	for len(opening) < plies && position.CheckWin() == '|' {
+		player := position.NextPlayer()
+		var quiet [][2]int
+		position.EachValidMove(func(col, row int) bool {
+			position.MoveAt(col, row, player)
+			if position.CheckWin() == '|' {
+				quiet = append(quiet, [2]int{col, row})
+			}
+			position.UnMoveAt(col, row)
+			return true
+		})
+		if len(quiet) == 0 {
+			break
+		}
+		move := quiet[random.Intn(len(quiet))]
+		position.MoveAt(move[0], move[1], player)
+		opening = append(opening, position.MoveName(move[0], move[1]))
+	}
+	return opening
+}
+
+// runOpenings implements the openings command: it aggregates the first moves of a game database's games into an
+// opening tree and lists the moves played from a position, with how they scored, for each board
+func runOpenings(args []string, output io.Writer) error {
+	var dbPath, moves, boardFilter, lang string
+	var plies, minGames, limit int
+
+	fs := flag.NewFlagSet("openings", flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.StringVar(&dbPath, "db", "", "game database to aggregate (required)")
+	fs.StringVar(&moves, "moves", "", "list the moves played after these, e.g. \"A1 B2\" (default the first moves)")
+	fs.StringVar(&boardFilter, "board", "", "only games on this board, as LxWxH or LxWxH/win (e.g. 4x4x4)")
+	fs.IntVar(&plies, "plies", OPENING_PLIES, "moves of each game to aggregate")
+	fs.IntVar(&minGames, "min-games", 1, "leave out moves played in fewer games")
+	fs.IntVar(&limit, "limit", 10, "list at most this many moves per board (0 lists all)")
+	fs.StringVar(&lang, "lang", "", "language for messages: "+strings.Join(availableLocales(), ", ")+" (default from TTT_LANG or LANG)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+	if dbPath == "" {
+		return fmt.Errorf("the openings command needs a game database in --db")
+	}
+	if plies < 1 {
+		return fmt.Errorf("--plies must be at least 1")
+	}
+
+	if lang == "" {
+		lang = localeFromEnvironment()
+	}
+	if err := setLocale(lang); err != nil {
+		return err
+	}
+
+	var filter *engine.BoardConfig
+	if boardFilter != "" {
+		var err error
+		if filter, err = engine.ParseBoardConfig(boardFilter); err != nil {
+			return err
+		}
+	}
+	line := strings.Fields(strings.ToUpper(moves))
+
+	db, err := openGameDB(dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	games, err := db.Games(nil)
+	if err != nil {
+		return err
+	}
+	tree := buildOpeningTree(games, plies)
+
+	listed := 0
+	for _, config := range tree.Boards() {
+		if filter != nil && !boardMatches(*filter, config) {
+			continue
+		}
+		board, err := engine.New(engine.WithConfig(config))
+		if err != nil {
+			continue
+		}
+		replayed := true
+		for _, move := range line {
+			if board.CheckWin() != '|' || board.Move(move, board.NextPlayer())[0] == -1 {
+				replayed = false
+				break
+			}
+		}
+		if !replayed {
+			continue
+		}
+
+		var shown []OpeningMove
+		for _, move := range tree.Moves(board) {
+			if move.Games >= minGames && (limit == 0 || len(shown) < limit) {
+				shown = append(shown, move)
+			}
+		}
+		if len(shown) == 0 {
+			continue
+		}
+		listed++
+
+		symbol := board.NextPlayer()
+		position := strings.Join(line, " ")
+		if position == "" {
+			position = msg("openings.start")
+		}
+		fmt.Fprint(output, msg("openings.board", config.Length, config.Width, config.Height, config.Win, tree.boards[config], position, symbol))
+		for _, move := range shown {
+			fmt.Fprint(output, msg("openings.entry", move.Move, move.Games, move.XWins, move.Draws, move.OWins, move.score(symbol)*100))
+		}
+	}
+	if listed == 0 {
+		fmt.Fprintln(output, msg("openings.none"))
+	}
+	return nil
+}
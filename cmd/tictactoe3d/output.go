@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"tic-tac-toe-3d-bots/bots"
+	"tic-tac-toe-3d-bots/formats"
+)
+
+// JSONOutput prints each game's result as one JSON object instead of the decorated console text; set from --output
+var JSONOutput bool
+
+// resultOutput receives JSON results: the real standard output, kept aside by enableJSONOutput
+var resultOutput io.Writer = os.Stdout
+
+// parseOutputFormat checks an --output value, returning whether it selects JSON output
+func parseOutputFormat(format string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "", "text":
+		return false, nil
+	case "json":
+		return true, nil
+	}
+	return false, fmt.Errorf("unknown output format %q (expected text or json)", format)
+}
+
+// enableJSONOutput moves the console text off standard output so that only JSON results appear there
+// Interactive games keep their board and prompts on standard error; other games discard them
+func enableJSONOutput(interactive bool) error {
+	JSONOutput = true
+	resultOutput = os.Stdout
+	if interactive {
+		os.Stdout = os.Stderr
+		return nil
+	}
+
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	os.Stdout = devNull
+	return nil
+}
+
+// result builds the game's summary for JSON output
+func (session *GameSession) result(winner, reason string) formats.GameResult {
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+
+	result := formats.GameResult{
+		Mode:    session.record.Mode,
+		Board:   session.record.Board,
+		Players: session.record.Players,
+		Bots:    session.record.Bots,
+		Winner:  winner,
+		Reason:  reason,
+		Moves:   append([]formats.PlayedMove{}, session.moves...),
+		Seed:    bots.RunSeed,
+	}
+	for i, clock := range session.clocks {
+		stats := &result.Stats[i]
+		stats.Moves = (len(session.record.Moves) + 1 - i) / 2
+		stats.TotalMS = formats.Milliseconds(clock)
+		stats.TTFill = session.ttFill[i]
+		if stats.Moves > 0 {
+			stats.AverageMS = stats.TotalMS / float64(stats.Moves)
+		}
+	}
+	return result
+}
+
+// printGameResult writes a game result as one line of JSON
+func printGameResult(result formats.GameResult) {
+	if err := json.NewEncoder(resultOutput).Encode(result); err != nil {
+		fmt.Fprintln(os.Stderr, msg("error"), err)
+	}
+}
+
+// printJSONResult prints a game's result with --output json (an OnGameEnd observer)
+func printJSONResult(end GameEnd) {
+	if JSONOutput {
+		printGameResult(end.Result)
+	}
+}
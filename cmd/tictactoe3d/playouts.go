@@ -0,0 +1,71 @@
+package main
+
+import (
+	"math/rand"
+
+	"tic-tac-toe-3d-bots/bots"
+	"tic-tac-toe-3d-bots/engine"
+)
+
+// Playouts is the number of random games played out from each position whose evaluation is shown, for the share
+// each side won to be shown next to the minimax score; set from --playouts at startup (0 plays none)
+// Random play is a poor judge of a position, but an unbiased one: where it disagrees sharply with the search, the
+// heuristic evaluation may be what is wrong
+var Playouts int
+
+// MAX_PLAYOUTS bounds the playouts an analysis request may ask for
+const MAX_PLAYOUTS = 100000
+
+// PlayoutResult counts how random playouts from a position ended
+type PlayoutResult struct {
+	Games int `json:"games"`
+	XWins int `json:"x_wins"`
+	OWins int `json:"o_wins"`
+	Draws int `json:"draws"`
+}
+
+// WinRate returns the share of the playouts symbol won, in [0, 1]
+func (result PlayoutResult) WinRate(symbol byte) float64 {
+	if result.Games == 0 {
+		return 0
+	}
+	if symbol == 'o' {
+		return float64(result.OWins) / float64(result.Games)
+	}
+	return float64(result.XWins) / float64(result.Games)
+}
+
+// playOut plays games random games to the end from a copy of board, each move drawn uniformly from the legal ones,
+// and counts how they ended. The moves derive from the run's seed, so a replayed run shows the same figures
+func playOut(board *engine.Board, games int) PlayoutResult {
+	random := rand.New(rand.NewSource(bots.NextSeed()))
+	result := PlayoutResult{Games: games}
+	for range games {
+		game := engine.CopyBoard(board)
+		for game.CheckWin() == '|' && !game.IsFull() {
+			moves := 0
+			game.EachValidMove(func(col, row int) bool {
+				moves++
+				return true
+			})
+			pick := random.Intn(moves)
+			game.EachValidMove(func(col, row int) bool {
+				if pick > 0 {
+					pick--
+					return true
+				}
+				game.MoveAt(col, row, game.NextPlayer())
+				return false
+			})
+		}
+		switch game.CheckWin() {
+		case 'x':
+			result.XWins++
+		case 'o':
+			result.OWins++
+		default:
+			result.Draws++
+		}
+	}
+	return result
+}
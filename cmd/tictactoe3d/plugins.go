@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"plugin"
+	"sort"
+	"strings"
+
+	"tic-tac-toe-3d-bots/bots"
+	"tic-tac-toe-3d-bots/engine"
+)
+
+// DEFAULT_PLUGINS_DIR is searched for bot plugins automatically when present and no other plugins are given
+const DEFAULT_PLUGINS_DIR = "plugins"
+
+// PLUGIN_BOT_ORDER places plugin bots after the built-in ones in menus
+const PLUGIN_BOT_ORDER = 100
+
+// Bot plugins are Go plugins (go build -buildmode=plugin) exporting a RegisterBots function.
+// A plugin cannot import this program's types, so the contract uses only standard types:
+// a bot chooses its move from the grid ([column][row][layer], holding 'x', 'o' or '|' for empty),
+// the pieces in a row needed to win, and the valid moves ("A1" is column A, row 1). For example:
+//
+//	package main
+//
+//	import "context"
+//
+//	func RegisterBots(register func(key, displayName, description string, defaults map[string]int,
+//		create func(symbol byte, params map[string]int) func(ctx context.Context, grid [][][]byte, winLength int, validMoves []string) (string, error))) {
+//		register("first", "FirstBot", "plays the first valid move", map[string]int{}, func(symbol byte, params map[string]int) func(context.Context, [][][]byte, int, []string) (string, error) {
+//			return func(ctx context.Context, grid [][][]byte, winLength int, validMoves []string) (string, error) {
+//				return validMoves[0], nil
+//			}
+//		})
+//	}
+//
+// The plugin must be built with the same Go version as this program
+
+// PluginMoveFunc chooses a plugin bot's move; see above
+type PluginMoveFunc = func(ctx context.Context, grid [][][]byte, winLength int, validMoves []string) (string, error)
+
+// PluginBotFactory creates a plugin bot for a side, returning the function choosing its moves
+type PluginBotFactory = func(symbol byte, params map[string]int) PluginMoveFunc
+
+// PluginRegisterFunc is passed to a plugin's RegisterBots to add its bots to the registry
+type PluginRegisterFunc = func(key, displayName, description string, defaults map[string]int, create PluginBotFactory)
+
+// PluginBot plays the moves a plugin chooses
+type PluginBot struct {
+	bots.BaseBot
+	choose PluginMoveFunc
+}
+
+// MakeMove asks the plugin for its move and plays it (implements BotInterface)
+// A plugin that panics loses the move with an error instead of crashing the program
+func (bot *PluginBot) MakeMove(ctx context.Context, board *engine.Board) (move bots.Move, err error) {
+	validMoves := board.GetValidMoves()
+	if len(validMoves) == 0 {
+		return bots.Move{}, bots.ErrNoValidMoves
+	}
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			move, err = bots.Move{}, fmt.Errorf("bot %s panicked: %v", bot.Name(), recovered)
+		}
+	}()
+
+	chosen, err := bot.choose(ctx, engine.CopyBoard(board).Grid, board.WinLength, validMoves)
+	if err != nil {
+		return bots.Move{}, err
+	}
+	return bots.PlayChosenMove(ctx, board, bot.Symbol(), strings.ToUpper(strings.TrimSpace(chosen)))
+}
+
+// loadBotPlugins loads a plugin file, or every .so file of a directory in name order
+func loadBotPlugins(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	files := []string{path}
+	if info.IsDir() {
+		if files, err = filepath.Glob(filepath.Join(path, "*.so")); err != nil {
+			return err
+		}
+		sort.Strings(files)
+	}
+
+	for _, file := range files {
+		if err := loadBotPlugin(file); err != nil {
+			return fmt.Errorf("%s: %v", file, err)
+		}
+	}
+	return nil
+}
+
+// loadBotPlugin opens a plugin and registers its bots
+func loadBotPlugin(file string) error {
+	opened, err := plugin.Open(file)
+	if err != nil {
+		return err
+	}
+	symbol, err := opened.Lookup("RegisterBots")
+	if err != nil {
+		return err
+	}
+	registerBots, ok := symbol.(func(PluginRegisterFunc))
+	if !ok {
+		return fmt.Errorf("RegisterBots has the wrong signature (%T)", symbol)
+	}
+
+	// Registration errors are collected, as the plugin calls register from its own code
+	var registrationErr error
+	registerBots(func(key, displayName, description string, defaults map[string]int, create PluginBotFactory) {
+		if _, exists := bots.LookupBot(key); registrationErr != nil {
+			return
+		} else if strings.TrimSpace(key) == "" {
+			registrationErr = errors.New("a bot has an empty key")
+			return
+		} else if exists {
+			registrationErr = fmt.Errorf("bot %q is already registered", key)
+			return
+		}
+		if defaults == nil {
+			defaults = map[string]int{}
+		}
+		bots.RegisterBot(&bots.BotRegistration{
+			Key:         key,
+			DisplayName: displayName,
+			Description: description,
+			Order:       PLUGIN_BOT_ORDER,
+			Defaults:    defaults,
+			New: func(symbol byte, name string, params map[string]int) bots.BotInterface {
+				if name == "" {
+					name = displayName
+				}
+				return &PluginBot{BaseBot: bots.NewBaseBot(symbol, name), choose: create(symbol, params)}
+			},
+		})
+	})
+	return registrationErr
+}
+
+// loadDefaultBotPlugins loads the plugins of DEFAULT_PLUGINS_DIR if it exists in the working directory
+func loadDefaultBotPlugins() error {
+	if _, err := os.Stat(DEFAULT_PLUGINS_DIR); err != nil {
+		return nil // No default plugins directory, nothing to load
+	}
+	return loadBotPlugins(DEFAULT_PLUGINS_DIR)
+}
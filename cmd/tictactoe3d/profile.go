@@ -0,0 +1,217 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"tic-tac-toe-3d-bots/bots"
+	"tic-tac-toe-3d-bots/formats"
+)
+
+// currentProfile names the human in PvE games; set with --player or from the profiles menu
+var currentProfile = DEFAULT_PLAYER_NAME
+
+// playerBot is implemented by bots that play by the profile of the human they face, such as AdaptiveBot and ModelingBot
+type playerBot interface {
+	SetPlayer(player string)
+}
+
+// OpponentRecord holds a human player's results against one bot (from the player's point of view)
+type OpponentRecord struct {
+	Name   string `json:"name"` // the bot's name in the last game against it
+	Wins   int    `json:"wins"`
+	Losses int    `json:"losses"`
+	Draws  int    `json:"draws"`
+}
+
+// RecordProfileGame adds a finished PvE game to the profile of its human player and saves the store
+func (store *StatsStore) RecordProfileGame(result formats.GameResult) error {
+	human := 0
+	if result.Bots[0] != nil {
+		human = 1
+	}
+	bot := result.Bots[1-human]
+	if bot == nil {
+		return nil // Not a game against a bot
+	}
+
+	record := store.Player(result.Players[human])
+	store.mutex.Lock()
+	winner := byte('|')
+	if result.Winner == "x" || result.Winner == "o" {
+		winner = result.Winner[0]
+	}
+	record.RecordResult(winner, "xo"[human])
+	record.Moves += result.Stats[human].Moves
+	record.ThinkingMS += result.Stats[human].TotalMS
+	record.LastPlayed = time.Now()
+
+	if record.Opponents == nil {
+		record.Opponents = make(map[string]*OpponentRecord)
+	}
+	opponent, exists := record.Opponents[bot.Spec()]
+	if !exists {
+		opponent = &OpponentRecord{}
+		record.Opponents[bot.Spec()] = opponent
+	}
+	opponent.Name = result.Players[1-human]
+	switch winner {
+	case "xo"[human]:
+		opponent.Wins++
+	case '|':
+		opponent.Draws++
+	default:
+		opponent.Losses++
+	}
+	store.mutex.Unlock()
+
+	return store.Save()
+}
+
+// recordProfileGame adds a finished PvE game to the human's profile in the shared stats store
+func recordProfileGame(result formats.GameResult) {
+	if err := sharedStatsStore().RecordProfileGame(result); err != nil {
+		fmt.Println(msg("player_stats.save_error"), err)
+	}
+}
+
+// AverageMoveMS returns the player's average thinking time per move, in milliseconds
+func (record *PlayerRecord) AverageMoveMS() float64 {
+	if record.Moves == 0 {
+		return 0
+	}
+	return record.ThinkingMS / float64(record.Moves)
+}
+
+// winRate returns the share of games won, in percent
+func winRate(wins, games int) float64 {
+	if games == 0 {
+		return 0
+	}
+	return 100 * float64(wins) / float64(games)
+}
+
+// difficultyOf returns the name of the PvE difficulty level playing as the bot with spec, or "" if none does
+// A level's own spec is matched before the specs it plays on solved boards, so "perfect" names the Perfect level
+func difficultyOf(spec string) string {
+	for _, solved := range []bool{false, true} {
+		for _, level := range difficultyLevels {
+			specs := []string{level.Spec}
+			if solved {
+				specs = specs[:0]
+				for _, solvedSpec := range level.Solved {
+					specs = append(specs, solvedSpec)
+				}
+			}
+			for _, levelSpec := range specs {
+				if matchesSpec(levelSpec, spec, level.Name+"Bot") {
+					return level.displayName()
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// matchesSpec reports whether the bot levelSpec builds has the given spec
+func matchesSpec(levelSpec, spec, name string) bool {
+	bot, err := newBotFromSpec(levelSpec, 'o', name)
+	if err != nil {
+		return false
+	}
+	defer bot.Close()
+	return bots.ConfigOf(bot).Spec() == spec
+}
+
+// RunProfiles lets the human select or create a profile from the menu and shows its lifetime statistics
+func RunProfiles() {
+	store := sharedStatsStore()
+	store.mutex.Lock()
+	names := make([]string, 0, len(store.Players))
+	games := make(map[string]int, len(store.Players))
+	for _, record := range store.Players {
+		names = append(names, record.Name)
+		games[record.Name] = record.GamesPlayed()
+	}
+	store.mutex.Unlock()
+	sort.Slice(names, func(a, b int) bool { return strings.ToLower(names[a]) < strings.ToLower(names[b]) })
+
+	fmt.Println(msg("profile.title"))
+	for i, name := range names {
+		fmt.Print(msg("profile.choice", i+1, name, games[name]))
+	}
+	fmt.Print(msg("profile.prompt", currentProfile))
+
+	var input string
+	fmt.Scanln(&input)
+	if choice, err := strconv.Atoi(input); err == nil && choice >= 1 && choice <= len(names) {
+		currentProfile = names[choice-1]
+	} else if input != "" {
+		currentProfile = input
+	}
+	printProfile(store, currentProfile)
+}
+
+// printProfile shows the lifetime statistics of the named human: results per bot and difficulty, move time and rating
+func printProfile(store *StatsStore, name string) {
+	fmt.Print(msg("profile.summary", name))
+	record, exists := store.Lookup(name)
+	if !exists || record.GamesPlayed() == 0 {
+		fmt.Println(msg("profile.no_games"))
+		return
+	}
+
+	store.mutex.Lock()
+	profile := *record
+	opponents := make([]string, 0, len(record.Opponents))
+	for spec := range record.Opponents {
+		opponents = append(opponents, spec)
+	}
+	store.mutex.Unlock()
+
+	games := profile.GamesPlayed()
+	fmt.Print(msg("profile.record", games, profile.Wins, profile.Draws, profile.Losses, winRate(profile.Wins, games)))
+	fmt.Print(msg("profile.move_time", profile.AverageMoveMS()/1000, profile.Moves))
+	key, _, _ := ratingKey(nil, name)
+	if rating, rated := store.Rating(key); rated {
+		fmt.Print(msg("profile.rating", rating.String()))
+	}
+	if !profile.LastPlayed.IsZero() {
+		fmt.Print(msg("profile.last_played", profile.LastPlayed.Local().Format("2006-01-02 15:04")))
+	}
+	if tendencies := profile.Tendencies; tendencies != nil {
+		store.mutex.Lock()
+		fmt.Print(msg("profile.tendencies", strings.Join(tendencies.favoriteColumns(3), ", "),
+			tendencies.VerticalBlocked, tendencies.VerticalBlocked+tendencies.VerticalMissed,
+			tendencies.OtherBlocked, tendencies.OtherBlocked+tendencies.OtherMissed))
+		store.mutex.Unlock()
+	}
+
+	if len(opponents) == 0 {
+		return
+	}
+	sort.Strings(opponents)
+	fmt.Println(msg("profile.opponents"))
+	for _, spec := range opponents {
+		store.mutex.Lock()
+		opponent := *record.Opponents[spec]
+		store.mutex.Unlock()
+
+		label := spec
+		if difficulty := difficultyOf(spec); difficulty != "" {
+			label = fmt.Sprintf("%s, %s", spec, difficulty)
+		}
+		played := opponent.Wins + opponent.Draws + opponent.Losses
+		fmt.Print(msg("profile.opponent", opponent.Name, label, played, opponent.Wins, opponent.Draws, opponent.Losses, winRate(opponent.Wins, played)))
+	}
+}
+
+// recordFinishedProfileGame adds a PvE game that was played to the end to the human's profile (an OnGameEnd observer)
+func recordFinishedProfileGame(end GameEnd) {
+	if end.Winner != 0 && end.Record.Mode == "pve" {
+		recordProfileGame(end.Result)
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"tic-tac-toe-3d-bots/bots"
+)
+
+// DEFAULT_PROFILES_FILE is loaded automatically when present and no other profiles file is given
+const DEFAULT_PROFILES_FILE = "bot_profiles.json"
+
+// BotProfile is a named, reusable bot definition loaded from a profiles file
+type BotProfile struct {
+	Name   string // profile name as written in the file, e.g. "Strong-X"
+	Config bots.BotConfig
+}
+
+// botProfiles holds the loaded profiles keyed by lowercase name
+var botProfiles = make(map[string]*BotProfile)
+
+// loadBotProfiles reads a JSON object mapping profile names to bot configurations, e.g.
+//
+//	{"Strong-X": {"type": "alphabeta", "params": {"depth": 8, "base": 10}}}
+//
+// A profile of type "external" plays with an external engine (see ExternalBot), one of type "remote"
+// with a server's bot (see RemoteBot), and one of type "cluster" searches on several machines (see ClusterBot)
+//
+// Profiles are added to (and may replace) those already loaded
+func loadBotProfiles(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var configs map[string]bots.BotConfig
+	decoder := json.NewDecoder(file)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&configs); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+
+	for name, config := range configs {
+		if err := addBotProfile(name, config); err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+	}
+
+	return nil
+}
+
+// addBotProfile adds or replaces the profile name
+func addBotProfile(name string, config bots.BotConfig) error {
+	if config.Type == "" {
+		return fmt.Errorf("profile %q has no type", name)
+	}
+	if _, isProfile := lookupBotProfile(config.Type); isProfile {
+		return fmt.Errorf("profile %q cannot refer to another profile", name)
+	}
+	usesCommand := config.Type == EXTERNAL_BOT_TYPE || config.Type == REMOTE_BOT_TYPE || config.Type == CLUSTER_BOT_TYPE
+	if usesCommand != (len(config.Command) > 0) {
+		return fmt.Errorf("profile %q needs a command if and only if its type is %q, %q or %q", name,
+			EXTERNAL_BOT_TYPE, REMOTE_BOT_TYPE, CLUSTER_BOT_TYPE)
+	}
+	var err error
+	switch config.Type {
+	case REMOTE_BOT_TYPE:
+		_, _, err = parseRemoteCommand(config.Command)
+	case CLUSTER_BOT_TYPE:
+		_, err = parseClusterCommand(config.Command)
+	}
+	if err != nil {
+		return fmt.Errorf("profile %q: %v", name, err)
+	}
+	botProfiles[strings.ToLower(name)] = &BotProfile{Name: name, Config: config}
+	return nil
+}
+
+// loadDefaultBotProfiles loads DEFAULT_PROFILES_FILE if it exists in the working directory
+func loadDefaultBotProfiles() error {
+	if _, err := os.Stat(DEFAULT_PROFILES_FILE); err != nil {
+		return nil // No default profiles file, nothing to load
+	}
+	return loadBotProfiles(DEFAULT_PROFILES_FILE)
+}
+
+// lookupBotProfile finds a profile by name (case-insensitive)
+func lookupBotProfile(name string) (*BotProfile, bool) {
+	profile, exists := botProfiles[strings.ToLower(strings.TrimSpace(name))]
+	return profile, exists
+}
+
+// sortedBotProfiles returns all loaded profiles ordered by name, for stable menu numbering
+func sortedBotProfiles() []*BotProfile {
+	profiles := make([]*BotProfile, 0, len(botProfiles))
+	for _, profile := range botProfiles {
+		profiles = append(profiles, profile)
+	}
+	sort.Slice(profiles, func(i, j int) bool {
+		return strings.ToLower(profiles[i].Name) < strings.ToLower(profiles[j].Name)
+	})
+	return profiles
+}
+
+// displayName returns the profile's configured display name, or the profile name itself
+func (profile *BotProfile) displayName() string {
+	if profile.Config.Name != "" {
+		return profile.Config.Name
+	}
+	return profile.Name
+}
+
+// printProfileChoices lists the loaded profiles as menu entries numbered from firstChoice
+func printProfileChoices(firstChoice int) {
+	for i, profile := range sortedBotProfiles() {
+		fmt.Print(msg("profile.entry", firstChoice+i, profile.Name, profile.Config.Spec()))
+	}
+}
+
+// profileForChoice returns the profile selected by a menu choice numbered from firstChoice, if any
+func profileForChoice(choice, firstChoice int) (*BotProfile, bool) {
+	profiles := sortedBotProfiles()
+	index := choice - firstChoice
+	if index < 0 || index >= len(profiles) {
+		return nil, false
+	}
+	return profiles[index], true
+}
+
+// newBotFromProfile creates a bot from a profile, named after the profile
+func newBotFromProfile(profile *BotProfile, symbol byte) (bots.BotInterface, error) {
+	return newBotFromSpec(profile.Name, symbol, profile.displayName())
+}
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"tic-tac-toe-3d-bots/bots"
+	"tic-tac-toe-3d-bots/engine"
+	"tic-tac-toe-3d-bots/formats"
+)
+
+// RunPvE starts a Player vs Environment (Bot) game and returns its rematch
+func RunPvE() Rematch {
+	board := chooseBoard(3)
+
+	// Ask user how hard the bot should play
+	fmt.Println(msg("pve.title"))
+	fmt.Println(msg("pve.choose_difficulty"))
+	for i, level := range difficultyLevels {
+		fmt.Printf("%d. %s (%s)\n", i+1, level.displayName(), level.description())
+	}
+	fmt.Print(msg("pve.custom", len(difficultyLevels)+1))
+	fmt.Print(msg("choice.prompt", len(difficultyLevels)+1))
+
+	var levelChoice int
+	fmt.Scanln(&levelChoice)
+
+	// newBot builds the chosen opponent for either side, so rematches can swap sides
+	var newBot func(symbol byte) bots.BotInterface
+	if levelChoice >= 1 && levelChoice <= len(difficultyLevels) {
+		level := difficultyLevels[levelChoice-1]
+		newBot = func(symbol byte) bots.BotInterface {
+			bot, _ := newBotFromSpec(level.specFor(board), symbol, level.Name+"Bot")
+			return bot
+		}
+	} else if levelChoice == len(difficultyLevels)+1 {
+		// Ask user which bot to face
+		fmt.Println(msg("pve.choose_opponent"))
+		choices := printBotChoices()
+		fmt.Print(msg("choice.prompt", choices))
+
+		var botChoice int
+		fmt.Scanln(&botChoice)
+		newBot = func(symbol byte) bots.BotInterface {
+			return createBot(botChoice, symbol, "")
+		}
+	}
+
+	bot := bots.BotInterface(nil)
+	if newBot != nil {
+		bot = newBot('o')
+	}
+	if bot == nil {
+		fmt.Println(msg("choice.fallback"))
+		newBot = func(symbol byte) bots.BotInterface {
+			return bots.NewBot(symbol, "RandomBot")
+		}
+		bot = newBot('o')
+	} else {
+		fmt.Print(msg("pve.you_face", bot.Name()))
+	}
+
+	// Games are recorded in the human's profile, so ask who is playing
+	fmt.Print(msg("pve.enter_name", currentProfile))
+	var playerName string
+	fmt.Scanln(&playerName)
+	if playerName != "" {
+		currentProfile = playerName
+	}
+
+	// Some bots also play by the profile, such as the adaptive bot, which sets its strength from it
+	if player, ok := bot.(playerBot); ok {
+		player.SetPlayer(currentProfile)
+		if adaptive, ok := bot.(*AdaptiveBot); ok {
+			fmt.Print(msg("pve.adaptive_welcome", adaptive.Player, adaptive.Level()))
+		}
+
+		newPlayerBot, profile := newBot, currentProfile
+		newBot = func(symbol byte) bots.BotInterface {
+			bot := newPlayerBot(symbol)
+			bot.(playerBot).SetPlayer(profile)
+			return bot
+		}
+	}
+
+	fmt.Print(msg("pve.training_prompt"))
+	var trainingInput string
+	fmt.Scanln(&trainingInput)
+
+	return pveMatch(board, bot, newBot, formats.PvESettings{Training: strings.EqualFold(trainingInput, "y")})
+}
+
+// DifficultyLevel maps a friendly difficulty name to a bot spec
+// The menu text comes from the "difficulty.<name>" and "difficulty.<name>.desc" messages
+type DifficultyLevel struct {
+	Name   string            // English name, also used to name the bot
+	Spec   string            // bot spec as accepted by newBotFromSpec
+	Solved map[[4]int]string // spec played instead on the solved boards, by [Length, Width, Height, WinLength]
+}
+
+// difficultyLevels lists the PvE difficulty presets from easiest to hardest
+var difficultyLevels = []DifficultyLevel{
+	{Name: "Easy", Spec: "rules"},
+	{Name: "Medium", Spec: "alphabeta:depth=3"},
+	{Name: "Hard", Spec: "limited:elo=1700"},
+	{Name: "Expert", Spec: "alphabeta:depth=64,movetime=3000", Solved: map[[4]int]string{{3, 3, 3, 3}: "perfect"}},
+	{Name: "Perfect", Spec: "perfect"},
+	{Name: "Adaptive", Spec: "adaptive"},
+}
+
+// specFor returns the spec of the bot playing the level on board
+func (level DifficultyLevel) specFor(board *engine.Board) string {
+	if spec, found := level.Solved[[4]int{board.Length, board.Width, board.Height, board.WinLength}]; found {
+		return spec
+	}
+	return level.Spec
+}
+
+// displayName returns the level's name in the current locale
+func (level DifficultyLevel) displayName() string {
+	return msg("difficulty." + strings.ToLower(level.Name))
+}
+
+// description returns the level's menu description in the current locale
+func (level DifficultyLevel) description() string {
+	return msg("difficulty." + strings.ToLower(level.Name) + ".desc")
+}
+
+// playPvE runs a game between the human and the given bot; the human plays the other symbol
+// and 'x' always moves first
+// The bot is closed when the game ends, after being told the result if it is a GameResultListener
+func playPvE(board *engine.Board, bot bots.BotInterface, settings formats.PvESettings) {
+	human := engine.OpponentSymbol(bot.Symbol())
+	humanName := currentProfile
+	switch player := bot.(type) {
+	case *AdaptiveBot:
+		humanName = player.Player
+	case *ModelingBot:
+		humanName = player.Player
+	}
+
+	record := formats.GameRecord{Mode: "pve", Player: humanName, PvE: &settings}
+	record.Players[formats.SymbolIndex(human)] = humanName
+	record.Players[formats.SymbolIndex(bot.Symbol())] = bot.Name()
+	record.Bots[formats.SymbolIndex(bot.Symbol())] = bots.ConfigOf(bot)
+	session := startGame(board, record)
+	defer session.End()
+	defer bot.Close()
+
+	winner := byte('|')
+	defer func() {
+		// An interrupted game has no result
+		if listener, ok := bot.(bots.GameResultListener); ok && !session.Interrupted() {
+			listener.GameOver(winner)
+		}
+	}()
+
+	current := board.NextPlayer()
+	totalMoves := board.MoveCount()
+	maxMoves := board.Length * board.Width * board.Height
+
+	fmt.Println("\n" + msg("game.welcome"))
+	fmt.Print(msg("pve.sides", human, bot.Name(), bot.Symbol()))
+	fmt.Print(msg("game.move_format", 'A'+byte(board.Length-1), board.Width))
+	fmt.Println(msg("pve.hint_help"))
+	fmt.Println(msg("pve.heat_help"))
+	fmt.Println(msg("save.help"))
+	fmt.Println()
+
+	showHeatMap := false
+	var cursor cursorPosition
+	turnStart := time.Now()
+	for totalMoves < maxMoves {
+		if current == human {
+			var overlay map[[3]int]byte
+			if showHeatMap {
+				overlay = heatMap(board, human, HINT_TIME_LIMIT)
+			}
+			if !CursorInput {
+				printBoardWithOverlay(board, overlay) // In cursor mode the board is drawn live while choosing
+			}
+			if showHeatMap {
+				fmt.Print(msg("pve.heat_legend", HEAT_GOOD, HEAT_NEUTRAL, HEAT_BAD))
+			}
+			printEvalBar(session, board, human)
+
+			// Player's turn
+			fmt.Print(msg("pve.your_turn", human))
+			var moveInput, argument string
+			if CursorInput {
+				fmt.Println()
+				moveInput = readCursorMove(board, human, &cursor, overlay)
+			}
+			if moveInput == "" {
+				fmt.Scanln(&moveInput, &argument)
+			}
+
+			if saveCommand(session, moveInput, argument) {
+				continue
+			}
+
+			if strings.EqualFold(moveInput, "heat") {
+				showHeatMap = !showHeatMap
+				continue
+			}
+
+			if strings.EqualFold(moveInput, "hint") {
+				// Analyse a copy of the board so the opponent bot's state is left untouched
+				if hintCount, _ := strconv.Atoi(argument); hintCount > 1 {
+					printCandidates(board, human, hintCount, HINT_TIME_LIMIT)
+				} else if hint, ok := suggestHint(board, human, HINT_TIME_LIMIT); ok {
+					fmt.Print(msg("pve.hint", hint.Move, hint.Reason))
+				}
+				continue
+			}
+
+			coords := board.Move(moveInput, human)
+			if coords[0] == -1 && coords[1] == -1 && coords[2] == -1 {
+				fmt.Println(msg("game.invalid_move"))
+				continue
+			}
+
+			// Training mode: warn about a blunder before the bot sees the move
+			if settings.Training {
+				if warning := blunderWarning(board, human, moveInput, HINT_TIME_LIMIT); warning != "" {
+					fmt.Println(warning)
+					fmt.Print(msg("pve.keep_prompt"))
+					var keep string
+					fmt.Scanln(&keep)
+					if strings.EqualFold(keep, "n") {
+						board.UnMove(moveInput)
+						fmt.Println(msg("pve.taken_back"))
+						continue
+					}
+				}
+			}
+
+			fmt.Print(msg("pve.your_move", moveInput, coords[0], coords[1], coords[2]))
+			session.RecordMove(moveInput, time.Since(turnStart))
+			totalMoves++
+			bot.OpponentMove(moveInput)
+
+			// Check for player win
+			winner = board.CheckWin()
+			if winner == human {
+				printBoard(board)
+				fmt.Print(msg("pve.you_win"))
+				return
+			}
+		} else {
+			// Bot's turn
+			fmt.Print(msg("pve.thinking", bot.Name()))
+
+			start := time.Now()
+			ctx, searchDone := session.searchContext()
+			botMove, err := makeMoveWithLiveLine(session, bot, board, LIVE_LINE_INTERVAL, ctx)
+			searchDone()
+			if session.Interrupted() {
+				return
+			}
+			if err != nil && !board.IsFull() {
+				// A bot that cannot move, such as an external engine that crashed, forfeits; only a full board leaves no
+				// move to play
+				session.SetResult(human, "forfeit")
+				fmt.Print(msg("pve.bot_forfeits", bot.Name(), err))
+				return
+			}
+			if err != nil {
+				break // The board is full
+			}
+			fmt.Print(msg("pve.time_taken", bot.Name(), time.Since(start)))
+
+			fmt.Print(msg("pve.bot_plays", bot.Name(), botMove.Name, botMove.Coords[0], botMove.Coords[1], botMove.Coords[2]))
+			session.RecordMove(botMove.Name, time.Since(start))
+			session.LogBotSearch(bot)
+			totalMoves++
+
+			// Check for bot win
+			winner = board.CheckWin()
+			if winner == bot.Symbol() {
+				printBoard(board)
+				fmt.Print(msg("pve.bot_wins", bot.Name()))
+				return
+			}
+		}
+
+		// Check if board is full
+		if board.IsFull() {
+			break
+		}
+		current = engine.OpponentSymbol(current)
+		turnStart = time.Now()
+	}
+
+	// If we reach here, it's a draw
+	printBoard(board)
+	fmt.Println(msg("game.draw"))
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"tic-tac-toe-3d-bots/bots"
+	"tic-tac-toe-3d-bots/engine"
+	"tic-tac-toe-3d-bots/formats"
+)
+
+// RunPvEStream runs the PvE Stream mode with multi-depth concurrent alpha-beta analysis
+func RunPvEStream() {
+	fmt.Println(msg("pvestream.title"))
+	fmt.Println("═══════════════════════════════════════════════")
+	fmt.Println()
+	fmt.Println(msg("pvestream.intro1"))
+	fmt.Println(msg("pvestream.intro2"))
+	fmt.Println()
+
+	// Define the depths to analyze
+	depths := []int{3, 4, 5, 6, 7}
+
+	playPvEStream(chooseBoard(4), depths)
+}
+
+// playPvEStream runs a PvE Stream game on the given board, analysing with the given depths
+func playPvEStream(board *engine.Board, depths []int) {
+	// Player is always X, multi-depth bot is O
+	playerSymbol := byte('x')
+	botSymbol := byte('o')
+	currentPlayer := board.NextPlayer()
+
+	session := startGame(board, formats.GameRecord{
+		Mode:    "pvestream",
+		Players: [2]string{DEFAULT_PLAYER_NAME, "Multi-Depth Bot"},
+		Player:  DEFAULT_PLAYER_NAME,
+		Depths:  depths,
+	})
+	defer session.End()
+	turnStart := time.Now()
+
+	// Coalesce intermediate updates so the console isn't flooded during a single move
+	throttle := bots.StreamThrottle{MinInterval: 200 * time.Millisecond, MinScoreDelta: 10}
+
+	fmt.Print(msg("pvestream.depths", depths))
+	fmt.Println(msg("save.help"))
+	fmt.Println()
+
+	for {
+		printBoard(board)
+		fmt.Println()
+
+		// Check for win condition
+		winner := board.CheckWin()
+		if winner != '|' {
+			if winner == playerSymbol {
+				fmt.Println(msg("game.you_win"))
+			} else {
+				fmt.Println(msg("pvestream.bot_wins"))
+			}
+			break
+		}
+
+		// Check for draw
+		if len(board.GetValidMoves()) == 0 {
+			fmt.Println(msg("game.draw_short"))
+			break
+		}
+
+		if currentPlayer == playerSymbol {
+			// Player's turn
+			fmt.Print(msg("pvestream.prompt"))
+			var moveInput, argument string
+			fmt.Scanln(&moveInput, &argument)
+
+			if saveCommand(session, moveInput, argument) {
+				continue
+			}
+
+			col, row := engine.ParseMove(moveInput)
+			if col == -1 || row == -1 {
+				fmt.Println(msg("pvestream.invalid_format"))
+				continue
+			}
+
+			coords := board.Move(moveInput, playerSymbol)
+			if coords[0] == -1 {
+				fmt.Println(msg("game.invalid_move"))
+				continue
+			}
+
+			fmt.Print(msg("pvestream.you_played", moveInput, coords[0], coords[1], coords[2]))
+			session.RecordMove(moveInput, time.Since(turnStart))
+		} else {
+			// Multi-depth bot's turn
+			fmt.Println(msg("pvestream.analyzing"))
+			fmt.Println("─────────────────────────────────────")
+
+			start := time.Now()
+
+			// Use multi-depth streaming analysis
+			parentCtx, searchDone := session.searchContext()
+			ctx, cancel := context.WithCancel(parentCtx)
+			resultCh := bots.MultiDepthAlphaBetaStream(board, false, depths, throttle, bots.DefaultStreamBuffering, ctx) // Bot is minimizing (O)
+
+			var bestMove string
+			var finalResult bots.MultiDepthStreamResult
+
+			// Listen to the stream and show real-time updates
+			for result := range resultCh {
+				if result.Final {
+					finalResult = result
+					break
+				}
+
+				// Show intermediate results
+				movesStr := strings.Join(result.Moves, " → ")
+				fmt.Print(msg("pvestream.new_best", result.Depth, movesStr, result.Score))
+			}
+
+			cancel() // Release any search goroutines still running
+			searchDone()
+			if session.Interrupted() {
+				return
+			}
+			duration := time.Since(start)
+
+			// Execute the best move found
+			if len(finalResult.Moves) > 0 {
+				bestMove = finalResult.Moves[0]
+				coords := board.Move(bestMove, botSymbol)
+
+				fmt.Println("─────────────────────────────────────")
+				movesStr := strings.Join(finalResult.Moves, " → ")
+				fmt.Print(msg("pvestream.final", finalResult.Depth, movesStr))
+				fmt.Print(msg("pvestream.bot_plays", bestMove, coords[0], coords[1], coords[2], duration))
+				session.RecordMove(bestMove, duration)
+				session.LogSearch(GameEvent{SearchDepth: finalResult.Depth})
+			} else {
+				fmt.Println(msg("pvestream.no_move"))
+				break
+			}
+		}
+
+		fmt.Println()
+
+		// Switch players
+		if currentPlayer == playerSymbol {
+			currentPlayer = botSymbol
+		} else {
+			currentPlayer = playerSymbol
+		}
+		turnStart = time.Now()
+	}
+
+	fmt.Println(msg("pvestream.game_over"))
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"tic-tac-toe-3d-bots/engine"
+	"tic-tac-toe-3d-bots/formats"
+)
+
+// RunPvP starts a Player vs Player game and returns its rematch
+func RunPvP() Rematch {
+	return pvpMatch(chooseBoard(3), defaultPlayerNames())
+}
+
+// defaultPlayerNames returns the names of the two PvP players, the first playing 'x'
+func defaultPlayerNames() [2]string {
+	return [2]string{msg("pvp.player1"), msg("pvp.player2")}
+}
+
+// playPvP runs a Player vs Player game on the given board; playerNames[0] plays 'x'
+func playPvP(board *engine.Board, playerNames [2]string) {
+	session := startGame(board, formats.GameRecord{Mode: "pvp", Players: playerNames})
+	defer session.End()
+
+	players := []byte{'x', 'o'}
+	currentPlayer := formats.SymbolIndex(board.NextPlayer())
+	totalMoves := board.MoveCount()
+	maxMoves := board.Length * board.Width * board.Height
+
+	fmt.Println(msg("pvp.title"))
+	fmt.Println(msg("game.welcome"))
+	fmt.Print(msg("game.move_format", 'A'+byte(board.Length-1), board.Width))
+	fmt.Println(msg("save.help"))
+	fmt.Println()
+
+	var cursor cursorPosition
+	turnStart := time.Now()
+	for totalMoves < maxMoves {
+		if !CursorInput {
+			printBoard(board)
+		}
+		fmt.Print(msg("pvp.turn", playerNames[currentPlayer], players[currentPlayer]))
+
+		var moveInput, argument string
+		if CursorInput {
+			fmt.Println()
+			moveInput = readCursorMove(board, players[currentPlayer], &cursor, nil)
+		}
+		if moveInput == "" {
+			fmt.Scanln(&moveInput, &argument)
+		}
+
+		if saveCommand(session, moveInput, argument) {
+			continue
+		}
+
+		coords := board.Move(moveInput, players[currentPlayer])
+
+		if coords[0] == -1 && coords[1] == -1 && coords[2] == -1 {
+			fmt.Println(msg("game.invalid_move"))
+			continue
+		}
+
+		fmt.Print(msg("pvp.placed", moveInput, coords[0], coords[1], coords[2]))
+		session.RecordMove(moveInput, time.Since(turnStart))
+		turnStart = time.Now()
+		totalMoves++
+
+		// Check for win
+		winner := board.CheckWin()
+		if winner != '|' {
+			printBoard(board)
+			fmt.Print(msg("pvp.wins", playerNames[currentPlayer]))
+			return
+		}
+
+		// Switch to next player
+		currentPlayer = (currentPlayer + 1) % 2
+	}
+
+	// If we reach here, it's a draw
+	printBoard(board)
+	fmt.Println(msg("game.draw"))
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"sync"
+
+	"tic-tac-toe-3d-bots/bots"
+	"tic-tac-toe-3d-bots/engine"
+)
+
+// DEFAULT_QTABLE_FILE is where the Q-learning bot's value table is kept between runs; the train command writes it
+const DEFAULT_QTABLE_FILE = "qtable.json"
+
+// QLEARNING_DISCOUNT weighs a result by how soon it comes: a win now is worth 1, a win two moves later this much less
+const QLEARNING_DISCOUNT = 0.95
+
+// QTable holds the values learned by self-play of playing a move in a position, for the player to move: 1 for a sure
+// win, -1 for a sure loss. Positions are keyed by qState, so the table holds one of each set of symmetric positions
+// and learns them all at once
+type QTable struct {
+	path     string
+	Values   map[string]map[string]float64 `json:"values"`   // by position, then by move on the position's canonical board
+	Episodes int                           `json:"episodes"` // self-play games learned from
+
+	symmetries map[engine.BoardConfig][][]int // by board, for each symmetry, where each column goes
+	mutex      sync.Mutex                     // guards symmetries, which the bots of parallel games fill in
+}
+
+// newQTable creates an empty table that will be saved to path
+func newQTable(path string) *QTable {
+	return &QTable{path: path, Values: make(map[string]map[string]float64), symmetries: make(map[engine.BoardConfig][][]int)}
+}
+
+// loadQTable reads the table file at path; a missing file gives an empty table
+func loadQTable(path string) (*QTable, error) {
+	table := newQTable(path)
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return table, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, table); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if table.Values == nil {
+		table.Values = make(map[string]map[string]float64)
+	}
+	return table, nil
+}
+
+// Save writes the table back to its file, replacing it atomically
+func (table *QTable) Save() error {
+	data, err := json.Marshal(table)
+	if err != nil {
+		return err
+	}
+	tmpPath := table.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, table.path)
+}
+
+var (
+	sharedQTableOnce sync.Once
+	sharedQTableData *QTable
+)
+
+// sharedQTable returns the table in DEFAULT_QTABLE_FILE, loaded on first use and shared by every Q-learning bot
+func sharedQTable() *QTable {
+	sharedQTableOnce.Do(func() {
+		table, err := loadQTable(DEFAULT_QTABLE_FILE)
+		if err != nil {
+			fmt.Println(msg("qtable.load_error"), err)
+			table = newQTable(DEFAULT_QTABLE_FILE)
+		}
+		sharedQTableData = table
+	})
+	return sharedQTableData
+}
+
+// qState returns the key of the position on board, the same for every symmetric position, and the symmetry that maps
+// board onto the canonical board the key stands for; see Board.Transformed
+func (table *QTable) qState(board *engine.Board) (string, int) {
+	config := engine.BoardConfig{Length: board.Length, Width: board.Width, Height: board.Height, Win: board.WinLength}
+	table.mutex.Lock()
+	columns := table.symmetries[config]
+	if columns == nil {
+		columns = make([][]int, board.Symmetries())
+		for symmetry := range columns {
+			columns[symmetry] = make([]int, board.Length*board.Width)
+			for col := 0; col < board.Length; col++ {
+				for row := 0; row < board.Width; row++ {
+					to, toRow := engine.ParseMove(board.TransformMove(fmt.Sprintf("%c%d", 'A'+byte(col), row+1), symmetry))
+					columns[symmetry][col*board.Width+row] = to*board.Width + toRow
+				}
+			}
+		}
+		table.symmetries[config] = columns
+	}
+	table.mutex.Unlock()
+
+	// The cells column by column, then the player to move, after the board's size
+	prefix := fmt.Sprintf("%dx%dx%d/%d ", board.Length, board.Width, board.Height, board.WinLength)
+	best, bestSymmetry := "", 0
+	cells := make([]byte, board.Length*board.Width*board.Height+1)
+	for symmetry, moved := range columns {
+		for col := 0; col < board.Length; col++ {
+			for row := 0; row < board.Width; row++ {
+				to := moved[col*board.Width+row] * board.Height
+				for height := 0; height < board.Height; height++ {
+					cells[to+height] = '-'
+					if height < board.CurrentHeights[col][row] {
+						cells[to+height] = board.Grid[col][row][height]
+					}
+				}
+			}
+		}
+		cells[len(cells)-1] = board.NextPlayer()
+		if key := prefix + string(cells); symmetry == 0 || key < best {
+			best, bestSymmetry = key, symmetry
+		}
+	}
+	return best, bestSymmetry
+}
+
+// bestMoves returns the valid moves on board of the highest value, and that value; a position the table has never
+// seen values every move 0
+func (table *QTable) bestMoves(board *engine.Board) ([]string, float64) {
+	key, symmetry := table.qState(board)
+	values := table.Values[key]
+	var best []string
+	bestValue := 0.0
+	for _, move := range board.GetValidMoves() {
+		value := values[board.TransformMove(move, symmetry)]
+		if len(best) == 0 || value > bestValue {
+			best, bestValue = []string{move}, value
+		} else if value == bestValue {
+			best = append(best, move)
+		}
+	}
+	return best, bestValue
+}
+
+// Episode plays a game of self-play from an empty board like board and learns from each of its moves: the value of a
+// move moves by alpha toward 1 if it wins, 0 if it fills the board, and otherwise minus the opponent's best value in
+// the position it leaves, discounted. Each move is random with chance epsilon, and otherwise of the highest value
+func (table *QTable) Episode(board *engine.Board, alpha, epsilon float64, random *rand.Rand) {
+	board = freshBoard(board)
+	for board.CheckWin() == '|' && !board.IsFull() {
+		symbol := board.NextPlayer()
+		key, symmetry := table.qState(board)
+
+		var move string
+		if random.Float64() < epsilon {
+			validMoves := board.GetValidMoves()
+			move = validMoves[random.Intn(len(validMoves))]
+		} else {
+			best, _ := table.bestMoves(board)
+			move = best[random.Intn(len(best))]
+		}
+		board.Move(move, symbol)
+
+		target := 0.0
+		if board.CheckWin() == symbol {
+			target = 1
+		} else if !board.IsFull() {
+			_, reply := table.bestMoves(board)
+			target = -QLEARNING_DISCOUNT * reply
+		}
+
+		if table.Values[key] == nil {
+			table.Values[key] = make(map[string]float64)
+		}
+		canonical := board.TransformMove(move, symmetry)
+		table.Values[key][canonical] += alpha * (target - table.Values[key][canonical])
+	}
+	table.Episodes++
+}
+
+// QLearningBot represents an AI player that plays the move of the highest value in its QTable, learned by self-play
+// with the train command, instead of searching. It only knows the positions the training reached, and plays randomly
+// from the others; a 3x3x3 board trains in under a minute
+type QLearningBot struct {
+	bots.BaseBot
+
+	table *QTable
+}
+
+// NewQLearningBot creates a new Q-learning bot playing from the given table
+func NewQLearningBot(symbol byte, name string, table *QTable) *QLearningBot {
+	return &QLearningBot{
+		BaseBot: bots.NewBaseBot(symbol, name),
+		table:   table,
+	}
+}
+
+// init registers QLearningBot with the bot registry
+func init() {
+	bots.RegisterBot(&bots.BotRegistration{
+		Key:         "qlearning",
+		DisplayName: "QLearningBot",
+		Description: "plays the values it learned by self-play with the train command, without searching",
+		Order:       14,
+		Defaults:    map[string]int{},
+		New: func(symbol byte, name string, params map[string]int) bots.BotInterface {
+			return NewQLearningBot(symbol, name, sharedQTable())
+		},
+	})
+}
+
+// MakeMove plays a move of the highest learned value, picking at random among equal ones (implements BotInterface)
+func (bot *QLearningBot) MakeMove(ctx context.Context, board *engine.Board) (bots.Move, error) {
+	if len(board.GetValidMoves()) == 0 {
+		return bots.Move{}, bots.ErrNoValidMoves
+	}
+	best, _ := bot.table.bestMoves(board)
+	return bots.PlayChosenMove(ctx, board, bot.Symbol(), best[bot.Random().Intn(len(best))])
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"tic-tac-toe-3d-bots/bots"
+	"tic-tac-toe-3d-bots/engine"
+	"tic-tac-toe-3d-bots/formats"
+)
+
+// Glicko-2 constants: new players start at DEFAULT_RATING ± DEFAULT_RD with DEFAULT_VOLATILITY
+const (
+	DEFAULT_RATING     = 1500.0
+	DEFAULT_RD         = 350.0
+	DEFAULT_VOLATILITY = 0.06
+	GLICKO_TAU         = 0.5      // how quickly volatility may change
+	GLICKO_SCALE       = 173.7178 // converts between the Glicko and Glicko-2 scales
+)
+
+// PlayerRating is the Glicko-2 rating of one bot configuration or human player
+type PlayerRating struct {
+	Name       string    `json:"name"`
+	Kind       string    `json:"kind"` // "bot" or "human"
+	Rating     float64   `json:"rating"`
+	RD         float64   `json:"rd"` // rating deviation: the rating is within ±2 RD with 95% confidence
+	Volatility float64   `json:"volatility"`
+	Wins       int       `json:"wins"`
+	Draws      int       `json:"draws"`
+	Losses     int       `json:"losses"`
+	Updated    time.Time `json:"updated"`
+}
+
+// RatedGame is one game of the rating history, kept so that ratings can be recomputed for a subset of games
+type RatedGame struct {
+	Time    time.Time          `json:"time"`
+	Mode    string             `json:"mode"`
+	Board   engine.BoardConfig `json:"board"`
+	Players [2]string          `json:"players"` // rating keys of the 'x' and 'o' players
+	Winner  string             `json:"winner"`  // "x", "o" or "draw"
+}
+
+// newPlayerRating returns the rating of a player who has not played yet
+func newPlayerRating(name, kind string) *PlayerRating {
+	return &PlayerRating{Name: name, Kind: kind, Rating: DEFAULT_RATING, RD: DEFAULT_RD, Volatility: DEFAULT_VOLATILITY}
+}
+
+// Games returns the number of rated games
+func (rating *PlayerRating) Games() int {
+	return rating.Wins + rating.Draws + rating.Losses
+}
+
+// String formats the rating with its 95% confidence interval, e.g. "1623 ± 98"
+func (rating *PlayerRating) String() string {
+	return fmt.Sprintf("%.0f ± %.0f", rating.Rating, 2*rating.RD)
+}
+
+var (
+	sharedStore     *StatsStore
+	sharedStoreOnce sync.Once
+)
+
+// sharedStatsStore returns the store of DEFAULT_STATS_FILE used by every game of this run
+// Sharing one store keeps concurrent games and adaptive bots from overwriting each other's updates
+func sharedStatsStore() *StatsStore {
+	sharedStoreOnce.Do(func() {
+		store, err := loadStatsStore(DEFAULT_STATS_FILE)
+		if err != nil {
+			fmt.Println(msg("player_stats.load_error"), err)
+			store = newStatsStore(DEFAULT_STATS_FILE)
+		}
+		sharedStore = store
+	})
+	return sharedStore
+}
+
+// RatingsFile is the stats store finished games are rated in; set from --ratings at startup (empty rates no game)
+var RatingsFile string
+
+var (
+	ratingsStore     *StatsStore
+	ratingsStoreOnce sync.Once
+)
+
+// sharedRatingsStore returns the store of RatingsFile used by every game of this run, which is the shared stats store
+// when RatingsFile is DEFAULT_STATS_FILE, so the two never overwrite each other; nil if no game is rated
+func sharedRatingsStore() *StatsStore {
+	if RatingsFile == "" {
+		return nil
+	}
+	if RatingsFile == DEFAULT_STATS_FILE {
+		return sharedStatsStore()
+	}
+	ratingsStoreOnce.Do(func() {
+		store, err := loadStatsStore(RatingsFile)
+		if err != nil {
+			fmt.Println(msg("player_stats.load_error"), err)
+			store = newStatsStore(RatingsFile)
+		}
+		ratingsStore = store
+	})
+	return ratingsStore
+}
+
+// ratingKey identifies a rated player: bots by their configuration, so renaming a bot keeps its rating, and humans by name
+// Remote arena engines have no configuration to go by, so they are rated by their name
+func ratingKey(bot *bots.BotConfig, name string) (key, kind, display string) {
+	if bot != nil && bot.Type == ARENA_BOT_TYPE {
+		return "arena:" + bot.Name, "bot", bot.Name
+	}
+	if bot != nil {
+		spec := bot.Spec()
+		return "bot:" + spec, "bot", spec
+	}
+	return "human:" + strings.ToLower(strings.TrimSpace(name)), "human", name
+}
+
+// Rating returns the rating stored under key, if any
+func (store *StatsStore) Rating(key string) (*PlayerRating, bool) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	rating, exists := store.Ratings[key]
+	if !exists {
+		return nil, false
+	}
+	copied := *rating
+	return &copied, true
+}
+
+// RateGame updates the ratings of both players of a finished game, adds it to the history and saves the store
+// winner is 'x', 'o' or '|' for a draw
+func (store *StatsStore) RateGame(record formats.GameRecord, winner byte) error {
+	game := RatedGame{Time: time.Now(), Mode: record.Mode, Board: record.Board, Winner: "draw"}
+	if winner == 'x' || winner == 'o' {
+		game.Winner = string(winner)
+	}
+
+	store.mutex.Lock()
+	var ratings [2]*PlayerRating
+	for i := range ratings {
+		key, kind, name := ratingKey(record.Bots[i], record.Players[i])
+		rating, exists := store.Ratings[key]
+		if !exists {
+			rating = newPlayerRating(name, kind)
+			store.Ratings[key] = rating
+		}
+		ratings[i] = rating
+		game.Players[i] = key
+	}
+	rateResult(ratings, game)
+	store.Games = append(store.Games, game)
+	store.mutex.Unlock()
+
+	return store.Save()
+}
+
+// rateResult updates the ratings of the 'x' and 'o' players of game with its result
+func rateResult(ratings [2]*PlayerRating, game RatedGame) {
+	scores := [2]float64{0.5, 0.5}
+	switch game.Winner {
+	case "x":
+		scores = [2]float64{1, 0}
+	case "o":
+		scores = [2]float64{0, 1}
+	}
+
+	// Both players are updated from their ratings before the game
+	before := [2]PlayerRating{*ratings[0], *ratings[1]}
+	for i, rating := range ratings {
+		rating.update(before[1-i], scores[i])
+		rating.Updated = game.Time
+		switch scores[i] {
+		case 1:
+			rating.Wins++
+		case 0:
+			rating.Losses++
+		default:
+			rating.Draws++
+		}
+	}
+}
+
+// update applies one Glicko-2 rating period made of a single game against opponent, scoring score
+func (rating *PlayerRating) update(opponent PlayerRating, score float64) {
+	rating.updatePeriod([]PlayerRating{opponent}, []float64{score})
+}
+
+// updatePeriod applies one Glicko-2 rating period made of games against opponents, scoring scores
+func (rating *PlayerRating) updatePeriod(opponents []PlayerRating, scores []float64) {
+	mu, phi := (rating.Rating-DEFAULT_RATING)/GLICKO_SCALE, rating.RD/GLICKO_SCALE
+
+	var inverseVariance, improvement float64
+	for i, opponent := range opponents {
+		opponentMu, opponentPhi := (opponent.Rating-DEFAULT_RATING)/GLICKO_SCALE, opponent.RD/GLICKO_SCALE
+		g := 1 / math.Sqrt(1+3*opponentPhi*opponentPhi/(math.Pi*math.Pi))
+		expected := 1 / (1 + math.Exp(-g*(mu-opponentMu)))
+		inverseVariance += g * g * expected * (1 - expected)
+		improvement += g * (scores[i] - expected)
+	}
+	variance := 1 / inverseVariance
+	delta := variance * improvement
+
+	volatility := newVolatility(phi, rating.Volatility, variance, delta)
+	phiStar := math.Sqrt(phi*phi + volatility*volatility)
+	phi = 1 / math.Sqrt(1/(phiStar*phiStar)+1/variance)
+	mu += phi * phi * improvement
+
+	rating.Rating = mu*GLICKO_SCALE + DEFAULT_RATING
+	rating.RD = min(phi*GLICKO_SCALE, DEFAULT_RD)
+	rating.Volatility = volatility
+}
+
+// newVolatility solves for the new volatility with the Illinois algorithm (step 5 of Glickman's Glicko-2 paper)
+func newVolatility(phi, sigma, variance, delta float64) float64 {
+	a := math.Log(sigma * sigma)
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		d := phi*phi + variance + ex
+		return ex*(delta*delta-d)/(2*d*d) - (x-a)/(GLICKO_TAU*GLICKO_TAU)
+	}
+
+	const epsilon = 0.000001
+	lower := a
+	var upper float64
+	if delta*delta > phi*phi+variance {
+		upper = math.Log(delta*delta - phi*phi - variance)
+	} else {
+		k := 1.0
+		for f(a-k*GLICKO_TAU) < 0 {
+			k++
+		}
+		upper = a - k*GLICKO_TAU
+	}
+
+	fLower, fUpper := f(lower), f(upper)
+	for math.Abs(upper-lower) > epsilon {
+		c := lower + (lower-upper)*fLower/(fUpper-fLower)
+		fc := f(c)
+		if fc*fUpper <= 0 {
+			lower, fLower = upper, fUpper
+		} else {
+			fLower /= 2
+		}
+		upper, fUpper = c, fc
+	}
+	return math.Exp(lower / 2)
+}
+
+// rateGame records a finished game in the shared ratings, if games are rated; errors are reported but never stop
+// the game
+func rateGame(record formats.GameRecord, winner byte) {
+	store := sharedRatingsStore()
+	if store == nil {
+		return
+	}
+	if err := store.RateGame(record, winner); err != nil {
+		fmt.Println(msg("ratings.save_error"), err)
+	}
+}
+
+// botRating returns the stored rating of the bot built by newBot, if games are rated and it has one
+func botRating(newBot func(symbol byte) bots.BotInterface) (*PlayerRating, bool) {
+	store := sharedRatingsStore()
+	if store == nil {
+		return nil, false
+	}
+	bot := newBot('x')
+	defer bot.Close()
+
+	key, _, _ := ratingKey(bots.ConfigOf(bot), bot.Name())
+	return store.Rating(key)
+}
+
+// rateFinishedGame rates a game that was played to the end (an OnGameEnd observer)
+// PvE Stream's analysis bot has no configuration to be rated by
+func rateFinishedGame(end GameEnd) {
+	if end.Winner != 0 && end.Record.Mode != "pvestream" {
+		rateGame(end.Record, end.Winner)
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// TestGlickoExample checks a rating period against the worked example of Glickman's "Example of the Glicko-2 system":
+// a 1500 player with RD 200 and volatility 0.06 beats a 1400 (RD 30) and loses to a 1550 (RD 100) and a 1700 (RD 300),
+// with tau 0.5
+func TestGlickoExample(t *testing.T) {
+	rating := PlayerRating{Rating: 1500, RD: 200, Volatility: 0.06}
+	opponents := []PlayerRating{{Rating: 1400, RD: 30}, {Rating: 1550, RD: 100}, {Rating: 1700, RD: 300}}
+	rating.updatePeriod(opponents, []float64{1, 0, 0})
+
+	if math.Abs(rating.Rating-1464.06) > 0.01 {
+		t.Errorf("rating: got %.2f, want 1464.06", rating.Rating)
+	}
+	if math.Abs(rating.RD-151.52) > 0.01 {
+		t.Errorf("RD: got %.2f, want 151.52", rating.RD)
+	}
+	if math.Abs(rating.Volatility-0.05999) > 0.00001 {
+		t.Errorf("volatility: got %.5f, want 0.05999", rating.Volatility)
+	}
+}
+
+func TestRateResult(t *testing.T) {
+	tests := []struct {
+		winner        string
+		wins, losses  [2]int
+		draws         int
+		higher, lower int // indices of the player whose rating rises and of the one whose rating falls, -1 if neither
+	}{
+		{"x", [2]int{1, 0}, [2]int{0, 1}, 0, 0, 1},
+		{"o", [2]int{0, 1}, [2]int{1, 0}, 0, 1, 0},
+		{"draw", [2]int{0, 0}, [2]int{0, 0}, 1, -1, -1},
+	}
+	for _, test := range tests {
+		ratings := [2]*PlayerRating{newPlayerRating("a", "bot"), newPlayerRating("b", "bot")}
+		rateResult(ratings, RatedGame{Winner: test.winner})
+		for i, rating := range ratings {
+			if rating.Wins != test.wins[i] || rating.Losses != test.losses[i] || rating.Draws != test.draws {
+				t.Errorf("%s: player %d has %d-%d-%d", test.winner, i, rating.Wins, rating.Draws, rating.Losses)
+			}
+			if rating.RD >= DEFAULT_RD {
+				t.Errorf("%s: player %d RD %.1f did not shrink", test.winner, i, rating.RD)
+			}
+		}
+		if test.higher >= 0 && (ratings[test.higher].Rating <= DEFAULT_RATING || ratings[test.lower].Rating >= DEFAULT_RATING) {
+			t.Errorf("%s: ratings %.1f and %.1f", test.winner, ratings[0].Rating, ratings[1].Rating)
+		}
+		if test.higher < 0 && math.Abs(ratings[0].Rating-ratings[1].Rating) > 0.001 {
+			t.Errorf("%s: even players end at %.1f and %.1f", test.winner, ratings[0].Rating, ratings[1].Rating)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"tic-tac-toe-3d-bots/bots"
+	"tic-tac-toe-3d-bots/engine"
+	"tic-tac-toe-3d-bots/formats"
+)
+
+// Rematch plays the same matchup again on a fresh board with the sides swapped
+// It returns the rematch after that one, so rematches can be chained
+type Rematch func() Rematch
+
+// askRematch asks whether to play again with the sides swapped
+func askRematch() bool {
+	fmt.Print(msg("rematch.prompt"))
+	var answer string
+	fmt.Scanln(&answer)
+	return strings.EqualFold(answer, "y")
+}
+
+// freshBoard returns an empty board with the same dimensions as board
+func freshBoard(board *engine.Board) *engine.Board {
+	fresh, _ := engine.New(engine.WithDims(board.Length, board.Width, board.Height), engine.WithWinLength(board.WinLength)) // as valid as board's
+	return fresh
+}
+
+// pvpMatch plays a PvP game where playerNames[0] plays 'x', returning the rematch with the names swapped
+func pvpMatch(board *engine.Board, playerNames [2]string) Rematch {
+	playPvP(board, playerNames)
+	return func() Rematch {
+		return pvpMatch(freshBoard(board), [2]string{playerNames[1], playerNames[0]})
+	}
+}
+
+// pveMatch plays a PvE game against bot, returning the rematch against a new bot from newBot on the other side
+// Every game gets its own bot because playPvE closes the bot when the game ends
+func pveMatch(board *engine.Board, bot bots.BotInterface, newBot func(symbol byte) bots.BotInterface, settings formats.PvESettings) Rematch {
+	playPvE(board, bot, settings)
+	return func() Rematch {
+		return pveMatch(freshBoard(board), newBot(engine.OpponentSymbol(bot.Symbol())), newBot, settings)
+	}
+}
+
+// eveMatch plays an EvE game with newBotX's bot as 'x', returning the rematch with the bots' sides swapped
+func eveMatch(board *engine.Board, newBotX, newBotO func(symbol byte) bots.BotInterface, settings formats.EvESettings) Rematch {
+	playEvE(board, newBotX('x'), newBotO('o'), settings)
+	return func() Rematch {
+		return eveMatch(freshBoard(board), newBotO, newBotX, settings)
+	}
+}
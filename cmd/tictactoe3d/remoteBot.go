@@ -0,0 +1,223 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"tic-tac-toe-3d-bots/bots"
+	"tic-tac-toe-3d-bots/engine"
+	"tic-tac-toe-3d-bots/formats"
+)
+
+// REMOTE_BOT_TYPE is the profile type of bots whose moves are searched by a remote server
+const REMOTE_BOT_TYPE = "remote"
+
+// REMOTE_LATENCY is the time allowed on top of a move's time limit for the request and the answer to travel
+const REMOTE_LATENCY = 500 * time.Millisecond
+
+// REMOTE_FALLBACK_RESERVE is kept from a move's deadline for the local search, should the server not answer in time
+const REMOTE_FALLBACK_RESERVE = 250 * time.Millisecond
+
+// remoteBotDefaults are the parameters a remote bot accepts and their defaults:
+// movetime is the server's time per move in milliseconds (a move time limit lowers it), depth the depth the server
+// searches to (0 leaves it to the bot), and fallback_depth the depth of the local alpha-beta search used instead
+// of the server's move when the server fails or is too slow
+var remoteBotDefaults = map[string]int{"movetime": 2000, "depth": 0, "fallback_depth": 3}
+
+// RemoteBot offloads its search to a server running the serve or grpc command, so that a weak device (or the
+// WebAssembly build) can play with the strength of a bigger machine. Remote bots are declared as bot profiles,
+// whose command is the server's address and the bot it should play (alphabeta if left out):
+//
+//	{"Box": {"type": "remote", "command": ["http://box:8080", "alphabeta:depth=8"], "params": {"movetime": 3000}}}
+//
+// An http:// or https:// address is the HTTP API of the serve command (POST /bot-move), and grpc://host:port the
+// Engine service of the grpc command (GetBestMove); the browser cannot reach the latter, which needs HTTP/2 trailers
+type RemoteBot struct {
+	bots.BaseBot
+	server   *url.URL
+	spec     string // the bot the server plays
+	moveTime time.Duration
+	depth    int
+	client   *http.Client
+	fallback bots.BotInterface
+}
+
+// newRemoteBotFromProfile creates the bot of a remote profile; params override the profile's own
+func newRemoteBotFromProfile(profile *BotProfile, symbol byte, name string, params map[string]int) (bots.BotInterface, error) {
+	resolved := make(map[string]int, len(remoteBotDefaults))
+	for key, value := range remoteBotDefaults {
+		resolved[key] = value
+	}
+	for _, overrides := range []map[string]int{profile.Config.Params, params} {
+		for key, value := range overrides {
+			if _, known := remoteBotDefaults[key]; !known {
+				return nil, fmt.Errorf("unknown parameter %q for bot %q", key, profile.Name)
+			}
+			resolved[key] = value
+		}
+	}
+	if resolved["movetime"] <= 0 || resolved["fallback_depth"] <= 0 || resolved["depth"] < 0 {
+		return nil, fmt.Errorf("bot %q: movetime and fallback_depth must be positive, and depth not negative", profile.Name)
+	}
+	server, spec, err := parseRemoteCommand(profile.Config.Command)
+	if err != nil {
+		return nil, fmt.Errorf("bot %q: %v", profile.Name, err)
+	}
+	if name == "" {
+		name = profile.displayName()
+	}
+
+	fallback, err := newBotFromSpec(fmt.Sprintf("alphabeta:depth=%d", resolved["fallback_depth"]), symbol, name)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{}
+	if server.Scheme == "grpc" {
+		client = newGRPCClient()
+	}
+
+	bot := &RemoteBot{
+		BaseBot:  bots.NewBaseBot(symbol, name),
+		server:   server,
+		spec:     spec,
+		moveTime: time.Duration(resolved["movetime"]) * time.Millisecond,
+		depth:    resolved["depth"],
+		client:   client,
+		fallback: fallback,
+	}
+	// Recreated through the profile, e.g. when a saved game is resumed
+	bot.SetConfig(&bots.BotConfig{Type: profile.Name, Name: name, Params: resolved})
+	return bot, nil
+}
+
+// parseRemoteCommand reads the server's address and the bot it plays from a remote profile's command
+func parseRemoteCommand(command []string) (*url.URL, string, error) {
+	if len(command) == 0 || len(command) > 2 {
+		return nil, "", fmt.Errorf("the command of a remote bot is the server's address, optionally followed by the bot it plays")
+	}
+	server, err := url.Parse(command[0])
+	if err != nil {
+		return nil, "", err
+	}
+	switch {
+	case server.Scheme != "http" && server.Scheme != "https" && server.Scheme != "grpc":
+		return nil, "", fmt.Errorf("unsupported server address %q (expected http://, https:// or grpc://)", command[0])
+	case server.Host == "":
+		return nil, "", fmt.Errorf("the server address %q has no host", command[0])
+	}
+	spec := "alphabeta"
+	if len(command) == 2 {
+		spec = command[1]
+	}
+	return server, spec, nil
+}
+
+// MakeMove asks the server for its move and plays it on the board, searching locally instead if the server fails or
+// does not answer in time (implements BotInterface)
+func (bot *RemoteBot) MakeMove(ctx context.Context, board *engine.Board) (bots.Move, error) {
+	if err := ctx.Err(); err != nil {
+		return bots.Move{}, err
+	}
+
+	wait := bot.moveTime + REMOTE_LATENCY
+	if deadline, ok := ctx.Deadline(); ok {
+		wait = min(wait, time.Until(deadline)-REMOTE_FALLBACK_RESERVE)
+	}
+	moveTime := min(bot.moveTime, wait-REMOTE_LATENCY)
+	if moveTime > 0 {
+		requestCtx, cancel := context.WithTimeout(ctx, wait)
+		defer cancel()
+		start := time.Now()
+		name, err := bot.request(requestCtx, board, moveTime)
+		if err == nil {
+			move, err := bots.PlayChosenMove(ctx, board, bot.Symbol(), strings.ToUpper(name))
+			if err == nil {
+				serverLog.Debug("remote move", "server", bot.server.Host, "move", move.Name, "elapsed", time.Since(start))
+				return move, nil
+			}
+			serverLog.Warn("the remote bot played an illegal move; searching locally", "server", bot.server.Host, "error", err)
+		} else if ctx.Err() != nil {
+			return bots.Move{}, ctx.Err()
+		} else {
+			serverLog.Warn("the remote bot did not answer; searching locally", "server", bot.server.Host, "error", err)
+		}
+	}
+	return bot.fallback.MakeMove(ctx, board)
+}
+
+// request asks the server for the move of its bot on board, within moveTime
+func (bot *RemoteBot) request(ctx context.Context, board *engine.Board, moveTime time.Duration) (string, error) {
+	config := engine.BoardConfig{Length: board.Length, Width: board.Width, Height: board.Height, Win: board.WinLength}
+	if bot.server.Scheme == "grpc" {
+		return bot.requestGRPC(ctx, formats.ProtoBoard{Length: config.Length, Width: config.Width, Height: config.Height,
+			Win: config.Win, Snapshot: board.Snapshot()}, moveTime)
+	}
+
+	spec := bot.spec
+	if bot.depth > 0 {
+		spec = engineDepthSpec(spec, bot.depth)
+	}
+	body, _ := json.Marshal(struct {
+		APIPosition
+		Bot         string `json:"bot"`
+		TimeLimitMS int64  `json:"time_limit_ms"`
+	}{APIPosition{Board: config, Snapshot: board.Snapshot()}, spec, moveTime.Milliseconds()})
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, bot.server.JoinPath("bot-move").String(), bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	response, err := bot.client.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	answer := struct {
+		Move  string `json:"move"`
+		Error string `json:"error"`
+	}{}
+	if err := json.NewDecoder(io.LimitReader(response.Body, 1<<20)).Decode(&answer); err != nil {
+		return "", fmt.Errorf("%s: %v", response.Status, err)
+	}
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s: %s", response.Status, answer.Error)
+	}
+	return answer.Move, nil
+}
+
+// requestGRPC calls GetBestMove on the server's Engine service
+func (bot *RemoteBot) requestGRPC(ctx context.Context, position formats.ProtoBoard, moveTime time.Duration) (string, error) {
+	request := formats.ProtoEncoder{}
+	request.Bytes(1, position.MarshalProto())
+	request.String(2, bot.spec)
+	request.Int(3, int64(bot.depth))
+	request.Int(4, moveTime.Milliseconds())
+	reply, err := grpcCall(ctx, bot.client, bot.server.Host, "/tictactoe3d.Engine/GetBestMove", request.Data)
+	if err != nil {
+		return "", err
+	}
+	move := formats.ProtoMove{}
+	if err := move.UnmarshalProto(reply); err != nil {
+		return "", err
+	}
+	return move.Name, nil
+}
+
+// OpponentMove is passed on to the fallback bot, which keeps whatever state it keeps between moves (implements BotInterface)
+func (bot *RemoteBot) OpponentMove(move string) {
+	bot.fallback.OpponentMove(move)
+}
+
+// Close releases the fallback bot and the server's connections (implements BotInterface)
+func (bot *RemoteBot) Close() {
+	bot.fallback.Close()
+	bot.client.CloseIdleConnections()
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"tic-tac-toe-3d-bots/engine"
+	"tic-tac-toe-3d-bots/formats"
+)
+
+// REPLAY_DELAY is the time between moves when a replay plays itself and no delay is given
+const REPLAY_DELAY = time.Second
+
+// replayGame steps through a saved game move by move
+func replayGame(path string) error {
+	record, err := formats.LoadGameRecord(path)
+	if err != nil {
+		return err
+	}
+	return replayRecord(record, path)
+}
+
+// replayRecord steps through the moves of record, which was read from source
+// Enter shows the next move, 'back' the previous one, 'auto [delay]' plays the rest by itself
+// (Enter pauses it again) and 'quit' leaves the viewer
+func replayRecord(record *formats.GameRecord, source string) error {
+	if _, err := record.Replay(len(record.Moves)); err != nil {
+		return fmt.Errorf("%s: %v", source, err)
+	}
+
+	// Read commands in the background so autoplay can be paused while it waits between moves
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+
+	fmt.Print(msg("replay.title", source, record.Players[0], record.Players[1], len(record.Moves)))
+	fmt.Println(msg("replay.help"))
+
+	position := 0
+	show := func() {
+		board, _ := record.Replay(position) // validated above
+		printBoard(board)
+		if position > 0 {
+			symbol := engine.OpponentSymbol(board.NextPlayer())
+			fmt.Print(msg("replay.move", position, len(record.Moves), record.Players[formats.SymbolIndex(symbol)], symbol, record.Moves[position-1]))
+		} else {
+			fmt.Print(msg("replay.start", len(record.Moves)))
+		}
+	}
+	show()
+
+	autoPlay := false
+	delay := REPLAY_DELAY
+	for {
+		if autoPlay {
+			select {
+			case _, open := <-lines:
+				if !open {
+					return nil
+				}
+				autoPlay = false
+				fmt.Println(msg("replay.paused"))
+			case <-time.After(delay):
+				position++
+				show()
+				if position == len(record.Moves) {
+					autoPlay = false
+					fmt.Println(msg("replay.end"))
+				}
+			}
+			continue
+		}
+
+		fmt.Print(msg("replay.prompt"))
+		line, open := <-lines
+		if !open {
+			return nil
+		}
+
+		fields := strings.Fields(strings.ToLower(line))
+		command := ""
+		if len(fields) > 0 {
+			command = fields[0]
+		}
+
+		switch command {
+		case "", "n", "next":
+			if position == len(record.Moves) {
+				fmt.Println(msg("replay.end"))
+				continue
+			}
+			position++
+			show()
+
+		case "b", "back":
+			if position > 0 {
+				position--
+			}
+			show()
+
+		case "a", "auto":
+			if len(fields) > 1 {
+				parsed, err := time.ParseDuration(fields[1])
+				if err != nil || parsed < 0 {
+					fmt.Print(msg("replay.bad_delay", fields[1]))
+					continue
+				}
+				delay = parsed
+			}
+			if position == len(record.Moves) {
+				fmt.Println(msg("replay.end"))
+				continue
+			}
+			autoPlay = true
+			fmt.Print(msg("replay.autoplay", delay))
+
+		case "q", "quit":
+			return nil
+
+		default:
+			fmt.Println(msg("replay.help"))
+		}
+	}
+}
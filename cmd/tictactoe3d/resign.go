@@ -0,0 +1,58 @@
+package main
+
+import (
+	"tic-tac-toe-3d-bots/bots"
+	"tic-tac-toe-3d-bots/engine"
+	"tic-tac-toe-3d-bots/formats"
+)
+
+// DEFAULT_RESIGN_MOVES is how many moves in a row a bot must find its position lost before resigning, unless
+// --resign-moves says otherwise
+const DEFAULT_RESIGN_MOVES = 3
+
+// JUDGE_DEPTH is the depth of the search that scores the positions of bots reporting no score of their own
+const JUDGE_DEPTH = 2
+
+// botEvaluation returns bot's evaluation ('x' perspective) of board, on which it has just moved: the score its own
+// search gave the move, or for a bot that reports none, the score of a shallow search of the position
+func botEvaluation(bot bots.BotInterface, board *engine.Board) int {
+	if score, ok := bots.ScoreOf(bot); ok {
+		return score
+	}
+	return evaluatePosition(board, engine.OpponentSymbol(bot.Symbol()), JUDGE_DEPTH)
+}
+
+// resignWatch decides when the bots of an EvE game resign: when a bot has scored its position at least
+// settings.ResignScore below even after settings.ResignMoves of its moves in a row. Long hopeless endings otherwise
+// take up most of the time of automated matches
+type resignWatch struct {
+	score, moves int
+	hopeless     [2]int // moves in a row each side, 'x' then 'o', has found its position lost after
+}
+
+// newResignWatch returns the watch for a game run with settings; bots never resign without a resign score
+func newResignWatch(settings formats.EvESettings) *resignWatch {
+	return &resignWatch{score: settings.ResignScore, moves: max(settings.ResignMoves, 1)}
+}
+
+// enabled reports whether the bots may resign at all
+func (watch *resignWatch) enabled() bool {
+	return watch.score > 0
+}
+
+// resigns reports whether symbol resigns, having just given score ('x' perspective) to the position it left
+func (watch *resignWatch) resigns(symbol byte, score int) bool {
+	if !watch.enabled() {
+		return false
+	}
+	if symbol == 'o' {
+		score = -score
+	}
+	side := formats.SymbolIndex(symbol)
+	if score > -watch.score {
+		watch.hopeless[side] = 0
+		return false
+	}
+	watch.hopeless[side]++
+	return watch.hopeless[side] >= watch.moves
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"tic-tac-toe-3d-bots/bots"
+	"tic-tac-toe-3d-bots/formats"
+)
+
+// SAVE_FILE is where the save command writes when no file is given
+const SAVE_FILE = "savegame.json"
+
+// resumedGame is the saved game being resumed, if any
+// The next game started in its mode continues its move history and clocks instead of starting empty
+var resumedGame *formats.GameRecord
+
+// recordBot recreates the bot playing symbol in record with its saved parameters
+func recordBot(record *formats.GameRecord, symbol byte) (bots.BotInterface, error) {
+	config := record.Bots[formats.SymbolIndex(symbol)]
+	if config == nil {
+		return nil, fmt.Errorf("the saved game has no bot configuration for '%c'", symbol)
+	}
+
+	bot, err := newBotFromSpec(config.Spec(), symbol, config.Name)
+	if err != nil {
+		return nil, err
+	}
+	if player, ok := bot.(playerBot); ok {
+		player.SetPlayer(record.Player)
+	}
+	return bot, nil
+}
+
+// resumeGame loads a game saved with the save command (or on Ctrl+C) and plays it on from where it stopped
+func resumeGame(path string) error {
+	record, err := formats.LoadGameRecord(path)
+	if err != nil {
+		return err
+	}
+	board, err := record.Replay(len(record.Moves))
+	if err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	if board.CheckWin() != '|' || board.IsFull() {
+		return fmt.Errorf("%s: the saved game is already over", path)
+	}
+
+	resumedGame = record
+	defer func() { resumedGame = nil }()
+	fmt.Print(msg("save.resuming", path, len(record.Moves)))
+
+	switch record.Mode {
+	case "pvp":
+		playPvP(board, record.Players)
+
+	case "mirror":
+		playMirror(board, record.Players)
+
+	case "pve":
+		if record.Player != "" {
+			currentProfile = record.Player
+		}
+		botSymbol := byte('o')
+		if record.Bots[0] != nil {
+			botSymbol = 'x'
+		}
+		bot, err := recordBot(record, botSymbol)
+		if err != nil {
+			return err
+		}
+		settings := formats.PvESettings{}
+		if record.PvE != nil {
+			settings = *record.PvE
+		}
+		playPvE(board, bot, settings)
+
+	case "eve", "evestream":
+		bot1, err := recordBot(record, 'x')
+		if err != nil {
+			return err
+		}
+		bot2, err := recordBot(record, 'o')
+		if err != nil {
+			bot1.Close()
+			return err
+		}
+
+		if record.Mode == "eve" {
+			settings := formats.EvESettings{}
+			if record.EvE != nil {
+				settings = *record.EvE
+			}
+			playEvE(board, bot1, bot2, settings)
+			return nil
+		}
+
+		botX, okX := bot1.(*bots.PersistentMinimaxBot)
+		botO, okO := bot2.(*bots.PersistentMinimaxBot)
+		if !okX || !okO {
+			bot1.Close()
+			bot2.Close()
+			return fmt.Errorf("%s: evestream games need two persistent bots", path)
+		}
+		playEvEStream(board, botX, botO)
+
+	case "pvestream":
+		depths := record.Depths
+		if len(depths) == 0 {
+			depths = []int{3, 4, 5, 6, 7}
+		}
+		playPvEStream(board, depths)
+
+	default:
+		return fmt.Errorf("%s: unknown mode %q", path, record.Mode)
+	}
+
+	return nil
+}
+
+// saveCommand handles "save [file]" typed in place of a move, saving the game to file (SAVE_FILE by default)
+// Returns false if command is not a save command
+func saveCommand(session *GameSession, command, file string) bool {
+	if !strings.EqualFold(command, "save") {
+		return false
+	}
+	if file == "" {
+		file = SAVE_FILE
+	}
+
+	if err := session.Record().Save(file); err != nil {
+		fmt.Println(msg("save.error"), err)
+	} else {
+		fmt.Print(msg("save.saved", file))
+		fmt.Print(msg("save.resume_hint", file))
+	}
+	return true
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"tic-tac-toe-3d-bots/engine"
+)
+
+// SEARCH_CACHE_MIN_DEPTH is the shallowest analysis kept in the search cache; shallower ones are quicker to redo
+// than to look up, and would only bloat the file
+const SEARCH_CACHE_MIN_DEPTH = 4
+
+// CachedSearch is the deepest analysis of a position the search cache knows of
+type CachedSearch struct {
+	Line  []string `json:"line"`  // principal variation, best move first
+	Score int      `json:"score"` // score from 'x' perspective
+	Depth int      `json:"depth"`
+}
+
+// Covers reports whether the cached analysis answers a search to depth: it went at least as deep, or found a forced
+// result, which searching deeper cannot change. A shallower one only saves the search its first iterations
+func (cached CachedSearch) Covers(depth int) bool {
+	return cached.Depth >= depth || cached.Score >= engine.MAX_INT/2 || cached.Score <= engine.MIN_INT/2
+}
+
+// SearchCache remembers the results of deep analyses across runs, set with --search-cache, so analysing the same
+// openings again starts where the last session stopped. Positions are keyed by their snapshot, and scores are only
+// valid under the evaluator they were searched with: entries of another one are dropped when the file is loaded
+type SearchCache struct {
+	path  string
+	mutex sync.Mutex
+	dirty bool // entries changed since the file was last written
+
+	Evaluator engine.Evaluator        `json:"evaluator"`
+	Entries   map[string]CachedSearch `json:"entries"`
+}
+
+// searchCache is consulted and filled by analyzeBest when set with --search-cache; nil disables it
+var searchCache *SearchCache
+
+// openSearchCache loads the search cache at path, empty if the file does not exist yet
+func openSearchCache(path string) (*SearchCache, error) {
+	cache := &SearchCache{path: path, Evaluator: engine.DefaultEvaluator(), Entries: make(map[string]CachedSearch)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cache, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var stored SearchCache
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if stored.Evaluator != cache.Evaluator {
+		cache.dirty = len(stored.Entries) > 0 // The evaluation changed since, so every score is stale
+		return cache, nil
+	}
+	for key, entry := range stored.Entries {
+		cache.Entries[key] = entry
+	}
+	return cache, nil
+}
+
+// Lookup returns the cached analysis of the position on board, if any
+func (cache *SearchCache) Lookup(board *engine.Board) (CachedSearch, bool) {
+	if cache == nil || board.Evaluator != cache.Evaluator {
+		return CachedSearch{}, false
+	}
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cached, ok := cache.Entries[board.Snapshot()]
+	return cached, ok
+}
+
+// Store records an analysis of the position on board, unless it is shallower than SEARCH_CACHE_MIN_DEPTH or than the
+// analysis already cached
+func (cache *SearchCache) Store(board *engine.Board, line []string, score, depth int) {
+	if cache == nil || board.Evaluator != cache.Evaluator || len(line) == 0 || depth < SEARCH_CACHE_MIN_DEPTH {
+		return
+	}
+	key := board.Snapshot()
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if cached, ok := cache.Entries[key]; ok && cached.Depth >= depth {
+		return
+	}
+	cache.Entries[key] = CachedSearch{Line: line, Score: score, Depth: depth}
+	cache.dirty = true
+}
+
+// Save writes the cache back to its file if it changed, replacing it atomically
+func (cache *SearchCache) Save() error {
+	if cache == nil {
+		return nil
+	}
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if !cache.dirty {
+		return nil
+	}
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return err
+	}
+	tmpPath := cache.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, cache.path); err != nil {
+		return err
+	}
+	cache.dirty = false
+	return nil
+}
+
+// saveSearchCache writes the search cache back to its file, if set, reporting failures rather than failing the run
+func saveSearchCache() {
+	if err := searchCache.Save(); err != nil {
+		fmt.Fprintln(os.Stderr, msg("searchcache.save_error"), err)
+	}
+}
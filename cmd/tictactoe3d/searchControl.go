@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"tic-tac-toe-3d-bots/bots"
+	"tic-tac-toe-3d-bots/engine"
+)
+
+// SearchProgress is the deepest completed iteration of a controlled search
+type SearchProgress struct {
+	Depth      int
+	Score      int         // score from 'x' perspective
+	Line       []string    // principal variation of the best move
+	Candidates []Candidate // every root move best first, with MultiPV above 1 only
+	Elapsed    time.Duration
+}
+
+// searchOptions configures a controlled search
+type searchOptions struct {
+	MaxDepth int // deepest iteration of an analysis-only search
+	MultiPV  int // above 1, an analysis-only search scores every root move (see analyzeCandidates)
+}
+
+// SearchController runs a bot's search in the background, so callers need not block on MakeMove: they poll Progress
+// or Subscribe to it, wait for Moved, and can demand BestMoveNow at any point. The progress is the bot's own, and only
+// bots that are ProgressReporters make any. The bot searches a copy of the board, so the caller plays the move it
+// settles on. Without a bot the search is an iteratively deepening analysis only, running until it is stopped or
+// reaches its deepest iteration
+type SearchController struct {
+	board    *engine.Board // the position searched, never played on (the search works on a copy)
+	start    time.Time
+	cancel   context.CancelFunc
+	moved    chan struct{} // closed once the bot has moved or given up, at once without a bot
+	iterated chan struct{} // closed once the search has completed its first iteration
+	analysed chan struct{} // closed once the search has ended and every subscription is closed
+	maxDepth int           // deepest iteration the search can report
+
+	mutex       sync.Mutex
+	progress    []SearchProgress // every iteration completed, deepest last
+	move        bots.Move
+	err         error
+	subscribers []chan SearchProgress
+}
+
+// startSearch starts bot (nil for analysis only) searching the position on board
+// The search stops when ctx ends or Stop is called; an analysis-only search goes on until the caller has seen enough
+// of it, the bot's search ends with its move
+func startSearch(ctx context.Context, bot bots.BotInterface, board *engine.Board, options searchOptions) *SearchController {
+	ctx, cancel := context.WithCancel(ctx)
+	control := &SearchController{
+		board:    engine.CopyBoard(board),
+		start:    time.Now(),
+		cancel:   cancel,
+		moved:    make(chan struct{}),
+		iterated: make(chan struct{}),
+		analysed: make(chan struct{}),
+		maxDepth: max(options.MaxDepth, 1),
+	}
+
+	if bot == nil {
+		control.err = bots.ErrNoValidMoves
+		close(control.moved)
+		go control.analyse(options, ctx)
+		return control
+	}
+	reporter, reports := bot.(bots.ProgressReporter)
+	if reports {
+		control.maxDepth = max(control.maxDepth, bots.MAX_SEARCH_DEPTH)
+		reporter.ReportProgress(func(iteration bots.SearchIteration) {
+			control.report(SearchProgress{Depth: iteration.Depth, Score: iteration.Score, Line: iteration.Line})
+		})
+	}
+	botBoard := engine.CopyBoard(board)
+	go func() {
+		defer control.endAnalysis()
+		defer close(control.moved)
+		move, err := searchMove(ctx, bot, botBoard)
+		if reports {
+			reporter.ReportProgress(nil)
+		}
+		control.mutex.Lock()
+		control.move, control.err = move, err
+		control.mutex.Unlock()
+	}()
+	return control
+}
+
+// analyse runs the analysis of an analysis-only search
+func (control *SearchController) analyse(options searchOptions, ctx context.Context) {
+	defer control.endAnalysis()
+	symbol := control.board.NextPlayer()
+	board := engine.CopyBoard(control.board)
+	if options.MultiPV <= 1 {
+		analyzeBestWithProgress(board, symbol, control.maxDepth, func(line []string, score, depth int) {
+			control.report(SearchProgress{Depth: depth, Score: score, Line: line})
+		}, ctx)
+		return
+	}
+	analyzeCandidatesWithProgress(board, symbol, control.maxDepth, func(candidates []Candidate, depth int) {
+		best := candidates[0]
+		control.report(SearchProgress{Depth: depth, Score: best.Score, Line: best.Line, Candidates: candidates})
+	}, ctx)
+}
+
+// endAnalysis closes every subscription and marks the search as ended
+func (control *SearchController) endAnalysis() {
+	control.mutex.Lock()
+	defer control.mutex.Unlock()
+	for _, subscriber := range control.subscribers {
+		close(subscriber)
+	}
+	control.subscribers = nil
+	close(control.analysed)
+}
+
+// report records a completed iteration of the search and passes it on to the subscribers
+func (control *SearchController) report(progress SearchProgress) {
+	progress.Elapsed = time.Since(control.start)
+	control.mutex.Lock()
+	defer control.mutex.Unlock()
+	if len(control.progress) == 0 {
+		close(control.iterated)
+	}
+	control.progress = append(control.progress, progress)
+	for _, subscriber := range control.subscribers {
+		select {
+		case subscriber <- progress: // Each subscription has room for every iteration the search can report
+		default:
+		}
+	}
+}
+
+// Progress returns the deepest iteration the search has completed so far (Depth 0 before the first)
+func (control *SearchController) Progress() SearchProgress {
+	control.mutex.Lock()
+	defer control.mutex.Unlock()
+	if len(control.progress) == 0 {
+		return SearchProgress{}
+	}
+	return control.progress[len(control.progress)-1]
+}
+
+// Subscribe returns a channel receiving every iteration the search completes, those completed so far first; it is
+// closed when the search ends
+func (control *SearchController) Subscribe() <-chan SearchProgress {
+	subscriber := make(chan SearchProgress, control.maxDepth+1)
+	control.mutex.Lock()
+	defer control.mutex.Unlock()
+	for _, progress := range control.progress {
+		subscriber <- progress
+	}
+	select {
+	case <-control.analysed:
+		close(subscriber)
+		return subscriber
+	default:
+	}
+	control.subscribers = append(control.subscribers, subscriber)
+	return subscriber
+}
+
+// Moved returns a channel closed once the bot has moved or given up
+func (control *SearchController) Moved() <-chan struct{} {
+	return control.moved
+}
+
+// Iterated returns a channel closed once the search has completed its first iteration, which it never does if it is
+// stopped early or the bot reports no progress: wait for Analysed as well
+func (control *SearchController) Iterated() <-chan struct{} {
+	return control.iterated
+}
+
+// Analysed returns a channel closed once the search has ended: with the bot's move, or when an analysis-only search
+// is stopped or complete
+func (control *SearchController) Analysed() <-chan struct{} {
+	return control.analysed
+}
+
+// Result returns the bot's move, or why it has none: ErrNoValidMoves without a bot, the context's error if it was
+// stopped first. Only meaningful once Moved is closed
+func (control *SearchController) Result() (bots.Move, error) {
+	control.mutex.Lock()
+	defer control.mutex.Unlock()
+	return control.move, control.err
+}
+
+// BestMoveNow returns the best move known at once, without waiting: the bot's if it has moved, else the first move
+// of the deepest line reported, else the first legal move. It is "" only when the position has no legal moves
+func (control *SearchController) BestMoveNow() string {
+	control.mutex.Lock()
+	defer control.mutex.Unlock()
+	if control.err == nil && control.move.Name != "" {
+		return control.move.Name
+	}
+	for i := len(control.progress) - 1; i >= 0; i-- {
+		if line := control.progress[i].Line; len(line) > 0 {
+			return line[0]
+		}
+	}
+	return bots.FirstMove(control.board.GetValidMoves())
+}
+
+// Stop ends the search, waits for it, and returns the best move it found (see BestMoveNow)
+func (control *SearchController) Stop() string {
+	control.cancel()
+	<-control.moved
+	<-control.analysed
+	return control.BestMoveNow()
+}
@@ -0,0 +1,669 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"tic-tac-toe-3d-bots/bots"
+	"tic-tac-toe-3d-bots/engine"
+	"tic-tac-toe-3d-bots/formats"
+)
+
+// DEFAULT_API_ADDRESS is where the serve command listens unless --listen says otherwise
+// The API has no authentication, so by default only local clients can reach it
+const DEFAULT_API_ADDRESS = "localhost:8080"
+
+// API_MAX_BODY is the largest request body the API reads
+const API_MAX_BODY = 1 << 20
+
+// API_DEFAULT_TIME_LIMIT bounds bot moves and analysis requested without a time limit
+const API_DEFAULT_TIME_LIMIT = 2 * time.Second
+
+// API_MAX_TIME_LIMIT is the longest time limit a request may ask for
+const API_MAX_TIME_LIMIT = time.Minute
+
+// The serve command exposes games, bots and analysis over HTTP; every body is JSON
+//
+//	POST   /games                      create a game: {"board": {...}} (default 3x3x3, win 3), optionally {"moves": [...]} or {"snapshot": "..."}
+//	GET    /games                      list the games
+//	GET    /games/{id}                 the game's state
+//	DELETE /games/{id}                 forget the game
+//	GET    /games/{id}/moves           the legal moves
+//	POST   /games/{id}/moves           play a move: {"move": "A1"}
+//	POST   /games/{id}/bot-move        let a bot play the next move: {"bot": "alphabeta:depth=4", "time_limit_ms": 1000}
+//	POST   /analysis                   analyse a position: {"board", "moves" or "snapshot", "depth", "time_limit_ms", "candidates"}
+//	POST   /bot-move                   a bot's move for a position, without creating a game: {"board", "moves" or "snapshot", "bot", "time_limit_ms"}
+//	GET    /games/{id}/live            WebSocket streaming the game's states and the bot's thinking (see APIEvent)
+//	GET    /analysis/live              WebSocket analysing each position sent to it: {"board", "moves" or "snapshot", "depths", "time_limit_ms"}
+//	GET    /analysis/stream            Server-Sent Events of an analysis: ?board=4x4x4/4&moves=A1,B2 (or ?snapshot=...)&depth=N&time_limit_ms=MS
+//	POST   /analysis/stream            the same, with the position and limits as a JSON body
+//	POST   /analysis/stream/{id}/stop  stop an analysis stream
+//	GET    /metrics                    metrics in the Prometheus text format (see metrics.go)
+//	GET    /healthz                    health check; GET /readyz fails once the server is shutting down (see health.go)
+//	GET    /                           the web UI: play a bot in a browser (see web/)
+//
+// Bots are picked among those of apiBots, searching no deeper than their limit (see newAPIBot)
+// Errors are answered with {"error": "..."} and a 4xx status
+
+// APIPosition is a position posted to the API: a board and the moves played on it, or a snapshot (see Board.Snapshot)
+type APIPosition struct {
+	Board    engine.BoardConfig `json:"board"`
+	Moves    []string           `json:"moves"`
+	Snapshot string             `json:"snapshot"`
+}
+
+// record resolves the position to a game record, checking that its moves can be played
+func (position APIPosition) record() (*formats.GameRecord, error) {
+	record := &formats.GameRecord{Board: position.Board, Moves: position.Moves}
+	if position.Snapshot != "" {
+		if len(position.Moves) > 0 {
+			return nil, fmt.Errorf("give either moves or a snapshot, not both")
+		}
+		board, moves, err := engine.ParseSnapshot(position.Snapshot)
+		if err != nil {
+			return nil, err
+		}
+		record.Board, record.Moves = board, moves
+	}
+	if record.Board == (engine.BoardConfig{}) {
+		record.Board = engine.BoardConfig{Length: 3, Width: 3, Height: 3, Win: 3}
+	}
+	if record.Board.Win == 0 {
+		record.Board.Win = min(record.Board.Length, record.Board.Width, record.Board.Height)
+	}
+	if err := engine.ValidateBoardDimensions(record.Board.Length, record.Board.Width, record.Board.Height, record.Board.Win); err != nil {
+		return nil, err
+	}
+	for i, move := range record.Moves {
+		record.Moves[i] = strings.ToUpper(strings.TrimSpace(move))
+	}
+	if _, err := record.Replay(len(record.Moves)); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// APIGameState is a game or position as the API returns it
+type APIGameState struct {
+	ID         int                `json:"id,omitempty"`
+	Board      engine.BoardConfig `json:"board"`
+	Moves      []string           `json:"moves"`
+	Next       string             `json:"next"`   // the player to move, "x" or "o"
+	Winner     string             `json:"winner"` // "x", "o", "draw", or "" while the game goes on
+	LegalMoves []string           `json:"legal_moves"`
+	Snapshot   string             `json:"snapshot"`
+}
+
+// apiState describes the position reached by record
+func apiState(id int, record *formats.GameRecord) APIGameState {
+	board, _ := record.Replay(len(record.Moves)) // Every stored record has been replayed once
+	state := APIGameState{ID: id, Board: record.Board, Moves: append([]string{}, record.Moves...), Next: string(board.NextPlayer()),
+		LegalMoves: []string{}, Snapshot: board.Snapshot()}
+	switch {
+	case board.CheckWin() != '|':
+		state.Winner = string(board.CheckWin())
+	case board.IsFull():
+		state.Winner = "draw"
+	default:
+		state.LegalMoves = board.GetValidMoves()
+	}
+	return state
+}
+
+// APIAnalysis is the result of analysing a position for the player to move
+type APIAnalysis struct {
+	Line           []string       `json:"line"`                    // best line, starting with the best move
+	Score          int            `json:"score"`                   // score of the line from 'x' perspective
+	ForcedWinner   string         `json:"forced_winner,omitempty"` // "x" or "o" if the line is a forced win
+	WinProbability float64        `json:"win_probability"`         // estimated chance the player to move wins
+	Playouts       *PlayoutResult `json:"playouts,omitempty"`      // how random games from the position ended, when asked for
+	Depth          int            `json:"depth"`                   // deepest completed search
+	Candidates     []APICandidate `json:"candidates,omitempty"`    // every move, best first, when asked for
+}
+
+// forcedWinner returns "x" or "o" if score ('x' perspective) is a forced win, and "" otherwise
+func forcedWinner(score int) string {
+	switch {
+	case score >= engine.MAX_INT/2:
+		return "x"
+	case score <= engine.MIN_INT/2:
+		return "o"
+	}
+	return ""
+}
+
+// APICandidate is one move of an analysis with its score and line
+type APICandidate struct {
+	Move           string   `json:"move"`
+	Score          int      `json:"score"`
+	WinProbability float64  `json:"win_probability"`
+	Line           []string `json:"line"`
+}
+
+// APIServer holds the games created through the API
+type APIServer struct {
+	mutex    sync.Mutex
+	games    map[int]*formats.GameRecord
+	nextID   int
+	watchers map[int]map[*apiWatcher]bool // spectators of each game
+
+	streams      map[int]context.CancelCauseFunc // analysis streams in progress, stopped with errAnalysisStopped
+	nextStreamID int
+}
+
+// newAPIServer creates a server without games
+func newAPIServer() *APIServer {
+	return &APIServer{games: make(map[int]*formats.GameRecord), nextID: 1, watchers: make(map[int]map[*apiWatcher]bool),
+		streams: make(map[int]context.CancelCauseFunc), nextStreamID: 1}
+}
+
+// apiError is an error answered with a specific HTTP status
+type apiError struct {
+	status int
+	err    error
+}
+
+func (e *apiError) Error() string { return e.err.Error() }
+
+// apiErrorf returns an error answered with status
+func apiErrorf(status int, format string, args ...any) error {
+	return &apiError{status: status, err: fmt.Errorf(format, args...)}
+}
+
+// handle adapts an API handler: its result is written as JSON, and its error as {"error": ...}
+func handle(handler func(request *http.Request) (any, error)) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		result, err := handler(request)
+		switch {
+		case err != nil:
+			writeAPIError(writer, err)
+		case request.Method == http.MethodPost && strings.HasSuffix(request.URL.Path, "/games"):
+			writeJSON(writer, http.StatusCreated, result)
+		default:
+			writeJSON(writer, http.StatusOK, result)
+		}
+	}
+}
+
+// loggedResponse records the status a handler answers with, for logRequests
+// Flushing and hijacking pass through, so event streams and WebSockets work as without it
+type loggedResponse struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader implements http.ResponseWriter
+func (response *loggedResponse) WriteHeader(status int) {
+	if response.status == 0 {
+		response.status = status
+	}
+	response.ResponseWriter.WriteHeader(status)
+}
+
+// Flush implements http.Flusher
+func (response *loggedResponse) Flush() {
+	if flusher, ok := response.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker
+func (response *loggedResponse) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := response.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("connection cannot be hijacked")
+	}
+	response.status = http.StatusSwitchingProtocols
+	return hijacker.Hijack()
+}
+
+// logRequests logs every request once it has been answered: server errors as errors, everything else at info level
+func logRequests(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		started := time.Now()
+		response := &loggedResponse{ResponseWriter: writer}
+		handler.ServeHTTP(response, request)
+
+		status := response.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		serverLog.Log(request.Context(), level, "http request", "method", request.Method, "path", request.URL.Path,
+			"remote", request.RemoteAddr, "status", status, "duration", time.Since(started))
+	})
+}
+
+// limitBodies stops handler from reading more than API_MAX_BODY bytes of any request body
+func limitBodies(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		request.Body = http.MaxBytesReader(writer, request.Body, API_MAX_BODY)
+		handler.ServeHTTP(writer, request)
+	})
+}
+
+// writeJSON answers with status and value as JSON
+func writeJSON(writer http.ResponseWriter, status int, value any) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+	json.NewEncoder(writer).Encode(value)
+}
+
+// writeAPIError answers with {"error": ...}; errors without a status of their own are the client's fault (400)
+func writeAPIError(writer http.ResponseWriter, err error) {
+	status := http.StatusBadRequest
+	var statusErr *apiError
+	if errors.As(err, &statusErr) {
+		status = statusErr.status
+	}
+	writeJSON(writer, status, map[string]string{"error": err.Error()})
+}
+
+// decodeBody reads a request's JSON body into value; an empty body leaves value as it is
+func decodeBody(request *http.Request, value any) error {
+	decoder := json.NewDecoder(request.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(value)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return apiErrorf(http.StatusRequestEntityTooLarge, "request body larger than %d bytes", tooLarge.Limit)
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("invalid request body: %v", err)
+	}
+	return nil
+}
+
+// timeLimit converts a requested time limit in milliseconds, 0 meaning API_DEFAULT_TIME_LIMIT
+func timeLimit(ms int) (time.Duration, error) {
+	limit := time.Duration(ms) * time.Millisecond
+	switch {
+	case ms < 0 || limit > API_MAX_TIME_LIMIT:
+		return 0, fmt.Errorf("time_limit_ms must be between 0 and %d", API_MAX_TIME_LIMIT.Milliseconds())
+	case ms == 0:
+		return API_DEFAULT_TIME_LIMIT, nil
+	}
+	return limit, nil
+}
+
+// game returns the record of the game named by the request's id, locked until unlock is called
+func (server *APIServer) game(request *http.Request) (record *formats.GameRecord, id int, err error) {
+	id, err = strconv.Atoi(request.PathValue("id"))
+	if err != nil {
+		return nil, 0, apiErrorf(http.StatusNotFound, "invalid game id %q", request.PathValue("id"))
+	}
+	record, exists := server.games[id]
+	if !exists {
+		return nil, 0, apiErrorf(http.StatusNotFound, "no game %d", id)
+	}
+	return record, id, nil
+}
+
+// playMove returns a copy of record with move played after its moves
+func playMove(record *formats.GameRecord, move string) (*formats.GameRecord, error) {
+	if apiState(0, record).Winner != "" {
+		return nil, apiErrorf(http.StatusConflict, "the game is over")
+	}
+	played := &formats.GameRecord{Board: record.Board, Moves: append(append([]string{}, record.Moves...), strings.ToUpper(strings.TrimSpace(move)))}
+	if _, err := played.Replay(len(played.Moves)); err != nil {
+		return nil, apiErrorf(http.StatusUnprocessableEntity, "illegal move %q", move)
+	}
+	return played, nil
+}
+
+// apiBots are the bots the API lets its callers pick, with the deepest search each may be asked for: bots that only
+// search, within the request's time limit, and keep nothing once they have moved. Stateful bots, bots running other
+// processes or reaching other servers, and profiles, which may be any of those, are refused
+var apiBots = map[string]int{
+	"alphabeta": 10,
+	"qubic":     10,
+	"minimax":   6,
+	"naive":     4,
+	"limited":   0,
+	"rules":     0,
+	"random":    0,
+}
+
+// API_MAX_TT_SIZE is the largest transposition table, in MiB, an API caller can ask a bot for
+const API_MAX_TT_SIZE = 64
+
+// newAPIBot creates the bot a caller of the API, or of another service open to the network, asks for with spec
+// Only the bots of apiBots are created, searching no deeper than their limit; anything else is an error answered with
+// 400 Bad Request
+func newAPIBot(spec string, symbol byte, name string) (bots.BotInterface, error) {
+	name, params, err := bots.ParseBotSpec(spec)
+	if err != nil {
+		return nil, apiErrorf(http.StatusBadRequest, "%v", err)
+	}
+	maxDepth, allowed := apiBots[name]
+	if _, isProfile := lookupBotProfile(name); isProfile || !allowed {
+		keys := make([]string, 0, len(apiBots))
+		for key := range apiBots {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		return nil, apiErrorf(http.StatusBadRequest, "bot %q is not available here (available: %s)", name, strings.Join(keys, ", "))
+	}
+	limits := map[string]int{"depth": maxDepth, "forcing": maxDepth, "tt": API_MAX_TT_SIZE,
+		"movetime": int(API_MAX_TIME_LIMIT.Milliseconds())}
+	for param, limit := range limits {
+		if value, set := params[param]; set && value > limit {
+			return nil, apiErrorf(http.StatusBadRequest, "parameter %s of bot %q must be at most %d here, got %d", param, name, limit, value)
+		}
+	}
+
+	registration, _ := bots.LookupBot(name)
+	bot, err := registration.Create(symbol, name, params)
+	if err != nil {
+		return nil, apiErrorf(http.StatusBadRequest, "%v", err)
+	}
+	return bot, nil
+}
+
+// botMove asks the bot described by spec for its move in the position of record, within limit
+func botMove(record *formats.GameRecord, spec string, limit time.Duration, ctx context.Context) (string, error) {
+	board, _ := record.Replay(len(record.Moves))
+	if board.CheckWin() != '|' || board.IsFull() {
+		return "", apiErrorf(http.StatusConflict, "the game is over")
+	}
+	if spec == "" {
+		spec = "alphabeta"
+	}
+	bot, err := newAPIBot(spec, board.NextPlayer(), "")
+	if err != nil {
+		return "", err
+	}
+	defer bot.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, limit)
+	defer cancel()
+	move, err := searchMove(ctx, bot, board)
+	if err != nil {
+		return "", apiErrorf(http.StatusServiceUnavailable, "the bot did not move within %s", limit)
+	}
+	movesServed.add(1, "api", "http")
+	return move.Name, nil
+}
+
+// routes registers the API's endpoints
+func (server *APIServer) routes() *http.ServeMux {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /games", handle(func(request *http.Request) (any, error) {
+		position := APIPosition{}
+		if err := decodeBody(request, &position); err != nil {
+			return nil, err
+		}
+		record, err := position.record()
+		if err != nil {
+			return nil, err
+		}
+
+		server.mutex.Lock()
+		defer server.mutex.Unlock()
+		id := server.nextID
+		server.nextID++
+		server.games[id] = record
+		return apiState(id, record), nil
+	}))
+
+	mux.HandleFunc("GET /games", handle(func(request *http.Request) (any, error) {
+		server.mutex.Lock()
+		defer server.mutex.Unlock()
+		states := make([]APIGameState, 0, len(server.games))
+		for id := 1; id < server.nextID; id++ {
+			if record, exists := server.games[id]; exists {
+				states = append(states, apiState(id, record))
+			}
+		}
+		return states, nil
+	}))
+
+	mux.HandleFunc("GET /games/{id}", handle(func(request *http.Request) (any, error) {
+		server.mutex.Lock()
+		defer server.mutex.Unlock()
+		record, id, err := server.game(request)
+		if err != nil {
+			return nil, err
+		}
+		return apiState(id, record), nil
+	}))
+
+	mux.HandleFunc("DELETE /games/{id}", handle(func(request *http.Request) (any, error) {
+		server.mutex.Lock()
+		defer server.mutex.Unlock()
+		record, id, err := server.game(request)
+		if err != nil {
+			return nil, err
+		}
+		delete(server.games, id)
+		server.publish(id, APIEvent{Type: "closed"})
+		return apiState(id, record), nil
+	}))
+
+	mux.HandleFunc("GET /games/{id}/moves", handle(func(request *http.Request) (any, error) {
+		server.mutex.Lock()
+		defer server.mutex.Unlock()
+		record, id, err := server.game(request)
+		if err != nil {
+			return nil, err
+		}
+		return apiState(id, record).LegalMoves, nil
+	}))
+
+	mux.HandleFunc("POST /games/{id}/moves", handle(func(request *http.Request) (any, error) {
+		body := struct {
+			Move string `json:"move"`
+		}{}
+		if err := decodeBody(request, &body); err != nil {
+			return nil, err
+		}
+
+		server.mutex.Lock()
+		defer server.mutex.Unlock()
+		record, id, err := server.game(request)
+		if err != nil {
+			return nil, err
+		}
+		played, err := playMove(record, body.Move)
+		if err != nil {
+			return nil, err
+		}
+		server.games[id] = played
+		server.publish(id, APIEvent{Type: "state", State: apiState(id, played)})
+		return apiState(id, played), nil
+	}))
+
+	mux.HandleFunc("POST /games/{id}/bot-move", handle(func(request *http.Request) (any, error) {
+		body := struct {
+			Bot         string `json:"bot"`
+			TimeLimitMS int    `json:"time_limit_ms"`
+		}{}
+		if err := decodeBody(request, &body); err != nil {
+			return nil, err
+		}
+		limit, err := timeLimit(body.TimeLimitMS)
+		if err != nil {
+			return nil, err
+		}
+
+		// The bot searches a copy, so the game stays available while it thinks
+		server.mutex.Lock()
+		record, id, err := server.game(request)
+		var position formats.GameRecord
+		if err == nil {
+			position = formats.GameRecord{Board: record.Board, Moves: append([]string{}, record.Moves...)}
+		}
+		server.mutex.Unlock()
+		if err != nil {
+			return nil, err
+		}
+		thinking, stopThinking := context.WithCancel(request.Context())
+		go server.streamThinking(id, &position, thinking)
+		move, err := botMove(&position, body.Bot, limit, request.Context())
+		stopThinking()
+		if err != nil {
+			return nil, err
+		}
+
+		server.mutex.Lock()
+		defer server.mutex.Unlock()
+		if current, exists := server.games[id]; !exists || len(current.Moves) != len(position.Moves) {
+			return nil, apiErrorf(http.StatusConflict, "the game changed while the bot was thinking")
+		}
+		position.Moves = append(position.Moves, move)
+		server.games[id] = &position
+		server.publish(id, APIEvent{Type: "state", State: apiState(id, &position)})
+		return apiState(id, &position), nil
+	}))
+
+	mux.HandleFunc("POST /bot-move", handle(func(request *http.Request) (any, error) {
+		body := struct {
+			APIPosition
+			Bot         string `json:"bot"`
+			TimeLimitMS int    `json:"time_limit_ms"`
+		}{}
+		if err := decodeBody(request, &body); err != nil {
+			return nil, err
+		}
+		limit, err := timeLimit(body.TimeLimitMS)
+		if err != nil {
+			return nil, err
+		}
+		record, err := body.APIPosition.record()
+		if err != nil {
+			return nil, err
+		}
+		move, err := botMove(record, body.Bot, limit, request.Context())
+		if err != nil {
+			return nil, err
+		}
+		return map[string]string{"move": move}, nil
+	}))
+
+	mux.HandleFunc("POST /analysis", handle(func(request *http.Request) (any, error) {
+		body := struct {
+			APIPosition
+			Depth       int  `json:"depth"`
+			TimeLimitMS int  `json:"time_limit_ms"`
+			Candidates  bool `json:"candidates"`
+			Playouts    int  `json:"playouts"`
+		}{}
+		if err := decodeBody(request, &body); err != nil {
+			return nil, err
+		}
+		limit, err := timeLimit(body.TimeLimitMS)
+		if err != nil {
+			return nil, err
+		}
+		if body.Depth < 0 {
+			return nil, fmt.Errorf("depth must not be negative")
+		}
+		if body.Depth == 0 {
+			body.Depth = ENGINE_MAX_DEPTH
+		}
+		if body.Playouts < 0 || body.Playouts > MAX_PLAYOUTS {
+			return nil, fmt.Errorf("playouts must be between 0 and %d", MAX_PLAYOUTS)
+		}
+		record, err := body.APIPosition.record()
+		if err != nil {
+			return nil, err
+		}
+		board, _ := record.Replay(len(record.Moves))
+		if board.CheckWin() != '|' || board.IsFull() {
+			return nil, apiErrorf(http.StatusConflict, "the game is over")
+		}
+
+		// Both searches share the one deadline; the best line leaves the candidates half of it, and whatever it does not use
+		ctx, cancel := context.WithTimeout(request.Context(), limit)
+		defer cancel()
+		bestCtx := ctx
+		if body.Candidates {
+			var cancelBest context.CancelFunc
+			bestCtx, cancelBest = context.WithTimeout(ctx, limit/2)
+			defer cancelBest()
+		}
+		symbol := board.NextPlayer()
+		analysis := APIAnalysis{}
+		analysis.Line, analysis.Score, analysis.Depth = analyzeBest(board, symbol, body.Depth, bestCtx)
+		analysis.WinProbability = winProbability(analysis.Score, symbol)
+		analysis.ForcedWinner = forcedWinner(analysis.Score)
+		if body.Playouts > 0 {
+			playouts := playOut(board, body.Playouts)
+			analysis.Playouts = &playouts
+		}
+
+		if body.Candidates {
+			candidates, _ := analyzeCandidates(board, symbol, body.Depth, ctx)
+			for _, candidate := range candidates {
+				analysis.Candidates = append(analysis.Candidates, APICandidate{Move: candidate.Move, Score: candidate.Score,
+					WinProbability: winProbability(candidate.Score, symbol), Line: candidate.Line})
+			}
+		}
+		return analysis, nil
+	}))
+
+	mux.HandleFunc("GET /games/{id}/live", server.watchGame)
+	mux.HandleFunc("GET /analysis/live", server.streamAnalysis)
+	mux.HandleFunc("GET /analysis/stream", server.streamAnalysisEvents)
+	mux.HandleFunc("POST /analysis/stream", server.streamAnalysisEvents)
+	mux.HandleFunc("POST /analysis/stream/{id}/stop", handle(server.stopAnalysisStream))
+	mux.HandleFunc("GET /metrics", metricsHandler)
+	mux.HandleFunc("GET /healthz", healthHandler)
+	mux.HandleFunc("GET /readyz", readyHandler)
+	mux.Handle("GET /", webUI())
+	return mux
+}
+
+// runServe implements the serve command: it answers the HTTP API until the program is stopped
+func runServe(args []string, output io.Writer) error {
+	var listen, lang string
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.StringVar(&listen, "listen", DEFAULT_API_ADDRESS, "address to serve the HTTP API and the web UI on")
+	fs.StringVar(&lang, "lang", "", "language for messages: "+strings.Join(availableLocales(), ", ")+" (default from TTT_LANG or LANG)")
+	logging := addLogFlags(fs)
+	traceEndpoint := addTraceFlag(fs)
+	addOriginFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+
+	if lang == "" {
+		lang = localeFromEnvironment()
+	}
+	if err := setLocale(lang); err != nil {
+		return err
+	}
+	closeLog, err := logging.apply()
+	if err != nil {
+		return err
+	}
+	defer closeLog()
+	stopTracing, err := startTracing(*traceEndpoint)
+	if err != nil {
+		return err
+	}
+	defer stopTracing()
+	fmt.Fprint(output, msg("serve.listening", listen))
+	return serveGracefully(&http.Server{Addr: listen, Handler: logRequests(limitBodies(newAPIServer().routes()))}, output)
+}
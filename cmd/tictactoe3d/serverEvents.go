@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"tic-tac-toe-3d-bots/engine"
+)
+
+// errAnalysisStopped ends an analysis stream stopped through the API
+var errAnalysisStopped = errors.New("analysis stopped")
+
+// Analysis streams send Server-Sent Events, for clients that cannot speak WebSocket (e.g. a browser's EventSource):
+//
+//	event: start  {"id": N}, the id to stop the stream with POST /analysis/stream/{id}/stop
+//	event: info   an APIStreamInfo after every depth of the iteratively deepening search
+//	event: done   an APIStreamDone once the search is complete, stopped, or out of time
+//
+// Nothing more is sent to a client that disconnects; its search just ends
+
+// APIStreamInfo is the search's best line after a completed depth
+type APIStreamInfo struct {
+	Depth          int      `json:"depth"`
+	Score          int      `json:"score"` // score from 'x' perspective
+	ForcedWinner   string   `json:"forced_winner,omitempty"`
+	WinProbability float64  `json:"win_probability"` // estimated chance the player to move wins
+	PV             []string `json:"pv"`
+}
+
+// APIStreamDone ends an analysis stream with the deepest line found
+type APIStreamDone struct {
+	Reason   string `json:"reason"` // "complete", "stopped" or "timeout"
+	BestMove string `json:"bestmove"`
+	APIStreamInfo
+}
+
+// streamInfo describes a line found for symbol
+func streamInfo(line []string, score, depth int, symbol byte) APIStreamInfo {
+	return APIStreamInfo{Depth: depth, Score: score, ForcedWinner: forcedWinner(score), WinProbability: winProbability(score, symbol), PV: line}
+}
+
+// writeEvent sends one Server-Sent Event with value as JSON data
+func writeEvent(writer http.ResponseWriter, event string, value any) {
+	data, _ := json.Marshal(value)
+	fmt.Fprintf(writer, "event: %s\ndata: %s\n\n", event, data)
+	writer.(http.Flusher).Flush()
+}
+
+// parseStreamRequest reads the position and limits of an analysis stream: from the query of a GET
+// (?board=4x4x4/4&moves=A1,B2 or ?snapshot=..., &depth=N, &time_limit_ms=MS) or from the JSON body of a POST
+// Without a time limit the search runs until its depth is complete or it is stopped
+func parseStreamRequest(request *http.Request) (APIPosition, int, time.Duration, error) {
+	body := struct {
+		APIPosition
+		Depth       int `json:"depth"`
+		TimeLimitMS int `json:"time_limit_ms"`
+	}{}
+	if request.Method == http.MethodPost {
+		if err := decodeBody(request, &body); err != nil {
+			return APIPosition{}, 0, 0, err
+		}
+	} else {
+		query := request.URL.Query()
+		if value := query.Get("board"); value != "" {
+			board, err := engine.ParseBoardConfig(value)
+			if err != nil {
+				return APIPosition{}, 0, 0, err
+			}
+			body.Board = *board
+		}
+		body.Moves = strings.FieldsFunc(query.Get("moves"), func(r rune) bool { return r == ',' || r == ' ' })
+		body.Snapshot = query.Get("snapshot")
+		for name, field := range map[string]*int{"depth": &body.Depth, "time_limit_ms": &body.TimeLimitMS} {
+			if value := query.Get(name); value != "" {
+				number, err := strconv.Atoi(value)
+				if err != nil {
+					return APIPosition{}, 0, 0, fmt.Errorf("invalid %s %q", name, value)
+				}
+				*field = number
+			}
+		}
+	}
+
+	if body.Depth < 0 || body.Depth > ENGINE_MAX_DEPTH {
+		return APIPosition{}, 0, 0, fmt.Errorf("depth must be between 1 and %d", ENGINE_MAX_DEPTH)
+	}
+	if body.Depth == 0 {
+		body.Depth = ENGINE_MAX_DEPTH
+	}
+	limit := time.Duration(body.TimeLimitMS) * time.Millisecond
+	if body.TimeLimitMS < 0 || limit > API_MAX_TIME_LIMIT {
+		return APIPosition{}, 0, 0, fmt.Errorf("time_limit_ms must be between 0 and %d", API_MAX_TIME_LIMIT.Milliseconds())
+	}
+	return body.APIPosition, body.Depth, limit, nil
+}
+
+// streamAnalysisEvents serves GET and POST /analysis/stream: an iteratively deepening analysis sent as Server-Sent Events
+func (server *APIServer) streamAnalysisEvents(writer http.ResponseWriter, request *http.Request) {
+	position, depth, limit, err := parseStreamRequest(request)
+	if err != nil {
+		writeAPIError(writer, err)
+		return
+	}
+	record, err := position.record()
+	if err != nil {
+		writeAPIError(writer, err)
+		return
+	}
+	board, _ := record.Replay(len(record.Moves))
+	if board.CheckWin() != '|' || board.IsFull() {
+		writeAPIError(writer, apiErrorf(http.StatusConflict, "the game is over"))
+		return
+	}
+	if _, ok := writer.(http.Flusher); !ok {
+		writeAPIError(writer, apiErrorf(http.StatusInternalServerError, "streaming is not supported"))
+		return
+	}
+
+	parent := request.Context()
+	if limit > 0 {
+		var cancelLimit context.CancelFunc
+		parent, cancelLimit = context.WithTimeout(parent, limit)
+		defer cancelLimit()
+	}
+	ctx, cancel := context.WithCancelCause(parent)
+	defer cancel(nil)
+
+	server.mutex.Lock()
+	id := server.nextStreamID
+	server.nextStreamID++
+	server.streams[id] = cancel
+	server.mutex.Unlock()
+	defer func() {
+		server.mutex.Lock()
+		delete(server.streams, id)
+		server.mutex.Unlock()
+	}()
+
+	writer.Header().Set("Content-Type", "text/event-stream")
+	writer.Header().Set("Cache-Control", "no-cache")
+	writer.WriteHeader(http.StatusOK)
+	writeEvent(writer, "start", map[string]int{"id": id})
+
+	symbol := board.NextPlayer()
+	line, score, reached := analyzeBestWithProgress(board, symbol, depth, func(line []string, score, depth int) {
+		writeEvent(writer, "info", streamInfo(line, score, depth, symbol))
+	}, ctx)
+
+	done := APIStreamDone{Reason: "complete", APIStreamInfo: streamInfo(line, score, reached, symbol)}
+	switch cause := context.Cause(ctx); {
+	case request.Context().Err() != nil:
+		return // The client is gone
+	case errors.Is(cause, errAnalysisStopped):
+		done.Reason = "stopped"
+	case errors.Is(cause, context.DeadlineExceeded):
+		done.Reason = "timeout"
+	}
+	if len(line) > 0 {
+		done.BestMove = line[0]
+	}
+	writeEvent(writer, "done", done)
+}
+
+// stopAnalysisStream serves POST /analysis/stream/{id}/stop, ending a stream with its done event
+func (server *APIServer) stopAnalysisStream(request *http.Request) (any, error) {
+	id, err := strconv.Atoi(request.PathValue("id"))
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	cancel, exists := server.streams[id]
+	if err != nil || !exists {
+		return nil, apiErrorf(http.StatusNotFound, "no analysis stream %q", request.PathValue("id"))
+	}
+	cancel(errAnalysisStopped)
+	return map[string]any{"id": id, "stopped": true}, nil
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"tic-tac-toe-3d-bots/bots"
+	"tic-tac-toe-3d-bots/engine"
+	"tic-tac-toe-3d-bots/formats"
+)
+
+// APIStreamDepths are the depths analysed while a bot thinks, and by live analysis unless a client asks for others
+var APIStreamDepths = []int{3, 4, 5, 6, 7}
+
+// APIStreamThrottle coalesces streamed analysis so web clients are not flooded
+var APIStreamThrottle = bots.StreamThrottle{MinInterval: 200 * time.Millisecond, MinScoreDelta: 10}
+
+// API_WATCHER_BUFFER is how many events a spectator may fall behind before it is disconnected
+const API_WATCHER_BUFFER = 64
+
+// APIEvent is one message sent over the API's WebSockets
+//
+//	state     the game after a move; also the first message to a spectator
+//	thinking  the best line so far of the bot deciding the game's next move
+//	analysis  the best line so far of a live analysis
+//	closed    the game was deleted; the WebSocket closes after it
+//	error     a message sent to the WebSocket could not be used
+type APIEvent struct {
+	Type   string           `json:"type"`
+	State  APIGameState     `json:"state,omitzero"`
+	Search *APISearchUpdate `json:"search,omitempty"`
+	Error  string           `json:"error,omitempty"`
+}
+
+// APISearchUpdate is a MultiDepthStreamResult as the API streams it
+type APISearchUpdate struct {
+	Line           []string `json:"line"`
+	Score          int      `json:"score"` // score from 'x' perspective
+	ForcedWinner   string   `json:"forced_winner,omitempty"`
+	WinProbability float64  `json:"win_probability"` // estimated chance the player to move wins
+	Depth          int      `json:"depth"`
+	Final          bool     `json:"final"` // the search is over
+}
+
+// searchUpdate converts a streamed result of a search for symbol
+func searchUpdate(result bots.MultiDepthStreamResult, symbol byte) *APISearchUpdate {
+	return &APISearchUpdate{Line: result.Moves, Score: result.Score, ForcedWinner: forcedWinner(result.Score),
+		WinProbability: winProbability(result.Score, symbol), Depth: result.Depth, Final: result.Final}
+}
+
+// apiWatcher is a spectator of a game, fed events by publish
+type apiWatcher struct {
+	events chan APIEvent
+}
+
+// publish sends event to every spectator of game id; the caller holds server.mutex
+// Spectators too slow to keep up are disconnected rather than allowed to block the game
+func (server *APIServer) publish(id int, event APIEvent) {
+	for watcher := range server.watchers[id] {
+		select {
+		case watcher.events <- event:
+			if event.Type == "closed" {
+				server.unwatch(id, watcher)
+			}
+		default:
+			server.unwatch(id, watcher)
+		}
+	}
+}
+
+// unwatch stops feeding watcher, if it still is; the caller holds server.mutex
+func (server *APIServer) unwatch(id int, watcher *apiWatcher) {
+	if !server.watchers[id][watcher] {
+		return
+	}
+	delete(server.watchers[id], watcher)
+	close(watcher.events)
+	if len(server.watchers[id]) == 0 {
+		delete(server.watchers, id)
+	}
+}
+
+// watchGame serves GET /games/{id}/live: a WebSocket sending the game's state, then every move and the bot's thinking
+// Messages from the spectator are ignored
+func (server *APIServer) watchGame(writer http.ResponseWriter, request *http.Request) {
+	server.mutex.Lock()
+	_, id, err := server.game(request)
+	server.mutex.Unlock()
+	if err != nil {
+		writeAPIError(writer, err)
+		return
+	}
+	socket, err := upgradeWebSocket(writer, request)
+	if err != nil {
+		return
+	}
+	defer socket.Close()
+
+	watcher := &apiWatcher{events: make(chan APIEvent, API_WATCHER_BUFFER)}
+	server.mutex.Lock()
+	record, exists := server.games[id]
+	if !exists {
+		server.mutex.Unlock()
+		socket.WriteJSON(APIEvent{Type: "closed"})
+		return
+	}
+	if server.watchers[id] == nil {
+		server.watchers[id] = make(map[*apiWatcher]bool)
+	}
+	server.watchers[id][watcher] = true
+	watcher.events <- APIEvent{Type: "state", State: apiState(id, record)}
+	server.mutex.Unlock()
+
+	stop := func() {
+		server.mutex.Lock()
+		server.unwatch(id, watcher)
+		server.mutex.Unlock()
+	}
+	go func() {
+		for {
+			if _, err := socket.ReadMessage(); err != nil {
+				stop()
+				return
+			}
+		}
+	}()
+	for event := range watcher.events {
+		if err := socket.WriteJSON(event); err != nil {
+			stop()
+		}
+	}
+}
+
+// streamThinking publishes to the spectators of game id a multi-depth analysis of position, until ctx ends
+// Nothing is analysed for a game nobody watches
+func (server *APIServer) streamThinking(id int, position *formats.GameRecord, ctx context.Context) {
+	server.mutex.Lock()
+	watched := len(server.watchers[id]) > 0
+	server.mutex.Unlock()
+	board, _ := position.Replay(len(position.Moves))
+	if !watched || board.CheckWin() != '|' || board.IsFull() {
+		return
+	}
+
+	symbol := board.NextPlayer()
+	buffering := bots.StreamBuffering{Mode: bots.BufferLatestWins}
+	for result := range bots.MultiDepthAlphaBetaStream(board, symbol == 'x', APIStreamDepths, APIStreamThrottle, buffering, ctx) {
+		// Checked under the lock, so no thinking follows the state the bot's move publishes
+		server.mutex.Lock()
+		if ctx.Err() == nil {
+			server.publish(id, APIEvent{Type: "thinking", Search: searchUpdate(result, symbol)})
+		}
+		server.mutex.Unlock()
+	}
+}
+
+// streamAnalysis serves GET /analysis/live: a WebSocket analysing each position sent to it, streaming the best line
+// as it improves; a new position replaces the one being analysed. When the time limit ends the search, the best line
+// so far is sent once more as final
+func (server *APIServer) streamAnalysis(writer http.ResponseWriter, request *http.Request) {
+	socket, err := upgradeWebSocket(writer, request)
+	if err != nil {
+		return
+	}
+	defer socket.Close()
+
+	cancel := context.CancelFunc(func() {})
+	var running sync.WaitGroup
+	defer func() {
+		cancel()
+		running.Wait()
+	}()
+
+	for {
+		data, err := socket.ReadMessage()
+		if err != nil {
+			return
+		}
+		board, depths, limit, err := parseAnalysisRequest(data)
+		if err != nil {
+			socket.WriteJSON(APIEvent{Type: "error", Error: err.Error()})
+			continue
+		}
+
+		cancel()
+		running.Wait()
+		ctx, cancelSearch := context.WithTimeout(context.Background(), limit)
+		cancel = cancelSearch
+		running.Add(1)
+		go func() {
+			defer running.Done()
+			symbol := board.NextPlayer()
+			var last bots.MultiDepthStreamResult
+			for result := range bots.MultiDepthAlphaBetaStream(board, symbol == 'x', depths, APIStreamThrottle, bots.DefaultStreamBuffering, ctx) {
+				last = result
+				socket.WriteJSON(APIEvent{Type: "analysis", Search: searchUpdate(result, symbol)})
+			}
+			if !last.Final && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				last.Final = true
+				socket.WriteJSON(APIEvent{Type: "analysis", Search: searchUpdate(last, symbol)})
+			}
+		}()
+	}
+}
+
+// parseAnalysisRequest reads a position sent to live analysis, with its depths and time limit
+func parseAnalysisRequest(data []byte) (*engine.Board, []int, time.Duration, error) {
+	body := struct {
+		APIPosition
+		Depths      []int `json:"depths"`
+		TimeLimitMS int   `json:"time_limit_ms"`
+	}{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&body); err != nil {
+		return nil, nil, 0, fmt.Errorf("invalid message: %v", err)
+	}
+	limit, err := timeLimit(body.TimeLimitMS)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if len(body.Depths) == 0 {
+		body.Depths = APIStreamDepths
+	}
+	for _, depth := range body.Depths {
+		if depth < 1 || depth > ENGINE_MAX_DEPTH {
+			return nil, nil, 0, fmt.Errorf("depths must be between 1 and %d", ENGINE_MAX_DEPTH)
+		}
+	}
+
+	record, err := body.APIPosition.record()
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	board, _ := record.Replay(len(record.Moves))
+	if board.CheckWin() != '|' || board.IsFull() {
+		return nil, nil, 0, fmt.Errorf("the game is over")
+	}
+	return board, body.Depths, limit, nil
+}
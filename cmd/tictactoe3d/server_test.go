@@ -0,0 +1,41 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAPIBotMoveAllowlist(t *testing.T) {
+	server := httptest.NewServer(newAPIServer().routes())
+	defer server.Close()
+
+	tests := []struct {
+		bot    string
+		status int
+	}{
+		{"", http.StatusOK},
+		{"alphabeta:depth=4", http.StatusOK},
+		{"random", http.StatusOK},
+		{"alphabeta:depth=11", http.StatusBadRequest},
+		{"alphabeta:tt=4096", http.StatusBadRequest},
+		{"alphabeta:movetime=3600000", http.StatusBadRequest},
+		{"minimax:depth=7", http.StatusBadRequest},
+		{"adaptive", http.StatusBadRequest},
+		{"qlearning", http.StatusBadRequest},
+		{"concurrent", http.StatusBadRequest},
+		{"nosuchbot", http.StatusBadRequest},
+	}
+	for _, test := range tests {
+		body := `{"board": {"length": 3, "width": 3, "height": 3, "win": 3}, "moves": ["A1"], "bot": "` + test.bot + `", "time_limit_ms": 2000}`
+		response, err := http.Post(server.URL+"/bot-move", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		response.Body.Close()
+		if response.StatusCode != test.status {
+			t.Errorf("POST /bot-move with bot %q: status %d, want %d", test.bot, response.StatusCode, test.status)
+		}
+	}
+}
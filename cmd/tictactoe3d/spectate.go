@@ -0,0 +1,295 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"tic-tac-toe-3d-bots/engine"
+	"tic-tac-toe-3d-bots/formats"
+)
+
+// With --broadcast, the games of a run can be watched by any number of spectators, over TCP or (with --broadcast-ws)
+// WebSocket at /spectate. Spectators are read-only: they receive the events of every game as JSON, one per line or message,
+// in the format of the event log (see GameEvent), and anything they send is ignored.
+// A spectator joining while games are in progress first receives their game_started events and the moves played so far.
+// The watch command is a spectator printing the board after every move
+
+// SPECTATOR_QUEUE is how many events may wait to be sent to a spectator; a spectator falling further behind is disconnected
+const SPECTATOR_QUEUE = 256
+
+// DEFAULT_WATCH_ADDRESS is the broadcast the watch command connects to unless --server says otherwise
+const DEFAULT_WATCH_ADDRESS = "localhost:7878"
+
+// Broadcast sends the events of the games in progress to the spectators
+type Broadcast struct {
+	mutex      sync.Mutex
+	games      map[int][]GameEvent // events of every game in progress so far, for spectators joining late
+	spectators map[*spectator]bool
+}
+
+// spectator is a connected spectator with the events waiting to be sent to it
+type spectator struct {
+	conn   arenaConn
+	events chan GameEvent
+}
+
+// broadcast receives the events of every game when set with --broadcast; nil disables broadcasting
+var broadcast *Broadcast
+
+// newBroadcast creates a broadcast without spectators
+func newBroadcast() *Broadcast {
+	return &Broadcast{games: make(map[int][]GameEvent), spectators: make(map[*spectator]bool)}
+}
+
+// publish stamps event with the current time and sends it to every spectator; a nil broadcast discards it
+func (broadcast *Broadcast) publish(event GameEvent) {
+	if broadcast == nil {
+		return
+	}
+	event.Time = time.Now()
+
+	broadcast.mutex.Lock()
+	defer broadcast.mutex.Unlock()
+	if event.Type == EventGameOver {
+		delete(broadcast.games, event.Game)
+	} else {
+		broadcast.games[event.Game] = append(broadcast.games[event.Game], event)
+	}
+	for watcher := range broadcast.spectators {
+		broadcast.send(watcher, event)
+	}
+}
+
+// send queues event for watcher, disconnecting it if its queue is full; the caller holds the mutex
+func (broadcast *Broadcast) send(watcher *spectator, event GameEvent) {
+	select {
+	case watcher.events <- event:
+	default:
+		serverLog.Info("spectator too slow, disconnecting it")
+		delete(broadcast.spectators, watcher)
+		close(watcher.events)
+	}
+}
+
+// attach adds a spectator on conn, sending it the games in progress first, and serves it until it disconnects
+func (broadcast *Broadcast) attach(conn arenaConn) {
+	watcher := &spectator{conn: conn, events: make(chan GameEvent, SPECTATOR_QUEUE)}
+
+	broadcast.mutex.Lock()
+	games := make([]int, 0, len(broadcast.games))
+	for game := range broadcast.games {
+		games = append(games, game)
+	}
+	sort.Ints(games)
+	broadcast.spectators[watcher] = true
+	for _, game := range games {
+		for _, event := range broadcast.games[game] {
+			broadcast.send(watcher, event)
+		}
+	}
+	broadcast.mutex.Unlock()
+
+	// Spectators only listen: reading just notices when they leave, or send a line longer than NET_MAX_LINE
+	left := make(chan struct{})
+	go func() {
+		defer close(left)
+		for {
+			if _, err := conn.ReadLine(); err != nil {
+				return
+			}
+		}
+	}()
+
+	defer conn.Close()
+	for {
+		select {
+		case event, ok := <-watcher.events:
+			if !ok {
+				return
+			}
+			data, _ := json.Marshal(event)
+			if err := conn.WriteLine(string(data)); err != nil {
+				broadcast.detach(watcher)
+				return
+			}
+		case <-left:
+			broadcast.detach(watcher)
+			return
+		}
+	}
+}
+
+// detach removes a spectator that has gone
+func (broadcast *Broadcast) detach(watcher *spectator) {
+	broadcast.mutex.Lock()
+	defer broadcast.mutex.Unlock()
+	if broadcast.spectators[watcher] {
+		delete(broadcast.spectators, watcher)
+		close(watcher.events)
+	}
+}
+
+// startBroadcast accepts spectators over TCP on address and, unless webSocket is empty, over WebSocket at /spectate on webSocket
+// It returns the addresses it listens on; spectators are served in the background for the rest of the run
+func startBroadcast(address, webSocket string) ([]net.Addr, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	addresses := []net.Addr{listener.Addr()}
+	var socketListener net.Listener
+	if webSocket != "" {
+		if socketListener, err = net.Listen("tcp", webSocket); err != nil {
+			listener.Close()
+			return nil, err
+		}
+		addresses = append(addresses, socketListener.Addr())
+	}
+
+	broadcast = newBroadcast()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			serverLog.Debug("spectator connected", "remote", conn.RemoteAddr())
+			go broadcast.attach(arenaTCPConn{Conn: conn, scanner: netLines(conn)})
+		}
+	}()
+	if socketListener != nil {
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /spectate", func(writer http.ResponseWriter, request *http.Request) {
+			socket, err := upgradeWebSocket(writer, request)
+			if err != nil {
+				serverLog.Debug("WebSocket handshake failed", "remote", request.RemoteAddr, "error", err)
+				return
+			}
+			serverLog.Debug("spectator connected over WebSocket", "remote", request.RemoteAddr)
+			broadcast.attach(arenaWebSocketConn{socket})
+		})
+		go http.Serve(socketListener, mux)
+	}
+	return addresses, nil
+}
+
+// broadcastGameStart publishes a game_started event (an OnGameStart observer)
+// The moves of a resumed game follow, so spectators see the position it resumes from
+func broadcastGameStart(start GameStart) {
+	if broadcast == nil {
+		return
+	}
+	broadcast.publish(GameEvent{Type: EventGameStarted, Game: start.Game, Mode: start.Record.Mode, Board: &start.Record.Board, Players: &start.Record.Players})
+	for i, move := range start.Record.Moves {
+		broadcast.publish(GameEvent{Type: EventMove, Game: start.Game, Ply: i + 1, Player: string("xo"[i%2]), Move: move})
+	}
+}
+
+// broadcastMove publishes a move event (an OnMove observer)
+func broadcastMove(move GameMove) {
+	if broadcast == nil {
+		return
+	}
+	score := move.Score
+	broadcast.publish(GameEvent{Type: EventMove, Game: move.Game, Ply: move.Ply, Player: string(move.Player), Move: move.Move,
+		ThinkingMS: formats.Milliseconds(move.Thinking), Score: &score})
+}
+
+// broadcastGameOver publishes a game_over event (an OnGameEnd observer)
+func broadcastGameOver(end GameEnd) {
+	if broadcast == nil {
+		return
+	}
+	broadcast.publish(GameEvent{Type: EventGameOver, Game: end.Game, Winner: end.Result.Winner, Reason: end.Result.Reason})
+}
+
+func init() {
+	OnGameStart(broadcastGameStart)
+	OnMove(broadcastMove)
+	OnGameEnd(broadcastGameOver)
+}
+
+// watchedGame is a game followed by the watch command
+type watchedGame struct {
+	board   *engine.Board
+	players [2]string
+}
+
+// runWatch implements the watch command: it follows the games of a broadcast, printing the board after every move
+func runWatch(args []string, output io.Writer) error {
+	var server, lang string
+	fs := flag.NewFlagSet("watch", flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.StringVar(&server, "server", DEFAULT_WATCH_ADDRESS, "address of the broadcast to watch (see --broadcast)")
+	fs.StringVar(&lang, "lang", "", "language for messages: "+strings.Join(availableLocales(), ", ")+" (default from TTT_LANG or LANG)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+	if lang == "" {
+		lang = localeFromEnvironment()
+	}
+	if err := setLocale(lang); err != nil {
+		return err
+	}
+
+	conn, err := net.Dial("tcp", server)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	fmt.Print(msg("watch.connected", server))
+
+	games := make(map[int]*watchedGame)
+	lines := netLines(conn)
+	for {
+		if !lines.Scan() {
+			if err := lines.Err(); err != nil {
+				return err
+			}
+			fmt.Print(msg("watch.closed"))
+			return nil
+		}
+		var event GameEvent
+		if err := json.Unmarshal(lines.Bytes(), &event); err != nil {
+			return err
+		}
+
+		switch event.Type {
+		case EventGameStarted:
+			if event.Board == nil || event.Players == nil {
+				continue
+			}
+			board, err := engine.New(engine.WithConfig(*event.Board))
+			if err != nil {
+				return err
+			}
+			games[event.Game] = &watchedGame{board: board, players: *event.Players}
+			fmt.Print(msg("watch.game_started", event.Game, event.Mode, event.Players[0], event.Players[1],
+				event.Board.Length, event.Board.Width, event.Board.Height, event.Board.Win))
+		case EventMove:
+			game := games[event.Game]
+			if game == nil || event.Player == "" {
+				continue // Started before we could follow it
+			}
+			game.board.Move(event.Move, event.Player[0])
+			fmt.Print(msg("watch.move", event.Game, game.players[formats.SymbolIndex(event.Player[0])], event.Player, event.Move))
+			printBoard(game.board)
+		case EventGameOver:
+			if game := games[event.Game]; game != nil {
+				delete(games, event.Game)
+				fmt.Print(msg("watch.game_over", event.Game, event.Winner, event.Reason))
+			}
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// SPRT is a sequential probability ratio test between two Elo differences of bot A over bot B
+// H0 says bot A is Elo0 stronger, H1 that it is Elo1 stronger; Alpha and Beta are the accepted false positive and false negative rates
+type SPRT struct {
+	Elo0  float64 `json:"elo0"`
+	Elo1  float64 `json:"elo1"`
+	Alpha float64 `json:"alpha"`
+	Beta  float64 `json:"beta"`
+}
+
+// SPRTDecision is the hypothesis an SPRT accepted and when
+type SPRTDecision struct {
+	Hypothesis string  `json:"hypothesis"` // "H0" or "H1"
+	Games      int     `json:"games"`      // games counted when the test decided
+	LLR        float64 `json:"llr"`
+}
+
+// parseSPRT parses an --sprt value such as "0,10" into the test's Elo bounds
+func parseSPRT(value string, alpha, beta float64) (*SPRT, error) {
+	elo0Str, elo1Str, found := strings.Cut(value, ",")
+	if !found {
+		return nil, fmt.Errorf("invalid SPRT bounds %q (expected elo0,elo1 such as 0,10)", value)
+	}
+	elo0, err := strconv.ParseFloat(strings.TrimSpace(elo0Str), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SPRT bound %q", elo0Str)
+	}
+	elo1, err := strconv.ParseFloat(strings.TrimSpace(elo1Str), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SPRT bound %q", elo1Str)
+	}
+
+	test := &SPRT{Elo0: elo0, Elo1: elo1, Alpha: alpha, Beta: beta}
+	return test, test.validate()
+}
+
+// validate checks that the test can reach a decision
+func (test *SPRT) validate() error {
+	if test.Elo0 >= test.Elo1 {
+		return fmt.Errorf("SPRT elo0 (%g) must be below elo1 (%g)", test.Elo0, test.Elo1)
+	}
+	if test.Alpha <= 0 || test.Alpha >= 1 || test.Beta <= 0 || test.Beta >= 1 {
+		return fmt.Errorf("SPRT alpha and beta must be between 0 and 1")
+	}
+	return nil
+}
+
+// bounds returns the log-likelihood ratios below which H0 and above which H1 is accepted
+func (test *SPRT) bounds() (lower, upper float64) {
+	return math.Log(test.Beta / (1 - test.Alpha)), math.Log((1 - test.Beta) / test.Alpha)
+}
+
+// decide returns the hypothesis accepted at llr, or "" if more games are needed
+func (test *SPRT) decide(llr float64) string {
+	lower, upper := test.bounds()
+	switch {
+	case llr >= upper:
+		return "H1"
+	case llr <= lower:
+		return "H0"
+	}
+	return ""
+}
+
+// expectedScore converts an Elo difference to the expected score of the stronger side
+func expectedScore(elo float64) float64 {
+	return 1 / (1 + math.Pow(10, -elo/400))
+}
+
+// llr returns the log-likelihood ratio of H1 against H0 after the games of stats
+// It uses the normal approximation of the mean game score. Half a game is added to each of wins, draws and losses
+// so that one-sided early results do not give a zero variance
+func (test *SPRT) llr(stats *MatchStats) float64 {
+	wins, draws, losses := float64(stats.Wins)+0.5, float64(stats.Draws)+0.5, float64(stats.Losses)+0.5
+	games := wins + draws + losses
+	score := (wins + draws/2) / games
+	variance := (wins*math.Pow(1-score, 2) + draws*math.Pow(0.5-score, 2) + losses*math.Pow(score, 2)) / games
+
+	score0, score1 := expectedScore(test.Elo0), expectedScore(test.Elo1)
+	return float64(stats.Games) * (score1 - score0) * (2*score - score0 - score1) / (2 * variance)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSPRTLLR(t *testing.T) {
+	// At an even score the LLR is about games * (s1 - s0) * (1 - s0 - s1) / (4 * variance), with s0 and s1 the expected
+	// scores of elo0 and elo1: 4000 games of 1000-2000-1000 against 0,5 give 4000 * 0.007195 * -0.007195 / 0.5 = -0.828
+	tests := []struct {
+		wins, draws, losses int
+		elo0, elo1          float64
+		want                float64
+	}{
+		{0, 0, 0, 0, 10, 0},
+		{1000, 2000, 1000, 0, 5, -0.8282},
+		{1000, 2000, 1000, -5, 5, 0},
+		{60, 20, 40, 0, 10, 0.6450},
+		{40, 20, 60, 0, 10, -0.7686},
+		{500, 300, 200, 0, 10, 13.4466},
+	}
+	for _, test := range tests {
+		stats := &MatchStats{Games: test.wins + test.draws + test.losses, Wins: test.wins, Draws: test.draws, Losses: test.losses}
+		sprt := &SPRT{Elo0: test.elo0, Elo1: test.elo1, Alpha: 0.05, Beta: 0.05}
+		if got := sprt.llr(stats); math.Abs(got-test.want) > 0.001 {
+			t.Errorf("LLR of %d-%d-%d against %g,%g: got %.4f, want %.4f", test.wins, test.draws, test.losses, test.elo0, test.elo1, got, test.want)
+		}
+	}
+}
+
+func TestSPRTDecide(t *testing.T) {
+	// With alpha = beta = 0.05 the bounds are ±ln(19) = ±2.944
+	sprt := &SPRT{Elo0: 0, Elo1: 10, Alpha: 0.05, Beta: 0.05}
+	if lower, upper := sprt.bounds(); math.Abs(lower+2.9444) > 0.0001 || math.Abs(upper-2.9444) > 0.0001 {
+		t.Errorf("bounds: got %.4f, %.4f, want -2.9444, 2.9444", lower, upper)
+	}
+	tests := []struct {
+		llr  float64
+		want string
+	}{
+		{0, ""},
+		{2.94, ""},
+		{2.95, "H1"},
+		{-2.94, ""},
+		{-2.95, "H0"},
+	}
+	for _, test := range tests {
+		if got := sprt.decide(test.llr); got != test.want {
+			t.Errorf("decide(%g): got %q, want %q", test.llr, got, test.want)
+		}
+	}
+}
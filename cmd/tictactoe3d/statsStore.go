@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DEFAULT_STATS_FILE is where player statistics are kept between runs
+const DEFAULT_STATS_FILE = "player_stats.json"
+
+// DEFAULT_PLAYER_NAME is used for the human player when no name is given
+const DEFAULT_PLAYER_NAME = "Player"
+
+// PlayerRecord holds the lifetime results of one human player (from the player's point of view)
+type PlayerRecord struct {
+	Name          string                     `json:"name"`
+	Wins          int                        `json:"wins"`
+	Losses        int                        `json:"losses"`
+	Draws         int                        `json:"draws"`
+	AdaptiveLevel int                        `json:"adaptive_level"`       // current strength level of the adaptive bot against this player
+	Moves         int                        `json:"moves"`                // moves played in PvE games
+	ThinkingMS    float64                    `json:"thinking_ms"`          // total thinking time over those moves
+	Opponents     map[string]*OpponentRecord `json:"opponents,omitempty"`  // results per bot, keyed by bot spec
+	LastPlayed    time.Time                  `json:"last_played,omitzero"` // when the last PvE game finished
+	Tendencies    *PlayerTendencies          `json:"tendencies,omitempty"` // what the modeling bot learned of the player
+}
+
+// StatsStore is a JSON file of player records keyed by lowercase player name, along with everyone's ratings
+type StatsStore struct {
+	path    string
+	Players map[string]*PlayerRecord `json:"players"`
+	Ratings map[string]*PlayerRating `json:"ratings"` // keyed by ratingKey
+	Games   []RatedGame              `json:"games"`   // every rated game, oldest first
+	mutex   sync.Mutex
+	saving  sync.Mutex // serializes writes of the file
+}
+
+// newStatsStore creates an empty store that will be saved to path
+func newStatsStore(path string) *StatsStore {
+	return &StatsStore{path: path, Players: make(map[string]*PlayerRecord), Ratings: make(map[string]*PlayerRating)}
+}
+
+// loadStatsStore reads the stats file at path; a missing file gives an empty store
+func loadStatsStore(path string) (*StatsStore, error) {
+	store := newStatsStore(path)
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, store); err != nil {
+		return nil, err
+	}
+	if store.Players == nil {
+		store.Players = make(map[string]*PlayerRecord)
+	}
+	if store.Ratings == nil {
+		store.Ratings = make(map[string]*PlayerRating)
+	}
+	return store, nil
+}
+
+// Lookup returns the record for name, if the player has one
+func (store *StatsStore) Lookup(name string) (*PlayerRecord, bool) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	record, exists := store.Players[strings.ToLower(strings.TrimSpace(name))]
+	return record, exists
+}
+
+// Player returns the record for name, creating an empty one if needed
+func (store *StatsStore) Player(name string) *PlayerRecord {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	key := strings.ToLower(strings.TrimSpace(name))
+	record, exists := store.Players[key]
+	if !exists {
+		record = &PlayerRecord{Name: name}
+		store.Players[key] = record
+	}
+	return record
+}
+
+// Save writes the store back to its file, replacing it atomically
+func (store *StatsStore) Save() error {
+	store.saving.Lock()
+	defer store.saving.Unlock()
+
+	store.mutex.Lock()
+	data, err := json.MarshalIndent(store, "", "  ")
+	store.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	tmpPath := store.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, store.path)
+}
+
+// RecordResult adds a finished game to the record; winner is 'x', 'o' or '|' for a draw
+func (record *PlayerRecord) RecordResult(winner, playerSymbol byte) {
+	switch winner {
+	case playerSymbol:
+		record.Wins++
+	case '|':
+		record.Draws++
+	default:
+		record.Losses++
+	}
+}
+
+// GamesPlayed returns the total number of recorded games
+func (record *PlayerRecord) GamesPlayed() int {
+	return record.Wins + record.Losses + record.Draws
+}
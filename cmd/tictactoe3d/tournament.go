@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"tic-tac-toe-3d-bots/bots"
+	"tic-tac-toe-3d-bots/engine"
+	"tic-tac-toe-3d-bots/formats"
+)
+
+// TournamentEntrant is one bot of a round-robin tournament
+type TournamentEntrant struct {
+	Name   string `json:"name"`
+	Spec   string `json:"spec"` // bot spec as accepted by --bot1/--bot2
+	newBot func(symbol byte) bots.BotInterface
+}
+
+// TournamentStanding is one line of the final ranking
+type TournamentStanding struct {
+	Name   string        `json:"name"`
+	Points float64       `json:"points"` // 1 per win, 1/2 per draw
+	Games  int           `json:"games"`
+	Wins   int           `json:"wins"`
+	Draws  int           `json:"draws"`
+	Losses int           `json:"losses"`
+	Resign int           `json:"resign_wins"`      // wins by the opponent resigning
+	Rating *PlayerRating `json:"rating,omitempty"` // the entrant's rating after the tournament
+}
+
+// add counts the games of one pairing
+func (standing *TournamentStanding) add(wins, draws, losses int) {
+	standing.Games += wins + draws + losses
+	standing.Wins += wins
+	standing.Draws += draws
+	standing.Losses += losses
+	standing.Points += float64(wins) + float64(draws)/2
+}
+
+// TournamentResult is the outcome of a tournament, written as one line of JSON with --output json
+type TournamentResult struct {
+	Entrants   []TournamentEntrant  `json:"entrants"`
+	Crosstable [][]float64          `json:"crosstable"` // points scored by the row entrant against the column entrant
+	Standings  []TournamentStanding `json:"standings"`  // best first
+}
+
+// uniqueNames numbers repeated names ("random", "random #2") so that every entrant can be told apart
+func uniqueNames(names []string) []string {
+	unique := make([]string, len(names))
+	seen := make(map[string]int)
+	for i, name := range names {
+		seen[name]++
+		unique[i] = name
+		if seen[name] > 1 {
+			unique[i] = fmt.Sprintf("%s #%d", name, seen[name])
+		}
+	}
+	return unique
+}
+
+// playTournament plays an all-play-all tournament: every pair of entrants plays a match of games games, alternating sides
+// Entrants must have distinct names (see uniqueNames)
+// Each pairing prints one line as it finishes, followed by the crosstable and the ranking
+func playTournament(board *engine.Board, entrants []TournamentEntrant, games, workers int, settings formats.EvESettings) TournamentResult {
+	result := TournamentResult{Entrants: entrants, Crosstable: make([][]float64, len(entrants))}
+	standings := make([]TournamentStanding, len(entrants))
+	for i := range entrants {
+		result.Crosstable[i] = make([]float64, len(entrants))
+		standings[i].Name = entrants[i].Name
+	}
+
+	pairings := len(entrants) * (len(entrants) - 1) / 2
+	fmt.Print(msg("tournament.begins", len(entrants), pairings, games))
+	pairing := 0
+	for i := range entrants {
+		for j := i + 1; j < len(entrants); j++ {
+			stats := runMatch(board, entrants[i].newBot, entrants[j].newBot, games, workers, settings, nil, nil)
+			pairing++
+			fmt.Print(msg("tournament.pairing", pairing, pairings, entrants[i].Name, stats.Wins, stats.Draws, stats.Losses, entrants[j].Name))
+
+			points := float64(stats.Wins) + float64(stats.Draws)/2
+			result.Crosstable[i][j] += points
+			result.Crosstable[j][i] += float64(stats.Games) - points
+			standings[i].add(stats.Wins, stats.Draws, stats.Losses)
+			standings[j].add(stats.Losses, stats.Draws, stats.Wins)
+			standings[i].Resign += stats.Resigned[0]
+			standings[j].Resign += stats.Resigned[1]
+		}
+	}
+
+	for i := range standings {
+		standings[i].Rating, _ = botRating(entrants[i].newBot)
+	}
+
+	// Rank by points, then by wins, keeping the entry order for full ties
+	sort.SliceStable(standings, func(a, b int) bool {
+		if standings[a].Points != standings[b].Points {
+			return standings[a].Points > standings[b].Points
+		}
+		return standings[a].Wins > standings[b].Wins
+	})
+	result.Standings = standings
+
+	printTournamentResult(result)
+	return result
+}
+
+// printTournamentResult prints the crosstable and the ranking; with JSON output the result is also written as one line of JSON
+func printTournamentResult(result TournamentResult) {
+	width := 0
+	for _, entrant := range result.Entrants {
+		width = max(width, len(entrant.Name))
+	}
+
+	fmt.Println(msg("tournament.crosstable"))
+	header := fmt.Sprintf("%-*s", width+4, "")
+	for i := range result.Entrants {
+		header += fmt.Sprintf(" %6d", i+1)
+	}
+	fmt.Println(header)
+	for i, entrant := range result.Entrants {
+		row := fmt.Sprintf("%2d. %-*s", i+1, width, entrant.Name)
+		for j, points := range result.Crosstable[i] {
+			if i == j {
+				row += fmt.Sprintf(" %6s", "-")
+			} else {
+				row += fmt.Sprintf(" %6.1f", points)
+			}
+		}
+		fmt.Println(row)
+	}
+
+	fmt.Println(msg("tournament.standings"))
+	fmt.Println(strings.Repeat("═", width+40))
+	for rank, standing := range result.Standings {
+		rating := "-"
+		if standing.Rating != nil {
+			rating = standing.Rating.String()
+		}
+		fmt.Print(msg("tournament.standing", rank+1, width, standing.Name, standing.Points, standing.Games, standing.Wins, standing.Draws, standing.Losses, rating))
+	}
+	fmt.Print(msg("seed.replay", bots.RunSeed))
+
+	csvExport.WriteTournament(result)
+	if JSONOutput {
+		if err := json.NewEncoder(resultOutput).Encode(result); err != nil {
+			fmt.Fprintln(os.Stderr, msg("error"), err)
+		}
+	}
+}
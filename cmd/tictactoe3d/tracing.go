@@ -0,0 +1,318 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"tic-tac-toe-3d-bots/bots"
+	"tic-tac-toe-3d-bots/engine"
+)
+
+// Bot moves can be traced with OpenTelemetry: every move a bot searches is a trace whose root span is the move,
+// with the spans of the search below it (see bots.Tracer). Spans are exported in batches to an OTLP collector,
+// as JSON over HTTP
+
+// TRACE_EXPORT_INTERVAL is how often finished spans are sent to the collector
+const TRACE_EXPORT_INTERVAL = 2 * time.Second
+
+// TRACE_BATCH_SIZE is how many finished spans are sent at once; more are sent before the interval is over
+const TRACE_BATCH_SIZE = 512
+
+// TRACE_QUEUE_LIMIT is how many finished spans are kept while the collector cannot be reached; newer spans are dropped
+const TRACE_QUEUE_LIMIT = 64 * TRACE_BATCH_SIZE
+
+// TRACE_SERVICE_NAME names the program to the collector
+const TRACE_SERVICE_NAME = "tic-tac-toe-3d-bots"
+
+// tracer traces bot moves when set with --trace-endpoint; nil disables tracing
+var tracer *OTLPTracer
+
+// OTLPTracer exports spans to an OpenTelemetry collector (implements bots.Tracer)
+type OTLPTracer struct {
+	endpoint string // URL spans are posted to, e.g. "http://localhost:4318/v1/traces"
+	client   *http.Client
+
+	mutex   sync.Mutex
+	pending []otlpSpan // finished spans waiting to be exported
+	dropped int        // spans dropped since the last export, because the queue was full
+	wake    chan struct{}
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+// otlpSpan is a finished span as OTLP/JSON encodes it
+type otlpSpan struct {
+	TraceID      string          `json:"traceId"`
+	SpanID       string          `json:"spanId"`
+	ParentSpanID string          `json:"parentSpanId,omitempty"`
+	Name         string          `json:"name"`
+	Kind         int             `json:"kind"` // always 1, internal
+	Start        string          `json:"startTimeUnixNano"`
+	End          string          `json:"endTimeUnixNano"`
+	Attributes   []otlpAttribute `json:"attributes,omitempty"`
+}
+
+// otlpAttribute is a key and its value, of which exactly one field is set
+type otlpAttribute struct {
+	Key   string `json:"key"`
+	Value struct {
+		String *string  `json:"stringValue,omitempty"`
+		Int    *string  `json:"intValue,omitempty"` // 64-bit integers are strings in OTLP/JSON
+		Bool   *bool    `json:"boolValue,omitempty"`
+		Double *float64 `json:"doubleValue,omitempty"`
+	} `json:"value"`
+}
+
+// newOTLPTracer starts a tracer exporting to the collector at endpoint
+// An endpoint without a path, such as "http://localhost:4318", gets the standard path /v1/traces
+func newOTLPTracer(endpoint string) (*OTLPTracer, error) {
+	parsed, err := url.Parse(endpoint)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid trace endpoint %q (expected a URL such as http://localhost:4318)", endpoint)
+	}
+	if parsed.Path == "" || parsed.Path == "/" {
+		parsed.Path = "/v1/traces"
+	}
+
+	tracer := &OTLPTracer{
+		endpoint: parsed.String(),
+		client:   &http.Client{Timeout: 10 * time.Second},
+		wake:     make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	go tracer.export()
+	return tracer, nil
+}
+
+// traceSpan is a span being recorded
+type traceSpan struct {
+	tracer *OTLPTracer
+	start  time.Time
+
+	mutex      sync.Mutex
+	data       otlpSpan
+	attributes map[string]any
+	keys       []string // attribute keys in the order they were first set
+	ended      bool
+}
+
+// traceSpanKey is the context key of the span a context carries
+type traceSpanKey struct{}
+
+// Start implements bots.Tracer
+func (tracer *OTLPTracer) Start(ctx context.Context, name string, attributes ...any) (context.Context, bots.Span) {
+	span := &traceSpan{tracer: tracer, start: time.Now(), data: otlpSpan{Name: name, Kind: 1, SpanID: randomHex(8)},
+		attributes: make(map[string]any)}
+	if parent, ok := ctx.Value(traceSpanKey{}).(*traceSpan); ok {
+		span.data.TraceID, span.data.ParentSpanID = parent.data.TraceID, parent.data.SpanID
+	} else {
+		span.data.TraceID = randomHex(16)
+	}
+	span.SetAttributes(attributes...)
+	return context.WithValue(ctx, traceSpanKey{}, span), span
+}
+
+// SetAttributes implements bots.Span; a key set again replaces its value
+func (span *traceSpan) SetAttributes(attributes ...any) {
+	span.mutex.Lock()
+	defer span.mutex.Unlock()
+	for i := 0; i+1 < len(attributes); i += 2 {
+		key := fmt.Sprint(attributes[i])
+		if _, exists := span.attributes[key]; !exists {
+			span.keys = append(span.keys, key)
+		}
+		span.attributes[key] = attributes[i+1]
+	}
+}
+
+// End implements bots.Span: the span is queued for export; ending it again does nothing
+func (span *traceSpan) End() {
+	end := time.Now()
+	span.mutex.Lock()
+	if span.ended {
+		span.mutex.Unlock()
+		return
+	}
+	span.ended = true
+	data := span.data
+	data.Start = strconv.FormatInt(span.start.UnixNano(), 10)
+	data.End = strconv.FormatInt(end.UnixNano(), 10)
+	for _, key := range span.keys {
+		data.Attributes = append(data.Attributes, newOTLPAttribute(key, span.attributes[key]))
+	}
+	span.mutex.Unlock()
+
+	span.tracer.queue(data)
+}
+
+// newOTLPAttribute converts an attribute value; values of other types are exported as their fmt representation
+func newOTLPAttribute(key string, value any) otlpAttribute {
+	attribute := otlpAttribute{Key: key}
+	switch value := value.(type) {
+	case string:
+		attribute.Value.String = &value
+	case int:
+		text := strconv.Itoa(value)
+		attribute.Value.Int = &text
+	case int64:
+		text := strconv.FormatInt(value, 10)
+		attribute.Value.Int = &text
+	case bool:
+		attribute.Value.Bool = &value
+	case float64:
+		attribute.Value.Double = &value
+	case []string:
+		text := strings.Join(value, " ")
+		attribute.Value.String = &text
+	case byte:
+		text := string(value)
+		attribute.Value.String = &text
+	default:
+		text := fmt.Sprint(value)
+		attribute.Value.String = &text
+	}
+	return attribute
+}
+
+// randomHex returns size random bytes in hex, for trace and span ids
+func randomHex(size int) string {
+	id := make([]byte, size)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// queue adds a finished span to the next export, waking the exporter once a batch is full
+func (tracer *OTLPTracer) queue(span otlpSpan) {
+	tracer.mutex.Lock()
+	defer tracer.mutex.Unlock()
+	if len(tracer.pending) >= TRACE_QUEUE_LIMIT {
+		tracer.dropped++
+		return
+	}
+	tracer.pending = append(tracer.pending, span)
+	if len(tracer.pending) == TRACE_BATCH_SIZE {
+		select {
+		case tracer.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// export sends the finished spans every TRACE_EXPORT_INTERVAL, or as soon as a batch is full, until Close
+func (tracer *OTLPTracer) export() {
+	defer close(tracer.stopped)
+	ticker := time.NewTicker(TRACE_EXPORT_INTERVAL)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-tracer.wake:
+		case <-tracer.stop:
+			tracer.flush()
+			return
+		}
+		tracer.flush()
+	}
+}
+
+// flush sends the finished spans in batches of TRACE_BATCH_SIZE; spans the collector did not take are kept for the next try
+func (tracer *OTLPTracer) flush() {
+	for {
+		tracer.mutex.Lock()
+		batch := tracer.pending[:min(len(tracer.pending), TRACE_BATCH_SIZE)]
+		dropped := tracer.dropped
+		tracer.dropped = 0
+		tracer.mutex.Unlock()
+		if dropped > 0 {
+			traceLog.Warn("spans dropped while the collector was behind", "spans", dropped)
+		}
+		if len(batch) == 0 {
+			return
+		}
+
+		if err := tracer.post(batch); err != nil {
+			traceLog.Warn("cannot export spans", "endpoint", tracer.endpoint, "spans", len(batch), "error", err)
+			return
+		}
+		tracer.mutex.Lock()
+		tracer.pending = tracer.pending[len(batch):]
+		tracer.mutex.Unlock()
+	}
+}
+
+// post sends one batch of spans to the collector
+func (tracer *OTLPTracer) post(spans []otlpSpan) error {
+	type keyValue = map[string]any
+	request := keyValue{"resourceSpans": []keyValue{{
+		"resource": keyValue{"attributes": []otlpAttribute{newOTLPAttribute("service.name", TRACE_SERVICE_NAME)}},
+		"scopeSpans": []keyValue{{
+			"scope": keyValue{"name": "tic-tac-toe-3d-bots/bots"},
+			"spans": spans,
+		}},
+	}}}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	response, err := tracer.client.Post(tracer.endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode/100 != 2 {
+		return fmt.Errorf("collector answered %s", response.Status)
+	}
+	return nil
+}
+
+// Close stops tracing after exporting the spans still waiting
+func (tracer *OTLPTracer) Close() error {
+	if tracer == nil {
+		return nil
+	}
+	bots.SetTracer(nil)
+	close(tracer.stop)
+	<-tracer.stopped
+	return nil
+}
+
+// addTraceFlag declares the --trace-endpoint flag on fs; its default comes from OTEL_EXPORTER_OTLP_ENDPOINT
+func addTraceFlag(fs *flag.FlagSet) *string {
+	return fs.String("trace-endpoint", os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"),
+		"trace every bot move to the OpenTelemetry collector at this URL, e.g. http://localhost:4318 (default from OTEL_EXPORTER_OTLP_ENDPOINT, off if unset)")
+}
+
+// startTracing traces bot moves to the collector at endpoint until the returned function is called; "" traces nothing
+func startTracing(endpoint string) (func() error, error) {
+	if endpoint == "" {
+		return func() error { return nil }, nil
+	}
+	var err error
+	if tracer, err = newOTLPTracer(endpoint); err != nil {
+		return nil, err
+	}
+	bots.SetTracer(tracer)
+	return tracer.Close, nil
+}
+
+// traceMove starts the root span of a bot's move, which the bot's search adds its spans below; nil if tracing is off
+func traceMove(ctx context.Context, bot bots.BotInterface, board *engine.Board) (context.Context, bots.Span) {
+	if tracer == nil {
+		return ctx, nil
+	}
+	return tracer.Start(ctx, "bot move", "bot", bot.Name(), "player", bot.Symbol(), "ply", board.MoveCount()+1)
+}
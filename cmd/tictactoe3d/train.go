@@ -0,0 +1,116 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+
+	"tic-tac-toe-3d-bots/bots"
+	"tic-tac-toe-3d-bots/engine"
+)
+
+// TRAIN_REPORTS is how many times the train command reports its progress over a run
+const TRAIN_REPORTS = 10
+
+// TRAIN_SCORE_GAMES is how many games per side the train command plays against random moves to score the table
+const TRAIN_SCORE_GAMES = 500
+
+// runTrain implements the train command: it teaches the Q-learning bot's table by self-play on a board, exploring
+// less and less as the run goes on, then saves the table and scores it against random moves
+func runTrain(args []string, output io.Writer) error {
+	var tablePath, boardSpec, lang string
+	var episodes int
+	var alpha, epsilonStart, epsilonEnd float64
+
+	fs := flag.NewFlagSet("train", flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.StringVar(&tablePath, "table", DEFAULT_QTABLE_FILE, "value table to train, created if missing; the qlearning bot plays from "+DEFAULT_QTABLE_FILE)
+	fs.StringVar(&boardSpec, "board", "3x3x3", "board to train on, as LxWxH or LxWxH/win")
+	fs.IntVar(&episodes, "episodes", 200000, "self-play games to learn from")
+	fs.Float64Var(&alpha, "alpha", 0.5, "learning rate: how far each move's value moves toward what followed it")
+	fs.Float64Var(&epsilonStart, "epsilon-start", 1, "chance of a random move at the start of the run")
+	fs.Float64Var(&epsilonEnd, "epsilon-end", 0.05, "chance of a random move at the end of the run, reached linearly")
+	fs.StringVar(&lang, "lang", "", "language for messages: "+strings.Join(availableLocales(), ", ")+" (default from TTT_LANG or LANG)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+	if episodes < 1 {
+		return fmt.Errorf("--episodes must be at least 1")
+	}
+	if alpha <= 0 || alpha > 1 {
+		return fmt.Errorf("--alpha must be above 0 and at most 1")
+	}
+	if epsilonStart < 0 || epsilonStart > 1 || epsilonEnd < 0 || epsilonEnd > 1 {
+		return fmt.Errorf("--epsilon-start and --epsilon-end must be between 0 and 1")
+	}
+
+	if lang == "" {
+		lang = localeFromEnvironment()
+	}
+	if err := setLocale(lang); err != nil {
+		return err
+	}
+
+	config, err := engine.ParseBoardConfig(boardSpec)
+	if err != nil {
+		return err
+	}
+	if config.Win == 0 {
+		config.Win = min(config.Length, config.Width, config.Height)
+	}
+	board, err := engine.New(engine.WithConfig(*config))
+	if err != nil {
+		return err
+	}
+	table, err := loadQTable(tablePath)
+	if err != nil {
+		return err
+	}
+
+	random := rand.New(rand.NewSource(bots.NextSeed()))
+	for episode := 1; episode <= episodes; episode++ {
+		epsilon := epsilonStart
+		if episodes > 1 {
+			epsilon += (epsilonEnd - epsilonStart) * float64(episode-1) / float64(episodes-1)
+		}
+		table.Episode(board, alpha, epsilon, random)
+		if episode%max(episodes/TRAIN_REPORTS, 1) == 0 || episode == episodes {
+			fmt.Print(msg("train.progress", episode, episodes, epsilon, len(table.Values)))
+		}
+	}
+	if err := table.Save(); err != nil {
+		return err
+	}
+	fmt.Print(msg("train.saved", tablePath, len(table.Values), table.Episodes))
+
+	fmt.Print(msg("train.score", TRAIN_SCORE_GAMES, 100*scoreQTable(table, board, 'x', random), 100*scoreQTable(table, board, 'o', random)))
+	return nil
+}
+
+// scoreQTable plays TRAIN_SCORE_GAMES games on an empty board like board, symbol playing the table's best moves
+// against random moves, and returns the share of the points symbol took, a draw counting half
+func scoreQTable(table *QTable, board *engine.Board, symbol byte, random *rand.Rand) float64 {
+	points := 0.0
+	for range TRAIN_SCORE_GAMES {
+		game := freshBoard(board)
+		for game.CheckWin() == '|' && !game.IsFull() {
+			moves := game.GetValidMoves()
+			if game.NextPlayer() == symbol {
+				moves, _ = table.bestMoves(game)
+			}
+			game.Move(moves[random.Intn(len(moves))], game.NextPlayer())
+		}
+		switch game.CheckWin() {
+		case symbol:
+			points++
+		case '|':
+			points += 0.5
+		}
+	}
+	return points / TRAIN_SCORE_GAMES
+}
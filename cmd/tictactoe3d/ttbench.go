@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"time"
+
+	"tic-tac-toe-3d-bots/bots"
+	"tic-tac-toe-3d-bots/engine"
+)
+
+// runTTBench implements the ttbench command: the alpha-beta bot searches the positions of one game again under each
+// replacement policy of its transposition table, at the same size, and the command prints how each policy fared, and
+// how many allocations the searches made. The
+// concurrent alpha-beta bot then searches them with its table lock-free, behind a single lock and split into shards,
+// to show what the parallel searches lose waiting for each other
+func runTTBench(args []string, output io.Writer) error {
+	var boardSpec, lang string
+	var depth, hash, plies int
+
+	fs := flag.NewFlagSet("ttbench", flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.StringVar(&boardSpec, "board", "4x4x4", "board to search on, as LxWxH or LxWxH/win")
+	fs.IntVar(&depth, "depth", 6, "search depth of the bot")
+	fs.IntVar(&hash, "hash", 1, "size in MiB of each side's transposition table; small tables show the policies apart")
+	fs.IntVar(&plies, "plies", 16, "moves of the game searched")
+	fs.StringVar(&lang, "lang", "", "language for messages: "+strings.Join(availableLocales(), ", ")+" (default from TTT_LANG or LANG)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+	if depth < 1 || hash < 1 || plies < 1 {
+		return fmt.Errorf("--depth, --hash and --plies must be at least 1")
+	}
+
+	if lang == "" {
+		lang = localeFromEnvironment()
+	}
+	if err := setLocale(lang); err != nil {
+		return err
+	}
+
+	config, err := engine.ParseBoardConfig(boardSpec)
+	if err != nil {
+		return err
+	}
+	if config.Win == 0 {
+		config.Win = min(config.Length, config.Width, config.Height)
+	}
+	board, err := engine.New(engine.WithConfig(*config))
+	if err != nil {
+		return err
+	}
+
+	// The game is the bot's own, played with the default policy, so every policy searches the same positions
+	alphaBeta := func(policy bots.ReplacementPolicy) func(symbol byte) ttBenchBot {
+		return func(symbol byte) ttBenchBot {
+			return bots.NewAlphaBetaMinimaxBot(symbol, string(symbol), depth, engine.DefaultEvaluator(), hash, policy)
+		}
+	}
+	game, _ := ttBenchGame(board, alphaBeta(bots.DEFAULT_TT_POLICY), nil, plies)
+	fmt.Print(msg("ttbench.game", len(game), boardSpec, depth, hash))
+	for _, policy := range bots.ReplacementPolicies() {
+		var stats bots.TTStats
+		elapsed, allocations := ttBenchMeasure(func() { _, stats = ttBenchGame(board, alphaBeta(policy), game, len(game)) })
+		fmt.Print(msg("ttbench.policy", policy, elapsed, 100*stats.HitRate(), stats.Cutoffs,
+			stats.Overwritten, stats.Stale, stats.Rejected, 100*stats.FillRate(), allocations))
+	}
+
+	// One shard is a table behind a single lock, the worst case sharding is there to avoid
+	fmt.Print(msg("ttbench.parallel", runtime.GOMAXPROCS(0)))
+	layouts := []struct {
+		locking bots.TTLocking
+		shards  int
+	}{{bots.TTLockFree, 0}, {bots.TTSharded, 1}, {bots.TTSharded, bots.DEFAULT_TT_SHARDS}}
+	for _, layout := range layouts {
+		concurrent := func(symbol byte) ttBenchBot {
+			tt, _ := bots.NewSharedTT(hash, bots.DEFAULT_TT_POLICY, layout.locking, layout.shards) // A MiB has room for every layout's shards
+			return bots.NewConcurrentAlphaBetaMinimaxBot(symbol, string(symbol), depth, engine.DefaultEvaluator(), tt)
+		}
+		name := layout.locking.String()
+		if layout.locking == bots.TTSharded {
+			name = fmt.Sprintf("%s/%d", name, layout.shards)
+		}
+		var stats bots.TTStats
+		elapsed, allocations := ttBenchMeasure(func() { _, stats = ttBenchGame(board, concurrent, game, len(game)) })
+		fmt.Print(msg("ttbench.locking", name, elapsed, 100*stats.HitRate(), stats.Cutoffs,
+			stats.Contended, stats.Locks, stats.LockWaitMS, allocations))
+	}
+	return nil
+}
+
+// ttBenchMeasure runs bench, returning how long it took and how many heap allocations the program made meanwhile
+func ttBenchMeasure(bench func()) (time.Duration, uint64) {
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	started := time.Now()
+	bench()
+	elapsed := time.Since(started).Round(time.Millisecond)
+	runtime.ReadMemStats(&after)
+	return elapsed, after.Mallocs - before.Mallocs
+}
+
+// ttBenchBot is a bot with a transposition table, as ttbench compares them
+type ttBenchBot interface {
+	bots.BotInterface
+	ttBot
+}
+
+// ttBenchGame has a bot made by newBot for each side search the first plies positions of game from board, playing the
+// game's moves rather than their own; with a nil game, the bots play their own moves. It returns the moves played,
+// and the statistics of both sides' tables added together
+func ttBenchGame(board *engine.Board, newBot func(symbol byte) ttBenchBot, game []string, plies int) ([]string, bots.TTStats) {
+	board = engine.CopyBoard(board)
+	sides := [2]ttBenchBot{newBot('x'), newBot('o')}
+	defer sides[0].Close()
+	defer sides[1].Close()
+
+	var played []string
+	for ply := 0; ply < plies && board.CheckWin() == '|' && !board.IsFull(); ply++ {
+		symbol := board.NextPlayer()
+		move, err := sides[ply%2].MakeMove(context.Background(), engine.CopyBoard(board))
+		if err != nil {
+			break
+		}
+		if game != nil {
+			move.Name = game[ply]
+		}
+		board.Move(move.Name, symbol)
+		played = append(played, move.Name)
+	}
+
+	var total bots.TTStats
+	for _, side := range sides {
+		stats := side.TTStats()
+		total.Entries += stats.Entries
+		total.Used += stats.Used
+		total.Probes += stats.Probes
+		total.Hits += stats.Hits
+		total.Cutoffs += stats.Cutoffs
+		total.Overwritten += stats.Overwritten
+		total.Stale += stats.Stale
+		total.Rejected += stats.Rejected
+		total.Locks += stats.Locks
+		total.Contended += stats.Contended
+		total.LockWaitMS += stats.LockWaitMS
+	}
+	return played, total
+}
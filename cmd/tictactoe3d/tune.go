@@ -0,0 +1,118 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"tic-tac-toe-3d-bots/bots"
+	"tic-tac-toe-3d-bots/engine"
+	"tic-tac-toe-3d-bots/formats"
+)
+
+// runTune implements the tune command: it plays a bot with each value of one parameter against the same bot as
+// specified, an SPRT deciding each match, and prints how every value scores, to tune the evaluator's defaults
+// Tuning several parameters is done one at a time, each run's --bot carrying the values the earlier runs settled on
+func runTune(args []string, output io.Writer) error {
+	var boardSpec, spec, param, valueList, sprt, lang string
+	var games, workers, randomPlies int
+	var seed int64
+	var sprtAlpha, sprtBeta float64
+
+	fs := flag.NewFlagSet("tune", flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.StringVar(&boardSpec, "board", "4x4x4", "board to play on, as LxWxH or LxWxH/win")
+	fs.StringVar(&spec, "bot", "alphabeta:depth=4", "bot spec to tune, which also plays the baseline")
+	fs.StringVar(&param, "param", "", "bot parameter to tune, e.g. vertical (required)")
+	fs.StringVar(&valueList, "values", "", "comma-separated values of --param to try (required)")
+	fs.IntVar(&games, "games", 2000, "most games of each value's match, sides alternating")
+	fs.StringVar(&sprt, "sprt", "0,10", "stop each match once an SPRT between these Elo bounds of the value over the baseline decides")
+	fs.Float64Var(&sprtAlpha, "sprt-alpha", 0.05, "SPRT false positive rate")
+	fs.Float64Var(&sprtBeta, "sprt-beta", 0.05, "SPRT false negative rate")
+	fs.IntVar(&randomPlies, "random-plies", 4, "start each pair of games from an opening of this many random moves")
+	fs.IntVar(&workers, "workers", runtime.NumCPU(), "games played at once")
+	fs.Int64Var(&seed, "seed", 1, "seed of the openings, so that a run can be repeated")
+	fs.StringVar(&lang, "lang", "", "language for messages: "+strings.Join(availableLocales(), ", ")+" (default from TTT_LANG or LANG)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+	if param == "" || valueList == "" {
+		return fmt.Errorf("the tune command needs a parameter in --param and its values in --values")
+	}
+	if games < 1 {
+		return fmt.Errorf("--games must be at least 1")
+	}
+	if randomPlies < 0 {
+		return fmt.Errorf("--random-plies must not be negative")
+	}
+
+	if lang == "" {
+		lang = localeFromEnvironment()
+	}
+	if err := setLocale(lang); err != nil {
+		return err
+	}
+
+	config, err := engine.ParseBoardConfig(boardSpec)
+	if err != nil {
+		return err
+	}
+	if config.Win == 0 {
+		config.Win = min(config.Length, config.Width, config.Height)
+	}
+	board, err := engine.New(engine.WithConfig(*config))
+	if err != nil {
+		return err
+	}
+	test, err := parseSPRT(sprt, sprtAlpha, sprtBeta)
+	if err != nil {
+		return err
+	}
+	var values []int
+	for _, field := range strings.Split(valueList, ",") {
+		value, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			return fmt.Errorf("--values: %v", err)
+		}
+		values = append(values, value)
+	}
+
+	baseline, err := specBotFactory(spec, "Baseline")
+	if err != nil {
+		return err
+	}
+	separator := ","
+	if !strings.Contains(spec, ":") {
+		separator = ":"
+	}
+	// Every value's match starts from the same openings
+	book := openingBook
+	defer func() { openingBook = book }()
+	if openingBook, err = openOpeningBook("", 0, randomPlies); err != nil {
+		return err
+	}
+
+	fmt.Fprint(output, msg("tune.begins", spec, param, config.Length, config.Width, config.Height, config.Win, games, test.Elo0, test.Elo1))
+	for _, value := range values {
+		candidateSpec := fmt.Sprintf("%s%s%s=%d", spec, separator, param, value)
+		candidate, err := specBotFactory(candidateSpec, "Candidate")
+		if err != nil {
+			return err
+		}
+		bots.SetSeed(seed)
+		stats := runMatch(board, candidate, baseline, games, workers, formats.EvESettings{}, test, nil)
+		verdict := msg("tune.undecided")
+		if stats.SPRT != nil {
+			verdict = stats.SPRT.Hypothesis
+		}
+		fmt.Fprint(output, msg("tune.result", param, value, stats.Wins, stats.Draws, stats.Losses, stats.Score*100, stats.Margin*100,
+			performanceRating(stats.Score, 0, stats.Games), stats.LLR, verdict))
+	}
+	return nil
+}
@@ -0,0 +1,250 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"syscall/js"
+
+	"tic-tac-toe-3d-bots/bots"
+	"tic-tac-toe-3d-bots/formats"
+)
+
+// Compiled to WebAssembly, the program is only the engine: the board, the bots and their search,
+// without the terminal modes or the servers. Build it and load it with the wasm_exec.js of the Go distribution:
+//
+//	GOOS=js GOARCH=wasm go build -o engine.wasm ./cmd/tictactoe3d
+//	cp "$(go env GOROOT)/lib/wasm/wasm_exec.js" .
+//
+// It then defines a global tictactoe3d object; games are kept in the page, and states have the fields of the HTTP API's (see APIGameState):
+//
+//	tictactoe3d.newGame({board: {length: 4, width: 4, height: 4, win: 4}})  create a game; moves or a snapshot may be given too, as with POST /games
+//	tictactoe3d.state(id)                                                   the game's state
+//	tictactoe3d.legalMoves(id)                                              the legal moves, e.g. ["A1", "A2", ...]
+//	tictactoe3d.play(id, "B2")                                              play a move and return the new state
+//	tictactoe3d.botMove(id, "alphabeta:depth=6", 1000)                      a Promise of the state after the bot's move, thinking at most 1000 ms
+//	tictactoe3d.deleteGame(id)                                              forget the game
+//	tictactoe3d.addProfile("Box", {type: "remote", command: [url, bot]})    define a bot profile, such as a remote bot
+//	                                                                        searching on a server (see RemoteBot)
+//
+// Failed calls return {error: "..."}; a failed botMove rejects its Promise with an Error
+
+// wasmGames holds the games created from JavaScript
+type wasmGames struct {
+	mutex  sync.Mutex
+	games  map[int]*formats.GameRecord
+	nextID int
+}
+
+func main() {
+	games := &wasmGames{games: make(map[int]*formats.GameRecord), nextID: 1}
+	js.Global().Set("tictactoe3d", js.ValueOf(map[string]any{
+		"newGame":    wasmFunc(games.newGame),
+		"state":      wasmFunc(games.state),
+		"legalMoves": wasmFunc(games.legalMoves),
+		"play":       wasmFunc(games.play),
+		"botMove":    js.FuncOf(games.botMove),
+		"deleteGame": wasmFunc(games.deleteGame),
+		"addProfile": wasmFunc(addProfile),
+	}))
+	select {} // The functions are called from JavaScript for as long as the page lives
+}
+
+// wasmFunc adapts a function to JavaScript: its result is converted to a JavaScript value, and its error to {error: ...}
+func wasmFunc(function func(args []js.Value) (any, error)) js.Func {
+	return js.FuncOf(func(this js.Value, args []js.Value) any {
+		result, err := function(args)
+		if err != nil {
+			return js.ValueOf(map[string]any{"error": err.Error()})
+		}
+		return toJS(result)
+	})
+}
+
+// toJS converts a value through JSON, so it reaches JavaScript with the field names of the HTTP API
+func toJS(value any) js.Value {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": err.Error()})
+	}
+	return js.Global().Get("JSON").Call("parse", string(data))
+}
+
+// fromJS converts a JavaScript value into value through JSON; undefined and null leave value as it is
+func fromJS(source js.Value, value any) error {
+	if source.IsUndefined() || source.IsNull() {
+		return nil
+	}
+	decoder := json.NewDecoder(strings.NewReader(js.Global().Get("JSON").Call("stringify", source).String()))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(value); err != nil {
+		return fmt.Errorf("invalid argument: %v", err)
+	}
+	return nil
+}
+
+// argument returns args[i], or undefined if it was not passed
+func argument(args []js.Value, i int) js.Value {
+	if i < len(args) {
+		return args[i]
+	}
+	return js.Undefined()
+}
+
+// game returns the record of the game whose id is args[0]; the caller holds the mutex
+func (games *wasmGames) game(args []js.Value) (*formats.GameRecord, int, error) {
+	if id := argument(args, 0); id.Type() == js.TypeNumber {
+		if record, exists := games.games[id.Int()]; exists {
+			return record, id.Int(), nil
+		}
+		return nil, 0, fmt.Errorf("no game %d", id.Int())
+	}
+	return nil, 0, fmt.Errorf("expected a game id")
+}
+
+func (games *wasmGames) newGame(args []js.Value) (any, error) {
+	position := APIPosition{}
+	if err := fromJS(argument(args, 0), &position); err != nil {
+		return nil, err
+	}
+	record, err := position.record()
+	if err != nil {
+		return nil, err
+	}
+
+	games.mutex.Lock()
+	defer games.mutex.Unlock()
+	id := games.nextID
+	games.nextID++
+	games.games[id] = record
+	return apiState(id, record), nil
+}
+
+func (games *wasmGames) state(args []js.Value) (any, error) {
+	games.mutex.Lock()
+	defer games.mutex.Unlock()
+	record, id, err := games.game(args)
+	if err != nil {
+		return nil, err
+	}
+	return apiState(id, record), nil
+}
+
+func (games *wasmGames) legalMoves(args []js.Value) (any, error) {
+	games.mutex.Lock()
+	defer games.mutex.Unlock()
+	record, id, err := games.game(args)
+	if err != nil {
+		return nil, err
+	}
+	return apiState(id, record).LegalMoves, nil
+}
+
+func (games *wasmGames) play(args []js.Value) (any, error) {
+	games.mutex.Lock()
+	defer games.mutex.Unlock()
+	record, id, err := games.game(args)
+	if err != nil {
+		return nil, err
+	}
+	if argument(args, 1).Type() != js.TypeString {
+		return nil, fmt.Errorf("expected a move")
+	}
+	played, err := playMove(record, args[1].String())
+	if err != nil {
+		return nil, err
+	}
+	games.games[id] = played
+	return apiState(id, played), nil
+}
+
+func (games *wasmGames) deleteGame(args []js.Value) (any, error) {
+	games.mutex.Lock()
+	defer games.mutex.Unlock()
+	record, id, err := games.game(args)
+	if err != nil {
+		return nil, err
+	}
+	delete(games.games, id)
+	return apiState(id, record), nil
+}
+
+// addProfile defines the bot profile named args[0] with the configuration args[1], as a profiles file would
+func addProfile(args []js.Value) (any, error) {
+	name := argument(args, 0)
+	if name.Type() != js.TypeString {
+		return nil, fmt.Errorf("expected a profile name")
+	}
+	config := bots.BotConfig{}
+	if err := fromJS(argument(args, 1), &config); err != nil {
+		return nil, err
+	}
+	if err := addBotProfile(name.String(), config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// botMove returns a Promise of the game's state after the bot's move
+// The bot searches in a goroutine, as a JavaScript call must not block
+func (games *wasmGames) botMove(this js.Value, args []js.Value) any {
+	games.mutex.Lock()
+	record, id, err := games.game(args)
+	var position formats.GameRecord
+	if err == nil {
+		position = formats.GameRecord{Board: record.Board, Moves: append([]string{}, record.Moves...)}
+	}
+	games.mutex.Unlock()
+	if err != nil {
+		return js.Global().Get("Promise").Call("reject", js.Global().Get("Error").New(err.Error()))
+	}
+
+	spec, ms := "", 0
+	if bot := argument(args, 1); bot.Type() == js.TypeString {
+		spec = bot.String()
+	}
+	if limit := argument(args, 2); limit.Type() == js.TypeNumber {
+		ms = limit.Int()
+	}
+
+	var executor js.Func
+	executor = js.FuncOf(func(this js.Value, promise []js.Value) any {
+		resolve, reject := promise[0], promise[1]
+		go func() {
+			defer executor.Release()
+			state, err := games.playBotMove(id, &position, spec, ms)
+			if err != nil {
+				reject.Invoke(js.Global().Get("Error").New(err.Error()))
+				return
+			}
+			resolve.Invoke(toJS(state))
+		}()
+		return nil
+	})
+	return js.Global().Get("Promise").New(executor)
+}
+
+// playBotMove lets the bot described by spec play its move in game id, whose moves were copied to position
+func (games *wasmGames) playBotMove(id int, position *formats.GameRecord, spec string, ms int) (APIGameState, error) {
+	limit, err := timeLimit(ms)
+	if err != nil {
+		return APIGameState{}, err
+	}
+	move, err := botMove(position, spec, limit, context.Background())
+	if err != nil {
+		return APIGameState{}, err
+	}
+
+	games.mutex.Lock()
+	defer games.mutex.Unlock()
+	if current, exists := games.games[id]; !exists || len(current.Moves) != len(position.Moves) {
+		return APIGameState{}, fmt.Errorf("the game changed while the bot was thinking")
+	}
+	position.Moves = append(position.Moves, move)
+	games.games[id] = position
+	return apiState(id, position), nil
+}
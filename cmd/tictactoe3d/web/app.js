@@ -0,0 +1,310 @@
+// Browser frontend of the serve command: a game against a bot through the HTTP API,
+// with the position's evaluation streamed over the API's WebSockets
+
+"use strict";
+
+const game = {
+	id: 0,
+	state: null,      // the last APIGameState received
+	human: "x",       // the side the player plays
+	bot: "",          // bot spec
+	timeLimit: 2000,  // milliseconds per bot move and per analysis
+	botThinking: false,
+	moving: false,    // the player's move is on its way
+	live: null,       // WebSocket watching the game
+};
+
+let analysis = null; // WebSocket analysing the positions where the player is to move
+
+const $ = (id) => document.getElementById(id);
+
+// api sends a JSON request and returns the decoded answer, throwing the API's error message
+async function api(method, path, body) {
+	const response = await fetch(path, {
+		method,
+		headers: body === undefined ? {} : { "Content-Type": "application/json" },
+		body: body === undefined ? undefined : JSON.stringify(body),
+	});
+	const answer = await response.json();
+	if (!response.ok) {
+		throw new Error(answer.error || response.statusText);
+	}
+	return answer;
+}
+
+function socketURL(path) {
+	return (location.protocol === "https:" ? "wss://" : "ws://") + location.host + path;
+}
+
+function setStatus(text) {
+	$("status").textContent = text;
+}
+
+// parseBoard turns "4x4x4/4" into the API's board object
+function parseBoard(value) {
+	const [size, win] = value.split("/");
+	const [length, width, height] = size.split("x").map(Number);
+	return { length, width, height, win: Number(win) };
+}
+
+// columnName is the move dropping a piece into column i, row j, e.g. "B3"
+function columnName(i, j) {
+	return String.fromCharCode(65 + i) + (j + 1);
+}
+
+// pieces replays the moves of a state: grid[i][j][k] is "x", "o" or ""
+function pieces(state) {
+	const { length, width, height } = state.board;
+	const grid = [];
+	for (let i = 0; i < length; i++) {
+		grid.push([]);
+		for (let j = 0; j < width; j++) {
+			grid[i].push(new Array(height).fill(""));
+		}
+	}
+	let last = null;
+	state.moves.forEach((move, ply) => {
+		const i = move.charCodeAt(0) - 65;
+		const j = Number(move.slice(1)) - 1;
+		const k = grid[i][j].indexOf("");
+		grid[i][j][k] = ply % 2 === 0 ? "x" : "o";
+		last = [i, j, k];
+	});
+	return { grid, last };
+}
+
+// render draws every layer of the board, the top layer first, and the list of moves
+function render() {
+	const state = game.state;
+	const { grid, last } = pieces(state);
+	const legal = new Set(state.legal_moves);
+	const playable = !state.winner && state.next === game.human && !game.botThinking;
+	const { length, width, height } = state.board;
+
+	const layers = $("layers");
+	layers.replaceChildren();
+	for (let k = height - 1; k >= 0; k--) {
+		const table = document.createElement("table");
+		table.className = "layer";
+		table.createCaption().textContent = height > 1 ? "Layer " + (k + 1) : "Board";
+
+		const header = table.insertRow();
+		header.appendChild(document.createElement("th"));
+		for (let i = 0; i < length; i++) {
+			const th = document.createElement("th");
+			th.textContent = String.fromCharCode(65 + i);
+			header.appendChild(th);
+		}
+		for (let j = width - 1; j >= 0; j--) {
+			const row = table.insertRow();
+			const th = document.createElement("th");
+			th.textContent = j + 1;
+			row.appendChild(th);
+			for (let i = 0; i < length; i++) {
+				const cell = row.insertCell();
+				const piece = grid[i][j][k];
+				const move = columnName(i, j);
+				cell.textContent = piece;
+				cell.dataset.move = move;
+				if (piece) {
+					cell.classList.add(piece);
+				}
+				if (last && last[0] === i && last[1] === j && last[2] === k) {
+					cell.classList.add("last");
+				}
+				if (playable && legal.has(move)) {
+					cell.classList.add("playable");
+					if (grid[i][j].indexOf("") === k) {
+						cell.classList.add("drop");
+					}
+				}
+			}
+		}
+		layers.appendChild(table);
+	}
+
+	const moves = $("moves");
+	moves.replaceChildren();
+	for (const move of state.moves) {
+		const item = document.createElement("li");
+		item.textContent = move;
+		moves.appendChild(item);
+	}
+
+	if (state.winner === "draw") {
+		setStatus("Draw.");
+	} else if (state.winner) {
+		setStatus(state.winner === game.human ? "You win!" : "The bot wins.");
+	} else if (game.botThinking) {
+		setStatus("The bot is thinking…");
+	} else {
+		setStatus("Your move (" + game.human + ").");
+	}
+}
+
+// showEvaluation moves the eval bar to a search update for the side to move
+function showEvaluation(search, toMove) {
+	const xChance = toMove === "x" ? search.win_probability : 1 - search.win_probability;
+	$("eval-x").style.width = (100 * xChance).toFixed(1) + "%";
+	let text;
+	if (search.forced_winner) {
+		text = search.forced_winner + " wins by force";
+	} else {
+		text = "x " + Math.round(100 * xChance) + "% – o " + Math.round(100 * (1 - xChance)) + "%";
+	}
+	$("eval-text").textContent = text + " (depth " + search.depth + ")";
+	$("eval-line").textContent = search.line.join(" ");
+}
+
+function showResult(winner) {
+	$("eval-x").style.width = winner === "x" ? "100%" : winner === "o" ? "0%" : "50%";
+	$("eval-text").textContent = winner === "draw" ? "Draw" : winner + " won";
+	$("eval-line").textContent = "";
+}
+
+// update takes a new state of the game, from an answer or the live WebSocket
+function update(state) {
+	if (state.id !== game.id || (game.state && state.moves.length < game.state.moves.length)) {
+		return; // Another game, or older than what is shown
+	}
+	const changed = !game.state || state.moves.length !== game.state.moves.length;
+	game.state = state;
+	render();
+	if (!changed) {
+		return;
+	}
+
+	if (state.winner) {
+		showResult(state.winner);
+	} else if (state.next === game.human) {
+		analyse(state);
+	} else {
+		botMove();
+	}
+}
+
+// analyse asks live analysis to evaluate the position the player has to move in
+function analyse(state) {
+	if (!analysis || analysis.readyState !== WebSocket.OPEN) {
+		return;
+	}
+	analysis.send(JSON.stringify({ board: state.board, moves: state.moves, time_limit_ms: game.timeLimit }));
+}
+
+async function botMove() {
+	if (game.botThinking) {
+		return;
+	}
+	game.botThinking = true;
+	render();
+	const id = game.id;
+	try {
+		const state = await api("POST", "/games/" + id + "/bot-move", { bot: game.bot, time_limit_ms: game.timeLimit });
+		game.botThinking = false;
+		update(state);
+	} catch (error) {
+		game.botThinking = false;
+		if (id === game.id) {
+			render();
+			setStatus("Bot error: " + error.message);
+		}
+	}
+}
+
+async function play(move) {
+	const state = game.state;
+	if (!state || state.winner || state.next !== game.human || game.botThinking || game.moving) {
+		return;
+	}
+	game.moving = true;
+	try {
+		update(await api("POST", "/games/" + game.id + "/moves", { move }));
+	} catch (error) {
+		setStatus(error.message);
+	} finally {
+		game.moving = false;
+	}
+}
+
+// watch follows the game's WebSocket: states, and the bot's thinking for the eval bar
+function watch(id) {
+	const live = new WebSocket(socketURL("/games/" + id + "/live"));
+	live.onmessage = (message) => {
+		const event = JSON.parse(message.data);
+		if (id !== game.id) {
+			return;
+		}
+		if (event.type === "state") {
+			update(event.state);
+		} else if (event.type === "thinking" && game.state) {
+			showEvaluation(event.search, game.state.next);
+			$("eval-text").textContent += " – bot thinking";
+		}
+	};
+	return live;
+}
+
+async function newGame(event) {
+	event.preventDefault();
+	if (game.live) {
+		game.live.close();
+	}
+	if (game.id) {
+		api("DELETE", "/games/" + game.id).catch(() => {});
+	}
+	game.human = $("side").value;
+	game.bot = $("bot").value.trim();
+	game.timeLimit = Number($("time").value) || 2000;
+	game.state = null;
+	game.botThinking = false;
+	$("eval-x").style.width = "50%";
+	$("eval-text").textContent = "No evaluation yet";
+	$("eval-line").textContent = "";
+
+	try {
+		const state = await api("POST", "/games", { board: parseBoard($("board").value) });
+		game.id = state.id;
+		game.live = watch(state.id);
+		update(state);
+	} catch (error) {
+		setStatus(error.message);
+	}
+}
+
+function connectAnalysis() {
+	analysis = new WebSocket(socketURL("/analysis/live"));
+	analysis.onmessage = (message) => {
+		const event = JSON.parse(message.data);
+		// Analysis of an earlier position may still arrive while the bot thinks
+		if (event.type === "analysis" && game.state && !game.state.winner && game.state.next === game.human && !game.botThinking) {
+			showEvaluation(event.search, game.human);
+		}
+	};
+	analysis.onopen = () => {
+		if (game.state && !game.state.winner && game.state.next === game.human) {
+			analyse(game.state);
+		}
+	};
+	analysis.onclose = () => setTimeout(connectAnalysis, 2000);
+}
+
+$("settings").addEventListener("submit", newGame);
+$("layers").addEventListener("click", (event) => {
+	const cell = event.target.closest("td.playable");
+	if (cell) {
+		play(cell.dataset.move);
+	}
+});
+// Hovering a column highlights the cell its piece would land in
+$("layers").addEventListener("mouseover", (event) => {
+	const cell = event.target.closest("td.playable");
+	for (const hovered of document.querySelectorAll("td.hovered")) {
+		hovered.classList.remove("hovered");
+	}
+	if (cell) {
+		for (const drop of document.querySelectorAll('td.drop[data-move="' + cell.dataset.move + '"]')) {
+			drop.classList.add("hovered");
+		}
+	}
+});
+connectAnalysis();
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// WEBSOCKET_GUID is appended to the client's key to prove the server speaks WebSocket (RFC 6455)
+const WEBSOCKET_GUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// WEBSOCKET_MAX_MESSAGE bounds the messages clients may send; they only ever send small JSON objects
+const WEBSOCKET_MAX_MESSAGE = 64 << 10
+
+// WebSocket opcodes
+const (
+	wsText  = 0x1
+	wsClose = 0x8
+	wsPing  = 0x9
+	wsPong  = 0xA
+)
+
+// WebSocket is the server side of a WebSocket connection, exchanging JSON text messages
+// Writes may come from several goroutines; reads must come from one
+type WebSocket struct {
+	conn    net.Conn
+	reader  *bufio.Reader
+	writing sync.Mutex
+	closed  bool
+}
+
+// allowedOrigins are the hosts, besides the server's own, whose pages may open WebSockets; "*" allows every one
+// Set with --allow-origin
+var allowedOrigins []string
+
+// addOriginFlag declares the --allow-origin flag on fs
+func addOriginFlag(fs *flag.FlagSet) {
+	fs.Func("allow-origin", "comma-separated hosts, e.g. \"example.com,localhost:3000\", whose web pages may open WebSockets besides the server's own (\"*\" for any; default none)", func(value string) error {
+		allowedOrigins = nil
+		for _, host := range strings.Split(value, ",") {
+			if host = strings.TrimSpace(host); host != "" {
+				allowedOrigins = append(allowedOrigins, strings.ToLower(host))
+			}
+		}
+		return nil
+	})
+}
+
+// originAllowed reports whether the page that sent request may open a WebSocket: it must come from the host the
+// request was sent to, or one of allowedOrigins. Clients other than browsers send no Origin and are always allowed
+func originAllowed(request *http.Request) bool {
+	origin := request.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	parsed, err := url.Parse(origin)
+	if err != nil || parsed.Host == "" {
+		return false
+	}
+	host := strings.ToLower(parsed.Host)
+	return strings.EqualFold(host, request.Host) || slices.Contains(allowedOrigins, "*") || slices.Contains(allowedOrigins, host)
+}
+
+// upgradeWebSocket answers a WebSocket handshake, taking over the request's connection
+// Handshakes from the pages of other sites are refused (see originAllowed)
+func upgradeWebSocket(writer http.ResponseWriter, request *http.Request) (*WebSocket, error) {
+	key := request.Header.Get("Sec-Websocket-Key")
+	if !headerContains(request.Header, "Connection", "upgrade") || !headerContains(request.Header, "Upgrade", "websocket") || key == "" {
+		http.Error(writer, "expected a WebSocket handshake", http.StatusBadRequest)
+		return nil, errors.New("not a WebSocket handshake")
+	}
+	if !originAllowed(request) {
+		http.Error(writer, "origin not allowed", http.StatusForbidden)
+		return nil, fmt.Errorf("origin %q not allowed", request.Header.Get("Origin"))
+	}
+	if request.Header.Get("Sec-Websocket-Version") != "13" {
+		writer.Header().Set("Sec-Websocket-Version", "13")
+		http.Error(writer, "unsupported WebSocket version", http.StatusUpgradeRequired)
+		return nil, errors.New("unsupported WebSocket version")
+	}
+	hijacker, ok := writer.(http.Hijacker)
+	if !ok {
+		http.Error(writer, "WebSocket not supported", http.StatusInternalServerError)
+		return nil, errors.New("connection cannot be hijacked")
+	}
+	conn, buffered, err := hijacker.Hijack()
+	if err != nil {
+		return nil, err
+	}
+
+	hash := sha1.Sum([]byte(key + WEBSOCKET_GUID))
+	fmt.Fprintf(buffered, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n",
+		base64.StdEncoding.EncodeToString(hash[:]))
+	if err := buffered.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &WebSocket{conn: conn, reader: buffered.Reader}, nil
+}
+
+// headerContains reports whether a comma-separated header lists token, ignoring case
+func headerContains(header http.Header, name, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, field := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(field), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// writeFrame sends one unfragmented, unmasked frame
+func (socket *WebSocket) writeFrame(opcode byte, payload []byte) error {
+	socket.writing.Lock()
+	defer socket.writing.Unlock()
+	if socket.closed {
+		return net.ErrClosed
+	}
+
+	header := []byte{0x80 | opcode}
+	switch {
+	case len(payload) < 126:
+		header = append(header, byte(len(payload)))
+	case len(payload) <= 0xFFFF:
+		header = append(header, 126)
+		header = binary.BigEndian.AppendUint16(header, uint16(len(payload)))
+	default:
+		header = append(header, 127)
+		header = binary.BigEndian.AppendUint64(header, uint64(len(payload)))
+	}
+	if _, err := socket.conn.Write(append(header, payload...)); err != nil {
+		return err
+	}
+	if opcode == wsClose {
+		socket.closed = true
+	}
+	return nil
+}
+
+// WriteJSON sends value as one text message
+func (socket *WebSocket) WriteJSON(value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return socket.writeFrame(wsText, data)
+}
+
+// ReadMessage returns the next text or binary message, answering pings on the way
+// It returns io.EOF once the client closes the connection
+func (socket *WebSocket) ReadMessage() ([]byte, error) {
+	var message []byte
+	for {
+		final, opcode, payload, err := socket.readFrame()
+		if err != nil {
+			return nil, err
+		}
+		switch opcode {
+		case wsPing:
+			if err := socket.writeFrame(wsPong, payload); err != nil {
+				return nil, err
+			}
+			continue
+		case wsPong:
+			continue
+		case wsClose:
+			socket.writeFrame(wsClose, nil)
+			return nil, io.EOF
+		}
+
+		message = append(message, payload...)
+		if len(message) > WEBSOCKET_MAX_MESSAGE {
+			return nil, errors.New("WebSocket message too large")
+		}
+		if final {
+			return message, nil
+		}
+	}
+}
+
+// readFrame reads one frame; frames from clients are always masked
+func (socket *WebSocket) readFrame() (final bool, opcode byte, payload []byte, err error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(socket.reader, header); err != nil {
+		return false, 0, nil, err
+	}
+	final, opcode = header[0]&0x80 != 0, header[0]&0x0F
+	if header[1]&0x80 == 0 {
+		return false, 0, nil, errors.New("unmasked WebSocket frame from the client")
+	}
+
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		extended := make([]byte, 2)
+		if _, err := io.ReadFull(socket.reader, extended); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(extended))
+	case 127:
+		extended := make([]byte, 8)
+		if _, err := io.ReadFull(socket.reader, extended); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(extended)
+	}
+	if length > WEBSOCKET_MAX_MESSAGE {
+		return false, 0, nil, errors.New("WebSocket message too large")
+	}
+
+	mask := make([]byte, 4)
+	if _, err := io.ReadFull(socket.reader, mask); err != nil {
+		return false, 0, nil, err
+	}
+	payload = make([]byte, length)
+	if _, err := io.ReadFull(socket.reader, payload); err != nil {
+		return false, 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return final, opcode, payload, nil
+}
+
+// Close says goodbye to the client and closes the connection
+func (socket *WebSocket) Close() error {
+	socket.writeFrame(wsClose, nil)
+	return socket.conn.Close()
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+// webFiles is the browser frontend: a game against a bot through the HTTP API, with an eval bar fed by its WebSockets
+//
+//go:embed web
+var webFiles embed.FS
+
+// webUI serves the files of the web directory
+func webUI() http.Handler {
+	files, _ := fs.Sub(webFiles, "web") // web is embedded, so it always exists
+	return http.FileServerFS(files)
+}
@@ -0,0 +1,505 @@
+// Package engine implements the 3D Tic-Tac-Toe board: moves, wins, evaluation and position snapshots
+package engine
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sync"
+)
+
+// Board represents a 3D Tic-Tac-Toe board
+type Board struct {
+	Length         int
+	Width          int
+	Height         int
+	WinLength      int
+	Grid           [][][]byte
+	CurrentHeights [][]int      // Tracks the current height of each column [length][width]
+	LastMove       [3]int       // Stores the last move coordinates [x, y, z], or [-1, -1, -1] if no moves yet
+	Score          int          // Current board evaluation score (+ favors 'x', - favors 'o')
+	Evaluator      Evaluator    // How the score is computed; change it with SetEvaluator to keep the score in step
+	PlayerWin      byte         // Stores who wins: 'x', 'o', or '|' for no winner
+	threats        threatCounts // Kept in step with the board only while the evaluator has a parity term
+	forkLines      [][2]int     // By cell (see cellIndex): the lines two pieces short through it of 'x' and 'o'; nil without a fork bonus
+	forkCells      [2]int       // Empty cells on two or more of those lines, of 'x' and 'o'
+	deadLines      int          // Score of the lines left out of Score for needing more pieces than their player has moves left
+	zobrist        uint64       // See ZobristHash
+	moveNames      []string     // By column, col*Width+row: the name of its move; shared by every board of the size
+	lines          *boardLines  // The board's lines; shared by every board of the size
+	lineCounters   []uint32     // By line: the pieces of 'x' and 'o' on it, packed (see linePieces)
+	fixed          *fixedBoard  // Storage of the standard sizes, nil for others
+}
+
+// emptyBoard creates an empty board; the dimensions are trusted, see New for checked ones
+func emptyBoard(length, width, height, winLength int) *Board {
+	b := &Board{
+		Length:    length,
+		Width:     width,
+		Height:    height,
+		WinLength: winLength,
+		Score:     0, // Start with neutral score
+		Evaluator: DefaultEvaluator(),
+	}
+	b.Init()
+	return b
+}
+
+// Init initializes the board with empty markers
+func (b *Board) Init() {
+	b.lines = linesOf(b.Length, b.Width, b.Height, b.WinLength)
+	if !b.initFixed() {
+		b.initGeneral()
+	}
+
+	// Initialize last move to indicate no moves yet
+	b.LastMove = [3]int{-1, -1, -1}
+
+	// Initialize player win to no winner
+	b.PlayerWin = '|'
+
+	b.moveNames = moveNameTable(b.Length, b.Width)
+}
+
+// initGeneral allocates the grid, heights and line counters of a board of any size
+func (b *Board) initGeneral() {
+	// Initialize the 3D grid
+	b.Grid = make([][][]byte, b.Length)
+	for i := 0; i < b.Length; i++ {
+		b.Grid[i] = make([][]byte, b.Width)
+		for j := 0; j < b.Width; j++ {
+			b.Grid[i][j] = make([]byte, b.Height)
+			for k := 0; k < b.Height; k++ {
+				b.Grid[i][j][k] = '|'
+			}
+		}
+	}
+
+	// Initialize the height tracking array
+	b.CurrentHeights = make([][]int, b.Length)
+	for i := 0; i < b.Length; i++ {
+		b.CurrentHeights[i] = make([]int, b.Width)
+		// Heights start at 0 (all columns are empty)
+	}
+
+	b.lineCounters = make([]uint32, len(b.lines.starts))
+}
+
+// CopyBoard creates a deep copy of the board for testing moves
+func CopyBoard(original *Board) *Board {
+	// Create new board with same dimensions and evaluator
+	newBoard := emptyBoard(original.Length, original.Width, original.Height, original.WinLength)
+	newBoard.Evaluator = original.Evaluator
+
+	if newBoard.fixed != nil {
+		newBoard.copyFixed(original)
+	} else {
+		// Copy the grid state
+		for i := 0; i < original.Length; i++ {
+			for j := 0; j < original.Width; j++ {
+				copy(newBoard.Grid[i][j], original.Grid[i][j])
+			}
+		}
+
+		// Copy the height tracking
+		for i := 0; i < original.Length; i++ {
+			copy(newBoard.CurrentHeights[i], original.CurrentHeights[i])
+		}
+		copy(newBoard.lineCounters, original.lineCounters)
+	}
+
+	// Copy last move, score, and player win
+	newBoard.LastMove = original.LastMove
+	newBoard.Score = original.Score
+	newBoard.PlayerWin = original.PlayerWin
+	newBoard.threats = original.threats
+	newBoard.deadLines = original.deadLines
+	newBoard.zobrist = original.zobrist
+	if original.forkLines != nil {
+		newBoard.forkLines = append([][2]int(nil), original.forkLines...)
+		newBoard.forkCells = original.forkCells
+	}
+
+	return newBoard
+}
+
+// OpponentSymbol returns the symbol of the other player
+func OpponentSymbol(symbol byte) byte {
+	if symbol == 'x' {
+		return 'o'
+	}
+	return 'x'
+}
+
+// ParseMove extracts column and row from move string (e.g., "A1" -> col=0, row=0)
+// Returns (-1, -1) if the move string is invalid
+func ParseMove(moveStr string) (int, int) {
+	if len(moveStr) < 2 {
+		return -1, -1
+	}
+
+	// Get column
+	col := int(moveStr[0]) - int('A')
+
+	// Get row
+	row := 0
+	for i := 1; i < len(moveStr); i++ {
+		if moveStr[i] < '0' || moveStr[i] > '9' {
+			return -1, -1
+		}
+		row = row*10 + int(moveStr[i]-'0')
+	}
+	row-- // Convert from 1-based to 0-based indexing
+
+	return col, row
+}
+
+// MoveName returns the name of the move in the given column and row (e.g., col=0, row=0 -> "A1"), as ParseMove reads it
+// Boards name their own moves from a table instead (see Board.MoveName)
+func MoveName(col, row int) string {
+	return fmt.Sprintf("%c%d", 'A'+byte(col), row+1)
+}
+
+// moveNameTables holds the move name table of every board size met so far, by [Length, Width]
+var moveNameTables sync.Map
+
+// moveNameTable returns the names of the moves of boards of the given length and width, by column (col*width+row),
+// building them the first time the size is met
+func moveNameTable(length, width int) []string {
+	if names, ok := moveNameTables.Load([2]int{length, width}); ok {
+		return names.([]string)
+	}
+	names := make([]string, length*width)
+	for col := 0; col < length; col++ {
+		for row := 0; row < width; row++ {
+			names[col*width+row] = MoveName(col, row)
+		}
+	}
+	stored, _ := moveNameTables.LoadOrStore([2]int{length, width}, names)
+	return stored.([]string)
+}
+
+// MoveName returns the name of the move in the given column and row of the board, without allocating
+func (b *Board) MoveName(col, row int) string {
+	return b.moveNames[col*b.Width+row]
+}
+
+// Move places a player's piece at the specified position
+// Returns the coordinates where the piece was placed as [3]int, or [-1, -1, -1] if invalid
+func (b *Board) Move(moveStr string, player byte) [3]int {
+	col, row := ParseMove(moveStr)
+	return b.MoveAt(col, row, player)
+}
+
+// MoveAt is Move for a move given by its column and row, sparing searches the parsing of move names
+func (b *Board) MoveAt(col, row int, player byte) [3]int {
+	if col < 0 || col >= b.Length || row < 0 || row >= b.Width {
+		return [3]int{-1, -1, -1}
+	}
+
+	// Try placing the block
+	currentHeight := b.CurrentHeights[col][row]
+	if currentHeight >= b.Height {
+		return [3]int{-1, -1, -1}
+	}
+
+	// Place the piece first
+	b.Grid[col][row][currentHeight] = player
+	b.CurrentHeights[col][row]++
+	b.zobrist ^= zobristKey(b.cellIndex(col, row, currentHeight), player)
+	b.countPiece(col, row, currentHeight, player, 1)
+	if b.fixed != nil {
+		b.fixed.occupied |= 1 << b.cellIndex(col, row, currentHeight)
+	}
+	b.LastMove = [3]int{col, row, currentHeight}
+
+	// Calculate score delta after placing the piece and update win status
+	delta, threats := b.deltaEvaluate(col, row, currentHeight, true)
+
+	// Update the board's score with the delta
+	b.Score += delta
+	if b.Evaluator.Parity != 0 {
+		mover := b.NextPlayer()
+		b.setThreats(b.threats.plus(threats, 1), OpponentSymbol(mover), mover)
+	}
+	if b.forkLines != nil {
+		b.updateForks(col, row, currentHeight, 1)
+	}
+	b.updateDeadLines()
+
+	return b.LastMove
+}
+
+// UnMove reverses a move at the given position by removing the topmost piece
+// and updating the score accordingly
+func (b *Board) UnMove(moveStr string) [3]int {
+	col, row := ParseMove(moveStr)
+	return b.UnMoveAt(col, row)
+}
+
+// UnMoveAt is UnMove for a move given by its column and row
+func (b *Board) UnMoveAt(col, row int) [3]int {
+	if col < 0 || col >= b.Length || row < 0 || row >= b.Width {
+		return [3]int{-1, -1, -1}
+	}
+
+	// Check if there's a piece to remove
+	currentHeight := b.CurrentHeights[col][row]
+	if currentHeight <= 0 {
+		return [3]int{-1, -1, -1}
+	}
+
+	// Get the height of the topmost piece (0-based)
+	topHeight := currentHeight - 1
+
+	// Calculate the delta before removing the piece (don't update win status)
+	delta, threats := b.deltaEvaluate(col, row, topHeight, false)
+	mover := b.NextPlayer()
+	if b.forkLines != nil {
+		b.updateForks(col, row, topHeight, -1)
+	}
+
+	// Remove the piece
+	b.zobrist ^= zobristKey(b.cellIndex(col, row, topHeight), b.Grid[col][row][topHeight])
+	b.countPiece(col, row, topHeight, b.Grid[col][row][topHeight], -1)
+	if b.fixed != nil {
+		b.fixed.occupied &^= 1 << b.cellIndex(col, row, topHeight)
+	}
+	b.Grid[col][row][topHeight] = '|'
+	b.CurrentHeights[col][row]--
+
+	// Reverse the score delta and reset win status
+	b.Score -= delta
+	b.PlayerWin = '|'
+	if b.Evaluator.Parity != 0 {
+		b.setThreats(b.threats.plus(threats, -1), mover, OpponentSymbol(mover))
+	}
+	b.updateDeadLines()
+
+	return [3]int{col, row, topHeight}
+}
+
+// IsValidCoordinate checks if the given coordinates are within board bounds
+func (b *Board) IsValidCoordinate(x, y, z int) bool {
+	return x >= 0 && x < b.Length && y >= 0 && y < b.Width && z >= 0 && z < b.Height
+}
+
+// GetLine returns a line of pieces starting from a position in a given direction
+func (b *Board) GetLine(start [3]int, direction [3]int) []byte {
+	line := make([]byte, b.WinLength)
+	for i := 0; i < b.WinLength; i++ {
+		x := start[0] + i*direction[0]
+		y := start[1] + i*direction[1]
+		z := start[2] + i*direction[2]
+		if !b.IsValidCoordinate(x, y, z) {
+			// Return a slice filled with invalid markers
+			invalidLine := make([]byte, b.WinLength)
+			for j := range invalidLine {
+				invalidLine[j] = '|'
+			}
+			return invalidLine
+		}
+		line[i] = b.Grid[x][y][z]
+	}
+	return line
+}
+
+// CheckWin returns the current winner stored in PlayerWin field
+// Returns 'x' if player X wins, 'o' if player O wins, or '|' if no winner
+func (b *Board) CheckWin() byte {
+	return b.PlayerWin
+}
+
+// GetValidMoves returns a slice of all valid move positions
+// Searches walk them with EachValidMove instead, which names no moves and allocates nothing
+func (b *Board) GetValidMoves() []string {
+	validMoves := make([]string, 0, b.Length*b.Width)
+	b.EachValidMove(func(col, row int) bool {
+		validMoves = append(validMoves, b.MoveName(col, row))
+		return true
+	})
+	return validMoves
+}
+
+// EachValidMove calls visit with the column and row of every valid move, in the order of GetValidMoves, until it
+// returns false. visit may play a move on the board, as long as it takes it back before returning
+func (b *Board) EachValidMove(visit func(col, row int) bool) {
+	for i := 0; i < b.Length; i++ {
+		for j := 0; j < b.Width; j++ {
+			if b.CurrentHeights[i][j] < b.Height && !visit(i, j) {
+				return
+			}
+		}
+	}
+}
+
+// IsFull checks if the board is completely filled
+func (b *Board) IsFull() bool {
+	if b.fixed != nil {
+		return b.fixedIsFull()
+	}
+	full := true
+	b.EachValidMove(func(col, row int) bool {
+		full = false
+		return false
+	})
+	return full
+}
+
+// MoveCount returns the number of pieces on the board
+func (b *Board) MoveCount() int {
+	if b.fixed != nil {
+		return b.fixedMoveCount()
+	}
+	count := 0
+	for i := 0; i < b.Length; i++ {
+		for j := 0; j < b.Width; j++ {
+			count += b.CurrentHeights[i][j]
+		}
+	}
+	return count
+}
+
+// NextPlayer returns the symbol to move next, given that 'x' moves first
+func (b *Board) NextPlayer() byte {
+	if b.MoveCount()%2 == 0 {
+		return 'x'
+	}
+	return 'o'
+}
+
+// PositionHash returns a 64-bit FNV-1a hash of the board's dimensions and pieces
+// Boards reached by different move orders hash alike
+func (b *Board) PositionHash() uint64 {
+	hash := fnv.New64a()
+	hash.Write([]byte{byte(b.Length), byte(b.Width), byte(b.Height), byte(b.WinLength)})
+	for i := 0; i < b.Length; i++ {
+		for j := 0; j < b.Width; j++ {
+			hash.Write(b.Grid[i][j])
+		}
+	}
+	return hash.Sum64()
+}
+
+// Evaluate calculates the full board evaluation score
+// + is good for 'x', - is good for 'o'
+func (b *Board) Evaluate() int {
+	score := 0
+	b.threats = threatCounts{}
+	b.forkLines, b.forkCells = nil, [2]int{}
+	if b.Evaluator.Forks != 0 {
+		b.forkLines = make([][2]int, b.Length*b.Width*b.Height)
+	}
+	if b.fixed != nil {
+		b.tabulateScores()
+	}
+
+	for line, direction := range b.lines.directions {
+		xCount, oCount, fillers := b.lineCounts(int32(line))
+		score += b.lineScore(direction.class, xCount, oCount, fillers)
+		b.threats.count(xCount, oCount, fillers, b.WinLength, 1)
+		if b.forkLines != nil {
+			b.countForkLine(b.lines.starts[line], direction, xCount, oCount, [3]int{-1, -1, -1}, 1)
+		}
+	}
+
+	score += b.Evaluator.parityScore(b.threats, b.NextPlayer(), b.WinLength) + b.forkScore()
+	b.deadLines = b.deadScore()
+	score -= b.deadLines
+	b.Score = score // Update the board's score
+	return score
+}
+
+// DeltaEvaluate calculates the change in evaluation score for a piece at the given coordinates
+// The piece must already be placed on the board. This is much more efficient than recalculating the entire board
+// If updateWin is true, it will check for and update the PlayerWin field when a win is detected
+// The parity term, which changes with the turn, is left out: Move and UnMove add it
+func (b *Board) DeltaEvaluate(x, y, z int, updateWin bool) int {
+	delta, _ := b.deltaEvaluate(x, y, z, updateWin)
+	return delta
+}
+
+// deltaEvaluate is DeltaEvaluate, also returning the change in the board's threats
+func (b *Board) deltaEvaluate(x, y, z int, updateWin bool) (int, threatCounts) {
+	var threats threatCounts
+
+	// Get the symbol of the piece at this position
+	symbol := b.Grid[x][y][z]
+	delta := 0
+
+	// Check every line that passes through this position
+	for _, line := range b.lines.through[b.cellIndex(x, y, z)] {
+		class := b.lines.directions[line].class
+
+		// Get the current line (with the piece already placed)
+		// The piece fills its column up to its own cell, so the line needs as many filler pieces as before it
+		xCountAfter, oCountAfter, fillers := b.lineCounts(line)
+
+		// Check for winning conditions and update PlayerWin if requested
+		if updateWin && xCountAfter == b.WinLength && oCountAfter == 0 {
+			b.PlayerWin = 'x'
+		} else if updateWin && oCountAfter == b.WinLength && xCountAfter == 0 {
+			b.PlayerWin = 'o'
+		}
+
+		// Calculate score contribution with the piece
+		scoreAfter := b.lineScore(class, xCountAfter, oCountAfter, fillers)
+
+		// Calculate what the counts were before the move
+		var xCountBefore, oCountBefore int
+		if symbol == 'x' {
+			xCountBefore = xCountAfter - 1
+			oCountBefore = oCountAfter
+		} else if symbol == 'o' {
+			xCountBefore = xCountAfter
+			oCountBefore = oCountAfter - 1
+		} else {
+			// Invalid symbol, skip this calculation
+			continue
+		}
+		threats.count(xCountAfter, oCountAfter, fillers, b.WinLength, 1)
+		threats.count(xCountBefore, oCountBefore, fillers, b.WinLength, -1)
+
+		// Calculate score contribution before the move
+		scoreBefore := b.lineScore(class, xCountBefore, oCountBefore, fillers)
+
+		// Add the delta for this line
+		delta += scoreAfter - scoreBefore
+	}
+
+	// The piece is also one filler fewer for the lines through the empty cells above it, bar the column's own
+	if b.Evaluator.Playability == 100 && b.Evaluator.Parity == 0 {
+		return delta, threats // Filler pieces count for nothing
+	}
+	for above := z + 1; above < b.Height; above++ {
+		for _, line := range b.lines.through[b.cellIndex(x, y, above)] {
+			class := b.lines.directions[line].class
+			if class == VERTICAL_LINES {
+				continue
+			}
+			xCount, oCount, fillers := b.lineCounts(line)
+			delta += b.lineScore(class, xCount, oCount, fillers) -
+				b.lineScore(class, xCount, oCount, fillers+1)
+			threats.count(xCount, oCount, fillers, b.WinLength, 1)
+			threats.count(xCount, oCount, fillers+1, b.WinLength, -1)
+		}
+	}
+
+	return delta, threats
+}
+
+// setThreats replaces the board's threats as the turn passes from moverBefore to moverAfter, updating the parity term
+func (b *Board) setThreats(threats threatCounts, moverBefore, moverAfter byte) {
+	b.Score += b.Evaluator.parityScore(threats, moverAfter, b.WinLength) - b.Evaluator.parityScore(b.threats, moverBefore, b.WinLength)
+	b.threats = threats
+}
+
+// CountBytes counts how many times target appears in the byte slice
+func CountBytes(bytes []byte, target byte) int {
+	count := 0
+	for _, b := range bytes {
+		if b == target {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,70 @@
+package engine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MAX_BOARD_DIMENSION is the largest board length or width (columns are lettered A-Z)
+const MAX_BOARD_DIMENSION = 26
+
+// MAX_BOARD_HEIGHT is the tallest board; boards also come from untrusted input, such as API requests
+const MAX_BOARD_HEIGHT = 64
+
+// MAX_BOARD_CELLS bounds the cells of a board, so that one board, with its line tables, cannot exhaust memory
+const MAX_BOARD_CELLS = 4096
+
+// BoardConfig describes the board dimensions
+type BoardConfig struct {
+	Length int `json:"length"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+	Win    int `json:"win"`
+}
+
+// ValidateBoardDimensions checks that a board configuration is playable
+func ValidateBoardDimensions(length, width, height, winLength int) error {
+	if length < 1 || width < 1 || height < 1 || winLength < 1 {
+		return fmt.Errorf("board dimensions and win length must be positive")
+	}
+	if length > MAX_BOARD_DIMENSION || width > MAX_BOARD_DIMENSION {
+		return fmt.Errorf("board length and width cannot exceed %d", MAX_BOARD_DIMENSION)
+	}
+	if height > MAX_BOARD_HEIGHT {
+		return fmt.Errorf("board height cannot exceed %d", MAX_BOARD_HEIGHT)
+	}
+	if length*width*height > MAX_BOARD_CELLS {
+		return fmt.Errorf("a %dx%dx%d board has more than %d cells", length, width, height, MAX_BOARD_CELLS)
+	}
+	if winLength > length && winLength > width && winLength > height {
+		return fmt.Errorf("win length %d does not fit on a %dx%dx%d board", winLength, length, width, height)
+	}
+	return nil
+}
+
+// ParseBoardConfig parses a board such as "4x4x4" or "5x5x5/4" (four in a row)
+func ParseBoardConfig(value string) (*BoardConfig, error) {
+	dimensions, win, hasWin := strings.Cut(strings.ToLower(strings.TrimSpace(value)), "/")
+	sizes := strings.Split(dimensions, "x")
+	if len(sizes) != 3 {
+		return nil, fmt.Errorf("invalid board %q (expected LxWxH or LxWxH/win, e.g. 4x4x4)", value)
+	}
+
+	board := &BoardConfig{}
+	for i, field := range []*int{&board.Length, &board.Width, &board.Height} {
+		size, err := strconv.Atoi(sizes[i])
+		if err != nil || size <= 0 {
+			return nil, fmt.Errorf("invalid board %q (expected LxWxH or LxWxH/win, e.g. 4x4x4)", value)
+		}
+		*field = size
+	}
+	if hasWin {
+		size, err := strconv.Atoi(win)
+		if err != nil || size <= 0 {
+			return nil, fmt.Errorf("invalid win length in board %q", value)
+		}
+		board.Win = size
+	}
+	return board, nil
+}
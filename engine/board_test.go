@@ -0,0 +1,67 @@
+package engine
+
+import "testing"
+
+func TestMoveNameTable(t *testing.T) {
+	tests := []struct {
+		length, width int
+		names         map[[2]int]string // by [col, row]
+	}{
+		{3, 3, map[[2]int]string{{0, 0}: "A1", {1, 2}: "B3", {2, 2}: "C3"}},
+		{4, 4, map[[2]int]string{{0, 0}: "A1", {3, 0}: "D1", {0, 3}: "A4", {3, 3}: "D4"}},
+		{5, 2, map[[2]int]string{{4, 1}: "E2", {2, 0}: "C1"}},
+		{2, 12, map[[2]int]string{{0, 9}: "A10", {1, 11}: "B12"}},
+		{1, 1, map[[2]int]string{{0, 0}: "A1"}},
+	}
+	for _, test := range tests {
+		names := moveNameTable(test.length, test.width)
+		if len(names) != test.length*test.width {
+			t.Fatalf("%dx%d: %d names, want %d", test.length, test.width, len(names), test.length*test.width)
+		}
+		for cell, want := range test.names {
+			if got := names[cell[0]*test.width+cell[1]]; got != want {
+				t.Errorf("%dx%d: name of column %d, row %d is %q, want %q", test.length, test.width, cell[0], cell[1], got, want)
+			}
+		}
+		for col := 0; col < test.length; col++ {
+			for row := 0; row < test.width; row++ {
+				name := names[col*test.width+row]
+				if name != MoveName(col, row) {
+					t.Errorf("%dx%d: table names column %d, row %d %q, MoveName %q", test.length, test.width, col, row, name, MoveName(col, row))
+				}
+				if parsedCol, parsedRow := ParseMove(name); parsedCol != col || parsedRow != row {
+					t.Errorf("%dx%d: %q parses as column %d, row %d, want %d, %d", test.length, test.width, name, parsedCol, parsedRow, col, row)
+				}
+			}
+		}
+		if again := moveNameTable(test.length, test.width); &again[0] != &names[0] {
+			t.Errorf("%dx%d: the table is built again instead of shared", test.length, test.width)
+		}
+	}
+}
+
+func TestBoardMoveName(t *testing.T) {
+	tests := []struct {
+		config BoardConfig
+		col    int
+		row    int
+		want   string
+	}{
+		{BoardConfig{Length: 3, Width: 3, Height: 3, Win: 3}, 2, 1, "C2"},
+		{BoardConfig{Length: 4, Width: 4, Height: 4, Win: 4}, 3, 3, "D4"},
+		{BoardConfig{Length: 7, Width: 6, Height: 4, Win: 4}, 6, 5, "G6"},
+		{BoardConfig{Length: 4, Width: 10, Height: 2, Win: 2}, 1, 9, "B10"},
+	}
+	for _, test := range tests {
+		board, err := New(WithConfig(test.config))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := board.MoveName(test.col, test.row); got != test.want {
+			t.Errorf("%v: MoveName(%d, %d) = %q, want %q", test.config, test.col, test.row, got, test.want)
+		}
+		if allocs := testing.AllocsPerRun(100, func() { board.MoveName(test.col, test.row) }); allocs != 0 {
+			t.Errorf("%v: MoveName allocates %v times", test.config, allocs)
+		}
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package engine
 
 // Common constants used throughout the application
 const (
@@ -0,0 +1,314 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+)
+
+// DirectionClass groups the directions a line of the board can run in
+type DirectionClass int
+
+const (
+	HORIZONTAL_LINES  DirectionClass = iota // along a row or a column of a layer
+	VERTICAL_LINES                          // up a column, which gravity fills from the bottom
+	PLANAR_LINES                            // diagonals within a plane
+	SPACE_LINES                             // diagonals through all three dimensions
+	DIRECTION_CLASSES                       // number of direction classes
+)
+
+// String names the class as the bots' weight parameters do
+func (class DirectionClass) String() string {
+	switch class {
+	case HORIZONTAL_LINES:
+		return "horizontal"
+	case VERTICAL_LINES:
+		return "vertical"
+	case PLANAR_LINES:
+		return "planar"
+	case SPACE_LINES:
+		return "space"
+	}
+	return fmt.Sprintf("DirectionClass(%d)", int(class))
+}
+
+// lineDirection is a direction lines are searched in, with its class
+type lineDirection struct {
+	step  [3]int
+	class DirectionClass
+}
+
+// lineDirections holds one of each pair of opposite directions
+var lineDirections = []lineDirection{
+	{[3]int{1, 0, 0}, HORIZONTAL_LINES}, {[3]int{0, 1, 0}, HORIZONTAL_LINES},
+	{[3]int{0, 0, 1}, VERTICAL_LINES},
+	{[3]int{1, 1, 0}, PLANAR_LINES}, {[3]int{1, -1, 0}, PLANAR_LINES}, {[3]int{1, 0, 1}, PLANAR_LINES},
+	{[3]int{1, 0, -1}, PLANAR_LINES}, {[3]int{0, 1, 1}, PLANAR_LINES}, {[3]int{0, 1, -1}, PLANAR_LINES},
+	{[3]int{1, 1, 1}, SPACE_LINES}, {[3]int{1, -1, -1}, SPACE_LINES}, {[3]int{1, 1, -1}, SPACE_LINES},
+	{[3]int{1, -1, 1}, SPACE_LINES},
+}
+
+// DEFAULT_DIRECTION_WEIGHTS are the weights of the direction classes unless an Evaluator says otherwise: every class
+// alike, as measured with the tune command (alphabeta:depth=4 on 4x4x4, 4 random plies, SPRT [0, 10], 2000 games at
+// most). Columns weighted 100 beat the 150 they had by 33 Elo (H1 after 1224 games; by 77 Elo at depth 5, H1 after
+// 486), and 250 to 400 lost; against that, planar lines at 50 to 200 and space lines at 50 and 75 lost, and space
+// lines at 125 to 200 were within 5 Elo after 2000 games each:
+//
+//	tictactoe3d tune --param vertical --values 50,100,125,175,200,250,300,400
+//	tictactoe3d tune --bot alphabeta:depth=5 --param vertical --values 100
+//	tictactoe3d tune --bot alphabeta:depth=4,vertical=100 --param planar --values 50,75,125,150,200
+//	tictactoe3d tune --bot alphabeta:depth=4,vertical=100 --param space --values 50,75,125,150,200
+var DEFAULT_DIRECTION_WEIGHTS = [DIRECTION_CLASSES]int{100, 100, 100, 100}
+
+// DEFAULT_PLAYABILITY is the percent of a line's score kept for each filler piece it needs unless an Evaluator says
+// otherwise: 100, leaving the height of empty cells out, as every lower value measured with the tune command (as for
+// DEFAULT_DIRECTION_WEIGHTS) lost to it, the more the lower, from 34 Elo for 90 to 164 for 25, each by SPRT H0. A
+// threat over an empty cell is no less dangerous for being out of reach when whoever plays under it hands it to its
+// owner:
+//
+//	tictactoe3d tune --param playability --values 25,50,75,90
+const DEFAULT_PLAYABILITY = 100
+
+// DEFAULT_FORKS is the fork bonus unless an Evaluator says otherwise: off, as every bonus measured with the tune
+// command (as for DEFAULT_DIRECTION_WEIGHTS) lost to none, from 21 Elo for 2 to 239 for 50, each by SPRT H0. Cells on
+// two lines half filled by the same player abound, and the search finds the forks that can be played now by itself:
+//
+//	tictactoe3d tune --param forks --values 1,2,5,10,25,50
+const DEFAULT_FORKS = 0
+
+// DEFAULT_PARITY is the parity term unless an Evaluator says otherwise: off, as it only tells apart the threats of
+// endgames where players fill the last columns in turn, which games between searching bots seldom come down to.
+// Measured with the tune command, 25 to 200 lost to it by 26 to 67 Elo, each by SPRT H0, and 10 drew level with it,
+// +4 Elo after 2000 games, undecided, too little to be worth the term:
+//
+//	tictactoe3d tune --param parity --values 10,25,50,100,200
+const DEFAULT_PARITY = 0
+
+// Evaluator configures Evaluate: every line still open to only one player scores Base to the power of that
+// player's pieces on it, times the weight of its direction class in percent. A line needing more pieces than its
+// player has moves left before the board is full is dead, and scores nothing
+// A line whose empty cells float above their columns needs filler pieces played under them before it can be
+// completed; Playability is the percent of its score kept for each, so that a threat that can be completed now
+// outweighs one needing the columns under it filled first (100 ignores the height of the cells)
+//
+// Parity scores the endgames of gravity, where players fill the last columns in turn: the cell over an even number
+// of empty cells falls to the player to move, and the one over an odd number to the other, who can answer every
+// move in the same column. A line one piece short of completion whose empty cell falls to its owner is a threat the
+// other cannot stop by waiting, and scores Parity percent of Base to the power of its pieces on top of the line's
+// own score (0 leaves it out)
+//
+// Forks scores every empty cell lying on two or more lines two pieces short of completion and open to the same
+// player, who makes two threats at once by playing it; each counts Forks percent of a completed line's score
+// (0 leaves them out)
+type Evaluator struct {
+	Base        int
+	Weights     [DIRECTION_CLASSES]int
+	Playability int
+	Parity      int
+	Forks       int
+}
+
+// DefaultEvaluator returns the evaluator of new boards
+func DefaultEvaluator() Evaluator {
+	return Evaluator{Base: DEFAULT_EVALUATION_BASE, Weights: DEFAULT_DIRECTION_WEIGHTS, Playability: DEFAULT_PLAYABILITY,
+		Parity: DEFAULT_PARITY, Forks: DEFAULT_FORKS}
+}
+
+// Validate checks that the base is at least 2, no weight, parity or fork bonus is negative, and the playability is a percent
+func (evaluator Evaluator) Validate() error {
+	if evaluator.Base < 2 {
+		return fmt.Errorf("evaluation base must be at least 2, got %d", evaluator.Base)
+	}
+	for class, weight := range evaluator.Weights {
+		if weight < 0 {
+			return fmt.Errorf("%s line weight must not be negative, got %d", DirectionClass(class), weight)
+		}
+	}
+	if evaluator.Playability < 0 || evaluator.Playability > 100 {
+		return fmt.Errorf("playability must be between 0 and 100, got %d", evaluator.Playability)
+	}
+	if evaluator.Parity < 0 {
+		return fmt.Errorf("parity must not be negative, got %d", evaluator.Parity)
+	}
+	if evaluator.Forks < 0 {
+		return fmt.Errorf("fork bonus must not be negative, got %d", evaluator.Forks)
+	}
+	return nil
+}
+
+// lineScore scores a line of the given class holding xCount and oCount pieces and needing fillers filler pieces
+// (+ favors 'x', - favors 'o')
+func (evaluator Evaluator) lineScore(class DirectionClass, xCount, oCount, fillers, winLength int) int {
+	score := 0
+	if xCount > 0 && oCount == 0 && xCount <= winLength {
+		score = int(math.Pow(float64(evaluator.Base), float64(xCount)))
+	} else if oCount > 0 && xCount == 0 && oCount <= winLength {
+		score = -int(math.Pow(float64(evaluator.Base), float64(oCount)))
+	}
+	score = score * evaluator.Weights[class] / 100
+	for ; fillers > 0 && score != 0 && evaluator.Playability != 100; fillers-- {
+		score = score * evaluator.Playability / 100
+	}
+	return score
+}
+
+// threatCounts counts the lines one piece short of completion by owner ('x' then 'o') and by the parity of the
+// number of empty cells under their last cell
+type threatCounts [2][2]int
+
+// count adds sign times a line holding xCount and oCount pieces with fillers empty cells under it, if it is a threat
+func (threats *threatCounts) count(xCount, oCount, fillers, winLength, sign int) {
+	switch {
+	case winLength < 2:
+		return // Every empty line would be a threat of both players
+	case xCount == winLength-1 && oCount == 0:
+		threats[0][fillers%2] += sign
+	case oCount == winLength-1 && xCount == 0:
+		threats[1][fillers%2] += sign
+	}
+}
+
+// plus returns the threats with sign times change added
+func (threats threatCounts) plus(change threatCounts, sign int) threatCounts {
+	for owner := range threats {
+		for parity := range threats[owner] {
+			threats[owner][parity] += sign * change[owner][parity]
+		}
+	}
+	return threats
+}
+
+// parityScore is the parity term of a board with the given threats when mover is to move (+ favors 'x', - favors 'o')
+func (evaluator Evaluator) parityScore(threats threatCounts, mover byte, winLength int) int {
+	if evaluator.Parity == 0 {
+		return 0
+	}
+	unit := int(math.Pow(float64(evaluator.Base), float64(winLength-1))) * evaluator.Parity / 100
+	if mover == 'x' {
+		return unit * (threats[0][0] - threats[1][1]) // x's threats over even cells fall to x, o's over odd to o
+	}
+	return unit * (threats[0][1] - threats[1][0])
+}
+
+// forkScore is the fork term of the board (+ favors 'x', - favors 'o')
+func (b *Board) forkScore() int {
+	if b.forkLines == nil {
+		return 0
+	}
+	unit := int(math.Pow(float64(b.Evaluator.Base), float64(b.WinLength))) * b.Evaluator.Forks / 100
+	return unit * (b.forkCells[0] - b.forkCells[1])
+}
+
+// countForkLine adds sign to the fork counts of the empty cells of a line holding xCount and oCount pieces, if it is
+// two pieces short of completion; vacated is a cell to count as empty although it is not, or {-1, -1, -1}
+func (b *Board) countForkLine(start [3]int, direction lineDirection, xCount, oCount int, vacated [3]int, sign int) {
+	owner := 0
+	switch {
+	case b.WinLength < 3:
+		return // Every line with a piece of one player would be two short of completion, or no line could be
+	case xCount == b.WinLength-2 && oCount == 0:
+	case oCount == b.WinLength-2 && xCount == 0:
+		owner = 1
+	default:
+		return
+	}
+
+	dir := direction.step
+	for i := 0; i < b.WinLength; i++ {
+		cell := [3]int{start[0] + i*dir[0], start[1] + i*dir[1], start[2] + i*dir[2]}
+		if b.Grid[cell[0]][cell[1]][cell[2]] != '|' && cell != vacated {
+			continue
+		}
+		lines := &b.forkLines[b.cellIndex(cell[0], cell[1], cell[2])][owner]
+		before := *lines
+		*lines += sign
+		if before < 2 && *lines >= 2 {
+			b.forkCells[owner]++
+		} else if before >= 2 && *lines < 2 {
+			b.forkCells[owner]--
+		}
+	}
+}
+
+// updateForks keeps the fork counts and the score in step with the piece at x, y, z, just placed (sign 1) or about
+// to be removed (sign -1); only the lines through it change
+func (b *Board) updateForks(x, y, z, sign int) {
+	before := b.forkScore()
+	symbol := b.Grid[x][y][z]
+	placed := [3]int{x, y, z}
+	for _, line := range b.lines.through[b.cellIndex(x, y, z)] {
+		start, direction := b.lines.starts[line], b.lines.directions[line]
+		xCount, oCount := b.linePieces(line)
+		xBefore, oBefore := xCount, oCount
+		if symbol == 'x' {
+			xBefore--
+		} else {
+			oBefore--
+		}
+		b.countForkLine(start, direction, xBefore, oBefore, placed, -sign)
+		b.countForkLine(start, direction, xCount, oCount, [3]int{-1, -1, -1}, sign)
+	}
+	b.Score += b.forkScore() - before
+}
+
+// movesLeft returns how many more pieces 'x' and 'o' can place before the board is full
+func (b *Board) movesLeft() [2]int {
+	played := b.MoveCount()
+	empty := b.Length*b.Width*b.Height - played
+	left := [2]int{empty / 2, empty / 2}
+	if empty%2 == 1 {
+		left[played%2]++ // The player to move gets the last cell
+	}
+	return left
+}
+
+// LinesThrough returns the number of lines through the cell at (x, y, z), the lines a piece there could complete.
+// On the 4x4x4 board the eight corners and the eight centre cells lie on seven lines, the other cells on four
+func (b *Board) LinesThrough(x, y, z int) int {
+	return len(b.lines.through[b.cellIndex(x, y, z)])
+}
+
+// deadScore is the score the lines their player can no longer complete would have, which Evaluate leaves out
+func (b *Board) deadScore() int {
+	left := b.movesLeft()
+	if min(left[0], left[1]) >= b.WinLength-1 {
+		return 0 // A line with a piece on it needs at most WinLength-1 more
+	}
+	score := 0
+	for line, direction := range b.lines.directions {
+		xCount, oCount := b.linePieces(int32(line))
+		if (xCount > 0 && oCount == 0 && b.WinLength-xCount > left[0]) || (oCount > 0 && xCount == 0 && b.WinLength-oCount > left[1]) {
+			_, _, fillers := b.lineCounts(int32(line))
+			score += b.lineScore(direction.class, xCount, oCount, fillers)
+		}
+	}
+	return score
+}
+
+// updateDeadLines takes the lines that died with the last move out of the score, or puts back those an undone move revived
+func (b *Board) updateDeadLines() {
+	dead := b.deadScore()
+	b.Score -= dead - b.deadLines
+	b.deadLines = dead
+}
+
+// IsDeadDraw reports whether neither player can complete a line any more however the game goes on, every line
+// holding pieces of both or needing more than its player has moves left, so that the game can only end in a draw
+func (b *Board) IsDeadDraw() bool {
+	if b.PlayerWin != '|' {
+		return false
+	}
+	left := b.movesLeft()
+	for line := range b.lineCounters {
+		xCount, oCount := b.linePieces(int32(line))
+		if (oCount == 0 && b.WinLength-xCount <= left[0]) || (xCount == 0 && b.WinLength-oCount <= left[1]) {
+			return false
+		}
+	}
+	return true
+}
+
+// SetEvaluator changes how the board is evaluated, and its score with it
+func (b *Board) SetEvaluator(evaluator Evaluator) {
+	b.Evaluator = evaluator
+	b.Evaluate()
+}
@@ -0,0 +1,98 @@
+package engine
+
+import "math/bits"
+
+// fixedBoard holds in fixed-size arrays what a board of one of the standard sizes, 3x3x3 and 4x4x4, keeps in
+// slices elsewhere, so that such a board is one allocation rather than dozens, and copies with a few array
+// assignments. Grid and CurrentHeights are slices into it, so code reading the board sees no difference
+// A 3x3x3 board uses the first cells of each array
+type fixedBoard struct {
+	cells    [64]byte                  // Grid's cells, column after column (see cellIndex)
+	columns  [16][]byte                // Grid's columns
+	rows     [4][][]byte               // Grid's rows of columns
+	heights  [16]int                   // CurrentHeights, column after column
+	layers   [4][]int                  // CurrentHeights' rows
+	counters [76]uint32                // lineCounters
+	occupied uint64                    // By cell: whether a piece is on it
+	scores   [DIRECTION_CLASSES][5]int // By class and pieces: the score of a line open to 'x' needing no fillers
+}
+
+// fixedBoardSizes are the sizes given a fixedBoard, as [Length, Width, Height, WinLength]; other sizes use the
+// general storage
+var fixedBoardSizes = [][4]int{{3, 3, 3, 3}, {4, 4, 4, 4}}
+
+// initFixed sets the board up on a fixedBoard if its size is a standard one, and reports whether it did
+func (b *Board) initFixed() bool {
+	size := [4]int{b.Length, b.Width, b.Height, b.WinLength}
+	if size != fixedBoardSizes[0] && size != fixedBoardSizes[1] {
+		b.fixed = nil
+		return false
+	}
+
+	f := &fixedBoard{}
+	cells := b.Length * b.Width * b.Height
+	for cell := range cells {
+		f.cells[cell] = '|'
+	}
+	b.Grid = f.rows[:b.Length:b.Length]
+	b.CurrentHeights = f.layers[:b.Length:b.Length]
+	for i := 0; i < b.Length; i++ {
+		b.Grid[i] = f.columns[i*b.Width : (i+1)*b.Width : (i+1)*b.Width]
+		b.CurrentHeights[i] = f.heights[i*b.Width : (i+1)*b.Width : (i+1)*b.Width]
+		for j := 0; j < b.Width; j++ {
+			column := (i*b.Width + j) * b.Height
+			b.Grid[i][j] = f.cells[column : column+b.Height : column+b.Height]
+		}
+	}
+	b.lineCounters = f.counters[:len(b.lines.starts)]
+	b.fixed = f
+	b.tabulateScores()
+	return true
+}
+
+// copyFixed copies the pieces, heights and line counters of original, a board of the same standard size, into
+// the board
+func (b *Board) copyFixed(original *Board) {
+	b.fixed.cells = original.fixed.cells
+	b.fixed.heights = original.fixed.heights
+	b.fixed.counters = original.fixed.counters
+	b.fixed.occupied = original.fixed.occupied
+	b.fixed.scores = original.fixed.scores
+}
+
+// tabulateScores fills the score table of a board on a fixedBoard for its evaluator; it must be called again
+// whenever the evaluator changes, as Evaluate does
+func (b *Board) tabulateScores() {
+	for class := range DIRECTION_CLASSES {
+		for pieces := 0; pieces <= b.WinLength; pieces++ {
+			b.fixed.scores[class][pieces] = b.Evaluator.lineScore(class, pieces, 0, 0, b.WinLength)
+		}
+	}
+}
+
+// lineScore is the evaluator's lineScore for a line of the board, read from the score table on a fixedBoard
+func (b *Board) lineScore(class DirectionClass, xCount, oCount, fillers int) int {
+	if b.fixed == nil {
+		return b.Evaluator.lineScore(class, xCount, oCount, fillers, b.WinLength)
+	}
+	score := 0
+	if oCount == 0 {
+		score = b.fixed.scores[class][xCount]
+	} else if xCount == 0 {
+		score = -b.fixed.scores[class][oCount] // Division truncates towards 0, so o's scores mirror x's
+	}
+	for ; fillers > 0 && score != 0 && b.Evaluator.Playability != 100; fillers-- {
+		score = score * b.Evaluator.Playability / 100
+	}
+	return score
+}
+
+// fixedMoveCount is MoveCount for a board on a fixedBoard, counting the occupied cells in one instruction
+func (b *Board) fixedMoveCount() int {
+	return bits.OnesCount64(b.fixed.occupied)
+}
+
+// fixedIsFull is IsFull for a board on a fixedBoard
+func (b *Board) fixedIsFull() bool {
+	return b.fixed.occupied == 1<<(b.Length*b.Width*b.Height)-1
+}
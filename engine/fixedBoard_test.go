@@ -0,0 +1,92 @@
+package engine
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// withoutFixedBoards has the boards created until the test ends use the general storage whatever their size
+func withoutFixedBoards(t *testing.T) {
+	sizes := fixedBoardSizes
+	fixedBoardSizes = [][4]int{{}, {}}
+	t.Cleanup(func() { fixedBoardSizes = sizes })
+}
+
+func TestFixedBoardMatchesGeneral(t *testing.T) {
+	configs := []BoardConfig{
+		{Length: 3, Width: 3, Height: 3, Win: 3},
+		{Length: 4, Width: 4, Height: 4, Win: 4},
+	}
+	random := rand.New(rand.NewSource(2))
+	for _, config := range configs {
+		for name, evaluator := range testEvaluators {
+			for game := 0; game < 5; game++ {
+				fixed, err := New(WithConfig(config), WithEvaluator(evaluator))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if fixed.fixed == nil {
+					t.Fatalf("%v board is not on a fixedBoard", config)
+				}
+				var general *Board
+				t.Run("", func(t *testing.T) {
+					withoutFixedBoards(t)
+					var err error
+					general, err = New(WithConfig(config), WithEvaluator(evaluator))
+					if err != nil {
+						t.Fatal(err)
+					}
+					if general.fixed != nil {
+						t.Fatalf("%v board is on a fixedBoard", config)
+					}
+					compareBoards(t, fixed, general, fmt.Sprint(config)+" "+name+" empty")
+
+					for !fixed.IsFull() {
+						col, row := randomColumn(fixed, random)
+						fixed.MoveAt(col, row, fixed.NextPlayer())
+						general.MoveAt(col, row, general.NextPlayer())
+						compareBoards(t, fixed, general, fmt.Sprint(config)+" "+name+" after "+fixed.MoveName(col, row))
+
+						if random.Intn(4) == 0 {
+							fixed.UnMoveAt(col, row)
+							general.UnMoveAt(col, row)
+							compareBoards(t, fixed, general, fmt.Sprint(config)+" "+name+" after taking back "+fixed.MoveName(col, row))
+						}
+					}
+				})
+				if general != nil && fixed.IsFull() {
+					compareBoards(t, CopyBoard(fixed), general, fmt.Sprint(config)+" "+name+" copied")
+				}
+			}
+		}
+	}
+}
+
+// compareBoards fails unless a board on a fixedBoard and one on the general storage agree
+func compareBoards(t *testing.T, fixed, general *Board, context string) {
+	t.Helper()
+	if fixed.Score != general.Score {
+		t.Fatalf("%s: fixed score %d, general %d", context, fixed.Score, general.Score)
+	}
+	if fixed.fixedIsFull() != general.IsFull() {
+		t.Fatalf("%s: fixedIsFull %v, general IsFull %v", context, fixed.fixedIsFull(), general.IsFull())
+	}
+	if fixed.fixedMoveCount() != general.MoveCount() {
+		t.Fatalf("%s: fixedMoveCount %d, general MoveCount %d", context, fixed.fixedMoveCount(), general.MoveCount())
+	}
+	if fixed.CheckWin() != general.CheckWin() {
+		t.Fatalf("%s: fixed winner %c, general %c", context, fixed.CheckWin(), general.CheckWin())
+	}
+	if fixed.ZobristHash() != general.ZobristHash() {
+		t.Fatalf("%s: fixed hash %x, general %x", context, fixed.ZobristHash(), general.ZobristHash())
+	}
+	if fixed.Snapshot() != general.Snapshot() {
+		t.Fatalf("%s: fixed board %q, general %q", context, fixed.Snapshot(), general.Snapshot())
+	}
+	for line := range fixed.lineCounters {
+		if fixed.lineCounters[line] != general.lineCounters[line] {
+			t.Fatalf("%s: line %d counter %#x on the fixed board, %#x on the general one", context, line, fixed.lineCounters[line], general.lineCounters[line])
+		}
+	}
+}
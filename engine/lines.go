@@ -0,0 +1,108 @@
+package engine
+
+import "sync"
+
+// boardLines numbers the lines of boards of one size, the WinLength cells in a row a player wins by filling, and
+// lists the lines through each cell, so that boards look their lines up instead of trying every direction from
+// every cell
+type boardLines struct {
+	starts     [][3]int        // By line: its first cell
+	directions []lineDirection // By line: the direction it runs in from there
+	through    [][]int32       // By cell (see cellIndex): the lines through it
+}
+
+// Each line's counter packs the pieces of 'x' on it into its low half and those of 'o' into its high half
+const (
+	lineCounterX    uint32 = 1
+	lineCounterO    uint32 = 1 << 16
+	lineCounterMask uint32 = lineCounterO - 1
+)
+
+// boardLineTables holds the lines of every board size met so far, by [Length, Width, Height, WinLength]
+var boardLineTables sync.Map
+
+// linesOf returns the lines of boards of the given size, numbering them the first time the size is met
+func linesOf(length, width, height, winLength int) *boardLines {
+	size := [4]int{length, width, height, winLength}
+	if lines, ok := boardLineTables.Load(size); ok {
+		return lines.(*boardLines)
+	}
+
+	lines := &boardLines{through: make([][]int32, length*width*height)}
+	fits := func(x, y, z int) bool { return x >= 0 && x < length && y >= 0 && y < width && z >= 0 && z < height }
+	for i := 0; i < length; i++ {
+		for j := 0; j < width; j++ {
+			for k := 0; k < height; k++ {
+				for _, direction := range lineDirections {
+					dir := direction.step
+					if !fits(i+(winLength-1)*dir[0], j+(winLength-1)*dir[1], k+(winLength-1)*dir[2]) {
+						continue
+					}
+					line := int32(len(lines.starts))
+					lines.starts = append(lines.starts, [3]int{i, j, k})
+					lines.directions = append(lines.directions, direction)
+					for step := 0; step < winLength; step++ {
+						cell := ((i+step*dir[0])*width+j+step*dir[1])*height + k + step*dir[2]
+						lines.through[cell] = append(lines.through[cell], line)
+					}
+				}
+			}
+		}
+	}
+
+	stored, _ := boardLineTables.LoadOrStore(size, lines)
+	return stored.(*boardLines)
+}
+
+// LineCount returns the number of lines of the board, each of which keeps a 4-byte counter of its pieces
+func (b *Board) LineCount() int {
+	return len(b.lineCounters)
+}
+
+// countPiece adds sign times the piece of player at x, y, z to the counters of the lines through its cell
+func (b *Board) countPiece(x, y, z int, player byte, sign int) {
+	var counter uint32
+	switch player {
+	case 'x':
+		counter = lineCounterX
+	case 'o':
+		counter = lineCounterO
+	default:
+		return // Not a piece any line counts
+	}
+	for _, line := range b.lines.through[b.cellIndex(x, y, z)] {
+		if sign > 0 {
+			b.lineCounters[line] += counter
+		} else {
+			b.lineCounters[line] -= counter
+		}
+	}
+}
+
+// linePieces returns the pieces of each player on a line
+func (b *Board) linePieces(line int32) (xCount, oCount int) {
+	counter := b.lineCounters[line]
+	return int(counter & lineCounterMask), int(counter >> 16)
+}
+
+// lineCounts returns the pieces of each player on a line, and the filler pieces it needs: those to be played under
+// its empty cells before all of them can be, except in its own column for a vertical line. Fillers are only counted
+// when the evaluator's playability or parity needs them, and are 0 otherwise
+func (b *Board) lineCounts(line int32) (xCount, oCount, fillers int) {
+	xCount, oCount = b.linePieces(line)
+	if b.Evaluator.Playability == 100 && b.Evaluator.Parity == 0 {
+		return xCount, oCount, 0
+	}
+	direction := b.lines.directions[line]
+	if direction.class == VERTICAL_LINES {
+		return xCount, oCount, 0
+	}
+	start, dir := b.lines.starts[line], direction.step
+	for i := 0; i < b.WinLength; i++ {
+		x, y, z := start[0]+i*dir[0], start[1]+i*dir[1], start[2]+i*dir[2]
+		if cell := b.Grid[x][y][z]; cell != 'x' && cell != 'o' {
+			fillers += z - b.CurrentHeights[x][y]
+		}
+	}
+	return xCount, oCount, fillers
+}
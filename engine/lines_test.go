@@ -0,0 +1,84 @@
+package engine
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// testEvaluators are the evaluators boards are checked with, each term on by itself
+var testEvaluators = map[string]Evaluator{
+	"default":     DefaultEvaluator(),
+	"weights":     {Base: 10, Weights: [DIRECTION_CLASSES]int{100, 250, 80, 120}, Playability: 100},
+	"playability": {Base: 10, Weights: DEFAULT_DIRECTION_WEIGHTS, Playability: 50},
+	"parity":      {Base: 10, Weights: DEFAULT_DIRECTION_WEIGHTS, Playability: 100, Parity: 200},
+	"forks":       {Base: 10, Weights: DEFAULT_DIRECTION_WEIGHTS, Playability: 100, Forks: 50},
+}
+
+// randomColumn returns a random column of the board that still has room
+func randomColumn(board *Board, random *rand.Rand) (int, int) {
+	var valid [][2]int
+	board.EachValidMove(func(col, row int) bool {
+		valid = append(valid, [2]int{col, row})
+		return true
+	})
+	move := valid[random.Intn(len(valid))]
+	return move[0], move[1]
+}
+
+// checkLineCounters reports the lines whose packed counter disagrees with the pieces on their cells
+func checkLineCounters(t *testing.T, board *Board, context string) {
+	t.Helper()
+	for line, start := range board.lines.starts {
+		xWant, oWant := 0, 0
+		for _, piece := range board.GetLine(start, board.lines.directions[line].step) {
+			switch piece {
+			case 'x':
+				xWant++
+			case 'o':
+				oWant++
+			}
+		}
+		if x, o := board.linePieces(int32(line)); x != xWant || o != oWant {
+			t.Fatalf("%s: line %d from %v counts %d x and %d o, want %d and %d", context, line, start, x, o, xWant, oWant)
+		}
+	}
+}
+
+func TestIncrementalScoreMatchesEvaluate(t *testing.T) {
+	configs := []BoardConfig{
+		{Length: 3, Width: 3, Height: 3, Win: 3},
+		{Length: 4, Width: 4, Height: 4, Win: 4},
+		{Length: 4, Width: 4, Height: 4, Win: 3},
+		{Length: 5, Width: 4, Height: 3, Win: 3},
+	}
+	random := rand.New(rand.NewSource(1))
+	for _, config := range configs {
+		for name, evaluator := range testEvaluators {
+			board, err := New(WithConfig(config), WithEvaluator(evaluator))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var played [][2]int
+			for !board.IsFull() {
+				col, row := randomColumn(board, random)
+				board.MoveAt(col, row, board.NextPlayer())
+				played = append(played, [2]int{col, row})
+
+				context := fmt.Sprint(config) + " " + name + " after " + board.MoveName(col, row)
+				checkLineCounters(t, board, context)
+				if want := CopyBoard(board).Evaluate(); board.Score != want {
+					t.Fatalf("%s: incremental score %d, Evaluate %d", context, board.Score, want)
+				}
+			}
+			for i := len(played) - 1; i >= 0; i-- {
+				board.UnMoveAt(played[i][0], played[i][1])
+				context := fmt.Sprint(config) + " " + name + " after taking back " + board.MoveName(played[i][0], played[i][1])
+				checkLineCounters(t, board, context)
+				if want := CopyBoard(board).Evaluate(); board.Score != want {
+					t.Fatalf("%s: incremental score %d, Evaluate %d", context, board.Score, want)
+				}
+			}
+		}
+	}
+}
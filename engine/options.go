@@ -0,0 +1,102 @@
+package engine
+
+import "fmt"
+
+// DEFAULT_EVALUATION_BASE is the base of Evaluate's exponential line scores unless WithEvaluationBase sets another
+const DEFAULT_EVALUATION_BASE = 10
+
+// boardSettings collects the options of New
+type boardSettings struct {
+	length, width, height int
+	winLength             int // 0 until set: the smallest dimension
+	evaluator             Evaluator
+}
+
+// Option configures a board created by New; an invalid option makes New fail
+type Option func(settings *boardSettings) error
+
+// WithDims sets the board's length (columns A..), width (rows 1..) and height (layers); the default is 4x4x4
+func WithDims(length, width, height int) Option {
+	return func(settings *boardSettings) error {
+		if length < 1 || width < 1 || height < 1 {
+			return fmt.Errorf("board dimensions must be positive, got %dx%dx%d", length, width, height)
+		}
+		settings.length, settings.width, settings.height = length, width, height
+		return nil
+	}
+}
+
+// WithWinLength sets how many pieces in a row win; the default is the board's smallest dimension
+func WithWinLength(winLength int) Option {
+	return func(settings *boardSettings) error {
+		if winLength < 1 {
+			return fmt.Errorf("win length must be positive, got %d", winLength)
+		}
+		settings.winLength = winLength
+		return nil
+	}
+}
+
+// WithConfig sets the dimensions and win length of a board configuration, as WithDims and WithWinLength
+func WithConfig(config BoardConfig) Option {
+	return func(settings *boardSettings) error {
+		if err := WithDims(config.Length, config.Width, config.Height)(settings); err != nil {
+			return err
+		}
+		return WithWinLength(config.Win)(settings)
+	}
+}
+
+// WithGravity selects whether pieces fall to the lowest free cell of their column
+// Only boards with gravity are implemented, so WithGravity(false) makes New fail
+func WithGravity(gravity bool) Option {
+	return func(settings *boardSettings) error {
+		if !gravity {
+			return fmt.Errorf("boards without gravity are not supported")
+		}
+		return nil
+	}
+}
+
+// WithEvaluationBase sets the base of Evaluate's exponential line scores (DEFAULT_EVALUATION_BASE by default)
+func WithEvaluationBase(base int) Option {
+	return func(settings *boardSettings) error {
+		if base < 2 {
+			return fmt.Errorf("evaluation base must be at least 2, got %d", base)
+		}
+		settings.evaluator.Base = base
+		return nil
+	}
+}
+
+// WithEvaluator sets how Evaluate scores the board's lines (DefaultEvaluator by default)
+func WithEvaluator(evaluator Evaluator) Option {
+	return func(settings *boardSettings) error {
+		if err := evaluator.Validate(); err != nil {
+			return err
+		}
+		settings.evaluator = evaluator
+		return nil
+	}
+}
+
+// New creates an empty board, by default a 4x4x4 board with gravity where four in a row win
+// Options are applied in order; the first invalid one, or a win length that fits no line of the board, is returned as an error
+func New(options ...Option) (*Board, error) {
+	settings := boardSettings{length: 4, width: 4, height: 4, evaluator: DefaultEvaluator()}
+	for _, option := range options {
+		if err := option(&settings); err != nil {
+			return nil, err
+		}
+	}
+	if settings.winLength == 0 {
+		settings.winLength = min(settings.length, settings.width, settings.height)
+	}
+	if err := ValidateBoardDimensions(settings.length, settings.width, settings.height, settings.winLength); err != nil {
+		return nil, err
+	}
+
+	b := emptyBoard(settings.length, settings.width, settings.height, settings.winLength)
+	b.SetEvaluator(settings.evaluator)
+	return b, nil
+}
@@ -0,0 +1,228 @@
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MAX_SNAPSHOT_POSITIONS is the most positions ParseSnapshot searches for a game reaching a snapshot before giving up
+// Telling whether the pieces can be taken back in turn takes a search, which on an unreachable snapshot can visit every
+// combination of column heights
+const MAX_SNAPSHOT_POSITIONS = 1 << 16
+
+// Snapshot describes the position in one FEN-like line: "LxWxH/win rows next"
+// rows lists rows 1..W separated by '/', each holding the columns A.. separated by ','; a column is its pieces
+// from the bottom up, or '-' when empty. next is the player to move. The empty 2x2x2 board is "2x2x2/2 -,-/-,- x"
+func (b *Board) Snapshot() string {
+	rows := make([]string, b.Width)
+	for row := range rows {
+		columns := make([]string, b.Length)
+		for col := range columns {
+			columns[col] = string(b.Grid[col][row][:b.CurrentHeights[col][row]])
+			if columns[col] == "" {
+				columns[col] = "-"
+			}
+		}
+		rows[row] = strings.Join(columns, ",")
+	}
+	return fmt.Sprintf("%dx%dx%d/%d %s %c", b.Length, b.Width, b.Height, b.WinLength, strings.Join(rows, "/"), b.NextPlayer())
+}
+
+// ParseSnapshot reads a snapshot written by Board.Snapshot
+// It returns the moves of one game reaching the position, as the position alone says nothing of the order they were played in
+func ParseSnapshot(snapshot string) (BoardConfig, []string, error) {
+	fields := strings.Fields(snapshot)
+	if len(fields) != 3 {
+		return BoardConfig{}, nil, fmt.Errorf("invalid snapshot %q (expected \"LxWxH/win rows next\")", snapshot)
+	}
+	config, err := ParseBoardConfig(fields[0])
+	if err != nil {
+		return BoardConfig{}, nil, err
+	}
+	if config.Win == 0 {
+		config.Win = min(config.Length, config.Width, config.Height)
+	}
+	if err := ValidateBoardDimensions(config.Length, config.Width, config.Height, config.Win); err != nil {
+		return BoardConfig{}, nil, err
+	}
+
+	// Read every column's stack of pieces
+	rows := strings.Split(fields[1], "/")
+	if len(rows) != config.Width {
+		return BoardConfig{}, nil, fmt.Errorf("snapshot has %d rows, the board has %d", len(rows), config.Width)
+	}
+	var columns []snapshotColumn
+	pieces := [2]int{}
+	for row, cells := range rows {
+		stacks := strings.Split(cells, ",")
+		if len(stacks) != config.Length {
+			return BoardConfig{}, nil, fmt.Errorf("snapshot row %d has %d columns, the board has %d", row+1, len(stacks), config.Length)
+		}
+		for col, stack := range stacks {
+			if stack == "-" {
+				continue
+			}
+			if len(stack) > config.Height || strings.Trim(stack, "xo") != "" {
+				return BoardConfig{}, nil, fmt.Errorf("invalid column %q in snapshot row %d", stack, row+1)
+			}
+			pieces[0] += strings.Count(stack, "x")
+			pieces[1] += strings.Count(stack, "o")
+			columns = append(columns, snapshotColumn{col: col, row: row, stack: stack})
+		}
+	}
+	// Columns are tried in the order of their names, so the game found is the same every time
+	sort.Slice(columns, func(i, j int) bool {
+		if columns[i].col != columns[j].col {
+			return columns[i].col < columns[j].col
+		}
+		return columns[i].row < columns[j].row
+	})
+
+	next := byte('x')
+	if pieces[0] > pieces[1] {
+		next = 'o'
+	}
+	if pieces[0] != pieces[1] && pieces[0] != pieces[1]+1 || fields[2] != string(next) {
+		return BoardConfig{}, nil, fmt.Errorf("snapshot has %d 'x' and %d 'o' pieces with '%s' to move, which no game can reach", pieces[0], pieces[1], fields[2])
+	}
+
+	// Search backwards from the final position. The last move filled a cell of every completed line; once it is taken
+	// back no line is complete, and taking back more pieces cannot complete one, so the moves before it only have to
+	// take turns
+	final := emptyBoard(config.Length, config.Width, config.Height, config.Win)
+	heights := make([]int, len(columns))
+	for i, column := range columns {
+		for _, piece := range []byte(column.stack) {
+			final.MoveAt(column.col, column.row, piece)
+		}
+		heights[i] = len(column.stack)
+	}
+	last, err := final.lastMoveColumns(next)
+	if err != nil {
+		return BoardConfig{}, nil, fmt.Errorf("no game reaches snapshot %q: %v", snapshot, err)
+	}
+	search := &snapshotSearch{board: final, columns: columns, heights: heights, failed: make(map[string]bool), moves: make([]string, pieces[0]+pieces[1])}
+	if !search.unplay(OpponentSymbol(next), len(search.moves), last) {
+		if search.searched > MAX_SNAPSHOT_POSITIONS {
+			return BoardConfig{}, nil, fmt.Errorf("no game reaching snapshot %q found within %d positions", snapshot, MAX_SNAPSHOT_POSITIONS)
+		}
+		return BoardConfig{}, nil, fmt.Errorf("no game reaches snapshot %q without ending earlier", snapshot)
+	}
+	return *config, search.moves, nil
+}
+
+// snapshotColumn is a column of a snapshot holding pieces, with its stack of them from the bottom up
+type snapshotColumn struct {
+	col, row int
+	stack    string
+}
+
+// lastMoveColumns returns the columns the last move of a game ending with the pieces on the board may have been
+// played in, next being the player to move, or nil if no line is complete and it may have been any. A game ends
+// there only if at most one player has completed lines, that player moved last, and all the lines run through one
+// cell on top of its column
+func (b *Board) lastMoveColumns(next byte) (map[[2]int]bool, error) {
+	var winner byte
+	var complete []int32
+	for line := range b.lineCounters {
+		xCount, oCount := b.linePieces(int32(line))
+		lineWinner := byte(0)
+		switch b.WinLength {
+		case xCount:
+			lineWinner = 'x'
+		case oCount:
+			lineWinner = 'o'
+		default:
+			continue
+		}
+		if winner != 0 && winner != lineWinner {
+			return nil, fmt.Errorf("both players have completed a line")
+		}
+		winner = lineWinner
+		complete = append(complete, int32(line))
+	}
+	if winner == 0 {
+		return nil, nil
+	}
+	if winner == next {
+		return nil, fmt.Errorf("'%c' has completed a line but did not move last", winner)
+	}
+
+	// Count the completed lines through the top cell of each column
+	through := make(map[[2]int]int)
+	for _, line := range complete {
+		start, step := b.lines.starts[line], b.lines.directions[line].step
+		for i := 0; i < b.WinLength; i++ {
+			x, y, z := start[0]+i*step[0], start[1]+i*step[1], start[2]+i*step[2]
+			if z == b.CurrentHeights[x][y]-1 {
+				through[[2]int{x, y}]++
+			}
+		}
+	}
+	columns := make(map[[2]int]bool)
+	for column, lines := range through {
+		if lines == len(complete) {
+			columns[column] = true
+		}
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("'%c' has completed lines no single last move could have", winner)
+	}
+	return columns, nil
+}
+
+// snapshotSearch searches for an order the pieces of columns, stacked up to heights, were played in, setting them in
+// moves as it takes them back
+// As the stacks fix which piece goes where, the heights alone name the position reached; failed records the positions
+// the search has already found no way back from, so none is searched twice
+type snapshotSearch struct {
+	board    *Board
+	columns  []snapshotColumn
+	heights  []int
+	failed   map[string]bool
+	moves    []string
+	searched int // positions searched so far, stopping the search past MAX_SNAPSHOT_POSITIONS
+}
+
+// unplay takes back the remaining moves, players alternating from symbol, the first from a column of last unless it is
+// nil, reporting whether it found an order
+// Columns whose piece below the one taken back is the other player's are tried first: they can go on taking turns
+// by themselves, while a column with two pieces of a player in a row needs the other's from elsewhere in between
+func (search *snapshotSearch) unplay(symbol byte, remaining int, last map[[2]int]bool) bool {
+	if remaining == 0 {
+		return true
+	}
+	if search.searched++; search.searched > MAX_SNAPSHOT_POSITIONS {
+		return false
+	}
+	key := make([]byte, len(search.heights))
+	for i, height := range search.heights {
+		key[i] = byte(height)
+	}
+	if search.failed[string(key)] {
+		return false
+	}
+
+	for _, alternating := range []bool{true, false} {
+		for i, column := range search.columns {
+			height := search.heights[i]
+			if height == 0 || column.stack[height-1] != symbol || last != nil && !last[[2]int{column.col, column.row}] {
+				continue
+			}
+			if (height > 1 && column.stack[height-2] != symbol) != alternating {
+				continue
+			}
+			search.heights[i]--
+			search.moves[remaining-1] = search.board.MoveName(column.col, column.row)
+			if search.unplay(OpponentSymbol(symbol), remaining-1, nil) {
+				return true
+			}
+			search.heights[i]++
+		}
+	}
+	if search.searched <= MAX_SNAPSHOT_POSITIONS {
+		search.failed[string(key)] = true
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package engine
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	configs := []BoardConfig{
+		{Length: 2, Width: 2, Height: 2, Win: 2},
+		{Length: 3, Width: 3, Height: 3, Win: 3},
+		{Length: 4, Width: 4, Height: 4, Win: 4},
+		{Length: 7, Width: 6, Height: 4, Win: 4},
+	}
+	random := rand.New(rand.NewSource(1))
+	for _, config := range configs {
+		for game := 0; game < 20; game++ {
+			board, err := New(WithConfig(config))
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Play random moves until the game ends or a random length is reached
+			for plies := random.Intn(config.Length * config.Width * config.Height); plies > 0 && board.CheckWin() == '|' && !board.IsFull(); plies-- {
+				var valid [][2]int
+				board.EachValidMove(func(col, row int) bool {
+					valid = append(valid, [2]int{col, row})
+					return true
+				})
+				move := valid[random.Intn(len(valid))]
+				board.Move(board.MoveName(move[0], move[1]), board.NextPlayer())
+			}
+
+			snapshot := board.Snapshot()
+			parsed, moves, err := ParseSnapshot(snapshot)
+			if err != nil {
+				t.Fatalf("ParseSnapshot(%q): %v", snapshot, err)
+			}
+			if parsed != config {
+				t.Fatalf("ParseSnapshot(%q) board is %+v, want %+v", snapshot, parsed, config)
+			}
+			replayed, err := New(WithConfig(parsed))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for ply, move := range moves {
+				if replayed.CheckWin() != '|' {
+					t.Fatalf("ParseSnapshot(%q) moves %v end the game before ply %d", snapshot, moves, ply+1)
+				}
+				if replayed.Move(move, replayed.NextPlayer())[0] == -1 {
+					t.Fatalf("ParseSnapshot(%q) move %d %q is illegal", snapshot, ply+1, move)
+				}
+			}
+			if got := replayed.Snapshot(); got != snapshot {
+				t.Errorf("ParseSnapshot(%q) moves %v reach %q", snapshot, moves, got)
+			}
+		}
+	}
+}
+
+func TestParseSnapshotRejectsUnreachable(t *testing.T) {
+	tests := []struct {
+		name     string
+		snapshot string
+	}{
+		{"too many o", "2x2x2/2 o,-/-,- x"},
+		{"wrong player to move", "2x2x2/2 x,-/-,- x"},
+		{"both players won", "3x3x3/3 x,o,-/x,o,-/x,o,- x"},
+		{"winner to move", "3x3x3/3 x,o,-/x,o,-/x,-,o x"},
+		{"lines no single move completes", "3x3x3/3 xo,x,x/oo,oo,-/x,x,x o"},
+		{"no x to start from", "3x3x3/3 ox,-,-/-,-,-/-,-,- x"},
+		{"column taller than the board", "2x2x2/2 xox,o/-,- x"},
+	}
+	for _, test := range tests {
+		if _, moves, err := ParseSnapshot(test.snapshot); err == nil {
+			t.Errorf("%s: ParseSnapshot(%q) = %v, want an error", test.name, test.snapshot, moves)
+		}
+	}
+}
+
+// TestParseSnapshotGivesUp checks that a long unreachable snapshot is turned down instead of searched through every
+// combination of column heights: k columns of 'o' beside k of "oxx", where 'x' never has a piece to start from
+func TestParseSnapshotGivesUp(t *testing.T) {
+	const k = 10
+	rows := [2][]string{}
+	for col := 0; col < 20; col++ {
+		rows[0], rows[1] = append(rows[0], "-"), append(rows[1], "-")
+		if col < k {
+			rows[0][col], rows[1][col] = "o", "oxx"
+		}
+	}
+	snapshot := "20x2x3/20 " + strings.Join(rows[0], ",") + "/" + strings.Join(rows[1], ",") + " x"
+	if _, moves, err := ParseSnapshot(snapshot); err == nil {
+		t.Errorf("ParseSnapshot(%q) = %v, want an error", snapshot, moves)
+	}
+}
@@ -0,0 +1,48 @@
+package engine
+
+// Symmetries returns the number of symmetries of the board, which map its base onto itself and keep the columns
+// upright as gravity needs: the eight of a square base, or the four reflections of a rectangular one.
+// Symmetry 0 is the identity; see Transformed
+func (b *Board) Symmetries() int {
+	if b.Length == b.Width {
+		return 8
+	}
+	return 4
+}
+
+// transformColumn maps the column at (col, row) by symmetry: the base is transposed if symmetry&4 (square bases
+// only), then mirrored along its length if symmetry&1 and along its width if symmetry&2
+func (b *Board) transformColumn(col, row, symmetry int) (int, int) {
+	if symmetry&4 != 0 {
+		col, row = row, col
+	}
+	if symmetry&1 != 0 {
+		col = b.Length - 1 - col
+	}
+	if symmetry&2 != 0 {
+		row = b.Width - 1 - row
+	}
+	return col, row
+}
+
+// Transformed returns a copy of the board with its columns moved by symmetry, one of 0 to Symmetries()-1
+// The position is as good for each player as the original: every line maps to a line
+func (b *Board) Transformed(symmetry int) *Board {
+	transformed := emptyBoard(b.Length, b.Width, b.Height, b.WinLength)
+	transformed.SetEvaluator(b.Evaluator)
+	for col := 0; col < b.Length; col++ {
+		for row := 0; row < b.Width; row++ {
+			move := b.TransformMove(b.MoveName(col, row), symmetry)
+			for height := 0; height < b.CurrentHeights[col][row]; height++ {
+				transformed.Move(move, b.Grid[col][row][height])
+			}
+		}
+	}
+	return transformed
+}
+
+// TransformMove returns the move on the board Transformed by symmetry that matches move on this one
+func (b *Board) TransformMove(move string, symmetry int) string {
+	col, row := ParseMove(move)
+	return b.MoveName(b.transformColumn(col, row, symmetry))
+}
@@ -0,0 +1,26 @@
+package engine
+
+// zobristKey returns the random key of a piece of player on the cell with the given index (see Board.cellIndex)
+// The keys are the splitmix64 sequence, so that boards of every size share them without a table to build
+func zobristKey(cell int, player byte) uint64 {
+	key := uint64(cell) * 2
+	if player == 'o' {
+		key++
+	}
+	key = key*0x9E3779B97F4A7C15 + 0x9E3779B97F4A7C15
+	key = (key ^ (key >> 30)) * 0xBF58476D1CE4E5B9
+	key = (key ^ (key >> 27)) * 0x94D049BB133111EB
+	return key ^ (key >> 31)
+}
+
+// cellIndex numbers the cells of the board, (x*Width+y)*Height+z
+func (b *Board) cellIndex(x, y, z int) int {
+	return (x*b.Width+y)*b.Height + z
+}
+
+// ZobristHash returns the Zobrist hash of the pieces on the board, kept up to date by Move and UnMove, for the
+// caches of searches: positions reached by different move orders hash alike. Unlike PositionHash it leaves the
+// board's dimensions out, so hashes of boards of different sizes must not be mixed
+func (b *Board) ZobristHash() uint64 {
+	return b.zobrist
+}
@@ -0,0 +1,378 @@
+package formats
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"tic-tac-toe-3d-bots/engine"
+)
+
+// Binary archives hold many games, or many positions, far more compactly than JSON:
+//
+//	header   "T3D", the kind of entries (BINARY_GAMES or BINARY_POSITIONS), the format version
+//	entries  each prefixed with its length as a uvarint
+//
+// A game is its board, mode, players, bots, clocks, outcome and moves, a move taking a single byte on boards of up to
+// 256 columns (two bytes on larger ones). A position is its board and the cells of x and o as bitboards, with a value
+// whose meaning is up to the file: the score of a book move, the distance to mate of a tablebase, and so on.
+// Integers are uvarints, strings are prefixed with their length, and bot configurations are BotConfig messages of game.proto
+
+// BINARY_ARCHIVE_EXTENSION marks binary archive files
+const BINARY_ARCHIVE_EXTENSION = ".t3d"
+
+// Kinds of binary archives
+const (
+	BINARY_GAMES     = 'G'
+	BINARY_POSITIONS = 'P'
+)
+
+// BINARY_VERSION is the version of the format written; readers refuse later versions
+const BINARY_VERSION = 1
+
+// MAX_BINARY_ENTRY is the largest entry a reader accepts, so that a damaged length cannot exhaust memory
+const MAX_BINARY_ENTRY = 1 << 20
+
+// binaryMagic starts every binary archive
+var binaryMagic = []byte("T3D")
+
+// ArchivedGame is a game of a binary archive: its record, and how it ended if it is over
+type ArchivedGame struct {
+	Record GameRecord
+	Winner string // "x", "o", "draw", or "" if the game did not finish
+	Reason string
+}
+
+// BinaryWriter writes the entries of a binary archive, one at a time
+type BinaryWriter struct {
+	writer io.Writer
+	file   *os.File // the archive's file, if the writer opened it
+	kind   byte
+	entry  []byte // buffer the current entry is encoded into
+}
+
+// NewBinaryWriter starts an archive of kind on writer, writing its header
+func NewBinaryWriter(writer io.Writer, kind byte) (*BinaryWriter, error) {
+	if _, err := writer.Write(append(append([]byte{}, binaryMagic...), kind, BINARY_VERSION)); err != nil {
+		return nil, err
+	}
+	return &BinaryWriter{writer: writer, kind: kind}, nil
+}
+
+// AppendBinaryArchive opens the archive of kind at path to add entries to it, creating it if it does not exist
+func AppendBinaryArchive(path string, kind byte) (*BinaryWriter, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	var writer *BinaryWriter
+	if info.Size() == 0 {
+		writer, err = NewBinaryWriter(file, kind)
+	} else if _, err = NewBinaryReader(file, kind); err == nil {
+		writer = &BinaryWriter{writer: file, kind: kind}
+	}
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	writer.file = file
+	return writer, nil
+}
+
+// Close closes the archive's file if the writer opened it
+func (writer *BinaryWriter) Close() error {
+	if writer.file == nil {
+		return nil
+	}
+	return writer.file.Close()
+}
+
+// flush writes the current entry, prefixed with its length, in a single write
+func (writer *BinaryWriter) flush() error {
+	data := binary.AppendUvarint(make([]byte, 0, len(writer.entry)+binary.MaxVarintLen32), uint64(len(writer.entry)))
+	_, err := writer.writer.Write(append(data, writer.entry...))
+	writer.entry = writer.entry[:0]
+	return err
+}
+
+func (writer *BinaryWriter) uint(value uint64) {
+	writer.entry = binary.AppendUvarint(writer.entry, value)
+}
+
+func (writer *BinaryWriter) string(value string) {
+	writer.uint(uint64(len(value)))
+	writer.entry = append(writer.entry, value...)
+}
+
+func (writer *BinaryWriter) board(board engine.BoardConfig) {
+	for _, dimension := range []int{board.Length, board.Width, board.Height, board.Win} {
+		writer.uint(uint64(dimension))
+	}
+}
+
+// WriteGame adds a game to an archive of games
+func (writer *BinaryWriter) WriteGame(game ArchivedGame) error {
+	if writer.kind != BINARY_GAMES {
+		return fmt.Errorf("not an archive of games")
+	}
+	record := &game.Record
+	columns := record.Board.Length * record.Board.Width
+	if columns == 0 {
+		return fmt.Errorf("the game has no board")
+	}
+
+	writer.board(record.Board)
+	writer.string(record.Mode)
+	writer.string(record.Players[0])
+	writer.string(record.Players[1])
+	writer.string(record.Player)
+	for _, config := range record.Bots {
+		if config == nil {
+			writer.uint(0)
+			continue
+		}
+		message := marshalBotConfig(config)
+		writer.uint(uint64(len(message)) + 1)
+		writer.entry = append(writer.entry, message...)
+	}
+	for _, clock := range record.ThinkingTimes() {
+		writer.uint(uint64(clock.Microseconds()))
+	}
+	writer.string(game.Winner)
+	writer.string(game.Reason)
+
+	writer.uint(uint64(len(record.Moves)))
+	for i, move := range record.Moves {
+		column, row := engine.ParseMove(move)
+		if column < 0 || column >= record.Board.Length || row < 0 || row >= record.Board.Width {
+			writer.entry = writer.entry[:0]
+			return fmt.Errorf("move %d (%s) is off the board", i+1, move)
+		}
+		cell := column*record.Board.Width + row
+		if columns > 256 {
+			writer.entry = append(writer.entry, byte(cell>>8))
+		}
+		writer.entry = append(writer.entry, byte(cell))
+	}
+	return writer.flush()
+}
+
+// WritePosition adds a position and its value to an archive of positions
+func (writer *BinaryWriter) WritePosition(board *engine.Board, value int64) error {
+	if writer.kind != BINARY_POSITIONS {
+		return fmt.Errorf("not an archive of positions")
+	}
+	writer.board(engine.BoardConfig{Length: board.Length, Width: board.Width, Height: board.Height, Win: board.WinLength})
+	cells := board.Length * board.Width * board.Height
+	bitboards := make([]byte, 2*((cells+7)/8))
+	for i := range board.Length {
+		for j := range board.Width {
+			for k := range board.CurrentHeights[i][j] {
+				cell := (i*board.Width+j)*board.Height + k
+				if board.Grid[i][j][k] == 'o' {
+					cell += len(bitboards) / 2 * 8
+				}
+				bitboards[cell/8] |= 1 << (cell % 8)
+			}
+		}
+	}
+	writer.entry = append(writer.entry, bitboards...)
+	writer.entry = binary.AppendVarint(writer.entry, value)
+	return writer.flush()
+}
+
+// BinaryReader reads the entries of a binary archive, one at a time
+type BinaryReader struct {
+	reader *bufio.Reader
+	entry  []byte // the rest of the entry being decoded
+	err    error  // the first decoding error of the entry
+}
+
+// NewBinaryReader reads the header of an archive of kind from reader
+func NewBinaryReader(reader io.Reader, kind byte) (*BinaryReader, error) {
+	header := make([]byte, len(binaryMagic)+2)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return nil, fmt.Errorf("not a binary archive: %v", err)
+	}
+	switch {
+	case string(header[:len(binaryMagic)]) != string(binaryMagic):
+		return nil, fmt.Errorf("not a binary archive")
+	case header[len(binaryMagic)] != kind:
+		return nil, fmt.Errorf("an archive of %q entries, not %q", header[len(binaryMagic)], kind)
+	case header[len(binaryMagic)+1] > BINARY_VERSION:
+		return nil, fmt.Errorf("archive version %d is newer than this program's (%d)", header[len(binaryMagic)+1], BINARY_VERSION)
+	}
+	return &BinaryReader{reader: bufio.NewReader(reader)}, nil
+}
+
+// next reads the next entry; it returns io.EOF after the last one
+func (reader *BinaryReader) next() error {
+	length, err := binary.ReadUvarint(reader.reader)
+	if err == io.EOF {
+		return io.EOF
+	} else if err != nil {
+		return io.ErrUnexpectedEOF
+	}
+	if length > MAX_BINARY_ENTRY {
+		return fmt.Errorf("entry of %d bytes is too large", length)
+	}
+	reader.entry, reader.err = make([]byte, length), nil
+	if _, err := io.ReadFull(reader.reader, reader.entry); err != nil {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+// errBinaryTruncated is the error of an entry that ends too early
+var errBinaryTruncated = errors.New("truncated entry")
+
+func (reader *BinaryReader) bytes(count int) []byte {
+	if count > len(reader.entry) {
+		reader.err, reader.entry = errBinaryTruncated, nil
+		return make([]byte, count)
+	}
+	data := reader.entry[:count]
+	reader.entry = reader.entry[count:]
+	return data
+}
+
+func (reader *BinaryReader) uint() uint64 {
+	value, n := binary.Uvarint(reader.entry)
+	if n <= 0 {
+		reader.err, reader.entry = errBinaryTruncated, nil
+		return 0
+	}
+	reader.entry = reader.entry[n:]
+	return value
+}
+
+func (reader *BinaryReader) string() string {
+	length := reader.uint()
+	if length > uint64(len(reader.entry)) {
+		reader.err, reader.entry = errBinaryTruncated, nil
+		return ""
+	}
+	return string(reader.bytes(int(length)))
+}
+
+// board reads and validates a board's dimensions
+func (reader *BinaryReader) board() (engine.BoardConfig, error) {
+	var dimensions [4]int
+	for i := range dimensions {
+		dimensions[i] = int(min(reader.uint(), 1<<16))
+	}
+	if reader.err != nil {
+		return engine.BoardConfig{}, reader.err
+	}
+	board := engine.BoardConfig{Length: dimensions[0], Width: dimensions[1], Height: dimensions[2], Win: dimensions[3]}
+	return board, engine.ValidateBoardDimensions(board.Length, board.Width, board.Height, board.Win)
+}
+
+// ReadGame reads the next game of an archive of games; it returns io.EOF after the last one
+// The moves are checked to be on the board, but not replayed
+func (reader *BinaryReader) ReadGame() (*ArchivedGame, error) {
+	if err := reader.next(); err != nil {
+		return nil, err
+	}
+	game := &ArchivedGame{}
+	record := &game.Record
+	var err error
+	if record.Board, err = reader.board(); err != nil {
+		return nil, err
+	}
+	record.Mode = reader.string()
+	record.Players = [2]string{reader.string(), reader.string()}
+	record.Player = reader.string()
+	for i := range record.Bots {
+		if length := reader.uint(); length > 0 && reader.err == nil {
+			if record.Bots[i], err = unmarshalBotConfig(reader.bytes(int(min(length-1, uint64(len(reader.entry)+1))))); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for i := range record.Clocks {
+		record.Clocks[i] = (time.Duration(reader.uint()) * time.Microsecond).String()
+	}
+	game.Winner, game.Reason = reader.string(), reader.string()
+
+	moves := reader.uint()
+	columns := record.Board.Length * record.Board.Width
+	moveSize := 1
+	if columns > 256 {
+		moveSize = 2
+	}
+	if moves > uint64(len(reader.entry)/moveSize) {
+		return nil, errBinaryTruncated
+	}
+	record.Moves = make([]string, moves)
+	for i := range record.Moves {
+		cell := 0
+		for _, b := range reader.bytes(moveSize) {
+			cell = cell<<8 | int(b)
+		}
+		if cell >= columns {
+			return nil, fmt.Errorf("move %d is off the board", i+1)
+		}
+		record.Moves[i] = moveName(cell/record.Board.Width, cell%record.Board.Width)
+	}
+	return game, reader.err
+}
+
+// ReadPosition reads the next position of an archive of positions and its value; it returns io.EOF after the last one
+func (reader *BinaryReader) ReadPosition() (*engine.Board, int64, error) {
+	if err := reader.next(); err != nil {
+		return nil, 0, err
+	}
+	config, err := reader.board()
+	if err != nil {
+		return nil, 0, err
+	}
+	cells := config.Length * config.Width * config.Height
+	size := (cells + 7) / 8
+	bitboards := reader.bytes(2 * size)
+	value, n := binary.Varint(reader.entry)
+	if reader.err != nil || n <= 0 {
+		return nil, 0, errBinaryTruncated
+	}
+
+	board, err := engine.New(engine.WithConfig(config))
+	if err != nil {
+		return nil, 0, err
+	}
+	set := func(cell int) bool { return bitboards[cell/8]&(1<<(cell%8)) != 0 }
+	for i := range config.Length {
+		for j := range config.Width {
+			for k := range config.Height {
+				cell := (i*config.Width+j)*config.Height + k
+				x, o := set(cell), set(cell+size*8)
+				if !x && !o {
+					continue
+				}
+				if x && o || board.CurrentHeights[i][j] != k {
+					return nil, 0, fmt.Errorf("invalid position: cell %s%d is taken twice or floats", moveName(i, j), k+1)
+				}
+				symbol := byte('o')
+				if x {
+					symbol = 'x'
+				}
+				board.Move(moveName(i, j), symbol)
+			}
+		}
+	}
+	return board, value, nil
+}
+
+// moveName names the move dropping a piece in column and row, e.g. "A1" for 0, 0
+func moveName(column, row int) string {
+	return fmt.Sprintf("%c%d", 'A'+column, row+1)
+}
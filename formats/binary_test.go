@@ -0,0 +1,177 @@
+package formats
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"tic-tac-toe-3d-bots/bots"
+	"tic-tac-toe-3d-bots/engine"
+)
+
+// randomMoves plays up to plies random moves on an empty board of config, returning them and the board reached
+func randomMoves(t *testing.T, config engine.BoardConfig, plies int, random *rand.Rand) ([]string, *engine.Board) {
+	t.Helper()
+	board, err := engine.New(engine.WithConfig(config))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var moves []string
+	for ; plies > 0 && !board.IsFull(); plies-- {
+		var valid []string
+		board.EachValidMove(func(col, row int) bool {
+			valid = append(valid, board.MoveName(col, row))
+			return true
+		})
+		move := valid[random.Intn(len(valid))]
+		board.Move(move, board.NextPlayer())
+		moves = append(moves, move)
+	}
+	return moves, board
+}
+
+func TestBinaryGamesRoundTrip(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	alphabeta := &bots.BotConfig{Type: "alphabeta", Name: "Deep", Params: map[string]int{"depth": 6, "base": 10, "movetime": 0}}
+	external := &bots.BotConfig{Type: "external", Params: map[string]int{}, Command: []string{"./engine", "--hash", "64"}}
+	tests := []struct {
+		name   string
+		board  engine.BoardConfig
+		plies  int
+		bots   [2]*bots.BotConfig
+		winner string
+	}{
+		{"empty game", engine.BoardConfig{Length: 3, Width: 3, Height: 3, Win: 3}, 0, [2]*bots.BotConfig{}, ""},
+		{"human against bot", engine.BoardConfig{Length: 4, Width: 4, Height: 4, Win: 4}, 20, [2]*bots.BotConfig{nil, alphabeta}, "x"},
+		{"full board", engine.BoardConfig{Length: 2, Width: 2, Height: 2, Win: 2}, 8, [2]*bots.BotConfig{alphabeta, external}, "draw"},
+		{"two-byte moves", engine.BoardConfig{Length: 16, Width: 17, Height: 2, Win: 5}, 200, [2]*bots.BotConfig{external, nil}, "o"},
+	}
+
+	var archive bytes.Buffer
+	writer, err := NewBinaryWriter(&archive, BINARY_GAMES)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want []ArchivedGame
+	for _, test := range tests {
+		moves, _ := randomMoves(t, test.board, test.plies, random)
+		game := ArchivedGame{
+			Record: GameRecord{Mode: "eve", Board: test.board, Players: [2]string{"Alice", "Bot " + test.name}, Bots: test.bots,
+				Player: "Alice", Clocks: [2]string{"1.5s", "2m3.000004s"}, Moves: moves},
+			Winner: test.winner,
+			Reason: "test " + test.name,
+		}
+		if err := writer.WriteGame(game); err != nil {
+			t.Fatalf("%s: WriteGame: %v", test.name, err)
+		}
+		want = append(want, game)
+	}
+
+	reader, err := NewBinaryReader(&archive, BINARY_GAMES)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, test := range tests {
+		got, err := reader.ReadGame()
+		if err != nil {
+			t.Fatalf("%s: ReadGame: %v", test.name, err)
+		}
+		if len(got.Record.Moves) == 0 {
+			got.Record.Moves = nil
+		}
+		for _, config := range want[i].Record.Bots {
+			if config != nil && config.Params == nil {
+				config.Params = map[string]int{}
+			}
+		}
+		if !reflect.DeepEqual(*got, want[i]) {
+			t.Errorf("%s: read back %+v, want %+v", test.name, *got, want[i])
+		}
+	}
+	if _, err := reader.ReadGame(); err != io.EOF {
+		t.Errorf("ReadGame after the last game: got %v, want io.EOF", err)
+	}
+}
+
+func TestBinaryPositionsRoundTrip(t *testing.T) {
+	random := rand.New(rand.NewSource(2))
+	configs := []engine.BoardConfig{
+		{Length: 3, Width: 3, Height: 3, Win: 3},
+		{Length: 4, Width: 4, Height: 4, Win: 4},
+		{Length: 7, Width: 6, Height: 5, Win: 4},
+	}
+	values := []int64{0, 1, -1, 1 << 40, -(1 << 40)}
+
+	var archive bytes.Buffer
+	writer, err := NewBinaryWriter(&archive, BINARY_POSITIONS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want []string
+	for _, config := range configs {
+		for _, value := range values {
+			_, board := randomMoves(t, config, random.Intn(config.Length*config.Width*config.Height+1), random)
+			if err := writer.WritePosition(board, value); err != nil {
+				t.Fatalf("WritePosition(%q): %v", board.Snapshot(), err)
+			}
+			want = append(want, board.Snapshot())
+		}
+	}
+
+	reader, err := NewBinaryReader(&archive, BINARY_POSITIONS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, snapshot := range want {
+		board, value, err := reader.ReadPosition()
+		if err != nil {
+			t.Fatalf("ReadPosition of %q: %v", snapshot, err)
+		}
+		if board.Snapshot() != snapshot || value != values[i%len(values)] {
+			t.Errorf("read back %q with value %d, want %q with %d", board.Snapshot(), value, snapshot, values[i%len(values)])
+		}
+	}
+	if _, _, err := reader.ReadPosition(); err != io.EOF {
+		t.Errorf("ReadPosition after the last position: got %v, want io.EOF", err)
+	}
+}
+
+func TestBinaryReaderRejects(t *testing.T) {
+	var archive bytes.Buffer
+	writer, err := NewBinaryWriter(&archive, BINARY_GAMES)
+	if err != nil {
+		t.Fatal(err)
+	}
+	game := ArchivedGame{Record: GameRecord{Mode: "pvp", Board: engine.BoardConfig{Length: 4, Width: 4, Height: 4, Win: 4}, Moves: []string{"A1", "B2", "C3"}}}
+	if err := writer.WriteGame(game); err != nil {
+		t.Fatal(err)
+	}
+	data := archive.Bytes()
+
+	tests := []struct {
+		name    string
+		kind    byte
+		archive []byte
+	}{
+		{"wrong magic", BINARY_GAMES, append([]byte("T3E"), data[3:]...)},
+		{"wrong kind", BINARY_POSITIONS, data},
+		{"later version", BINARY_GAMES, append(append([]byte("T3D"), BINARY_GAMES, BINARY_VERSION+1), data[5:]...)},
+		{"truncated entry", BINARY_GAMES, data[:len(data)-1]},
+		{"oversized entry", BINARY_GAMES, append([]byte("T3D"), BINARY_GAMES, BINARY_VERSION, 0xff, 0xff, 0xff, 0x7f)},
+	}
+	for _, test := range tests {
+		reader, err := NewBinaryReader(bytes.NewReader(test.archive), test.kind)
+		if err == nil {
+			_, err = reader.ReadGame()
+		}
+		if err == nil || err == io.EOF {
+			t.Errorf("%s: got %v, want an error", test.name, err)
+		}
+	}
+
+	if err := writer.WriteGame(ArchivedGame{Record: GameRecord{Board: game.Record.Board, Moves: []string{"E1"}}}); err == nil {
+		t.Errorf("WriteGame of a move off the board: got no error")
+	}
+}
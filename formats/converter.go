@@ -0,0 +1,109 @@
+package formats
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"tic-tac-toe-3d-bots/engine"
+)
+
+// Formats the export and import commands convert between; records themselves are the saved game format
+const (
+	FormatJSON     = "json"     // a GameResult, as printed with --output json
+	FormatCSV      = "csv"      // a move list with ply, player and move columns
+	FormatSnapshot = "snapshot" // one line describing a position, see Board.Snapshot
+)
+
+// ExportRecord writes record in format
+func ExportRecord(record *GameRecord, format string, output io.Writer) error {
+	switch format {
+	case FormatJSON:
+		result, err := record.result()
+		if err != nil {
+			return err
+		}
+		encoder := json.NewEncoder(output)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+
+	case FormatCSV:
+		writer := csv.NewWriter(output)
+		writer.Write([]string{"ply", "player", "move"})
+		for i, move := range record.Moves {
+			writer.Write([]string{strconv.Itoa(i + 1), string("xo"[i%2]), move})
+		}
+		writer.Flush()
+		return writer.Error()
+
+	case FormatSnapshot:
+		board, err := record.Replay(len(record.Moves))
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(output, board.Snapshot())
+		return err
+	}
+	return fmt.Errorf("unknown format %q (expected json, csv or snapshot)", format)
+}
+
+// ImportRecord reads a game in format; CSV move lists carry no board, so board gives it
+// Only the JSON format names the players
+func ImportRecord(data []byte, format string, board *engine.BoardConfig) (*GameRecord, error) {
+	record := &GameRecord{Mode: "pvp"}
+	switch format {
+	case FormatJSON:
+		result := GameResult{}
+		if err := json.Unmarshal(data, &result); err != nil {
+			return nil, err
+		}
+		record = result.Record()
+
+	case FormatCSV:
+		rows, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+		if err != nil {
+			return nil, err
+		}
+		column := -1
+		for i, rows := range rows {
+			if i == 0 {
+				for j, name := range rows {
+					if strings.EqualFold(strings.TrimSpace(name), "move") {
+						column = j
+					}
+				}
+				if column == -1 {
+					return nil, fmt.Errorf("the CSV move list has no \"move\" column")
+				}
+				continue
+			}
+			if column >= len(rows) {
+				return nil, fmt.Errorf("CSV row %d has no move", i+1)
+			}
+			record.Moves = append(record.Moves, strings.ToUpper(strings.TrimSpace(rows[column])))
+		}
+		record.Board = *board
+
+	case FormatSnapshot:
+		config, moves, err := engine.ParseSnapshot(strings.TrimSpace(string(data)))
+		if err != nil {
+			return nil, err
+		}
+		record.Board, record.Moves = config, moves
+
+	default:
+		return nil, fmt.Errorf("unknown format %q (expected json, csv or snapshot)", format)
+	}
+
+	if err := engine.ValidateBoardDimensions(record.Board.Length, record.Board.Width, record.Board.Height, record.Board.Win); err != nil {
+		return nil, err
+	}
+	if _, err := record.Replay(len(record.Moves)); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
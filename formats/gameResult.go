@@ -0,0 +1,84 @@
+package formats
+
+import (
+	"time"
+
+	"tic-tac-toe-3d-bots/bots"
+	"tic-tac-toe-3d-bots/engine"
+)
+
+// GameResult is the summary of one game printed with --output json
+type GameResult struct {
+	Mode    string             `json:"mode"`
+	Board   engine.BoardConfig `json:"board"`
+	Players [2]string          `json:"players"` // names of the 'x' and 'o' players
+	Bots    [2]*bots.BotConfig `json:"bots"`    // configuration of the bot playing 'x' and 'o', null for a human
+	Winner  string             `json:"winner"`  // "x", "o", "draw", or "" if the game did not finish
+	Reason  string             `json:"reason"`  // how the game ended: "line", "full_board", "time", "forfeit", "interrupted", "resigned", "adjudicated" or "abandoned"
+	Moves   []PlayedMove       `json:"moves"`
+	Stats   [2]PlayerStats     `json:"stats"` // thinking time of 'x' and 'o'
+	Seed    int64              `json:"seed"`  // the run's --seed, which replays the game
+}
+
+// PlayedMove is one move of a GameResult
+// Moves replayed from a resumed game have no timing or score
+type PlayedMove struct {
+	Ply        int     `json:"ply"`
+	Player     string  `json:"player"` // "x" or "o"
+	Move       string  `json:"move"`
+	ThinkingMS float64 `json:"thinking_ms"`
+	Score      *int    `json:"score,omitempty"` // board evaluation after the move (+ favors 'x')
+}
+
+// PlayerStats sums up the thinking time of one player
+type PlayerStats struct {
+	Moves     int     `json:"moves"`
+	TotalMS   float64 `json:"total_ms"`
+	AverageMS float64 `json:"average_ms"`
+	TTFill    float64 `json:"tt_fill,omitempty"` // fill rate of the bot's transposition table after its last move, 0 to 1
+}
+
+// result converts a saved game to a game result, taking the outcome from its final position
+func (record *GameRecord) result() (GameResult, error) {
+	board, err := record.Replay(len(record.Moves))
+	if err != nil {
+		return GameResult{}, err
+	}
+
+	result := GameResult{Mode: record.Mode, Board: record.Board, Players: record.Players, Bots: record.Bots}
+	switch {
+	case board.CheckWin() != '|':
+		result.Winner, result.Reason = string(board.CheckWin()), "line"
+	case board.IsFull():
+		result.Winner, result.Reason = "draw", "full_board"
+	}
+	for i, move := range record.Moves {
+		result.Moves = append(result.Moves, PlayedMove{Ply: i + 1, Player: string("xo"[i%2]), Move: move})
+	}
+	for i, clock := range record.ThinkingTimes() {
+		stats := &result.Stats[i]
+		stats.Moves = (len(record.Moves) + 1 - i) / 2
+		stats.TotalMS = Milliseconds(clock)
+		if stats.Moves > 0 {
+			stats.AverageMS = stats.TotalMS / float64(stats.Moves)
+		}
+	}
+	return result, nil
+}
+
+// Record converts a game result back to a game record, as read by the replay viewer and --resume
+func (result *GameResult) Record() *GameRecord {
+	record := &GameRecord{Mode: result.Mode, Board: result.Board, Players: result.Players, Bots: result.Bots}
+	for _, move := range result.Moves {
+		record.Moves = append(record.Moves, move.Move)
+	}
+	for i, stats := range result.Stats {
+		record.Clocks[i] = time.Duration(stats.TotalMS * float64(time.Millisecond)).String()
+	}
+	return record
+}
+
+// Milliseconds converts a duration to fractional milliseconds
+func Milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,419 @@
+package formats
+
+import (
+	"sort"
+	"time"
+
+	"tic-tac-toe-3d-bots/bots"
+	"tic-tac-toe-3d-bots/engine"
+)
+
+// The messages of game.proto. Board, Move and SearchUpdate have Go types of their own, as what they carry is spread
+// over several internal structs; the other messages are the wire form of GameRecord, GameResult and bots.BotConfig,
+// which convert to and from them with MarshalProto and UnmarshalProto
+
+// ProtoBoard is the Board message
+type ProtoBoard struct {
+	Length, Width, Height, Win int
+	Moves                      []string
+	Snapshot                   string
+}
+
+// ProtoBoardOf describes the position reached by record
+func ProtoBoardOf(record *GameRecord) ProtoBoard {
+	return ProtoBoard{Length: record.Board.Length, Width: record.Board.Width, Height: record.Board.Height, Win: record.Board.Win,
+		Moves: record.Moves}
+}
+
+// Config returns the board's dimensions
+func (board ProtoBoard) Config() engine.BoardConfig {
+	return engine.BoardConfig{Length: board.Length, Width: board.Width, Height: board.Height, Win: board.Win}
+}
+
+func (board ProtoBoard) MarshalProto() []byte {
+	encoder := ProtoEncoder{}
+	encoder.Int(1, int64(board.Length))
+	encoder.Int(2, int64(board.Width))
+	encoder.Int(3, int64(board.Height))
+	encoder.Int(4, int64(board.Win))
+	encoder.Strings(5, board.Moves)
+	encoder.String(6, board.Snapshot)
+	return encoder.Data
+}
+
+func (board *ProtoBoard) UnmarshalProto(data []byte) error {
+	return DecodeProto(data, func(field ProtoField) error {
+		switch field.Number {
+		case 1:
+			board.Length = int(field.Int())
+		case 2:
+			board.Width = int(field.Int())
+		case 3:
+			board.Height = int(field.Int())
+		case 4:
+			board.Win = int(field.Int())
+		case 5:
+			board.Moves = append(board.Moves, field.Text())
+		case 6:
+			board.Snapshot = field.Text()
+		}
+		return nil
+	})
+}
+
+// ProtoMove is the Move message
+type ProtoMove struct {
+	Name, Player string
+	ThinkingMS   int64
+}
+
+func (move ProtoMove) MarshalProto() []byte {
+	encoder := ProtoEncoder{}
+	encoder.String(1, move.Name)
+	encoder.String(2, move.Player)
+	encoder.Int(3, move.ThinkingMS)
+	return encoder.Data
+}
+
+func (move *ProtoMove) UnmarshalProto(data []byte) error {
+	return DecodeProto(data, func(field ProtoField) error {
+		switch field.Number {
+		case 1:
+			move.Name = field.Text()
+		case 2:
+			move.Player = field.Text()
+		case 3:
+			move.ThinkingMS = field.Int()
+		}
+		return nil
+	})
+}
+
+// ProtoSearchUpdate is the SearchUpdate message
+type ProtoSearchUpdate struct {
+	Depth          int
+	Score          int64
+	PV             []string
+	WinProbability float64
+	ForcedWinner   string
+	Final          bool
+	ElapsedMS      int64
+}
+
+func (update ProtoSearchUpdate) MarshalProto() []byte {
+	encoder := ProtoEncoder{}
+	encoder.Int(1, int64(update.Depth))
+	encoder.Int(2, update.Score)
+	encoder.Strings(3, update.PV)
+	encoder.Double(4, update.WinProbability)
+	encoder.String(5, update.ForcedWinner)
+	encoder.Bool(6, update.Final)
+	encoder.Int(7, update.ElapsedMS)
+	return encoder.Data
+}
+
+func (update *ProtoSearchUpdate) UnmarshalProto(data []byte) error {
+	return DecodeProto(data, func(field ProtoField) error {
+		switch field.Number {
+		case 1:
+			update.Depth = int(field.Int())
+		case 2:
+			update.Score = field.Int()
+		case 3:
+			update.PV = append(update.PV, field.Text())
+		case 4:
+			update.WinProbability = field.Double()
+		case 5:
+			update.ForcedWinner = field.Text()
+		case 6:
+			update.Final = field.Bool()
+		case 7:
+			update.ElapsedMS = field.Int()
+		}
+		return nil
+	})
+}
+
+// marshalBotConfig encodes the BotConfig message; its params are sorted so that equal configurations encode alike
+func marshalBotConfig(config *bots.BotConfig) []byte {
+	encoder := ProtoEncoder{}
+	encoder.String(1, config.Type)
+	encoder.String(2, config.Name)
+	keys := make([]string, 0, len(config.Params))
+	for key := range config.Params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		entry := ProtoEncoder{}
+		entry.String(1, key)
+		entry.Int(2, int64(config.Params[key]))
+		encoder.Bytes(3, entry.Data)
+	}
+	encoder.Strings(4, config.Command)
+	return encoder.Data
+}
+
+// unmarshalBotConfig decodes the BotConfig message
+func unmarshalBotConfig(data []byte) (*bots.BotConfig, error) {
+	config := &bots.BotConfig{Params: make(map[string]int)}
+	err := DecodeProto(data, func(field ProtoField) error {
+		switch field.Number {
+		case 1:
+			config.Type = field.Text()
+		case 2:
+			config.Name = field.Text()
+		case 3:
+			var key string
+			var value int64
+			if err := DecodeProto(field.Bytes, func(entry ProtoField) error {
+				switch entry.Number {
+				case 1:
+					key = entry.Text()
+				case 2:
+					value = entry.Int()
+				}
+				return nil
+			}); err != nil {
+				return err
+			}
+			config.Params[key] = int(value)
+		case 4:
+			config.Command = append(config.Command, field.Text())
+		}
+		return nil
+	})
+	return config, err
+}
+
+// marshalPlayers encodes the players and bots fields shared by GameRecord and GameResult
+func marshalPlayers(encoder *ProtoEncoder, players [2]string, configs [2]*bots.BotConfig) {
+	encoder.Strings(3, players[:])
+	for i, config := range configs {
+		if config != nil {
+			encoder.Bytes(4+i, marshalBotConfig(config))
+		}
+	}
+}
+
+// unmarshalPlayer decodes a players or bots field shared by GameRecord and GameResult; it reports whether field is one
+func unmarshalPlayer(field ProtoField, players *[2]string, configs *[2]*bots.BotConfig, playerCount *int) (bool, error) {
+	switch field.Number {
+	case 3:
+		if *playerCount < 2 {
+			players[*playerCount] = field.Text()
+		}
+		*playerCount++
+	case 4, 5:
+		config, err := unmarshalBotConfig(field.Bytes)
+		configs[field.Number-4] = config
+		return true, err
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// MarshalProto encodes the record as the GameRecord message
+func (record *GameRecord) MarshalProto() []byte {
+	encoder := ProtoEncoder{}
+	encoder.String(1, record.Mode)
+	encoder.Bytes(2, ProtoBoardOf(record).MarshalProto())
+	marshalPlayers(&encoder, record.Players, record.Bots)
+	encoder.String(6, record.Player)
+	for i, clock := range record.ThinkingTimes() {
+		encoder.Double(7+i, Milliseconds(clock))
+	}
+	if record.PvE != nil {
+		settings := ProtoEncoder{}
+		settings.Bool(1, record.PvE.Training)
+		encoder.Bytes(9, settings.Data)
+	}
+	if record.EvE != nil {
+		settings := ProtoEncoder{}
+		settings.Bool(1, record.EvE.AutoPlay)
+		settings.Int(2, record.EvE.MoveTimeLimit.Milliseconds())
+		settings.Bool(3, record.EvE.ShowSearchStats)
+		settings.Bool(4, record.EvE.Quiet)
+		settings.Bool(5, record.EvE.Silent)
+		settings.Strings(6, record.EvE.Opening)
+		settings.Int(7, int64(record.EvE.ResignScore))
+		settings.Int(8, int64(record.EvE.ResignMoves))
+		settings.Int(9, int64(record.EvE.AdjudicateWin))
+		settings.Int(10, int64(record.EvE.AdjudicateDraw))
+		settings.Int(11, int64(record.EvE.DrawAfter))
+		settings.Int(12, int64(record.EvE.AdjudicateMoves))
+		encoder.Bytes(10, settings.Data)
+	}
+	depths := make([]int64, len(record.Depths))
+	for i, depth := range record.Depths {
+		depths[i] = int64(depth)
+	}
+	encoder.Ints(11, depths)
+	return encoder.Data
+}
+
+// UnmarshalProto decodes the GameRecord message into the record; the game is not validated
+func (record *GameRecord) UnmarshalProto(data []byte) error {
+	playerCount := 0
+	return DecodeProto(data, func(field ProtoField) error {
+		if isPlayer, err := unmarshalPlayer(field, &record.Players, &record.Bots, &playerCount); isPlayer {
+			return err
+		}
+		switch field.Number {
+		case 1:
+			record.Mode = field.Text()
+		case 2:
+			board := ProtoBoard{}
+			if err := board.UnmarshalProto(field.Bytes); err != nil {
+				return err
+			}
+			record.Board, record.Moves = board.Config(), board.Moves
+		case 6:
+			record.Player = field.Text()
+		case 7, 8:
+			record.Clocks[field.Number-7] = time.Duration(field.Double() * float64(time.Millisecond)).String()
+		case 9:
+			record.PvE = &PvESettings{}
+			return DecodeProto(field.Bytes, func(setting ProtoField) error {
+				if setting.Number == 1 {
+					record.PvE.Training = setting.Bool()
+				}
+				return nil
+			})
+		case 10:
+			record.EvE = &EvESettings{}
+			return DecodeProto(field.Bytes, func(setting ProtoField) error {
+				switch setting.Number {
+				case 1:
+					record.EvE.AutoPlay = setting.Bool()
+				case 2:
+					record.EvE.MoveTimeLimit = time.Duration(setting.Int()) * time.Millisecond
+				case 3:
+					record.EvE.ShowSearchStats = setting.Bool()
+				case 4:
+					record.EvE.Quiet = setting.Bool()
+				case 5:
+					record.EvE.Silent = setting.Bool()
+				case 6:
+					record.EvE.Opening = append(record.EvE.Opening, setting.Text())
+				case 7:
+					record.EvE.ResignScore = int(setting.Int())
+				case 8:
+					record.EvE.ResignMoves = int(setting.Int())
+				case 9:
+					record.EvE.AdjudicateWin = int(setting.Int())
+				case 10:
+					record.EvE.AdjudicateDraw = int(setting.Int())
+				case 11:
+					record.EvE.DrawAfter = int(setting.Int())
+				case 12:
+					record.EvE.AdjudicateMoves = int(setting.Int())
+				}
+				return nil
+			})
+		case 11:
+			depths, err := field.Ints()
+			for _, depth := range depths {
+				record.Depths = append(record.Depths, int(depth))
+			}
+			return err
+		}
+		return nil
+	})
+}
+
+// MarshalProto encodes the result as the GameResult message
+func (result *GameResult) MarshalProto() []byte {
+	encoder := ProtoEncoder{}
+	encoder.String(1, result.Mode)
+	encoder.Bytes(2, ProtoBoard{Length: result.Board.Length, Width: result.Board.Width, Height: result.Board.Height,
+		Win: result.Board.Win}.MarshalProto())
+	marshalPlayers(&encoder, result.Players, result.Bots)
+	encoder.String(6, result.Winner)
+	encoder.String(7, result.Reason)
+	for _, move := range result.Moves {
+		played := ProtoEncoder{}
+		played.Int(1, int64(move.Ply))
+		played.String(2, move.Player)
+		played.String(3, move.Move)
+		played.Double(4, move.ThinkingMS)
+		if move.Score != nil {
+			played.Varint(5, int64(*move.Score))
+		}
+		encoder.Bytes(8, played.Data)
+	}
+	for i, stats := range result.Stats {
+		player := ProtoEncoder{}
+		player.Int(1, int64(stats.Moves))
+		player.Double(2, stats.TotalMS)
+		player.Double(3, stats.AverageMS)
+		player.Double(4, stats.TTFill)
+		encoder.Bytes(9+i, player.Data)
+	}
+	encoder.Int(11, result.Seed)
+	return encoder.Data
+}
+
+// UnmarshalProto decodes the GameResult message into the result
+func (result *GameResult) UnmarshalProto(data []byte) error {
+	playerCount := 0
+	return DecodeProto(data, func(field ProtoField) error {
+		if isPlayer, err := unmarshalPlayer(field, &result.Players, &result.Bots, &playerCount); isPlayer {
+			return err
+		}
+		switch field.Number {
+		case 1:
+			result.Mode = field.Text()
+		case 2:
+			board := ProtoBoard{}
+			if err := board.UnmarshalProto(field.Bytes); err != nil {
+				return err
+			}
+			result.Board = board.Config()
+		case 6:
+			result.Winner = field.Text()
+		case 7:
+			result.Reason = field.Text()
+		case 8:
+			move := PlayedMove{}
+			if err := DecodeProto(field.Bytes, func(played ProtoField) error {
+				switch played.Number {
+				case 1:
+					move.Ply = int(played.Int())
+				case 2:
+					move.Player = played.Text()
+				case 3:
+					move.Move = played.Text()
+				case 4:
+					move.ThinkingMS = played.Double()
+				case 5:
+					score := int(played.Int())
+					move.Score = &score
+				}
+				return nil
+			}); err != nil {
+				return err
+			}
+			result.Moves = append(result.Moves, move)
+		case 9, 10:
+			stats := &result.Stats[field.Number-9]
+			return DecodeProto(field.Bytes, func(player ProtoField) error {
+				switch player.Number {
+				case 1:
+					stats.Moves = int(player.Int())
+				case 2:
+					stats.TotalMS = player.Double()
+				case 3:
+					stats.AverageMS = player.Double()
+				case 4:
+					stats.TTFill = player.Double()
+				}
+				return nil
+			})
+		case 11:
+			result.Seed = field.Int()
+		}
+		return nil
+	})
+}
@@ -0,0 +1,166 @@
+package formats
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// The messages of game.proto, which the gRPC service, the arena and binary save files share, are encoded in the
+// protobuf wire format (https://protobuf.dev/programming-guides/encoding/) with these few helpers, so the module
+// needs no generated code and no dependencies
+
+// Protobuf wire types
+const (
+	ProtoVarint  = 0
+	ProtoFixed64 = 1
+	ProtoBytes   = 2
+	ProtoFixed32 = 5
+)
+
+// ProtoEncoder appends fields to a message; like proto3, it leaves out fields holding their default value
+type ProtoEncoder struct {
+	Data []byte
+}
+
+func (encoder *ProtoEncoder) tag(field, wireType int) {
+	encoder.Data = binary.AppendUvarint(encoder.Data, uint64(field<<3|wireType))
+}
+
+// Int encodes an int32 or int64 field; negative numbers take ten bytes, as in protobuf
+func (encoder *ProtoEncoder) Int(field int, value int64) {
+	if value != 0 {
+		encoder.Varint(field, value)
+	}
+}
+
+// Varint encodes an integer field even if it is 0, as set optional fields must be
+func (encoder *ProtoEncoder) Varint(field int, value int64) {
+	encoder.tag(field, ProtoVarint)
+	encoder.Data = binary.AppendUvarint(encoder.Data, uint64(value))
+}
+
+// Ints encodes a repeated integer field, packed as proto3 does
+func (encoder *ProtoEncoder) Ints(field int, values []int64) {
+	if len(values) == 0 {
+		return
+	}
+	var packed []byte
+	for _, value := range values {
+		packed = binary.AppendUvarint(packed, uint64(value))
+	}
+	encoder.Bytes(field, packed)
+}
+
+func (encoder *ProtoEncoder) Bool(field int, value bool) {
+	if value {
+		encoder.Int(field, 1)
+	}
+}
+
+func (encoder *ProtoEncoder) Double(field int, value float64) {
+	if value != 0 {
+		encoder.tag(field, ProtoFixed64)
+		encoder.Data = binary.LittleEndian.AppendUint64(encoder.Data, math.Float64bits(value))
+	}
+}
+
+func (encoder *ProtoEncoder) String(field int, value string) {
+	if value != "" {
+		encoder.Bytes(field, []byte(value))
+	}
+}
+
+// Strings encodes a repeated string field, empty strings included
+func (encoder *ProtoEncoder) Strings(field int, values []string) {
+	for _, value := range values {
+		encoder.Bytes(field, []byte(value))
+	}
+}
+
+// Bytes encodes a length-delimited field even if it is empty, as set message fields must be
+func (encoder *ProtoEncoder) Bytes(field int, value []byte) {
+	encoder.tag(field, ProtoBytes)
+	encoder.Data = binary.AppendUvarint(encoder.Data, uint64(len(value)))
+	encoder.Data = append(encoder.Data, value...)
+}
+
+// ProtoField is one decoded field: numeric values are in Value, length-delimited ones in Bytes
+type ProtoField struct {
+	Number   int
+	WireType int
+	Value    uint64
+	Bytes    []byte
+}
+
+func (field ProtoField) Int() int64 { return int64(field.Value) }
+
+func (field ProtoField) Bool() bool { return field.Value != 0 }
+
+func (field ProtoField) Double() float64 { return math.Float64frombits(field.Value) }
+
+func (field ProtoField) Text() string { return string(field.Bytes) }
+
+// Ints returns the values of a repeated integer field, whether it was packed or not
+func (field ProtoField) Ints() ([]int64, error) {
+	if field.WireType != ProtoBytes {
+		return []int64{field.Int()}, nil
+	}
+	var values []int64
+	for data := field.Bytes; len(data) > 0; {
+		value, n := binary.Uvarint(data)
+		if n <= 0 {
+			return nil, ErrProtoTruncated
+		}
+		values, data = append(values, int64(value)), data[n:]
+	}
+	return values, nil
+}
+
+// ErrProtoTruncated is returned for a message that ends in the middle of a field
+var ErrProtoTruncated = errors.New("truncated protobuf message")
+
+// DecodeProto calls visit with every field of a message, in order; unknown fields can simply be ignored by visit
+func DecodeProto(data []byte, visit func(field ProtoField) error) error {
+	for len(data) > 0 {
+		key, n := binary.Uvarint(data)
+		if n <= 0 {
+			return ErrProtoTruncated
+		}
+		data = data[n:]
+		field := ProtoField{Number: int(key >> 3), WireType: int(key & 7)}
+
+		switch field.WireType {
+		case ProtoVarint:
+			field.Value, n = binary.Uvarint(data)
+			if n <= 0 {
+				return ErrProtoTruncated
+			}
+			data = data[n:]
+		case ProtoFixed64:
+			if len(data) < 8 {
+				return ErrProtoTruncated
+			}
+			field.Value, data = binary.LittleEndian.Uint64(data), data[8:]
+		case ProtoFixed32:
+			if len(data) < 4 {
+				return ErrProtoTruncated
+			}
+			field.Value, data = uint64(binary.LittleEndian.Uint32(data)), data[4:]
+		case ProtoBytes:
+			length, n := binary.Uvarint(data)
+			if n <= 0 || length > uint64(len(data)-n) {
+				return ErrProtoTruncated
+			}
+			field.Bytes, data = data[n:n+int(length)], data[n+int(length):]
+		default:
+			return fmt.Errorf("unsupported protobuf wire type %d", field.WireType)
+		}
+
+		if err := visit(field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+// Package formats reads and writes games: saved game records, game results, and the export and import formats
+package formats
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"tic-tac-toe-3d-bots/bots"
+	"tic-tac-toe-3d-bots/engine"
+)
+
+// GameRecord is the full state of a game in progress, as written by the save command and on Ctrl+C
+// The board is not stored cell by cell: resuming rebuilds it by replaying Moves
+type GameRecord struct {
+	Mode    string             `json:"mode"`             // pvp, pve, eve, pvestream, evestream, netpvp or arena
+	Board   engine.BoardConfig `json:"board"`            // board dimensions
+	Players [2]string          `json:"players"`          // names of the 'x' and 'o' players
+	Bots    [2]*bots.BotConfig `json:"bots"`             // configuration of the bot playing 'x' and 'o', null for a human
+	Player  string             `json:"player,omitempty"` // human player's name for per-player statistics
+	Clocks  [2]string          `json:"clocks"`           // thinking time used so far by 'x' and 'o', as Go durations
+	Moves   []string           `json:"moves"`            // moves played so far, 'x' first
+	PvE     *PvESettings       `json:"pve,omitempty"`    // Player vs Bot settings
+	EvE     *EvESettings       `json:"eve,omitempty"`    // Bot vs Bot settings
+	Depths  []int              `json:"depths,omitempty"` // analysis depths of PvE Stream mode
+}
+
+// PvESettings controls optional Player vs Bot features
+type PvESettings struct {
+	Training bool `json:"training"` // check each human move for blunders and offer to take it back
+}
+
+// EvESettings controls how a bot vs bot game is run and displayed
+type EvESettings struct {
+	AutoPlay        bool          `json:"auto"`                       // play without waiting for Enter and without printing the board
+	MoveTimeLimit   time.Duration `json:"move_time_limit"`            // a bot exceeding this per-move time loses on time (0 means unlimited)
+	ShowSearchStats bool          `json:"show_search_stats"`          // print per-worker search statistics after bot moves
+	Quiet           bool          `json:"quiet"`                      // play automatically and print only the result and final statistics
+	Silent          bool          `json:"silent"`                     // play automatically and print nothing; used for the games of a match
+	Opening         []string      `json:"opening,omitempty"`          // moves played for the bots before they take over, e.g. from an opening book
+	ResignScore     int           `json:"resign_score,omitempty"`     // a bot resigns once it scores its position this far below even... (0 never resigns)
+	ResignMoves     int           `json:"resign_moves,omitempty"`     // ...after this many of its moves in a row
+	AdjudicateWin   int           `json:"adjudicate_win,omitempty"`   // the game is won once both bots score the position this far in one side's favour... (0 never)
+	AdjudicateDraw  int           `json:"adjudicate_draw,omitempty"`  // ...or drawn once both score it within this of even...
+	DrawAfter       int           `json:"draw_after,omitempty"`       // ...from this move on (0 never)...
+	AdjudicateMoves int           `json:"adjudicate_moves,omitempty"` // ...for this many moves in a row
+	Game            int           `json:"-"`                          // number of the game within its run (0 numbers it as it starts)
+}
+
+// SymbolIndex returns 0 for 'x' and 1 for 'o', the order of GameRecord's per-player fields
+func SymbolIndex(symbol byte) int {
+	if symbol == 'x' {
+		return 0
+	}
+	return 1
+}
+
+// BINARY_SAVE_EXTENSION marks saved games written as the GameRecord message of game.proto instead of JSON
+const BINARY_SAVE_EXTENSION = ".pb"
+
+// Save writes the record to path, replacing the file atomically
+// It is written as indented JSON, or as protobuf if path ends with BINARY_SAVE_EXTENSION
+func (record GameRecord) Save(path string) error {
+	var data []byte
+	var err error
+	if filepath.Ext(path) == BINARY_SAVE_EXTENSION {
+		data = record.MarshalProto()
+	} else if data, err = json.MarshalIndent(record, "", "  "); err != nil {
+		return err
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// LoadGameRecord reads and validates a saved game, in JSON or, if path ends with BINARY_SAVE_EXTENSION, protobuf
+func LoadGameRecord(path string) (*GameRecord, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &GameRecord{}
+	if filepath.Ext(path) == BINARY_SAVE_EXTENSION {
+		err = record.UnmarshalProto(data)
+	} else {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(record)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	board := record.Board
+	if err := engine.ValidateBoardDimensions(board.Length, board.Width, board.Height, board.Win); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	for _, clock := range record.Clocks {
+		if clock == "" {
+			continue
+		}
+		if _, err := time.ParseDuration(clock); err != nil {
+			return nil, fmt.Errorf("%s: clocks: %v", path, err)
+		}
+	}
+
+	return record, nil
+}
+
+// ThinkingTimes returns the thinking time used by 'x' and 'o' (validated on load)
+func (record *GameRecord) ThinkingTimes() [2]time.Duration {
+	var clocks [2]time.Duration
+	for i, clock := range record.Clocks {
+		clocks[i], _ = time.ParseDuration(clock)
+	}
+	return clocks
+}
+
+// Replay rebuilds the board by playing the first moveCount recorded moves, 'x' first
+func (record *GameRecord) Replay(moveCount int) (*engine.Board, error) {
+	board, err := engine.New(engine.WithConfig(record.Board))
+	if err != nil {
+		return nil, err
+	}
+	symbol := byte('x')
+	for i, move := range record.Moves[:moveCount] {
+		if board.CheckWin() != '|' {
+			return nil, fmt.Errorf("move %d (%s) is played after the game was won", i+1, move)
+		}
+		if coords := board.Move(move, symbol); coords[0] == -1 {
+			return nil, fmt.Errorf("move %d (%s) is illegal", i+1, move)
+		}
+		symbol = engine.OpponentSymbol(symbol)
+	}
+	return board, nil
+}